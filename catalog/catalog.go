@@ -6,6 +6,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -85,7 +86,13 @@ func load(rootDmap *sync.Map, d *Directory, subPath, rootPath string) error {
 					return fmt.Errorf(io.GetCallerFileContext(0) + ", " + err.Error())
 				}
 			}
-		} else if filepath.Ext(leafPath) == ".bin" {
+		} else if ext := filepath.Ext(leafPath); ext == io.YearFileExt || ext == io.ArchiveFileExt ||
+			ext == io.RemoteFileExt {
+			if shadowed := shadowingFile(leafPath); shadowed != "" {
+				// an interrupted conversion or offload, the file it started from is still the one in use
+				log.Warn("both %s and %s exist, %s will be ignored", shadowed, leafPath, leafPath)
+				continue
+			}
 			rootDmap.Store(d.pathToItemName, d)
 			if d.datafile == nil {
 				d.datafile = make(map[string]*io.TimeBucketInfo)
@@ -95,7 +102,7 @@ func load(rootDmap *sync.Map, d *Directory, subPath, rootPath string) error {
 			d.datafile[leafPath].IsRead = false
 			d.datafile[leafPath].Path = leafPath
 			yearFileBase := filepath.Base(leafPath)
-			yearString := yearFileBase[:len(yearFileBase)-4]
+			yearString := yearFileBase[:len(yearFileBase)-len(ext)]
 			yearInt, err := strconv.Atoi(yearString)
 			if err != nil {
 				return fmt.Errorf(io.GetCallerFileContext(0) + err.Error())
@@ -111,6 +118,23 @@ func load(rootDmap *sync.Map, d *Directory, subPath, rootPath string) error {
 	return nil
 }
 
+/*
+shadowingFile returns the path of the file taking precedence over the file of a year, if it exists:
+the year file over the archive file and the stub of an offloaded year, and the archive file over the stub.
+*/
+func shadowingFile(leafPath string) string {
+	ext := filepath.Ext(leafPath)
+	for _, other := range []string{io.YearFileExt, io.ArchiveFileExt} {
+		if other == ext {
+			return ""
+		}
+		if path := strings.TrimSuffix(leafPath, ext) + other; fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
 func fileExists(path string) bool {
 	_, err := os.Stat(path)
 	if err == nil {
@@ -351,8 +375,12 @@ func (subDir *Directory) AddFile(newYear int16) (finfo_p *io.TimeBucketInfo, err
 
 	var finfoTemplate *io.TimeBucketInfo
 	for _, fi := range subDir.datafile {
+		if fi.Year == newYear && (fi.IsArchived() || fi.IsRemote()) {
+			// archived and offloaded years are not re-created, writers check them
+			subDir.RUnlock()
+			return fi, nil
+		}
 		finfoTemplate = fi
-		break
 	}
 	subDir.RUnlock()
 
@@ -533,6 +561,40 @@ func ListTimeBucketKeyNames(d *Directory) []string {
 	return result
 }
 
+// MatchTimeBucketKeys returns the sorted keys of the buckets matching the pattern.
+// Each item of the pattern is a comma separated list of glob patterns (e.g. "AAPL,MS*/1Min/*").
+func MatchTimeBucketKeys(d *Directory, pattern *io.TimeBucketKey) (keys []*io.TimeBucketKey) {
+	patternItems := pattern.GetItems()
+	names := ListTimeBucketKeyNames(d)
+	sort.Strings(names)
+	for _, name := range names {
+		items := strings.Split(name, "/")
+		if len(items) != len(patternItems) {
+			continue
+		}
+		matched := true
+		for i, item := range items {
+			matched = matched && matchItem(patternItems[i], item)
+		}
+		if matched {
+			keys = append(keys, io.NewTimeBucketKey(name, pattern.GetCatKey()))
+		}
+	}
+	return keys
+}
+
+func matchItem(pattern, item string) bool {
+	for _, p := range strings.Split(pattern, ",") {
+		if p == item {
+			return true
+		}
+		if ok, _ := path.Match(p, item); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func (d *Directory) String() string {
 	// Must be thread-safe for READ access
 	printstring := "Node: " + d.itemName
@@ -606,6 +668,28 @@ func (d *Directory) GetLatestYearFile() (latestFile *io.TimeBucketInfo, err erro
 	return latestFile, nil
 }
 
+// ReloadYearFiles forgets the headers read from the year files of the directory, e.g. after the files
+// are rewritten with a new layout. The headers are read again on the next access.
+func (d *Directory) ReloadYearFiles() {
+	d.Lock()
+	defer d.Unlock()
+	for path, fi := range d.datafile {
+		d.datafile[path] = &io.TimeBucketInfo{Year: fi.Year, Path: fi.Path}
+	}
+}
+
+// RemoveYearFile removes a year file from the catalog, once the file is removed from the directory of its bucket.
+func (d *Directory) RemoveYearFile(fullFilePath string) error {
+	subDir, err := d.GetOwningSubDirectory(fullFilePath)
+	if err != nil {
+		return err
+	}
+	subDir.Lock()
+	defer subDir.Unlock()
+	delete(subDir.datafile, fullFilePath)
+	return nil
+}
+
 func (d *Directory) pathToKey(fullPath string) (key string) {
 	dirPath := path.Dir(fullPath)
 	key = strings.Replace(dirPath, d.pathToItemName, "", 1)
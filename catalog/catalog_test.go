@@ -68,6 +68,22 @@ func TestGetDirList(t *testing.T) {
 	assert.Len(t, dirList, 40)
 }
 
+func TestMatchTimeBucketKeys(t *testing.T) {
+	tearDown, _, catalogDir := setup(t, "TestMatchTimeBucketKeys")
+	defer tearDown()
+
+	keyNames := func(pattern string) (names []string) {
+		for _, key := range catalog.MatchTimeBucketKeys(catalogDir, io.NewTimeBucketKey(pattern)) {
+			names = append(names, key.GetItemKey())
+		}
+		return names
+	}
+	assert.Equal(t, []string{"EURUSD/1H/OHLC", "USDJPY/1H/OHLC"}, keyNames("EURUSD,USD*/1H/OHLC"))
+	assert.Equal(t, []string{"EURUSD/1D/OHLC", "NZDUSD/1D/OHLC", "USDJPY/1D/OHLC"}, keyNames("*/1D/OHLC"))
+	assert.Equal(t, []string{"NZDUSD/1D/OHLC"}, keyNames("NZDUSD/1D/*"))
+	assert.Empty(t, keyNames("NOPE/1D/OHLC"))
+}
+
 func TestGatherFilePaths(t *testing.T) {
 	tearDown, _, catalogDir := setup(t, "TestGatherFilePaths")
 	defer tearDown()
@@ -1,6 +1,7 @@
 package loader
 
 import (
+	"encoding/hex"
 	"fmt"
 	"strconv"
 
@@ -28,6 +29,12 @@ func columnSeriesMapFromCSVData(csmInit io.ColumnSeriesMap, key io.TimeBucketKey
 					return nil
 				}
 				csm.AddColumn(key, shape.Name, col)
+			case io.BLOB:
+				col, err := getBlobColumnFromCSVRows(csvRows, index)
+				if columnError(err, shape.Name) {
+					return nil
+				}
+				csm.AddColumn(key, shape.Name, col)
 			case io.FLOAT32:
 				col, err := getFloat32ColumnFromCSVRows(csvRows, index)
 				if columnError(err, shape.Name) {
@@ -88,6 +95,13 @@ func columnSeriesMapFromCSVData(csmInit io.ColumnSeriesMap, key io.TimeBucketKey
 					return nil
 				}
 				csm.AddColumn(key, shape.Name, col)
+			case io.DECIMAL64:
+				col, err := getDecimal64ColumnFromCSVRows(csvRows, index, shape.Scale)
+				if columnError(err, shape.Name) {
+					return nil
+				}
+				csm.AddColumn(key, shape.Name, col)
+				csm[key].SetScale(shape.Name, shape.Scale)
 			case io.STRING16:
 				col := getString16ColumnFromCSVRows(csvRows, index)
 				csm.AddColumn(key, shape.Name, col)
@@ -131,6 +145,18 @@ func getStringColumnFromCSVRows(csvRows [][]string, index int) (col []string, er
 	return col, nil
 }
 
+// getBlobColumnFromCSVRows returns the values of a BLOB column, which are written in hexadecimal in the CSV.
+func getBlobColumnFromCSVRows(csvRows [][]string, index int) (col [][]byte, err error) {
+	col = make([][]byte, len(csvRows))
+	for i, row := range csvRows {
+		col[i], err = hex.DecodeString(row[index])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return col, nil
+}
+
 func getFloat32ColumnFromCSVRows(csvRows [][]string, index int) (col []float32, err error) {
 	col = make([]float32, len(csvRows))
 	for i, row := range csvRows {
@@ -154,6 +180,17 @@ func getFloat64ColumnFromCSVRows(csvRows [][]string, index int) (col []float64,
 	return col, nil
 }
 
+func getDecimal64ColumnFromCSVRows(csvRows [][]string, index int, scale int8) (col []io.Decimal64, err error) {
+	col = make([]io.Decimal64, len(csvRows))
+	for i, row := range csvRows {
+		col[i], err = io.ParseDecimal64(row[index], scale)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return col, nil
+}
+
 func getInt8ColumnFromCSVRows(csvRows [][]string, index int) (col []int8, err error) {
 	col = make([]int8, len(csvRows))
 	for i, row := range csvRows {
@@ -0,0 +1,79 @@
+package session
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+// alter changes the columns of a bucket.
+// It returns true if the bucket is successfully altered.
+func (c *Client) alter(line string) (ok bool) {
+	args := strings.Fields(line)
+	args = args[1:] // chop off the first word which should be "alter"
+	// args[0]:tbk, then pairs of an action ("add", "drop" or "retype") and its columns
+	const minArgLen = 3
+	if len(args) < minArgLen || len(args)%2 == 0 {
+		// nolint:forbidigo // CLI output needs fmt.Println
+		fmt.Println(`Wrong number of arguments - need "\alter [tbk] [add|drop|retype] [columns] ..."`)
+		// nolint:forbidigo // CLI output needs fmt.Println
+		fmt.Println(`example usage: "\alter TEST/1Min/OHLCV add Trades/int32 drop Volume retype Open,Close/float64"`)
+		return false
+	}
+
+	req, err := toAlterBucketRequest(args[0], args[1:])
+	if err != nil {
+		log.Error("Failed with error: %s", err.Error())
+		return false
+	}
+	reqs := &frontend.MultiAlterBucketRequest{
+		Requests: []frontend.AlterBucketRequest{*req},
+	}
+	responses := &frontend.MultiServerResponse{}
+
+	err = c.apiClient.AlterBucket(reqs, responses)
+	if err != nil {
+		log.Error("Failed with error: %s", err.Error())
+		return false
+	}
+
+	for _, resp := range responses.Responses {
+		if resp.Error != "" {
+			log.Error("Failed with error: %v", resp.Error)
+			return false
+		}
+	}
+	log.Info("Successfully altered the columns of bucket %s\n", args[0])
+	return true
+}
+
+// toAlterBucketRequest builds the request from the action and columns pairs of the command.
+func toAlterBucketRequest(key string, actions []string) (*frontend.AlterBucketRequest, error) {
+	req := &frontend.AlterBucketRequest{Key: key}
+	for i := 0; i+1 < len(actions); i += 2 {
+		columns := actions[i+1]
+		switch actions[i] {
+		case "add":
+			names, types, err := toColumns(columns)
+			if err != nil {
+				return nil, err
+			}
+			req.AddColumnNames = append(req.AddColumnNames, names...)
+			req.AddColumnTypes = append(req.AddColumnTypes, types...)
+		case "drop":
+			req.DropColumnNames = append(req.DropColumnNames, strings.Split(columns, ",")...)
+		case "retype":
+			names, types, err := toColumns(columns)
+			if err != nil {
+				return nil, err
+			}
+			req.RetypeColumnNames = append(req.RetypeColumnNames, names...)
+			req.RetypeColumnTypes = append(req.RetypeColumnTypes, types...)
+		default:
+			return nil, fmt.Errorf("unknown action %q, should be one of add, drop or retype", actions[i])
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,99 @@
+package session
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/alpacahq/marketstore/v4/cmd/connect/session/mock"
+	"github.com/alpacahq/marketstore/v4/frontend"
+)
+
+const exampleCommandAlter = `\alter TEST/1Min/OHLCV add Trades/int32 drop Low,High retype Open,Close/float64`
+
+func TestClient_alter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		line    string
+		resp    *frontend.MultiServerResponse
+		err     error
+		calls   int // The calls reaching the API
+		wantReq *frontend.AlterBucketRequest
+		wantOk  bool
+	}{
+		{
+			name:  "success",
+			line:  exampleCommandAlter,
+			calls: 1,
+			resp:  &frontend.MultiServerResponse{Responses: []frontend.ServerResponse{}},
+			wantReq: &frontend.AlterBucketRequest{
+				Key:               "TEST/1Min/OHLCV",
+				AddColumnNames:    []string{"Trades"},
+				AddColumnTypes:    []string{"i4"},
+				DropColumnNames:   []string{"Low", "High"},
+				RetypeColumnNames: []string{"Open", "Close"},
+				RetypeColumnTypes: []string{"f8", "f8"},
+			},
+			wantOk: true,
+		},
+		{
+			name:   "error/not enough arguments",
+			line:   `\alter TEST/1Min/OHLCV drop`,
+			wantOk: false,
+		},
+		{
+			name:   "error/unknown action",
+			line:   `\alter TEST/1Min/OHLCV rename Open`,
+			wantOk: false,
+		},
+		{
+			name:   "error/invalid typestr",
+			line:   `\alter TEST/1Min/OHLCV add Trades/float128`,
+			wantOk: false,
+		},
+		{
+			name:   "error/rpc Error",
+			line:   exampleCommandAlter,
+			calls:  1,
+			err:    errors.New("error"),
+			wantOk: false,
+		},
+		{
+			name:   "error/AlterBucket API error",
+			line:   exampleCommandAlter,
+			calls:  1,
+			resp:   &frontend.MultiServerResponse{Responses: []frontend.ServerResponse{{Error: "API errors!"}}},
+			wantOk: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockCtrl := gomock.NewController(t)
+			mockClient := mock.NewMockAPIClient(mockCtrl)
+			mockClient.EXPECT().AlterBucket(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(reqs *frontend.MultiAlterBucketRequest, responses *frontend.MultiServerResponse) error {
+					if tt.wantReq != nil && !reflect.DeepEqual(reqs.Requests[0], *tt.wantReq) {
+						t.Errorf("AlterBucket() request = %v, want %v", reqs.Requests[0], *tt.wantReq)
+					}
+					if responses != nil && tt.resp != nil {
+						*responses = *tt.resp
+					}
+					return tt.err
+				},
+			).Times(tt.calls)
+
+			c := NewClient(mockClient)
+			if gotOk := c.alter(tt.line); gotOk != tt.wantOk {
+				t.Errorf("alter() = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
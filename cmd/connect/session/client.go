@@ -7,6 +7,7 @@ package session
 import (
 	"bytes"
 	"encoding/csv"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -51,6 +52,10 @@ type APIClient interface {
 	Write(reqs *frontend.MultiWriteRequest, responses *frontend.MultiServerResponse) error
 	// Destroy deletes a bucket from the marketstore server.
 	Destroy(reqs *frontend.MultiKeyRequest, responses *frontend.MultiServerResponse) error
+	// Delete removes the data in a date range from buckets of the marketstore server.
+	Delete(reqs *frontend.MultiDeleteRequest, responses *frontend.MultiServerResponse) error
+	// AlterBucket changes the columns of buckets of the marketstore server.
+	AlterBucket(reqs *frontend.MultiAlterBucketRequest, responses *frontend.MultiServerResponse) error
 	// ProcessShow returns data stored in the marketstore server.
 	Show(tbk *dbio.TimeBucketKey, start, end *time.Time) (csm dbio.ColumnSeriesMap, err error)
 	// GetBucketInfo returns information(datashape, timeframe, record type, etc.) for the specified buckets.
@@ -124,6 +129,8 @@ EVAL:
 			c.create(line)
 		case strings.HasPrefix(line, `\destroy`):
 			c.destroy(line)
+		case strings.HasPrefix(line, `\alter`):
+			c.alter(line)
 		case strings.HasPrefix(line, `\getinfo`):
 			c.getinfo(line)
 		case strings.HasPrefix(line, `\help`) || strings.HasPrefix(line, `\?`):
@@ -158,6 +165,7 @@ func newReader() (*readline.Instance, error) {
 		readline.PcItem(`\show`),
 		readline.PcItem(`\load`),
 		readline.PcItem(`\create`),
+		readline.PcItem(`\alter`),
 		readline.PcItem(`\getinfo`),
 		readline.PcItem(`\trim`),
 		readline.PcItem(`\help`),
@@ -292,6 +300,10 @@ func printResult(queryText string, cs *dbio.ColumnSeries, optionalFile ...string
 					val := col.([]int32)[i]
 					element = strconv.FormatInt(int64(val), decimal)
 				case reflect.Int64:
+					if decimals, ok := col.([]dbio.Decimal64); ok {
+						element = decimals[i].Format(cs.Scale(name))
+						break
+					}
 					val := col.([]int64)[i]
 					element = strconv.FormatInt(val, decimal)
 				case reflect.Uint8:
@@ -316,6 +328,10 @@ func printResult(queryText string, cs *dbio.ColumnSeries, optionalFile ...string
 				case reflect.Array: // string type (e.g. [16]rune)
 					runes := reflect.ValueOf(col).Index(i)
 					element = strings.Trim(runesToString(runes), "\x00") // trim space
+				case reflect.String:
+					element = col.([]string)[i]
+				case reflect.Slice: // blob type, printed in hexadecimal as \load reads it
+					element = hex.EncodeToString(col.([][]byte)[i])
 				}
 				// print column value in the format length
 				l := columnFormatLength(name, col)
@@ -141,7 +141,7 @@ func toColumns(dataShapeStr string) (columnNames, columnTypeStrs []string, err e
 	columnTypeStrs = make([]string, len(dsv))
 	for i, ds := range dsv {
 		columnNames[i] = ds.Name
-		typeStr, ok := io.ToTypeStr(ds.Type) // e.g. i8, f4
+		typeStr, ok := io.DataShapeToTypeStr(ds) // e.g. i8, f4, decimal64(8)
 		if !ok {
 			return nil, nil,
 				fmt.Errorf("type:%v is not supported", ds.Type)
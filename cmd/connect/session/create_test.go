@@ -25,17 +25,20 @@ func TestClient_create(t *testing.T) {
 		line   string
 		resp   *frontend.MultiServerResponse
 		err    error
+		calls  int // The calls reaching the API
 		wantOk bool
 	}{
 		{
 			name:   "success/fixed record",
 			line:   exampleCommandFixed,
+			calls:  1,
 			resp:   &frontend.MultiServerResponse{Responses: []frontend.ServerResponse{}},
 			wantOk: true,
 		},
 		{
 			name:   "success/variable-length record",
 			line:   exampleCommandVariable,
+			calls:  1,
 			resp:   &frontend.MultiServerResponse{Responses: []frontend.ServerResponse{}},
 			wantOk: true,
 		},
@@ -58,12 +61,14 @@ func TestClient_create(t *testing.T) {
 		{
 			name:   "error/rpc Error",
 			line:   exampleCommandFixed,
+			calls:  1,
 			err:    errors.New("error"),
 			wantOk: false,
 		},
 		{
 			name:   "error/Create API error",
 			line:   exampleCommandFixed,
+			calls:  1,
 			resp:   &frontend.MultiServerResponse{Responses: []frontend.ServerResponse{{Error: "API errors!"}}},
 			err:    nil,
 			wantOk: false,
@@ -85,7 +90,7 @@ func TestClient_create(t *testing.T) {
 					}
 					return tt.err
 				},
-			)
+			).Times(tt.calls)
 
 			c := NewClient(mockClient)
 			if gotOk := c.create(tt.line); gotOk != tt.wantOk {
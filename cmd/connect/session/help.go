@@ -23,7 +23,7 @@ func (c *Client) functionHelp(line string) {
 		fmt.Println(`
 		Usage: \help command_name
 
-		Available commands: o, timing, show, trim, gaps, load, create, destroy, feed`)
+		Available commands: o, timing, show, trim, gaps, load, create, alter, destroy, feed`)
 
 	case "o":
 		// nolint:forbidigo // CLI output
@@ -50,7 +50,8 @@ func (c *Client) functionHelp(line string) {
 
 			>> \show TSLA/1Min/OHLCV 2016-09-15 2016-09-16
 
-	trim: removes the data in the date range from the DB
+	trim: removes the data in the date range from the DB, up to the latest data
+	      without an end time. The symbol can be a list or * (e.g. AAPL,TSLA/1Min/OHLCV)
 	show: displays data in the date range
 	gaps: finds gaps in data in the date range`)
 
@@ -115,6 +116,24 @@ func (c *Client) functionHelp(line string) {
 		number of rows:
 			<row-type> = variable`)
 
+	case "alter":
+		// nolint:forbidigo // CLI output
+		fmt.Println(`
+		The alter command changes the columns of an existing bucket, rewriting all its data files.
+		Syntax:
+			>> \alter <partial-schema-key> [add <row-data-shape>] [drop <names>] [retype <row-data-shape>]
+		Example: We add a trade count to the candles of TSLA, remove the volume and store the prices as 64-bit floats:
+			>> \alter TSLA/1Min/OHLCV add Trades/int32 drop Volume retype Open,High,Low,Close/float64
+
+		where:
+
+		add: the new columns, whose values are zero in the existing rows
+		drop: a comma separated list of the columns to remove
+		retype: the columns whose values are converted to a new numeric type
+		(the <row-data-shape> format is the one of the create command)
+
+		The writes to the bucket wait while the files are rewritten.`)
+
 	default:
 		log.Error("No help available for %s\n", helpKey)
 	}
@@ -99,6 +99,17 @@ func (lc *LocalAPIClient) Destroy(reqs *frontend.MultiKeyRequest, responses *fro
 	return ds.Destroy(nil, reqs, responses)
 }
 
+func (lc *LocalAPIClient) Delete(reqs *frontend.MultiDeleteRequest, responses *frontend.MultiServerResponse) error {
+	ds := frontend.NewDataService(lc.dir, lc.catalogDir, lc.aggRunner, lc.writer, lc.query)
+	return ds.Delete(nil, reqs, responses)
+}
+
+func (lc *LocalAPIClient) AlterBucket(reqs *frontend.MultiAlterBucketRequest, responses *frontend.MultiServerResponse,
+) error {
+	ds := frontend.NewDataService(lc.dir, lc.catalogDir, lc.aggRunner, lc.writer, lc.query)
+	return ds.AlterBucket(nil, reqs, responses)
+}
+
 func (lc *LocalAPIClient) GetBucketInfo(reqs *frontend.MultiKeyRequest, responses *frontend.MultiGetInfoResponse,
 ) error {
 	ds := frontend.NewDataService(lc.dir, lc.catalogDir, lc.aggRunner, lc.writer, lc.query)
@@ -36,6 +36,20 @@ func (m *MockAPIClient) EXPECT() *MockAPIClientMockRecorder {
 	return m.recorder
 }
 
+// AlterBucket mocks base method.
+func (m *MockAPIClient) AlterBucket(arg0 *frontend.MultiAlterBucketRequest, arg1 *frontend.MultiServerResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AlterBucket", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AlterBucket indicates an expected call of AlterBucket.
+func (mr *MockAPIClientMockRecorder) AlterBucket(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AlterBucket", reflect.TypeOf((*MockAPIClient)(nil).AlterBucket), arg0, arg1)
+}
+
 // Create mocks base method.
 func (m *MockAPIClient) Create(arg0 *frontend.MultiCreateRequest, arg1 *frontend.MultiServerResponse) error {
 	m.ctrl.T.Helper()
@@ -50,6 +64,20 @@ func (mr *MockAPIClientMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIClient)(nil).Create), arg0, arg1)
 }
 
+// Delete mocks base method.
+func (m *MockAPIClient) Delete(arg0 *frontend.MultiDeleteRequest, arg1 *frontend.MultiServerResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockAPIClientMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockAPIClient)(nil).Delete), arg0, arg1)
+}
+
 // Destroy mocks base method.
 func (m *MockAPIClient) Destroy(arg0 *frontend.MultiKeyRequest, arg1 *frontend.MultiServerResponse) error {
 	m.ctrl.T.Helper()
@@ -113,6 +113,40 @@ func (rc *RemoteAPIClient) Destroy(reqs *frontend.MultiKeyRequest, responses *fr
 	return nil
 }
 
+func (rc *RemoteAPIClient) Delete(reqs *frontend.MultiDeleteRequest, responses *frontend.MultiServerResponse) error {
+	var respI interface{}
+	respI, err := rc.rpcClient.DoRPC("Delete", reqs)
+	if err != nil {
+		return fmt.Errorf("DoRPC:Delete error:%w", err)
+	}
+	if respI != nil {
+		if val, ok := respI.(*frontend.MultiServerResponse); ok {
+			*responses = *val
+		} else {
+			return fmt.Errorf("[bug] unexpected data type returned from DoRPC:Delete func. resp=%v", respI)
+		}
+	}
+	return nil
+}
+
+func (rc *RemoteAPIClient) AlterBucket(reqs *frontend.MultiAlterBucketRequest,
+	responses *frontend.MultiServerResponse,
+) error {
+	var respI interface{}
+	respI, err := rc.rpcClient.DoRPC("AlterBucket", reqs)
+	if err != nil {
+		return fmt.Errorf("DoRPC:AlterBucket error:%w", err)
+	}
+	if respI != nil {
+		if val, ok := respI.(*frontend.MultiServerResponse); ok {
+			*responses = *val
+		} else {
+			return fmt.Errorf("[bug] unexpected data type returned from DoRPC:AlterBucket func. resp=%v", respI)
+		}
+	}
+	return nil
+}
+
 func (rc *RemoteAPIClient) GetBucketInfo(reqs *frontend.MultiKeyRequest, responses *frontend.MultiGetInfoResponse,
 ) error {
 	var respI interface{}
@@ -1,52 +1,55 @@
 package session
 
 import (
-	io2 "io"
-	"os"
 	"strings"
 
-	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/planner"
 	"github.com/alpacahq/marketstore/v4/utils/log"
 )
 
 // trim removes the data in the date range from the db.
-// Note that this is implemented only for LocalClient.
 func (c *Client) trim(line string) {
-	cli, ok := c.apiClient.(*LocalAPIClient)
-	if !ok {
-		log.Error("Trim command can be used only when '--dir' is specified to connect marketstore.")
+	args := strings.Split(line, " ")
+	args = args[1:]
+	// need bucket name and date
+	const argLen = 1
+	if !(len(args) > argLen) {
+		log.Error("Not enough arguments, see \"\\help trim\" ")
 		return
 	}
-
-	log.Info("Trimming...")
-	args := strings.Split(line, " ")
-	// need \trim {key} {date}
-	const argLen = 3
-	if len(args) < argLen {
-		log.Error("Not enough arguments - need \"trim key date\"")
+	tbk, start, end := c.parseQueryArgs(args)
+	if tbk == nil {
+		log.Error(`Could not parse arguments, see "\help trim" `)
 		return
 	}
-	trimDate, err := parseTime(args[len(args)-1])
-	if err != nil {
-		log.Error("Failed to parse trim date - Error: %v", trimDate)
+	// Without an end time, the data is removed up to the latest record
+	if end == nil {
+		end = &planner.MaxTime
 	}
 
-	const ownerReadWritePerm = 0o600
-	fInfos := cli.catalogDir.GatherTimeBucketInfo()
-	for _, info := range fInfos {
-		if info.Year != int16(trimDate.Year()) {
-			continue
-		}
+	reqs := &frontend.MultiDeleteRequest{
+		Requests: []frontend.DeleteRequest{{
+			Key:             args[0],
+			EpochStart:      start.Unix(),
+			EpochStartNanos: int64(start.Nanosecond()),
+			EpochEnd:        end.Unix(),
+			EpochEndNanos:   int64(end.Nanosecond()),
+		}},
+	}
+	responses := &frontend.MultiServerResponse{}
 
-		offset := io.TimeToOffset(trimDate, info.GetTimeframe(), info.GetRecordLength())
-		fp, err := os.OpenFile(info.Path, os.O_CREATE|os.O_RDWR, ownerReadWritePerm)
-		if err != nil {
-			log.Error("Failed to open file %v - Error: %v", info.Path, err)
-			continue
+	log.Info("Trimming...")
+	err := c.apiClient.Delete(reqs, responses)
+	if err != nil {
+		log.Error("Failed with error: %s\n", err.Error())
+		return
+	}
+	for _, resp := range responses.Responses {
+		if resp.Error != "" {
+			log.Error("Failed with error: %s\n", resp.Error)
+			return
 		}
-		fp.Seek(offset, io2.SeekStart)
-		zeroes := make([]byte, io.FileSize(info.GetTimeframe(), int(info.Year), int(info.GetRecordLength()))-offset)
-		fp.Write(zeroes)
-		fp.Close()
 	}
+	log.Info("Successfully removed the data of %s in the date range\n", args[0])
 }
@@ -21,7 +21,11 @@ import (
 	"google.golang.org/grpc/credentials"
 
 	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/executor/tiered"
 	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/frontend/flight"
+	"github.com/alpacahq/marketstore/v4/frontend/pgwire"
 	"github.com/alpacahq/marketstore/v4/frontend/stream"
 	"github.com/alpacahq/marketstore/v4/metrics"
 	"github.com/alpacahq/marketstore/v4/plugins/trigger"
@@ -131,6 +135,10 @@ func executeStart(cmd *cobra.Command, _ []string) error {
 	start := time.Now()
 
 	triggerMatchers := trigger.NewTriggerMatchers(config.Triggers)
+	remoteStorage, err := tiered.NewFromSetting(config.TieredStorage, config.RootDirectory)
+	if err != nil {
+		return fmt.Errorf("init tiered storage: %w", err)
+	}
 	instanceConfig, shutdownPending, walWG, err := executor.NewInstanceSetup(
 		config.RootDirectory,
 		rs,
@@ -140,12 +148,20 @@ func executeStart(cmd *cobra.Command, _ []string) error {
 		executor.InitWALCache(config.InitWALCache),
 		executor.BackgroundSync(config.BackgroundSync),
 		executor.WALBypass(config.WALBypass),
+		executor.RemoteStorage(remoteStorage),
 	)
 	if err != nil {
 		return fmt.Errorf("craete new instance setup: %w", err)
 	}
 
-	go metrics.StartDiskUsageMonitor(metrics.TotalDiskUsageBytes, config.RootDirectory, diskUsageMonitorInterval)
+	go metrics.StartDiskUsageMonitor(metrics.TotalDiskUsageBytes.WithLabelValues("local"), config.RootDirectory,
+		diskUsageMonitorInterval)
+	if remoteStorage != nil {
+		go metrics.StartDiskUsageMonitor(metrics.TotalDiskUsageBytes.WithLabelValues("cache"),
+			remoteStorage.CacheDir(), diskUsageMonitorInterval)
+		go metrics.StartUsageMonitor(metrics.TotalDiskUsageBytes.WithLabelValues("remote"),
+			func() int64 { return tiered.RemoteUsage(config.RootDirectory) }, diskUsageMonitorInterval)
+	}
 
 	startupTime := time.Since(start)
 	metrics.StartupTime.Set(startupTime.Seconds())
@@ -157,14 +173,37 @@ func executeStart(cmd *cobra.Command, _ []string) error {
 	// init QueryService
 	qs := frontend.NewQueryService(instanceConfig.CatalogDir)
 
+	// the guard is nil when the authentication is disabled, it lets every request through
+	guard, err := auth.NewFromSetting(config.Auth)
+	if err != nil {
+		return fmt.Errorf("init authentication: %w", err)
+	}
+	if guard != nil {
+		log.Info("enabling the authentication of the API requests...")
+	}
+
 	// New grpc server for marketstore API.
-	grpcServer := grpc.NewServer(
+	grpcOpts := []grpc.ServerOption{
 		grpc.MaxSendMsgSize(config.GRPCMaxSendMsgSize),
 		grpc.MaxRecvMsgSize(config.GRPCMaxRecvMsgSize),
-	)
+		grpc.UnaryInterceptor(guard.UnaryServerInterceptor),
+		grpc.StreamInterceptor(guard.StreamServerInterceptor),
+	}
+	tlsOpt, err := auth.GRPCCredentials(config.Auth.GRPCTLS)
+	if err != nil {
+		return fmt.Errorf("init grpc TLS: %w", err)
+	}
+	if tlsOpt != nil {
+		grpcOpts = append(grpcOpts, tlsOpt)
+	}
+	grpcServer := grpc.NewServer(grpcOpts...)
 
 	// init writer
-	var server *frontend.RPCServer
+	var (
+		server       *frontend.RPCServer
+		dataService  *frontend.DataService
+		writeHandler *frontend.WriteHandler
+	)
 	writer, err := executor.NewWriter(instanceConfig.CatalogDir, instanceConfig.WALFile)
 	if err != nil {
 		return fmt.Errorf("init writer: %w", err)
@@ -191,32 +230,56 @@ func executeStart(cmd *cobra.Command, _ []string) error {
 		// New server.
 		// WRITE is not allowed on a replica
 		errorWriter := &executor.ErrorWriter{}
-		server, _ = frontend.NewServer(config.RootDirectory, instanceConfig.CatalogDir, aggRunner, errorWriter, qs)
+		server, dataService = frontend.NewServer(config.RootDirectory, instanceConfig.CatalogDir, aggRunner,
+			errorWriter, qs)
 
 		// register grpc server
 		pb.RegisterMarketstoreServer(grpcServer,
 			frontend.NewGRPCService(config.RootDirectory,
 				instanceConfig.CatalogDir, aggRunner, errorWriter, qs),
 		)
+		flight.RegisterFlightServiceServer(grpcServer, frontend.NewFlightService(instanceConfig.CatalogDir, errorWriter))
+		writeHandler = frontend.NewWriteHandler(instanceConfig.CatalogDir, errorWriter, config.WriteAutoCreate)
 	} else {
 		// New server.
-		server, _ = frontend.NewServer(config.RootDirectory, instanceConfig.CatalogDir, aggRunner, writer, qs)
+		server, dataService = frontend.NewServer(config.RootDirectory, instanceConfig.CatalogDir, aggRunner, writer, qs)
+
+		// the replicas remove the expired rows with the primary
+		if len(config.Retention.Policies) > 0 {
+			log.Info("launching the retention of %d bucket patterns (dry run: %v)...",
+				len(config.Retention.Policies), config.Retention.DryRun)
+			go executor.NewRetention(writer, config.Retention).Run(globalCtx)
+		}
 
 		// register grpc server
 		pb.RegisterMarketstoreServer(grpcServer,
 			frontend.NewGRPCService(config.RootDirectory,
 				instanceConfig.CatalogDir, aggRunner, writer, qs),
 		)
+		flight.RegisterFlightServiceServer(grpcServer, frontend.NewFlightService(instanceConfig.CatalogDir, writer))
+		writeHandler = frontend.NewWriteHandler(instanceConfig.CatalogDir, writer, config.WriteAutoCreate)
+	}
+
+	// the compaction only rewrites the local files, the replicas compact theirs
+	if config.Compaction.Interval > 0 {
+		log.Info("launching the compaction of the variable length buckets every %v...", config.Compaction.Interval)
+		go executor.NewCompactor(writer, config.Compaction).Run(globalCtx)
 	}
 
 	// Set rpc handler.
 	log.Info("launching rpc data server...")
-	http.Handle("/rpc", server)
+	http.Handle("/rpc", guard.Handler(server))
+
+	// Set streamed query handler.
+	http.Handle("/query/stream", guard.Handler(frontend.NewQueryStreamHandler(instanceConfig.CatalogDir)))
+
+	// Set line protocol and CSV write handler.
+	http.Handle("/write", guard.Handler(writeHandler))
 
 	// Set websocket handler.
 	log.Info("initializing websocket...")
 	stream.Initialize()
-	http.HandleFunc("/ws", stream.Handler)
+	http.Handle("/ws", guard.Handler(stream.NewHandler(guard.CheckOrigin)))
 
 	// Set monitoring handler.
 	log.Info("launching prometheus metrics server...")
@@ -256,6 +319,31 @@ func executeStart(cmd *cobra.Command, _ []string) error {
 		}()
 	}
 
+	var pgServer *pgwire.Server
+	if config.PGListenURL != "" {
+		pgLn, err2 := net.Listen("tcp", config.PGListenURL)
+		if err2 != nil {
+			return fmt.Errorf("failed to start PostgreSQL server - error: %w", err2)
+		}
+		var pgTLS *tls.Config
+		if config.Auth.PGTLS.CertFile != "" {
+			cert, err3 := tls.LoadX509KeyPair(config.Auth.PGTLS.CertFile, config.Auth.PGTLS.KeyFile)
+			if err3 != nil {
+				return fmt.Errorf("failed to load the certificate of the PostgreSQL server: %w", err3)
+			}
+			pgTLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		} else if guard != nil {
+			log.Warn("pg_tls is not set, the PostgreSQL clients can not authenticate")
+		}
+		log.Info("launching PostgreSQL wire protocol server...")
+		pgServer = pgwire.NewServer(dataService, guard, pgTLS)
+		go func() {
+			if err3 := pgServer.Serve(pgLn); err3 != nil {
+				log.Error("PostgreSQL server error: %v", err3.Error())
+			}
+		}()
+	}
+
 	// Spawn a goroutine and listen for a signal.
 	const defaultSignalChanLen = 10
 	signalChan := make(chan os.Signal, defaultSignalChanLen)
@@ -275,6 +363,10 @@ func executeStart(cmd *cobra.Command, _ []string) error {
 				log.Info("initiating graceful shutdown due to '%v' request", s)
 				grpcServer.GracefulStop()
 				log.Info("shutdown grpc API server...")
+				if pgServer != nil {
+					pgServer.Close()
+					log.Info("shutdown PostgreSQL server...")
+				}
 				globalCancel()
 				if grpcReplicationServer != nil {
 					grpcReplicationServer.Stop() // gRPC stream connection doesn't close by GracefulStop()
@@ -354,7 +446,8 @@ func initReplicationClient(ctx context.Context, masterHost, rootDir string, tlsE
 
 	c := replication.NewGRPCReplicationClient(pb.NewReplicationClient(conn))
 
-	replayer := replication.NewReplayer(executor.ParseTGData, w.WriteCSM, rootDir)
+	replayer := replication.NewReplayer(executor.ParseTGData, w.WriteCSM, w.Delete, w.AlterDataShapes, w.DropYear,
+		rootDir)
 	replicationReceiver := replication.NewReceiver(c, replayer)
 
 	go func() {
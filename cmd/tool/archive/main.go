@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+const (
+	usage = "archive"
+	short = "Convert closed years into the compressed archive format and back"
+	long  = `This command converts the year files of closed years into read-only, compressed columnar archive
+files, which are read transparently by queries. With --restore, the archive files are converted back
+into year files, so that they can be written again.
+
+The marketstore server using the directory must be stopped (after a clean shutdown) while converting.`
+	example = "marketstore tool archive --config <path> --key 'AAPL,MSFT/1Min/*' --year 2019 [--restore]"
+
+	// Flag descriptions.
+	configDesc  = "set the path for the marketstore YAML configuration file"
+	keyDesc     = "set the bucket key to convert, each item can be a comma separated list of glob patterns"
+	yearDesc    = "set the year to convert"
+	restoreDesc = "convert the archive files back into year files"
+
+	defaultConfigFilePath = "./mkts.yml"
+)
+
+var (
+	// Cmd is the archive command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeArchive,
+	}
+
+	// Available flags.
+	configFilePath string
+	key            string
+	year           int
+	restore        bool
+)
+
+// nolint:gochecknoinits // cobra's standard way to initialize flags
+func init() {
+	Cmd.Flags().StringVarP(&configFilePath, "config", "c", defaultConfigFilePath, configDesc)
+	Cmd.Flags().StringVarP(&key, "key", "k", "", keyDesc)
+	Cmd.Flags().IntVarP(&year, "year", "y", 0, yearDesc)
+	Cmd.Flags().BoolVar(&restore, "restore", false, restoreDesc)
+	Cmd.MarkFlagRequired("key")
+	Cmd.MarkFlagRequired("year")
+}
+
+// executeArchive implements the archive command.
+func executeArchive(cmd *cobra.Command, _ []string) error {
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file error: %w", err)
+	}
+	cmd.SilenceUsage = true
+
+	// the timezone and the compression of the variable length records are the ones of the instance
+	config, err := utils.InstanceConfig.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse configuration file error: %w", err)
+	}
+	if !restore && year >= time.Now().In(config.Timezone).Year() {
+		return fmt.Errorf("year %d is not closed yet, only the past years can be archived", year)
+	}
+
+	d, err := catalog.NewDirectory(config.RootDirectory)
+	if err != nil {
+		var e catalog.ErrCategoryFileNotFound
+		if !errors.As(err, &e) {
+			return fmt.Errorf("failed to load the catalog of %s: %w", config.RootDirectory, err)
+		}
+	}
+	keys := catalog.MatchTimeBucketKeys(d, io.NewTimeBucketKey(key))
+	if len(keys) == 0 {
+		return fmt.Errorf("no bucket matches %s", key)
+	}
+
+	for _, tbk := range keys {
+		tbi := yearFile(config.RootDirectory, tbk)
+		switch {
+		case tbi == nil:
+			log.Info("%s has no file for year %d, skipped", tbk.String(), year)
+			continue
+		case restore && !tbi.IsArchived(), !restore && tbi.IsArchived():
+			log.Info("%s is already converted, skipped", tbi.Path)
+			continue
+		case restore:
+			err = executor.RestoreYear(tbi)
+		default:
+			err = executor.ArchiveYear(tbi)
+		}
+		if err != nil {
+			return err
+		}
+		log.Info("converted %s", tbi.Path)
+	}
+	return nil
+}
+
+// yearFile returns the file of the year of a bucket, the year file taking precedence
+// over the archive file like in the catalog.
+func yearFile(rootDir string, tbk *io.TimeBucketKey) *io.TimeBucketInfo {
+	for _, ext := range []string{io.YearFileExt, io.ArchiveFileExt} {
+		path := filepath.Join(tbk.GetPathToYearFiles(rootDir), strconv.Itoa(year)+ext)
+		if _, err := os.Stat(path); err == nil {
+			return &io.TimeBucketInfo{Year: int16(year), Path: path}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/client"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+const (
+	usage = "backup"
+	short = "Make an online backup of a running marketstore server"
+	long  = `This command asks a running marketstore server to copy its root directory into a new backup
+directory, in the backup_directory of its configuration, while the writes go on. The backup can be
+restored with the restore tool.`
+	example = "marketstore tool backup --url localhost:5993 [--name <backup directory name>]"
+
+	// Flag descriptions.
+	urlDesc   = "set the hostname:port of the marketstore server"
+	nameDesc  = "set the name of the backup directory, \"backup-{UTC time}\" by default"
+	tokenDesc = "set the API key or the signed token of a server with the authentication enabled"
+
+	defaultURL = "localhost:5993"
+)
+
+var (
+	// Cmd is the backup command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeBackup,
+	}
+
+	// Available flags.
+	url   string
+	name  string
+	token string
+)
+
+// nolint:gochecknoinits // cobra's standard way to initialize flags
+func init() {
+	Cmd.Flags().StringVarP(&url, "url", "u", defaultURL, urlDesc)
+	Cmd.Flags().StringVarP(&name, "name", "n", "", nameDesc)
+	Cmd.Flags().StringVar(&token, "token", "", tokenDesc)
+}
+
+// executeBackup implements the backup command.
+func executeBackup(cmd *cobra.Command, _ []string) error {
+	if strings.Count(url, ":") != 1 {
+		return fmt.Errorf("incorrect URL, need \"hostname:port\", have: %s", url)
+	}
+	cmd.SilenceUsage = true
+
+	cl, err := client.NewClient("http://" + url)
+	if err != nil {
+		return err
+	}
+	cl.Token = token
+	resp, err := cl.DoRPC("Backup", &frontend.BackupRequest{Name: name})
+	if err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+	result, ok := resp.(*frontend.BackupResponse)
+	if !ok {
+		return errors.New("unexpected backup response")
+	}
+	log.Info("backed up %d files (%d bytes) to %s, up to transaction group %d",
+		result.Files, result.Bytes, result.Path, result.TGID)
+	return nil
+}
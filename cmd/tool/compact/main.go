@@ -0,0 +1,93 @@
+package compact
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/client"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+const (
+	usage = "compact"
+	short = "Reclaim the dead space of the variable length buckets of a running marketstore server"
+	long  = `This command asks a running marketstore server to compact the year files of variable length buckets.
+Each write to an interval of a variable length bucket appends all its rows at the end of the year file, the
+ones previously written become dead space. The compaction rewrites the year files with only their live rows,
+while the writes are suspended and the reads go on. The archived and the offloaded years are skipped.
+
+The server also compacts the year files in the background at each compaction_interval of its configuration.`
+	example = "marketstore tool compact --url localhost:5993 --key '*/1Sec/TICK' [--year 2021] [--min-dead-ratio 0.2]"
+
+	// Flag descriptions.
+	urlDesc          = "set the hostname:port of the marketstore server"
+	keyDesc          = "set the bucket key to compact, each item can be a comma separated list of glob patterns"
+	yearDesc         = "set the year to compact, all the years of the buckets are compacted if not set"
+	minDeadRatioDesc = "skip the year files whose dead space is less than this part of their rows"
+	tokenDesc        = "set the API key or the signed token of a server with the authentication enabled"
+
+	defaultURL = "localhost:5993"
+)
+
+var (
+	// Cmd is the compact command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeCompact,
+	}
+
+	// Available flags.
+	url          string
+	key          string
+	year         int16
+	minDeadRatio float64
+	token        string
+)
+
+// nolint:gochecknoinits // cobra's standard way to initialize flags
+func init() {
+	Cmd.Flags().StringVarP(&url, "url", "u", defaultURL, urlDesc)
+	Cmd.Flags().StringVarP(&key, "key", "k", "", keyDesc)
+	Cmd.Flags().Int16VarP(&year, "year", "y", 0, yearDesc)
+	Cmd.Flags().Float64Var(&minDeadRatio, "min-dead-ratio", 0, minDeadRatioDesc)
+	Cmd.Flags().StringVar(&token, "token", "", tokenDesc)
+	Cmd.MarkFlagRequired("key")
+}
+
+// executeCompact implements the compact command.
+func executeCompact(cmd *cobra.Command, _ []string) error {
+	if strings.Count(url, ":") != 1 {
+		return fmt.Errorf("incorrect URL, need \"hostname:port\", have: %s", url)
+	}
+	cmd.SilenceUsage = true
+
+	cl, err := client.NewClient("http://" + url)
+	if err != nil {
+		return err
+	}
+	cl.Token = token
+	resp, err := cl.DoRPC("Compact", &frontend.CompactRequest{Key: key, Year: year, MinDeadRatio: minDeadRatio})
+	if err != nil {
+		return fmt.Errorf("compaction failed: %w", err)
+	}
+	result, ok := resp.(*frontend.CompactResponse)
+	if !ok {
+		return errors.New("unexpected compact response")
+	}
+	for _, r := range result.Results {
+		if r.Compacted {
+			log.Info("%s %d: compacted from %d to %d bytes", r.Key, r.Year, r.SizeBefore, r.SizeAfter)
+		} else {
+			log.Info("%s %d: skipped, %d dead bytes in %d", r.Key, r.Year, r.DeadBytes, r.SizeBefore)
+		}
+	}
+	log.Info("compacted %d year files, %d bytes reclaimed", len(result.Results), result.Reclaimed)
+	return nil
+}
@@ -0,0 +1,136 @@
+package index
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/executor/blockindex"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+const (
+	usage = "index"
+	short = "Build the block indexes of year files, or drop them"
+	long  = `This command builds the block index of year files: the number of rows and the minimum and maximum
+of each numeric column of the blocks of the year, with the intervals holding a row in a fixed bucket.
+Queries use it to skip the blocks that can not match the WHERE bounds of their columns, and the blocks
+without rows. Once a year is indexed, the writes keep its index up to date, and the next years of the
+bucket are indexed when they are created. With --drop, the block indexes are removed.
+
+The marketstore server using the directory must be stopped (after a clean shutdown) while indexing.`
+	example = "marketstore tool index --config <path> --key 'AAPL,MSFT/1Min/*' [--year 2019] [--drop]"
+
+	// Flag descriptions.
+	configDesc = "set the path for the marketstore YAML configuration file"
+	keyDesc    = "set the bucket key to index, each item can be a comma separated list of glob patterns"
+	yearDesc   = "set the year to index, all the years of the buckets are indexed if not set"
+	dropDesc   = "remove the block indexes instead of building them"
+
+	defaultConfigFilePath = "./mkts.yml"
+)
+
+var (
+	// Cmd is the index command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeIndex,
+	}
+
+	// Available flags.
+	configFilePath string
+	key            string
+	year           int
+	drop           bool
+)
+
+// nolint:gochecknoinits // cobra's standard way to initialize flags
+func init() {
+	Cmd.Flags().StringVarP(&configFilePath, "config", "c", defaultConfigFilePath, configDesc)
+	Cmd.Flags().StringVarP(&key, "key", "k", "", keyDesc)
+	Cmd.Flags().IntVarP(&year, "year", "y", 0, yearDesc)
+	Cmd.Flags().BoolVar(&drop, "drop", false, dropDesc)
+	Cmd.MarkFlagRequired("key")
+}
+
+// executeIndex implements the index command.
+func executeIndex(cmd *cobra.Command, _ []string) error {
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file error: %w", err)
+	}
+	cmd.SilenceUsage = true
+
+	// the timezone and the compression of the variable length records are the ones of the instance
+	config, err := utils.InstanceConfig.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse configuration file error: %w", err)
+	}
+
+	d, err := catalog.NewDirectory(config.RootDirectory)
+	if err != nil {
+		var e catalog.ErrCategoryFileNotFound
+		if !errors.As(err, &e) {
+			return fmt.Errorf("failed to load the catalog of %s: %w", config.RootDirectory, err)
+		}
+	}
+	keys := catalog.MatchTimeBucketKeys(d, io.NewTimeBucketKey(key))
+	if len(keys) == 0 {
+		return fmt.Errorf("no bucket matches %s", key)
+	}
+
+	done := "built"
+	if drop {
+		done = "dropped"
+	}
+	for _, tbk := range keys {
+		for _, tbi := range yearFiles(config.RootDirectory, tbk) {
+			if drop {
+				err = os.Remove(blockindex.PathOf(tbi.Path))
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+			} else {
+				err = executor.BuildBlockIndex(tbi)
+			}
+			if err != nil {
+				return err
+			}
+			log.Info("%s the block index of %s", done, tbi.Path)
+		}
+	}
+	return nil
+}
+
+// yearFiles returns the files of the years of a bucket, or of the year of the flag if it is set,
+// the year file taking precedence over the archive file like in the catalog.
+func yearFiles(rootDir string, tbk *io.TimeBucketKey) (files []*io.TimeBucketInfo) {
+	dir := tbk.GetPathToYearFiles(rootDir)
+	years := map[int16]string{}
+	for _, ext := range []string{io.ArchiveFileExt, io.YearFileExt} {
+		paths, _ := filepath.Glob(filepath.Join(dir, "*"+ext))
+		for _, path := range paths {
+			y, err := strconv.Atoi(filepath.Base(path[:len(path)-len(ext)]))
+			if err == nil && (year == 0 || y == year) {
+				years[int16(y)] = path
+			}
+		}
+	}
+	for y, path := range years {
+		files = append(files, &io.TimeBucketInfo{Year: y, Path: path})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Year < files[j].Year })
+	return files
+}
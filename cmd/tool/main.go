@@ -3,7 +3,13 @@ package tool
 import (
 	"github.com/spf13/cobra"
 
+	"github.com/alpacahq/marketstore/v4/cmd/tool/archive"
+	"github.com/alpacahq/marketstore/v4/cmd/tool/backup"
+	"github.com/alpacahq/marketstore/v4/cmd/tool/compact"
+	"github.com/alpacahq/marketstore/v4/cmd/tool/index"
 	"github.com/alpacahq/marketstore/v4/cmd/tool/integrity"
+	"github.com/alpacahq/marketstore/v4/cmd/tool/offload"
+	"github.com/alpacahq/marketstore/v4/cmd/tool/restore"
 	"github.com/alpacahq/marketstore/v4/cmd/tool/wal"
 )
 
@@ -19,12 +25,18 @@ var Cmd = &cobra.Command{
 	Use:        usage,
 	Short:      short,
 	Long:       long,
-	SuggestFor: []string{"wal", "integrity"},
+	SuggestFor: []string{"wal", "integrity", "archive", "index", "backup", "restore", "offload", "compact"},
 	Example:    example,
 }
 
 // nolint:gochecknoinits // cobra's standard way to initialize flags
 func init() {
+	Cmd.AddCommand(archive.Cmd)
+	Cmd.AddCommand(backup.Cmd)
+	Cmd.AddCommand(compact.Cmd)
+	Cmd.AddCommand(index.Cmd)
 	Cmd.AddCommand(integrity.Cmd)
+	Cmd.AddCommand(offload.Cmd)
+	Cmd.AddCommand(restore.Cmd)
 	Cmd.AddCommand(wal.Cmd)
 }
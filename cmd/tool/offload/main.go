@@ -0,0 +1,148 @@
+package offload
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor/tiered"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+const (
+	usage = "offload"
+	short = "Offload old years to the tiered storage, or recall them"
+	long  = `This command uploads the year files (or archive files) of the years older than the
+offload_after_years of the tiered_storage configuration to its blob store, and replaces them by small
+stubs. The offloaded years are read-only, and they are downloaded into the cache directory when they
+are read. With --recall, the offloaded years are downloaded back into the root directory, so that they
+can be written again.
+
+The marketstore server using the directory must be stopped (after a clean shutdown) while offloading.`
+	example = "marketstore tool offload --config <path> [--key 'AAPL,MSFT/1Min/*'] [--year 2010] [--recall]"
+
+	// Flag descriptions.
+	configDesc = "set the path for the marketstore YAML configuration file"
+	keyDesc    = "set the bucket key to offload, each item can be a comma separated list of glob patterns"
+	yearDesc   = "set the year to offload, all the years older than offload_after_years are offloaded if not set"
+	recallDesc = "download the offloaded years back into the root directory"
+
+	defaultConfigFilePath = "./mkts.yml"
+	defaultKey            = "*/*/*"
+)
+
+var (
+	// Cmd is the offload command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeOffload,
+	}
+
+	// Available flags.
+	configFilePath string
+	key            string
+	year           int
+	recall         bool
+)
+
+// nolint:gochecknoinits // cobra's standard way to initialize flags
+func init() {
+	Cmd.Flags().StringVarP(&configFilePath, "config", "c", defaultConfigFilePath, configDesc)
+	Cmd.Flags().StringVarP(&key, "key", "k", defaultKey, keyDesc)
+	Cmd.Flags().IntVarP(&year, "year", "y", 0, yearDesc)
+	Cmd.Flags().BoolVar(&recall, "recall", false, recallDesc)
+}
+
+// executeOffload implements the offload command.
+func executeOffload(cmd *cobra.Command, _ []string) error {
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file error: %w", err)
+	}
+	cmd.SilenceUsage = true
+
+	config, err := utils.InstanceConfig.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse configuration file error: %w", err)
+	}
+	storage, err := tiered.NewFromSetting(config.TieredStorage, config.RootDirectory)
+	if err != nil {
+		return fmt.Errorf("failed to initialize the tiered storage: %w", err)
+	} else if storage == nil {
+		return errors.New("the tiered storage is not configured, tiered_storage.backend is not set")
+	}
+	// the years up to lastYear are offloaded
+	lastYear := time.Now().In(config.Timezone).Year() - config.TieredStorage.OffloadAfterYears
+	if !recall && year > lastYear {
+		return fmt.Errorf("year %d is not older than %d years, only the years up to %d can be offloaded",
+			year, config.TieredStorage.OffloadAfterYears, lastYear)
+	}
+
+	d, err := catalog.NewDirectory(config.RootDirectory)
+	if err != nil {
+		var e catalog.ErrCategoryFileNotFound
+		if !errors.As(err, &e) {
+			return fmt.Errorf("failed to load the catalog of %s: %w", config.RootDirectory, err)
+		}
+	}
+	keys := catalog.MatchTimeBucketKeys(d, io.NewTimeBucketKey(key))
+	if len(keys) == 0 {
+		return fmt.Errorf("no bucket matches %s", key)
+	}
+
+	done := "offloaded"
+	if recall {
+		done = "recalled"
+	}
+	for _, tbk := range keys {
+		for _, tbi := range yearFiles(config.RootDirectory, tbk) {
+			switch {
+			case year != 0 && int(tbi.Year) != year, year == 0 && int(tbi.Year) > lastYear:
+				continue
+			case recall && !tbi.IsRemote(), !recall && tbi.IsRemote():
+				continue
+			case recall:
+				err = storage.Recall(tbi)
+			default:
+				err = storage.Offload(tbi)
+			}
+			if err != nil {
+				return err
+			}
+			log.Info("%s %s", done, tbi.Path)
+		}
+	}
+	return nil
+}
+
+// yearFiles returns the files of the years of a bucket, the year file taking precedence over the archive file
+// and the stub of an offloaded year like in the catalog.
+func yearFiles(rootDir string, tbk *io.TimeBucketKey) (files []*io.TimeBucketInfo) {
+	dir := tbk.GetPathToYearFiles(rootDir)
+	years := map[int16]string{}
+	for _, ext := range []string{io.RemoteFileExt, io.ArchiveFileExt, io.YearFileExt} {
+		paths, _ := filepath.Glob(filepath.Join(dir, "*"+ext))
+		for _, path := range paths {
+			if y, err := strconv.Atoi(filepath.Base(path[:len(path)-len(ext)])); err == nil {
+				years[int16(y)] = path
+			}
+		}
+	}
+	for y, path := range years {
+		files = append(files, &io.TimeBucketInfo{Year: y, Path: path})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Year < files[j].Year })
+	return files
+}
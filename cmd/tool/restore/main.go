@@ -0,0 +1,75 @@
+package restore
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/utils"
+)
+
+const (
+	usage = "restore"
+	short = "Restore a backup into the root directory of a configuration"
+	long  = `This command verifies the files of a backup made by the backup tool and copies them into the
+root_directory of the configuration, which must not exist or be empty. The transaction groups of the
+WAL tail of the backup and of the --wal files (e.g. the WAL files of the failed instance) are then
+replayed, up to the transaction group --tgid, or all of them by default.
+
+The marketstore server using the root directory must be stopped while restoring.`
+	example = "marketstore tool restore --config <path> --backup <backup directory> [--tgid <id>] [--wal <path>]..."
+
+	// Flag descriptions.
+	configDesc = "set the path for the marketstore YAML configuration file"
+	backupDesc = "set the path of the backup directory"
+	tgidDesc   = "set the ID of the last transaction group to restore, 0 to restore all of them"
+	walDesc    = "set the path of a WAL file to replay after the backup, can be repeated"
+
+	defaultConfigFilePath = "./mkts.yml"
+)
+
+var (
+	// Cmd is the restore command.
+	Cmd = &cobra.Command{
+		Use:     usage,
+		Short:   short,
+		Long:    long,
+		Example: example,
+		RunE:    executeRestore,
+	}
+
+	// Available flags.
+	configFilePath string
+	backupDir      string
+	tgID           int64
+	walFiles       []string
+)
+
+// nolint:gochecknoinits // cobra's standard way to initialize flags
+func init() {
+	Cmd.Flags().StringVarP(&configFilePath, "config", "c", defaultConfigFilePath, configDesc)
+	Cmd.Flags().StringVarP(&backupDir, "backup", "b", "", backupDesc)
+	Cmd.Flags().Int64Var(&tgID, "tgid", 0, tgidDesc)
+	Cmd.Flags().StringArrayVar(&walFiles, "wal", nil, walDesc)
+	Cmd.MarkFlagRequired("backup")
+}
+
+// executeRestore implements the restore command.
+func executeRestore(cmd *cobra.Command, _ []string) error {
+	data, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read configuration file error: %w", err)
+	}
+	cmd.SilenceUsage = true
+
+	// the compression of the variable length records in the WAL is the one of the instance
+	config, err := utils.InstanceConfig.Parse(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse configuration file error: %w", err)
+	}
+
+	_, err = executor.RestoreBackup(backupDir, config.RootDirectory, tgID, walFiles)
+	return err
+}
@@ -107,15 +107,23 @@ func (ca *CandleCandler) Accum(_ io.TimeBucketKey, argMap *functions.ArgumentMap
 			}
 		}
 	}
+	/*
+		The NULL prices are left out of the candles, and the NULL values out of the sums
+	*/
+	priceNulls := ca.PriceNulls(cols, openCols, highCols, lowCols, closeCols)
+	sumNulls := ca.SumNulls(cols)
 	var candle *candler.Candle
 	for i, t := range ts {
 		candle = ca.GetCandle(t, candle)
-		candle.AddCandle(t, open[i], high[i], low[i], close[i])
+		if priceNulls == nil || !priceNulls[i] {
+			candle.AddCandle(t, open[i], high[i], low[i], close[i])
+		}
 		/*
 			Iterate over the candle's named columns that need sums
 		*/
 		for _, name := range ca.AccumSumNames {
-			candle.SumMap[name] += float64(sumCols[name][i])
+			nulls := sumNulls[name]
+			candle.AddSum(name, float64(sumCols[name][i]), nulls != nil && nulls[i])
 		}
 		candle.Count++
 	}
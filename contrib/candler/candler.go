@@ -44,6 +44,7 @@ var (
 
 type Candler struct {
 	uda.AggInterface
+	uda.NullableOutput
 
 	/*
 	   Manages one timeframe, creates candles in that timeframe from
@@ -206,7 +207,59 @@ func (ca *Candler) Output() *io.ColumnSeries {
 	}
 
 	rows := io.NewRows(dataShapes, dataBuf)
-	return rows.ToColumnSeries()
+	cs := rows.ToColumnSeries()
+	if !ca.IsNullable() {
+		return cs
+	}
+	priceNulls := make([]bool, len(tsa))
+	valueNulls := make(map[string][]bool)
+	for _, name := range ca.AccumSumNames {
+		valueNulls[name] = make([]bool, len(tsa))
+	}
+	for i, tkey := range tsa {
+		cdl := ca.CMap[tkey]
+		priceNulls[i] = cdl.OpenTime.IsZero()
+		for _, name := range ca.AccumSumNames {
+			valueNulls[name][i] = cdl.Count == cdl.NullCounts[name]
+		}
+	}
+	for _, name := range []string{"Open", "High", "Low", "Close"} {
+		ca.SetOutputNulls(cs, name, priceNulls...)
+	}
+	for _, name := range ca.SumNames {
+		ca.SetOutputNulls(cs, name+"_SUM", valueNulls[name]...)
+	}
+	for _, name := range ca.AvgNames {
+		ca.SetOutputNulls(cs, name+"_AVG", valueNulls[name]...)
+	}
+	return cs
+}
+
+/*
+SumNulls returns the NULL marks of the summed and averaged input columns that have NULL values, by name.
+*/
+func (ca *Candler) SumNulls(cols io.ColumnInterface) map[string][]bool {
+	nulls := make(map[string][]bool)
+	for _, name := range ca.AccumSumNames {
+		if marks := ca.InputNulls(cols, name); marks != nil {
+			nulls[name] = marks
+		}
+	}
+	return nulls
+}
+
+/*
+PriceNulls returns the NULL marks of the rows whose price, averaged from the input columns srcCols, is NULL,
+or nil if the input columns have no NULL.
+*/
+func (ca *Candler) PriceNulls(cols io.ColumnInterface, srcCols ...[]io.DataShape) []bool {
+	var names []string
+	for _, dsv := range srcCols {
+		for _, ds := range dsv {
+			names = append(names, ds.Name)
+		}
+	}
+	return ca.InputNulls(cols, names...)
 }
 
 func (ca *Candler) GetCandle(t time.Time, cndl ...*Candle) *Candle {
@@ -260,8 +313,9 @@ type Candle struct {
 		Some candles optionally sum quantities like "Volume" from the
 		input columns
 	*/
-	SumMap map[string]float64 // One sum per mapped column
-	Count  int64              // Counts the elements incorporated in the Sums
+	SumMap     map[string]float64 // One sum per mapped column
+	Count      int64              // Counts the elements incorporated in the Sums
+	NullCounts map[string]int64   // Counts the NULL elements left out of each Sum
 	/*
 		Does this candle have complete data? Sometimes we can tell...
 	*/
@@ -280,6 +334,7 @@ func NewCandle(startTime time.Time, cd *utils.CandleDuration, sumColumns, avgCol
 	}
 	if len(sumColumns) != 0 || len(avgColumns) != 0 {
 		ca.SumMap = make(map[string]float64)
+		ca.NullCounts = make(map[string]int64)
 		for _, name := range sumColumns {
 			ca.SumMap[name] = 0
 		}
@@ -340,11 +395,20 @@ func (ca *Candle) SerializeToRowData(sumNames, avgNames []string) (rowBuf []byte
 		rowBuf, _ = io.Serialize(rowBuf, ca.SumMap[name])
 	}
 	for _, name := range avgNames {
-		rowBuf, _ = io.Serialize(rowBuf, ca.SumMap[name]/float64(ca.Count))
+		rowBuf, _ = io.Serialize(rowBuf, ca.SumMap[name]/float64(ca.Count-ca.NullCounts[name]))
 	}
 	return rowBuf
 }
 
+// AddSum adds a value of a summed column to the candle, a NULL value is left out of the sum and the average.
+func (ca *Candle) AddSum(name string, value float64, isNull bool) {
+	if isNull {
+		ca.NullCounts[name]++
+		return
+	}
+	ca.SumMap[name] += value
+}
+
 // CandleMap is a Map of start times to active candles.
 type CandleMap map[time.Time]*Candle
 
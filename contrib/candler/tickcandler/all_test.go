@@ -142,3 +142,31 @@ func createTickBucket(symbol, rootDir string, catalogDir *catalog.Directory, wf
 	}
 	wf.RequestFlush()
 }
+
+func TestTickCandlerNulls(t *testing.T) {
+	t.Parallel()
+	tc := tickcandler.TickCandler{}
+	am := functions.NewArgumentMap(tc.GetRequiredArgs(), tc.GetOptionalArgs()...)
+	am.MapRequiredColumn("CandlePrice", io.DataShape{Name: "Price", Type: io.FLOAT32})
+	am.MapRequiredColumn("Sum", io.DataShape{Name: "Size", Type: io.FLOAT32})
+	am.MapRequiredColumn("Avg", io.DataShape{Name: "Size", Type: io.FLOAT32})
+	cdl, err := tc.New(am, "1Min")
+	assert.Nil(t, err)
+
+	// the second minute only has a NULL price and a NULL size
+	base := time.Date(2016, time.November, 1, 12, 0, 0, 0, time.UTC).Unix()
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{base, base + 10, base + 20, base + 60})
+	cs.AddColumn("Price", []float32{2, 0, 3, 0})
+	cs.SetNulls("Price", []bool{false, true, false, true})
+	cs.AddColumn("Size", []float32{10, 0, 20, 0})
+	cs.SetNulls("Size", []bool{false, true, false, true})
+	out, err := cdl.Accum(io.TimeBucketKey{}, am, cs)
+	assert.Nil(t, err)
+	assert.Equal(t, []float32{2, 0}, out.GetColumn("Open"))
+	assert.Equal(t, []float32{2, 0}, out.GetColumn("Low"))
+	assert.Equal(t, []bool{false, true}, out.Nulls("Close"))
+	assert.Equal(t, []float64{30, 0}, out.GetColumn("Size_SUM"))
+	assert.Equal(t, 15.0, out.GetColumn("Size_AVG").([]float64)[0])
+	assert.Equal(t, []bool{false, true}, out.Nulls("Size_AVG"))
+}
@@ -60,7 +60,7 @@ type OnDiskAggTrigger struct {
 	aggCache *sync.Map
 }
 
-var _ trigger.Trigger = &OnDiskAggTrigger{}
+var _ trigger.DeleteTrigger = &OnDiskAggTrigger{}
 
 func recast(config map[string]interface{}) *Config {
 	data, _ := json.Marshal(config)
@@ -108,11 +108,7 @@ func NewTrigger(conf map[string]interface{}) (trigger.Trigger, error) {
 
 // Fire implements trigger interface.
 func (s *OnDiskAggTrigger) Fire(keyPath string, records []trigger.Record) {
-	elements := strings.Split(keyPath, "/")
-	tf := utils.NewTimeframe(elements[1])
-	fileName := elements[len(elements)-1]
-	year, _ := strconv.Atoi(strings.Replace(fileName, ".bin", "", 1))
-	tbk := io.NewTimeBucketKey(strings.Join(elements[:len(elements)-1], "/"))
+	elements, tf, year, tbk := parseKeyPath(keyPath)
 
 	head := io.IndexToTime(
 		records[0].Index(),
@@ -164,6 +160,58 @@ Query:
 	}
 }
 
+// FireDelete implements trigger.DeleteTrigger, the aggregates of the deleted records are computed again.
+func (s *OnDiskAggTrigger) FireDelete(keyPath string, indexes []int64) {
+	elements, tf, year, tbk := parseKeyPath(keyPath)
+
+	head := io.IndexToTime(indexes[0], tf.Duration, int16(year))
+	tail := io.IndexToTime(indexes[len(indexes)-1], tf.Duration, int16(year))
+
+	// the cached records may have been deleted
+	s.aggCache.Delete(tbk.String())
+
+	for _, dest := range s.destinations {
+		aggTbk := aggregateKey(elements, dest)
+		window := utils.CandleDurationFromString(dest.String)
+		start := window.Truncate(head)
+		end := window.Ceil(tail).Add(-time.Nanosecond)
+		if err := executor.Delete(aggTbk, start, end); err != nil {
+			log.Debug("no %v aggregates to delete (%v)\n", aggTbk.String(), err)
+		}
+	}
+
+	// aggregate the records left in the windows of the deleted ones
+	window := utils.CandleDurationFromString(s.destinations.UpperBound().String)
+	csm, err := s.query(tbk, window, head, tail)
+	if err != nil || csm == nil {
+		log.Error("query error for %v (%v)\n", tbk.String(), err)
+		return
+	}
+
+	if cs := (*csm)[*tbk]; cs != nil && cs.Len() > 0 {
+		s.write(tbk, cs, tail, head, elements)
+	}
+}
+
+// parseKeyPath returns the key elements, timeframe, year and bucket key of a "{Symbol}/{Timeframe}/{AttributeGroup}/{Year}.bin" key path.
+func parseKeyPath(keyPath string) (elements []string, tf *utils.Timeframe, year int, tbk *io.TimeBucketKey) {
+	elements = strings.Split(keyPath, "/")
+	tf = utils.NewTimeframe(elements[1])
+	fileName := elements[len(elements)-1]
+	year, _ = strconv.Atoi(strings.Replace(fileName, ".bin", "", 1))
+	tbk = io.NewTimeBucketKey(strings.Join(elements[:len(elements)-1], "/"))
+	return elements, tf, year, tbk
+}
+
+// aggregateKey returns the key of the bucket of the aggregates of a base bucket.
+func aggregateKey(elements []string, dest utils.Timeframe) *io.TimeBucketKey {
+	attributeGroup := elements[2]
+	if elements[2] == "TRADE" {
+		attributeGroup = "OHLCV"
+	}
+	return io.NewTimeBucketKeyFromString(elements[0] + "/" + dest.String + "/" + attributeGroup)
+}
+
 func (s *OnDiskAggTrigger) write(
 	tbk *io.TimeBucketKey,
 	cs *io.ColumnSeries,
@@ -171,11 +219,7 @@ func (s *OnDiskAggTrigger) write(
 	elements []string) {
 	for _, dest := range s.destinations {
 		symbol := elements[0]
-		attributeGroup := elements[2]
-		if elements[2] == "TRADE" {
-			attributeGroup = "OHLCV"
-		}
-		aggTbk := io.NewTimeBucketKeyFromString(symbol + "/" + dest.String + "/" + attributeGroup)
+		aggTbk := aggregateKey(elements, dest)
 
 		if err := s.writeAggregates(aggTbk, tbk, *cs, dest, head, tail, symbol); err != nil {
 			log.Error(
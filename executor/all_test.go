@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -16,8 +17,10 @@ import (
 
 	. "github.com/alpacahq/marketstore/v4/catalog"
 	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/executor/tiered"
 	. "github.com/alpacahq/marketstore/v4/planner"
 	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/blob"
 	. "github.com/alpacahq/marketstore/v4/utils/io"
 	. "github.com/alpacahq/marketstore/v4/utils/test"
 )
@@ -750,10 +753,864 @@ func TestWriter(t *testing.T) {
 	require.Nil(t, err)
 }
 
+func TestWriteGroup(t *testing.T) {
+	tearDown, _, _, metadata, _ := setup(t, "TestWriteGroup")
+	defer tearDown()
+
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	batch := func(key string, epoch int64, columns ...string) executor.WriteBatch {
+		cs := NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{epoch})
+		for _, name := range columns {
+			cs.AddColumn(name, []float32{1})
+		}
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*NewTimeBucketKey(key), cs)
+		return executor.WriteBatch{CSM: csm}
+	}
+	epoch := time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC).Unix()
+
+	// the batches are committed together, a failed batch does not stop the others
+	tgID, errs, err := writer.WriteGroup([]executor.WriteBatch{
+		batch("TEST-GROUP/1D/OHLCV", epoch, "Close"),
+		batch("TEST-GROUP/1D/OHLCV", epoch+86400, "Close", "Volume"),
+		batch("TEST-GROUP2/1D/OHLCV", epoch, "Close"),
+	})
+	require.Nil(t, err)
+	require.Len(t, errs, 3)
+	assert.Nil(t, errs[0])
+	assert.NotNil(t, errs[1])
+	assert.Nil(t, errs[2])
+	assert.Greater(t, tgID, int64(0))
+
+	q := NewQuery(metadata.CatalogDir)
+	q.AddTargetKey(NewTimeBucketKey("TEST-GROUP,TEST-GROUP2/1D/OHLCV"))
+	q.SetRange(time.Unix(epoch, 0), time.Unix(epoch+2*86400, 0))
+	parsed, err := q.Parse()
+	require.Nil(t, err)
+	reader, err := executor.NewReader(parsed)
+	require.Nil(t, err)
+	csm, err := reader.Read()
+	require.Nil(t, err)
+	assert.Equal(t, 1, csm[*NewTimeBucketKey("TEST-GROUP/1D/OHLCV")].Len())
+	assert.Equal(t, 1, csm[*NewTimeBucketKey("TEST-GROUP2/1D/OHLCV")].Len())
+
+	// the next group has a new transaction group
+	tgID2, _, err := writer.WriteGroup([]executor.WriteBatch{batch("TEST-GROUP/1D/OHLCV", epoch+86400, "Close")})
+	require.Nil(t, err)
+	assert.Greater(t, tgID2, tgID)
+}
+
+func TestWriterDelete(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestWriterDelete")
+	defer tearDown()
+
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+
+	// Delete 3 hours from a list of symbols
+	start := time.Date(2002, time.October, 1, 3, 0, 0, 0, time.UTC)
+	end := time.Date(2002, time.October, 1, 5, 0, 0, 0, time.UTC)
+	err = writer.Delete(NewTimeBucketKey("EURUSD,USDJPY/1H/OHLC"), start, end)
+	require.Nil(t, err)
+
+	q := NewQuery(metadata.CatalogDir)
+	q.AddRestriction("Timeframe", "1H")
+	q.SetRange(start.Add(-3*time.Hour), end.Add(5*time.Hour))
+	parsed, err := q.Parse()
+	require.Nil(t, err)
+	reader, err := executor.NewReader(parsed)
+	require.Nil(t, err)
+	csm, err := reader.Read()
+	require.Nil(t, err)
+	assert.Equal(t, 8, csm[*NewTimeBucketKey("EURUSD/1H/OHLC")].Len())
+	assert.Equal(t, 8, csm[*NewTimeBucketKey("USDJPY/1H/OHLC")].Len())
+	assert.Equal(t, 11, csm[*NewTimeBucketKey("NZDUSD/1H/OHLC")].Len())
+	for _, epoch := range csm[*NewTimeBucketKey("EURUSD/1H/OHLC")].GetEpoch() {
+		assert.False(t, epoch >= start.Unix() && epoch <= end.Unix())
+	}
+
+	// The rows of a variable length interval outside of the range are kept
+	tbk := NewTimeBucketKey("TEST-WD/1Min/TICK-BIDASK")
+	dsv := NewDataShapeVector([]string{"Bid", "Ask"}, []EnumElementType{FLOAT32, FLOAT32})
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(rootDir), "Test",
+		int16(2016), dsv, VARIABLE)
+	require.Nil(t, metadata.CatalogDir.AddTimeBucket(tbk, tbi))
+	row := struct {
+		Epoch    int64
+		Bid, Ask float32
+	}{0, 100, 200}
+	base := time.Date(2016, time.December, 1, 12, 0, 0, 0, time.UTC)
+	for _, sec := range []int{10, 20, 30, 70, 80} {
+		ts := base.Add(time.Duration(sec) * time.Second)
+		row.Epoch = ts.Unix()
+		buffer, _ := Serialize([]byte{}, row)
+		require.Nil(t, writer.WriteRecords([]time.Time{ts}, buffer, dsv, tbi))
+	}
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+
+	q = NewQuery(metadata.CatalogDir)
+	q.AddTargetKey(tbk)
+	q.SetRange(base, base.Add(time.Hour))
+	parsed, err = q.Parse()
+	require.Nil(t, err)
+	reader, err = executor.NewReader(parsed)
+	require.Nil(t, err)
+	csm, err = reader.Read()
+	require.Nil(t, err)
+	before := csm[*tbk]
+	require.Equal(t, 5, before.Len())
+
+	err = writer.Delete(tbk, base.Add(15*time.Second), base.Add(45*time.Second))
+	require.Nil(t, err)
+
+	csm, err = reader.Read()
+	require.Nil(t, err)
+	deleted := []bool{false, true, true, false, false}
+	require.Nil(t, before.RestrictViaBitmap(deleted))
+	assert.Equal(t, before.GetEpoch(), csm[*tbk].GetEpoch())
+	assert.Equal(t, before.GetByName("Nanoseconds"), csm[*tbk].GetByName("Nanoseconds"))
+
+	// Nothing matches the key
+	err = writer.Delete(NewTimeBucketKey("NOPE/1H/OHLC"), start, end)
+	assert.NotNil(t, err)
+}
+
+func TestWriterDeleteAfterWrite(t *testing.T) {
+	rootDir, _ := os.MkdirTemp("", "executor_test-TestWriterDeleteAfterWrite")
+	defer CleanupDummyDataDir(rootDir)
+	// the WAL writer runs in the background, the flushes are asynchronous
+	metadata, shutdownPending, walWG, err := executor.NewInstanceSetup(rootDir, nil, nil, 5)
+	require.Nil(t, err)
+	defer func() {
+		*shutdownPending = true
+		walWG.Wait()
+	}()
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+
+	base := time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC).Unix()
+	row := func(key string, epoch int64) ColumnSeriesMap {
+		cs := NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{epoch})
+		cs.AddColumn("Close", []float32{1})
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*NewTimeBucketKey(key), cs)
+		return csm
+	}
+	require.Nil(t, writer.WriteCSM(row("TEST-DEL/1Min/OHLCV", base), false))
+
+	// the other writers keep a flush pending most of the time
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := int64(0); ; j++ {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				assert.Nil(t, writer.WriteCSM(row(fmt.Sprintf("TEST-OTHER%d/1Min/OHLCV", i), base+60*j), false))
+			}
+		}(i)
+	}
+
+	key := NewTimeBucketKey("TEST-DEL/1Min/OHLCV")
+	for i := int64(1); i <= 200; i++ {
+		epoch := base + 60*i
+		require.Nil(t, writer.WriteCSM(row(key.GetItemKey(), epoch), false))
+		require.Nil(t, writer.Delete(key, time.Unix(epoch, 0), time.Unix(epoch, 0)))
+	}
+	close(done)
+	wg.Wait()
+
+	q := NewQuery(metadata.CatalogDir)
+	q.AddTargetKey(key)
+	q.SetRange(time.Unix(base, 0), time.Unix(base+86400, 0))
+	parsed, err := q.Parse()
+	require.Nil(t, err)
+	reader, err := executor.NewReader(parsed)
+	require.Nil(t, err)
+	csm, err := reader.Read()
+	require.Nil(t, err)
+	assert.Equal(t, []int64{base}, csm[*key].GetEpoch())
+}
+
+func TestArchiveYear(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestArchiveYear")
+	defer tearDown()
+
+	lastRows := func(parsed *ParseResult) { parsed.Limit = &RowLimit{Number: 1000, Direction: LAST} }
+
+	// a FIXED bucket, read across the boundaries of the archived year
+	tbk := NewTimeBucketKey("EURUSD/1Min/OHLC")
+	start := time.Date(2000, time.December, 31, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2002, time.January, 1, 12, 0, 0, 0, time.UTC)
+	first := readBucket(t, metadata.CatalogDir, tbk, start, end)
+	last := readBucket(t, metadata.CatalogDir, tbk, start, end, lastRows)
+	require.True(t, first.Len() > 1000)
+
+	yearFile := filepath.Join(rootDir, "EURUSD/1Min/OHLC/2001.bin")
+	tbi := &TimeBucketInfo{Year: 2001, Path: yearFile}
+	require.Nil(t, executor.ArchiveYear(tbi))
+	assert.Equal(t, filepath.Join(rootDir, "EURUSD/1Min/OHLC/2001.arc"), tbi.Path)
+	_, err := os.Stat(yearFile)
+	assert.True(t, os.IsNotExist(err))
+
+	d, err := NewDirectory(rootDir)
+	require.Nil(t, err)
+	assert.Equal(t, first, readBucket(t, d, tbk, start, end))
+	assert.Equal(t, last, readBucket(t, d, tbk, start, end, lastRows))
+
+	// archived years are read-only
+	writer, err := executor.NewWriter(d, metadata.WALFile)
+	require.Nil(t, err)
+	cs := NewColumnSeries()
+	ts := time.Date(2001, time.March, 1, 0, 0, 0, 0, time.UTC)
+	cs.AddColumn("Epoch", []int64{ts.Unix()})
+	for _, name := range []string{"Open", "High", "Low", "Close"} {
+		cs.AddColumn(name, []float32{1})
+	}
+	csm := NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	assert.NotNil(t, writer.WriteCSM(csm, false))
+	assert.NotNil(t, writer.Delete(tbk, ts, ts.Add(time.Hour)))
+
+	require.Nil(t, executor.RestoreYear(tbi))
+	assert.Equal(t, yearFile, tbi.Path)
+	d, err = NewDirectory(rootDir)
+	require.Nil(t, err)
+	assert.Equal(t, first, readBucket(t, d, tbk, start, end))
+	assert.Equal(t, last, readBucket(t, d, tbk, start, end, lastRows))
+
+	// a VARIABLE bucket with several rows per interval
+	tbk = NewTimeBucketKey("TEST-ARC/1Min/TICK-BIDASK")
+	dsv := NewDataShapeVector([]string{"Bid", "Ask"}, []EnumElementType{FLOAT32, FLOAT64})
+	vtbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(rootDir), "Test",
+		int16(2016), dsv, VARIABLE)
+	require.Nil(t, metadata.CatalogDir.AddTimeBucket(tbk, vtbi))
+	writer, err = executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	row := struct {
+		Epoch int64
+		Bid   float32
+		Ask   float64
+	}{}
+	base := time.Date(2016, time.December, 1, 12, 0, 0, 0, time.UTC)
+	for _, sec := range []int{10, 20, 30, 70, 80, 3600, 7210} {
+		ts := base.Add(time.Duration(sec) * time.Second)
+		row.Epoch, row.Bid, row.Ask = ts.Unix(), float32(sec), float64(sec)+0.5
+		buffer, _ := Serialize([]byte{}, row)
+		require.Nil(t, writer.WriteRecords([]time.Time{ts}, buffer, dsv, vtbi))
+	}
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+
+	start, end = base, base.Add(3*time.Hour)
+	first = readBucket(t, metadata.CatalogDir, tbk, start, end)
+	last = readBucket(t, metadata.CatalogDir, tbk, start, end, lastRows)
+	require.Equal(t, 7, first.Len())
+
+	vtbi = &TimeBucketInfo{Year: 2016, Path: vtbi.Path}
+	require.Nil(t, executor.ArchiveYear(vtbi))
+	d, err = NewDirectory(rootDir)
+	require.Nil(t, err)
+	assert.Equal(t, first, readBucket(t, d, tbk, start, end))
+	assert.Equal(t, last, readBucket(t, d, tbk, start, end, lastRows))
+	part := readBucket(t, d, tbk, base.Add(time.Minute), base.Add(time.Hour))
+	assert.Equal(t, []float32{70, 80, 3600}, part.GetByName("Bid"))
+
+	require.Nil(t, executor.RestoreYear(vtbi))
+	d, err = NewDirectory(rootDir)
+	require.Nil(t, err)
+	assert.Equal(t, first, readBucket(t, d, tbk, start, end))
+}
+
+func TestAlterBucket(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestAlterBucket")
+	defer tearDown()
+
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+
+	// a FIXED bucket, with rows in several years
+	tbk := NewTimeBucketKey("EURUSD/1Min/OHLC")
+	start := time.Date(2000, time.December, 31, 23, 0, 0, 0, time.UTC)
+	end := time.Date(2001, time.January, 1, 1, 0, 0, 0, time.UTC)
+	before := readBucket(t, metadata.CatalogDir, tbk, start, end)
+	require.True(t, before.Len() > 0)
+
+	err = writer.AlterBucket(tbk, executor.ColumnChanges{
+		Add:    []DataShape{{Name: "Trades", Type: INT32}},
+		Drop:   []string{"Low"},
+		Retype: []DataShape{{Name: "High", Type: INT64}, {Name: "Close", Type: FLOAT64}},
+	})
+	require.Nil(t, err)
+
+	for _, year := range []string{"2000", "2001", "2002"} {
+		tbi := &TimeBucketInfo{Path: filepath.Join(rootDir, "EURUSD/1Min/OHLC", year+".bin")}
+		assert.Equal(t, []string{"Open", "High", "Close", "Trades"}, tbi.GetElementNames())
+	}
+	after := readBucket(t, metadata.CatalogDir, tbk, start, end)
+	require.Equal(t, before.Len(), after.Len())
+	assert.Equal(t, before.GetEpoch(), after.GetEpoch())
+	assert.Equal(t, before.GetByName("Open"), after.GetByName("Open"))
+	assert.Nil(t, after.GetByName("Low"))
+	for i, v := range before.GetByName("High").([]float32) {
+		assert.Equal(t, int64(v), after.GetByName("High").([]int64)[i])
+		assert.Equal(t, float64(before.GetByName("Close").([]float32)[i]), after.GetByName("Close").([]float64)[i])
+		assert.Equal(t, int32(0), after.GetByName("Trades").([]int32)[i])
+	}
+
+	// the writes use the new layout
+	ts := time.Date(2001, time.March, 1, 0, 0, 0, 0, time.UTC)
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{ts.Unix()})
+	cs.AddColumn("Open", []float32{1})
+	cs.AddColumn("High", []int64{2})
+	cs.AddColumn("Close", []float64{3})
+	cs.AddColumn("Trades", []int32{4})
+	csm := NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	require.Nil(t, writer.WriteCSM(csm, false))
+	written := readBucket(t, metadata.CatalogDir, tbk, ts, ts)
+	require.Equal(t, 1, written.Len())
+	assert.Equal(t, []int32{4}, written.GetByName("Trades"))
+
+	// invalid changes
+	for _, changes := range []executor.ColumnChanges{
+		{Drop: []string{"Low"}},
+		{Add: []DataShape{{Name: "Open", Type: FLOAT32}}},
+		{Drop: []string{"Open", "High", "Close", "Trades"}},
+		{Retype: []DataShape{{Name: "Open", Type: STRING16}}},
+		{Add: []DataShape{{Name: "Epoch", Type: INT64}}},
+	} {
+		assert.NotNil(t, writer.AlterBucket(tbk, changes), changes)
+	}
+	assert.NotNil(t, writer.AlterBucket(NewTimeBucketKey("NONE/1Min/OHLC"),
+		executor.ColumnChanges{Drop: []string{"Open"}}))
+
+	// a VARIABLE bucket keeps the rows of its intervals and their time
+	tbk = NewTimeBucketKey("TEST-ALTER/1Min/TICK-BIDASK")
+	dsv := NewDataShapeVector([]string{"Bid", "Ask"}, []EnumElementType{FLOAT32, FLOAT64})
+	vtbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(rootDir), "Test",
+		int16(2016), dsv, VARIABLE)
+	require.Nil(t, metadata.CatalogDir.AddTimeBucket(tbk, vtbi))
+	row := struct {
+		Epoch int64
+		Bid   float32
+		Ask   float64
+	}{}
+	base := time.Date(2016, time.December, 1, 12, 0, 0, 0, time.UTC)
+	for _, ms := range []int{10500, 20250, 30000, 70125, 3600000} {
+		ts := base.Add(time.Duration(ms) * time.Millisecond)
+		row.Epoch, row.Bid, row.Ask = ts.Unix(), float32(ms)/1000, float64(ms)+0.5
+		buffer, _ := Serialize([]byte{}, row)
+		require.Nil(t, writer.WriteRecords([]time.Time{ts}, buffer, dsv, vtbi))
+	}
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+	before = readBucket(t, metadata.CatalogDir, tbk, base, base.Add(2*time.Hour))
+	require.Equal(t, 5, before.Len())
+
+	err = writer.AlterBucket(tbk, executor.ColumnChanges{
+		Add:    []DataShape{{Name: "Size", Type: UINT16}},
+		Retype: []DataShape{{Name: "Bid", Type: FLOAT64}},
+	})
+	require.Nil(t, err)
+	after = readBucket(t, metadata.CatalogDir, tbk, base, base.Add(2*time.Hour))
+	assert.Equal(t, before.GetEpoch(), after.GetEpoch())
+	assert.Equal(t, before.GetByName("Nanoseconds"), after.GetByName("Nanoseconds"))
+	assert.Equal(t, before.GetByName("Ask"), after.GetByName("Ask"))
+	assert.Equal(t, []float64{10.5, 20.25, 30, 70.125, 3600}, after.GetByName("Bid"))
+	assert.Equal(t, []uint16{0, 0, 0, 0, 0}, after.GetByName("Size"))
+}
+
+func TestNullableBucket(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestNullableBucket")
+	defer tearDown()
+
+	start, end := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	write := func(tbk *TimeBucketKey, cs *ColumnSeries) error {
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*tbk, cs)
+		return writer.WriteCSM(csm, false)
+	}
+	epochs := []int64{
+		time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC).Unix(),
+		time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC).Unix(),
+		time.Date(2019, 1, 4, 0, 0, 0, 0, time.UTC).Unix(),
+	}
+
+	// a series with NULL values creates a nullable bucket
+	tbk := NewTimeBucketKey("TEST-NULL/1D/OHLCV")
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", epochs[:2])
+	cs.AddColumn("Close", []float32{0, 0})
+	cs.SetNulls("Close", []bool{false, true})
+	cs.AddColumn("Volume", []int64{10, 0})
+	require.Nil(t, write(tbk, cs))
+	tbi, err := metadata.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	require.Nil(t, err)
+	assert.Equal(t, []string{"Close", "Volume", NullsColumn}, tbi.GetElementNames())
+
+	// a missing column is NULL
+	cs = NewColumnSeries()
+	cs.AddColumn("Epoch", epochs[2:])
+	cs.AddColumn("Close", []float32{3})
+	require.Nil(t, write(tbk, cs))
+
+	got := readBucket(t, metadata.CatalogDir, tbk, start, end)
+	require.Equal(t, 3, got.Len())
+	assert.False(t, got.Exists(NullsColumn))
+	assert.Equal(t, []float32{0, 0, 3}, got.GetByName("Close"))
+	assert.Equal(t, []bool{false, true, false}, got.Nulls("Close"))
+	assert.Equal(t, []bool{false, false, true}, got.Nulls("Volume"))
+
+	// the columns added by an alter are NULL in the existing rows, the others keep their NULL values
+	require.Nil(t, writer.AlterBucket(tbk, executor.ColumnChanges{
+		Add:  []DataShape{{Name: "Open", Type: FLOAT32}},
+		Drop: []string{"Close"},
+	}))
+	got = readBucket(t, metadata.CatalogDir, tbk, start, end)
+	assert.Equal(t, []bool{false, false, true}, got.Nulls("Volume"))
+	assert.Equal(t, []bool{true, true, true}, got.Nulls("Open"))
+	assert.NotNil(t, writer.AlterBucket(tbk, executor.ColumnChanges{Drop: []string{NullsColumn}}))
+
+	// a bucket that is not nullable rejects NULL values until an alter makes it nullable
+	tbk2 := NewTimeBucketKey("TEST-NULL2/1D/OHLCV")
+	cs = NewColumnSeries()
+	cs.AddColumn("Epoch", epochs[:1])
+	cs.AddColumn("Close", []float32{1})
+	require.Nil(t, write(tbk2, cs))
+	cs = NewColumnSeries()
+	cs.AddColumn("Epoch", epochs[1:2])
+	cs.AddColumn("Close", []float32{0})
+	cs.SetNulls("Close", []bool{true})
+	assert.NotNil(t, write(tbk2, cs))
+	require.Nil(t, writer.AlterBucket(tbk2, executor.ColumnChanges{Nullable: true}))
+	require.Nil(t, write(tbk2, cs))
+	assert.Equal(t, []bool{false, true}, readBucket(t, metadata.CatalogDir, tbk2, start, end).Nulls("Close"))
+
+	// the NULL values are replayed from the WAL
+	metadata, _, _, err = executor.NewInstanceSetup(rootDir, nil, nil, 5, executor.BackgroundSync(false))
+	require.Nil(t, err)
+	assert.Equal(t, []bool{false, false, true}, readBucket(t, metadata.CatalogDir, tbk, start, end).Nulls("Volume"))
+}
+
+func TestDecimalBucket(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestDecimalBucket")
+	defer tearDown()
+
+	start, end := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	write := func(tbk *TimeBucketKey, cs *ColumnSeries) error {
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*tbk, cs)
+		return writer.WriteCSM(csm, false)
+	}
+	epochs := []int64{
+		time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC).Unix(),
+		time.Date(2019, 1, 3, 0, 0, 0, 0, time.UTC).Unix(),
+	}
+
+	// the scale of a decimal column is stored in the header of the bucket
+	tbk := NewTimeBucketKey("TEST-DEC/1D/TICK")
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", epochs[:1])
+	cs.AddDecimalColumn("Price", []Decimal64{12345678}, 5)
+	cs.AddColumn("Size", []float64{1.5})
+	require.Nil(t, write(tbk, cs))
+	tbi, err := metadata.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	require.Nil(t, err)
+	assert.Equal(t, DataShape{Name: "Price", Type: DECIMAL64, Scale: 5}, tbi.GetDataShapes()[0])
+
+	// the values written with another scale or type are converted to the scale of the bucket
+	cs = NewColumnSeries()
+	cs.AddColumn("Epoch", epochs[1:])
+	cs.AddColumn("Price", []float64{0.123456})
+	cs.AddColumn("Size", []float64{2})
+	require.Nil(t, write(tbk, cs))
+	got := readBucket(t, metadata.CatalogDir, tbk, start, end)
+	assert.Equal(t, []Decimal64{12345678, 12346}, got.GetByName("Price"))
+	assert.Equal(t, int8(5), got.Scale("Price"))
+
+	// an alter converts a float column to a decimal one
+	require.Nil(t, writer.AlterBucket(tbk, executor.ColumnChanges{
+		Retype: []DataShape{{Name: "Size", Type: DECIMAL64, Scale: 2}},
+	}))
+	got = readBucket(t, metadata.CatalogDir, tbk, start, end)
+	assert.Equal(t, []Decimal64{150, 200}, got.GetByName("Size"))
+	assert.Equal(t, int8(2), got.Scale("Size"))
+
+	// the scales are loaded from the headers
+	metadata, _, _, err = executor.NewInstanceSetup(rootDir, nil, nil, 5, executor.BackgroundSync(false))
+	require.Nil(t, err)
+	got = readBucket(t, metadata.CatalogDir, tbk, start, end)
+	assert.Equal(t, []Decimal64{12345678, 12346}, got.GetByName("Price"))
+	assert.Equal(t, int8(5), got.Scale("Price"))
+	assert.Equal(t, int8(2), got.Scale("Size"))
+}
+
+func TestBlockIndex(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestBlockIndex")
+	defer tearDown()
+
+	tbk := NewTimeBucketKey("TEST-BIX/1Min/PRICE")
+	dsv := NewDataShapeVector([]string{"Price", "Size"}, []EnumElementType{FLOAT64, INT32})
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(rootDir), "Test",
+		int16(2016), dsv, FIXED)
+	require.Nil(t, metadata.CatalogDir.AddTimeBucket(tbk, tbi))
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+
+	// writes 10 rows a minute apart from start, with the prices from price
+	write := func(start time.Time, price float64) {
+		t.Helper()
+		var (
+			epochs []int64
+			prices []float64
+			sizes  []int32
+		)
+		for i := 0; i < 10; i++ {
+			epochs = append(epochs, start.Add(time.Duration(i)*time.Minute).Unix())
+			prices = append(prices, price+float64(i))
+			sizes = append(sizes, int32(i))
+		}
+		cs := NewColumnSeries()
+		cs.AddColumn("Epoch", epochs)
+		cs.AddColumn("Price", prices)
+		cs.AddColumn("Size", sizes)
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*tbk, cs)
+		require.Nil(t, writer.WriteCSM(csm, false))
+		require.Nil(t, metadata.WALFile.FlushToWAL())
+	}
+	read := func(start, end time.Time, limit DirectionEnum, n int, ranges ...ColumnRange) []float64 {
+		t.Helper()
+		cs := readBucket(t, metadata.CatalogDir, tbk, start, end, func(parsed *ParseResult) {
+			if n != 0 {
+				parsed.Limit = &RowLimit{Number: int32(n), Direction: limit}
+			}
+			parsed.ColumnRanges = ranges
+		})
+		if cs == nil || cs.Len() == 0 {
+			return nil
+		}
+		return cs.GetByName("Price").([]float64)
+	}
+
+	// the rows are in distinct blocks of the index
+	jan1 := time.Date(2016, time.January, 1, 0, 0, 0, 0, time.UTC)
+	jan10 := time.Date(2016, time.January, 10, 0, 0, 0, 0, time.UTC)
+	feb1 := time.Date(2016, time.February, 1, 0, 0, 0, 0, time.UTC)
+	write(jan1, 10)
+	write(jan10, 100)
+	write(feb1, 1000)
+	require.Nil(t, executor.BuildBlockIndex(&TimeBucketInfo{Year: 2016, Path: tbi.Path}))
+	_, err = os.Stat(filepath.Join(filepath.Dir(tbi.Path), "2016.idx"))
+	require.Nil(t, err)
+
+	start, end := jan1, time.Date(2016, time.December, 31, 0, 0, 0, 0, time.UTC)
+	all := read(start, end, FIRST, 0)
+	assert.Equal(t, 30, len(all))
+	// the column ranges only skip blocks, the rows of the read blocks are all returned
+	assert.Equal(t, all[10:20], read(start, end, FIRST, 0, ColumnRange{Name: "Price", Min: 100, Max: 105}))
+	assert.Nil(t, read(start, end, FIRST, 0, ColumnRange{Name: "Price", Min: 5000, Max: 6000}))
+	assert.Equal(t, all, read(start, end, FIRST, 0, ColumnRange{Name: "Unknown", Min: 5000, Max: 6000}))
+	// the column ranges are ignored by a read with a row limit
+	assert.Equal(t, all[27:], read(start, end, LAST, 3, ColumnRange{Name: "Price", Min: 5000, Max: 6000}))
+	assert.Equal(t, all[:12], read(start, end, FIRST, 12))
+	assert.Equal(t, all[5:15], read(jan1.Add(5*time.Minute), end, FIRST, 10))
+
+	// new rows widen the index
+	mar1 := time.Date(2016, time.March, 1, 0, 0, 0, 0, time.UTC)
+	write(mar1, 5550)
+	assert.Equal(t, 10, len(read(start, end, FIRST, 0, ColumnRange{Name: "Price", Min: 5555, Max: 5555})))
+	assert.Equal(t, []float64{5557, 5558, 5559}, read(start, end, LAST, 3))
+
+	// deleted rows are removed from the index
+	require.Nil(t, writer.Delete(tbk, feb1, feb1.Add(time.Hour)))
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+	assert.Nil(t, read(start, end, FIRST, 0, ColumnRange{Name: "Price", Min: 1000, Max: 1009}))
+	assert.Equal(t, 30, len(read(start, end, FIRST, 0)))
+
+	// a new year of an indexed bucket is indexed
+	write(time.Date(2017, time.January, 2, 0, 0, 0, 0, time.UTC), 20000)
+	_, err = os.Stat(filepath.Join(filepath.Dir(tbi.Path), "2017.idx"))
+	require.Nil(t, err)
+	assert.Equal(t, 10, len(read(start, end.AddDate(1, 0, 0), FIRST, 0, ColumnRange{Name: "Price", Min: 20000, Max: 30000})))
+}
+
+func TestBackupRestore(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestBackupRestore")
+	defer tearDown()
+
+	tbk := NewTimeBucketKey("TEST-BK/1Min/TICK-BIDASK")
+	dsv := NewDataShapeVector([]string{"Bid", "Ask"}, []EnumElementType{FLOAT32, FLOAT32})
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(rootDir), "Test",
+		int16(2016), dsv, VARIABLE)
+	require.Nil(t, metadata.CatalogDir.AddTimeBucket(tbk, tbi))
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+
+	// writes a row to the variable bucket and a row to a new fixed bucket of the year
+	base := time.Date(2016, time.December, 1, 12, 0, 0, 0, time.UTC)
+	write := func(sec int, year int) {
+		t.Helper()
+		ts := base.Add(time.Duration(sec) * time.Second)
+		cs := NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{ts.Unix()})
+		cs.AddColumn("Bid", []float32{float32(sec)})
+		cs.AddColumn("Ask", []float32{float32(sec + 1)})
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*tbk, cs)
+		cs = NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{time.Date(year, time.January, 2, 0, 0, 0, 0, time.UTC).Unix()})
+		cs.AddColumn("Price", []float64{float64(sec)})
+		csm.AddColumnSeries(*NewTimeBucketKey(fmt.Sprintf("TEST-BK%d/1D/PRICE", year)), cs)
+		require.Nil(t, writer.WriteCSM(csm, false))
+		require.Nil(t, metadata.WALFile.FlushToWAL())
+	}
+	read := func(dir string, key string) *ColumnSeries {
+		t.Helper()
+		catDir, err := NewDirectory(dir)
+		require.Nil(t, err)
+		return readBucket(t, catDir, NewTimeBucketKey(key), time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+			time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC))
+	}
+
+	write(10, 2016)
+	write(10, 2016)
+	backupDir := filepath.Join(t.TempDir(), "backup")
+	m, err := writer.Backup(backupDir)
+	require.Nil(t, err)
+	assert.NotZero(t, m.TGID)
+	assert.NotEmpty(t, m.WALFiles)
+	assert.Nil(t, m.Verify(backupDir))
+	// the manifest is written with the backup
+	m2, err := executor.ReadBackupManifest(backupDir)
+	require.Nil(t, err)
+	assert.Equal(t, m.TGID, m2.TGID)
+	assert.Equal(t, len(m.Files), len(m2.Files))
+	_, err = writer.Backup(backupDir)
+	assert.NotNil(t, err)
+	_, err = writer.Backup(filepath.Join(rootDir, "backup"))
+	assert.NotNil(t, err)
+
+	// the writes after the backup are in the WAL file of the instance
+	write(20, 2016)
+	write(30, 2017)
+
+	// the backup alone restores the state of the backup
+	restoreDir := filepath.Join(t.TempDir(), "restore")
+	restored, err := executor.RestoreBackup(backupDir, restoreDir, m.TGID, nil)
+	require.Nil(t, err)
+	assert.Equal(t, m.TGID, restored)
+	assert.Equal(t, read(rootDir, "EURUSD/1H/OHLC").Len(), read(restoreDir, "EURUSD/1H/OHLC").Len())
+	assert.Equal(t, []float32{10, 10}, read(restoreDir, "TEST-BK/1Min/TICK-BIDASK").GetByName("Bid"))
+	assert.Equal(t, []float64{10}, read(restoreDir, "TEST-BK2016/1D/PRICE").GetByName("Price"))
+
+	// the WAL of the instance brings the restore up to date, with the years created after the backup
+	restoreDir = filepath.Join(t.TempDir(), "restore")
+	restored, err = executor.RestoreBackup(backupDir, restoreDir, 0, []string{metadata.WALFile.FilePtr.Name()})
+	require.Nil(t, err)
+	assert.Greater(t, restored, m.TGID)
+	assert.Equal(t, read(rootDir, "TEST-BK/1Min/TICK-BIDASK").GetByName("Bid"),
+		read(restoreDir, "TEST-BK/1Min/TICK-BIDASK").GetByName("Bid"))
+	assert.Equal(t, []float32{10, 10, 20, 30}, read(restoreDir, "TEST-BK/1Min/TICK-BIDASK").GetByName("Bid"))
+	assert.Equal(t, []float64{30}, read(restoreDir, "TEST-BK2017/1D/PRICE").GetByName("Price"))
+
+	// the backup can not be restored before its last transaction group, or into a non empty directory
+	_, err = executor.RestoreBackup(backupDir, t.TempDir(), m.TGID-1, nil)
+	assert.NotNil(t, err)
+	_, err = executor.RestoreBackup(backupDir, restoreDir, 0, nil)
+	assert.NotNil(t, err)
+
+	// a corrupted file fails the verification
+	f, err := os.OpenFile(filepath.Join(backupDir, "data", filepath.FromSlash(m.Files[0].Path)), os.O_WRONLY, 0)
+	require.Nil(t, err)
+	_, err = f.WriteAt([]byte{0xff}, 0)
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+	assert.NotNil(t, m.Verify(backupDir))
+	_, err = executor.RestoreBackup(backupDir, filepath.Join(t.TempDir(), "restore"), 0, nil)
+	assert.NotNil(t, err)
+}
+
+func TestRemoteYear(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestRemoteYear")
+	defer tearDown()
+
+	tbk := NewTimeBucketKey("TEST-RY/1Min/TICK-BIDASK")
+	dsv := NewDataShapeVector([]string{"Bid", "Ask"}, []EnumElementType{FLOAT32, FLOAT32})
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), tbk.GetPathToYearFiles(rootDir), "Test",
+		int16(2016), dsv, VARIABLE)
+	require.Nil(t, metadata.CatalogDir.AddTimeBucket(tbk, tbi))
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	base := time.Date(2016, time.December, 1, 12, 0, 0, 0, time.UTC)
+	write := func(w *executor.Writer, ts time.Time, bid float32) error {
+		cs := NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{ts.Unix()})
+		cs.AddColumn("Bid", []float32{bid})
+		cs.AddColumn("Ask", []float32{bid + 1})
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*tbk, cs)
+		return w.WriteCSM(csm, true)
+	}
+	start, end := base.AddDate(-1, 0, 0), base.AddDate(2, 0, 0)
+	require.Nil(t, write(writer, base, 10))
+	require.Nil(t, write(writer, base.Add(time.Second), 11))
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+	require.Nil(t, metadata.WALFile.CreateCheckpoint())
+
+	// the year is offloaded while the instance is stopped
+	store, err := blob.NewLocal(t.TempDir())
+	require.Nil(t, err)
+	cacheDir := t.TempDir()
+	storage, err := tiered.New(store, rootDir, cacheDir, 0)
+	require.Nil(t, err)
+	offloaded := &TimeBucketInfo{Year: 2016, Path: tbi.Path}
+	require.Nil(t, storage.Offload(offloaded))
+	_, err = os.Stat(tbi.Path)
+	assert.True(t, os.IsNotExist(err))
+
+	metadata, _, _, err = executor.NewInstanceSetup(rootDir, nil, nil, 5,
+		executor.BackgroundSync(false), executor.RemoteStorage(storage))
+	require.Nil(t, err)
+	writer, err = executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+
+	// the offloaded year is read from the cache
+	assert.Equal(t, []float32{10, 11}, readBucket(t, metadata.CatalogDir, tbk, start, end).GetByName("Bid"))
+	_, err = os.Stat(filepath.Join(cacheDir, "TEST-RY", "1Min", "TICK-BIDASK", "2016.bin"))
+	assert.Nil(t, err)
+
+	// it is read-only, while the next years are written
+	assert.NotNil(t, write(writer, base.Add(time.Minute), 12))
+	assert.NotNil(t, writer.Delete(tbk, base, base.Add(time.Hour)))
+	require.Nil(t, write(writer, base.AddDate(1, 0, 0), 20))
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+	assert.Equal(t, []float32{10, 11, 20}, readBucket(t, metadata.CatalogDir, tbk, start, end).GetByName("Bid"))
+	require.Nil(t, metadata.WALFile.CreateCheckpoint())
+
+	// without the tiered storage, the offloaded year is skipped like an unreadable year file
+	metadata, _, _, err = executor.NewInstanceSetup(rootDir, nil, nil, 5, executor.BackgroundSync(false))
+	require.Nil(t, err)
+	assert.Equal(t, []float32{20}, readBucket(t, metadata.CatalogDir, tbk, start, end).GetByName("Bid"))
+}
+
+func TestRetention(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestRetention")
+	defer tearDown()
+
+	tbk := NewTimeBucketKey("TEST-RET/1D/OHLCV")
+	dsv := NewDataShapeVector([]string{"Close"}, []EnumElementType{FLOAT32})
+	tbi := NewTimeBucketInfo(*utils.TimeframeFromString("1D"), tbk.GetPathToYearFiles(rootDir), "Test",
+		int16(2018), dsv, FIXED)
+	require.Nil(t, metadata.CatalogDir.AddTimeBucket(tbk, tbi))
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	var epochs []int64
+	var closes []float32
+	for year := 2018; year <= 2020; year++ {
+		for _, month := range []time.Month{time.January, time.July} {
+			epochs = append(epochs, time.Date(year, month, 2, 0, 0, 0, 0, time.UTC).Unix())
+			closes = append(closes, float32(year-2000)+float32(month)/100)
+		}
+	}
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", epochs)
+	cs.AddColumn("Close", closes)
+	csm := NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	require.Nil(t, writer.WriteCSM(csm, false))
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+	start, end := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	policy, err := utils.NewRetentionPolicy("TEST-RET/*/*", "1y")
+	require.Nil(t, err)
+	setting := utils.RetentionSetting{Policies: []utils.RetentionPolicy{policy}, Interval: time.Hour, DryRun: true}
+	now := time.Date(2020, time.March, 1, 0, 0, 0, 0, time.UTC)
+	yearPath := func(year int) string {
+		return filepath.Join(tbk.GetPathToYearFiles(rootDir), fmt.Sprintf("%d.bin", year))
+	}
+
+	// a dry run only reports what would be removed
+	results, err := executor.NewRetention(writer, setting).Apply(now)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []int16{2018}, results[0].DroppedYears)
+	assert.Equal(t, int16(2019), results[0].TruncatedYear)
+	assert.Equal(t, time.Date(2019, time.March, 1, 0, 0, 0, 0, time.UTC), results[0].Cutoff)
+	assert.Len(t, readBucket(t, metadata.CatalogDir, tbk, start, end).GetByName("Close"), 6)
+	_, err = os.Stat(yearPath(2018))
+	assert.Nil(t, err)
+
+	// the year before the cutoff is dropped and the year of the cutoff is truncated
+	setting.DryRun = false
+	retention := executor.NewRetention(writer, setting)
+	results, err = retention.Apply(now)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []int16{2018}, results[0].DroppedYears)
+	assert.Equal(t, []float32{19.07, 20.01, 20.07},
+		readBucket(t, metadata.CatalogDir, tbk, start, end).GetByName("Close"))
+	_, err = os.Stat(yearPath(2018))
+	assert.True(t, os.IsNotExist(err))
+	results, err = retention.Apply(now)
+	require.Nil(t, err)
+	assert.Empty(t, results)
+
+	// the latest year is truncated, not dropped
+	results, err = retention.Apply(time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC))
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []int16{2019}, results[0].DroppedYears)
+	assert.Equal(t, int16(2020), results[0].TruncatedYear)
+	assert.Empty(t, readBucket(t, metadata.CatalogDir, tbk, start, end).GetByName("Close"))
+	_, err = os.Stat(yearPath(2020))
+	assert.Nil(t, err)
+	assert.NotNil(t, writer.DropYear(tbk, 2020))
+
+	// the drops replayed from the WAL are no-ops
+	metadata, _, _, err = executor.NewInstanceSetup(rootDir, nil, nil, 5, executor.BackgroundSync(false))
+	require.Nil(t, err)
+	assert.Empty(t, readBucket(t, metadata.CatalogDir, tbk, start, end).GetByName("Close"))
+	_, err = os.Stat(yearPath(2020))
+	assert.Nil(t, err)
+}
+
 /*
 	===================== Helper Functions =================================
 */
 
+// readBucket reads the rows of a bucket from start to end, the options modify the parsed query, e.g. its row limit.
+func readBucket(t *testing.T, catDir *Directory, tbk *TimeBucketKey, start, end time.Time,
+	options ...func(parsed *ParseResult),
+) *ColumnSeries {
+	t.Helper()
+
+	q := NewQuery(catDir)
+	q.AddTargetKey(tbk)
+	q.SetRange(start, end)
+	parsed, err := q.Parse()
+	require.Nil(t, err)
+	for _, option := range options {
+		option(parsed)
+	}
+	reader, err := executor.NewReader(parsed)
+	require.Nil(t, err)
+	csm, err := reader.Read()
+	require.Nil(t, err)
+	return csm[*tbk]
+}
+
 func forwardBackwardScan(t *testing.T, numRecs int, d *Directory) {
 	t.Helper()
 
@@ -842,3 +1699,196 @@ func nearestSecond(seconds int64, nanos int32) int64 {
 	}
 	return seconds
 }
+
+func TestHeapColumns(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestHeapColumns")
+	defer tearDown()
+
+	start, end := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	write := func(tbk *TimeBucketKey, cs *ColumnSeries, isVariableLength bool) {
+		t.Helper()
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*tbk, cs)
+		require.Nil(t, writer.WriteCSM(csm, isVariableLength))
+		require.Nil(t, metadata.WALFile.FlushToWAL())
+		require.Nil(t, metadata.WALFile.CreateCheckpoint())
+	}
+
+	// the values of a string and a blob column are stored in the heap of each year, identical values once
+	tbk := NewTimeBucketKey("TEST-HEAP/1Min/TRADE")
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{
+		time.Date(2019, 12, 31, 12, 0, 0, 0, time.UTC).Unix(),
+		time.Date(2019, 12, 31, 12, 0, 0, 0, time.UTC).Unix(),
+		time.Date(2020, 1, 2, 12, 0, 0, 0, time.UTC).Unix(),
+	})
+	cs.AddColumn("Price", []float64{1, 2, 3})
+	cs.AddColumn("Cond", []string{"@ F T", "@ F T", ""})
+	cs.AddColumn("Raw", [][]byte{{0, 1}, {2}, {0xff}})
+	write(tbk, cs, true)
+	tbi, err := metadata.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	require.Nil(t, err)
+	assert.Equal(t, []EnumElementType{FLOAT64, STRING, BLOB}, tbi.GetElementTypes())
+
+	got := readBucket(t, metadata.CatalogDir, tbk, start, end)
+	assert.Equal(t, []float64{1, 2, 3}, got.GetByName("Price"))
+	assert.Equal(t, []string{"@ F T", "@ F T", ""}, got.GetByName("Cond"))
+	assert.Equal(t, [][]byte{{0, 1}, {2}, {0xff}}, got.GetByName("Raw"))
+	heap2019 := filepath.Join(filepath.Dir(tbi.Path), "2019"+HeapFileExt)
+	fi, err := os.Stat(heap2019)
+	require.Nil(t, err)
+	assert.Equal(t, int64(HeapHeaderSize+(4+5)+(4+2)+(4+1)), fi.Size())
+
+	// a fixed bucket, whose rows are overwritten
+	tbk2 := NewTimeBucketKey("TEST-HEAP/1D/NEWS")
+	epoch := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC).Unix()
+	for _, headline := range []string{"first", "second"} {
+		cs = NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{epoch})
+		cs.AddColumn("Headline", []string{headline})
+		write(tbk2, cs, false)
+	}
+	assert.Equal(t, []string{"second"}, readBucket(t, metadata.CatalogDir, tbk2, start, end).GetByName("Headline"))
+
+	// a string column can be retyped to a blob column, not to a numeric one
+	require.Nil(t, writer.AlterBucket(tbk2, executor.ColumnChanges{
+		Retype: []DataShape{{Name: "Headline", Type: BLOB}},
+		Add:    []DataShape{{Name: "Source", Type: STRING}},
+	}))
+	got = readBucket(t, metadata.CatalogDir, tbk2, start, end)
+	assert.Equal(t, [][]byte{[]byte("second")}, got.GetByName("Headline"))
+	assert.Equal(t, []string{""}, got.GetByName("Source"))
+	assert.NotNil(t, writer.AlterBucket(tbk2, executor.ColumnChanges{
+		Retype: []DataShape{{Name: "Source", Type: INT64}},
+	}))
+
+	// the values are read after a restart
+	require.Nil(t, metadata.WALFile.CreateCheckpoint())
+	metadata, _, _, err = executor.NewInstanceSetup(rootDir, nil, nil, 5, executor.BackgroundSync(false))
+	require.Nil(t, err)
+	got = readBucket(t, metadata.CatalogDir, tbk, start, end)
+	assert.Equal(t, []string{"@ F T", "@ F T", ""}, got.GetByName("Cond"))
+	assert.Equal(t, [][]byte{{0, 1}, {2}, {0xff}}, got.GetByName("Raw"))
+
+	// the heap of a year is removed with it
+	writer, err = executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	require.Nil(t, writer.DropYear(tbk, 2019))
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+	_, err = os.Stat(heap2019)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, []string{""}, readBucket(t, metadata.CatalogDir, tbk, start, end).GetByName("Cond"))
+}
+
+func TestHeapColumnsReplay(t *testing.T) {
+	tearDown, rootDir, _, metadata, _ := setup(t, "TestHeapColumnsReplay")
+	defer tearDown()
+
+	tbk := NewTimeBucketKey("TEST-HEAP/1D/NEWS")
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{
+		time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC).Unix(),
+		time.Date(2019, 3, 2, 0, 0, 0, 0, time.UTC).Unix(),
+	})
+	cs.AddColumn("Headline", []string{"first", "second"})
+	csm := NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	require.Nil(t, writer.WriteCSM(csm, false))
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+
+	// Save the WALFile contents after WAL flush, and remove the heap written by the checkpoint
+	fstat, err := metadata.WALFile.FilePtr.Stat()
+	require.Nil(t, err)
+	walContents := make([]byte, fstat.Size())
+	_, err = metadata.WALFile.FilePtr.ReadAt(walContents, 0)
+	require.Nil(t, err)
+	require.Nil(t, metadata.WALFile.CreateCheckpoint())
+	tbi, err := metadata.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	require.Nil(t, err)
+	heapPath := HeapPathOf(tbi.Path)
+	require.Nil(t, os.Remove(heapPath))
+
+	// the replay of a WAL with a bogus PID writes the heap again
+	for i, val := range [8]byte{1, 1, 1, 1, 1, 1, 1, 1} {
+		walContents[3+i] = val
+	}
+	walPath := filepath.Join(rootDir, "HeapWAL")
+	require.Nil(t, os.WriteFile(walPath, walContents, 0o600))
+	walFile, err := executor.TakeOverWALFile(walPath)
+	require.Nil(t, err)
+	require.Nil(t, walFile.Replay(false))
+	_, err = os.Stat(heapPath)
+	require.Nil(t, err)
+
+	q := NewQuery(metadata.CatalogDir)
+	q.AddTargetKey(tbk)
+	q.SetRange(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC))
+	parsed, err := q.Parse()
+	require.Nil(t, err)
+	reader, err := executor.NewReader(parsed)
+	require.Nil(t, err)
+	csm, err = reader.Read()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"first", "second"}, csm[*tbk].GetByName("Headline"))
+}
+
+func TestCompaction(t *testing.T) {
+	tearDown, _, _, metadata, _ := setup(t, "TestCompaction")
+	defer tearDown()
+
+	tbk := NewTimeBucketKey("TEST-COMPACT/1Sec/TICK")
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	epoch := time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC).Unix()
+	// each write of an interval appends its previous rows with the new one, unless they end the file
+	for i := 0; i < 10; i++ {
+		cs := NewColumnSeries()
+		cs.AddColumn("Epoch", []int64{epoch + int64(i%2)})
+		cs.AddColumn("Price", []float64{float64(i)})
+		csm := NewColumnSeriesMap()
+		csm.AddColumnSeries(*tbk, cs)
+		require.Nil(t, writer.WriteCSM(csm, true))
+		require.Nil(t, metadata.WALFile.FlushToWAL())
+		require.Nil(t, metadata.WALFile.CreateCheckpoint())
+	}
+	start, end := time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 12, 31, 0, 0, 0, 0, time.UTC)
+	before := readBucket(t, metadata.CatalogDir, tbk, start, end)
+	require.Equal(t, 10, before.Len())
+
+	tbi, err := metadata.CatalogDir.GetLatestTimeBucketInfoFromKey(tbk)
+	require.Nil(t, err)
+	fi, err := os.Stat(tbi.Path)
+	require.Nil(t, err)
+	results, err := writer.Compact(NewTimeBucketKey("TEST-COMPACT/*/*"), 0, 0.5)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Compacted)
+	assert.Equal(t, int16(2019), results[0].Year)
+	assert.Equal(t, fi.Size(), results[0].SizeBefore)
+	assert.Equal(t, results[0].DeadBytes, results[0].Reclaimed())
+	fi, err = os.Stat(tbi.Path)
+	require.Nil(t, err)
+	assert.Equal(t, results[0].SizeAfter, fi.Size())
+	assert.Equal(t, before, readBucket(t, metadata.CatalogDir, tbk, start, end))
+
+	// a compacted year file has no dead space left
+	results, err = writer.Compact(tbk, 2019, 0)
+	require.Nil(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Compacted)
+	assert.Equal(t, int64(0), results[0].DeadBytes)
+
+	// the rows written after the compaction are appended to the compacted file
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{epoch + 2})
+	cs.AddColumn("Price", []float64{10})
+	csm := NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	require.Nil(t, writer.WriteCSM(csm, true))
+	require.Nil(t, metadata.WALFile.FlushToWAL())
+	assert.Equal(t, 11, readBucket(t, metadata.CatalogDir, tbk, start, end).Len())
+}
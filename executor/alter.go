@@ -0,0 +1,448 @@
+package executor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+/*
+bucketLayout serializes the changes of the data shapes of the buckets with the writes:
+the writes and the deletions hold it for reading while their commands are queued, and
+an alter holds it for writing until the year files are rewritten, so that no command
+formatted with the previous layout is applied after the alter.
+*/
+var bucketLayout sync.RWMutex
+
+const maxElementNameBytes = 31
+
+// ColumnChanges describes the changes of the columns of a bucket, applied in the order Drop, Retype, Add.
+type ColumnChanges struct {
+	// Add appends columns to the bucket, the existing rows get the zero value of their type, NULL if nullable
+	Add []io.DataShape
+	// Drop removes columns from the bucket
+	Drop []string
+	// Retype changes the type of columns, their values are converted like a Go conversion
+	Retype []io.DataShape
+	// Nullable adds a validity bitmap to a bucket that has none, the existing values are not NULL
+	Nullable bool
+}
+
+/*
+Apply returns the data shapes, without Epoch, of a bucket with the data shapes dsv after the changes. The
+bitmap of a nullable bucket stays its last column, and the columns added to it are NULL in the existing rows.
+*/
+func (c *ColumnChanges) Apply(dsv []io.DataShape) ([]io.DataShape, error) {
+	nullable := io.IsNullable(dsv)
+	if nullable {
+		dsv = dsv[:len(dsv)-1]
+	}
+	out := append([]io.DataShape{}, dsv...)
+	find := func(name string) int {
+		for i, ds := range out {
+			if ds.Name == name {
+				return i
+			}
+		}
+		return -1
+	}
+	for _, name := range c.Drop {
+		i := find(name)
+		if i < 0 {
+			return nil, fmt.Errorf("column %s does not exist", name)
+		}
+		out = append(out[:i], out[i+1:]...)
+	}
+	for _, ds := range c.Retype {
+		i := find(ds.Name)
+		if i < 0 {
+			return nil, fmt.Errorf("column %s does not exist", ds.Name)
+		}
+		out[i].Type = ds.Type
+		out[i].Scale = ds.Scale
+	}
+	for _, ds := range c.Add {
+		if find(ds.Name) >= 0 {
+			return nil, fmt.Errorf("column %s already exists", ds.Name)
+		}
+		out = append(out, ds)
+	}
+	if nullable || c.Nullable {
+		return io.NullableDataShapes(out)
+	}
+	return out, nil
+}
+
+/*
+AlterBucket changes the columns of an existing bucket. All the year files of the bucket are
+rewritten with the new layout through the WAL, so that the change is durable and replicated,
+and the writes are blocked until it is done.
+*/
+func (w *Writer) AlterBucket(tbk *io.TimeBucketKey, changes ColumnChanges) error {
+	bucketLayout.Lock()
+	defer bucketLayout.Unlock()
+
+	tbi, err := w.rootCatDir.GetLatestTimeBucketInfoFromKey(tbk)
+	if err != nil {
+		return fmt.Errorf("bucket %s is not found: %w", tbk.GetItemKey(), err)
+	}
+	dsv, err := changes.Apply(tbi.GetDataShapes())
+	if err != nil {
+		return fmt.Errorf("alter %s: %w", tbk.GetItemKey(), err)
+	}
+	return w.alter(tbk, tbi, dsv)
+}
+
+// AlterDataShapes changes the data shapes, without Epoch, of an existing bucket, see Writer.AlterBucket.
+// It is used by the replicas to apply the alters of the primary.
+func (w *Writer) AlterDataShapes(tbk *io.TimeBucketKey, dsv []io.DataShape) error {
+	bucketLayout.Lock()
+	defer bucketLayout.Unlock()
+
+	tbi, err := w.rootCatDir.GetLatestTimeBucketInfoFromKey(tbk)
+	if err != nil {
+		return fmt.Errorf("bucket %s is not found: %w", tbk.GetItemKey(), err)
+	}
+	return w.alter(tbk, tbi, dsv)
+}
+
+func (w *Writer) alter(tbk *io.TimeBucketKey, latest *io.TimeBucketInfo, dsv []io.DataShape) error {
+	if err := validateAlter(latest.GetDataShapes(), dsv, latest.GetRecordType()); err != nil {
+		return fmt.Errorf("alter %s: %w", tbk.GetItemKey(), err)
+	}
+	if io.DataShapesEqual(latest.GetDataShapes(), dsv) {
+		return nil
+	}
+	subDir, err := w.rootCatDir.GetOwningSubDirectory(latest.Path)
+	if err != nil {
+		return fmt.Errorf("alter %s: %w", tbk.GetItemKey(), err)
+	}
+	for _, tbi := range subDir.GetTimeBucketInfoSlice() {
+		if err = readOnlyYear(tbi); err != nil {
+			return err
+		}
+	}
+
+	// the rows queued before the alter are written with the previous layout
+	w.walFile.RequestFlush()
+	done := make(chan error, 1)
+	wc, err := w.walFile.AlterCommand(latest.GetRecordType(), latest.Path,
+		append([]io.DataShape{{Name: "Epoch", Type: io.INT64}}, dsv...))
+	if err != nil {
+		return fmt.Errorf("alter %s: %w", tbk.GetItemKey(), err)
+	}
+	wc.Done = done
+	w.walFile.QueueWriteCommand(wc)
+	w.walFile.RequestFlush()
+	if err = <-done; err != nil {
+		return fmt.Errorf("alter %s: %w", tbk.GetItemKey(), err)
+	}
+	subDir.ReloadYearFiles()
+	return nil
+}
+
+// validateAlter checks that the data shapes of a bucket can be changed from before to after.
+func validateAlter(before, after []io.DataShape, recordType io.EnumRecordType) error {
+	if len(after) == 0 {
+		return fmt.Errorf("a bucket needs at least one column")
+	}
+	if len(after) >= math.MaxUint8 {
+		return fmt.Errorf("a bucket can have at most %d columns", math.MaxUint8-1)
+	}
+	if io.IsNullable(after) {
+		if err := io.ValidateNullable(after[:len(after)-1]); err != nil {
+			return err
+		}
+	}
+	names := map[string]bool{}
+	for i, ds := range after {
+		switch {
+		case ds.Name == io.NullsColumn && (i != len(after)-1 || ds.Type != io.UINT64):
+			return fmt.Errorf("column %s is reserved", ds.Name)
+		case ds.Name == "" || len(ds.Name) > maxElementNameBytes:
+			return fmt.Errorf("invalid column name %q", ds.Name)
+		case ds.Name == "Epoch", recordType == io.VARIABLE && ds.Name == "Nanoseconds":
+			return fmt.Errorf("column %s is reserved", ds.Name)
+		case names[ds.Name]:
+			return fmt.Errorf("duplicate column %s", ds.Name)
+		case ds.Type.Size() == 0:
+			return fmt.Errorf("column %s can not be of type %s", ds.Name, ds.Type.String())
+		case ds.Type == io.DECIMAL64:
+			if err := io.ValidateScale(ds.Scale); err != nil {
+				return fmt.Errorf("column %s: %w", ds.Name, err)
+			}
+		}
+		names[ds.Name] = true
+		for _, old := range before {
+			if old.Name == ds.Name && !convertible(old.Type, ds.Type) {
+				return fmt.Errorf("column %s can not be converted from %s to %s",
+					ds.Name, old.Type.String(), ds.Type.String())
+			}
+		}
+	}
+	return nil
+}
+
+func isNumeric(typ io.EnumElementType) bool {
+	switch typ {
+	case io.FLOAT32, io.FLOAT64, io.INT16, io.INT32, io.INT64, io.EPOCH, io.BYTE, io.BOOL,
+		io.UINT8, io.UINT16, io.UINT32, io.UINT64, io.DECIMAL64:
+		return true
+	default:
+		return false
+	}
+}
+
+func convertible(from, to io.EnumElementType) bool {
+	// the references of a string or a blob column are kept, the values in the heap are the same bytes
+	return from == to || isNumeric(from) && isNumeric(to) || io.IsHeapType(from) && io.IsHeapType(to)
+}
+
+/*
+alterBucketFiles rewrites the year files in the directory of a bucket with the data shapes
+dsvWithEpoch. The files that already have them are skipped, so that an alter replayed from
+the WAL leaves the files it already rewrote as they are.
+*/
+func alterBucketFiles(dir string, dsvWithEpoch []io.DataShape) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+io.YearFileExt))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err = alterYearFile(path, dsvWithEpoch[1:]); err != nil {
+			return fmt.Errorf("alter %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func alterYearFile(path string, dsv []io.DataShape) error {
+	old, err := io.ReadTimeBucketInfo(path)
+	if err != nil {
+		return err
+	}
+	if io.DataShapesEqual(old.GetDataShapes(), dsv) {
+		// a replayed alter may have stopped before the block index was rebuilt
+		return rebuildBlockIndex(old, false)
+	}
+	tf := utils.TimeframeFromDuration(old.GetTimeframe())
+	if tf == nil {
+		return fmt.Errorf("invalid timeframe %v", old.GetTimeframe())
+	}
+	tbi := io.NewTimeBucketInfo(*tf, filepath.Dir(path), old.GetDescription(), old.Year, dsv, old.GetRecordType())
+	tbi.Path = path
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmpPath := path + ".alter"
+	w, err := createYearFile(tmpPath, tbi)
+	if err != nil {
+		return err
+	}
+
+	conv := newRowConversion(old.GetDataShapes(), dsv)
+	oldRowLen, newRowLen := conv.fromLen, conv.toLen
+	if tbi.GetRecordType() == io.VARIABLE {
+		oldRowLen = int(old.GetVariableRecordLength())
+		newRowLen = int(tbi.GetVariableRecordLength())
+	}
+	err = readYearFile(src, old, func(index int64, rows []byte) error {
+		out := make([]byte, 0, len(rows)/oldRowLen*newRowLen)
+		for ; len(rows) >= oldRowLen; rows = rows[oldRowLen:] {
+			row := make([]byte, newRowLen)
+			conv.convert(row, rows)
+			// the interval ticks of a variable length row are kept
+			copy(row[conv.toLen:], rows[conv.fromLen:oldRowLen])
+			out = append(out, row...)
+		}
+		return w.write(index, out)
+	})
+	if err == nil {
+		err = w.Close()
+	} else {
+		_ = w.f.Close()
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	log.Info("altered the data shapes of %s to %v", path, dsv)
+	return rebuildBlockIndex(tbi, true)
+}
+
+/*
+rowConversion maps the fields of the rows of a layout to the fields of another one, by column name. The bits
+of the bitmap of a nullable bucket are mapped the same way.
+*/
+type rowConversion struct {
+	fields         []fieldConversion
+	fromLen, toLen int
+	// nullBits is the bit of the source bitmap of each bit of the target bitmap, -1 for a new column
+	nullBits           []int
+	nullsFrom, nullsTo int
+	fromNullable       bool
+}
+
+// noNullBit marks a column of a source without a bitmap in rowConversion.nullBits.
+const noNullBit = -2
+
+type fieldConversion struct {
+	from, to io.EnumElementType
+	// fromScale and toScale are the scales of DECIMAL64 fields
+	fromScale, toScale int8
+	// fromOffset is -1 for a new column
+	fromOffset, toOffset int
+}
+
+func newRowConversion(from, to []io.DataShape) *rowConversion {
+	c := &rowConversion{}
+	offsets := map[string]int{}
+	shapes := map[string]io.DataShape{}
+	for _, ds := range from {
+		offsets[ds.Name] = c.fromLen
+		shapes[ds.Name] = ds
+		c.fromLen += ds.Type.Size()
+	}
+	for _, ds := range to {
+		if ds.Name == io.NullsColumn {
+			c.nullsTo = c.toLen
+			c.toLen += ds.Type.Size()
+			continue
+		}
+		f := fieldConversion{
+			from: shapes[ds.Name].Type, to: ds.Type, fromScale: shapes[ds.Name].Scale, toScale: ds.Scale,
+			fromOffset: -1, toOffset: c.toLen,
+		}
+		if offset, ok := offsets[ds.Name]; ok {
+			f.fromOffset = offset
+		}
+		c.fields = append(c.fields, f)
+		c.toLen += ds.Type.Size()
+	}
+	if !io.IsNullable(to) {
+		return c
+	}
+	bits := map[string]int{}
+	for i, ds := range from {
+		bits[ds.Name] = i
+	}
+	if c.fromNullable = io.IsNullable(from); !c.fromNullable {
+		// the columns of a bucket without a bitmap are never NULL
+		for name := range bits {
+			bits[name] = noNullBit
+		}
+	}
+	c.nullsFrom = offsets[io.NullsColumn]
+	for _, ds := range to[:len(to)-1] {
+		bit, ok := bits[ds.Name]
+		if !ok {
+			bit = -1
+		}
+		c.nullBits = append(c.nullBits, bit)
+	}
+	return c
+}
+
+// convert writes the fields of the row src into dst, which is zeroed.
+func (c *rowConversion) convert(dst, src []byte) {
+	for _, f := range c.fields {
+		if f.fromOffset < 0 {
+			continue
+		}
+		convertValue(dst[f.toOffset:f.toOffset+f.to.Size()], f, src[f.fromOffset:])
+	}
+	if c.nullBits == nil {
+		return
+	}
+	var from, to uint64
+	if c.fromNullable {
+		from = binary.LittleEndian.Uint64(src[c.nullsFrom:])
+	}
+	for i, bit := range c.nullBits {
+		switch {
+		case bit == noNullBit:
+		case bit < 0, from&(1<<uint(bit)) != 0:
+			to |= 1 << uint(i)
+		}
+	}
+	binary.LittleEndian.PutUint64(dst[c.nullsTo:], to)
+}
+
+/*
+convertValue converts a little endian value between numeric types, truncating like a Go conversion.
+A DECIMAL64 value is converted from and to its value with its scale, rounded to the nearest decimal.
+*/
+func convertValue(dst []byte, f fieldConversion, src []byte) {
+	from, to := f.from, f.to
+	switch {
+	case from == io.DECIMAL64 && to == io.DECIMAL64:
+		d := io.Decimal64(binary.LittleEndian.Uint64(src)).Rescale(f.fromScale, f.toScale)
+		binary.LittleEndian.PutUint64(dst, uint64(d))
+		return
+	case from == to, io.IsHeapType(from) && io.IsHeapType(to):
+		copy(dst, src[:to.Size()])
+		return
+	}
+	var (
+		i       int64
+		v       float64
+		isFloat bool
+	)
+	switch from {
+	case io.FLOAT32:
+		v, isFloat = float64(math.Float32frombits(binary.LittleEndian.Uint32(src))), true
+	case io.FLOAT64:
+		v, isFloat = math.Float64frombits(binary.LittleEndian.Uint64(src)), true
+	case io.DECIMAL64:
+		v, isFloat = io.Decimal64(binary.LittleEndian.Uint64(src)).Float64(f.fromScale), true
+	case io.BYTE:
+		i = int64(int8(src[0]))
+	case io.INT16:
+		i = int64(int16(binary.LittleEndian.Uint16(src)))
+	case io.INT32:
+		i = int64(int32(binary.LittleEndian.Uint32(src)))
+	case io.INT64, io.EPOCH, io.UINT64:
+		i = int64(binary.LittleEndian.Uint64(src))
+	case io.BOOL, io.UINT8:
+		i = int64(src[0])
+	case io.UINT16:
+		i = int64(binary.LittleEndian.Uint16(src))
+	case io.UINT32:
+		i = int64(binary.LittleEndian.Uint32(src))
+	}
+	if isFloat {
+		i = int64(v)
+	} else {
+		v = float64(i)
+	}
+
+	switch to {
+	case io.FLOAT32:
+		binary.LittleEndian.PutUint32(dst, math.Float32bits(float32(v)))
+	case io.FLOAT64:
+		binary.LittleEndian.PutUint64(dst, math.Float64bits(v))
+	case io.DECIMAL64:
+		binary.LittleEndian.PutUint64(dst, uint64(io.NewDecimal64(v, f.toScale)))
+	case io.BOOL:
+		if v != 0 {
+			dst[0] = 1
+		}
+	default:
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], uint64(i))
+		copy(dst, buf[:to.Size()])
+	}
+}
@@ -0,0 +1,302 @@
+/*
+Package archive implements the read-only, compressed columnar format of the closed years of a time bucket.
+
+An archive file ({Year}.arc) starts with the same header as the year file ({Year}.bin) it replaces, followed by
+chunks of rows in time order, the chunk directory and a footer:
+
+	| Header | chunk 0 | chunk 1 | ... | directory | footer |
+
+A chunk stores the row count as a uvarint, then the Epoch column and the element columns, each as a uvarint
+length followed by the encoded column. The rows of a VARIABLE bucket have an additional UINT32 column with the
+interval ticks, like the rows of the year file, and the rows of an interval are never split across two chunks.
+A directory entry holds the offset, length, number of rows and the minimum and maximum Epoch of a chunk, so
+that a reader only decodes the chunks of the time range it reads.
+*/
+package archive
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+const (
+	// DefaultChunkRows is the number of rows after which a chunk is closed.
+	DefaultChunkRows = 8192
+	magic            = "MKTSARC1"
+	directoryEntry   = 40
+	footerSize       = 8 + 8 + len(magic)
+	intervalTicks    = io.UINT32
+)
+
+var ErrNotArchive = errors.New("not an archive file")
+
+// ChunkInfo describes a chunk of an archive file.
+type ChunkInfo struct {
+	Offset   int64
+	Length   int64
+	Rows     int64
+	MinEpoch int64
+	MaxEpoch int64
+}
+
+// Chunk holds the decoded rows of a chunk. Rows are packed in the same layout as the rows of the year file,
+// without the Epoch.
+type Chunk struct {
+	Epochs []int64
+	Rows   []byte
+	RowLen int
+}
+
+// Row returns the fields of the i-th row of the chunk.
+func (c *Chunk) Row(i int) []byte {
+	return c.Rows[i*c.RowLen : (i+1)*c.RowLen]
+}
+
+// columns returns the layout of the rows of a time bucket.
+func columns(tbi *io.TimeBucketInfo) (cols []column, rowLen int) {
+	types := tbi.GetElementTypes()
+	if tbi.GetRecordType() == io.VARIABLE {
+		types = append(append([]io.EnumElementType{}, types...), intervalTicks)
+	}
+	for _, typ := range types {
+		cols = append(cols, column{typ: typ, size: typ.Size(), offset: rowLen})
+		rowLen += typ.Size()
+	}
+	return cols, rowLen
+}
+
+// Writer writes the rows of a year into an archive file.
+type Writer struct {
+	// ChunkRows is the number of rows after which a chunk is closed
+	ChunkRows int
+
+	f      *os.File
+	cols   []column
+	rowLen int
+	offset int64
+	epochs []int64
+	rows   []byte
+	chunks []ChunkInfo
+}
+
+// Create creates an archive file at path for the time bucket described by tbi and writes its header.
+func Create(path string, tbi *io.TimeBucketInfo) (*Writer, error) {
+	const ownerReadWrite = 0o600
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, ownerReadWrite)
+	if err != nil {
+		return nil, fmt.Errorf("create archive %s: %w", path, err)
+	}
+	if err = io.WriteHeader(f, tbi); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("write archive header %s: %w", path, err)
+	}
+	cols, rowLen := columns(tbi)
+	return &Writer{
+		ChunkRows: DefaultChunkRows,
+		f:         f,
+		cols:      cols,
+		rowLen:    rowLen,
+		offset:    io.Headersize,
+	}, nil
+}
+
+// Append adds a row at epoch to the archive. Rows must be appended in time order, and the row holds the fields
+// of the record without the Epoch (and with the interval ticks in a VARIABLE bucket).
+func (w *Writer) Append(epoch int64, row []byte) error {
+	if len(row) != w.rowLen {
+		return fmt.Errorf("row length %d does not match the archive row length %d", len(row), w.rowLen)
+	}
+	if n := len(w.epochs); n > 0 {
+		last := w.epochs[n-1]
+		if epoch < last {
+			return fmt.Errorf("rows are not in time order: %d after %d", epoch, last)
+		}
+		if n >= w.ChunkRows && epoch != last {
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	} else if len(w.chunks) > 0 && epoch < w.chunks[len(w.chunks)-1].MaxEpoch {
+		return fmt.Errorf("rows are not in time order: %d after %d", epoch, w.chunks[len(w.chunks)-1].MaxEpoch)
+	}
+	w.epochs = append(w.epochs, epoch)
+	w.rows = append(w.rows, row...)
+	return nil
+}
+
+func (w *Writer) flush() error {
+	if len(w.epochs) == 0 {
+		return nil
+	}
+	buf := appendUvarint(nil, uint64(len(w.epochs)))
+	appendColumn := func(col []byte) {
+		buf = appendUvarint(buf, uint64(len(col)))
+		buf = append(buf, col...)
+	}
+	appendColumn(encodeEpochs(w.epochs))
+	for _, col := range w.cols {
+		appendColumn(col.encode(w.rows, w.rowLen))
+	}
+	if _, err := w.f.WriteAt(buf, w.offset); err != nil {
+		return fmt.Errorf("write archive chunk: %w", err)
+	}
+	w.chunks = append(w.chunks, ChunkInfo{
+		Offset:   w.offset,
+		Length:   int64(len(buf)),
+		Rows:     int64(len(w.epochs)),
+		MinEpoch: w.epochs[0],
+		MaxEpoch: w.epochs[len(w.epochs)-1],
+	})
+	w.offset += int64(len(buf))
+	w.epochs = w.epochs[:0]
+	w.rows = w.rows[:0]
+	return nil
+}
+
+// Close writes the remaining rows, the chunk directory and the footer, and syncs the file.
+func (w *Writer) Close() error {
+	defer w.f.Close()
+	if err := w.flush(); err != nil {
+		return err
+	}
+	buf := make([]byte, len(w.chunks)*directoryEntry, len(w.chunks)*directoryEntry+footerSize)
+	for i, c := range w.chunks {
+		entry := buf[i*directoryEntry:]
+		binary.LittleEndian.PutUint64(entry, uint64(c.Offset))
+		binary.LittleEndian.PutUint64(entry[8:], uint64(c.Length))
+		binary.LittleEndian.PutUint64(entry[16:], uint64(c.Rows))
+		binary.LittleEndian.PutUint64(entry[24:], uint64(c.MinEpoch))
+		binary.LittleEndian.PutUint64(entry[32:], uint64(c.MaxEpoch))
+	}
+	var footer [footerSize]byte
+	binary.LittleEndian.PutUint64(footer[:], uint64(w.offset))
+	binary.LittleEndian.PutUint64(footer[8:], uint64(len(w.chunks)))
+	copy(footer[16:], magic)
+	buf = append(buf, footer[:]...)
+	if _, err := w.f.WriteAt(buf, w.offset); err != nil {
+		return fmt.Errorf("write archive directory: %w", err)
+	}
+	if err := w.f.Sync(); err != nil {
+		return fmt.Errorf("sync archive: %w", err)
+	}
+	return nil
+}
+
+// File is an archive file opened for reading.
+type File struct {
+	f      *os.File
+	cols   []column
+	rowLen int
+	chunks []ChunkInfo
+}
+
+// Open opens the archive file of the time bucket described by tbi and reads its chunk directory.
+func Open(path string, tbi *io.TimeBucketInfo) (*File, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open archive %s: %w", path, err)
+	}
+	a := &File{f: f}
+	a.cols, a.rowLen = columns(tbi)
+	if err = a.readDirectory(); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return a, nil
+}
+
+func (a *File) readDirectory() error {
+	stat, err := a.f.Stat()
+	if err != nil {
+		return err
+	}
+	if stat.Size() < io.Headersize+int64(footerSize) {
+		return ErrNotArchive
+	}
+	var footer [footerSize]byte
+	if _, err = a.f.ReadAt(footer[:], stat.Size()-int64(footerSize)); err != nil {
+		return err
+	}
+	if !bytes.Equal(footer[16:], []byte(magic)) {
+		return ErrNotArchive
+	}
+	dirOffset := int64(binary.LittleEndian.Uint64(footer[:]))
+	numChunks := int64(binary.LittleEndian.Uint64(footer[8:]))
+	if dirOffset+numChunks*directoryEntry+int64(footerSize) != stat.Size() {
+		return ErrNotArchive
+	}
+	buf := make([]byte, numChunks*directoryEntry)
+	if _, err = a.f.ReadAt(buf, dirOffset); err != nil {
+		return err
+	}
+	a.chunks = make([]ChunkInfo, numChunks)
+	for i := range a.chunks {
+		entry := buf[i*directoryEntry:]
+		a.chunks[i] = ChunkInfo{
+			Offset:   int64(binary.LittleEndian.Uint64(entry)),
+			Length:   int64(binary.LittleEndian.Uint64(entry[8:])),
+			Rows:     int64(binary.LittleEndian.Uint64(entry[16:])),
+			MinEpoch: int64(binary.LittleEndian.Uint64(entry[24:])),
+			MaxEpoch: int64(binary.LittleEndian.Uint64(entry[32:])),
+		}
+	}
+	return nil
+}
+
+// Chunks returns the chunk directory of the archive.
+func (a *File) Chunks() []ChunkInfo {
+	return a.chunks
+}
+
+// RowLen returns the length of the rows of the archive.
+func (a *File) RowLen() int {
+	return a.rowLen
+}
+
+// ReadChunk reads and decodes the i-th chunk of the archive.
+func (a *File) ReadChunk(i int) (*Chunk, error) {
+	info := a.chunks[i]
+	buf := make([]byte, info.Length)
+	if _, err := a.f.ReadAt(buf, info.Offset); err != nil {
+		return nil, fmt.Errorf("read archive chunk %d: %w", i, err)
+	}
+	r := varintReader{buf: buf}
+	n, err := r.uvarint()
+	if err != nil || int64(n) != info.Rows {
+		return nil, fmt.Errorf("archive chunk %d has an invalid row count", i)
+	}
+	nextColumn := func() ([]byte, error) {
+		l, err := r.uvarint()
+		if err != nil || uint64(len(r.buf)) < l {
+			return nil, errShortColumn
+		}
+		col := r.buf[:l]
+		r.buf = r.buf[l:]
+		return col, nil
+	}
+
+	c := &Chunk{Rows: make([]byte, int(n)*a.rowLen), RowLen: a.rowLen}
+	col, err := nextColumn()
+	if err == nil {
+		c.Epochs, err = decodeEpochs(col, int(n))
+	}
+	for j := 0; err == nil && j < len(a.cols); j++ {
+		if col, err = nextColumn(); err == nil {
+			err = a.cols[j].decode(col, c.Rows, a.rowLen)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("decode archive chunk %d: %w", i, err)
+	}
+	return c, nil
+}
+
+// Close closes the archive file.
+func (a *File) Close() error {
+	return a.f.Close()
+}
@@ -0,0 +1,133 @@
+package archive_test
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alpacahq/marketstore/v4/executor/archive"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+func TestArchiveRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	dsv := io.NewDataShapeVector(
+		[]string{"F32", "F64", "I16", "I32", "I64", "U64", "Flag", "Name"},
+		[]io.EnumElementType{io.FLOAT32, io.FLOAT64, io.INT16, io.INT32, io.INT64, io.UINT64, io.BOOL, io.STRING16},
+	)
+	tests := map[string]struct {
+		recordType io.EnumRecordType
+		rows       int
+		chunkRows  int
+	}{
+		"fixed, several chunks":         {recordType: io.FIXED, rows: 1000, chunkRows: 300},
+		"fixed, one chunk":              {recordType: io.FIXED, rows: 10, chunkRows: archive.DefaultChunkRows},
+		"variable, intervals not split": {recordType: io.VARIABLE, rows: 1000, chunkRows: 7},
+		"empty":                         {recordType: io.FIXED, rows: 0, chunkRows: archive.DefaultChunkRows},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			dir := t.TempDir()
+			tbi := io.NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), dir, "Test", 2020, dsv, tt.recordType)
+			path := filepath.Join(dir, "2020.arc")
+
+			w, err := archive.Create(path, tbi)
+			require.Nil(t, err)
+			w.ChunkRows = tt.chunkRows
+			var (
+				epochs []int64
+				rows   [][]byte
+			)
+			for i := 0; i < tt.rows; i++ {
+				epoch := int64(1577836800 + 60*i)
+				if tt.recordType == io.VARIABLE {
+					// 3 rows per interval
+					epoch = int64(1577836800 + 60*(i/3))
+				}
+				row := makeRow(i, tt.recordType == io.VARIABLE)
+				require.Nil(t, w.Append(epoch, row))
+				epochs = append(epochs, epoch)
+				rows = append(rows, row)
+			}
+			require.Nil(t, w.Close())
+
+			a, err := archive.Open(path, tbi)
+			require.Nil(t, err)
+			defer a.Close()
+			// the header of the year file is kept
+			h := &io.TimeBucketInfo{Path: path}
+			assert.Equal(t, tbi.GetElementNames(), h.GetElementNames())
+
+			var n int
+			for i, info := range a.Chunks() {
+				c, err := a.ReadChunk(i)
+				require.Nil(t, err)
+				require.Equal(t, int(info.Rows), len(c.Epochs))
+				assert.Equal(t, info.MinEpoch, c.Epochs[0])
+				assert.Equal(t, info.MaxEpoch, c.Epochs[len(c.Epochs)-1])
+				if i > 0 {
+					assert.True(t, a.Chunks()[i-1].MaxEpoch < info.MinEpoch)
+				}
+				for j := range c.Epochs {
+					assert.Equal(t, epochs[n], c.Epochs[j])
+					assert.Equal(t, rows[n], c.Row(j))
+					n++
+				}
+			}
+			assert.Equal(t, tt.rows, n)
+		})
+	}
+}
+
+func TestArchiveOrder(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dsv := io.NewDataShapeVector([]string{"Price"}, []io.EnumElementType{io.FLOAT64})
+	tbi := io.NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), dir, "Test", 2020, dsv, io.FIXED)
+	w, err := archive.Create(filepath.Join(dir, "2020.arc"), tbi)
+	require.Nil(t, err)
+	require.Nil(t, w.Append(120, make([]byte, 8)))
+	assert.NotNil(t, w.Append(60, make([]byte, 8)))
+	assert.NotNil(t, w.Append(180, make([]byte, 4)))
+	require.Nil(t, w.Close())
+
+	// a year file is not an archive
+	yearFile := filepath.Join(dir, "2021.bin")
+	require.Nil(t, os.WriteFile(yearFile, make([]byte, io.Headersize+100), 0o600))
+	_, err = archive.Open(yearFile, tbi)
+	assert.ErrorIs(t, err, archive.ErrNotArchive)
+}
+
+func makeRow(i int, variable bool) []byte {
+	var row []byte
+	row = appendUint(row, uint64(math.Float32bits(float32(i)*0.25)), 4)
+	row = appendUint(row, math.Float64bits(100+float64(i%17)*0.01), 8)
+	row = appendUint(row, uint64(-i), 2)
+	row = appendUint(row, uint64(i*i), 4)
+	row = appendUint(row, uint64(-int64(i)<<40), 8)
+	row = appendUint(row, math.MaxUint64-uint64(i), 8)
+	row = append(row, byte(i%2))
+	name := make([]byte, 64)
+	copy(name, []string{"A", "BB", "CCC"}[i%3])
+	row = append(row, name...)
+	if variable {
+		row = appendUint(row, uint64(i)*1000, 4)
+	}
+	return row
+}
+
+func appendUint(b []byte, v uint64, size int) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:size]...)
+}
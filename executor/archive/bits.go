@@ -0,0 +1,72 @@
+package archive
+
+import (
+	"errors"
+)
+
+var errShortColumn = errors.New("archive column is truncated")
+
+// bitWriter appends values to a byte slice one bit at a time, most significant bit first.
+type bitWriter struct {
+	buf []byte
+	// used is the number of bits used in the last byte of buf
+	used uint
+}
+
+func (w *bitWriter) writeBit(bit bool) {
+	if bit {
+		w.writeBits(1, 1)
+	} else {
+		w.writeBits(0, 1)
+	}
+}
+
+// writeBits writes the nbits lowest bits of v.
+func (w *bitWriter) writeBits(v uint64, nbits uint) {
+	for nbits > 0 {
+		if w.used == 0 || w.used == 8 {
+			w.buf = append(w.buf, 0)
+			w.used = 0
+		}
+		free := 8 - w.used
+		take := free
+		if nbits < take {
+			take = nbits
+		}
+		bits := byte(v>>(nbits-take)) & byte(1<<take-1)
+		w.buf[len(w.buf)-1] |= bits << (free - take)
+		w.used += take
+		nbits -= take
+	}
+}
+
+// bitReader reads the bits written by a bitWriter.
+type bitReader struct {
+	buf []byte
+	pos uint
+}
+
+func (r *bitReader) readBit() (bool, error) {
+	v, err := r.readBits(1)
+	return v == 1, err
+}
+
+func (r *bitReader) readBits(nbits uint) (uint64, error) {
+	if r.pos+nbits > uint(len(r.buf))*8 {
+		return 0, errShortColumn
+	}
+	var v uint64
+	for nbits > 0 {
+		used := r.pos % 8
+		free := 8 - used
+		take := free
+		if nbits < take {
+			take = nbits
+		}
+		bits := (r.buf[r.pos/8] >> (free - take)) & byte(1<<take-1)
+		v = v<<take | uint64(bits)
+		r.pos += take
+		nbits -= take
+	}
+	return v, nil
+}
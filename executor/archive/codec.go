@@ -0,0 +1,327 @@
+package archive
+
+import (
+	"encoding/binary"
+	"math/bits"
+
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+The columns of a chunk are encoded depending on their type:
+  - Epoch: the first value, then the first delta, then the deltas of the deltas, as zigzag varints
+  - FLOAT32 and FLOAT64: XOR of each value with the previous one, bit packed (Gorilla)
+  - STRING16 and wider types: a dictionary of the distinct values, then a uvarint dictionary index per row
+  - integers, BYTE and BOOL: the delta with the previous value as a zigzag varint
+*/
+
+const (
+	leadingZerosBits = 5
+	significantBits  = 6
+	maxLeadingZeros  = 1<<leadingZerosBits - 1
+	maxIntegerSize   = 8
+)
+
+func appendVarint(buf []byte, v int64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+func appendUvarint(buf []byte, v uint64) []byte {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	return append(buf, tmp[:n]...)
+}
+
+// varintReader reads consecutive varints from a column.
+type varintReader struct {
+	buf []byte
+}
+
+func (r *varintReader) varint() (int64, error) {
+	v, n := binary.Varint(r.buf)
+	if n <= 0 {
+		return 0, errShortColumn
+	}
+	r.buf = r.buf[n:]
+	return v, nil
+}
+
+func (r *varintReader) uvarint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		return 0, errShortColumn
+	}
+	r.buf = r.buf[n:]
+	return v, nil
+}
+
+func encodeEpochs(epochs []int64) []byte {
+	var (
+		buf             []byte
+		prev, prevDelta int64
+	)
+	for i, epoch := range epochs {
+		switch i {
+		case 0:
+			buf = appendVarint(buf, epoch)
+		default:
+			delta := epoch - prev
+			buf = appendVarint(buf, delta-prevDelta)
+			prevDelta = delta
+		}
+		prev = epoch
+	}
+	return buf
+}
+
+func decodeEpochs(buf []byte, n int) ([]int64, error) {
+	var (
+		r               = varintReader{buf: buf}
+		prev, prevDelta int64
+	)
+	epochs := make([]int64, n)
+	for i := range epochs {
+		v, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			prev = v
+		} else {
+			prevDelta += v
+			prev += prevDelta
+		}
+		epochs[i] = prev
+	}
+	return epochs, nil
+}
+
+// column is an element of the rows of a chunk.
+type column struct {
+	typ    io.EnumElementType
+	size   int
+	offset int
+}
+
+func (c column) signed() bool {
+	switch c.typ {
+	case io.BYTE, io.INT16, io.INT32, io.INT64, io.EPOCH:
+		return true
+	default:
+		return false
+	}
+}
+
+// value returns the little endian value of the column in a row, sign extended for the signed types.
+func (c column) value(row []byte) uint64 {
+	var v uint64
+	for i := c.size - 1; i >= 0; i-- {
+		v = v<<8 | uint64(row[c.offset+i])
+	}
+	if c.signed() {
+		shift := uint(64 - 8*c.size)
+		v = uint64(int64(v<<shift) >> shift)
+	}
+	return v
+}
+
+func (c column) setValue(row []byte, v uint64) {
+	for i := 0; i < c.size; i++ {
+		row[c.offset+i] = byte(v)
+		v >>= 8
+	}
+}
+
+func (c column) encode(rows []byte, rowLen int) []byte {
+	n := len(rows) / rowLen
+	switch {
+	case c.typ == io.FLOAT32 || c.typ == io.FLOAT64:
+		return c.encodeXOR(rows, rowLen, n)
+	case c.size > maxIntegerSize:
+		return c.encodeDictionary(rows, rowLen, n)
+	default:
+		return c.encodeDelta(rows, rowLen, n)
+	}
+}
+
+func (c column) decode(buf, rows []byte, rowLen int) error {
+	n := len(rows) / rowLen
+	switch {
+	case c.typ == io.FLOAT32 || c.typ == io.FLOAT64:
+		return c.decodeXOR(buf, rows, rowLen, n)
+	case c.size > maxIntegerSize:
+		return c.decodeDictionary(buf, rows, rowLen, n)
+	default:
+		return c.decodeDelta(buf, rows, rowLen, n)
+	}
+}
+
+func (c column) encodeDelta(rows []byte, rowLen, n int) []byte {
+	var (
+		buf  []byte
+		prev int64
+	)
+	for i := 0; i < n; i++ {
+		v := int64(c.value(rows[i*rowLen:]))
+		buf = appendVarint(buf, v-prev)
+		prev = v
+	}
+	return buf
+}
+
+func (c column) decodeDelta(buf, rows []byte, rowLen, n int) error {
+	var (
+		r    = varintReader{buf: buf}
+		prev int64
+	)
+	for i := 0; i < n; i++ {
+		delta, err := r.varint()
+		if err != nil {
+			return err
+		}
+		prev += delta
+		c.setValue(rows[i*rowLen:], uint64(prev))
+	}
+	return nil
+}
+
+func (c column) encodeXOR(rows []byte, rowLen, n int) []byte {
+	var (
+		w                         bitWriter
+		width                     = 8 * c.size
+		prev                      uint64
+		prevLeading, prevTrailing = -1, 0
+	)
+	for i := 0; i < n; i++ {
+		v := c.value(rows[i*rowLen:])
+		if i == 0 {
+			w.writeBits(v, uint(width))
+			prev = v
+			continue
+		}
+		x := v ^ prev
+		prev = v
+		if x == 0 {
+			w.writeBit(false)
+			continue
+		}
+		w.writeBit(true)
+		leading := bits.LeadingZeros64(x) - (64 - width)
+		trailing := bits.TrailingZeros64(x)
+		if leading > maxLeadingZeros {
+			leading = maxLeadingZeros
+		}
+		if prevLeading >= 0 && leading >= prevLeading && trailing >= prevTrailing {
+			// the meaningful bits fit in the window of the previous value
+			w.writeBit(false)
+			w.writeBits(x>>uint(prevTrailing), uint(width-prevLeading-prevTrailing))
+			continue
+		}
+		w.writeBit(true)
+		significant := width - leading - trailing
+		w.writeBits(uint64(leading), leadingZerosBits)
+		w.writeBits(uint64(significant-1), significantBits)
+		w.writeBits(x>>uint(trailing), uint(significant))
+		prevLeading, prevTrailing = leading, trailing
+	}
+	return w.buf
+}
+
+func (c column) decodeXOR(buf, rows []byte, rowLen, n int) error {
+	var (
+		r                         = bitReader{buf: buf}
+		width                     = 8 * c.size
+		prev                      uint64
+		prevLeading, prevTrailing int
+	)
+	for i := 0; i < n; i++ {
+		if i == 0 {
+			v, err := r.readBits(uint(width))
+			if err != nil {
+				return err
+			}
+			prev = v
+			c.setValue(rows, prev)
+			continue
+		}
+		changed, err := r.readBit()
+		if err != nil {
+			return err
+		}
+		if changed {
+			newWindow, err := r.readBit()
+			if err != nil {
+				return err
+			}
+			if newWindow {
+				leading, err := r.readBits(leadingZerosBits)
+				if err != nil {
+					return err
+				}
+				significant, err := r.readBits(significantBits)
+				if err != nil {
+					return err
+				}
+				prevLeading = int(leading)
+				prevTrailing = width - prevLeading - int(significant+1)
+			}
+			x, err := r.readBits(uint(width - prevLeading - prevTrailing))
+			if err != nil {
+				return err
+			}
+			prev ^= x << uint(prevTrailing)
+		}
+		c.setValue(rows[i*rowLen:], prev)
+	}
+	return nil
+}
+
+func (c column) encodeDictionary(rows []byte, rowLen, n int) []byte {
+	var (
+		indexes    = make([]int, n)
+		dictionary = map[string]int{}
+		values     []byte
+	)
+	for i := 0; i < n; i++ {
+		v := rows[i*rowLen+c.offset : i*rowLen+c.offset+c.size]
+		idx, ok := dictionary[string(v)]
+		if !ok {
+			idx = len(dictionary)
+			dictionary[string(v)] = idx
+			values = append(values, v...)
+		}
+		indexes[i] = idx
+	}
+	buf := appendUvarint(nil, uint64(len(dictionary)))
+	buf = append(buf, values...)
+	for _, idx := range indexes {
+		buf = appendUvarint(buf, uint64(idx))
+	}
+	return buf
+}
+
+func (c column) decodeDictionary(buf, rows []byte, rowLen, n int) error {
+	r := varintReader{buf: buf}
+	count, err := r.uvarint()
+	if err != nil {
+		return err
+	}
+	if uint64(len(r.buf)) < count*uint64(c.size) {
+		return errShortColumn
+	}
+	values := r.buf[:count*uint64(c.size)]
+	r.buf = r.buf[len(values):]
+	for i := 0; i < n; i++ {
+		idx, err := r.uvarint()
+		if err != nil {
+			return err
+		}
+		if idx >= count {
+			return errShortColumn
+		}
+		copy(rows[i*rowLen+c.offset:i*rowLen+c.offset+c.size], values[idx*uint64(c.size):])
+	}
+	return nil
+}
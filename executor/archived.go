@@ -0,0 +1,352 @@
+package executor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/executor/archive"
+	. "github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// primaryFile is the primary area of a year, read by the scanner.
+type primaryFile interface {
+	io.ReadSeeker
+	io.Closer
+}
+
+// dataFile is the data area of the variable length records of a year, read in the second stage.
+type dataFile interface {
+	io.ReaderAt
+	io.Closer
+}
+
+// openPrimary opens the primary area of the year of a plan, the offloaded years being read from the cache.
+func openPrimary(fp *ioFilePlan) (primaryFile, error) {
+	const readWriteAll = 0o666
+	tbi, err := localYear(fp.tbi)
+	if err != nil {
+		return nil, err
+	}
+	if !tbi.IsArchived() {
+		return os.OpenFile(tbi.Path, os.O_RDONLY, readWriteAll)
+	}
+	ay, err := openArchivedYear(tbi)
+	if err != nil {
+		return nil, err
+	}
+	return &archivePrimary{
+		archivedYear: ay,
+		recordLen:    int64(fp.tbi.GetRecordLength()),
+		size:         FileSize(fp.tbi.GetTimeframe(), int(fp.tbi.Year), int(fp.tbi.GetRecordLength())),
+	}, nil
+}
+
+func openData(md *bufferMeta) (dataFile, error) {
+	const readWriteAll = 0o666
+	tbi, err := localYear(md.tbi)
+	if err != nil {
+		return nil, err
+	}
+	if !tbi.IsArchived() {
+		return os.OpenFile(tbi.Path, os.O_RDONLY, readWriteAll)
+	}
+	ay, err := openArchivedYear(tbi)
+	if err != nil {
+		return nil, err
+	}
+	return &archiveData{archivedYear: ay}, nil
+}
+
+/*
+archivedYear reads the chunks of an archived year. The last decoded chunks are cached,
+as the scanner reads the records of a chunk in several buffers.
+*/
+type archivedYear struct {
+	tbi  *TimeBucketInfo
+	file *archive.File
+	// firstRows holds the number of rows before each chunk
+	firstRows []int64
+	cache     [2]struct {
+		index int
+		chunk *archive.Chunk
+	}
+}
+
+func openArchivedYear(tbi *TimeBucketInfo) (*archivedYear, error) {
+	file, err := archive.Open(tbi.Path, tbi)
+	if err != nil {
+		return nil, err
+	}
+	ay := &archivedYear{tbi: tbi, file: file}
+	var rows int64
+	for _, c := range file.Chunks() {
+		ay.firstRows = append(ay.firstRows, rows)
+		rows += c.Rows
+	}
+	for i := range ay.cache {
+		ay.cache[i].index = -1
+	}
+	return ay, nil
+}
+
+func (ay *archivedYear) chunk(i int) (*archive.Chunk, error) {
+	for _, c := range ay.cache {
+		if c.index == i {
+			return c.chunk, nil
+		}
+	}
+	chunk, err := ay.file.ReadChunk(i)
+	if err != nil {
+		return nil, err
+	}
+	ay.cache[1] = ay.cache[0]
+	ay.cache[0].index, ay.cache[0].chunk = i, chunk
+	return chunk, nil
+}
+
+func (ay *archivedYear) Close() error {
+	return ay.file.Close()
+}
+
+/*
+archivePrimary presents the primary area of an archived year as it is laid out in the year file:
+the records of the rows in a FIXED bucket, and the {index, offset, len} triplets of the intervals
+in a VARIABLE bucket, where the offset and len address the rows in the archiveData of the year.
+Only the chunks that overlap the time range of a read are decoded.
+*/
+type archivePrimary struct {
+	*archivedYear
+	recordLen int64
+	size      int64
+	pos       int64
+}
+
+func (p *archivePrimary) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += p.pos
+	case io.SeekEnd:
+		offset += p.size
+	default:
+		return p.pos, fmt.Errorf("invalid whence %d", whence)
+	}
+	if offset < 0 {
+		return p.pos, fmt.Errorf("negative position %d", offset)
+	}
+	p.pos = offset
+	return p.pos, nil
+}
+
+func (p *archivePrimary) Read(b []byte) (n int, err error) {
+	if p.pos >= p.size {
+		return 0, io.EOF
+	}
+	n = len(b)
+	if int64(n) > p.size-p.pos {
+		n = int(p.size - p.pos)
+	}
+	start, end := p.pos, p.pos+int64(n)
+	b = b[:n]
+	for i := range b {
+		b[i] = 0
+	}
+
+	// the epochs of the records overlapping [start, end)
+	tf := p.tbi.GetTimeframe()
+	minEpoch := int64(math.MinInt64)
+	if start >= Headersize {
+		minEpoch = IndexToTime((start-Headersize)/p.recordLen+1, tf, p.tbi.Year).Unix()
+	}
+	maxEpoch := IndexToTime((end-1-Headersize)/p.recordLen+2, tf, p.tbi.Year).Unix()
+
+	isVariable := p.tbi.GetRecordType() == VARIABLE
+	record := make([]byte, p.recordLen)
+	for i, info := range p.file.Chunks() {
+		if info.MaxEpoch < minEpoch || info.MinEpoch >= maxEpoch {
+			continue
+		}
+		chunk, err := p.chunk(i)
+		if err != nil {
+			return 0, err
+		}
+		row := sort.Search(len(chunk.Epochs), func(j int) bool { return chunk.Epochs[j] >= minEpoch })
+		for row < len(chunk.Epochs) && chunk.Epochs[row] < maxEpoch {
+			epoch := chunk.Epochs[row]
+			index := EpochToIndex(epoch, tf)
+			rows := 1
+			for isVariable && row+rows < len(chunk.Epochs) && chunk.Epochs[row+rows] == epoch {
+				rows++
+			}
+
+			binary.LittleEndian.PutUint64(record, uint64(index))
+			if isVariable {
+				rowLen := int64(chunk.RowLen)
+				binary.LittleEndian.PutUint64(record[8:], uint64((p.firstRows[i]+int64(row))*rowLen))
+				binary.LittleEndian.PutUint64(record[16:], uint64(int64(rows)*rowLen))
+			} else {
+				copy(record[epochLenBytes:], chunk.Row(row))
+			}
+			// copy the part of the record within the read
+			offset := IndexToOffset(index, int32(p.recordLen))
+			from, to := offset, offset+p.recordLen
+			if from < start {
+				from = start
+			}
+			if to > end {
+				to = end
+			}
+			if from < to {
+				copy(b[from-start:to-start], record[from-offset:to-offset])
+			}
+			row += rows
+		}
+	}
+	p.pos = end
+	return n, nil
+}
+
+// archiveData reads the rows of an archived year, uncompressed, at the offsets of the archivePrimary.
+type archiveData struct {
+	*archivedYear
+}
+
+func (d *archiveData) ReadAt(b []byte, off int64) (n int, err error) {
+	rowLen := int64(d.file.RowLen())
+	for n < len(b) {
+		row := (off + int64(n)) / rowLen
+		i := sort.Search(len(d.firstRows), func(j int) bool { return d.firstRows[j] > row }) - 1
+		if i < 0 || row >= d.firstRows[i]+d.file.Chunks()[i].Rows {
+			return n, io.EOF
+		}
+		chunk, err := d.chunk(i)
+		if err != nil {
+			return n, err
+		}
+		pos := (off+int64(n))%rowLen + (row-d.firstRows[i])*rowLen
+		n += copy(b[n:], chunk.Rows[pos:])
+	}
+	return n, nil
+}
+
+/*
+ArchiveYear converts the year file of a time bucket into an archive file, which replaces it.
+The catalog of a running instance is not updated, so the conversion is meant to be done while
+the instance is stopped.
+*/
+func ArchiveYear(tbi *TimeBucketInfo) error {
+	if tbi.IsArchived() {
+		return fmt.Errorf("year %d is already archived: %s", tbi.Year, tbi.Path)
+	}
+	arcPath := strings.TrimSuffix(tbi.Path, YearFileExt) + ArchiveFileExt
+	tmpPath := arcPath + ".tmp"
+
+	f, err := os.Open(tbi.Path)
+	if err != nil {
+		return fmt.Errorf("open year file %s: %w", tbi.Path, err)
+	}
+	defer f.Close()
+	w, err := archive.Create(tmpPath, tbi)
+	if err != nil {
+		return err
+	}
+	if err = appendYear(w, f, tbi); err != nil {
+		_ = w.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("archive %s: %w", tbi.Path, err)
+	}
+	if err = w.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return err
+	}
+	if err = os.Rename(tmpPath, arcPath); err != nil {
+		return fmt.Errorf("rename archive %s: %w", tmpPath, err)
+	}
+	if err = os.Remove(tbi.Path); err != nil {
+		return fmt.Errorf("remove year file %s: %w", tbi.Path, err)
+	}
+	tbi.Path = arcPath
+	return nil
+}
+
+// appendYear appends the rows of a year file to an archive, in time order.
+func appendYear(w *archive.Writer, f *os.File, tbi *TimeBucketInfo) error {
+	varRecLen := int(tbi.GetVariableRecordLength())
+	return readYearFile(f, tbi, func(index int64, rows []byte) error {
+		epoch := IndexToTime(index, tbi.GetTimeframe(), tbi.Year).Unix()
+		if tbi.GetRecordType() != VARIABLE {
+			return w.Append(epoch, rows)
+		}
+		for ; len(rows) >= varRecLen; rows = rows[varRecLen:] {
+			if err := w.Append(epoch, rows[:varRecLen]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+/*
+RestoreYear converts an archive file back into the year file of a time bucket, which replaces it.
+Like ArchiveYear, it is meant to be done while the instance is stopped.
+*/
+func RestoreYear(tbi *TimeBucketInfo) error {
+	if !tbi.IsArchived() {
+		return fmt.Errorf("year %d is not archived: %s", tbi.Year, tbi.Path)
+	}
+	binPath := strings.TrimSuffix(tbi.Path, ArchiveFileExt) + YearFileExt
+	tmpPath := binPath + ".tmp"
+
+	a, err := archive.Open(tbi.Path, tbi)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	if err = writeYear(tmpPath, a, tbi); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("restore %s: %w", tbi.Path, err)
+	}
+	if err = os.Rename(tmpPath, binPath); err != nil {
+		return fmt.Errorf("rename year file %s: %w", tmpPath, err)
+	}
+	if err = os.Remove(tbi.Path); err != nil {
+		return fmt.Errorf("remove archive %s: %w", tbi.Path, err)
+	}
+	tbi.Path = binPath
+	return nil
+}
+
+// writeYear writes the rows of an archive into a new year file.
+func writeYear(path string, a *archive.File, tbi *TimeBucketInfo) error {
+	w, err := createYearFile(path, tbi)
+	if err != nil {
+		return err
+	}
+	tf := tbi.GetTimeframe()
+	for i := range a.Chunks() {
+		chunk, err := a.ReadChunk(i)
+		if err != nil {
+			_ = w.f.Close()
+			return err
+		}
+		for row := 0; row < len(chunk.Epochs); {
+			// the rows of an interval are written together
+			last := row + 1
+			for tbi.GetRecordType() == VARIABLE && last < len(chunk.Epochs) && chunk.Epochs[last] == chunk.Epochs[row] {
+				last++
+			}
+			err = w.write(EpochToIndex(chunk.Epochs[row], tf), chunk.Rows[row*chunk.RowLen:last*chunk.RowLen])
+			if err != nil {
+				_ = w.f.Close()
+				return err
+			}
+			row = last
+		}
+	}
+	return w.Close()
+}
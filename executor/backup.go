@@ -0,0 +1,499 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goio "io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor/wal"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+const (
+	// BackupManifestFile is the name of the manifest in a backup directory.
+	BackupManifestFile = "manifest.json"
+
+	backupDataDir       = "data"
+	backupWALDir        = "wal"
+	backupFormatVersion = 1
+	backupDirPerm       = 0o700
+	backupFilePerm      = 0o600
+)
+
+// BackupFile is a file of a backup.
+type BackupFile struct {
+	// Path is the slash separated path of the file, relative to the data or the WAL directory of the backup
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+	// TGID is the ID of the last transaction group written to a data file when it was copied
+	TGID int64 `json:"tgid,omitempty"`
+	// Linked is set if the file is a hard link to an archive file, which is never written
+	Linked bool `json:"linked,omitempty"`
+}
+
+/*
+BackupManifest describes a backup directory:
+
+	| manifest.json | data/{root directory files} | wal/{WAL tail} |
+
+The files of each directory of the root directory are copied at a transaction group boundary, while the writes
+to the other directories go on. The WAL tail holds the transaction groups written while the files were copied,
+so that the restore can bring every file to the state of the last transaction group of the backup (TGID).
+*/
+type BackupManifest struct {
+	Version       int          `json:"version"`
+	Created       time.Time    `json:"created"`
+	RootDirectory string       `json:"root_directory"`
+	TGID          int64        `json:"tgid"`
+	Files         []BackupFile `json:"files"`
+	WALFiles      []BackupFile `json:"wal_files"`
+}
+
+// Size returns the total size of the files of the backup.
+func (m *BackupManifest) Size() (size int64) {
+	for _, f := range m.Files {
+		size += f.Size
+	}
+	for _, f := range m.WALFiles {
+		size += f.Size
+	}
+	return size
+}
+
+/*
+Backup copies the files of the root directory into a new backup directory at dest, while the writes go on.
+The year files are copied and the archive files, which are never written, are hard linked when possible.
+The WAL file is not truncated during the backup.
+*/
+func (wf *WALFileType) Backup(dest string) (*BackupManifest, error) {
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return nil, err
+	}
+	if rel, err2 := filepath.Rel(wf.rootDir, dest); err2 == nil && !strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("backup directory %s is in the root directory %s", dest, wf.rootDir)
+	}
+	if err = createEmptyDir(dest); err != nil {
+		return nil, err
+	}
+	defer wf.HoldRotation()()
+
+	b := &backup{
+		rootDir: wf.rootDir,
+		dest:    dest,
+		copied:  map[string]bool{},
+		manifest: &BackupManifest{
+			Version:       backupFormatVersion,
+			Created:       time.Now().UTC(),
+			RootDirectory: wf.rootDir,
+		},
+	}
+	// the transaction groups are not in the WAL file with walBypass, so the files are copied at once
+	if !wf.walBypass {
+		dirs, err2 := b.dataDirs()
+		if err2 != nil {
+			return nil, err2
+		}
+		for _, dir := range dirs {
+			dir := dir
+			if err = wf.Quiesce(func(tgID int64) error { return b.copyDir(dir, tgID) }); err != nil {
+				return nil, fmt.Errorf("backup of %s: %w", dir, err)
+			}
+		}
+	}
+	// the files created during the backup are copied with the WAL tail
+	err = wf.Quiesce(func(tgID int64) error {
+		dirs, err2 := b.dataDirs()
+		if err2 != nil {
+			return err2
+		}
+		for _, dir := range dirs {
+			if err2 = b.copyDir(dir, tgID); err2 != nil {
+				return err2
+			}
+		}
+		b.manifest.TGID = tgID
+		if wf.walBypass {
+			return nil
+		}
+		return b.copyWALTail(wf.FilePtr.Name())
+	})
+	if err != nil {
+		return nil, fmt.Errorf("backup to %s: %w", dest, err)
+	}
+	if err = writeManifest(dest, b.manifest); err != nil {
+		return nil, err
+	}
+	log.Info("backed up %d files of %s to %s, up to transaction group %d",
+		len(b.manifest.Files), wf.rootDir, dest, b.manifest.TGID)
+	return b.manifest, nil
+}
+
+// Backup copies the files of the root directory into a new backup directory at dest, see WALFileType.Backup.
+func (w *Writer) Backup(dest string) (*BackupManifest, error) {
+	return w.walFile.Backup(dest)
+}
+
+type backup struct {
+	rootDir  string
+	dest     string
+	copied   map[string]bool
+	manifest *BackupManifest
+}
+
+// dataDirs returns the directories of the root directory.
+func (b *backup) dataDirs() (dirs []string, err error) {
+	err = filepath.WalkDir(b.rootDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			dirs = append(dirs, path)
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// copyDir copies the files of a directory of the root directory that are not copied yet.
+func (b *backup) copyDir(dir string, tgID int64) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if !entry.Type().IsRegular() || b.copied[path] || (dir == b.rootDir && isWALFile(entry.Name())) {
+			continue
+		}
+		rel, err := filepath.Rel(b.rootDir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(b.dest, backupDataDir, rel)
+		if err = os.MkdirAll(filepath.Dir(target), backupDirPerm); err != nil {
+			return err
+		}
+		f := BackupFile{Path: filepath.ToSlash(rel), TGID: tgID}
+		if filepath.Ext(path) == io.ArchiveFileExt && os.Link(path, target) == nil {
+			f.Linked = true
+			f.Size, f.SHA256, err = hashFile(path)
+		} else {
+			f.Size, f.SHA256, err = copyFile(path, target)
+		}
+		if err != nil {
+			return fmt.Errorf("copy %s: %w", path, err)
+		}
+		b.copied[path] = true
+		b.manifest.Files = append(b.manifest.Files, f)
+	}
+	return nil
+}
+
+// copyWALTail copies the WAL file, which holds the transaction groups written since the backup started.
+func (b *backup) copyWALTail(walPath string) error {
+	name := filepath.Base(walPath)
+	target := filepath.Join(b.dest, backupWALDir, name)
+	if err := os.MkdirAll(filepath.Dir(target), backupDirPerm); err != nil {
+		return err
+	}
+	size, sum, err := copyFile(walPath, target)
+	if err != nil {
+		return fmt.Errorf("copy the WAL file %s: %w", walPath, err)
+	}
+	b.manifest.WALFiles = append(b.manifest.WALFiles, BackupFile{Path: name, Size: size, SHA256: sum})
+	return nil
+}
+
+// ReadBackupManifest reads the manifest of the backup directory dir.
+func ReadBackupManifest(dir string) (*BackupManifest, error) {
+	data, err := os.ReadFile(filepath.Join(dir, BackupManifestFile))
+	if err != nil {
+		return nil, fmt.Errorf("read the backup manifest: %w", err)
+	}
+	m := &BackupManifest{}
+	if err = json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("parse the backup manifest: %w", err)
+	}
+	if m.Version != backupFormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d", m.Version)
+	}
+	return m, nil
+}
+
+// Verify checks the size and the checksum of the files of the backup directory dir.
+func (m *BackupManifest) Verify(dir string) error {
+	for _, files := range []struct {
+		dir   string
+		files []BackupFile
+	}{{dir: backupDataDir, files: m.Files}, {dir: backupWALDir, files: m.WALFiles}} {
+		for _, f := range files.files {
+			path := filepath.Join(dir, files.dir, filepath.FromSlash(f.Path))
+			size, sum, err := hashFile(path)
+			if err != nil {
+				return fmt.Errorf("verify the backup: %w", err)
+			}
+			if size != f.Size || sum != f.SHA256 {
+				return fmt.Errorf("verify the backup: %s does not match its checksum", path)
+			}
+		}
+	}
+	return nil
+}
+
+/*
+RestoreBackup restores the backup directory backupDir into rootDir, which must not exist or be empty.
+The files of the backup are verified and copied, then the transaction groups of the WAL tail of the backup
+and of the WAL files walFiles (e.g. the ones of the failed instance) are replayed, up to the transaction
+group tgID, or all of them if tgID is zero. It returns the ID of the last transaction group restored.
+
+The server must not use rootDir during the restore.
+*/
+func RestoreBackup(backupDir, rootDir string, tgID int64, walFiles []string) (restored int64, err error) {
+	m, err := ReadBackupManifest(backupDir)
+	if err != nil {
+		return 0, err
+	}
+	if tgID == 0 {
+		tgID = math.MaxInt64
+	} else if tgID < m.TGID {
+		return 0, fmt.Errorf("the backup is as of transaction group %d, it can not be restored to %d", m.TGID, tgID)
+	}
+	if err = m.Verify(backupDir); err != nil {
+		return 0, err
+	}
+	if rootDir, err = filepath.Abs(rootDir); err != nil {
+		return 0, err
+	}
+	if err = createEmptyDir(rootDir); err != nil {
+		return 0, err
+	}
+
+	fileTGIDs := map[string]int64{}
+	for _, f := range m.Files {
+		path := filepath.Join(rootDir, filepath.FromSlash(f.Path))
+		if err = os.MkdirAll(filepath.Dir(path), backupDirPerm); err != nil {
+			return 0, err
+		}
+		// the restored files are written, so they are never linked to the backup
+		if _, _, err = copyFile(filepath.Join(backupDir, backupDataDir, filepath.FromSlash(f.Path)), path); err != nil {
+			return 0, fmt.Errorf("restore %s: %w", f.Path, err)
+		}
+		fileTGIDs[path] = f.TGID
+	}
+
+	for _, f := range m.WALFiles {
+		walFiles = append([]string{filepath.Join(backupDir, backupWALDir, f.Path)}, walFiles...)
+	}
+	groups := map[int64][]byte{}
+	for _, path := range walFiles {
+		if err = readWALGroups(path, groups); err != nil {
+			return 0, err
+		}
+	}
+	var tgIDs TGIDlist
+	for id := range groups {
+		if id <= tgID {
+			tgIDs = append(tgIDs, id)
+		}
+	}
+	sort.Sort(tgIDs)
+
+	restored = m.TGID
+	r := &restorer{rootDir: rootDir}
+	for _, id := range tgIDs {
+		_, wtSets := ParseTGData(groups[id], rootDir)
+		// the groups written to a file before it was copied are skipped
+		var pending []wal.WTSet
+		for _, wtSet := range wtSets {
+			if id > fileTGIDs[wtSet.FilePath] {
+				pending = append(pending, wtSet)
+			}
+		}
+		if err = r.createFiles(pending); err != nil {
+			return 0, fmt.Errorf("restore transaction group %d: %w", id, err)
+		}
+		if err = applyTGData(pending); err != nil {
+			return 0, fmt.Errorf("restore transaction group %d: %w", id, err)
+		}
+		if id > restored {
+			restored = id
+		}
+	}
+	io.Syncfs()
+	log.Info("restored %s into %s, up to transaction group %d", backupDir, rootDir, restored)
+	return restored, nil
+}
+
+// restorer creates the year files written by the replayed transaction groups that are not in the backup.
+type restorer struct {
+	rootDir string
+	catDir  *catalog.Directory
+}
+
+func (r *restorer) createFiles(wtSets []wal.WTSet) error {
+	for _, wtSet := range wtSets {
+		if _, err := os.Stat(wtSet.FilePath); !errors.Is(err, os.ErrNotExist) ||
+			wtSet.Buffer.IsAlter() || wtSet.Buffer.IsDrop() {
+			continue
+		}
+		if r.catDir == nil {
+			d, err := catalog.NewDirectory(r.rootDir)
+			var e catalog.ErrCategoryFileNotFound
+			if err != nil && !errors.As(err, &e) {
+				return err
+			}
+			r.catDir = d
+		}
+		tbk, year, err := io.NewTimeBucketKeyFromWalKeyPath(FullPathToWALKey(r.rootDir, wtSet.FilePath))
+		if err != nil {
+			return err
+		}
+		if tbi, err2 := r.catDir.GetLatestTimeBucketInfoFromKey(tbk); err2 == nil {
+			_, err = r.catDir.GetSubDirectoryAndAddFile(tbi.Path, int16(year))
+		} else {
+			tf, err2 := tbk.GetTimeFrame()
+			if err2 != nil {
+				return err2
+			}
+			tbi = io.NewTimeBucketInfo(*tf, tbk.GetPathToYearFiles(r.rootDir), "Created By Restore",
+				int16(year), wtSet.DataShapes, wtSet.RecordType)
+			err = r.catDir.AddTimeBucket(tbk, tbi)
+		}
+		if err != nil {
+			return fmt.Errorf("create %s: %w", wtSet.FilePath, err)
+		}
+	}
+	return nil
+}
+
+// readWALGroups adds the transaction groups of a WAL file to groups, by ID.
+func readWALGroups(path string, groups map[int64][]byte) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("open the WAL file: %w", err)
+	}
+	defer f.Close()
+	wf := &WALFileType{FilePtr: f}
+	for {
+		msgID, err := wf.readMessageID()
+		if endOfWAL(err) {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("read the WAL file %s: %w", path, err)
+		}
+		switch msgID {
+		case TGDATA:
+			tgID, tgSerialized, err := wf.readTGData()
+			if endOfWAL(err) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("read a transaction group of the WAL file %s: %w", path, err)
+			}
+			if _, ok := groups[tgID]; !ok {
+				groups[tgID] = tgSerialized
+			}
+		case TXNINFO:
+			if _, _, _, err = wf.readTransactionInfo(); endOfWAL(err) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("read the WAL file %s: %w", path, err)
+			}
+		case STATUS:
+			if _, _, _, err = wal.ReadStatus(f); endOfWAL(err) {
+				return nil
+			} else if err != nil {
+				return fmt.Errorf("read the WAL file %s: %w", path, err)
+			}
+		}
+	}
+}
+
+// endOfWAL returns true if err is the end of a WAL file, which may end with a partially written message.
+func endOfWAL(err error) bool {
+	var shortRead wal.ShortReadError
+	return errors.Is(err, goio.EOF) || errors.As(err, &shortRead)
+}
+
+func isWALFile(name string) bool {
+	return strings.Contains(name, ".walfile")
+}
+
+// createEmptyDir creates the directory at path, which may already exist if it is empty.
+func createEmptyDir(path string) error {
+	entries, err := os.ReadDir(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return os.MkdirAll(path, backupDirPerm)
+	case err != nil:
+		return err
+	case len(entries) != 0:
+		return fmt.Errorf("directory %s is not empty", path)
+	}
+	return nil
+}
+
+func writeManifest(dir string, m *BackupManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, BackupManifestFile), os.O_CREATE|os.O_EXCL|os.O_WRONLY, backupFilePerm)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// copyFile copies the file at src into a new file at dst, and returns its size and its SHA-256 checksum.
+func copyFile(src, dst string) (size int64, sum string, err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, "", err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, backupFilePerm)
+	if err != nil {
+		return 0, "", err
+	}
+	hash := sha256.New()
+	size, err = goio.Copy(goio.MultiWriter(out, hash), in)
+	if err == nil {
+		err = out.Sync()
+	}
+	if err2 := out.Close(); err == nil {
+		err = err2
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), err
+}
+
+func hashFile(path string) (size int64, sum string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if size, err = goio.Copy(hash, f); err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(hash.Sum(nil)), nil
+}
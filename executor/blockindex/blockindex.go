@@ -0,0 +1,507 @@
+/*
+Package blockindex implements the optional block index of a year file: the statistics of the blocks of consecutive
+intervals of the year, which let a reader skip the blocks that can not hold the rows it looks for.
+
+The index file ({Year}.idx) is stored next to the year file ({Year}.bin) or the archive file ({Year}.arc):
+
+	| header | columns | block 0 | block 1 | ... |
+
+The header holds the number of intervals per block and in the year, the length of the rows and the number of
+indexed (numeric) columns, each described by its name, type and offset in the row. A block entry holds the number
+of rows of the block and the minimum and maximum of each indexed column, followed in a FIXED bucket by a bitmap of
+the intervals holding a row. NaN values are not counted in the minimum and maximum.
+
+The statistics are bounds: overwriting the rows of a block may leave them wider than the values of the block.
+*/
+package blockindex
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+const (
+	// FileExt is the extension of the index files.
+	FileExt = ".idx"
+	// DefaultBlockIntervals is the number of intervals of a block.
+	DefaultBlockIntervals = 1024
+
+	magic         = "MKTSBIX1"
+	headerSize    = 32
+	columnSize    = 40
+	maxNameLength = 32
+	bitmapFlag    = 1
+	intervalTicks = 4
+)
+
+var ErrNotIndex = errors.New("not a block index file")
+
+// PathOf returns the path of the index file of a year file or an archive file.
+func PathOf(yearFilePath string) string {
+	return strings.TrimSuffix(yearFilePath, filepath.Ext(yearFilePath)) + FileExt
+}
+
+// Column is an indexed column of the rows.
+type Column struct {
+	Name string
+	Type io.EnumElementType
+	// Scale is the scale of a DECIMAL64 column
+	Scale  int8
+	offset int
+}
+
+// Block holds the statistics of a block of intervals.
+type Block struct {
+	Rows int64
+	// Min and Max are the bounds of the values of each indexed column, Min is greater than Max
+	// when the block has no value for the column
+	Min, Max []float64
+	present  []byte
+}
+
+// Overlaps returns true if the block may hold a value of the column between min and max included.
+func (b *Block) Overlaps(column int, min, max float64) bool {
+	return b.Rows != 0 && b.Min[column] <= b.Max[column] && b.Min[column] <= max && b.Max[column] >= min
+}
+
+func (b *Block) reset() {
+	b.Rows = 0
+	for i := range b.Min {
+		b.Min[i], b.Max[i] = math.Inf(1), math.Inf(-1)
+	}
+	for i := range b.present {
+		b.present[i] = 0
+	}
+}
+
+// Index is an opened index file. The blocks are read when they are first used, and the changed blocks are
+// written by Flush.
+type Index struct {
+	// BlockIntervals is the number of intervals of a block, and Intervals the number of intervals of the year
+	BlockIntervals int64
+	Intervals      int64
+
+	f        *os.File
+	rowLen   int
+	bitmap   bool
+	columns  []Column
+	blocks   map[int64]*Block
+	dirty    map[int64]bool
+	entryLen int64
+}
+
+// layout returns the index layout of the rows of a time bucket.
+func layout(tbi *io.TimeBucketInfo) *Index {
+	ix := &Index{
+		BlockIntervals: DefaultBlockIntervals,
+		bitmap:         tbi.GetRecordType() == io.FIXED,
+	}
+	recordLen := int64(tbi.GetRecordLength())
+	ix.Intervals = (io.FileSize(tbi.GetTimeframe(), int(tbi.Year), int(recordLen)) - io.Headersize) / recordLen
+	for i, typ := range tbi.GetElementTypes() {
+		if indexed(typ) {
+			ix.columns = append(ix.columns, Column{Name: tbi.GetElementNames()[i], Type: typ, offset: ix.rowLen})
+			if typ == io.DECIMAL64 {
+				ix.columns[len(ix.columns)-1].Scale = tbi.GetElementScales()[i]
+			}
+		}
+		ix.rowLen += typ.Size()
+	}
+	if !ix.bitmap {
+		ix.rowLen += intervalTicks
+	}
+	ix.init()
+	return ix
+}
+
+func (ix *Index) init() {
+	ix.entryLen = 8 + 16*int64(len(ix.columns))
+	if ix.bitmap {
+		ix.entryLen += ix.BlockIntervals / 8
+	}
+	ix.blocks = map[int64]*Block{}
+	ix.dirty = map[int64]bool{}
+}
+
+func indexed(typ io.EnumElementType) bool {
+	switch typ {
+	case io.FLOAT32, io.FLOAT64, io.INT16, io.INT32, io.INT64, io.EPOCH,
+		io.BYTE, io.UINT8, io.UINT16, io.UINT32, io.UINT64, io.DECIMAL64:
+		return true
+	default:
+		return false
+	}
+}
+
+/*
+Create creates an empty index file at path for the time bucket described by tbi. It fails if the file exists,
+so that an index is never replaced while it is used.
+*/
+func Create(path string, tbi *io.TimeBucketInfo) (*Index, error) {
+	const ownerReadWrite = 0o600
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, ownerReadWrite)
+	if err != nil {
+		return nil, fmt.Errorf("create block index %s: %w", path, err)
+	}
+	ix := layout(tbi)
+	ix.f = f
+	buf := make([]byte, headerSize+columnSize*len(ix.columns))
+	copy(buf, magic)
+	binary.LittleEndian.PutUint32(buf[8:], uint32(ix.BlockIntervals))
+	binary.LittleEndian.PutUint32(buf[12:], uint32(ix.Intervals))
+	binary.LittleEndian.PutUint32(buf[16:], uint32(ix.rowLen))
+	if ix.bitmap {
+		binary.LittleEndian.PutUint32(buf[20:], bitmapFlag)
+	}
+	binary.LittleEndian.PutUint32(buf[24:], uint32(len(ix.columns)))
+	for i, col := range ix.columns {
+		entry := buf[headerSize+i*columnSize:]
+		copy(entry[:maxNameLength], col.Name)
+		entry[maxNameLength] = byte(col.Type)
+		entry[maxNameLength+1] = byte(col.Scale)
+		binary.LittleEndian.PutUint32(entry[maxNameLength+4:], uint32(col.offset))
+	}
+	if _, err = f.WriteAt(buf, 0); err == nil {
+		err = f.Truncate(ix.blockOffset(ix.blockOf(ix.Intervals) + 1))
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("write block index %s: %w", path, err)
+	}
+	return ix, nil
+}
+
+// Open opens the index file at path for reading, or for updating its blocks if writable is set.
+func Open(path string, writable bool) (*Index, error) {
+	flag := os.O_RDONLY
+	if writable {
+		flag = os.O_RDWR
+	}
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open block index %s: %w", path, err)
+	}
+	ix := &Index{f: f}
+	if err = ix.readHeader(); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return ix, nil
+}
+
+func (ix *Index) readHeader() error {
+	header := make([]byte, headerSize)
+	if _, err := ix.f.ReadAt(header, 0); err != nil || !bytes.Equal(header[:8], []byte(magic)) {
+		return ErrNotIndex
+	}
+	ix.BlockIntervals = int64(binary.LittleEndian.Uint32(header[8:]))
+	ix.Intervals = int64(binary.LittleEndian.Uint32(header[12:]))
+	ix.rowLen = int(binary.LittleEndian.Uint32(header[16:]))
+	ix.bitmap = binary.LittleEndian.Uint32(header[20:])&bitmapFlag != 0
+	buf := make([]byte, columnSize*int(binary.LittleEndian.Uint32(header[24:])))
+	if _, err := ix.f.ReadAt(buf, headerSize); err != nil {
+		return ErrNotIndex
+	}
+	for entry := buf; len(entry) != 0; entry = entry[columnSize:] {
+		ix.columns = append(ix.columns, Column{
+			Name:   string(bytes.TrimRight(entry[:maxNameLength], "\x00")),
+			Type:   io.EnumElementType(entry[maxNameLength]),
+			Scale:  int8(entry[maxNameLength+1]),
+			offset: int(binary.LittleEndian.Uint32(entry[maxNameLength+4:])),
+		})
+	}
+	ix.init()
+	return nil
+}
+
+// Matches returns true if the index has the layout of the rows of the time bucket described by tbi.
+func (ix *Index) Matches(tbi *io.TimeBucketInfo) bool {
+	want := layout(tbi)
+	if ix.Intervals != want.Intervals || ix.rowLen != want.rowLen || ix.bitmap != want.bitmap ||
+		len(ix.columns) != len(want.columns) {
+		return false
+	}
+	for i, col := range ix.columns {
+		if col != want.columns[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Columns returns the indexed columns.
+func (ix *Index) Columns() []Column {
+	return ix.columns
+}
+
+// ColumnIndex returns the position of a column in the indexed columns, or -1 if the column is not indexed.
+func (ix *Index) ColumnIndex(name string) int {
+	for i, col := range ix.columns {
+		if col.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// HasBitmap returns true if the index records the intervals holding a row, which it does in a FIXED bucket.
+func (ix *Index) HasBitmap() bool {
+	return ix.bitmap
+}
+
+// BlockRange returns the first and the last index of the intervals of a block.
+func (ix *Index) BlockRange(block int64) (first, last int64) {
+	first = block*ix.BlockIntervals + 1
+	last = first + ix.BlockIntervals - 1
+	if last > ix.Intervals {
+		last = ix.Intervals
+	}
+	return first, last
+}
+
+// BlockOf returns the block of the interval at index.
+func (ix *Index) BlockOf(index int64) int64 {
+	return ix.blockOf(index)
+}
+
+func (ix *Index) blockOf(index int64) int64 {
+	return (index - 1) / ix.BlockIntervals
+}
+
+func (ix *Index) blockOffset(block int64) int64 {
+	return headerSize + columnSize*int64(len(ix.columns)) + block*ix.entryLen
+}
+
+// Block returns the statistics of a block.
+func (ix *Index) Block(block int64) (*Block, error) {
+	if b, ok := ix.blocks[block]; ok {
+		return b, nil
+	}
+	if block < 0 || block > ix.blockOf(ix.Intervals) {
+		return nil, fmt.Errorf("block %d is out of the index", block)
+	}
+	buf := make([]byte, ix.entryLen)
+	if _, err := ix.f.ReadAt(buf, ix.blockOffset(block)); err != nil {
+		return nil, fmt.Errorf("read block %d of the index: %w", block, err)
+	}
+	b := &Block{
+		Rows: int64(binary.LittleEndian.Uint64(buf)),
+		Min:  make([]float64, len(ix.columns)),
+		Max:  make([]float64, len(ix.columns)),
+	}
+	if ix.bitmap {
+		b.present = buf[8+16*len(ix.columns):]
+	}
+	if b.Rows == 0 {
+		b.reset()
+	} else {
+		for i := range ix.columns {
+			b.Min[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[8+16*i:]))
+			b.Max[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[16+16*i:]))
+		}
+	}
+	ix.blocks[block] = b
+	return b, nil
+}
+
+/*
+Add records the rows written at the interval index: the fields of the record in a FIXED bucket,
+or the appended rows with their interval ticks in a VARIABLE bucket.
+*/
+func (ix *Index) Add(index int64, rows []byte) error {
+	if index < 1 || index > ix.Intervals {
+		return nil
+	}
+	block := ix.blockOf(index)
+	b, err := ix.Block(block)
+	if err != nil {
+		return err
+	}
+	if b.Rows == 0 {
+		b.reset()
+	}
+	if ix.bitmap {
+		if len(rows) < ix.rowLen {
+			return fmt.Errorf("row length %d does not match the index row length %d", len(rows), ix.rowLen)
+		}
+		bit := (index - 1) % ix.BlockIntervals
+		if b.present[bit/8]&(1<<(bit%8)) == 0 {
+			b.present[bit/8] |= 1 << (bit % 8)
+			b.Rows++
+		}
+		ix.addValues(b, rows)
+	} else {
+		for ; len(rows) >= ix.rowLen; rows = rows[ix.rowLen:] {
+			b.Rows++
+			ix.addValues(b, rows)
+		}
+	}
+	ix.dirty[block] = true
+	return nil
+}
+
+func (ix *Index) addValues(b *Block, row []byte) {
+	for i, col := range ix.columns {
+		v := value(row[col.offset:], col.Type, col.Scale)
+		if math.IsNaN(v) {
+			continue
+		}
+		if v < b.Min[i] {
+			b.Min[i] = v
+		}
+		if v > b.Max[i] {
+			b.Max[i] = v
+		}
+	}
+}
+
+// Reset empties a block, before its rows are added again.
+func (ix *Index) Reset(block int64) error {
+	b, err := ix.Block(block)
+	if err != nil {
+		return err
+	}
+	b.reset()
+	ix.dirty[block] = true
+	return nil
+}
+
+// Present returns true if the interval at index holds a row. It needs the bitmap of a FIXED bucket.
+func (ix *Index) Present(index int64) (bool, error) {
+	b, err := ix.Block(ix.blockOf(index))
+	if err != nil || !ix.bitmap {
+		return false, err
+	}
+	bit := (index - 1) % ix.BlockIntervals
+	return b.present[bit/8]&(1<<(bit%8)) != 0, nil
+}
+
+/*
+LastRows returns the first index from which the intervals up to last hold n rows, and the number of rows
+found, which is less than n if the intervals from first to last do not hold n rows. It needs the bitmap.
+*/
+func (ix *Index) LastRows(first, last, n int64) (start, count int64, err error) {
+	for index := last; index >= first && count < n; index-- {
+		block := ix.blockOf(index)
+		blockFirst, blockLast := ix.BlockRange(block)
+		b, err := ix.Block(block)
+		if err != nil {
+			return 0, 0, err
+		}
+		// the rows of a whole block are counted at once if they are not enough
+		if index == blockLast && blockFirst >= first && count+b.Rows < n {
+			count += b.Rows
+			index = blockFirst
+			continue
+		}
+		if ok, err := ix.Present(index); err != nil {
+			return 0, 0, err
+		} else if ok {
+			count++
+			start = index
+		}
+	}
+	if count < n {
+		start = first
+	}
+	return start, count, nil
+}
+
+/*
+FirstRows returns the last index up to which the intervals from first hold n rows, and the number of rows
+found, which is less than n if the intervals from first to last do not hold n rows. It needs the bitmap.
+*/
+func (ix *Index) FirstRows(first, last, n int64) (end, count int64, err error) {
+	for index := first; index <= last && count < n; index++ {
+		block := ix.blockOf(index)
+		blockFirst, blockLast := ix.BlockRange(block)
+		b, err := ix.Block(block)
+		if err != nil {
+			return 0, 0, err
+		}
+		if index == blockFirst && blockLast <= last && count+b.Rows < n {
+			count += b.Rows
+			index = blockLast
+			continue
+		}
+		if ok, err := ix.Present(index); err != nil {
+			return 0, 0, err
+		} else if ok {
+			count++
+			end = index
+		}
+	}
+	if count < n {
+		end = last
+	}
+	return end, count, nil
+}
+
+// Flush writes the changed blocks to the index file.
+func (ix *Index) Flush() error {
+	buf := make([]byte, ix.entryLen)
+	for block := range ix.dirty {
+		b := ix.blocks[block]
+		binary.LittleEndian.PutUint64(buf, uint64(b.Rows))
+		for i := range ix.columns {
+			binary.LittleEndian.PutUint64(buf[8+16*i:], math.Float64bits(b.Min[i]))
+			binary.LittleEndian.PutUint64(buf[16+16*i:], math.Float64bits(b.Max[i]))
+		}
+		copy(buf[8+16*len(ix.columns):], b.present)
+		if _, err := ix.f.WriteAt(buf, ix.blockOffset(block)); err != nil {
+			return fmt.Errorf("write block %d of the index: %w", block, err)
+		}
+		delete(ix.dirty, block)
+	}
+	return nil
+}
+
+// Close writes the changed blocks and closes the index file.
+func (ix *Index) Close() error {
+	if err := ix.Flush(); err != nil {
+		_ = ix.f.Close()
+		return err
+	}
+	return ix.f.Close()
+}
+
+// Sync commits the index file to the disk.
+func (ix *Index) Sync() error {
+	return ix.f.Sync()
+}
+
+// value returns the value of a numeric field as a float64, the way the rows are compared in a query.
+func value(field []byte, typ io.EnumElementType, scale int8) float64 {
+	switch typ {
+	case io.FLOAT32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(field)))
+	case io.FLOAT64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(field))
+	case io.INT16:
+		return float64(int16(binary.LittleEndian.Uint16(field)))
+	case io.INT32:
+		return float64(int32(binary.LittleEndian.Uint32(field)))
+	case io.INT64, io.EPOCH:
+		return float64(int64(binary.LittleEndian.Uint64(field)))
+	case io.DECIMAL64:
+		return io.Decimal64(binary.LittleEndian.Uint64(field)).Float64(scale)
+	case io.BYTE, io.UINT8:
+		return float64(field[0])
+	case io.UINT16:
+		return float64(binary.LittleEndian.Uint16(field))
+	case io.UINT32:
+		return float64(binary.LittleEndian.Uint32(field))
+	case io.UINT64:
+		return float64(binary.LittleEndian.Uint64(field))
+	default:
+		return math.NaN()
+	}
+}
@@ -0,0 +1,154 @@
+package blockindex_test
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alpacahq/marketstore/v4/executor/blockindex"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+func TestBlockIndexFixed(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dsv := io.NewDataShapeVector(
+		[]string{"Price", "Size", "Name"},
+		[]io.EnumElementType{io.FLOAT64, io.INT32, io.STRING16},
+	)
+	tbi := io.NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), dir, "Test", 2020, dsv, io.FIXED)
+	path := blockindex.PathOf(filepath.Join(dir, "2020.bin"))
+	assert.Equal(t, filepath.Join(dir, "2020.idx"), path)
+
+	ix, err := blockindex.Create(path, tbi)
+	require.Nil(t, err)
+	assert.True(t, ix.HasBitmap())
+	// the strings are not indexed
+	assert.Equal(t, 2, len(ix.Columns()))
+	assert.Equal(t, 1, ix.ColumnIndex("Size"))
+	assert.Equal(t, -1, ix.ColumnIndex("Name"))
+	assert.Equal(t, int64(366*24*60), ix.Intervals)
+
+	// 10 rows in block 0, 1 row in block 2, and a NaN price in block 3
+	for i := int64(1); i <= 10; i++ {
+		require.Nil(t, ix.Add(i*50, fixedRow(float64(i), int32(-i))))
+	}
+	require.Nil(t, ix.Add(2*blockindex.DefaultBlockIntervals+5, fixedRow(1000, 7)))
+	require.Nil(t, ix.Add(3*blockindex.DefaultBlockIntervals+1, fixedRow(math.NaN(), 1)))
+	// an interval written again is counted once
+	require.Nil(t, ix.Add(50, fixedRow(20, -1)))
+	assert.NotNil(t, ix.Add(100, make([]byte, 4)))
+	require.Nil(t, ix.Close())
+
+	_, err = blockindex.Create(path, tbi)
+	assert.ErrorIs(t, err, os.ErrExist)
+
+	ix, err = blockindex.Open(path, true)
+	require.Nil(t, err)
+	defer ix.Close()
+	assert.True(t, ix.Matches(tbi))
+
+	b, err := ix.Block(0)
+	require.Nil(t, err)
+	assert.Equal(t, int64(10), b.Rows)
+	assert.Equal(t, []float64{1, -10}, b.Min)
+	assert.Equal(t, []float64{20, -1}, b.Max)
+	assert.True(t, b.Overlaps(0, 15, 30))
+	assert.False(t, b.Overlaps(0, 21, 30))
+	b, err = ix.Block(1)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), b.Rows)
+	assert.False(t, b.Overlaps(0, math.Inf(-1), math.Inf(1)))
+	b, err = ix.Block(3)
+	require.Nil(t, err)
+	assert.Equal(t, int64(1), b.Rows)
+	assert.False(t, b.Overlaps(0, math.Inf(-1), math.Inf(1)))
+	assert.True(t, b.Overlaps(1, 1, 1))
+
+	ok, err := ix.Present(100)
+	require.Nil(t, err)
+	assert.True(t, ok)
+	ok, err = ix.Present(101)
+	require.Nil(t, err)
+	assert.False(t, ok)
+
+	last := 4 * blockindex.DefaultBlockIntervals
+	start, count, err := ix.LastRows(1, int64(last), 3)
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, int64(500), start)
+	start, count, err = ix.LastRows(1, int64(last), 100)
+	require.Nil(t, err)
+	assert.Equal(t, int64(12), count)
+	assert.Equal(t, int64(1), start)
+	end, count, err := ix.FirstRows(1, int64(last), 11)
+	require.Nil(t, err)
+	assert.Equal(t, int64(11), count)
+	assert.Equal(t, int64(2*blockindex.DefaultBlockIntervals+5), end)
+
+	require.Nil(t, ix.Reset(0))
+	b, err = ix.Block(0)
+	require.Nil(t, err)
+	assert.Equal(t, int64(0), b.Rows)
+
+	// the index of another layout does not match
+	other := io.NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), dir, "Test", 2020,
+		io.NewDataShapeVector([]string{"Price"}, []io.EnumElementType{io.FLOAT64}), io.FIXED)
+	assert.False(t, ix.Matches(other))
+}
+
+func TestBlockIndexVariable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dsv := io.NewDataShapeVector([]string{"Bid"}, []io.EnumElementType{io.FLOAT32})
+	tbi := io.NewTimeBucketInfo(*utils.TimeframeFromString("1Min"), dir, "Test", 2020, dsv, io.VARIABLE)
+	path := filepath.Join(dir, "2020.idx")
+	ix, err := blockindex.Create(path, tbi)
+	require.Nil(t, err)
+	assert.False(t, ix.HasBitmap())
+
+	// 3 rows in one interval, with their interval ticks
+	var rows []byte
+	for _, bid := range []float32{3, 1, 2} {
+		rows = appendUint(rows, uint64(math.Float32bits(bid)), 4)
+		rows = appendUint(rows, 0, 4)
+	}
+	require.Nil(t, ix.Add(10, rows))
+	require.Nil(t, ix.Close())
+
+	ix, err = blockindex.Open(path, false)
+	require.Nil(t, err)
+	defer ix.Close()
+	b, err := ix.Block(0)
+	require.Nil(t, err)
+	assert.Equal(t, int64(3), b.Rows)
+	assert.Equal(t, []float64{1}, b.Min)
+	assert.Equal(t, []float64{3}, b.Max)
+
+	// a file that is not an index
+	notIndex := filepath.Join(dir, "2021.idx")
+	require.Nil(t, os.WriteFile(notIndex, make([]byte, 100), 0o600))
+	_, err = blockindex.Open(notIndex, false)
+	assert.ErrorIs(t, err, blockindex.ErrNotIndex)
+}
+
+func fixedRow(price float64, size int32) []byte {
+	var row []byte
+	row = appendUint(row, math.Float64bits(price), 8)
+	row = appendUint(row, uint64(uint32(size)), 4)
+	return append(row, make([]byte, 64)...)
+}
+
+func appendUint(b []byte, v uint64, size int) []byte {
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	return append(b, buf[:size]...)
+}
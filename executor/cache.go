@@ -17,9 +17,16 @@ const WriteChannelCommandDepth = 1000000
 // TransactionPipe stores the contents of the current pending Transaction Group
 // and writes it to WAL when flush() is called.
 type TransactionPipe struct {
-	tgID         int64                  // Current transaction group ID
-	writeChannel chan *wal.WriteCommand // Channel for write commands
-	flushChannel chan chan struct{}     // Channel for flush request
+	tgID           int64                  // Current transaction group ID
+	writeChannel   chan *wal.WriteCommand // Channel for write commands
+	flushChannel   chan chan struct{}     // Channel for flush request
+	quiesceChannel chan quiesceRequest    // Channel for functions run between transaction groups
+}
+
+// quiesceRequest is a function run by the WAL writer between two transaction groups, see WALFileType.Quiesce.
+type quiesceRequest struct {
+	fn   func(tgID int64) error
+	done chan error
 }
 
 // NewTransactionPipe creates a new transaction pipe that channels all
@@ -28,8 +35,9 @@ func NewTransactionPipe() *TransactionPipe {
 	return &TransactionPipe{
 		tgID: time.Now().UTC().UnixNano(),
 		// Allocate the write channel with enough depth to allow all conceivable writers concurrent access
-		writeChannel: make(chan *wal.WriteCommand, WriteChannelCommandDepth),
-		flushChannel: make(chan chan struct{}, WriteChannelCommandDepth),
+		writeChannel:   make(chan *wal.WriteCommand, WriteChannelCommandDepth),
+		flushChannel:   make(chan chan struct{}, WriteChannelCommandDepth),
+		quiesceChannel: make(chan quiesceRequest),
 	}
 }
 
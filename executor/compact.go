@@ -0,0 +1,252 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	goio "io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/metrics"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+/*
+yearFileSwap serializes the replacement of the year files by their compacted copies with the reads: a read
+of a variable length bucket reads the {index, offset, len} records of the intervals first, then their rows
+at the offsets, which only hold in the file the records were read from.
+*/
+var yearFileSwap sync.RWMutex
+
+const compactExt = ".compact"
+
+/*
+Compaction of the variable length buckets
+
+A write to an interval of a variable length bucket appends all its rows, the previous ones with the new ones,
+at the end of the year file and points the record of the interval to them: the rows previously written
+become dead space, which the reads skip but which is never freed, unless they were at the end of the file.
+The compaction rewrites a year file with only the live rows of its intervals, in time order, while the writes
+are suspended.
+*/
+
+// CompactionResult reports the compaction of a year file of a variable length bucket.
+type CompactionResult struct {
+	Key  *io.TimeBucketKey
+	Year int16
+	// SizeBefore and SizeAfter are the sizes of the year file before and after the compaction
+	SizeBefore, SizeAfter int64
+	// DeadBytes is the size of the rows that are not pointed to by the records of the intervals any more
+	DeadBytes int64
+	// Compacted is false if the dead space of the year file is below the threshold, it is left as it is
+	Compacted bool
+}
+
+// Reclaimed returns the number of bytes freed by the compaction.
+func (r *CompactionResult) Reclaimed() int64 {
+	return r.SizeBefore - r.SizeAfter
+}
+
+/*
+Compact rewrites the year files of the variable length buckets matching the pattern (see
+catalog.MatchTimeBucketKeys) whose dead space is at least minDeadRatio of the size of their rows, or only
+their year if it is not 0. The archived and the offloaded years are skipped, they are read-only.
+
+The failure of a year does not stop the others, the first error is returned.
+*/
+func (w *Writer) Compact(pattern *io.TimeBucketKey, year int16, minDeadRatio float64,
+) (results []CompactionResult, err error) {
+	for _, key := range catalog.MatchTimeBucketKeys(w.rootCatDir, pattern) {
+		latest, err2 := w.rootCatDir.GetLatestTimeBucketInfoFromKey(key)
+		if err2 != nil || latest.GetRecordType() != io.VARIABLE {
+			continue
+		}
+		subDir, err2 := w.rootCatDir.GetOwningSubDirectory(latest.Path)
+		if err2 != nil {
+			continue
+		}
+		years := subDir.GetTimeBucketInfoSlice()
+		sort.Slice(years, func(i, j int) bool { return years[i].Year < years[j].Year })
+		for _, tbi := range years {
+			if (year != 0 && tbi.Year != year) || readOnlyYear(tbi) != nil {
+				continue
+			}
+			res, err2 := w.compactYear(key, tbi, minDeadRatio)
+			if err2 != nil {
+				log.Error("failed to compact year %d of %s: %v", tbi.Year, key.GetItemKey(), err2)
+				if err == nil {
+					err = err2
+				}
+				continue
+			}
+			if res.Compacted {
+				log.Info("compacted year %d of %s: %d bytes reclaimed", tbi.Year, key.GetItemKey(), res.Reclaimed())
+				metrics.CompactionReclaimedBytes.Add(float64(res.Reclaimed()))
+			}
+			results = append(results, *res)
+		}
+	}
+	return results, err
+}
+
+// compactYear compacts a year file if its dead space is at least minDeadRatio of the size of its rows.
+func (w *Writer) compactYear(key *io.TimeBucketKey, tbi *io.TimeBucketInfo, minDeadRatio float64,
+) (*CompactionResult, error) {
+	// the layout of the file does not change while it is rewritten
+	bucketLayout.RLock()
+	defer bucketLayout.RUnlock()
+
+	res := &CompactionResult{Key: key, Year: tbi.Year}
+	// the transaction groups are not written to the file while it is rewritten
+	err := w.walFile.Quiesce(func(int64) error {
+		usage, err := readVariableUsage(tbi.Path)
+		if err != nil {
+			return err
+		}
+		res.SizeBefore, res.SizeAfter, res.DeadBytes = usage.size, usage.size, usage.dead()
+		if res.DeadBytes == 0 || float64(res.DeadBytes) < minDeadRatio*float64(usage.size-usage.dataOffset) {
+			return nil
+		}
+		if res.SizeAfter, err = compactYearFile(tbi.Path); err != nil {
+			return err
+		}
+		res.Compacted = true
+		return nil
+	})
+	return res, err
+}
+
+// variableUsage is the use of the space of a year file of a variable length bucket.
+type variableUsage struct {
+	// size is the size of the file, dataOffset the offset of the rows after the records of the intervals
+	size, dataOffset int64
+	// live is the size of the rows pointed to by the records of the intervals
+	live int64
+}
+
+func (u variableUsage) dead() int64 {
+	return u.size - u.dataOffset - u.live
+}
+
+// readVariableUsage reads the use of the space of a year file of a variable length bucket.
+func readVariableUsage(path string) (u variableUsage, err error) {
+	tbi, err := io.ReadTimeBucketInfo(path)
+	if err != nil {
+		return u, err
+	}
+	if tbi.GetRecordType() != io.VARIABLE {
+		return u, fmt.Errorf("%s is not a year file of a variable length bucket", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return u, err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return u, err
+	}
+	recordLen := int64(tbi.GetRecordLength())
+	u.size = fi.Size()
+	u.dataOffset = io.FileSize(tbi.GetTimeframe(), int(tbi.Year), int(recordLen))
+	buffer := make([]byte, recordsPerRead*recordLen)
+	for pos := int64(io.Headersize); pos < u.dataOffset; pos += int64(len(buffer)) {
+		if u.dataOffset-pos < int64(len(buffer)) {
+			buffer = buffer[:u.dataOffset-pos]
+		}
+		if _, err = f.ReadAt(buffer, pos); err != nil && !errors.Is(err, goio.EOF) {
+			return u, err
+		}
+		for rec := buffer; int64(len(rec)) >= recordLen; rec = rec[recordLen:] {
+			// {index, offset, len}
+			if io.ToInt64(rec) != 0 {
+				u.live += io.ToInt64(rec[16:])
+			}
+		}
+	}
+	return u, nil
+}
+
+/*
+compactYearFile rewrites a year file of a variable length bucket with the live rows of its intervals, it
+returns its new size. The copy replaces the file once it is complete, so that a compaction interrupted by a
+crash leaves the file as it was, and the reads in progress finish with the file they started with.
+*/
+func compactYearFile(path string) (int64, error) {
+	tbi, err := io.ReadTimeBucketInfo(path)
+	if err != nil {
+		return 0, err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	tmpPath := path + compactExt
+	w, err := createYearFile(tmpPath, tbi)
+	if err != nil {
+		return 0, err
+	}
+	err = readYearFile(src, tbi, w.write)
+	if err == nil {
+		err = w.Close()
+	} else {
+		_ = w.f.Close()
+	}
+	if err == nil {
+		yearFileSwap.Lock()
+		err = os.Rename(tmpPath, path)
+		yearFileSwap.Unlock()
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return 0, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// Compactor compacts the variable length buckets of a writer in the background.
+type Compactor struct {
+	w       *Writer
+	setting utils.CompactionSetting
+}
+
+// NewCompactor returns the compactor of the buckets written by w.
+func NewCompactor(w *Writer, setting utils.CompactionSetting) *Compactor {
+	return &Compactor{w: w, setting: setting}
+}
+
+// Run compacts the year files at each interval of the setting until ctx is done, starting after one interval.
+func (c *Compactor) Run(ctx context.Context) {
+	t := time.NewTicker(c.setting.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		results, err := c.w.Compact(io.NewTimeBucketKey("*/*/*"), 0, c.setting.MinDeadRatio)
+		if err != nil {
+			log.Error("failed to compact the variable length buckets: %v", err)
+		}
+		var reclaimed int64
+		for i := range results {
+			reclaimed += results[i].Reclaimed()
+		}
+		if reclaimed > 0 {
+			log.Info("compaction reclaimed %d bytes", reclaimed)
+		}
+		metrics.CompactionLastRunTimestamp.Set(float64(time.Now().Unix()))
+	}
+}
@@ -1,128 +1,181 @@
 package executor
 
 import (
-	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"sort"
+	"time"
+
+	"github.com/klauspost/compress/snappy"
 
 	"github.com/alpacahq/marketstore/v4/planner"
+	"github.com/alpacahq/marketstore/v4/utils"
 	. "github.com/alpacahq/marketstore/v4/utils/io"
-	"github.com/alpacahq/marketstore/v4/utils/log"
 )
 
 type deleter struct {
-	pr     planner.ParseResult
-	IOPMap map[TimeBucketKey]*ioplan
+	pr      planner.ParseResult
+	IOPMap  map[TimeBucketKey]*ioplan
+	walFile *WALFileType
 }
 
+/*
+NewDeleter prepares the deletion of the rows of a parsed query. Like the
+writes, the rows are deleted through the WAL of the instance, so that the
+deletion is durable, replicated and reported to the triggers.
+*/
 func NewDeleter(pr *planner.ParseResult) (de *deleter, err error) {
-	de = new(deleter)
-	de.pr = *pr
+	if ThisInstance == nil || ThisInstance.WALFile == nil {
+		return nil, fmt.Errorf("there is not an active WALFile for this instance, so cannot delete")
+	}
+	return newDeleter(pr, ThisInstance.WALFile)
+}
+
+func newDeleter(pr *planner.ParseResult, walFile *WALFileType) (de *deleter, err error) {
 	if pr.Range == nil {
 		pr.Range = planner.NewDateRange()
 	}
+	de = new(deleter)
+	de.pr = *pr
+	de.walFile = walFile
 
 	sortedFileMap := make(map[TimeBucketKey]SortedFileList)
 	for _, qf := range pr.QualifiedFiles {
 		sortedFileMap[qf.Key] = append(sortedFileMap[qf.Key], qf)
 	}
 	de.IOPMap = make(map[TimeBucketKey]*ioplan)
-	maxRecordLen := int32(0)
 	for key, sfl := range sortedFileMap {
 		sort.Sort(sfl)
-		if de.IOPMap[key], err = NewIOPlan(sfl, pr.Limit, pr.Range, pr.TimeQuals); err != nil {
+		if de.IOPMap[key], err = NewIOPlan(sfl, pr.Limit, pr.Range, pr.TimeQuals, nil); err != nil {
 			return nil, err
 		}
-		recordLen := de.IOPMap[key].RecordLen
-		if maxRecordLen < recordLen {
-			maxRecordLen = recordLen
-		}
 	}
 	return de, nil
 }
 
+// Delete removes the rows of the query, including the rows queued for writing before the call.
 func (de *deleter) Delete() (err error) {
-	for _, iop := range de.IOPMap {
-		err2 := de.delete(iop)
-		if err2 != nil {
-			return err2
-		}
+	if err = de.walFile.flushQueued(); err != nil {
+		return err
+	}
+	if err = de.queue(); err != nil {
+		return err
 	}
-	return err
+	return de.walFile.flushQueued()
 }
 
-// Deletes the selected time range, preserving the file holes.
-func (de *deleter) delete(iop *ioplan) error {
-	for _, fp := range iop.FilePlan {
-		if err := deleteInner(fp, iop.RecordLen); err != nil {
-			return err
+// queue sends the commands deleting the rows to the WAL.
+func (de *deleter) queue() error {
+	// the range is rejected as a whole if it spans an archived or offloaded year
+	for _, iop := range de.IOPMap {
+		for _, fp := range iop.FilePlan {
+			if err := readOnlyYear(fp.tbi); err != nil {
+				return err
+			}
+		}
+	}
+	for _, iop := range de.IOPMap {
+		for _, fp := range iop.FilePlan {
+			if err := de.deleteFile(iop, fp); err != nil {
+				return err
+			}
 		}
 	}
 	return nil
 }
 
-func deleteInner(fp *ioFilePlan, recordLen int32) (err error) {
-	const allReadWrite = 0o666
-
-	filePath := fp.FullPath
-	f, err := os.OpenFile(filePath, os.O_RDWR, allReadWrite)
+/*
+deleteFile deletes the selected time range of a file, preserving the file
+holes. The records of the range are read first, so that only the runs of
+existing records are zeroed. In a variable length bucket, the intervals at the
+edges of the range may keep some of their rows, they are written again once
+the interval is deleted.
+*/
+func (de *deleter) deleteFile(iop *ioplan, fp *ioFilePlan) error {
+	f, err := os.Open(fp.FullPath)
 	if err != nil {
-		log.Error("Read: opening %s\n%s", filePath, err)
-		return err
+		return fmt.Errorf("open %s for delete: %w", fp.FullPath, err)
 	}
-	defer func(f *os.File) {
-		if err3 := f.Close(); err3 != nil {
-			log.Error("close ")
-		}
-	}(f)
+	defer f.Close()
 
-	seekerFunc := func(offset int64) error {
-		if _, err = f.Seek(fp.Offset, io.SeekStart); err != nil {
-			log.Error("Read: seeking in %s\n%s", filePath, err)
-			return err
+	recordLen := int64(iop.RecordLen)
+	buffer := make([]byte, fp.Length)
+	n, err := f.ReadAt(buffer, fp.Offset)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("read %s for delete: %w", fp.FullPath, err)
+	}
+	numRecords := int64(n) / recordLen
+
+	dsv := fp.tbi.GetDataShapesWithEpoch()
+	runStart := int64(-1)
+	for i := int64(0); i <= numRecords; i++ {
+		if i < numRecords && ToInt64(buffer[i*recordLen:]) != 0 {
+			if runStart < 0 {
+				runStart = i
+			}
+			continue
+		}
+		if runStart < 0 {
+			continue
+		}
+		de.walFile.QueueWriteCommand(de.walFile.DeleteCommand(iop.RecordType, fp.FullPath,
+			iop.VariableRecordLen, fp.Offset+runStart*recordLen, recordLen, i-runStart, dsv))
+		if iop.RecordType == VARIABLE {
+			for j := runStart; j < i; j++ {
+				if err = de.keepRows(f, iop, fp, fp.Offset+j*recordLen, buffer[j*recordLen:], dsv); err != nil {
+					return err
+				}
+			}
 		}
-		return nil
+		runStart = -1
 	}
+	return nil
+}
 
-	if err2 := seekerFunc(fp.Offset); err2 != nil {
-		return err2
+/*
+keepRows writes again the rows of a variable length interval that are outside
+of the deleted range. info is the {index, offset, len} record of the interval.
+*/
+func (de *deleter) keepRows(f *os.File, iop *ioplan, fp *ioFilePlan, primaryOffset int64, info []byte,
+	dsv []DataShape,
+) error {
+	index := ToInt64(info)
+	tf := fp.tbi.GetTimeframe()
+	intervalStart := IndexToTime(index, tf, fp.GetFileYear())
+	start, end := de.pr.Range.Start, de.pr.Range.End
+	if !intervalStart.Before(start) && !intervalStart.Add(tf-time.Nanosecond).After(end) {
+		return nil // All the rows of the interval are deleted
 	}
 
-	/*
-		Read in the whole target data area to find the non-zero index locations
-		This will preserve the existing holes in the data area at the expense of
-		a potentially large number of file seeks
-	*/
-	bufferSize := int(fp.Length + int64(recordLen))
-	buffer := make([]byte, bufferSize)
-	n, err := f.Read(buffer)
-	if err != nil || n != bufferSize {
-		return fmt.Errorf("delete(): Short read %d bytes", n)
-	}
-	numRecs := bufferSize / int(recordLen)
-	zeroRecord := make([]byte, int(recordLen))
-	var isContiguous bool
-	for i := 0; i < numRecs; i++ {
-		epochLoc := i * int(recordLen)
-		index := int64(binary.LittleEndian.Uint64(buffer[epochLoc:]))
-		switch {
-		case index != 0 && !isContiguous:
-			if err := seekerFunc(int64(epochLoc) + fp.Offset); err != nil {
-				return err
-			}
-			isContiguous = true
-			fallthrough
-		case index != 0 && isContiguous:
-			n, err := f.Write(zeroRecord)
-			if err != nil || n != int(recordLen) {
-				return fmt.Errorf("delete(): Short write %d bytes, error: %s", n, err.Error())
-			}
-		case index == 0:
-			isContiguous = false
+	data := make([]byte, ToInt64(info[16:]))
+	if _, err := f.ReadAt(data, ToInt64(info[8:])); err != nil {
+		return fmt.Errorf("read interval %d of %s for delete: %w", index, fp.FullPath, err)
+	}
+	if !utils.InstanceConfig.DisableVariableCompression {
+		var err error
+		if data, err = snappy.Decode(nil, data); err != nil {
+			return err
 		}
 	}
-	buffer = nil
+
+	const intervalTicksLenBytes = 4
+	varRecLen := iop.VariableRecordLen
+	intervalsPerDay := uint32(fp.tbi.GetIntervals())
+	var kept []byte
+	for pos := 0; pos+varRecLen <= len(data); pos += varRecLen {
+		row := data[pos : pos+varRecLen]
+		ticks := ToUInt32(row[varRecLen-intervalTicksLenBytes:])
+		sec, nanosec := GetTimeFromTicks(uint64(intervalStart.Unix()), intervalsPerDay, ticks)
+		t := time.Unix(int64(sec), int64(nanosec))
+		if t.Before(start) || t.After(end) {
+			kept = append(kept, row...)
+		}
+	}
+	if len(kept) != 0 {
+		de.walFile.QueueWriteCommand(de.walFile.WriteCommand(VARIABLE, fp.FullPath, varRecLen,
+			primaryOffset, index, kept, dsv))
+	}
 	return nil
 }
@@ -0,0 +1,177 @@
+package executor
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	stdio "io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/executor/wal"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+const heapPerm = 0o600
+
+/*
+heapEnds is the end of the space allocated in each heap file. The values of a write are allocated their space
+when it is queued, and written to it when its transaction group is flushed, so the heap files can be shorter
+than their allocated space.
+*/
+var heapEnds = struct {
+	sync.Mutex
+	m map[string]int64
+}{m: map[string]int64{}}
+
+// allocateHeap allocates size bytes at the end of a heap file, it returns their offset.
+func allocateHeap(path string, size int) (int64, error) {
+	heapEnds.Lock()
+	defer heapEnds.Unlock()
+	end, ok := heapEnds.m[path]
+	if !ok {
+		end = io.HeapHeaderSize
+		fi, err := os.Stat(path)
+		switch {
+		case err == nil && fi.Size() > end:
+			end = fi.Size()
+		case err != nil && !errors.Is(err, os.ErrNotExist):
+			return 0, err
+		}
+	}
+	heapEnds.m[path] = end + int64(size)
+	return end, nil
+}
+
+// forgetHeap drops the allocated space of a heap file that is removed.
+func forgetHeap(path string) {
+	heapEnds.Lock()
+	defer heapEnds.Unlock()
+	delete(heapEnds.m, path)
+}
+
+/*
+storeHeapValues stores the values of the heap columns of the rows of a write command in its heap, the chunk
+allocated at the end of the heap file of the year file, and writes their references in the rows. recordLen
+is the length of the rows of the command, without Epoch. Identical values of the command are stored once, and
+empty values are not stored, their reference is 0.
+*/
+func storeHeapValues(cc *wal.WriteCommand, yearPath string, rows []int, cols []io.HeapColumnValues,
+	recordLen int,
+) error {
+	type reference struct {
+		at, pos int
+	}
+	var (
+		chunk     []byte
+		refs      []reference
+		positions = map[string]int{}
+	)
+	for j, row := range rows {
+		for _, col := range cols {
+			value := col.Values[row]
+			if len(value) == 0 {
+				continue
+			}
+			pos, ok := positions[string(value)]
+			if !ok {
+				chunk, pos = io.AppendHeapValue(chunk, value)
+				positions[string(value)] = pos
+			}
+			refs = append(refs, reference{at: j*recordLen + col.Offset - epochLenBytes, pos: pos})
+		}
+	}
+	if chunk == nil {
+		return nil
+	}
+	base, err := allocateHeap(io.HeapPathOf(yearPath), len(chunk))
+	if err != nil {
+		return fmt.Errorf("allocate the heap of %s: %w", yearPath, err)
+	}
+	for _, ref := range refs {
+		binary.LittleEndian.PutUint64(cc.Data[ref.at:], uint64(base)+uint64(ref.pos))
+	}
+	cc.Heap = &io.HeapChunk{Base: base, Data: chunk}
+	return nil
+}
+
+// heapFiles are the heap files opened to write the heaps of write commands.
+type heapFiles map[string]*os.File
+
+// write writes a heap to the heap file of a year file, which is created if it does not exist.
+func (h heapFiles) write(yearPath string, heap *io.HeapChunk) error {
+	path := io.HeapPathOf(yearPath)
+	f, ok := h[path]
+	if !ok {
+		var err error
+		if f, err = os.OpenFile(path, os.O_CREATE|os.O_RDWR, heapPerm); err != nil {
+			return err
+		}
+		h[path] = f
+		if _, err = f.WriteAt(io.HeapHeader(), 0); err != nil {
+			return err
+		}
+	}
+	_, err := f.WriteAt(heap.Data, heap.Base)
+	return err
+}
+
+func (h heapFiles) Close() (err error) {
+	for path, f := range h {
+		if err2 := f.Close(); err2 != nil && err == nil {
+			err = err2
+		}
+		delete(h, path)
+	}
+	return err
+}
+
+// writeHeaps writes the heaps of the write commands to the heap files under the root directory.
+func writeHeaps(rootDir string, commands []*wal.WriteCommand) (err error) {
+	h := heapFiles{}
+	defer func() {
+		if err2 := h.Close(); err == nil {
+			err = err2
+		}
+	}()
+	for _, wc := range commands {
+		if wc.Heap == nil {
+			continue
+		}
+		if err = h.write(walKeyToFullPath(rootDir, wc.WALKeyPath), wc.Heap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+readHeapColumns replaces the references of the heap columns of a series read from the year files of a bucket
+by their values, read from the heap files of the year files. yearFiles are the paths of the year files by year.
+*/
+func readHeapColumns(cs *io.ColumnSeries, dsv []io.DataShape, yearFiles map[int16]string) error {
+	epochs := cs.GetEpoch()
+	heaps := map[int16]*os.File{}
+	defer func() {
+		for _, f := range heaps {
+			_ = f.Close()
+		}
+	}()
+	return cs.ReadHeapColumns(dsv, func(i int) (stdio.ReaderAt, error) {
+		year := int16(io.ToSystemTimezone(time.Unix(epochs[i], 0)).Year())
+		if f, ok := heaps[year]; ok {
+			return f, nil
+		}
+		path, ok := yearFiles[year]
+		if !ok {
+			return nil, fmt.Errorf("no year file for the heap values of %d", year)
+		}
+		f, err := os.Open(io.HeapPathOf(path))
+		if err != nil {
+			return nil, fmt.Errorf("open the heap of %s: %w", path, err)
+		}
+		heaps[year] = f
+		return f, nil
+	})
+}
@@ -0,0 +1,328 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/alpacahq/marketstore/v4/executor/archive"
+	"github.com/alpacahq/marketstore/v4/executor/blockindex"
+	"github.com/alpacahq/marketstore/v4/executor/wal"
+	"github.com/alpacahq/marketstore/v4/planner"
+	. "github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+// openBlockIndex opens the block index of a year to read it, it returns nil if the year has no usable index.
+func openBlockIndex(tbi *TimeBucketInfo) *blockindex.Index {
+	ix, err := blockindex.Open(blockindex.PathOf(tbi.Path), false)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Warn("block index ignored: %v", err)
+		}
+		return nil
+	}
+	if !ix.Matches(tbi) {
+		log.Warn("block index of %s ignored, it does not match the layout of the year", tbi.Path)
+		_ = ix.Close()
+		return nil
+	}
+	return ix
+}
+
+/*
+blockPlans splits the plan of a file into the runs of blocks that the read needs, according to the block index
+of the file: the blocks without rows are skipped, and so are the blocks with no value in the column ranges of a
+read without a row limit. With a row limit and the bitmap of a FIXED bucket, the runs are also cut to the
+intervals holding the first or last rows of the limit.
+*/
+func blockPlans(fp *ioFilePlan, limit *planner.RowLimit, hasTimeQuals bool,
+	columnRanges []planner.ColumnRange,
+) []*ioFilePlan {
+	ix := openBlockIndex(fp.tbi)
+	if ix == nil {
+		return []*ioFilePlan{fp}
+	}
+	defer ix.Close()
+	plans, err := splitPlan(ix, fp, limit, hasTimeQuals, columnRanges)
+	if err != nil {
+		log.Warn("block index of %s ignored: %v", fp.FullPath, err)
+		return []*ioFilePlan{fp}
+	}
+	return plans
+}
+
+func splitPlan(ix *blockindex.Index, fp *ioFilePlan, limit *planner.RowLimit, hasTimeQuals bool,
+	columnRanges []planner.ColumnRange,
+) ([]*ioFilePlan, error) {
+	recordLen := int64(fp.tbi.GetRecordLength())
+	first := (fp.Offset-Headersize)/recordLen + 1
+	last := first + fp.Length/recordLen - 1
+	if last > ix.Intervals {
+		last = ix.Intervals
+	}
+	if first < 1 || first > last {
+		return []*ioFilePlan{fp}, nil
+	}
+	limited := limit.Number != math.MaxInt32
+
+	type run struct{ first, last int64 }
+	var runs []run
+	for block := ix.BlockOf(first); block <= ix.BlockOf(last); block++ {
+		b, err := ix.Block(block)
+		if err != nil {
+			return nil, err
+		}
+		if b.Rows == 0 || (!limited && !inColumnRanges(ix, b, columnRanges)) {
+			continue
+		}
+		lo, hi := ix.BlockRange(block)
+		if lo < first {
+			lo = first
+		}
+		if hi > last {
+			hi = last
+		}
+		if n := len(runs); n > 0 && runs[n-1].last+1 == lo {
+			runs[n-1].last = hi
+		} else {
+			runs = append(runs, run{first: lo, last: hi})
+		}
+	}
+
+	// the time qualifiers may reject rows, so the intervals of the limit are only known without them
+	if limited && !hasTimeQuals && ix.HasBitmap() {
+		n := int64(limit.Number)
+		if limit.Direction == LAST {
+			for i := len(runs) - 1; i >= 0; i-- {
+				start, count, err := ix.LastRows(runs[i].first, runs[i].last, n)
+				if err != nil {
+					return nil, err
+				}
+				if n -= count; n == 0 {
+					runs[i].first = start
+					runs = runs[i:]
+					break
+				}
+			}
+		} else {
+			for i := range runs {
+				end, count, err := ix.FirstRows(runs[i].first, runs[i].last, n)
+				if err != nil {
+					return nil, err
+				}
+				if n -= count; n == 0 {
+					runs[i].last = end
+					runs = runs[:i+1]
+					break
+				}
+			}
+		}
+	}
+
+	plans := make([]*ioFilePlan, len(runs))
+	for i, r := range runs {
+		plan := *fp
+		plan.Offset = IndexToOffset(r.first, int32(recordLen))
+		plan.Length = (r.last - r.first + 1) * recordLen
+		plans[i] = &plan
+	}
+	return plans, nil
+}
+
+// inColumnRanges returns true if a block may hold a value in every column range on an indexed column.
+func inColumnRanges(ix *blockindex.Index, b *blockindex.Block, columnRanges []planner.ColumnRange) bool {
+	for _, cr := range columnRanges {
+		if col := ix.ColumnIndex(cr.Name); col >= 0 && !b.Overlaps(col, cr.Min, cr.Max) {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+BuildBlockIndex builds the block index of a year file or an archive file, replacing its current index.
+It must not run while the year is written.
+*/
+func BuildBlockIndex(tbi *TimeBucketInfo) error {
+	path := blockindex.PathOf(tbi.Path)
+	tmpPath := path + ".tmp"
+	_ = os.Remove(tmpPath)
+	ix, err := blockindex.Create(tmpPath, tbi)
+	if err != nil {
+		return err
+	}
+	if tbi.IsArchived() {
+		err = indexArchive(ix, tbi)
+	} else {
+		err = indexYearFile(ix, tbi)
+	}
+	if err == nil {
+		err = ix.Flush()
+	}
+	if err == nil {
+		err = ix.Sync()
+	}
+	if err2 := ix.Close(); err == nil {
+		err = err2
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("build block index of %s: %w", tbi.Path, err)
+	}
+	return os.Rename(tmpPath, path)
+}
+
+// rebuildBlockIndex builds again the block index of a year file if it has one, and if it is outdated or force is set.
+func rebuildBlockIndex(tbi *TimeBucketInfo, force bool) error {
+	ix, err := blockindex.Open(blockindex.PathOf(tbi.Path), false)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err == nil {
+		force = force || !ix.Matches(tbi)
+		_ = ix.Close()
+	}
+	if err != nil || force {
+		return BuildBlockIndex(tbi)
+	}
+	return nil
+}
+
+func indexYearFile(ix *blockindex.Index, tbi *TimeBucketInfo) error {
+	f, err := os.Open(tbi.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return readYearFile(f, tbi, ix.Add)
+}
+
+func indexArchive(ix *blockindex.Index, tbi *TimeBucketInfo) error {
+	a, err := archive.Open(tbi.Path, tbi)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	for i := range a.Chunks() {
+		c, err := a.ReadChunk(i)
+		if err != nil {
+			return err
+		}
+		for j, epoch := range c.Epochs {
+			if err = ix.Add(EpochToIndex(epoch, tbi.GetTimeframe()), c.Row(j)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+/*
+indexWrites adds the records of the writes to a year file to its block index, if it has one. It is called
+before the records are written, so that a concurrent read never skips the blocks of the new records.
+*/
+func indexWrites(fullPath string, writes []wal.OffsetIndexBuffer) error {
+	ix, err := blockindex.Open(blockindex.PathOf(fullPath), true)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, buffer := range writes {
+		if buffer.IsDelete() || buffer.IsAlter() || buffer.IsDrop() {
+			continue
+		}
+		if err = ix.Add(buffer.Index(), buffer.Payload()); err != nil {
+			_ = ix.Close()
+			return err
+		}
+	}
+	return ix.Close()
+}
+
+// reindexDeletes computes again the blocks of the block index of a year file holding records deleted by the writes.
+func reindexDeletes(fullPath string, writes []wal.OffsetIndexBuffer) error {
+	var deleted []int64
+	for _, buffer := range writes {
+		if buffer.IsDelete() {
+			deleted = append(deleted, deletedIndexes(buffer)...)
+		}
+	}
+	if len(deleted) == 0 {
+		return nil
+	}
+	ix, err := blockindex.Open(blockindex.PathOf(fullPath), true)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	err = reindexBlocks(ix, fullPath, deleted)
+	if err2 := ix.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+func reindexBlocks(ix *blockindex.Index, fullPath string, indexes []int64) error {
+	tbi, err := ReadTimeBucketInfo(fullPath)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(fullPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	done := map[int64]bool{}
+	for _, index := range indexes {
+		block := ix.BlockOf(index)
+		if index < 1 || index > ix.Intervals || done[block] {
+			continue
+		}
+		done[block] = true
+		if err = ix.Reset(block); err != nil {
+			return err
+		}
+		first, last := ix.BlockRange(block)
+		if err = readYearFileRange(f, tbi, first, last, ix.Add); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+addYearFile returns the file of a year of the bucket of tbi, creating it if needed. A new year file
+gets an empty block index when the year of tbi has one, so that the bucket stays indexed.
+*/
+func (w *Writer) addYearFile(tbi *TimeBucketInfo, year int16) (*TimeBucketInfo, error) {
+	dir := filepath.Dir(tbi.Path)
+	isNew := true
+	for _, ext := range []string{YearFileExt, ArchiveFileExt, RemoteFileExt} {
+		if _, err := os.Stat(filepath.Join(dir, strconv.Itoa(int(year))+ext)); err == nil {
+			isNew = false
+		}
+	}
+	newTbi, err := w.rootCatDir.GetSubDirectoryAndAddFile(tbi.Path, year)
+	if err != nil || !isNew {
+		return newTbi, err
+	}
+	if _, err = os.Stat(blockindex.PathOf(tbi.Path)); err != nil {
+		return newTbi, nil
+	}
+	ix, err := blockindex.Create(blockindex.PathOf(newTbi.Path), newTbi)
+	switch {
+	case errors.Is(err, os.ErrExist):
+		// created by a concurrent write
+	case err != nil:
+		log.Warn("failed to create the block index of %s: %v", newTbi.Path, err)
+	default:
+		_ = ix.Close()
+	}
+	return newTbi, nil
+}
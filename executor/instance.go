@@ -10,6 +10,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor/tiered"
 	"github.com/alpacahq/marketstore/v4/executor/wal"
 	"github.com/alpacahq/marketstore/v4/plugins/trigger"
 	"github.com/alpacahq/marketstore/v4/utils/log"
@@ -27,6 +28,7 @@ type InstanceMetadataOptions struct {
 	initWALCache   bool
 	backgroundSync bool
 	walBypass      bool
+	remoteStorage  *tiered.Storage
 }
 type Option func(option *InstanceMetadataOptions)
 
@@ -54,6 +56,13 @@ func WALBypass(f bool) Option {
 	}
 }
 
+// RemoteStorage sets the tiered storage where the offloaded years are read from.
+func RemoteStorage(s *tiered.Storage) Option {
+	return func(option *InstanceMetadataOptions) {
+		option.remoteStorage = s
+	}
+}
+
 func NewInstanceSetup(relRootDir string, rs ReplicationSender, tm []*trigger.TriggerMatcher,
 	walRotateInterval int, options ...Option,
 ) (metadata *InstanceMetadata, shutdownPending *bool, walWG *sync.WaitGroup, err error) {
@@ -75,6 +84,7 @@ func NewInstanceSetup(relRootDir string, rs ReplicationSender, tm []*trigger.Tri
 	if ThisInstance == nil {
 		ThisInstance = new(InstanceMetadata)
 	}
+	remoteStorage = opts.remoteStorage
 
 	// rootDir is the absolute path to the data directory.
 	// e.g. rootDir = "/project/marketstore/data"
@@ -1,8 +1,6 @@
 package executor
 
 import (
-	"os"
-
 	"github.com/klauspost/compress/snappy"
 
 	"github.com/alpacahq/marketstore/v4/utils"
@@ -17,24 +15,25 @@ func (r *Reader) readSecondStage(bufMeta []bufferMeta) (rb []byte, err error) {
 	var varRecLen int
 	// resultBuffers for all bufMetas
 	totalBuf := make([]byte, 0)
-	for _, md := range bufMeta {
+	for i := range bufMeta {
+		md := &bufMeta[i]
 		varRecLen = md.VarRecLen
-		file := md.FullPath
 		indexBuffer := md.Data
 
-		// Open the file to read the data
-		const readWriteAll = 0o666
-		fp, err := os.OpenFile(file, os.O_RDONLY, readWriteAll)
+		// Open the file to read the data, archived years are read uncompressed
+		fp, err := openData(md)
 		if err != nil {
 			return nil, err
 		}
+		_, archived := fp.(*archiveData)
+		compressed := !utils.InstanceConfig.DisableVariableCompression && !archived
 		/*
 			Calculate how much space is needed in the results buffer
 		*/
 		var totalDatalen int
 		// Without compression we have the exact size of the output buffer
 		numIndexRecords := len(indexBuffer) / 24 // Three fields, {epoch, offset, len}, 8 bytes each
-		if utils.InstanceConfig.DisableVariableCompression {
+		if !compressed {
 			for i := 0; i < numIndexRecords; i++ {
 				datalen := int(ToInt64(indexBuffer[i*24+16:]))
 				numVarRecords := datalen / varRecLen // TODO: This doesn't work with compression
@@ -64,7 +63,7 @@ func (r *Reader) readSecondStage(bufMeta []bufferMeta) (rb []byte, err error) {
 				return nil, err
 			}
 
-			if !utils.InstanceConfig.DisableVariableCompression {
+			if compressed {
 				buffer, err = snappy.Decode(nil, buffer)
 				if err != nil {
 					return nil, err
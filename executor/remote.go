@@ -0,0 +1,44 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/alpacahq/marketstore/v4/executor/tiered"
+	. "github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// remoteStorage is the tiered storage of the offloaded years, nil if it is not configured.
+var remoteStorage *tiered.Storage
+
+/*
+localYear returns the year to read in place of tbi: tbi itself if its file is in the root directory, or a copy
+of tbi with the path of the file in the cache of the tiered storage if the year is offloaded.
+*/
+func localYear(tbi *TimeBucketInfo) (*TimeBucketInfo, error) {
+	if !tbi.IsRemote() {
+		return tbi, nil
+	}
+	if remoteStorage == nil {
+		return nil, fmt.Errorf("year %d is offloaded, but the tiered storage is not configured: %s",
+			tbi.Year, tbi.Path)
+	}
+	path, err := remoteStorage.Fetch(tbi.Path)
+	if err != nil {
+		return nil, fmt.Errorf("fetch offloaded year %d: %w", tbi.Year, err)
+	}
+	local := tbi.GetDeepCopy()
+	local.Path = path
+	return local, nil
+}
+
+// readOnlyYear returns an error if the year can not be written, because it is archived or offloaded.
+func readOnlyYear(tbi *TimeBucketInfo) error {
+	switch {
+	case tbi.IsArchived():
+		return fmt.Errorf("year %d is archived and read-only: %s", tbi.Year, tbi.Path)
+	case tbi.IsRemote():
+		return fmt.Errorf("year %d is offloaded to the remote storage and read-only, recall it to write it: %s",
+			tbi.Year, tbi.Path)
+	}
+	return nil
+}
@@ -0,0 +1,256 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor/blockindex"
+	"github.com/alpacahq/marketstore/v4/executor/tiered"
+	"github.com/alpacahq/marketstore/v4/metrics"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+/*
+DropYear removes a year of a bucket, with its rows and its block index, through the WAL, so that the
+removal is durable and replicated. The latest year of a bucket holds its layout and is never removed,
+its rows can be deleted instead. Dropping a year that does not exist is not an error.
+*/
+func (w *Writer) DropYear(tbk *io.TimeBucketKey, year int16) error {
+	bucketLayout.RLock()
+	defer bucketLayout.RUnlock()
+
+	latest, err := w.rootCatDir.GetLatestTimeBucketInfoFromKey(tbk)
+	if err != nil {
+		return fmt.Errorf("bucket %s is not found: %w", tbk.GetItemKey(), err)
+	}
+	if year >= latest.Year {
+		return fmt.Errorf("drop %s: year %d is the latest year of the bucket", tbk.GetItemKey(), year)
+	}
+	tbi := findYear(w.rootCatDir, latest, year)
+	if tbi == nil {
+		return nil
+	}
+
+	// the rows queued before the drop are written to the year before it is removed
+	w.walFile.RequestFlush()
+	done := make(chan error, 1)
+	wc := w.walFile.DropCommand(latest.GetRecordType(), tbi.Path, latest.GetDataShapesWithEpoch())
+	wc.Done = done
+	w.walFile.QueueWriteCommand(wc)
+	w.walFile.RequestFlush()
+	if err = <-done; err != nil {
+		return fmt.Errorf("drop year %d of %s: %w", year, tbk.GetItemKey(), err)
+	}
+	return w.rootCatDir.RemoveYearFile(tbi.Path)
+}
+
+// findYear returns the year of the bucket of latest, or nil if the bucket does not have it.
+func findYear(rootCatDir *catalog.Directory, latest *io.TimeBucketInfo, year int16) *io.TimeBucketInfo {
+	subDir, err := rootCatDir.GetOwningSubDirectory(latest.Path)
+	if err != nil {
+		return nil
+	}
+	for _, tbi := range subDir.GetTimeBucketInfoSlice() {
+		if tbi.Year == year {
+			return tbi
+		}
+	}
+	return nil
+}
+
+/*
+dropYearFile removes a year file and its block index. The object of an offloaded year is removed from the
+remote storage too. A file that is already removed is skipped, so that a drop replayed from the WAL is a no-op.
+*/
+func dropYearFile(path string) error {
+	if strings.HasSuffix(path, io.RemoteFileExt) {
+		if remoteStorage == nil {
+			log.Warn("the tiered storage is not configured, the object of %s is left in the remote storage", path)
+		} else if err := remoteStorage.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	for _, p := range []string{path, blockindex.PathOf(path), io.HeapPathOf(path)} {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	forgetHeap(io.HeapPathOf(path))
+	return nil
+}
+
+// RetentionResult reports what a retention policy removes, or would remove in a dry run, from a bucket.
+type RetentionResult struct {
+	Pattern string
+	Key     *io.TimeBucketKey
+	// Cutoff is the time before which the rows are removed
+	Cutoff time.Time
+	// DroppedYears are the years removed as a whole
+	DroppedYears []int16
+	// DroppedBytes is the size of the dropped years on the disk, or in the remote storage if offloaded
+	DroppedBytes int64
+	// TruncatedYear is the year whose rows before the cutoff are deleted, 0 if none
+	TruncatedYear int16
+}
+
+// Retention applies the retention policies of the configuration to the buckets of a writer.
+type Retention struct {
+	w       *Writer
+	setting utils.RetentionSetting
+	// truncated is the cutoff of the last deletion of the rows of each bucket, the rows before it are
+	// already deleted
+	truncated map[string]time.Time
+}
+
+// NewRetention returns the retention of the buckets written by w.
+func NewRetention(w *Writer, setting utils.RetentionSetting) *Retention {
+	return &Retention{w: w, setting: setting, truncated: map[string]time.Time{}}
+}
+
+// Run applies the policies at each interval of the setting until ctx is done, starting immediately.
+func (r *Retention) Run(ctx context.Context) {
+	t := time.NewTicker(r.setting.Interval)
+	defer t.Stop()
+	for {
+		if _, err := r.Apply(time.Now()); err != nil {
+			log.Error("failed to apply the retention policies: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+/*
+Apply applies the policies at the time now. The years of a bucket that end before the cutoff of its policy
+are dropped as a whole, and the rows of the year of the cutoff are deleted up to it. A bucket matching
+several patterns keeps its rows for the shortest of their periods. In a dry run, nothing is removed and the
+results report what would be.
+
+The failure of a bucket does not stop the others, the first error is returned.
+*/
+func (r *Retention) Apply(now time.Time) (results []RetentionResult, err error) {
+	dryRun := strconv.FormatBool(r.setting.DryRun)
+	for i := range r.setting.Policies {
+		policy := &r.setting.Policies[i]
+		for _, key := range catalog.MatchTimeBucketKeys(r.w.rootCatDir, io.NewTimeBucketKey(policy.Pattern)) {
+			res, err2 := r.applyToBucket(policy, key, policy.Cutoff(now))
+			if err2 != nil {
+				log.Error("failed to apply the retention of %s to %s: %v", policy.Pattern, key.GetItemKey(), err2)
+				if err == nil {
+					err = err2
+				}
+			}
+			if res == nil {
+				continue
+			}
+			metrics.RetentionDroppedYears.WithLabelValues(policy.Pattern, dryRun).Add(float64(len(res.DroppedYears)))
+			metrics.RetentionDroppedBytes.WithLabelValues(policy.Pattern, dryRun).Add(float64(res.DroppedBytes))
+			if res.TruncatedYear != 0 {
+				metrics.RetentionTruncatedYears.WithLabelValues(policy.Pattern, dryRun).Inc()
+			}
+			results = append(results, *res)
+		}
+	}
+	metrics.RetentionLastRunTimestamp.Set(float64(now.Unix()))
+	return results, err
+}
+
+// applyToBucket removes the rows of a bucket before the cutoff, it returns nil if there is nothing to remove.
+func (r *Retention) applyToBucket(policy *utils.RetentionPolicy, key *io.TimeBucketKey, cutoff time.Time,
+) (*RetentionResult, error) {
+	latest, err := r.w.rootCatDir.GetLatestTimeBucketInfoFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+	subDir, err := r.w.rootCatDir.GetOwningSubDirectory(latest.Path)
+	if err != nil {
+		return nil, err
+	}
+	years := subDir.GetTimeBucketInfoSlice()
+	sort.Slice(years, func(i, j int) bool { return years[i].Year < years[j].Year })
+
+	res := &RetentionResult{Pattern: policy.Pattern, Key: key, Cutoff: cutoff}
+	prefix := ""
+	if r.setting.DryRun {
+		prefix = "[dry run] "
+	}
+	for _, tbi := range years {
+		if int(tbi.Year) >= cutoff.Year() || tbi.Year >= latest.Year {
+			break
+		}
+		size := yearSize(tbi)
+		log.Info("%sretention %s: dropping year %d of %s", prefix, policy.Period, tbi.Year, key.GetItemKey())
+		if !r.setting.DryRun {
+			if err = r.w.DropYear(key, tbi.Year); err != nil {
+				return res, err
+			}
+		}
+		res.DroppedYears = append(res.DroppedYears, tbi.Year)
+		res.DroppedBytes += size
+	}
+
+	// the latest year is truncated instead of dropped, even if it is over
+	year := int16(cutoff.Year())
+	if latest.Year < year {
+		year = latest.Year
+	}
+	start := time.Date(int(year), time.January, 1, 0, 0, 0, 0, time.UTC)
+	if last, ok := r.truncated[key.GetItemKey()]; ok && last.After(start) {
+		// the rows before the last cutoff are already deleted
+		start = last
+	}
+	if tbi := findYear(r.w.rootCatDir, latest, year); tbi != nil && start.Before(cutoff) {
+		if err = readOnlyYear(tbi); err != nil {
+			log.Warn("retention %s: the rows of %s before %v are kept: %v", policy.Period, key.GetItemKey(),
+				cutoff, err)
+		} else {
+			log.Info("%sretention %s: deleting the rows of %s from %v to %v", prefix, policy.Period,
+				key.GetItemKey(), start, cutoff)
+			if !r.setting.DryRun {
+				if err = r.w.Delete(key, start, cutoff.Add(-time.Nanosecond)); err != nil {
+					return res, err
+				}
+				r.truncated[key.GetItemKey()] = cutoff
+			}
+			res.TruncatedYear = year
+		}
+	}
+	if len(res.DroppedYears) == 0 && res.TruncatedYear == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// yearSize returns the size of a year file on the disk, or of its object if it is offloaded.
+func yearSize(tbi *io.TimeBucketInfo) int64 {
+	if tbi.IsRemote() {
+		stub, err := tiered.ReadStub(tbi.Path)
+		if err != nil {
+			return 0
+		}
+		return stub.Size
+	}
+	fi, err := os.Stat(tbi.Path)
+	if err != nil {
+		return 0
+	}
+	// the year files are sparse, only their allocated blocks are freed
+	if stat, ok := fi.Sys().(*syscall.Stat_t); ok {
+		const blockSize = 512
+		return stat.Blocks * blockSize
+	}
+	return fi.Size()
+}
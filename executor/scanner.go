@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"os"
 	"sort"
 	"time"
 
@@ -53,6 +52,7 @@ type ioplan struct {
 }
 
 func NewIOPlan(fl SortedFileList, limit *planner.RowLimit, range2 *planner.DateRange, timeQuals []planner.TimeQualFunc,
+	columnRanges []planner.ColumnRange,
 ) (iop *ioplan, err error) {
 	iop = &ioplan{
 		FilePlan: make([]*ioFilePlan, 0),
@@ -126,7 +126,7 @@ func NewIOPlan(fl SortedFileList, limit *planner.RowLimit, range2 *planner.DateR
 			if iop.Limit.Direction == LAST {
 				fp.seekingLast = true
 			}
-			iop.FilePlan = append(iop.FilePlan, fp)
+			iop.FilePlan = append(iop.FilePlan, blockPlans(fp, limit, len(timeQuals) != 0, columnRanges)...)
 		}
 	}
 
@@ -159,7 +159,7 @@ func NewReader(pr *planner.ParseResult) (r *Reader, err error) {
 	maxRecordLen := int32(0)
 	for key, sfl := range sortedFileMap {
 		sort.Sort(sfl)
-		if r.IOPMap[key], err = NewIOPlan(sfl, pr.Limit, pr.Range, pr.TimeQuals); err != nil {
+		if r.IOPMap[key], err = NewIOPlan(sfl, pr.Limit, pr.Range, pr.TimeQuals, pr.ColumnRanges); err != nil {
 			return nil, err
 		}
 		recordLen := r.IOPMap[key].RecordLen
@@ -201,11 +201,30 @@ func (r *Reader) Read() (csm ColumnSeriesMap, err error) {
 		}
 		rs := NewRowSeries(key, buffer, dsMap[key], rlen, rt)
 		key, cs := rs.ToColumnSeries()
+		if HasHeapColumns(dsMap[key]) {
+			if err = readHeapColumns(cs, dsMap[key], r.yearFiles(key)); err != nil {
+				return nil, fmt.Errorf("read %s: %w", key.GetItemKey(), err)
+			}
+		}
+		if IsNullable(dsMap[key]) {
+			cs.UnpackNulls(dsMap[key])
+		}
 		csm[key] = cs
 	}
 	return csm, err
 }
 
+// yearFiles returns the paths of the year files of a bucket read by the reader, by year.
+func (r *Reader) yearFiles(key TimeBucketKey) map[int16]string {
+	paths := map[int16]string{}
+	for _, qf := range r.pr.QualifiedFiles {
+		if qf.Key == key {
+			paths[qf.File.Year] = qf.File.Path
+		}
+	}
+	return paths
+}
+
 func trimResultsToRange(dr *planner.DateRange, rowlen int, src []byte) (dest []byte) {
 	// find the beginning of the range (sorted order)
 	rowLength := rowlen + epochLenBytes + nanosecLenBytes - intervalTicksLenBytes
@@ -265,6 +284,7 @@ func trimResultsToLimit(l *planner.RowLimit, rowLen int, src []byte) []byte {
 bufferMeta stores an indirect index to variable length data records. It's used to read the actual data in a second pass.
 */
 type bufferMeta struct {
+	tbi       *TimeBucketInfo
 	FullPath  string
 	Data      []byte
 	VarRecLen int
@@ -274,6 +294,10 @@ type bufferMeta struct {
 // Reads the data from files, removing holes. The resulting buffer will be packed
 // Uses the index that prepends each row to identify filled rows versus holes.
 func (r *Reader) read(iop *ioplan) ([]byte, error) {
+	// the records of the intervals and their rows are read from the same year files
+	yearFileSwap.RLock()
+	defer yearFileSwap.RUnlock()
+
 	var (
 		resultBuffer []byte
 		err          error
@@ -330,6 +354,7 @@ func (r *Reader) read(iop *ioplan) ([]byte, error) {
 				// If we've added data to the buffer from this file, record it for possible later use
 				if len(resultBuffer) > dataLen {
 					bufMeta = append(bufMeta, bufferMeta{
+						tbi:       fp.tbi,
 						FullPath:  fp.FullPath,
 						Data:      resultBuffer[dataLen:],
 						VarRecLen: iop.VariableRecordLen,
@@ -367,6 +392,7 @@ func (r *Reader) read(iop *ioplan) ([]byte, error) {
 						bufMetaLen = int32(len(resultBuffer))
 					}
 					bufMeta = append(bufMeta, bufferMeta{
+						tbi:       fp[i].tbi,
 						FullPath:  fp[i].FullPath,
 						Data:      resultBuffer[bytesLeftToFill : bytesLeftToFill+bufMetaLen],
 						VarRecLen: iop.VariableRecordLen,
@@ -485,7 +511,6 @@ func (ex *ioExec) packingReader(packedBuffer *[]byte, f io.ReadSeeker, buffer []
 
 func (ex *ioExec) readForward(finalBuffer []byte, fp *ioFilePlan, bytesToRead int32, readBuffer []byte) (
 	resultBuffer []byte, finished bool, err error) {
-	const readWriteAll = 0o666
 	// log.Info("reading forward [recordLen: %v bytesToRead: %v]", recordLen, bytesToRead)
 	filePath := fp.FullPath
 
@@ -493,7 +518,7 @@ func (ex *ioExec) readForward(finalBuffer []byte, fp *ioFilePlan, bytesToRead in
 		finalBuffer = make([]byte, 0, len(readBuffer))
 	}
 	// Forward scan
-	f, err := os.OpenFile(filePath, os.O_RDONLY, readWriteAll)
+	f, err := openPrimary(fp)
 	if err != nil {
 		log.Error("Read: opening %s\n%s", filePath, err)
 		return nil, false, err
@@ -520,7 +545,6 @@ func (ex *ioExec) readForward(finalBuffer []byte, fp *ioFilePlan, bytesToRead in
 func (ex *ioExec) readBackward(finalBuffer []byte, fp *ioFilePlan,
 	recordLen, bytesToRead int32, readBuffer, fileBuffer []byte) (
 	result []byte, finished bool, bytesRead int32, err error) {
-	const readWriteAll = 0o666
 	// log.Info("reading backward [recordLen: %v bytesToRead: %v offset: %v]", recordLen, bytesToRead, fp.Offset)
 
 	filePath := fp.FullPath
@@ -531,7 +555,7 @@ func (ex *ioExec) readBackward(finalBuffer []byte, fp *ioFilePlan,
 		finalBuffer = make([]byte, bytesToRead)
 	}
 
-	f, err := os.OpenFile(filePath, os.O_RDONLY, readWriteAll)
+	f, err := openPrimary(fp)
 	if err != nil {
 		log.Error("Read: opening %s\n%s", filePath, err)
 		return nil, false, 0, err
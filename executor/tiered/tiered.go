@@ -0,0 +1,385 @@
+/*
+Package tiered moves the year files of the closed years to a blob store, and back.
+
+An offloaded year is replaced in the root directory by a stub ({Year}.remote), which starts with the same
+header as the year file or the archive file it replaces, so that the catalog reads its layout as usual,
+followed by the description of the object in JSON:
+
+	| Header | {"key": ..., "ext": ".bin", "size": ..., "sha256": ...} |
+
+The offloaded years are read-only. A read downloads the year into the cache directory, where it is kept
+until the cache grows over its maximum size, the least recently read years being removed first.
+*/
+package tiered
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goio "io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/blob"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+const (
+	dirPerm  = 0o700
+	filePerm = 0o600
+	tmpExt   = ".tmp"
+)
+
+var ErrNotStub = errors.New("not a stub of an offloaded year")
+
+// Stub describes the object of an offloaded year.
+type Stub struct {
+	// Key is the key of the object, the path of the year file relative to the root directory
+	Key string `json:"key"`
+	// Ext is the extension of the file the stub replaces, YearFileExt or ArchiveFileExt
+	Ext    string `json:"ext"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Storage offloads the years of a root directory to a blob store, and caches the offloaded years on read.
+type Storage struct {
+	store    blob.Store
+	rootDir  string
+	cacheDir string
+	// maxCacheBytes is the maximum size of the cache directory, 0 if unlimited
+	maxCacheBytes int64
+
+	mu sync.Mutex
+	// fetching holds a lock per key, so that a year is downloaded once by concurrent reads
+	fetching map[string]*sync.Mutex
+}
+
+// New returns the tiered storage of the years of rootDir in store, cached in cacheDir.
+func New(store blob.Store, rootDir, cacheDir string, maxCacheBytes int64) (*Storage, error) {
+	rootDir, err := filepath.Abs(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	if cacheDir, err = filepath.Abs(cacheDir); err != nil {
+		return nil, err
+	}
+	if rel, err2 := filepath.Rel(rootDir, cacheDir); err2 == nil && !strings.HasPrefix(rel, "..") {
+		return nil, fmt.Errorf("cache directory %s is in the root directory %s", cacheDir, rootDir)
+	}
+	if err = os.MkdirAll(cacheDir, dirPerm); err != nil {
+		return nil, fmt.Errorf("create cache directory %s: %w", cacheDir, err)
+	}
+	return &Storage{
+		store:         store,
+		rootDir:       rootDir,
+		cacheDir:      cacheDir,
+		maxCacheBytes: maxCacheBytes,
+		fetching:      map[string]*sync.Mutex{},
+	}, nil
+}
+
+// CacheDir returns the path of the cache directory.
+func (s *Storage) CacheDir() string {
+	return s.cacheDir
+}
+
+/*
+Offload uploads the file of a year (a year file or an archive file) to the blob store and replaces it with
+a stub. The catalog of a running instance is not updated, so it is meant to be done while the instance is
+stopped, like the archiving of a year.
+*/
+func (s *Storage) Offload(tbi *io.TimeBucketInfo) error {
+	if tbi.IsRemote() {
+		return fmt.Errorf("year %d is already offloaded: %s", tbi.Year, tbi.Path)
+	}
+	rel, err := filepath.Rel(s.rootDir, tbi.Path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("%s is not in the root directory %s", tbi.Path, s.rootDir)
+	}
+	ext := filepath.Ext(tbi.Path)
+	stubPath := strings.TrimSuffix(tbi.Path, ext) + io.RemoteFileExt
+
+	f, err := os.Open(tbi.Path)
+	if err != nil {
+		return fmt.Errorf("open year file %s: %w", tbi.Path, err)
+	}
+	defer f.Close()
+	header := make([]byte, io.Headersize)
+	if _, err = f.ReadAt(header, 0); err != nil {
+		return fmt.Errorf("read header of %s: %w", tbi.Path, err)
+	}
+	size, sum, err := hashFile(f)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", tbi.Path, err)
+	}
+	if _, err = f.Seek(0, goio.SeekStart); err != nil {
+		return err
+	}
+	stub := &Stub{Key: filepath.ToSlash(rel), Ext: ext, Size: size, SHA256: sum}
+	if err = s.store.Put(stub.Key, f, size); err != nil {
+		return fmt.Errorf("upload %s: %w", tbi.Path, err)
+	}
+
+	if err = writeStub(stubPath, header, stub); err != nil {
+		return err
+	}
+	if err = os.Remove(tbi.Path); err != nil {
+		return fmt.Errorf("remove year file %s: %w", tbi.Path, err)
+	}
+	tbi.Path = stubPath
+	return nil
+}
+
+/*
+Recall downloads an offloaded year back into the root directory, where it replaces its stub, and removes
+its object from the blob store. Like Offload, it is meant to be done while the instance is stopped.
+*/
+func (s *Storage) Recall(tbi *io.TimeBucketInfo) error {
+	if !tbi.IsRemote() {
+		return fmt.Errorf("year %d is not offloaded: %s", tbi.Year, tbi.Path)
+	}
+	stub, err := ReadStub(tbi.Path)
+	if err != nil {
+		return err
+	}
+	path := strings.TrimSuffix(tbi.Path, io.RemoteFileExt) + stub.Ext
+	if err = s.download(stub, path); err != nil {
+		return err
+	}
+	if err = os.Remove(tbi.Path); err != nil {
+		return fmt.Errorf("remove stub %s: %w", tbi.Path, err)
+	}
+	tbi.Path = path
+	_ = os.Remove(s.cachePath(stub))
+	if err = s.store.Delete(stub.Key); err != nil {
+		log.Warn("failed to delete the object of %s from the remote storage: %v", path, err)
+	}
+	return nil
+}
+
+/*
+Remove deletes the object of an offloaded year from the blob store and its cached copy, e.g. when the
+year expires. The stub is left to the caller, which removes it with the year.
+*/
+func (s *Storage) Remove(stubPath string) error {
+	stub, err := ReadStub(stubPath)
+	if err != nil {
+		return err
+	}
+	_ = os.Remove(s.cachePath(stub))
+	if err = s.store.Delete(stub.Key); err != nil {
+		return fmt.Errorf("delete the object of %s from the remote storage: %w", stubPath, err)
+	}
+	return nil
+}
+
+/*
+Fetch returns the path of the file of an offloaded year in the cache directory, downloading it from the
+blob store if it is not cached. The path has the extension of the file the stub replaces.
+*/
+func (s *Storage) Fetch(stubPath string) (string, error) {
+	stub, err := ReadStub(stubPath)
+	if err != nil {
+		return "", err
+	}
+	path := s.cachePath(stub)
+
+	s.mu.Lock()
+	keyLock, ok := s.fetching[stub.Key]
+	if !ok {
+		keyLock = &sync.Mutex{}
+		s.fetching[stub.Key] = keyLock
+	}
+	s.mu.Unlock()
+	keyLock.Lock()
+	defer keyLock.Unlock()
+
+	if fi, err2 := os.Stat(path); err2 == nil && fi.Size() == stub.Size {
+		// the modification time orders the cached years by last read
+		now := time.Now()
+		_ = os.Chtimes(path, now, now)
+		return path, nil
+	}
+	if err = s.download(stub, path); err != nil {
+		return "", err
+	}
+	s.evict(path)
+	return path, nil
+}
+
+// download writes the object of a stub at path, once its checksum is verified.
+func (s *Storage) download(stub *Stub, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return err
+	}
+	r, err := s.store.Get(stub.Key)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", stub.Key, err)
+	}
+	defer r.Close()
+	tmpPath := path + tmpExt
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, filePerm)
+	if err != nil {
+		return err
+	}
+	h := sha256.New()
+	size, err := goio.Copy(f, goio.TeeReader(r, h))
+	if err == nil && (size != stub.Size || hex.EncodeToString(h.Sum(nil)) != stub.SHA256) {
+		err = fmt.Errorf("checksum mismatch of %s, %d bytes downloaded instead of %d", stub.Key, size, stub.Size)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("download %s: %w", stub.Key, err)
+	}
+	return nil
+}
+
+func (s *Storage) cachePath(stub *Stub) string {
+	return filepath.Join(s.cacheDir, filepath.FromSlash(stub.Key))
+}
+
+// evict removes the least recently read years from the cache until it fits its maximum size, except keep.
+func (s *Storage) evict(keep string) {
+	if s.maxCacheBytes <= 0 {
+		return
+	}
+	type cached struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var (
+		files []cached
+		total int64
+	)
+	_ = filepath.WalkDir(s.cacheDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) == tmpExt {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		total += fi.Size()
+		if path != keep {
+			files = append(files, cached{path: path, size: fi.Size(), modTime: fi.ModTime()})
+		}
+		return nil
+	})
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= s.maxCacheBytes {
+			return
+		}
+		// a year being read is still readable once removed
+		if err := os.Remove(f.path); err != nil {
+			log.Warn("failed to remove %s from the cache: %v", f.path, err)
+			continue
+		}
+		total -= f.size
+	}
+}
+
+// ReadStub reads the description of the object of an offloaded year.
+func ReadStub(path string) (*Stub, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) <= io.Headersize {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotStub)
+	}
+	stub := &Stub{}
+	if err = json.Unmarshal(buf[io.Headersize:], stub); err != nil || stub.Key == "" {
+		return nil, fmt.Errorf("%s: %w", path, ErrNotStub)
+	}
+	return stub, nil
+}
+
+func writeStub(path string, header []byte, stub *Stub) error {
+	buf, err := json.Marshal(stub)
+	if err != nil {
+		return err
+	}
+	tmpPath := path + tmpExt
+	if err = os.WriteFile(tmpPath, append(header, buf...), filePerm); err != nil {
+		return fmt.Errorf("write stub %s: %w", tmpPath, err)
+	}
+	if err = os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("rename stub %s: %w", tmpPath, err)
+	}
+	return nil
+}
+
+// RemoteUsage returns the total size of the years of rootDir offloaded to the remote storage.
+func RemoteUsage(rootDir string) (size int64) {
+	_ = filepath.WalkDir(rootDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != io.RemoteFileExt {
+			return nil
+		}
+		if stub, err := ReadStub(path); err == nil {
+			size += stub.Size
+		}
+		return nil
+	})
+	return size
+}
+
+// hashFile returns the size and the SHA-256 of a file read from its current position.
+func hashFile(f *os.File) (size int64, sum string, err error) {
+	h := sha256.New()
+	if size, err = goio.Copy(h, f); err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+/*
+NewFromSetting returns the tiered storage of the configuration for the years of rootDir, or nil if it is not
+configured. The credentials of the S3 backend are read from the AWS environment variables when they are
+not in the configuration.
+*/
+func NewFromSetting(setting utils.TieredStorageSetting, rootDir string) (*Storage, error) {
+	var (
+		store blob.Store
+		err   error
+	)
+	switch setting.Backend {
+	case "":
+		return nil, nil
+	case "local":
+		store, err = blob.NewLocal(setting.LocalDirectory)
+	case "s3":
+		config := blob.S3Config(setting.S3)
+		if config.AccessKeyID == "" {
+			config.AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+			config.SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+			config.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+		}
+		store, err = blob.NewS3(config)
+	default:
+		err = fmt.Errorf("unknown tiered storage backend %q", setting.Backend)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return New(store, rootDir, setting.CacheDirectory, setting.CacheMaxBytes)
+}
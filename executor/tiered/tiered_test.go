@@ -0,0 +1,124 @@
+package tiered_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alpacahq/marketstore/v4/executor/tiered"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/blob"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// yearFile creates the file of a year of the bucket AAPL/1D/OHLCV, with size bytes after its header.
+func yearFile(t *testing.T, rootDir string, year int16, size int) *io.TimeBucketInfo {
+	t.Helper()
+	dir := filepath.Join(rootDir, "AAPL", "1D", "OHLCV")
+	require.Nil(t, os.MkdirAll(dir, 0o700))
+	dsv := io.NewDataShapeVector([]string{"Close"}, []io.EnumElementType{io.FLOAT32})
+	tbi := io.NewTimeBucketInfo(*utils.TimeframeFromString("1D"), dir, "Test", year, dsv, io.FIXED)
+	f, err := os.Create(tbi.Path)
+	require.Nil(t, err)
+	require.Nil(t, io.WriteHeader(f, tbi))
+	_, err = f.Write(make([]byte, size))
+	require.Nil(t, err)
+	require.Nil(t, f.Close())
+	return &io.TimeBucketInfo{Year: year, Path: tbi.Path}
+}
+
+func TestOffloadRecall(t *testing.T) {
+	t.Parallel()
+
+	rootDir, remoteDir, cacheDir := t.TempDir(), t.TempDir(), t.TempDir()
+	store, err := blob.NewLocal(remoteDir)
+	require.Nil(t, err)
+	// the cache holds a single year
+	s, err := tiered.New(store, rootDir, cacheDir, io.Headersize+150)
+	require.Nil(t, err)
+	_, err = tiered.New(store, rootDir, filepath.Join(rootDir, "cache"), 0)
+	assert.NotNil(t, err)
+
+	tbi2010 := yearFile(t, rootDir, 2010, 100)
+	tbi2011 := yearFile(t, rootDir, 2011, 100)
+	binPath := tbi2010.Path
+	original, err := os.ReadFile(binPath)
+	require.Nil(t, err)
+
+	require.Nil(t, s.Offload(tbi2010))
+	require.Nil(t, s.Offload(tbi2011))
+	assert.True(t, tbi2010.IsRemote())
+	assert.NotNil(t, s.Offload(tbi2010))
+	_, err = os.Stat(binPath)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(remoteDir, "AAPL", "1D", "OHLCV", "2010.bin"))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2*len(original)), tiered.RemoteUsage(rootDir))
+
+	// the stub has the header of the year file
+	stubInfo, err := io.ReadTimeBucketInfo(tbi2010.Path)
+	require.Nil(t, err)
+	assert.Equal(t, int16(2010), stubInfo.Year)
+	assert.Equal(t, []string{"Close"}, stubInfo.GetElementNames())
+	stub, err := tiered.ReadStub(tbi2010.Path)
+	require.Nil(t, err)
+	assert.Equal(t, "AAPL/1D/OHLCV/2010.bin", stub.Key)
+	_, err = tiered.ReadStub(yearFile(t, rootDir, 2012, 0).Path)
+	assert.ErrorIs(t, err, tiered.ErrNotStub)
+
+	// the years are cached on read, and the least recently read one is evicted
+	path, err := s.Fetch(tbi2010.Path)
+	require.Nil(t, err)
+	assert.Equal(t, filepath.Join(cacheDir, "AAPL", "1D", "OHLCV", "2010.bin"), path)
+	cached, err := os.ReadFile(path)
+	require.Nil(t, err)
+	assert.Equal(t, original, cached)
+	path2011, err := s.Fetch(tbi2011.Path)
+	require.Nil(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(path2011)
+	assert.Nil(t, err)
+
+	// a corrupted object is not cached
+	require.Nil(t, os.WriteFile(filepath.Join(remoteDir, "AAPL", "1D", "OHLCV", "2010.bin"), original[1:], 0o600))
+	_, err = s.Fetch(tbi2010.Path)
+	assert.NotNil(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+	require.Nil(t, os.WriteFile(filepath.Join(remoteDir, "AAPL", "1D", "OHLCV", "2010.bin"), original, 0o600))
+
+	// a recalled year replaces its stub, and its object is deleted
+	require.Nil(t, s.Recall(tbi2010))
+	assert.Equal(t, binPath, tbi2010.Path)
+	recalled, err := os.ReadFile(binPath)
+	require.Nil(t, err)
+	assert.Equal(t, original, recalled)
+	_, err = os.Stat(filepath.Join(remoteDir, "AAPL", "1D", "OHLCV", "2010.bin"))
+	assert.True(t, os.IsNotExist(err))
+	assert.NotNil(t, s.Recall(tbi2010))
+	assert.Equal(t, int64(len(original)), tiered.RemoteUsage(rootDir))
+}
+
+func TestNewFromSetting(t *testing.T) {
+	t.Parallel()
+
+	s, err := tiered.NewFromSetting(utils.TieredStorageSetting{}, t.TempDir())
+	assert.Nil(t, err)
+	assert.Nil(t, s)
+
+	cacheDir := t.TempDir()
+	s, err = tiered.NewFromSetting(utils.TieredStorageSetting{
+		Backend:        "local",
+		LocalDirectory: t.TempDir(),
+		CacheDirectory: cacheDir,
+	}, t.TempDir())
+	require.Nil(t, err)
+	assert.Equal(t, cacheDir, s.CacheDir())
+
+	_, err = tiered.NewFromSetting(utils.TieredStorageSetting{Backend: "s3", CacheDirectory: cacheDir}, t.TempDir())
+	assert.NotNil(t, err)
+}
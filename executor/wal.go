@@ -9,6 +9,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alpacahq/marketstore/v4/executor/buffile"
@@ -38,6 +39,8 @@ type WALFileType struct {
 	walWaitGroup      *sync.WaitGroup
 	tpd               *TriggerPluginDispatcher
 	txnPipe           *TransactionPipe
+	// the WAL file is not truncated while rotationHolds is not zero, see HoldRotation
+	rotationHolds int32
 }
 
 type ReplicationSender interface {
@@ -181,6 +184,30 @@ func (wf *WALFileType) WriteCommand(rt io.EnumRecordType, tbiAbsPath string, var
 	}
 }
 
+// DeleteCommand returns a command zeroing count records of recordLen bytes from the offset.
+func (wf *WALFileType) DeleteCommand(rt io.EnumRecordType, tbiAbsPath string, varRecLen int,
+	offset, recordLen, count int64, ds []io.DataShape,
+) *wal.WriteCommand {
+	data, _ := io.Serialize(nil, recordLen)
+	data, _ = io.Serialize(data, count)
+	return wf.WriteCommand(rt, tbiAbsPath, varRecLen, offset, wal.DeleteIndex, data, ds)
+}
+
+// AlterCommand returns a command rewriting the year files of the bucket of tbiAbsPath with the data shapes dsv.
+func (wf *WALFileType) AlterCommand(rt io.EnumRecordType, tbiAbsPath string, dsv []io.DataShape,
+) (*wal.WriteCommand, error) {
+	data, err := io.DSVToBytes(dsv)
+	if err != nil {
+		return nil, err
+	}
+	return wf.WriteCommand(rt, tbiAbsPath, 0, 0, wal.AlterIndex, data, dsv), nil
+}
+
+// DropCommand returns a command removing the year file tbiAbsPath and its block index.
+func (wf *WALFileType) DropCommand(rt io.EnumRecordType, tbiAbsPath string, ds []io.DataShape) *wal.WriteCommand {
+	return wf.WriteCommand(rt, tbiAbsPath, 0, 0, wal.DropIndex, nil, ds)
+}
+
 func FullPathToWALKey(rootPath, fullPath string) (keyPath string) {
 	/*
 		NOTE: This key includes the year filename at the end of the metadata key
@@ -289,9 +316,30 @@ func (wf *WALFileType) FlushCommandsToWAL(writeCommands []*wal.WriteCommand) (er
 	}
 
 	/*
-		Write the buffers to primary files (should happen after WAL writes)
+		Write the buffers to primary files (should happen after WAL writes).
+		The heaps are written first, before the rows referencing their values.
+		The alters and the drops are applied last: the writes of the group were queued
+		before them and are formatted with the previous layout of the files.
 	*/
+	if err := writeHeaps(filepath.Dir(wf.FilePtr.Name()), writeCommands); err != nil {
+		log.Error(fmt.Sprintf("failed to write the heaps: %s", err.Error()))
+	}
+	rewrites := map[string][]wal.OffsetIndexBuffer{}
+	for keyPath, writes := range writesPerFile {
+		kept := writes[:0]
+		for _, buffer := range writes {
+			if buffer.IsAlter() || buffer.IsDrop() {
+				rewrites[keyPath] = append(rewrites[keyPath], buffer)
+			} else {
+				kept = append(kept, buffer)
+			}
+		}
+		writesPerFile[keyPath] = kept
+	}
 	for keyPath, writes := range writesPerFile {
+		if len(writes) == 0 {
+			continue
+		}
 		recordType := fileRecordTypes[keyPath]
 		varRecLen := varRecLens[keyPath]
 		if err := wf.writePrimary(keyPath, writes, recordType, varRecLen); err != nil {
@@ -299,11 +347,26 @@ func (wf *WALFileType) FlushCommandsToWAL(writeCommands []*wal.WriteCommand) (er
 			log.Error(fmt.Sprintf("failed to write data to file %s: %s", keyPath, err.Error()))
 		}
 		for i, buffer := range writes {
-			wf.tpd.AppendRecord(keyPath, trigger.Record(buffer.IndexAndPayload()))
+			if buffer.IsDelete() {
+				wf.tpd.AppendDeletion(keyPath, deletedIndexes(buffer))
+			} else {
+				wf.tpd.AppendRecord(keyPath, trigger.Record(buffer.IndexAndPayload()))
+			}
 			writes[i] = nil // for GC
 		}
 		writesPerFile[keyPath] = nil // for GC
 	}
+	for keyPath, writes := range rewrites {
+		err := wf.writePrimary(keyPath, writes, fileRecordTypes[keyPath], varRecLens[keyPath])
+		if err != nil {
+			log.Error(fmt.Sprintf("failed to alter or drop the files of %s: %s", keyPath, err.Error()))
+		}
+		for _, wc := range writeCommands {
+			if wc.Done != nil && wc.WALKeyPath == keyPath {
+				wc.Done <- err
+			}
+		}
+	}
 	return nil
 }
 
@@ -320,7 +383,11 @@ func serializeTG(tgID int64, commands []*wal.WriteCommand,
 		This loop serializes write transactions from the channel for writing to disk
 	*/
 	for i := 0; i < WTCount; i++ {
-		TG_Serialized, _ = io.Serialize(TG_Serialized, int8(commands[i].RecordType))
+		recordType := int8(commands[i].RecordType)
+		if commands[i].Heap != nil {
+			recordType |= wal.HeapFlag
+		}
+		TG_Serialized, _ = io.Serialize(TG_Serialized, recordType)
 		TG_Serialized, _ = io.Serialize(TG_Serialized, int16(len(commands[i].WALKeyPath)))
 		TG_Serialized, _ = io.Serialize(TG_Serialized, commands[i].WALKeyPath)
 		TG_Serialized, _ = io.Serialize(TG_Serialized, int32(len(commands[i].Data)))
@@ -335,6 +402,11 @@ func serializeTG(tgID int64, commands []*wal.WriteCommand,
 		if err == nil {
 			TG_Serialized = append(TG_Serialized, dsvBytes...)
 		}
+		if heap := commands[i].Heap; heap != nil {
+			TG_Serialized, _ = io.Serialize(TG_Serialized, heap.Base)
+			TG_Serialized, _ = io.Serialize(TG_Serialized, int32(len(heap.Data)))
+			TG_Serialized = append(TG_Serialized, heap.Data...)
+		}
 
 		keyPath := commands[i].WALKeyPath
 		// Store the data in a buffer for primary storage writes after WAL writes are done
@@ -359,6 +431,23 @@ func (wf *WALFileType) writePrimary(keyPath string, writes []wal.OffsetIndexBuff
 	var fp WriteAtCloser
 	rootDir := filepath.Dir(wf.FilePtr.Name())
 	fullPath := walKeyToFullPath(rootDir, keyPath)
+	if writes[0].IsAlter() || writes[0].IsDrop() {
+		for _, buffer := range writes {
+			if buffer.IsDrop() {
+				err = dropYearFile(fullPath)
+			} else {
+				err = alterBucketFiles(filepath.Dir(fullPath), buffer.AlteredDataShapes())
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err = indexWrites(fullPath, writes); err != nil {
+		log.Error("failed to update the block index of %s: %v", fullPath, err)
+		return err
+	}
 	if recordType == io.FIXED && len(writes) >= batchThreshold {
 		fp, err = buffile.New(fullPath)
 	} else {
@@ -369,13 +458,14 @@ func (wf *WALFileType) writePrimary(keyPath string, writes []wal.OffsetIndexBuff
 		log.Error("cannot open file %s for write transaction commit: %v", fullPath, err)
 		return err
 	}
-	defer fp.Close()
 
 	for _, buffer := range writes {
-		switch recordType {
-		case io.FIXED:
+		switch {
+		case buffer.IsDelete():
+			err = DeleteBufferFromFile(fp, buffer)
+		case recordType == io.FIXED:
 			err = WriteBufferToFile(fp, buffer)
-		case io.VARIABLE:
+		case recordType == io.VARIABLE:
 			err = WriteBufferToFileIndirect(
 				fp.(*os.File),
 				buffer,
@@ -383,11 +473,16 @@ func (wf *WALFileType) writePrimary(keyPath string, writes []wal.OffsetIndexBuff
 			)
 		}
 		if err != nil {
+			_ = fp.Close()
 			log.Error("failed to write committed data: %v", err)
 			return err
 		}
 	}
-	return nil
+	// the deleted records are read from the file, once it is written
+	if err = fp.Close(); err != nil {
+		return err
+	}
+	return reindexDeletes(fullPath, writes)
 }
 
 // CreateCheckpoint flushes all primary dirty pages to disk, and
@@ -503,6 +598,9 @@ func ParseTGData(tgSerialized []byte, rootPath string) (TGID int64, wtSets []wal
 		// (offset (8bytes), index(8bytes), buffer(dataLen-bytes))
 		offsetLenBytes = 8
 		indexLenBytes  = 8
+		// (base (8bytes), heapLen (4bytes), heap(heapLen-bytes)) if the record type has the HeapFlag
+		heapBaseLenBytes = 8
+		heapLenLenBytes  = 4
 	)
 	TGID = io.ToInt64(tgSerialized[0:tgIDLenBytes])
 	WTCount := io.ToInt64(tgSerialized[tgIDLenBytes : tgIDLenBytes+wtCountLenBytes])
@@ -526,6 +624,16 @@ func ParseTGData(tgSerialized []byte, rootPath string) (TGID int64, wtSets []wal
 		cursor += offsetLenBytes + indexLenBytes + dataLen
 		dataShapes, l := io.DSVFromBytes(tgSerialized[cursor:])
 		cursor += l
+		var heap *io.HeapChunk
+		if RecordType&wal.HeapFlag != 0 {
+			RecordType &^= wal.HeapFlag
+			base := io.ToInt64(tgSerialized[cursor : cursor+heapBaseLenBytes])
+			cursor += heapBaseLenBytes
+			heapLen := int(io.ToInt32(tgSerialized[cursor : cursor+heapLenLenBytes]))
+			cursor += heapLenLenBytes
+			heap = &io.HeapChunk{Base: base, Data: tgSerialized[cursor : cursor+heapLen]}
+			cursor += heapLen
+		}
 
 		wtSets[i] = wal.NewWTSet(
 			io.EnumRecordType(RecordType),
@@ -535,6 +643,7 @@ func ParseTGData(tgSerialized []byte, rootPath string) (TGID int64, wtSets []wal
 			data,
 			dataShapes,
 		)
+		wtSets[i].Heap = heap
 	}
 
 	return TGID, wtSets
@@ -658,10 +767,12 @@ func (wf *WALFileType) SyncWAL(walRefresh, primaryRefresh time.Duration, walRota
 						log.Error("[tickerCheck] failed to FlushToWAL: " + err.Error())
 					}
 				}
+			case q := <-wf.txnPipe.quiesceChannel:
+				q.done <- wf.runQuiesced(q.fn)
 			case <-tickerPrimary.C:
 				wf.CreateCheckpoint()
 				primaryFlushCounter++
-				if primaryFlushCounter%walRotateInterval == 0 {
+				if primaryFlushCounter >= walRotateInterval && atomic.LoadInt32(&wf.rotationHolds) == 0 {
 					log.Info("Truncating WAL file...")
 					wf.FilePtr.Truncate(0)
 					wf.WriteStatus(wal.OPEN, wal.NOTREPLAYED)
@@ -705,6 +816,53 @@ func (wf *WALFileType) RequestFlush() {
 	<-f
 }
 
+/*
+Quiesce runs fn between two transaction groups: the queued writes are flushed first, and no transaction group
+is written to the WAL file or to the primary files until fn returns. fn is given the ID of the last transaction
+group, all the transaction groups up to it are written to the primary files.
+*/
+func (wf *WALFileType) Quiesce(fn func(tgID int64) error) error {
+	const shutdownCheckInterval = 100 * time.Millisecond
+	if !haveWALWriter {
+		return wf.runQuiesced(fn)
+	}
+	q := quiesceRequest{fn: fn, done: make(chan error, 1)}
+	for {
+		select {
+		case wf.txnPipe.quiesceChannel <- q:
+			return <-q.done
+		case <-time.After(shutdownCheckInterval):
+			if *wf.shutdownPending {
+				return errors.New("the WAL writer is shut down")
+			}
+		}
+	}
+}
+
+/*
+flushQueued flushes the writes queued so far to the WAL file and to the primary files. Unlike RequestFlush, it
+does not return early when another flush is pending, it waits for the transaction group of the queued writes.
+*/
+func (wf *WALFileType) flushQueued() error {
+	return wf.Quiesce(func(int64) error { return nil })
+}
+
+func (wf *WALFileType) runQuiesced(fn func(tgID int64) error) error {
+	if err := wf.FlushToWAL(); err != nil {
+		return fmt.Errorf("flush the queued writes: %w", err)
+	}
+	return fn(wf.txnPipe.TGID() - 1)
+}
+
+/*
+HoldRotation prevents the WAL file from being truncated until the returned function is called, so that the
+WAL file keeps all the transaction groups written in between.
+*/
+func (wf *WALFileType) HoldRotation() (release func()) {
+	atomic.AddInt32(&wf.rotationHolds, 1)
+	return func() { atomic.AddInt32(&wf.rotationHolds, -1) }
+}
+
 // FinishAndWait closes the writtenIndexes channel, and waits
 // for the remaining triggers to fire, returning.
 func (wf *WALFileType) FinishAndWait() {
@@ -23,8 +23,43 @@ type WriteCommand struct {
 	Data []byte
 	// DataShapes with Epoch column
 	DataShapes []io.DataShape
+	// Heap holds the values of the STRING and BLOB columns of the rows of Data, which reference them.
+	// It is written to the heap file of the year file before the rows, see io.HeapPathOf.
+	Heap *io.HeapChunk
+	// Done, when set on an alter or a drop command, receives its result once the year files are rewritten
+	// or removed.
+	// It is not serialized.
+	Done chan<- error
 }
 
+/*
+DeleteIndex is the Index of the WriteCommands that delete records instead of
+writing them. Their Data holds the length and the number of the records to zero
+from the Offset, see OffsetIndexBuffer.DeletedRecords.
+*/
+const DeleteIndex = -1
+
+/*
+AlterIndex is the Index of the WriteCommands that change the data shapes of a
+bucket. Their Data holds the new data shapes with the Epoch column (see
+io.DSVToBytes) and their WALKeyPath is one of the year files of the bucket, all
+of which are rewritten with the new layout.
+*/
+const AlterIndex = -2
+
+/*
+DropIndex is the Index of the WriteCommands that remove a year file with its
+block index, e.g. once its records are older than the retention period of the
+bucket. Their WALKeyPath is the year file, they have no Data.
+*/
+const DropIndex = -3
+
+/*
+HeapFlag is set on the record type of the serialized write commands that have a Heap, which follows their
+data shapes (see ParseTGData).
+*/
+const HeapFlag = 0x40
+
 // Convert WriteCommand to string for debuging/presentation.
 func (wc *WriteCommand) String() string {
 	return fmt.Sprintf("WC[%v] WALKeyPath:%s (len:%d, off:%d, idx:%d, dsize:%d)",
@@ -27,3 +27,30 @@ func (b OffsetIndexBuffer) IndexAndPayload() []byte {
 func (b OffsetIndexBuffer) Payload() []byte {
 	return b[16:]
 }
+
+// IsDelete returns true if the buffer deletes records instead of writing them.
+func (b OffsetIndexBuffer) IsDelete() bool {
+	return b.Index() == DeleteIndex
+}
+
+// DeletedRecords returns the length and the number of the records zeroed from the Offset by a delete buffer.
+func (b OffsetIndexBuffer) DeletedRecords() (recordLen, count int64) {
+	payload := b.Payload()
+	return io.ToInt64(payload[:8]), io.ToInt64(payload[8:16])
+}
+
+// IsAlter returns true if the buffer changes the data shapes of a bucket.
+func (b OffsetIndexBuffer) IsAlter() bool {
+	return b.Index() == AlterIndex
+}
+
+// AlteredDataShapes returns the new data shapes, with Epoch, of the bucket changed by an alter buffer.
+func (b OffsetIndexBuffer) AlteredDataShapes() []io.DataShape {
+	dsv, _ := io.DSVFromBytes(b.Payload())
+	return dsv
+}
+
+// IsDrop returns true if the buffer removes a year file.
+func (b OffsetIndexBuffer) IsDrop() bool {
+	return b.Index() == DropIndex
+}
@@ -18,6 +18,8 @@ type WTSet struct {
 	Buffer OffsetIndexBuffer
 	// Data Shape with Epoch Column
 	DataShapes []io.DataShape
+	// Heap holds the values of the STRING and BLOB columns of the rows of the Buffer, if any
+	Heap *io.HeapChunk
 }
 
 func NewWTSet(recordType io.EnumRecordType, filePath string, dataLen, varRecLen int,
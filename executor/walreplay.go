@@ -1,8 +1,10 @@
 package executor
 
 import (
+	"errors"
 	"fmt"
 	goio "io"
+	"os"
 	"path/filepath"
 	"sort"
 
@@ -165,7 +167,20 @@ func (wf *WALFileType) replayTGData(tgID int64, wtSets []wal.WTSet) (err error)
 	if len(wtSets) == 0 {
 		return nil
 	}
+	if err = applyTGData(wtSets); err != nil {
+		return err
+	}
+	wf.lastCommittedTGID = tgID
+	err = wf.CreateCheckpoint()
+	if err != nil {
+		return fmt.Errorf("create checkpoint of wal:%w", err)
+	}
 
+	return nil
+}
+
+// applyTGData writes the write transaction sets of a transaction group to the primary files.
+func applyTGData(wtSets []wal.WTSet) (err error) {
 	cfp := NewCachedFP() // Cached open file pointer
 	defer func() {
 		err2 := cfp.Close()
@@ -174,8 +189,59 @@ func (wf *WALFileType) replayTGData(tgID int64, wtSets []wal.WTSet) (err error)
 		}
 	}()
 
+	// the heap files of the year files, written before the rows that refer to them
+	heaps := heapFiles{}
+	defer func() {
+		if err2 := heaps.Close(); err2 != nil {
+			log.Error(fmt.Sprintf("failed to close the heap files: %v", err2))
+		}
+	}()
+
+	// whether the layout of each file matches the one of the write transactions
+	layoutMatches := map[string]bool{}
+	// the replayed writes of each file, for its block index
+	replayed := map[string][]wal.OffsetIndexBuffer{}
 	for _, wtSet := range wtSets {
+		if wtSet.Buffer.IsAlter() || wtSet.Buffer.IsDrop() {
+			// the files are rewritten or removed, so the cached one is closed before
+			if err = cfp.Close(); err != nil {
+				return err
+			}
+			cfp = NewCachedFP()
+			if err = heaps.Close(); err != nil {
+				return err
+			}
+			layoutMatches = map[string]bool{}
+			if err = reindexReplayed(replayed); err != nil {
+				return err
+			}
+			replayed = map[string][]wal.OffsetIndexBuffer{}
+			if wtSet.Buffer.IsDrop() {
+				err = dropYearFile(wtSet.FilePath)
+			} else {
+				err = alterBucketFiles(filepath.Dir(wtSet.FilePath), wtSet.Buffer.AlteredDataShapes())
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		matches, ok := layoutMatches[wtSet.FilePath]
+		if !ok {
+			matches = hasLayout(wtSet.FilePath, wtSet.DataShapes)
+			layoutMatches[wtSet.FilePath] = matches
+		}
+		if !matches {
+			// written before an alter that is already applied to the file
+			log.Info("skipping a write transaction of %s made with a previous layout", wtSet.FilePath)
+			continue
+		}
 		fp, err2 := cfp.GetFP(wtSet.FilePath)
+		if errors.Is(err2, os.ErrNotExist) {
+			// the year file is removed by a drop that follows in the WAL
+			log.Warn("skipping a write transaction of %s, the year file does not exist", wtSet.FilePath)
+			continue
+		}
 		if err2 != nil {
 			return wal.ReplayError{
 				Msg: fmt.Sprintf("failed to open a filepath %s in write transaction set:%v",
@@ -184,12 +250,21 @@ func (wf *WALFileType) replayTGData(tgID int64, wtSets []wal.WTSet) (err error)
 				Cont: true,
 			}
 		}
-		switch wtSet.RecordType {
-		case io.FIXED:
+		if wtSet.Heap != nil {
+			if err = heaps.write(wtSet.FilePath, wtSet.Heap); err != nil {
+				return err
+			}
+		}
+		switch {
+		case wtSet.Buffer.IsDelete():
+			if err = DeleteBufferFromFile(fp, wtSet.Buffer); err != nil {
+				return err
+			}
+		case wtSet.RecordType == io.FIXED:
 			if err = WriteBufferToFile(fp, wtSet.Buffer); err != nil {
 				return err
 			}
-		case io.VARIABLE:
+		case wtSet.RecordType == io.VARIABLE:
 			// Find the record length - we need it to use the time column as a sort key later
 			if err = WriteBufferToFileIndirect(fp,
 				wtSet.Buffer,
@@ -200,16 +275,37 @@ func (wf *WALFileType) replayTGData(tgID int64, wtSets []wal.WTSet) (err error)
 		default:
 			return fmt.Errorf("record Type is incorrect from WALFile, may be invalid/outdated WAL file")
 		}
+		replayed[wtSet.FilePath] = append(replayed[wtSet.FilePath], wtSet.Buffer)
 	}
-	wf.lastCommittedTGID = tgID
-	err = wf.CreateCheckpoint()
-	if err != nil {
-		return fmt.Errorf("create checkpoint of wal:%w", err)
-	}
+	return reindexReplayed(replayed)
+}
 
+// reindexReplayed updates the block indexes of the files written by a replay.
+func reindexReplayed(replayed map[string][]wal.OffsetIndexBuffer) error {
+	for path, writes := range replayed {
+		if err := indexWrites(path, writes); err != nil {
+			return err
+		}
+		if err := reindexDeletes(path, writes); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// hasLayout returns true if the file at path has the data shapes dsvWithEpoch, or if they are unknown.
+func hasLayout(path string, dsvWithEpoch []io.DataShape) bool {
+	if len(dsvWithEpoch) == 0 {
+		return true
+	}
+	tbi, err := io.ReadTimeBucketInfo(path)
+	if err != nil {
+		// the error is reported when the file is opened
+		return true
+	}
+	return io.DataShapesEqual(tbi.GetDataShapesWithEpoch(), dsvWithEpoch)
+}
+
 // fullRead checks an error to see if we have read only partial data.
 func fullRead(err error) bool {
 	if err == nil {
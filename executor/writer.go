@@ -13,6 +13,7 @@ import (
 	"github.com/alpacahq/marketstore/v4/catalog"
 	"github.com/alpacahq/marketstore/v4/executor/wal"
 	"github.com/alpacahq/marketstore/v4/metrics"
+	"github.com/alpacahq/marketstore/v4/planner"
 	"github.com/alpacahq/marketstore/v4/utils"
 	"github.com/alpacahq/marketstore/v4/utils/io"
 	"github.com/alpacahq/marketstore/v4/utils/log"
@@ -64,6 +65,14 @@ func formatRecord(buf, row []byte, t time.Time, index, intervalsPerDay int64, is
 // to the file regardless if it satisfies the on-disk data shape, possible corrupting
 // the data files. It is recommended to call WriteCSM() for any writes as it is safer.
 func (w *Writer) WriteRecords(ts []time.Time, data []byte, dsWithEpoch []io.DataShape, tbi *io.TimeBucketInfo) error {
+	return w.writeRecords(ts, data, dsWithEpoch, tbi, nil)
+}
+
+// writeRecords is WriteRecords with the values of the heap columns of the records, which are stored in the
+// heaps of the write commands (see storeHeapValues).
+func (w *Writer) writeRecords(ts []time.Time, data []byte, dsWithEpoch []io.DataShape, tbi *io.TimeBucketInfo,
+	heapCols []io.HeapColumnValues,
+) error {
 	/*
 		[]data contains a number of records, each including the epoch in the first 8 bytes
 	*/
@@ -76,6 +85,8 @@ func (w *Writer) WriteRecords(ts []time.Time, data []byte, dsWithEpoch []io.Data
 		prevIndex int64
 		prevYear  int16
 		cc        *wal.WriteCommand
+		ccPath    string
+		ccRows    []int // the records in cc
 		outBuf    []byte
 		rowLen    = len(data) / numRows
 		err       error
@@ -83,6 +94,19 @@ func (w *Writer) WriteRecords(ts []time.Time, data []byte, dsWithEpoch []io.Data
 
 	vrl := tbi.GetVariableRecordLength()
 	rt := tbi.GetRecordType()
+	cmdRecordLen := rowLen - epochLenBytes
+	if rt == io.VARIABLE {
+		cmdRecordLen += intervalTicksLenBytes
+	}
+	queue := func() error {
+		if heapCols != nil {
+			if err2 := storeHeapValues(cc, ccPath, ccRows, heapCols, cmdRecordLen); err2 != nil {
+				return err2
+			}
+		}
+		w.walFile.QueueWriteCommand(cc)
+		return nil
+	}
 	for i := 0; i < numRows; i++ {
 		pos := i * rowLen
 		record := data[pos : pos+rowLen]
@@ -90,11 +114,14 @@ func (w *Writer) WriteRecords(ts []time.Time, data []byte, dsWithEpoch []io.Data
 		year := int16(t.Year())
 		if year != tbi.Year {
 			// add a new year's file
-			tbi, err = w.rootCatDir.GetSubDirectoryAndAddFile(tbi.Path, year)
+			tbi, err = w.addYearFile(tbi, year)
 			if err != nil {
 				return fmt.Errorf("add new year file. tbi=%v, err: %w", tbi, err)
 			}
 		}
+		if err = readOnlyYear(tbi); err != nil {
+			return err
+		}
 		index := io.TimeToIndex(t, tbi.GetTimeframe())
 		offset := io.IndexToOffset(index, tbi.GetRecordLength())
 
@@ -104,6 +131,7 @@ func (w *Writer) WriteRecords(ts []time.Time, data []byte, dsWithEpoch []io.Data
 			prevYear = year
 			outBuf = formatRecord([]byte{}, record, t, index, tbi.GetIntervals(), tbi.GetRecordType() == io.VARIABLE)
 			cc = w.walFile.WriteCommand(rt, tbi.Path, int(vrl), offset, index, outBuf, dsWithEpoch)
+			ccPath, ccRows = tbi.Path, []int{i}
 			continue
 		}
 		// Because index is relative time from the beginning of the year,
@@ -115,26 +143,33 @@ func (w *Writer) WriteRecords(ts []time.Time, data []byte, dsWithEpoch []io.Data
 			*/
 			outBuf = formatRecord(outBuf, record, t, index, tbi.GetIntervals(), tbi.GetRecordType() == io.VARIABLE)
 			cc.Data = outBuf
+			if rt == io.VARIABLE {
+				ccRows = append(ccRows, i)
+			} else {
+				// a fixed record replaces the previous one at its index
+				ccRows = []int{i}
+			}
 			continue
 		}
 		if index != prevIndex || year != prevYear {
 			/*
 				This row is at a new index, output previous output buffer
 			*/
-			w.walFile.QueueWriteCommand(cc)
+			if err = queue(); err != nil {
+				return err
+			}
 			// Setup next command
 			prevIndex = index
 			outBuf = formatRecord([]byte{}, record, t, index, tbi.GetIntervals(), tbi.GetRecordType() == io.VARIABLE)
 			cc = w.walFile.WriteCommand(
 				tbi.GetRecordType(), tbi.Path, int(tbi.GetVariableRecordLength()), offset, index,
 				outBuf, dsWithEpoch)
+			ccPath, ccRows = tbi.Path, []int{i}
 		}
 	}
 
 	// output to WAL
-	w.walFile.QueueWriteCommand(cc)
-
-	return nil
+	return queue()
 }
 
 func appendIntervalTicks(buf []byte, t time.Time, index, intervalsPerDay int64) (outBuf []byte) {
@@ -151,6 +186,24 @@ func WriteBufferToFile(fp stdio.WriterAt, buffer wal.OffsetIndexBuffer) error {
 	return err
 }
 
+// DeleteBufferFromFile zeroes the records deleted by a delete buffer.
+func DeleteBufferFromFile(fp stdio.WriterAt, buffer wal.OffsetIndexBuffer) error {
+	recordLen, count := buffer.DeletedRecords()
+	_, err := fp.WriteAt(make([]byte, recordLen*count), buffer.Offset())
+	return err
+}
+
+// deletedIndexes returns the indexes of the records deleted by a delete buffer.
+func deletedIndexes(buffer wal.OffsetIndexBuffer) []int64 {
+	recordLen, count := buffer.DeletedRecords()
+	first := (buffer.Offset()-io.Headersize)/recordLen + 1
+	indexes := make([]int64, count)
+	for i := range indexes {
+		indexes[i] = first + int64(i)
+	}
+	return indexes
+}
+
 type IndirectRecordInfo struct {
 	Index, Offset, Len int64
 }
@@ -245,6 +298,51 @@ func WriteBufferToFileIndirect(fp *os.File, buffer wal.OffsetIndexBuffer, varRec
 // In order to improve testability, use this function instead of the static WriteCSM function.
 func (w *Writer) WriteCSM(csm io.ColumnSeriesMap, isVariableLength bool) error {
 	start := time.Now()
+	bucketLayout.RLock()
+	defer bucketLayout.RUnlock()
+	if err := w.queueCSM(csm, isVariableLength); err != nil {
+		return err
+	}
+
+	w.walFile.RequestFlush()
+	metrics.WriteCSMDuration.Observe(time.Since(start).Seconds())
+	return nil
+}
+
+// WriteBatch is a ColumnSeriesMap written in a transaction group by WriteGroup.
+type WriteBatch struct {
+	CSM              io.ColumnSeriesMap
+	IsVariableLength bool
+}
+
+/*
+WriteGroup writes the batches together, and returns once they are committed to the WAL file with the ID of
+the last transaction group: the writes queued before the flush are committed by it or by an earlier one. The
+error of each batch is returned in errs, the buckets of a failed batch written before its error stay written
+like with WriteCSM. err is the error of the flush, none of the batches may be committed then.
+*/
+func (w *Writer) WriteGroup(batches []WriteBatch) (tgID int64, errs []error, err error) {
+	start := time.Now()
+	bucketLayout.RLock()
+	defer bucketLayout.RUnlock()
+	errs = make([]error, len(batches))
+	for i, b := range batches {
+		errs[i] = w.queueCSM(b.CSM, b.IsVariableLength)
+	}
+
+	err = w.walFile.Quiesce(func(id int64) error {
+		tgID = id
+		return nil
+	})
+	if err != nil {
+		return 0, errs, fmt.Errorf("commit the transaction group: %w", err)
+	}
+	metrics.WriteCSMDuration.Observe(time.Since(start).Seconds())
+	return tgID, errs, nil
+}
+
+// queueCSM queues the write commands of the ColumnSeriesMap to the WAL, the bucketLayout lock must be held.
+func (w *Writer) queueCSM(csm io.ColumnSeriesMap, isVariableLength bool) error {
 	for tbk, cs := range csm {
 		tf, err := tbk.GetTimeFrame()
 		if err != nil {
@@ -286,12 +384,16 @@ func (w *Writer) WriteCSM(csm io.ColumnSeriesMap, isVariableLength bool) error {
 				continue
 			}
 
+			dsv, err2 := cs.BucketDataShapes()
+			if err2 != nil {
+				return fmt.Errorf("create bucket %s: %w", tbk.GetItemKey(), err2)
+			}
 			year := int16(t[0].Year())
 			tbi = io.NewTimeBucketInfo(
 				*tf,
 				tbk.GetPathToYearFiles(w.rootCatDir.GetPath()),
 				"Created By Writer", year,
-				cs.GetDataShapes(), recordType)
+				dsv, recordType)
 
 			/*
 				Verify there is an available TimeBucket for the destination
@@ -306,6 +408,14 @@ func (w *Writer) WriteCSM(csm io.ColumnSeriesMap, isVariableLength bool) error {
 		// Check if the previously-written data schema matches the input
 		columnMismatchError := "unable to match data columns (%v) to bucket columns (%v)"
 		dbDSV := tbi.GetDataShapesWithEpoch()
+		if io.IsNullable(dbDSV) {
+			err = cs.PackNulls(dbDSV)
+		} else {
+			err = cs.RemoveNullColumns()
+		}
+		if err != nil {
+			return fmt.Errorf("write %s: %w", tbk.GetItemKey(), err)
+		}
 		csDSV := cs.GetDataShapes()
 		if len(dbDSV) != len(csDSV) {
 			return fmt.Errorf(columnMismatchError, csDSV, dbDSV)
@@ -319,26 +429,66 @@ func (w *Writer) WriteCSM(csm io.ColumnSeriesMap, isVariableLength bool) error {
 		}
 
 		for _, dbDS := range coercion {
-			if err2 := cs.CoerceColumnType(dbDS.Name, dbDS.Type); err2 != nil {
+			if err2 := cs.CoerceColumn(dbDS); err2 != nil {
 				csType := io.GetElementType(cs.GetColumn(dbDS.Name))
 				log.Error("[%s] error coercing %s from %s to %s", tbk.GetItemKey(), dbDS.Name, csType.String(), dbDS.Type.String())
 				return err2
 			}
 		}
 
+		// the values of the STRING and BLOB columns are written to the heaps, the rows reference them
+		heapCols := cs.TakeHeapColumns()
 		rs, err := cs.ToRowSeries(tbk, alignData)
 		if err != nil {
 			return fmt.Errorf("convert column series to row series. tbk=%s: %w", tbk, err)
 		}
 		rowData := rs.GetData()
-		err = w.WriteRecords(times, rowData, dbDSV, tbi)
+		err = w.writeRecords(times, rowData, dbDSV, tbi, heapCols)
 		if err != nil {
 			return fmt.Errorf("write records to %v: %w", tbi, err)
 		}
 	}
+	return nil
+}
 
-	w.walFile.RequestFlush()
-	metrics.WriteCSMDuration.Observe(time.Since(start).Seconds())
+// Delete removes the rows between start and end, both included, from the buckets
+// matching the key, whose items can be glob patterns or comma separated lists
+// (e.g. "*/1Min/OHLCV"). Like the writes, the deletion goes through the WAL, so it
+// is replicated and reported to the triggers.
+func (w *Writer) Delete(tbk *io.TimeBucketKey, start, end time.Time) error {
+	if start.After(end) {
+		return fmt.Errorf("start %v of the deletion is after its end %v", start, end)
+	}
+	bucketLayout.RLock()
+	defer bucketLayout.RUnlock()
+	keys := catalog.MatchTimeBucketKeys(w.rootCatDir, tbk)
+	if len(keys) == 0 {
+		return fmt.Errorf("no bucket matches %s", tbk.GetItemKey())
+	}
+
+	// Rows queued for writing before the deletion are deleted too
+	if err := w.walFile.flushQueued(); err != nil {
+		return fmt.Errorf("flush the writes queued before the deletion: %w", err)
+	}
+	for _, key := range keys {
+		q := planner.NewQuery(w.rootCatDir)
+		q.AddTargetKey(key)
+		q.SetRange(start, end)
+		pr, err := q.Parse()
+		if err != nil {
+			return fmt.Errorf("plan the deletion of %s: %w", key.GetItemKey(), err)
+		}
+		de, err := newDeleter(pr, w.walFile)
+		if err != nil {
+			return fmt.Errorf("plan the deletion of %s: %w", key.GetItemKey(), err)
+		}
+		if err = de.queue(); err != nil {
+			return fmt.Errorf("delete rows of %s: %w", key.GetItemKey(), err)
+		}
+	}
+	if err := w.walFile.flushQueued(); err != nil {
+		return fmt.Errorf("flush the deletion: %w", err)
+	}
 	return nil
 }
 
@@ -355,3 +505,13 @@ func WriteCSM(csm io.ColumnSeriesMap, isVariableLength bool) (err error) {
 
 	return writer.WriteCSM(csm, isVariableLength)
 }
+
+// Delete removes the rows of the time range from the buckets matching the key, see Writer.Delete.
+func Delete(tbk *io.TimeBucketKey, start, end time.Time) error {
+	writer, err := NewWriter(ThisInstance.CatalogDir, ThisInstance.WALFile)
+	if err != nil {
+		return err
+	}
+
+	return writer.Delete(tbk, start, end)
+}
@@ -2,6 +2,7 @@ package executor
 
 import (
 	"errors"
+	"time"
 
 	"github.com/alpacahq/marketstore/v4/utils/io"
 )
@@ -11,3 +12,24 @@ type ErrorWriter struct{}
 func (w *ErrorWriter) WriteCSM(csm io.ColumnSeriesMap, isVariableLength bool) error {
 	return errors.New("write is not allowed on replica")
 }
+
+func (w *ErrorWriter) WriteGroup(batches []WriteBatch) (tgID int64, errs []error, err error) {
+	return 0, nil, errors.New("write is not allowed on replica")
+}
+
+func (w *ErrorWriter) Delete(tbk *io.TimeBucketKey, start, end time.Time) error {
+	return errors.New("delete is not allowed on replica")
+}
+
+func (w *ErrorWriter) AlterBucket(tbk *io.TimeBucketKey, changes ColumnChanges) error {
+	return errors.New("alter is not allowed on replica")
+}
+
+func (w *ErrorWriter) Backup(dest string) (*BackupManifest, error) {
+	return nil, errors.New("backup is not supported on replica")
+}
+
+func (w *ErrorWriter) Compact(pattern *io.TimeBucketKey, year int16, minDeadRatio float64,
+) ([]CompactionResult, error) {
+	return nil, errors.New("compact is not supported on replica")
+}
@@ -12,6 +12,7 @@ type TriggerPluginDispatcher struct {
 	c               chan writtenRecords
 	done            chan struct{}
 	m               map[string][]trigger.Record
+	deleted         map[string][]int64
 	triggerMatchers []*trigger.TriggerMatcher
 	triggerWg       *sync.WaitGroup
 }
@@ -19,6 +20,7 @@ type TriggerPluginDispatcher struct {
 type writtenRecords struct {
 	key     string
 	records []trigger.Record
+	deleted []int64 // The indexes of the deleted records
 }
 
 func NewTriggerPluginDispatcher(triggerMatchers []*trigger.TriggerMatcher) *TriggerPluginDispatcher {
@@ -39,10 +41,17 @@ func (tpd *TriggerPluginDispatcher) run() {
 
 	for wr := range tpd.c {
 		for _, tmatcher := range tpd.triggerMatchers {
-			if tmatcher.Match(wr.key) {
+			if !tmatcher.Match(wr.key) {
+				continue
+			}
+			if len(wr.records) != 0 {
 				tpd.triggerWg.Add(1)
 				go tpd.fire(tmatcher.Trigger, wr.key, wr.records)
 			}
+			if dt, ok := tmatcher.Trigger.(trigger.DeleteTrigger); ok && len(wr.deleted) != 0 {
+				tpd.triggerWg.Add(1)
+				go tpd.fireDelete(dt, wr.key, wr.deleted)
+			}
 		}
 	}
 }
@@ -56,14 +65,29 @@ func (tpd *TriggerPluginDispatcher) AppendRecord(keyPath string, record []byte)
 	tpd.m[keyPath] = append(tpd.m[keyPath], record)
 }
 
+// AppendDeletion collects the indexes of the records deleted from a file.
+func (tpd *TriggerPluginDispatcher) AppendDeletion(keyPath string, indexes []int64) {
+	if tpd.deleted == nil {
+		tpd.deleted = make(map[string][]int64)
+	}
+
+	tpd.deleted[keyPath] = append(tpd.deleted[keyPath], indexes...)
+}
+
 // DispatchRecords iterates over the registered triggers and fire the event
 // if the file path matches the condition.  This is meant to be
 // run in a separate goroutine and recovers from panics in the triggers.
 func (tpd *TriggerPluginDispatcher) DispatchRecords() {
 	for key, records := range tpd.m {
-		tpd.c <- writtenRecords{key: key, records: records}
+		tpd.c <- writtenRecords{key: key, records: records, deleted: tpd.deleted[key]}
+	}
+	for key, indexes := range tpd.deleted {
+		if _, ok := tpd.m[key]; !ok {
+			tpd.c <- writtenRecords{key: key, deleted: indexes}
+		}
 	}
-	tpd.m = nil // for GC
+	tpd.m = nil       // for GC
+	tpd.deleted = nil // for GC
 }
 
 func (tpd *TriggerPluginDispatcher) fire(trig trigger.Trigger, key string, records []trigger.Record) {
@@ -75,3 +99,13 @@ func (tpd *TriggerPluginDispatcher) fire(trig trigger.Trigger, key string, recor
 	}()
 	trig.Fire(key, records)
 }
+
+func (tpd *TriggerPluginDispatcher) fireDelete(trig trigger.DeleteTrigger, key string, indexes []int64) {
+	defer func() {
+		tpd.triggerWg.Done()
+		if r := recover(); r != nil {
+			log.Error("recovering from %v\n%s", r, string(debug.Stack()))
+		}
+	}()
+	trig.FireDelete(key, indexes)
+}
@@ -0,0 +1,130 @@
+package executor
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+
+	"github.com/klauspost/compress/snappy"
+
+	"github.com/alpacahq/marketstore/v4/utils"
+	. "github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+readYearFile calls fn for each interval written in a year file, in time order, with the index of
+the interval and its rows: the fields of the record in a FIXED bucket, and the uncompressed rows with
+their interval ticks in a VARIABLE bucket.
+*/
+func readYearFile(f *os.File, tbi *TimeBucketInfo, fn func(index int64, rows []byte) error) error {
+	recordLen := int64(tbi.GetRecordLength())
+	end := FileSize(tbi.GetTimeframe(), int(tbi.Year), int(recordLen))
+	return readYearFileRange(f, tbi, 1, (end-Headersize)/recordLen, fn)
+}
+
+// readYearFileRange is readYearFile for the intervals from the first to the last index.
+func readYearFileRange(f *os.File, tbi *TimeBucketInfo, first, last int64,
+	fn func(index int64, rows []byte) error,
+) error {
+	recordLen := int64(tbi.GetRecordLength())
+	fieldsLen := int64(0)
+	for _, typ := range tbi.GetElementTypes() {
+		fieldsLen += int64(typ.Size())
+	}
+	end := IndexToOffset(last+1, int32(recordLen))
+	buffer := make([]byte, recordsPerRead*recordLen)
+	for pos := IndexToOffset(first, int32(recordLen)); pos < end; pos += int64(len(buffer)) {
+		if end-pos < int64(len(buffer)) {
+			buffer = buffer[:end-pos]
+		}
+		if _, err := f.ReadAt(buffer, pos); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		for rec := buffer; int64(len(rec)) >= recordLen; rec = rec[recordLen:] {
+			index := ToInt64(rec)
+			if index == 0 {
+				continue
+			}
+			if tbi.GetRecordType() != VARIABLE {
+				if err := fn(index, rec[epochLenBytes:epochLenBytes+fieldsLen]); err != nil {
+					return err
+				}
+				continue
+			}
+			data := make([]byte, ToInt64(rec[16:]))
+			if _, err := f.ReadAt(data, ToInt64(rec[8:])); err != nil {
+				return err
+			}
+			if !utils.InstanceConfig.DisableVariableCompression {
+				var err error
+				if data, err = snappy.Decode(nil, data); err != nil {
+					return err
+				}
+			}
+			if err := fn(index, data); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// yearFileWriter writes the intervals of a new year file, in the layout read by readYearFile.
+type yearFileWriter struct {
+	f          *os.File
+	tbi        *TimeBucketInfo
+	record     []byte
+	dataOffset int64
+}
+
+func createYearFile(path string, tbi *TimeBucketInfo) (*yearFileWriter, error) {
+	const ownerReadWrite = 0o600
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, ownerReadWrite)
+	if err != nil {
+		return nil, err
+	}
+	dataOffset := FileSize(tbi.GetTimeframe(), int(tbi.Year), int(tbi.GetRecordLength()))
+	if err = WriteHeader(f, tbi); err == nil {
+		err = f.Truncate(dataOffset)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return &yearFileWriter{
+		f:          f,
+		tbi:        tbi,
+		record:     make([]byte, tbi.GetRecordLength()),
+		dataOffset: dataOffset,
+	}, nil
+}
+
+func (w *yearFileWriter) write(index int64, rows []byte) error {
+	binary.LittleEndian.PutUint64(w.record, uint64(index))
+	if w.tbi.GetRecordType() != VARIABLE {
+		copy(w.record[epochLenBytes:], rows)
+	} else {
+		data := rows
+		if !utils.InstanceConfig.DisableVariableCompression {
+			data = snappy.Encode(nil, rows)
+		}
+		if _, err := w.f.WriteAt(data, w.dataOffset); err != nil {
+			return err
+		}
+		binary.LittleEndian.PutUint64(w.record[8:], uint64(w.dataOffset))
+		binary.LittleEndian.PutUint64(w.record[16:], uint64(len(data)))
+		w.dataOffset += int64(len(data))
+	}
+	_, err := w.f.WriteAt(w.record, IndexToOffset(index, w.tbi.GetRecordLength()))
+	return err
+}
+
+// Close syncs and closes the year file.
+func (w *yearFileWriter) Close() error {
+	if err := w.f.Sync(); err != nil {
+		_ = w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
@@ -0,0 +1,73 @@
+package frontend
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alpacahq/marketstore/v4/utils/arrow"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// FormatArrow is the format of the queries returning an Arrow IPC stream per time bucket key.
+const FormatArrow = "arrow"
+
+func checkFormat(format string) error {
+	if format != "" && format != FormatArrow {
+		return fmt.Errorf("unsupported query format %q", format)
+	}
+	return nil
+}
+
+// toArrowStreams returns an Arrow IPC stream per time bucket key of the result, sorted by key.
+func toArrowStreams(csm io.ColumnSeriesMap) ([][]byte, error) {
+	keys := csm.GetMetadataKeys()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	streams := make([][]byte, 0, len(keys))
+	for _, tbk := range keys {
+		stream, err := arrow.MarshalStream(tbk, csm[tbk])
+		if err != nil {
+			return nil, fmt.Errorf("encode %s in arrow: %w", tbk.String(), err)
+		}
+		streams = append(streams, stream)
+	}
+	return streams, nil
+}
+
+// fromArrowStreams returns the series of Arrow IPC streams, which must each have a time bucket key.
+func fromArrowStreams(streams [][]byte) (io.ColumnSeriesMap, error) {
+	csm := io.NewColumnSeriesMap()
+	for _, stream := range streams {
+		tbk, cs, err := arrow.UnmarshalStream(stream)
+		if err != nil {
+			return nil, err
+		}
+		if tbk == nil {
+			return nil, fmt.Errorf("arrow stream without the %s schema metadata", arrow.KeyMetadata)
+		}
+		if _, ok := csm[*tbk]; ok {
+			return nil, fmt.Errorf("more than one arrow stream for %s", tbk.String())
+		}
+		csm[*tbk] = cs
+	}
+	return csm, nil
+}
+
+// toNumpyMultiDataset returns the NumpyMultiDataset of a result, nil if it has no time bucket key.
+func toNumpyMultiDataset(csm io.ColumnSeriesMap) (*io.NumpyMultiDataset, error) {
+	var nmds *io.NumpyMultiDataset
+	for tbk, cs := range csm {
+		nds, err := io.NewNumpyDataset(cs)
+		if err != nil {
+			return nil, err
+		}
+		if nmds == nil {
+			nmds, err = io.NewNumpyMultiDataset(nds, tbk)
+			if err != nil {
+				return nil, err
+			}
+		} else if err = nmds.Append(cs, tbk); err != nil {
+			return nil, fmt.Errorf("add tbk=%s to NumpyMultiDataSet: %w", tbk.String(), err)
+		}
+	}
+	return nmds, nil
+}
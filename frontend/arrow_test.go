@@ -0,0 +1,95 @@
+package frontend_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/sqlparser"
+	"github.com/alpacahq/marketstore/v4/utils/arrow"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+func TestQueryArrow(t *testing.T) {
+	tearDown, rootDir, metadata, writer, q := setup(t, "TestQueryArrow")
+	defer tearDown()
+
+	service := frontend.NewDataService(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer, q)
+	service.Init()
+
+	query := func(format string) *frontend.MultiQueryResponse {
+		req := frontend.NewQueryRequestBuilder("USDJPY,EURUSD/1Min/OHLC").LimitRecordCount(50).End()
+		req.Format = format
+		var resp frontend.MultiQueryResponse
+		require.Nil(t, service.Query(nil, &frontend.MultiQueryRequest{Requests: []frontend.QueryRequest{req}}, &resp))
+		return &resp
+	}
+
+	// The arrow result has the rows of the numpy result, in a stream per bucket
+	numpy, err := query("").ToColumnSeriesMap()
+	require.Nil(t, err)
+	resp := query(frontend.FormatArrow)
+	assert.Nil(t, resp.Responses[0].Result)
+	require.Len(t, resp.Responses[0].Arrow, 2)
+	tbk, cs, err := arrow.UnmarshalStream(resp.Responses[0].Arrow[0])
+	require.Nil(t, err)
+	assert.Equal(t, "EURUSD/1Min/OHLC", tbk.GetItemKey())
+	assert.Equal(t, 50, cs.Len())
+
+	csm, err := resp.ToColumnSeriesMap()
+	require.Nil(t, err)
+	require.Len(t, *csm, 2)
+	for key, cs := range *numpy {
+		for _, name := range cs.GetColumnNames() {
+			assert.Equal(t, cs.GetColumn(name), (*csm)[key].GetColumn(name), name)
+		}
+	}
+
+	// An unknown format fails the query
+	req := frontend.NewQueryRequestBuilder("USDJPY/1Min/OHLC").End()
+	req.Format = "parquet"
+	var mresp frontend.MultiQueryResponse
+	assert.NotNil(t, service.Query(nil, &frontend.MultiQueryRequest{Requests: []frontend.QueryRequest{req}}, &mresp))
+}
+
+func TestWriteArrow(t *testing.T) {
+	tearDown, rootDir, metadata, writer, q := setup(t, "TestWriteArrow")
+	defer tearDown()
+
+	service := frontend.NewDataService(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer, q)
+	service.Init()
+
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1600000020, 1600000080, 1600000140})
+	cs.AddColumn("Price", []float64{1.5, 2.5, 3.5})
+	cs.AddColumn("Size", []int32{10, 0, 30})
+	cs.SetNulls("Size", []bool{false, true, false})
+	stream, err := arrow.MarshalStream(*io.NewTimeBucketKey("TEST/1Min/TICK"), cs)
+	require.Nil(t, err)
+
+	var response frontend.MultiServerResponse
+	args := &frontend.MultiWriteRequest{Requests: []frontend.WriteRequest{{Arrow: [][]byte{stream}}}}
+	require.Nil(t, service.Write(nil, args, &response))
+	assert.Empty(t, response.Responses)
+
+	req := frontend.NewQueryRequestBuilder("TEST/1Min/TICK").End()
+	req.Format = frontend.FormatArrow
+	var qresponse frontend.MultiQueryResponse
+	require.Nil(t, service.Query(nil, &frontend.MultiQueryRequest{Requests: []frontend.QueryRequest{req}}, &qresponse))
+	csm, err := qresponse.ToColumnSeriesMap()
+	require.Nil(t, err)
+	out := (*csm)[*io.NewTimeBucketKey("TEST/1Min/TICK")]
+	require.NotNil(t, out)
+	assert.Equal(t, cs.GetEpoch(), out.GetEpoch())
+	assert.Equal(t, cs.GetColumn("Price"), out.GetColumn("Price"))
+	assert.Equal(t, []bool{false, true, false}, out.Nulls("Size"))
+
+	// A write request needs data
+	response = frontend.MultiServerResponse{}
+	args = &frontend.MultiWriteRequest{Requests: []frontend.WriteRequest{{}}}
+	require.Nil(t, service.Write(nil, args, &response))
+	require.Len(t, response.Responses, 1)
+	assert.NotEmpty(t, response.Responses[0].Error)
+}
@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+
+	"github.com/alpacahq/marketstore/v4/utils"
+)
+
+// allKeys is the key pattern of the rules on all the buckets, the only rules that allow a pattern.
+const allKeys = "*/*/*"
+
+// patternChars are the characters of the glob patterns of a key.
+const patternChars = "*?[]{}\\"
+
+// rule is a compiled ACL rule.
+type rule struct {
+	keys  []glob.Glob
+	verbs map[Verb]bool
+	// all is true if the rule is on all the buckets
+	all bool
+}
+
+func newRule(r utils.ACLRule) (rule, error) {
+	compiled := rule{verbs: map[Verb]bool{}}
+	for _, key := range r.Keys {
+		g, err := glob.Compile(key, '/')
+		if err != nil {
+			return compiled, fmt.Errorf("invalid key pattern %q: %w", key, err)
+		}
+		compiled.keys = append(compiled.keys, g)
+		compiled.all = compiled.all || key == allKeys || key == "**"
+	}
+	for _, verb := range r.Verbs {
+		compiled.verbs[Verb(verb)] = true
+	}
+	return compiled, nil
+}
+
+/*
+allows returns true if the rule allows the verb on a key without lists. A pattern is not matched against the
+patterns of the rule, the buckets it matches could be outside of them: only a rule on all the buckets allows it.
+*/
+func (r *rule) allows(verb Verb, key string) bool {
+	if !r.verbs[verb] {
+		return false
+	}
+	if IsPattern(key) {
+		return r.all
+	}
+	for _, g := range r.keys {
+		if g.Match(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsPattern returns true if a key has glob patterns, which must be expanded to the buckets to authorize them.
+func IsPattern(key string) bool {
+	return strings.ContainsAny(key, patternChars)
+}
+
+// expandKey returns the keys of each combination of the items of the comma separated lists of a key, e.g.
+// "AAPL/1Min/OHLCV" and "TSLA/1Min/OHLCV" for "AAPL,TSLA/1Min/OHLCV".
+func expandKey(key string) []string {
+	keys := []string{""}
+	for i, item := range strings.Split(key, "/") {
+		var next []string
+		for _, prefix := range keys {
+			for _, value := range strings.Split(item, ",") {
+				if i > 0 {
+					value = prefix + "/" + value
+				}
+				next = append(next, value)
+			}
+		}
+		keys = next
+	}
+	return keys
+}
@@ -0,0 +1,198 @@
+// Package auth authenticates the requests of the RPC, gRPC and websocket APIs and authorizes them with ACLs.
+//
+// A request is authenticated by one of:
+//   - a static API key, sent as "Authorization: Bearer <key>"
+//   - a token signed with HMAC-SHA256, sent the same way (see SignToken)
+//   - a client certificate of the gRPC API, verified with the configured CAs, whose Common Name is the user
+//
+// The user of the credentials has the roles of the configuration, and a token can grant more roles. Each role
+// is a list of rules, which allow verbs (read, write, create, destroy, admin) on the buckets matching TimeBucketKey
+// glob patterns, e.g. "*/1Min/OHLCV". Nothing is allowed that no rule allows. The requests on patterns of keys
+// are authorized on each bucket that the pattern matches.
+//
+// The Guard is nil when the authentication is not configured: its handlers and interceptors let every request
+// through, and the requests have no Principal, which is allowed everything.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/metrics"
+	"github.com/alpacahq/marketstore/v4/utils"
+)
+
+// Verb is what a request does to the buckets.
+type Verb string
+
+const (
+	Read    Verb = "read"
+	Write   Verb = "write"
+	Create  Verb = "create"
+	Destroy Verb = "destroy"
+	// Admin is allowed on "*/*/*" for the requests on the whole database, e.g. a backup
+	Admin Verb = "admin"
+)
+
+var (
+	// ErrUnauthenticated is returned for the requests without valid credentials.
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrPermissionDenied is returned for the requests that the ACLs do not allow.
+	ErrPermissionDenied = errors.New("permission denied")
+)
+
+// Principal is the authenticated user of a request.
+type Principal struct {
+	Name  string
+	Roles []string
+	rules []rule
+}
+
+/*
+Authorize returns ErrPermissionDenied if the verb is not allowed on one of the keys, which are the item keys
+of the buckets, e.g. "AAPL/1Min/OHLCV". An item can be a comma separated list, but a glob pattern is only
+allowed by a rule on all the buckets: the requests on patterns expand them to the buckets of the
+catalog and authorize each one. A nil principal is allowed everything.
+*/
+func (p *Principal) Authorize(verb Verb, keys ...string) error {
+	if p == nil {
+		return nil
+	}
+	for _, key := range keys {
+		if !p.allowed(verb, key) {
+			metrics.AuthDenied.WithLabelValues(string(verb)).Inc()
+			return fmt.Errorf("%w: %s can not %s %s", ErrPermissionDenied, p.Name, verb, key)
+		}
+	}
+	return nil
+}
+
+// Allowed returns true if the verb is allowed on the key, see Authorize. Unlike Authorize, it does not count
+// the keys that are not allowed, it is meant to filter the results of a request.
+func (p *Principal) Allowed(verb Verb, key string) bool {
+	return p == nil || p.allowed(verb, key)
+}
+
+func (p *Principal) allowed(verb Verb, key string) bool {
+	for _, k := range expandKey(key) {
+		ok := false
+		for i := range p.rules {
+			if p.rules[i].allows(verb, k) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+type principalKey struct{}
+
+// NewContext returns a context carrying the principal of a request.
+func NewContext(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of a request, nil if it has none.
+func FromContext(ctx context.Context) *Principal {
+	if ctx == nil {
+		return nil
+	}
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Authorize authorizes the verb on the keys for the principal of the context, see Principal.Authorize.
+func Authorize(ctx context.Context, verb Verb, keys ...string) error {
+	return FromContext(ctx).Authorize(verb, keys...)
+}
+
+// Guard authenticates the requests with the credentials of the configuration.
+type Guard struct {
+	setting utils.AuthSetting
+	// apiKeys are the users of the API keys by their SHA-256, so that the lookup does not leak the keys
+	apiKeys map[[sha256.Size]byte]string
+	roles   map[string][]rule
+}
+
+// NewFromSetting returns the guard of the setting, nil if the authentication is not enabled.
+func NewFromSetting(setting utils.AuthSetting) (*Guard, error) {
+	if !setting.Enabled() {
+		return nil, nil
+	}
+	g := &Guard{
+		setting: setting,
+		apiKeys: map[[sha256.Size]byte]string{},
+		roles:   map[string][]rule{},
+	}
+	for key, user := range setting.APIKeys {
+		g.apiKeys[sha256.Sum256([]byte(key))] = user
+	}
+	for role, acl := range setting.Roles {
+		for _, r := range acl {
+			compiled, err := newRule(r)
+			if err != nil {
+				return nil, fmt.Errorf("role %s: %w", role, err)
+			}
+			g.roles[role] = append(g.roles[role], compiled)
+		}
+	}
+	return g, nil
+}
+
+// principal returns the principal of a user with the roles of the configuration and the extra roles.
+func (g *Guard) principal(name string, extraRoles []string) *Principal {
+	p := &Principal{Name: name}
+	seen := map[string]bool{}
+	for _, role := range append(append([]string{}, g.setting.Users[name]...), extraRoles...) {
+		if seen[role] {
+			continue
+		}
+		seen[role] = true
+		p.Roles = append(p.Roles, role)
+		p.rules = append(p.rules, g.roles[role]...)
+	}
+	return p
+}
+
+// authenticateBearer returns the principal of an API key or a signed token.
+func (g *Guard) authenticateBearer(credential string) (*Principal, error) {
+	if user, ok := g.apiKeys[sha256.Sum256([]byte(credential))]; ok {
+		return g.principal(user, nil), nil
+	}
+	if g.setting.TokenSecret != "" && strings.Contains(credential, ".") {
+		claims, err := VerifyToken(g.setting.TokenSecret, credential)
+		if err != nil {
+			return nil, err
+		}
+		return g.principal(claims.Subject, claims.Roles), nil
+	}
+	return nil, fmt.Errorf("%w: unknown API key", ErrUnauthenticated)
+}
+
+/*
+Authenticate returns the principal of an API key or a signed token received outside of an Authorization header,
+e.g. as the password of a PostgreSQL connection. A nil guard authenticates everything with a nil principal.
+*/
+func (g *Guard) Authenticate(credential string) (*Principal, error) {
+	if g == nil {
+		return nil, nil
+	}
+	p, err := g.authenticateBearer(credential)
+	if err != nil {
+		return nil, denied(err)
+	}
+	return p, nil
+}
+
+// denied counts a request denied for its credentials.
+func denied(err error) error {
+	metrics.AuthDenied.WithLabelValues("authenticate").Inc()
+	return err
+}
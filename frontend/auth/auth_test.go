@@ -0,0 +1,213 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/utils"
+)
+
+func setting() utils.AuthSetting {
+	return utils.AuthSetting{
+		APIKeys:        map[string]string{"reader-key": "dashboard", "feeder-key": "feeder"},
+		TokenSecret:    "secret",
+		AllowedOrigins: []string{"https://dashboard.example.com"},
+		Users:          map[string][]string{"dashboard": {"reader"}, "feeder": {"feeder"}},
+		Roles: map[string][]utils.ACLRule{
+			"reader": {{Keys: []string{"*/*/*"}, Verbs: []string{"read"}}},
+			"feeder": {
+				{Keys: []string{"*/1Min/OHLCV"}, Verbs: []string{"write", "create"}},
+				{Keys: []string{"AAPL/1Sec/TICK", "TSLA/1Sec/TICK"}, Verbs: []string{"write"}},
+			},
+			"admin": {{Keys: []string{"*/*/*"}, Verbs: []string{"read", "write", "create", "destroy", "admin"}}},
+		},
+	}
+}
+
+func newGuard(t *testing.T) *auth.Guard {
+	t.Helper()
+	g, err := auth.NewFromSetting(setting())
+	require.Nil(t, err)
+	require.NotNil(t, g)
+	return g
+}
+
+// principalOf authenticates an RPC request with the credential and returns its principal.
+func principalOf(t *testing.T, g *auth.Guard, credential string) *auth.Principal {
+	t.Helper()
+	var p *auth.Principal
+	h := g.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p = auth.FromContext(r.Context())
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+	r.Header.Set("Authorization", "Bearer "+credential)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, p)
+	return p
+}
+
+func TestDisabled(t *testing.T) {
+	t.Parallel()
+	g, err := auth.NewFromSetting(utils.AuthSetting{})
+	assert.Nil(t, err)
+	assert.Nil(t, g)
+
+	called := false
+	h := g.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		assert.Nil(t, auth.FromContext(r.Context()))
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/rpc", nil))
+	assert.True(t, called)
+
+	var p *auth.Principal
+	assert.Nil(t, p.Authorize(auth.Destroy, "*/*/*"))
+	assert.True(t, g.CheckOrigin(httptest.NewRequest(http.MethodGet, "/ws", nil)))
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+	g := newGuard(t)
+
+	reader := principalOf(t, g, "reader-key")
+	assert.Equal(t, "dashboard", reader.Name)
+	assert.Nil(t, reader.Authorize(auth.Read, "AAPL/1Min/OHLCV", "*/1D/OHLCV", "AAPL,TSLA/1Sec/TICK"))
+	err := reader.Authorize(auth.Write, "AAPL/1Min/OHLCV")
+	assert.True(t, errors.Is(err, auth.ErrPermissionDenied))
+
+	feeder := principalOf(t, g, "feeder-key")
+	assert.Nil(t, feeder.Authorize(auth.Write, "AAPL/1Min/OHLCV", "AAPL,TSLA/1Sec/TICK"))
+	assert.Nil(t, feeder.Authorize(auth.Create, "NVDA/1Min/OHLCV"))
+	assert.NotNil(t, feeder.Authorize(auth.Create, "AAPL/1Sec/TICK"))
+	assert.NotNil(t, feeder.Authorize(auth.Write, "AAPL,NVDA/1Sec/TICK"))
+	// a pattern could match buckets outside of the rules, only a rule on all the buckets allows it
+	assert.NotNil(t, feeder.Authorize(auth.Write, "*/1Sec/TICK"))
+	assert.NotNil(t, feeder.Authorize(auth.Write, "*/1Min/OHLCV"))
+	assert.NotNil(t, feeder.Authorize(auth.Write, "AAPL,[A-Z]*/1Min/OHLCV"))
+	assert.False(t, feeder.Allowed(auth.Write, "AAPL/1Min/*"))
+	assert.Nil(t, reader.Authorize(auth.Read, "*/1Min/OHLCV", "AAPL,TS?A/1Sec/{TICK,OHLCV}"))
+	assert.NotNil(t, reader.Authorize(auth.Admin, "*/*/*"))
+	assert.NotNil(t, feeder.Authorize(auth.Read, "AAPL/1Min/OHLCV"))
+	assert.True(t, feeder.Allowed(auth.Write, "TSLA/1Sec/TICK"))
+	assert.False(t, feeder.Allowed(auth.Destroy, "TSLA/1Sec/TICK"))
+
+	ctx := auth.NewContext(context.Background(), reader)
+	assert.Equal(t, reader, auth.FromContext(ctx))
+	assert.Nil(t, auth.Authorize(ctx, auth.Read, "AAPL/1Min/OHLCV"))
+	assert.NotNil(t, auth.Authorize(ctx, auth.Destroy, "AAPL/1Min/OHLCV"))
+	assert.Nil(t, auth.Authorize(context.Background(), auth.Destroy, "AAPL/1Min/OHLCV"))
+}
+
+func TestToken(t *testing.T) {
+	t.Parallel()
+	g := newGuard(t)
+
+	token, err := auth.SignToken("secret", auth.TokenClaims{Subject: "dashboard", Roles: []string{"admin"}})
+	assert.Nil(t, err)
+	claims, err := auth.VerifyToken("secret", token)
+	assert.Nil(t, err)
+	assert.Equal(t, "dashboard", claims.Subject)
+
+	// the roles of the token are added to the ones of the user
+	p := principalOf(t, g, token)
+	assert.Equal(t, []string{"reader", "admin"}, p.Roles)
+	assert.Nil(t, p.Authorize(auth.Destroy, "AAPL/1Min/OHLCV"))
+	assert.Nil(t, p.Authorize(auth.Admin, "*/*/*"))
+
+	_, err = auth.VerifyToken("other secret", token)
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+	_, err = auth.VerifyToken("secret", "x"+token)
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+	_, err = auth.VerifyToken("secret", "garbage")
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+
+	expired, err := auth.SignToken("secret", auth.TokenClaims{
+		Subject: "dashboard", ExpiresAt: time.Now().Add(-time.Minute).Unix(),
+	})
+	assert.Nil(t, err)
+	_, err = auth.VerifyToken("secret", expired)
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+
+	_, err = auth.SignToken("secret", auth.TokenClaims{})
+	assert.NotNil(t, err)
+}
+
+func TestHandler(t *testing.T) {
+	t.Parallel()
+	g := newGuard(t)
+	h := g.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(auth.FromContext(r.Context()).Name))
+	}))
+
+	for _, header := range []string{"", "Bearer unknown-key", "Basic cmVhZGVyLWtleQ==", "Bearer a.b"} {
+		r := httptest.NewRequest(http.MethodPost, "/rpc", nil)
+		if header != "" {
+			r.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusUnauthorized, w.Code, header)
+		assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+	}
+
+	// the browsers can not set the headers of a websocket
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ws?access_token=feeder-key", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "feeder", w.Body.String())
+}
+
+func TestCheckOrigin(t *testing.T) {
+	t.Parallel()
+	g := newGuard(t)
+	for origin, accepted := range map[string]bool{
+		"":                              true,
+		"https://dashboard.example.com": true,
+		"http://marketstore:5993":       true,
+		"https://evil.example.com":      false,
+	} {
+		r := httptest.NewRequest(http.MethodGet, "http://marketstore:5993/ws", nil)
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		assert.Equal(t, accepted, g.CheckOrigin(r), origin)
+	}
+}
+
+func TestGRPCInterceptors(t *testing.T) {
+	t.Parallel()
+	g := newGuard(t)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, auth.Authorize(ctx, auth.Write, req.(string))
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/proto.Marketstore/Write"}
+	call := func(md metadata.MD, key string) codes.Code {
+		ctx := metadata.NewIncomingContext(context.Background(), md)
+		_, err := g.UnaryServerInterceptor(ctx, key, info, handler)
+		return status.Code(err)
+	}
+
+	assert.Equal(t, codes.OK, call(metadata.Pairs("authorization", "Bearer feeder-key"), "AAPL/1Min/OHLCV"))
+	assert.Equal(t, codes.PermissionDenied,
+		call(metadata.Pairs("authorization", "Bearer reader-key"), "AAPL/1Min/OHLCV"))
+	assert.Equal(t, codes.Unauthenticated, call(metadata.Pairs("authorization", "Bearer unknown"), "AAPL/1Min/OHLCV"))
+	assert.Equal(t, codes.Unauthenticated, call(metadata.MD{}, "AAPL/1Min/OHLCV"))
+
+	var nilGuard *auth.Guard
+	_, err := nilGuard.UnaryServerInterceptor(context.Background(), "AAPL/1Min/OHLCV", info, handler)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/alpacahq/marketstore/v4/utils"
+)
+
+/*
+GRPCCredentials returns the TLS option of the gRPC API server, nil if it has no certificate. The client
+certificates are verified with the CAs of ClientCAFile if it is set, they are optional, so that the clients
+can still authenticate with an API key or a token.
+*/
+func GRPCCredentials(setting utils.AuthTLSSetting) (grpc.ServerOption, error) {
+	if setting.CertFile == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(setting.CertFile, setting.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("load the certificate of the gRPC API: %w", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if setting.ClientCAFile != "" {
+		pem, err := os.ReadFile(setting.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read the client CAs of the gRPC API: %w", err)
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate in the client CAs file %s", setting.ClientCAFile)
+		}
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return grpc.Creds(credentials.NewTLS(config)), nil
+}
+
+// UnaryServerInterceptor authenticates the unary calls of the gRPC API, see Handler.
+func (g *Guard) UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if g == nil {
+		return handler(ctx, req)
+	}
+	p, err := g.authenticateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, denied(err).Error())
+	}
+	resp, err := handler(NewContext(ctx, p), req)
+	return resp, grpcError(err)
+}
+
+// StreamServerInterceptor authenticates the streaming calls of the gRPC API, see Handler.
+func (g *Guard) StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if g == nil {
+		return handler(srv, ss)
+	}
+	p, err := g.authenticateGRPC(ss.Context())
+	if err != nil {
+		return status.Error(codes.Unauthenticated, denied(err).Error())
+	}
+	return grpcError(handler(srv, &principalStream{ServerStream: ss, ctx: NewContext(ss.Context(), p)}))
+}
+
+/*
+authenticateGRPC returns the principal of the credentials of a call: the API key or the token of its
+authorization metadata, or else the Common Name of its verified client certificate.
+*/
+func (g *Guard) authenticateGRPC(ctx context.Context) (*Principal, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) != 0 {
+			if !strings.HasPrefix(values[0], bearerPrefix) {
+				return nil, ErrUnauthenticated
+			}
+			return g.authenticateBearer(strings.TrimPrefix(values[0], bearerPrefix))
+		}
+	}
+	if pr, ok := peer.FromContext(ctx); ok {
+		if info, ok := pr.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) != 0 {
+			if cn := info.State.VerifiedChains[0][0].Subject.CommonName; cn != "" {
+				return g.principal(cn, nil), nil
+			}
+		}
+	}
+	return nil, ErrUnauthenticated
+}
+
+// grpcError returns the PermissionDenied status of the denied calls.
+func grpcError(err error) error {
+	if errors.Is(err, ErrPermissionDenied) {
+		return status.Error(codes.PermissionDenied, err.Error())
+	}
+	return err
+}
+
+// principalStream is a server stream whose context carries the principal of the call.
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
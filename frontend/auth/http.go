@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const bearerPrefix = "Bearer "
+
+/*
+Handler authenticates the requests before passing them to next with their principal in their context, see
+FromContext. The credentials are read from the Authorization header, or from the access_token parameter of
+the URL for the browsers, which can not set the headers of a websocket. The requests without valid
+credentials are answered 401.
+*/
+func (g *Guard) Handler(next http.Handler) http.Handler {
+	if g == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p, err := g.authenticateHTTP(r)
+		if err != nil {
+			_ = denied(err)
+			w.Header().Set("WWW-Authenticate", `Bearer realm="marketstore"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), p)))
+	})
+}
+
+func (g *Guard) authenticateHTTP(r *http.Request) (*Principal, error) {
+	credential := r.URL.Query().Get("access_token")
+	if h := r.Header.Get("Authorization"); h != "" {
+		if !strings.HasPrefix(h, bearerPrefix) {
+			return nil, ErrUnauthenticated
+		}
+		credential = strings.TrimPrefix(h, bearerPrefix)
+	}
+	if credential == "" {
+		return nil, ErrUnauthenticated
+	}
+	return g.authenticateBearer(credential)
+}
+
+/*
+CheckOrigin returns true if a websocket connection is accepted from the origin of the request: the requests
+without an Origin header, which do not come from a browser, those of the allowed origins of the configuration
+("*" allows any), and those of the same origin as the server. Any origin is accepted by a nil guard.
+*/
+func (g *Guard) CheckOrigin(r *http.Request) bool {
+	if g == nil {
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range g.setting.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, r.Host)
+}
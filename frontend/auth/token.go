@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+/*
+TokenClaims are the claims of a signed token. A token is the base64url encoding of the JSON of its claims,
+followed by a dot and the base64url encoding of their HMAC-SHA256 with the token secret, e.g.
+"eyJzdWIiOiJmZWVkZXIifQ.<signature>".
+*/
+type TokenClaims struct {
+	// Subject is the user of the token
+	Subject string `json:"sub"`
+	// Roles are granted on top of the roles of the user in the configuration
+	Roles []string `json:"roles,omitempty"`
+	// ExpiresAt is the unix time after which the token is not valid, never if 0
+	ExpiresAt int64 `json:"exp,omitempty"`
+}
+
+// SignToken returns the token of the claims signed with the secret.
+func SignToken(secret string, claims TokenClaims) (string, error) {
+	if claims.Subject == "" {
+		return "", fmt.Errorf("the subject of a token can not be empty")
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(sign(secret, encoded)), nil
+}
+
+// VerifyToken returns the claims of a token signed with the secret, if it is not expired.
+func VerifyToken(secret, token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("%w: malformed token", ErrUnauthenticated)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(signature, sign(secret, parts[0])) {
+		return nil, fmt.Errorf("%w: invalid token signature", ErrUnauthenticated)
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed token", ErrUnauthenticated)
+	}
+	var claims TokenClaims
+	if err = json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return nil, fmt.Errorf("%w: malformed token claims", ErrUnauthenticated)
+	}
+	if claims.ExpiresAt != 0 && time.Now().Unix() > claims.ExpiresAt {
+		return nil, fmt.Errorf("%w: the token of %s expired", ErrUnauthenticated, claims.Subject)
+	}
+	return &claims, nil
+}
+
+func sign(secret, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,139 @@
+package frontend
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/sqlparser"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// allBuckets is the key of the requests on the whole database, e.g. a backup.
+const allBuckets = "*/*/*"
+
+// principalOf returns the principal of an RPC request, nil if the authentication is disabled.
+func principalOf(r *http.Request) *auth.Principal {
+	if r == nil {
+		return nil
+	}
+	return auth.FromContext(r.Context())
+}
+
+/*
+authorizeSQL authorizes the reads and the writes of the tables of a SQL statement, the tables selecting symbols
+with a pattern are authorized on each bucket that the pattern reads. A write to a table that is not in the
+catalog creates it.
+*/
+func authorizeSQL(principal *auth.Principal, catDir *catalog.Directory, es *sqlparser.ExecutableStatement) error {
+	if principal == nil {
+		return nil
+	}
+	read, written := es.Tables()
+	for _, table := range read {
+		keys, err := sqlparser.TableBuckets(catDir, table)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			keys = []string{table}
+		}
+		if err = principal.Authorize(auth.Read, keys...); err != nil {
+			return err
+		}
+	}
+	for _, table := range written {
+		if err := principal.Authorize(auth.Write, table); err != nil {
+			return err
+		}
+		if tbk := io.NewTimeBucketKey(table); tbk != nil {
+			if _, err := catDir.GetLatestTimeBucketInfoFromKey(tbk); err == nil {
+				continue
+			}
+		}
+		if err := principal.Authorize(auth.Create, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+authorizeKeys returns the buckets of the catalog matching a key, which can have lists and patterns of items,
+once the verb is authorized on them: the buckets that the principal can not read are left out of a read, the
+other verbs are denied if one of the buckets is not allowed. A key matching no allowed bucket is authorized as
+a whole, so that a pattern matching nothing is still only allowed by a rule on all the buckets.
+*/
+func authorizeKeys(principal *auth.Principal, verb auth.Verb, catDir *catalog.Directory, key *io.TimeBucketKey,
+) ([]*io.TimeBucketKey, error) {
+	keys := catalog.MatchTimeBucketKeys(catDir, key)
+	if principal == nil {
+		return keys, nil
+	}
+	allowed := make([]*io.TimeBucketKey, 0, len(keys))
+	for _, k := range keys {
+		if verb != auth.Read {
+			if err := principal.Authorize(verb, k.GetItemKey()); err != nil {
+				return nil, err
+			}
+		} else if !principal.Allowed(verb, k.GetItemKey()) {
+			continue
+		}
+		allowed = append(allowed, k)
+	}
+	if len(allowed) == 0 {
+		return nil, principal.Authorize(verb, key.GetItemKey())
+	}
+	return allowed, nil
+}
+
+/*
+readableDestination authorizes the read of the destination of a query. A destination selecting its symbols with a
+pattern, e.g. "*" for all of them, is replaced by the list of the symbols of the buckets that the principal can read.
+*/
+func readableDestination(principal *auth.Principal, catDir *catalog.Directory, dest *io.TimeBucketKey,
+) (*io.TimeBucketKey, error) {
+	timeframe := dest.GetItemInCategory("Timeframe")
+	recordFormat := dest.GetItemInCategory("AttributeGroup")
+	if principal == nil || !auth.IsPattern(dest.GetItemInCategory("Symbol")) ||
+		auth.IsPattern(timeframe) || auth.IsPattern(recordFormat) {
+		return dest, principal.Authorize(auth.Read, dest.GetItemKey())
+	}
+	keys, err := authorizeKeys(principal, auth.Read, catDir, dest)
+	if err != nil || len(keys) == 0 {
+		return dest, err
+	}
+	symbols := make([]string, len(keys))
+	for i, key := range keys {
+		symbols[i] = key.GetItemInCategory("Symbol")
+	}
+	itemKey := strings.Join([]string{strings.Join(symbols, ","), timeframe, recordFormat}, "/")
+	return io.NewTimeBucketKey(itemKey, dest.GetCatKey()), nil
+}
+
+// readableKeys returns the bucket keys that the principal can read.
+func readableKeys(principal *auth.Principal, keys []string) []string {
+	if principal == nil {
+		return keys
+	}
+	readable := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if principal.Allowed(auth.Read, key) {
+			readable = append(readable, key)
+		}
+	}
+	return readable
+}
+
+// readableSymbols returns the symbols of the catalog that have a bucket the principal can read.
+func readableSymbols(principal *auth.Principal, catDir *catalog.Directory) map[string]int {
+	if principal == nil {
+		return catDir.GatherCategoriesAndItems()["Symbol"]
+	}
+	symbols := map[string]int{}
+	for _, key := range readableKeys(principal, catalog.ListTimeBucketKeyNames(catDir)) {
+		symbols[strings.SplitN(key, "/", 2)[0]]++
+	}
+	return symbols
+}
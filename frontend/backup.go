@@ -0,0 +1,61 @@
+package frontend
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/utils"
+)
+
+/*
+	Backup: Copies the files of the root directory into a new backup directory, while the writes go on
+*/
+type BackupRequest struct {
+	// the name of the backup directory in the backup_directory of the server,
+	// "backup-{UTC time}" if empty. e.g. "backup-20210102T150405Z"
+	Name string `msgpack:"name"`
+}
+
+type BackupResponse struct {
+	// the path of the backup directory on the server
+	Path string `msgpack:"path"`
+	// the ID of the last transaction group in the backup
+	TGID  int64 `msgpack:"tgid"`
+	Files int   `msgpack:"files"`
+	Bytes int64 `msgpack:"bytes"`
+}
+
+func (s *DataService) Backup(r *http.Request, req *BackupRequest, response *BackupResponse) (err error) {
+	resp, err := backup(principalOf(r), s.writer, req.Name)
+	if err != nil {
+		return err
+	}
+	*response = *resp
+	return nil
+}
+
+// backup makes a backup in the backup directory of the configuration, it is an administration of the database.
+func backup(principal *auth.Principal, w Writer, name string) (*BackupResponse, error) {
+	if err := principal.Authorize(auth.Admin, allBuckets); err != nil {
+		return nil, err
+	}
+	if utils.InstanceConfig.BackupDirectory == "" {
+		return nil, errors.New("backups are disabled, backup_directory is not set in the configuration")
+	}
+	if name == "" {
+		name = "backup-" + time.Now().UTC().Format("20060102T150405Z")
+	}
+	if filepath.Base(name) != name || name == "." || name == ".." {
+		return nil, fmt.Errorf("invalid backup name %q, it must be a directory name", name)
+	}
+	path := filepath.Join(utils.InstanceConfig.BackupDirectory, name)
+	m, err := w.Backup(path)
+	if err != nil {
+		return nil, fmt.Errorf("backup failed: %w", err)
+	}
+	return &BackupResponse{Path: path, TGID: m.TGID, Files: len(m.Files), Bytes: m.Size()}, nil
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	goio "io"
 	"net/http"
@@ -25,6 +26,8 @@ const (
 
 type Client struct {
 	BaseURL string
+	// Token is the API key or the signed token sent to a server with the authentication enabled
+	Token string
 }
 
 // NewClient intializes a new MarketStore RPC client.
@@ -57,6 +60,7 @@ func (cl *Client) DoRPC(functionName string, args interface{}) (response interfa
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/x-msgpack")
+	cl.setAuthorization(req.Header)
 	client := new(http.Client)
 	resp, err := client.Do(req)
 	if err != nil {
@@ -92,7 +96,7 @@ func (cl *Client) DoRPC(functionName string, args interface{}) (response interfa
 		}
 		return result, nil
 
-	case "Create", "Destroy":
+	case "Create", "Destroy", "Delete", "AlterBucket":
 		result := &frontend.MultiServerResponse{}
 		err = msgpack2.DecodeClientResponse(resp.Body, result)
 		if err != nil {
@@ -115,6 +119,20 @@ func (cl *Client) DoRPC(functionName string, args interface{}) (response interfa
 			return nil, fmt.Errorf("decode ListSymbols API client response:%w", err)
 		}
 		return result.Results, nil
+	case "Backup":
+		result := &frontend.BackupResponse{}
+		err = msgpack2.DecodeClientResponse(resp.Body, result)
+		if err != nil {
+			return nil, fmt.Errorf("decode Backup API client response:%w", err)
+		}
+		return result, nil
+	case "Compact":
+		result := &frontend.CompactResponse{}
+		err = msgpack2.DecodeClientResponse(resp.Body, result)
+		if err != nil {
+			return nil, fmt.Errorf("decode Compact API client response:%w", err)
+		}
+		return result, nil
 	case "Write":
 		result := &frontend.MultiServerResponse{}
 		err = msgpack2.DecodeClientResponse(resp.Body, result)
@@ -129,6 +147,57 @@ func (cl *Client) DoRPC(functionName string, args interface{}) (response interfa
 	return nil, nil
 }
 
+/*
+QueryStream runs a streamed query, the handler receives the batches of rows
+until it returns false or the query is done. The server stops reading when
+the handler stops.
+*/
+func (cl *Client) QueryStream(args *frontend.QueryStreamRequest,
+	handler func(resp *frontend.QueryStreamResponse) bool,
+) error {
+	body, err := msgpack.Marshal(args)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), "POST", cl.BaseURL+"/query/stream",
+		bytes.NewBuffer(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-msgpack")
+	cl.setAuthorization(req.Header)
+	resp, err := new(http.Client).Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body goio.ReadCloser) {
+		if err2 := Body.Close(); err2 != nil {
+			log.Error(fmt.Sprintf("failed to close http client for marketstore api. err=%v", err2))
+		}
+	}(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := goio.ReadAll(resp.Body)
+		return fmt.Errorf("response error (%d): %s", resp.StatusCode, strings.TrimSpace(string(bodyBytes)))
+	}
+
+	dec := msgpack.NewDecoder(resp.Body)
+	for {
+		batch := &frontend.QueryStreamResponse{}
+		if err = dec.Decode(batch); err != nil {
+			if errors.Is(err, goio.EOF) {
+				return nil
+			}
+			return fmt.Errorf("decode QueryStream response: %w", err)
+		}
+		if batch.Error != "" {
+			return fmt.Errorf("query stream error: %s", batch.Error)
+		}
+		if !handler(batch) {
+			return nil
+		}
+	}
+}
+
 // Subscribe to the marketstore websocket interface with a
 // message handler, a set of streams and cancel channel.
 func (cl *Client) Subscribe(
@@ -138,7 +207,9 @@ func (cl *Client) Subscribe(
 	u, _ := url.Parse(cl.BaseURL + "/ws")
 	u.Scheme = "ws"
 
-	conn, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	header := http.Header{}
+	cl.setAuthorization(header)
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), header)
 	if err != nil {
 		return nil, err
 	}
@@ -270,3 +341,9 @@ func streamsEqual(a, b []string) bool {
 	}
 	return true
 }
+
+func (cl *Client) setAuthorization(header http.Header) {
+	if cl.Token != "" {
+		header.Set("Authorization", "Bearer "+cl.Token)
+	}
+}
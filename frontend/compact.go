@@ -0,0 +1,84 @@
+package frontend
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+	Compact: Rewrites the year files of variable length buckets without the rows of the rewritten intervals
+*/
+type CompactRequest struct {
+	// the buckets to compact, each item can be a comma separated list of glob patterns. e.g. "*/1Sec/TICK"
+	Key string `msgpack:"key"`
+	// the year to compact, all the years of the buckets if 0
+	Year int16 `msgpack:"year"`
+	// the year files whose dead space is less than this part of their rows are skipped, 0 to compact all
+	MinDeadRatio float64 `msgpack:"min_dead_ratio"`
+}
+
+type CompactResponse struct {
+	Results []CompactResult `msgpack:"results"`
+	// the total number of bytes freed
+	Reclaimed int64 `msgpack:"reclaimed"`
+}
+
+type CompactResult struct {
+	Key        string `msgpack:"key"`
+	Year       int16  `msgpack:"year"`
+	SizeBefore int64  `msgpack:"size_before"`
+	SizeAfter  int64  `msgpack:"size_after"`
+	DeadBytes  int64  `msgpack:"dead_bytes"`
+	Compacted  bool   `msgpack:"compacted"`
+}
+
+func (s *DataService) Compact(r *http.Request, req *CompactRequest, response *CompactResponse) (err error) {
+	resp, err := compact(principalOf(r), s.catalogDir, s.writer, req)
+	if err != nil {
+		return err
+	}
+	*response = *resp
+	return nil
+}
+
+func compact(principal *auth.Principal, catDir *catalog.Directory, w Writer, req *CompactRequest,
+) (*CompactResponse, error) {
+	if req.MinDeadRatio < 0 || req.MinDeadRatio > 1 {
+		return nil, fmt.Errorf("invalid minimum dead ratio %v, it must be between 0 and 1", req.MinDeadRatio)
+	}
+	pattern := io.NewTimeBucketKey(req.Key)
+	if len(pattern.GetItems()) != 3 {
+		return nil, fmt.Errorf("key %q is not in proper format, should be like: TSLA/1Sec/TICK", req.Key)
+	}
+	keys, err := authorizeKeys(principal, auth.Write, catDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	// Each authorized bucket is compacted on its own, a bucket created since then does not match the pattern
+	var results []executor.CompactionResult
+	for _, key := range keys {
+		res, err2 := w.Compact(key, req.Year, req.MinDeadRatio)
+		results = append(results, res...)
+		if err2 != nil && err == nil {
+			err = err2
+		}
+	}
+	resp := &CompactResponse{}
+	for i := range results {
+		r := &results[i]
+		resp.Results = append(resp.Results, CompactResult{
+			Key: r.Key.GetItemKey(), Year: r.Year, SizeBefore: r.SizeBefore, SizeAfter: r.SizeAfter,
+			DeadBytes: r.DeadBytes, Compacted: r.Compacted,
+		})
+		resp.Reclaimed += r.Reclaimed()
+	}
+	if err != nil {
+		return resp, fmt.Errorf("compaction failed: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,291 @@
+package frontend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goio "io"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/frontend/flight"
+	"github.com/alpacahq/marketstore/v4/utils/arrow"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+FlightCommand is the JSON command of the Arrow Flight descriptors and tickets. A PATH descriptor is the
+command of its destination, e.g. ["AAPL/1Min/OHLCV"].
+*/
+type FlightCommand struct {
+	// Destination is <symbol>/<timeframe>/<attributegroup>, the symbols may be a list or glob patterns
+	Destination string `json:"destination"`
+	// Lower and upper time predicates in unix epoch second, all the rows by default
+	EpochStart *int64 `json:"epoch_start,omitempty"`
+	EpochEnd   *int64 `json:"epoch_end,omitempty"`
+	// Array of column names to be returned
+	Columns []string `json:"columns,omitempty"`
+	// Max number of rows in each record batch of DoGet, DefaultQueryBatchSize if 0
+	BatchSize int `json:"batch_size,omitempty"`
+	// DoPut creates a variable length bucket if it does not exist
+	IsVariableLength bool `json:"is_variable_length,omitempty"`
+}
+
+/*
+FlightService serves the buckets over Arrow Flight: GetFlightInfo returns an endpoint per bucket of a destination,
+whose ticket DoGet streams as record batches of bounded size, and DoPut writes the record batches of a bucket.
+The schema of a bucket has its time bucket key in the "marketstore.key" metadata, see the arrow package.
+*/
+type FlightService struct {
+	flight.UnimplementedFlightServiceServer
+	catalogDir *catalog.Directory
+	writer     Writer
+}
+
+func NewFlightService(catDir *catalog.Directory, w Writer) *FlightService {
+	return &FlightService{
+		catalogDir: catDir,
+		writer:     w,
+	}
+}
+
+// parseFlightDescriptor returns the command of a descriptor.
+func parseFlightDescriptor(d *flight.FlightDescriptor) (*FlightCommand, error) {
+	switch d.GetType() {
+	case flight.FlightDescriptor_PATH:
+		return &FlightCommand{Destination: strings.Join(d.GetPath(), "/")}, nil
+	case flight.FlightDescriptor_CMD:
+		cmd := &FlightCommand{}
+		if err := json.Unmarshal(d.GetCmd(), cmd); err != nil {
+			return nil, fmt.Errorf("invalid flight command: %w", err)
+		}
+		return cmd, nil
+	default:
+		return nil, fmt.Errorf("flight descriptor without a path or a command")
+	}
+}
+
+// flightKeys returns the readable buckets of the destination of a command.
+func (s *FlightService) flightKeys(ctx context.Context, cmd *FlightCommand) ([]*io.TimeBucketKey, error) {
+	dest := io.NewTimeBucketKey(cmd.Destination)
+	if dest == nil || len(dest.GetItems()) != 3 {
+		return nil, fmt.Errorf("destinations must have a Symbol, Timeframe and AttributeGroup, have: %s",
+			cmd.Destination)
+	}
+	keys, err := authorizeKeys(auth.FromContext(ctx), auth.Read, s.catalogDir, dest)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no bucket matches %s", cmd.Destination)
+	}
+	return keys, nil
+}
+
+/*
+flightShapes returns the data shapes of the rows of a bucket returned by a query of the columns: the Epoch and
+the Nanoseconds of a variable length bucket come with the columns.
+*/
+func (s *FlightService) flightShapes(key *io.TimeBucketKey, columns []string) ([]io.DataShape, error) {
+	tbi, err := s.catalogDir.GetLatestTimeBucketInfoFromKey(key)
+	if err != nil {
+		return nil, err
+	}
+	keep := map[string]bool{"Epoch": true}
+	for _, name := range columns {
+		keep[name] = true
+	}
+	var dsv []io.DataShape
+	for _, ds := range tbi.GetDataShapesWithEpoch() {
+		if ds.Name != io.NullsColumn && (len(columns) == 0 || keep[ds.Name]) {
+			dsv = append(dsv, ds)
+		}
+	}
+	if tbi.GetRecordType() == io.VARIABLE {
+		dsv = append(dsv, io.DataShape{Name: "Nanoseconds", Type: io.INT32})
+	}
+	// the bitmap comes last, see io.IsNullable
+	if io.IsNullable(tbi.GetDataShapes()) {
+		dsv = append(dsv, io.DataShape{Name: io.NullsColumn, Type: io.UINT64})
+	}
+	return dsv, nil
+}
+
+// flightInfo returns the FlightInfo of the buckets, with an endpoint per bucket.
+func (s *FlightService) flightInfo(d *flight.FlightDescriptor, cmd *FlightCommand, keys []*io.TimeBucketKey,
+) (*flight.FlightInfo, error) {
+	dsv, err := s.flightShapes(keys[0], cmd.Columns)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := arrow.MarshalSchema(*keys[0], dsv)
+	if err != nil {
+		return nil, err
+	}
+	info := &flight.FlightInfo{Schema: schema, FlightDescriptor: d, TotalRecords: -1, TotalBytes: -1}
+	for _, key := range keys {
+		ticket := *cmd
+		ticket.Destination = key.GetItemKey()
+		b, err := json.Marshal(&ticket)
+		if err != nil {
+			return nil, err
+		}
+		info.Endpoint = append(info.Endpoint, &flight.FlightEndpoint{Ticket: &flight.Ticket{Ticket: b}})
+	}
+	return info, nil
+}
+
+func (s *FlightService) GetFlightInfo(ctx context.Context, d *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	cmd, err := parseFlightDescriptor(d)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := s.flightKeys(ctx, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return s.flightInfo(d, cmd, keys)
+}
+
+func (s *FlightService) GetSchema(ctx context.Context, d *flight.FlightDescriptor) (*flight.SchemaResult, error) {
+	info, err := s.GetFlightInfo(ctx, d)
+	if err != nil {
+		return nil, err
+	}
+	return &flight.SchemaResult{Schema: info.Schema}, nil
+}
+
+// ListFlights returns a flight per readable bucket, the expression of the criteria is a key pattern.
+func (s *FlightService) ListFlights(criteria *flight.Criteria, stream flight.FlightService_ListFlightsServer) error {
+	pattern := "*/*/*"
+	if len(criteria.Expression) != 0 {
+		pattern = string(criteria.Expression)
+	}
+	principal := auth.FromContext(stream.Context())
+	for _, key := range catalog.MatchTimeBucketKeys(s.catalogDir, io.NewTimeBucketKey(pattern)) {
+		if !principal.Allowed(auth.Read, key.GetItemKey()) {
+			continue
+		}
+		d := &flight.FlightDescriptor{Type: flight.FlightDescriptor_PATH, Path: []string{key.GetItemKey()}}
+		info, err := s.flightInfo(d, &FlightCommand{Destination: key.GetItemKey()}, []*io.TimeBucketKey{key})
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DoGet streams the rows of the bucket of a ticket, the schema first then a record batch per page of rows.
+func (s *FlightService) DoGet(ticket *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	cmd := &FlightCommand{}
+	if err := json.Unmarshal(ticket.GetTicket(), cmd); err != nil {
+		return fmt.Errorf("invalid flight ticket: %w", err)
+	}
+	req := &QueryStreamRequest{
+		Request: QueryRequest{
+			Destination: cmd.Destination,
+			EpochStart:  cmd.EpochStart,
+			EpochEnd:    cmd.EpochEnd,
+			Columns:     cmd.Columns,
+		},
+		BatchSize: cmd.BatchSize,
+	}
+	pager, err := newRequestPager(auth.FromContext(stream.Context()), s.catalogDir, req)
+	if err != nil {
+		return err
+	}
+	if len(pager.keys) != 1 {
+		return fmt.Errorf("a flight ticket must name a single bucket, not %s", cmd.Destination)
+	}
+	key := pager.keys[0]
+	dsv, err := s.flightShapes(key, cmd.Columns)
+	if err != nil {
+		return err
+	}
+	enc, err := arrow.NewShapeEncoder(*key, dsv)
+	if err != nil {
+		return err
+	}
+	if err = stream.Send(&flight.FlightData{DataHeader: enc.Schema().Header}); err != nil {
+		return err
+	}
+	for {
+		if err = stream.Context().Err(); err != nil {
+			return err
+		}
+		_, cs, err := pager.Next()
+		if err != nil {
+			return err
+		}
+		if cs == nil {
+			return nil
+		}
+		batch, err := enc.Encode(cs)
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(&flight.FlightData{DataHeader: batch.Header, DataBody: batch.Body}); err != nil {
+			return err
+		}
+	}
+}
+
+/*
+DoPut writes the record batches of a bucket as they come. The bucket is the destination of the descriptor of the
+first message, or the time bucket key of the schema metadata, it is created if it does not exist.
+*/
+func (s *FlightService) DoPut(stream flight.FlightService_DoPutServer) error {
+	var (
+		tbk              *io.TimeBucketKey
+		isVariableLength bool
+		decoder          arrow.Decoder
+	)
+	for first := true; ; first = false {
+		data, err := stream.Recv()
+		if errors.Is(err, goio.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if first && data.GetFlightDescriptor() != nil {
+			cmd, err := parseFlightDescriptor(data.GetFlightDescriptor())
+			if err != nil {
+				return err
+			}
+			if cmd.Destination != "" {
+				tbk = io.NewTimeBucketKey(cmd.Destination)
+			}
+			isVariableLength = cmd.IsVariableLength
+		}
+		cs, err := decoder.Decode(arrow.Message{Header: data.DataHeader, Body: data.DataBody})
+		if err != nil {
+			return err
+		}
+		if cs == nil {
+			continue
+		}
+		if tbk == nil {
+			if tbk = decoder.Key(); tbk == nil {
+				return fmt.Errorf("flight data without a destination or the %s schema metadata", arrow.KeyMetadata)
+			}
+		}
+		if len(tbk.GetItems()) != 3 {
+			return fmt.Errorf("flight data destination must be a Symbol/Timeframe/AttributeGroup, have: %s",
+				tbk.GetItemKey())
+		}
+		if err = auth.Authorize(stream.Context(), auth.Write, tbk.GetItemKey()); err != nil {
+			return err
+		}
+		if tbi, err := s.catalogDir.GetLatestTimeBucketInfoFromKey(tbk); err == nil {
+			isVariableLength = tbi.GetRecordType() == io.VARIABLE
+		}
+		if err = s.writer.WriteCSM(io.ColumnSeriesMap{*tbk: cs}, isVariableLength); err != nil {
+			return err
+		}
+	}
+}
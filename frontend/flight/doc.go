@@ -0,0 +1,6 @@
+/*
+Package flight is the gRPC protocol of the Arrow Flight service of marketstore, generated from the Flight.proto of
+the Apache Arrow project with its go_package changed, see the Makefile. The methods that marketstore does not serve
+are answered with Unimplemented.
+*/
+package flight
@@ -0,0 +1,209 @@
+package frontend_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	goio "io"
+	"net"
+	"testing"
+
+	"github.com/apache/arrow/go/v10/arrow/ipc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/frontend/flight"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/arrow"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/test"
+)
+
+// flightClient serves the flight service of the writer on an in-memory connection, with authentication.
+func flightClient(t *testing.T, service *frontend.FlightService) (client flight.FlightServiceClient, closer func()) {
+	t.Helper()
+
+	guard, err := auth.NewFromSetting(utils.AuthSetting{
+		APIKeys: map[string]string{"reader-key": "reader", "writer-key": "writer"},
+		Users:   map[string][]string{"reader": {"reader"}, "writer": {"reader", "writer"}},
+		Roles: map[string][]utils.ACLRule{
+			"reader": {{Keys: []string{"*/*/*"}, Verbs: []string{"read"}}},
+			"writer": {{Keys: []string{"TEST/*/*"}, Verbs: []string{"write", "create"}}},
+		},
+	})
+	require.Nil(t, err)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(guard.UnaryServerInterceptor),
+		grpc.StreamInterceptor(guard.StreamServerInterceptor),
+	)
+	flight.RegisterFlightServiceServer(server, service)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }))
+	require.Nil(t, err)
+	return flight.NewFlightServiceClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func withKey(key string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+key)
+}
+
+// doGet returns the time bucket key of the schema and the record batches of a ticket.
+func doGet(t *testing.T, client flight.FlightServiceClient, ticket *flight.Ticket,
+) (*io.TimeBucketKey, []*io.ColumnSeries) {
+	t.Helper()
+
+	stream, err := client.DoGet(withKey("reader-key"), ticket)
+	require.Nil(t, err)
+	var (
+		decoder arrow.Decoder
+		batches []*io.ColumnSeries
+	)
+	for {
+		data, err := stream.Recv()
+		if errors.Is(err, goio.EOF) {
+			return decoder.Key(), batches
+		}
+		require.Nil(t, err)
+		cs, err := decoder.Decode(arrow.Message{Header: data.DataHeader, Body: data.DataBody})
+		require.Nil(t, err)
+		if cs != nil {
+			batches = append(batches, cs)
+		}
+	}
+}
+
+func TestFlight(t *testing.T) {
+	tearDown, _, metadata, writer, _ := setup(t, "TestFlight")
+	defer tearDown()
+
+	client, closer := flightClient(t, frontend.NewFlightService(metadata.CatalogDir, writer))
+	defer closer()
+
+	start := test.ParseT("2002-10-01 00:00:00").Unix()
+	end := test.ParseT("2002-10-01 01:00:00").Unix()
+	cmd, err := json.Marshal(&frontend.FlightCommand{
+		Destination: "USDJPY,EURUSD/1Min/OHLC",
+		EpochStart:  &start,
+		EpochEnd:    &end,
+		BatchSize:   25,
+	})
+	require.Nil(t, err)
+	descriptor := &flight.FlightDescriptor{Type: flight.FlightDescriptor_CMD, Cmd: cmd}
+
+	// The flight has an endpoint per bucket, and the schema of the buckets
+	info, err := client.GetFlightInfo(withKey("reader-key"), descriptor)
+	require.Nil(t, err)
+	require.Len(t, info.Endpoint, 2)
+	m, err := arrow.ReadMessage(bytes.NewReader(info.Schema))
+	require.Nil(t, err)
+	var decoder arrow.Decoder
+	_, err = decoder.Decode(m)
+	require.Nil(t, err)
+	assert.Contains(t, []string{"USDJPY/1Min/OHLC", "EURUSD/1Min/OHLC"}, decoder.Key().GetItemKey())
+
+	// The 61 rows of a bucket come in 3 batches
+	key, batches := doGet(t, client, info.Endpoint[0].Ticket)
+	require.NotNil(t, key)
+	var epochs []int64
+	var lens []int
+	for _, cs := range batches {
+		lens = append(lens, cs.Len())
+		epochs = append(epochs, cs.GetEpoch()...)
+	}
+	assert.Equal(t, []int{25, 25, 11}, lens)
+	assert.Equal(t, start, epochs[0])
+	assert.Equal(t, end, epochs[len(epochs)-1])
+
+	// The flight data are read by the reader of the arrow library
+	stream, err := client.DoGet(withKey("reader-key"), info.Endpoint[0].Ticket)
+	require.Nil(t, err)
+	var buf bytes.Buffer
+	for {
+		data, err := stream.Recv()
+		if errors.Is(err, goio.EOF) {
+			break
+		}
+		require.Nil(t, err)
+		require.Nil(t, arrow.WriteMessage(&buf, arrow.Message{Header: data.DataHeader, Body: data.DataBody}))
+	}
+	r, err := ipc.NewReader(&buf)
+	require.Nil(t, err)
+	defer r.Release()
+	assert.Equal(t, "Epoch", r.Schema().Field(0).Name)
+	rows := 0
+	for r.Next() {
+		rows += int(r.Record().NumRows())
+	}
+	require.Nil(t, r.Err())
+	assert.Equal(t, len(epochs), rows)
+
+	// A reader can not put, a writer puts the batches in a new bucket
+	put := func(apiKey, dest string) error {
+		stream, err := client.DoPut(withKey(apiKey))
+		require.Nil(t, err)
+		enc, err := arrow.NewSeriesEncoder(*key, batches[0])
+		require.Nil(t, err)
+		d := &flight.FlightDescriptor{Type: flight.FlightDescriptor_PATH, Path: []string{dest}}
+		require.Nil(t, stream.Send(&flight.FlightData{FlightDescriptor: d, DataHeader: enc.Schema().Header}))
+		for _, cs := range batches {
+			batch, err := enc.Encode(cs)
+			require.Nil(t, err)
+			if err = stream.Send(&flight.FlightData{DataHeader: batch.Header, DataBody: batch.Body}); err != nil {
+				break
+			}
+		}
+		require.Nil(t, stream.CloseSend())
+		for {
+			if _, err = stream.Recv(); err != nil {
+				break
+			}
+		}
+		if errors.Is(err, goio.EOF) {
+			return nil
+		}
+		return err
+	}
+	assert.Equal(t, codes.PermissionDenied, status.Code(put("reader-key", "TEST/1Min/OHLC")))
+	assert.Equal(t, codes.PermissionDenied, status.Code(put("writer-key", "USDJPY/1Min/OHLC")))
+	require.Nil(t, put("writer-key", "TEST/1Min/OHLC"))
+
+	ticket, err := json.Marshal(&frontend.FlightCommand{Destination: "TEST/1Min/OHLC"})
+	require.Nil(t, err)
+	key, batches = doGet(t, client, &flight.Ticket{Ticket: ticket})
+	assert.Equal(t, "TEST/1Min/OHLC", key.GetItemKey())
+	require.Len(t, batches, 1)
+	assert.Equal(t, epochs, batches[0].GetEpoch())
+
+	// The flights are the readable buckets matching the criteria
+	list, err := client.ListFlights(withKey("reader-key"), &flight.Criteria{Expression: []byte("TEST/*/*")})
+	require.Nil(t, err)
+	var flights []string
+	for {
+		info, err := list.Recv()
+		if errors.Is(err, goio.EOF) {
+			break
+		}
+		require.Nil(t, err)
+		flights = append(flights, info.FlightDescriptor.Path...)
+	}
+	assert.Equal(t, []string{"TEST/1Min/OHLC"}, flights)
+
+	// The flight of an unknown bucket fails
+	descriptor = &flight.FlightDescriptor{Type: flight.FlightDescriptor_PATH, Path: []string{"NONE", "1Min", "OHLC"}}
+	_, err = client.GetFlightInfo(withKey("reader-key"), descriptor)
+	assert.NotNil(t, err)
+}
@@ -2,7 +2,9 @@ package frontend
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	goio "io"
 	"math"
 	"strings"
 	"sync/atomic"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/alpacahq/marketstore/v4/catalog"
 	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
 	"github.com/alpacahq/marketstore/v4/proto"
 	"github.com/alpacahq/marketstore/v4/sqlparser"
 	"github.com/alpacahq/marketstore/v4/utils"
@@ -56,38 +59,27 @@ func NewGRPCService(rootDir string, catDir *catalog.Directory, aggRunner *sqlpar
 	}
 }
 
-func (s GRPCService) Query(_ context.Context, reqs *proto.MultiQueryRequest) (*proto.MultiQueryResponse, error) {
+func (s GRPCService) Query(ctx context.Context, reqs *proto.MultiQueryRequest) (*proto.MultiQueryResponse, error) {
+	principal := auth.FromContext(ctx)
 	response := proto.MultiQueryResponse{}
 	response.Version = utils.GitHash
 	response.Timezone = utils.InstanceConfig.Timezone.String()
 	for _, req := range reqs.Requests {
+		if err := checkFormat(req.Format); err != nil {
+			return nil, err
+		}
 		switch req.IsSqlStatement {
 		case true:
-			queryTree, err := sqlparser.BuildQueryTree(req.SqlStatement)
-			if err != nil {
-				return nil, err
-			}
-			es, err := sqlparser.NewExecutableStatement(queryTree)
-			if err != nil {
-				return nil, err
-			}
-			cs, err := es.Materialize(s.aggRunner, s.catalogDir)
-			if err != nil {
-				return nil, err
-			}
-			nds, err := io.NewNumpyDataset(cs)
+			cs, err := materializeSQL(principal, s.aggRunner, s.catalogDir, req.SqlStatement)
 			if err != nil {
 				return nil, err
 			}
 			tbk := io.NewTimeBucketKeyFromString(req.SqlStatement + ":SQL")
-			nmds, err := io.NewNumpyMultiDataset(nds, *tbk)
+			resp, err := newProtoQueryResponse(io.ColumnSeriesMap{*tbk: cs}, req.Format)
 			if err != nil {
 				return nil, err
 			}
-			response.Responses = append(response.Responses,
-				&proto.QueryResponse{
-					Result: ToProtoNumpyMultiDataSet(nmds),
-				})
+			response.Responses = append(response.Responses, resp)
 
 		case false:
 			/*
@@ -110,7 +102,12 @@ func (s GRPCService) Query(_ context.Context, reqs *proto.MultiQueryRequest) (*p
 			if len(Timeframe) == 0 || len(RecordFormat) == 0 || len(Symbols) == 0 {
 				return nil, fmt.Errorf("destinations must have a Symbol, Timeframe and AttributeGroup, have: %s",
 					dest.String())
-			} else if len(Symbols) == 1 && Symbols[0] == "*" {
+			}
+			dest, err := readableDestination(principal, s.catalogDir, dest)
+			if err != nil {
+				return nil, err
+			}
+			if dest.GetItemInCategory("Symbol") == "*" {
 				// replace the * "symbol" with a list all known actual symbols
 				allSymbols := s.catalogDir.GatherCategoriesAndItems()["Symbol"]
 				symbols := make([]string, 0, len(allSymbols))
@@ -161,48 +158,54 @@ func (s GRPCService) Query(_ context.Context, reqs *proto.MultiQueryRequest) (*p
 			}
 
 			/*
-				Separate each TimeBucket from the result and compose a NumpyMultiDataset
+				Separate each TimeBucket from the result and compose a NumpyMultiDataset, or an Arrow stream per bucket
 			*/
-			var nmds *io.NumpyMultiDataset
-			for tbk, cs := range csm {
-				nds, err := io.NewNumpyDataset(cs)
-				if err != nil {
-					return nil, err
-				}
-				if nmds == nil {
-					nmds, err = io.NewNumpyMultiDataset(nds, tbk)
-					if err != nil {
-						return nil, err
-					}
-				} else {
-					err := nmds.Append(cs, tbk)
-					if err != nil {
-						return nil, fmt.Errorf("add tbk=%s to NumpyMultiDataSet: %w", tbk, err)
-					}
-				}
+			resp, err := newProtoQueryResponse(csm, req.Format)
+			if err != nil {
+				return nil, err
 			}
-
-			/*
-				Append the NumpyMultiDataset to the MultiResponse
-			*/
-
-			response.Responses = append(response.Responses,
-				&proto.QueryResponse{
-					Result: ToProtoNumpyMultiDataSet(nmds),
-				})
+			response.Responses = append(response.Responses, resp)
 		}
 	}
 	return &response, nil
 }
 
+// newProtoQueryResponse returns the response of a result in the format of the query.
+func newProtoQueryResponse(csm io.ColumnSeriesMap, format string) (*proto.QueryResponse, error) {
+	if format == FormatArrow {
+		streams, err := toArrowStreams(csm)
+		if err != nil {
+			return nil, err
+		}
+		return &proto.QueryResponse{Arrow: streams}, nil
+	}
+	nmds, err := toNumpyMultiDataset(csm)
+	if err != nil {
+		return nil, err
+	}
+	if nmds == nil {
+		return &proto.QueryResponse{}, nil
+	}
+	return &proto.QueryResponse{Result: ToProtoNumpyMultiDataSet(nmds)}, nil
+}
+
 func (s GRPCService) Write(ctx context.Context, reqs *proto.MultiWriteRequest) (*proto.MultiServerResponse, error) {
+	principal := auth.FromContext(ctx)
 	response := proto.MultiServerResponse{}
 	for _, req := range reqs.Requests {
-		csm, err := ToNumpyMultiDataSet(req.Data).ToColumnSeriesMap()
+		var nmds *io.NumpyMultiDataset
+		if req.Data != nil {
+			nmds = ToNumpyMultiDataSet(req.Data)
+		}
+		csm, err := writeRequestSeries(nmds, req.Arrow)
 		if err != nil {
 			appendResponse(&response, err)
 			continue
 		}
+		if err = authorizeWrite(principal, csm); err != nil {
+			appendResponse(&response, err)
+			continue
+		}
 		if err = executor.WriteCSM(csm, req.IsVariableLength); err != nil {
 			appendResponse(&response, err)
 			continue
@@ -213,6 +216,112 @@ func (s GRPCService) Write(ctx context.Context, reqs *proto.MultiWriteRequest) (
 	return &response, nil
 }
 
+// maxWriteStreamGroup bounds the number of batches of a write stream coalesced in a transaction group.
+const maxWriteStreamGroup = 1000
+
+/*
+WriteStream writes the batches sent on the stream. The batches received while the previous ones are written
+are coalesced and committed together to the WAL, and each group is acknowledged by a response with the range
+of its batches, the ID of the transaction group committing them and the errors of the batches that are not
+written. The stream ends once the batches sent before the client closed its side are acknowledged.
+*/
+func (s GRPCService) WriteStream(stream proto.Marketstore_WriteStreamServer) error {
+	principal := auth.FromContext(stream.Context())
+	requests := make(chan *proto.WriteStreamRequest, maxWriteStreamGroup)
+	recvErr := make(chan error, 1)
+	go func() {
+		defer close(requests)
+		for {
+			req, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			select {
+			case requests <- req:
+			case <-stream.Context().Done():
+				recvErr <- stream.Context().Err()
+				return
+			}
+		}
+	}()
+
+	var sequence uint64
+	for req := range requests {
+		group := []*proto.WriteStreamRequest{req}
+	coalesce:
+		for len(group) < maxWriteStreamGroup {
+			select {
+			case req, ok := <-requests:
+				if !ok {
+					break coalesce
+				}
+				group = append(group, req)
+			default:
+				break coalesce
+			}
+		}
+		resp, err := s.writeStreamGroup(principal, sequence+1, group)
+		if err != nil {
+			return err
+		}
+		if err = stream.Send(resp); err != nil {
+			return err
+		}
+		sequence = resp.LastSequence
+	}
+	if err := <-recvErr; !errors.Is(err, goio.EOF) {
+		return err
+	}
+	return nil
+}
+
+// writeStreamGroup writes a group of batches of a stream numbered from first, and returns its acknowledgement.
+func (s GRPCService) writeStreamGroup(principal *auth.Principal, first uint64, group []*proto.WriteStreamRequest,
+) (*proto.WriteStreamResponse, error) {
+	errs := make([]error, len(group))
+	batches := make([]executor.WriteBatch, 0, len(group))
+	indexes := make([]int, 0, len(group))
+	for i, req := range group {
+		if req.Request == nil {
+			errs[i] = fmt.Errorf("write stream request without data")
+			continue
+		}
+		var nmds *io.NumpyMultiDataset
+		if req.Request.Data != nil {
+			nmds = ToNumpyMultiDataSet(req.Request.Data)
+		}
+		csm, err := writeRequestSeries(nmds, req.Request.Arrow)
+		if err == nil {
+			err = authorizeWrite(principal, csm)
+		}
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		batches = append(batches, executor.WriteBatch{CSM: csm, IsVariableLength: req.Request.IsVariableLength})
+		indexes = append(indexes, i)
+	}
+
+	resp := &proto.WriteStreamResponse{FirstSequence: first, LastSequence: first + uint64(len(group)) - 1}
+	if len(batches) != 0 {
+		tgID, writeErrs, err := s.writer.WriteGroup(batches)
+		if err != nil {
+			return nil, err
+		}
+		for i, err := range writeErrs {
+			errs[indexes[i]] = err
+		}
+		resp.Tgid = tgID
+	}
+	for i, err := range errs {
+		if err != nil {
+			resp.Errors = append(resp.Errors, &proto.WriteStreamError{Sequence: first + uint64(i), Error: err.Error()})
+		}
+	}
+	return resp, nil
+}
+
 func ToNumpyMultiDataSet(p *proto.NumpyMultiDataset) *io.NumpyMultiDataset {
 	return &io.NumpyMultiDataset{
 		NumpyDataset: io.NumpyDataset{
@@ -258,11 +367,11 @@ func convertIntMap(m map[string]int) map[string]int32 {
 // NewDataShapeVector returns a new array of io.DataShape for the given array of proto.DataShape inputs.
 func NewDataShapeVector(dataShapes []*proto.DataShape) (dsv []io.DataShape, err error) {
 	for _, ds := range dataShapes {
-		elemType, ok := io.TypeStrToElemType(ds.Type)
+		shape, ok := io.TypeStrToDataShape(ds.Name, ds.Type)
 		if !ok {
 			return nil, fmt.Errorf("not supported data type: %v", ds.Type)
 		}
-		dsv = append(dsv, io.DataShape{Name: ds.Name, Type: elemType})
+		dsv = append(dsv, shape)
 	}
 	return dsv, err
 }
@@ -289,21 +398,23 @@ func (s GRPCService) ListSymbols(ctx context.Context, req *proto.ListSymbolsRequ
 		return nil, errNotQueryable
 	}
 
+	principal := auth.FromContext(ctx)
 	switch req.Format {
 	case proto.ListSymbolsRequest_SYMBOL:
-		for symbol := range s.catalogDir.GatherCategoriesAndItems()["Symbol"] {
+		for symbol := range readableSymbols(principal, s.catalogDir) {
 			response.Results = append(response.Results, symbol)
 		}
 	case proto.ListSymbolsRequest_TIME_BUCKET_KEY:
 		fallthrough
 	default:
-		response.Results = catalog.ListTimeBucketKeyNames(s.catalogDir)
+		response.Results = readableKeys(principal, catalog.ListTimeBucketKeyNames(s.catalogDir))
 	}
 
 	return &response, nil
 }
 
 func (s GRPCService) Create(ctx context.Context, req *proto.MultiCreateRequest) (*proto.MultiServerResponse, error) {
+	principal := auth.FromContext(ctx)
 	response := proto.MultiServerResponse{}
 
 	for _, req := range req.Requests {
@@ -313,6 +424,10 @@ func (s GRPCService) Create(ctx context.Context, req *proto.MultiCreateRequest)
 			appendResponse(&response, err)
 			continue
 		}
+		if err := principal.Authorize(auth.Create, tbk.GetItemKey()); err != nil {
+			appendResponse(&response, err)
+			continue
+		}
 
 		switch req.RowType {
 		case "fixed", "variable":
@@ -347,9 +462,45 @@ func (s GRPCService) Create(ctx context.Context, req *proto.MultiCreateRequest)
 	return &response, nil
 }
 
+func (s GRPCService) Delete(ctx context.Context, req *proto.MultiDeleteRequest) (*proto.MultiServerResponse, error) {
+	response := proto.MultiServerResponse{}
+	for _, req := range req.Requests {
+		appendResponse(&response, deleteRange(auth.FromContext(ctx), s.catalogDir, s.writer, req.Key,
+			time.Unix(req.EpochStart, req.EpochStartNanos), time.Unix(req.EpochEnd, req.EpochEndNanos)))
+	}
+	return &response, nil
+}
+
+func (s GRPCService) AlterBucket(ctx context.Context, req *proto.MultiAlterBucketRequest,
+) (*proto.MultiServerResponse, error) {
+	response := proto.MultiServerResponse{}
+	for _, req := range req.Requests {
+		alterReq := &AlterBucketRequest{Key: req.Key, DropColumnNames: req.DropColumns}
+		for _, ds := range req.AddColumns {
+			alterReq.AddColumnNames = append(alterReq.AddColumnNames, ds.Name)
+			alterReq.AddColumnTypes = append(alterReq.AddColumnTypes, ds.Type)
+		}
+		for _, ds := range req.RetypeColumns {
+			alterReq.RetypeColumnNames = append(alterReq.RetypeColumnNames, ds.Name)
+			alterReq.RetypeColumnTypes = append(alterReq.RetypeColumnTypes, ds.Type)
+		}
+		appendResponse(&response, alterBucket(auth.FromContext(ctx), s.writer, alterReq))
+	}
+	return &response, nil
+}
+
+func (s GRPCService) Backup(ctx context.Context, req *proto.BackupRequest) (*proto.BackupResponse, error) {
+	resp, err := backup(auth.FromContext(ctx), s.writer, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &proto.BackupResponse{Path: resp.Path, Tgid: resp.TGID, Files: int64(resp.Files), Bytes: resp.Bytes}, nil
+}
+
 func (s GRPCService) Destroy(ctx context.Context, req *proto.MultiKeyRequest) (*proto.MultiServerResponse, error) {
 	errorString := "key \"%s\" is not in proper format, should be like: TSLA/1Min/OHLCV"
 
+	principal := auth.FromContext(ctx)
 	response := proto.MultiServerResponse{}
 	for _, req := range req.Requests {
 		// Construct a time bucket key from the input string
@@ -365,6 +516,10 @@ func (s GRPCService) Destroy(ctx context.Context, req *proto.MultiKeyRequest) (*
 			appendResponse(&response, err)
 			continue
 		}
+		if err := principal.Authorize(auth.Destroy, tbk.GetItemKey()); err != nil {
+			appendResponse(&response, err)
+			continue
+		}
 
 		err := s.catalogDir.RemoveTimeBucket(tbk)
 		if err != nil {
@@ -384,3 +539,83 @@ func (s GRPCService) ServerVersion(ctx context.Context, req *proto.ServerVersion
 		Version: utils.GitHash,
 	}, nil
 }
+
+/*
+QueryStream sends the rows of a query in batches, so that large queries hold
+neither the server memory nor the message size limit. The client can stop
+reading at any time, and resume later with the cursor of the last batch.
+*/
+func (s GRPCService) QueryStream(req *proto.QueryStreamRequest, stream proto.Marketstore_QueryStreamServer) error {
+	if atomic.LoadUint32(&Queryable) == 0 {
+		return errNotQueryable
+	}
+	if req.Request == nil {
+		return fmt.Errorf("no query in the stream request")
+	}
+	streamReq := &QueryStreamRequest{
+		Request:   fromProtoQueryRequest(req.Request),
+		BatchSize: int(req.BatchSize),
+	}
+	if req.Cursor != nil {
+		streamReq.Cursor = &QueryCursor{
+			Key:         req.Cursor.Key,
+			Epoch:       req.Cursor.Epoch,
+			Nanoseconds: req.Cursor.Nanoseconds,
+			Ordinal:     req.Cursor.Ordinal,
+		}
+	}
+	pager, err := newRequestPager(auth.FromContext(stream.Context()), s.catalogDir, streamReq)
+	if err != nil {
+		return err
+	}
+
+	for {
+		// Stop reading once the client is gone
+		if err = stream.Context().Err(); err != nil {
+			return err
+		}
+		resp, done, err := nextStreamResponse(pager)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		err = stream.Send(&proto.QueryStreamResponse{
+			Result: ToProtoNumpyMultiDataSet(resp.Result),
+			Cursor: &proto.QueryCursor{
+				Key:         resp.Cursor.Key,
+				Epoch:       resp.Cursor.Epoch,
+				Nanoseconds: resp.Cursor.Nanoseconds,
+				Ordinal:     resp.Cursor.Ordinal,
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// fromProtoQueryRequest converts a query, an EpochEnd of 0 leaves the end of the time range open.
+func fromProtoQueryRequest(req *proto.QueryRequest) QueryRequest {
+	q := QueryRequest{
+		IsSQLStatement:  req.IsSqlStatement,
+		SQLStatement:    req.SqlStatement,
+		Destination:     req.Destination,
+		KeyCategory:     req.KeyCategory,
+		EpochStart:      &req.EpochStart,
+		EpochStartNanos: &req.EpochStartNanos,
+		LimitFromStart:  &req.LimitFromStart,
+		Columns:         req.Columns,
+		Functions:       req.Functions,
+	}
+	if req.EpochEnd != 0 {
+		q.EpochEnd = &req.EpochEnd
+		q.EpochEndNanos = &req.EpochEndNanos
+	}
+	if req.LimitRecordCount != 0 {
+		limitRecordCount := int(req.LimitRecordCount)
+		q.LimitRecordCount = &limitRecordCount
+	}
+	return q
+}
@@ -0,0 +1,664 @@
+package frontend
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	goio "io"
+	"math"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/sqlparser"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+// maxWriteBodySize bounds the size of the body of a write, after its decompression.
+const maxWriteBodySize = 256 << 20
+
+// WriteError is an error of the /write endpoint, on a line of the body or on all the rows of a bucket.
+type WriteError struct {
+	Line  int    `json:"line,omitempty"`
+	Key   string `json:"key,omitempty"`
+	Error string `json:"error"`
+}
+
+// WriteLinesResponse is the JSON response of the /write endpoint.
+type WriteLinesResponse struct {
+	Written int          `json:"written"` // The number of rows written
+	Errors  []WriteError `json:"errors,omitempty"`
+}
+
+/*
+WriteHandler writes the rows of a body in the InfluxDB line protocol or in CSV, for the clients that don't
+build a NumpyMultiDataset, e.g. Telegraf or shell scripts. The rows are written to their buckets with
+WriteCSM, their columns converted to the ones of the bucket. The buckets that don't exist are created with
+the columns inferred from the rows if autoCreate is set, nullable if some rows miss a column.
+
+The query parameters of the request are:
+
+	format              "influx" (the default) or "csv", also set by a text/csv Content-Type
+	timeframe           the Timeframe of the buckets, 1Min by default
+	precision           the unit of the integer timestamps: ns (the default of influx), us, ms or s (of csv)
+	is_variable_length  true to create variable length buckets, which keep the Nanoseconds of the rows
+	symbol_tag          influx: the tag of the Symbol, "symbol" by default
+	symbol              csv: the Symbol of the rows, if the file has no Symbol column
+	attribute_group     csv: the AttributeGroup of the rows
+
+A CSV body starts with the header row of the column names, with an Epoch column of integer timestamps or
+RFC 3339 times, and an optional Nanoseconds column. An empty cell is a NULL.
+
+The response is a WriteLinesResponse, with the errors of the lines and buckets that were not written: its
+status is 400 if there are any, the other rows are still written.
+*/
+type WriteHandler struct {
+	catalogDir *catalog.Directory
+	writer     Writer
+	autoCreate bool
+}
+
+func NewWriteHandler(catDir *catalog.Directory, writer Writer, autoCreate bool) *WriteHandler {
+	return &WriteHandler{catalogDir: catDir, writer: writer, autoCreate: autoCreate}
+}
+
+func (h *WriteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST the rows in the line protocol or in CSV", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	isCSV := query.Get("format") == "csv" ||
+		query.Get("format") == "" && strings.HasPrefix(r.Header.Get("Content-Type"), "text/csv")
+	if format := query.Get("format"); format != "" && format != "csv" && format != "influx" {
+		http.Error(w, fmt.Sprintf("unknown format %q, use influx or csv", format), http.StatusBadRequest)
+		return
+	}
+	opts, err := parseIngestOptions(query, isCSV)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body := r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err2 := gzip.NewReader(r.Body)
+		if err2 != nil {
+			http.Error(w, fmt.Sprintf("decompress body: %v", err2), http.StatusBadRequest)
+			return
+		}
+		defer gz.Close()
+		body = gz
+	}
+	body = http.MaxBytesReader(w, body, maxWriteBodySize)
+
+	var (
+		rows []*ingestRow
+		errs []WriteError
+	)
+	if isCSV {
+		rows, errs = parseCSV(body, opts)
+	} else {
+		rows, errs = parseLineProtocol(body, opts, time.Now())
+	}
+	resp := h.write(principalOf(r), rows, opts.isVariableLength)
+	resp.Errors = append(errs, resp.Errors...)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("marketstore-version", utils.GitHash)
+	if len(resp.Errors) != 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	if err = json.NewEncoder(w).Encode(resp); err != nil {
+		log.Debug("write response not sent: %v", err)
+	}
+}
+
+// ingestOptions are the query parameters of a write.
+type ingestOptions struct {
+	timeframe        string
+	precision        time.Duration // the unit of the integer timestamps
+	isVariableLength bool
+	symbolTag        string
+	symbol           string
+	attributeGroup   string
+}
+
+func parseIngestOptions(query url.Values, isCSV bool) (*ingestOptions, error) {
+	opts := &ingestOptions{
+		timeframe:      "1Min",
+		precision:      time.Nanosecond,
+		symbolTag:      "symbol",
+		symbol:         query.Get("symbol"),
+		attributeGroup: query.Get("attribute_group"),
+	}
+	if isCSV {
+		opts.precision = time.Second
+	}
+	if tf := query.Get("timeframe"); tf != "" {
+		if utils.TimeframeFromString(tf) == nil {
+			return nil, fmt.Errorf("invalid timeframe %q", tf)
+		}
+		opts.timeframe = tf
+	}
+	if precision := query.Get("precision"); precision != "" {
+		units := map[string]time.Duration{
+			"ns": time.Nanosecond, "n": time.Nanosecond, "us": time.Microsecond, "u": time.Microsecond,
+			"ms": time.Millisecond, "s": time.Second,
+		}
+		unit, ok := units[precision]
+		if !ok {
+			return nil, fmt.Errorf("invalid precision %q, use ns, us, ms or s", precision)
+		}
+		opts.precision = unit
+	}
+	if v := query.Get("is_variable_length"); v != "" {
+		var err error
+		if opts.isVariableLength, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("invalid is_variable_length %q", v)
+		}
+	}
+	if tag := query.Get("symbol_tag"); tag != "" {
+		opts.symbolTag = tag
+	}
+	if isCSV && opts.attributeGroup == "" {
+		return nil, fmt.Errorf("a CSV write needs the attribute_group parameter")
+	}
+	return opts, nil
+}
+
+// invalidKeyItemChars are the separators of the bucket keys and the characters of the symbol patterns.
+const invalidKeyItemChars = "/:" + sqlparser.SymbolPatternChars
+
+// bucketKey returns the item key of the bucket of a symbol and an attribute group.
+func (o *ingestOptions) bucketKey(symbol, attributeGroup string) (string, error) {
+	for _, item := range []string{symbol, attributeGroup} {
+		if item == "" || strings.ContainsAny(item, invalidKeyItemChars) {
+			return "", fmt.Errorf("invalid bucket key item %q", item)
+		}
+	}
+	return symbol + "/" + o.timeframe + "/" + attributeGroup, nil
+}
+
+// epoch returns the seconds and the nanoseconds of an integer timestamp in the precision of the options.
+func (o *ingestOptions) epoch(ts int64) (epoch int64, nanoseconds int32) {
+	perSecond := int64(time.Second / o.precision)
+	epoch, rem := ts/perSecond, ts%perSecond
+	if rem < 0 {
+		epoch, rem = epoch-1, rem+perSecond
+	}
+	return epoch, int32(rem * int64(o.precision))
+}
+
+// textValue is the text of a CSV cell, parsed after the type of its column.
+type textValue string
+
+/*
+parseCSV returns the rows of a CSV body with a header row. The Epoch column has the timestamps, the Symbol
+column, if any, the Symbols instead of the one of the options, and the Nanoseconds column, if any, the
+sub-second time of the rows.
+*/
+func parseCSV(r goio.Reader, opts *ingestOptions) (rows []*ingestRow, errs []WriteError) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, []WriteError{{Line: 1, Error: fmt.Sprintf("read the header row: %v", err)}}
+	}
+	epochIndex, symbolIndex, nanosecondsIndex := -1, -1, -1
+	for i, name := range header {
+		switch name {
+		case "Epoch":
+			epochIndex = i
+		case "Symbol":
+			symbolIndex = i
+		case "Nanoseconds":
+			nanosecondsIndex = i
+		}
+	}
+	if epochIndex < 0 {
+		return nil, []WriteError{{Line: 1, Error: "the header row has no Epoch column"}}
+	}
+	if symbolIndex < 0 && opts.symbol == "" {
+		return nil, []WriteError{{Line: 1, Error: "the header row has no Symbol column, and no symbol is set"}}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, goio.EOF) {
+			return rows, errs
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			errs = append(errs, WriteError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+			continue
+		} else if err != nil {
+			return rows, append(errs, WriteError{Error: fmt.Sprintf("read body: %v", err)})
+		}
+		line, _ := reader.FieldPos(0)
+		row, err := csvRow(header, record, epochIndex, symbolIndex, nanosecondsIndex, opts)
+		if err != nil {
+			errs = append(errs, WriteError{Line: line, Error: err.Error()})
+			continue
+		}
+		row.line = line
+		rows = append(rows, row)
+	}
+}
+
+func csvRow(header, record []string, epochIndex, symbolIndex, nanosecondsIndex int, opts *ingestOptions,
+) (*ingestRow, error) {
+	row := &ingestRow{}
+	symbol := opts.symbol
+	if symbolIndex >= 0 {
+		symbol = record[symbolIndex]
+	}
+	key, err := opts.bucketKey(symbol, opts.attributeGroup)
+	if err != nil {
+		return nil, err
+	}
+	row.key = key
+
+	text := record[epochIndex]
+	if ts, err2 := strconv.ParseInt(text, 10, 64); err2 == nil {
+		row.epoch, row.nanoseconds = opts.epoch(ts)
+	} else if t, err2 := time.Parse(time.RFC3339Nano, text); err2 == nil {
+		row.epoch, row.nanoseconds = t.Unix(), int32(t.Nanosecond())
+	} else {
+		return nil, fmt.Errorf("invalid Epoch %q, expected an integer timestamp or an RFC 3339 time", text)
+	}
+	if nanosecondsIndex >= 0 {
+		ns, err2 := strconv.ParseInt(record[nanosecondsIndex], 10, 32)
+		if err2 != nil || ns < 0 || ns >= int64(time.Second) {
+			return nil, fmt.Errorf("invalid Nanoseconds %q", record[nanosecondsIndex])
+		}
+		row.nanoseconds = int32(ns)
+	}
+
+	for i, name := range header {
+		if i == epochIndex || i == symbolIndex || i == nanosecondsIndex || record[i] == "" {
+			continue
+		}
+		row.add(name, textValue(record[i]))
+	}
+	return row, nil
+}
+
+// write writes the rows to their buckets, in the order of their first rows.
+func (h *WriteHandler) write(principal *auth.Principal, rows []*ingestRow, isVariableLength bool,
+) *WriteLinesResponse {
+	var keys []string
+	rowsByKey := map[string][]*ingestRow{}
+	for _, row := range rows {
+		if _, ok := rowsByKey[row.key]; !ok {
+			keys = append(keys, row.key)
+		}
+		rowsByKey[row.key] = append(rowsByKey[row.key], row)
+	}
+
+	resp := &WriteLinesResponse{}
+	for _, key := range keys {
+		written, errs := h.writeBucket(principal, io.NewTimeBucketKey(key), rowsByKey[key], isVariableLength)
+		resp.Written += written
+		resp.Errors = append(resp.Errors, errs...)
+	}
+	return resp
+}
+
+/*
+writeBucket writes the rows of a bucket. The rows whose values don't fit the columns of the bucket are not
+written, the other ones are written together.
+*/
+func (h *WriteHandler) writeBucket(principal *auth.Principal, tbk *io.TimeBucketKey, rows []*ingestRow,
+	isVariableLength bool,
+) (written int, errs []WriteError) {
+	bucketError := func(err error) []WriteError {
+		return append(errs, WriteError{Line: rows[0].line, Key: tbk.GetItemKey(), Error: err.Error()})
+	}
+	if err := principal.Authorize(auth.Write, tbk.GetItemKey()); err != nil {
+		return 0, bucketError(err)
+	}
+
+	var (
+		dsv      []io.DataShape
+		nullable = true
+	)
+	if tbi, err := h.catalogDir.GetLatestTimeBucketInfoFromKey(tbk); err == nil {
+		isVariableLength = tbi.GetRecordType() == io.VARIABLE
+		nullable = io.IsNullable(tbi.GetDataShapes())
+		for _, ds := range tbi.GetDataShapes() {
+			if ds.Name != io.NullsColumn {
+				dsv = append(dsv, ds)
+			}
+		}
+	} else {
+		if !h.autoCreate {
+			return 0, bucketError(fmt.Errorf("bucket %s does not exist", tbk.GetItemKey()))
+		}
+		if err = principal.Authorize(auth.Create, tbk.GetItemKey()); err != nil {
+			return 0, bucketError(err)
+		}
+		dsv = inferDataShapes(rows)
+	}
+
+	columns := make([]reflect.Value, len(dsv))
+	nulls := make([][]bool, len(dsv))
+	index := map[string]int{}
+	for i, ds := range dsv {
+		columns[i] = reflect.MakeSlice(reflect.SliceOf(ds.Type.TypeOf()), 0, len(rows))
+		index[ds.Name] = i
+	}
+	var (
+		epochs      []int64
+		nanoseconds []int32
+		hasNulls    bool
+	)
+	for _, row := range rows {
+		values, err := rowValues(row, dsv, index, nullable)
+		if err != nil {
+			errs = append(errs, WriteError{Line: row.line, Error: err.Error()})
+			continue
+		}
+		for i, v := range values {
+			isNull := v == nil
+			if isNull {
+				columns[i] = reflect.Append(columns[i], reflect.Zero(dsv[i].Type.TypeOf()))
+				hasNulls = true
+			} else {
+				columns[i] = reflect.Append(columns[i], reflect.ValueOf(v))
+			}
+			nulls[i] = append(nulls[i], isNull)
+		}
+		epochs = append(epochs, row.epoch)
+		nanoseconds = append(nanoseconds, row.nanoseconds)
+	}
+	if len(epochs) == 0 {
+		return 0, errs
+	}
+
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", epochs)
+	for i, ds := range dsv {
+		if ds.Type == io.DECIMAL64 {
+			cs.AddDecimalColumn(ds.Name, columns[i].Interface().([]io.Decimal64), ds.Scale)
+		} else {
+			cs.AddColumn(ds.Name, columns[i].Interface())
+		}
+	}
+	if hasNulls {
+		for i, ds := range dsv {
+			cs.SetNulls(ds.Name, nulls[i])
+		}
+	}
+	if isVariableLength {
+		cs.AddColumn("Nanoseconds", nanoseconds)
+	}
+	if err := h.writer.WriteCSM(io.ColumnSeriesMap{*tbk: cs}, isVariableLength); err != nil {
+		return 0, bucketError(err)
+	}
+	return len(epochs), errs
+}
+
+// rowValues returns the values of a row converted to the columns dsv, nil for the NULL values.
+func rowValues(row *ingestRow, dsv []io.DataShape, index map[string]int, nullable bool) ([]interface{}, error) {
+	values := make([]interface{}, len(dsv))
+	for j, name := range row.names {
+		if isReservedColumn(name) {
+			return nil, fmt.Errorf("column name %s is reserved", name)
+		}
+		i, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("the bucket has no %s column", name)
+		}
+		if values[i] != nil {
+			return nil, fmt.Errorf("column %s is set twice", name)
+		}
+		v, err := convertValue(row.values[j], dsv[i])
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", name, err)
+		}
+		values[i] = v
+	}
+	if !nullable {
+		for i, v := range values {
+			if v == nil {
+				return nil, fmt.Errorf("column %s is missing, the bucket is not nullable", dsv[i].Name)
+			}
+		}
+	}
+	return values, nil
+}
+
+// isReservedColumn returns true for the names of the columns that are not written from the values of a row.
+func isReservedColumn(name string) bool {
+	return name == "Epoch" || name == "Nanoseconds" || name == io.NullsColumn || strings.HasSuffix(name, io.NullSuffix)
+}
+
+/*
+inferDataShapes returns the columns of a new bucket for its rows, in the order they first appear. A column
+has the type of its values, FLOAT64 if it has both integers and floats, STRING if it has CSV texts that are
+not numbers nor booleans, and the type of its first value otherwise.
+*/
+func inferDataShapes(rows []*ingestRow) []io.DataShape {
+	var names []string
+	values := map[string][]interface{}{}
+	for _, row := range rows {
+		for j, name := range row.names {
+			if isReservedColumn(name) {
+				continue
+			}
+			if _, ok := values[name]; !ok {
+				names = append(names, name)
+			}
+			values[name] = append(values[name], row.values[j])
+		}
+	}
+	dsv := make([]io.DataShape, len(names))
+	for i, name := range names {
+		dsv[i] = io.DataShape{Name: name, Type: inferType(values[name])}
+	}
+	return dsv
+}
+
+func inferType(values []interface{}) io.EnumElementType {
+	counts := map[io.EnumElementType]int{}
+	var texts int
+	for _, v := range values {
+		if text, ok := v.(textValue); ok {
+			texts++
+			counts[textType(string(text))]++
+		} else {
+			counts[valueType(v)]++
+		}
+	}
+	numbers := counts[io.FLOAT64] + counts[io.INT64] + counts[io.UINT64]
+	switch {
+	case numbers == len(values) && counts[io.FLOAT64] != 0:
+		return io.FLOAT64
+	case numbers == len(values) && counts[io.INT64] != 0:
+		return io.INT64
+	case numbers == len(values):
+		return io.UINT64
+	case counts[io.BOOL] == len(values):
+		return io.BOOL
+	case texts == len(values):
+		return io.STRING
+	}
+	if text, ok := values[0].(textValue); ok {
+		return textType(string(text))
+	}
+	return valueType(values[0])
+}
+
+func valueType(v interface{}) io.EnumElementType {
+	switch v.(type) {
+	case float64:
+		return io.FLOAT64
+	case int64:
+		return io.INT64
+	case uint64:
+		return io.UINT64
+	case bool:
+		return io.BOOL
+	default:
+		return io.STRING
+	}
+}
+
+// textType returns the type of the value of a CSV cell.
+func textType(s string) io.EnumElementType {
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return io.INT64
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return io.FLOAT64
+	}
+	if strings.EqualFold(s, "true") || strings.EqualFold(s, "false") {
+		return io.BOOL
+	}
+	return io.STRING
+}
+
+// convertValue returns a value with the Go type of a column, or an error if it doesn't fit the column.
+func convertValue(v interface{}, ds io.DataShape) (interface{}, error) {
+	invalid := func() error { return fmt.Errorf("invalid %s value %v", ds.Type, v) }
+	text, isText := v.(textValue)
+	switch ds.Type {
+	case io.STRING, io.BLOB, io.STRING16:
+		s, ok := v.(string)
+		if isText {
+			s, ok = string(text), true
+		}
+		switch {
+		case !ok:
+			return nil, invalid()
+		case ds.Type == io.BLOB:
+			return []byte(s), nil
+		case ds.Type == io.STRING16:
+			if utf8.RuneCountInString(s) > len([16]rune{}) {
+				return nil, fmt.Errorf("%q is longer than 16 characters", s)
+			}
+			var r [16]rune
+			copy(r[:], []rune(s))
+			return r, nil
+		}
+		return s, nil
+	case io.BOOL:
+		if isText {
+			b, err := strconv.ParseBool(string(text))
+			if err != nil {
+				return nil, invalid()
+			}
+			return b, nil
+		}
+		if b, ok := v.(bool); ok {
+			return b, nil
+		}
+		return nil, invalid()
+	case io.DECIMAL64:
+		var s string
+		switch x := v.(type) {
+		case textValue:
+			s = string(x)
+		case float64:
+			return io.NewDecimal64(x, ds.Scale), nil
+		case int64, uint64:
+			s = fmt.Sprint(x)
+		default:
+			return nil, invalid()
+		}
+		d, err := io.ParseDecimal64(s, ds.Scale)
+		if err != nil {
+			return nil, invalid()
+		}
+		return d, nil
+	}
+
+	typ := ds.Type.TypeOf()
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, ok := floatValue(v)
+		if !ok || typ.Kind() == reflect.Float32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0) {
+			return nil, invalid()
+		}
+		return reflect.ValueOf(f).Convert(typ).Interface(), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := intValue(v)
+		if !ok || reflect.Zero(typ).OverflowInt(i) {
+			return nil, invalid()
+		}
+		return reflect.ValueOf(i).Convert(typ).Interface(), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, ok := uintValue(v)
+		if !ok || reflect.Zero(typ).OverflowUint(u) {
+			return nil, invalid()
+		}
+		return reflect.ValueOf(u).Convert(typ).Interface(), nil
+	}
+	return nil, fmt.Errorf("unsupported column type %s", ds.Type)
+}
+
+func floatValue(v interface{}) (float64, bool) {
+	switch x := v.(type) {
+	case float64:
+		return x, true
+	case int64:
+		return float64(x), true
+	case uint64:
+		return float64(x), true
+	case textValue:
+		f, err := strconv.ParseFloat(string(x), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// intValue returns the integer of a value, the floats must be integral.
+func intValue(v interface{}) (int64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return x, true
+	case uint64:
+		return int64(x), x <= math.MaxInt64
+	case float64:
+		return int64(x), x == math.Trunc(x) && x >= math.MinInt64 && x < math.MaxInt64
+	case textValue:
+		if i, err := strconv.ParseInt(string(x), 10, 64); err == nil {
+			return i, true
+		}
+		if f, ok := floatValue(x); ok {
+			return intValue(f)
+		}
+	}
+	return 0, false
+}
+
+// uintValue returns the unsigned integer of a value, the floats must be integral.
+func uintValue(v interface{}) (uint64, bool) {
+	switch x := v.(type) {
+	case uint64:
+		return x, true
+	case int64:
+		return uint64(x), x >= 0
+	case float64:
+		return uint64(x), x == math.Trunc(x) && x >= 0 && x < math.MaxUint64
+	case textValue:
+		if u, err := strconv.ParseUint(string(x), 10, 64); err == nil {
+			return u, true
+		}
+		if f, ok := floatValue(x); ok {
+			return uintValue(f)
+		}
+	}
+	return 0, false
+}
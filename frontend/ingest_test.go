@@ -0,0 +1,217 @@
+package frontend_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// postWrite posts a body to the write handler, and returns the status and the response.
+func postWrite(t *testing.T, h http.Handler, target, contentType, body string,
+) (int, *frontend.WriteLinesResponse) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer writer-key")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	var resp frontend.WriteLinesResponse
+	if rec.Code == http.StatusOK || rec.Code == http.StatusBadRequest && strings.HasPrefix(rec.Body.String(), "{") {
+		require.Nil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	}
+	return rec.Code, &resp
+}
+
+// queryAll returns all the rows of a bucket.
+func queryAll(t *testing.T, q frontend.QueryInterface, key string) *io.ColumnSeries {
+	t.Helper()
+	tbk := io.NewTimeBucketKey(key)
+	csm, err := q.ExecuteQuery(tbk, time.Unix(0, 0), time.Unix(math.MaxInt32, 0), 0, false, nil)
+	require.Nil(t, err)
+	return csm[*tbk]
+}
+
+func TestWriteHandlerLineProtocol(t *testing.T) {
+	tearDown, _, metadata, writer, q := setup(t, "TestWriteHandlerLineProtocol")
+	defer tearDown()
+	h := frontend.NewWriteHandler(metadata.CatalogDir, writer, true)
+
+	body := `# the trades of AAPL and MSFT
+trades,symbol=AAPL,exchange=Q price=131.5,size=100i,odd=false 1600000000123456789
+trades,symbol=AAPL,exchange=Q price=131.52,size=50i 1600000001000000000
+
+trades,symbol=MSFT,exchange=N price=214.1,size=10i,note="a \"big\" one" 1600000000500000000
+trades,exchange=Q price=1 1600000002000000000
+trades,symbol=AAPL price=oops 1600000002000000000
+trades,symbol=AAPL,exchange=Q price=131.6,size="big" 1600000003000000000`
+	code, resp := postWrite(t, h, "/write?timeframe=1Sec&is_variable_length=true", "", body)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 3, resp.Written)
+	require.Len(t, resp.Errors, 3)
+	assert.Equal(t, 6, resp.Errors[0].Line)
+	assert.Equal(t, "no symbol tag", resp.Errors[0].Error)
+	assert.Equal(t, 7, resp.Errors[1].Line)
+	assert.Equal(t, 8, resp.Errors[2].Line)
+	assert.Contains(t, resp.Errors[2].Error, "column size")
+
+	// The bucket created from the lines is nullable, the missing field is NULL
+	cs := queryAll(t, q, "AAPL/1Sec/trades")
+	require.NotNil(t, cs)
+	assert.Equal(t, []int64{1600000000, 1600000001}, cs.GetEpoch())
+	assert.Equal(t, []int32{123456789, 0}, cs.GetColumn("Nanoseconds"))
+	assert.Equal(t, []float64{131.5, 131.52}, cs.GetColumn("price"))
+	assert.Equal(t, []int64{100, 50}, cs.GetColumn("size"))
+	assert.Equal(t, []string{"Q", "Q"}, cs.GetColumn("exchange"))
+	assert.Equal(t, []bool{false, true}, cs.Nulls("odd"))
+	cs = queryAll(t, q, "MSFT/1Sec/trades")
+	require.NotNil(t, cs)
+	assert.Equal(t, []string{`a "big" one`}, cs.GetColumn("note"))
+
+	// The lines of an existing bucket are converted to its columns
+	code, resp = postWrite(t, h, "/write?precision=s&symbol_tag=sym", "",
+		"OHLC,sym=USDJPY Open=1i,High=2i,Low=0.5,Close=1.5 1600000020\n"+
+			"OHLC,sym=USDJPY Open=1i,High=2i,Low=0.5 1600000080")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 1, resp.Written)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, 2, resp.Errors[0].Line)
+	assert.Contains(t, resp.Errors[0].Error, "column Close is missing")
+	cs = queryAll(t, q, "USDJPY/1Min/OHLC")
+	epochs := cs.GetEpoch()
+	assert.Equal(t, int64(1600000020), epochs[len(epochs)-1])
+	assert.Equal(t, float32(0.5), cs.GetColumn("Low").([]float32)[len(epochs)-1])
+
+	// A gzip body
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte("trades,symbol=AAPL,exchange=Q price=131.7,size=5i,odd=true 1600000004000000000\n"))
+	require.Nil(t, gz.Close())
+	req := httptest.NewRequest(http.MethodPost, "/write?timeframe=1Sec", &buf)
+	req.Header.Set("Content-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, queryAll(t, q, "AAPL/1Sec/trades").GetEpoch(), 3)
+
+	// The symbols of the patterns of the queries and the key separators are not bucket key items
+	code, resp = postWrite(t, h, "/write?timeframe=1Sec", "",
+		"trades,symbol=A?PL price=1 1600000005000000000\n"+
+			"trades,symbol=A[PL price=1 1600000005000000000\n"+
+			"trades,symbol=A:PL price=1 1600000005000000000")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 0, resp.Written)
+	require.Len(t, resp.Errors, 3)
+	for _, e := range resp.Errors {
+		assert.Contains(t, e.Error, "invalid bucket key item")
+	}
+}
+
+func TestWriteHandlerCSV(t *testing.T) {
+	tearDown, _, metadata, writer, q := setup(t, "TestWriteHandlerCSV")
+	defer tearDown()
+	h := frontend.NewWriteHandler(metadata.CatalogDir, writer, false)
+
+	body := `Symbol,Epoch,Open,High,Low,Close
+EURUSD,2020-09-13T12:27:00Z,1.1,1.2,1.0,1.15
+EURUSD,1600000080,1.15,1.25,1.05,x
+EURUSD,1600000140,1.2,1.3,1.1
+NONE,1600000140,1.2,1.3,1.1,1.2`
+	code, resp := postWrite(t, h, "/write?attribute_group=OHLC", "text/csv", body)
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 1, resp.Written)
+	require.Len(t, resp.Errors, 3)
+	// The errors of the rows come before the ones of the buckets
+	assert.Equal(t, 4, resp.Errors[0].Line)
+	assert.Equal(t, 3, resp.Errors[1].Line)
+	assert.Equal(t, "column Close: invalid FLOAT32 value x", resp.Errors[1].Error)
+	assert.Equal(t, "NONE/1Min/OHLC", resp.Errors[2].Key)
+	assert.Equal(t, "bucket NONE/1Min/OHLC does not exist", resp.Errors[2].Error)
+
+	cs := queryAll(t, q, "EURUSD/1Min/OHLC")
+	epochs := cs.GetEpoch()
+	assert.Equal(t, int64(1600000020), epochs[len(epochs)-1])
+	assert.Equal(t, float32(1.15), cs.GetColumn("Close").([]float32)[len(epochs)-1])
+
+	// A CSV without a Symbol column has the symbol of the request
+	code, resp = postWrite(t, h, "/write?format=csv&symbol=EURUSD&attribute_group=OHLC&precision=ms", "",
+		"Epoch,Open,High,Low,Close\n1600000140000,1,2,0,1\n")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, &frontend.WriteLinesResponse{Written: 1}, resp)
+
+	// The requests with invalid parameters are rejected
+	code, _ = postWrite(t, h, "/write?format=csv", "", body)
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = postWrite(t, h, "/write?timeframe=1Week&attribute_group=OHLC", "text/csv", body)
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestWriteHandlerReplica(t *testing.T) {
+	tearDown, _, metadata, _, q := setup(t, "TestWriteHandlerReplica")
+	defer tearDown()
+	h := frontend.NewWriteHandler(metadata.CatalogDir, &executor.ErrorWriter{}, true)
+
+	code, resp := postWrite(t, h, "/write?precision=s", "",
+		"OHLC,symbol=USDJPY Open=2,High=2,Low=2,Close=2 1600000200\n"+
+			"OHLC,symbol=NEW Open=1,High=1,Low=1,Close=1 1600000200")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 0, resp.Written)
+	require.Len(t, resp.Errors, 2)
+	assert.Equal(t, "write is not allowed on replica", resp.Errors[0].Error)
+	epochs := queryAll(t, q, "USDJPY/1Min/OHLC").GetEpoch()
+	assert.NotEqual(t, int64(1600000200), epochs[len(epochs)-1])
+	_, err := metadata.CatalogDir.GetLatestTimeBucketInfoFromKey(io.NewTimeBucketKey("NEW/1Min/OHLC"))
+	assert.NotNil(t, err)
+}
+
+func TestWriteHandlerAuthorization(t *testing.T) {
+	tearDown, _, metadata, writer, q := setup(t, "TestWriteHandlerAuthorization")
+	defer tearDown()
+	guard, err := auth.NewFromSetting(utils.AuthSetting{
+		APIKeys: map[string]string{"writer-key": "feeder"},
+		Users:   map[string][]string{"feeder": {"writer"}},
+		Roles: map[string][]utils.ACLRule{
+			"writer": {{Keys: []string{"USDJPY/*/*", "NEW/*/*"}, Verbs: []string{"write"}}},
+		},
+	})
+	require.Nil(t, err)
+	h := guard.Handler(frontend.NewWriteHandler(metadata.CatalogDir, writer, true))
+
+	code, resp := postWrite(t, h, "/write?precision=s", "",
+		"OHLC,symbol=USDJPY Open=1,High=1,Low=1,Close=1 1600000200\n"+
+			"OHLC,symbol=EURUSD Open=1,High=1,Low=1,Close=1 1600000200\n"+
+			"OHLC,symbol=NEW Open=1,High=1,Low=1,Close=1 1600000200")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, 1, resp.Written)
+	require.Len(t, resp.Errors, 2)
+	assert.Equal(t, "EURUSD/1Min/OHLC", resp.Errors[0].Key)
+	assert.Contains(t, resp.Errors[0].Error, auth.ErrPermissionDenied.Error())
+	// The creation of a bucket needs the create verb
+	assert.Equal(t, "NEW/1Min/OHLC", resp.Errors[1].Key)
+	assert.Contains(t, resp.Errors[1].Error, auth.ErrPermissionDenied.Error())
+	epochs := queryAll(t, q, "USDJPY/1Min/OHLC").GetEpoch()
+	assert.Equal(t, int64(1600000200), epochs[len(epochs)-1])
+	_, err = metadata.CatalogDir.GetLatestTimeBucketInfoFromKey(io.NewTimeBucketKey("NEW/1Min/OHLC"))
+	assert.NotNil(t, err)
+
+	// The requests without credentials are refused
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/write", strings.NewReader("")))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
@@ -0,0 +1,169 @@
+package frontend
+
+import (
+	"bufio"
+	"fmt"
+	goio "io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxWriteLineSize bounds the size of a line of a written body.
+const maxWriteLineSize = 1 << 20
+
+// ingestRow is a row of a written body, with the number of its line for the errors.
+type ingestRow struct {
+	line        int
+	key         string // the item key of the bucket, e.g. AAPL/1Sec/trades
+	epoch       int64
+	nanoseconds int32
+	names       []string
+	values      []interface{} // float64, int64, uint64, bool, string, or the textValue of a CSV cell
+}
+
+func (r *ingestRow) add(name string, value interface{}) {
+	r.names = append(r.names, name)
+	r.values = append(r.values, value)
+}
+
+/*
+parseLineProtocol returns the rows of a body in the InfluxDB line protocol:
+
+	measurement[,tag=value...] field=value[,field=value...] [timestamp]
+
+The measurement is the AttributeGroup of the bucket and the symbol tag its Symbol, the other tags are string
+columns. The fields are float, integer (1i), unsigned (1u), boolean or "string" columns, and the timestamp is
+an integer in the precision of the options, the time of the write if it is missing. The lines that can't be
+parsed are returned as errors, the other lines are still returned.
+*/
+func parseLineProtocol(r goio.Reader, opts *ingestOptions, now time.Time) (rows []*ingestRow, errs []WriteError) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxWriteLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		row, err := parseLine(text, opts, now)
+		if err != nil {
+			errs = append(errs, WriteError{Line: line, Error: err.Error()})
+			continue
+		}
+		row.line = line
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, WriteError{Error: fmt.Sprintf("read body: %v", err)})
+	}
+	return rows, errs
+}
+
+func parseLine(text string, opts *ingestOptions, now time.Time) (*ingestRow, error) {
+	sections := splitUnescaped(text, ' ', true)
+	if len(sections) != 2 && len(sections) != 3 {
+		return nil, fmt.Errorf("expected a measurement, fields and an optional timestamp, have %d sections",
+			len(sections))
+	}
+
+	row := &ingestRow{}
+	series := splitUnescaped(sections[0], ',', false)
+	measurement := unescape(series[0])
+	var symbol string
+	for _, tag := range series[1:] {
+		kv := splitUnescaped(tag, '=', false)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid tag %q", tag)
+		}
+		name, value := unescape(kv[0]), unescape(kv[1])
+		if name == opts.symbolTag {
+			symbol = value
+			continue
+		}
+		row.add(name, value)
+	}
+	if symbol == "" {
+		return nil, fmt.Errorf("no %s tag", opts.symbolTag)
+	}
+	key, err := opts.bucketKey(symbol, measurement)
+	if err != nil {
+		return nil, err
+	}
+	row.key = key
+
+	for _, field := range splitUnescaped(sections[1], ',', true) {
+		kv := splitUnescaped(field, '=', true)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid field %q", field)
+		}
+		value, ok := parseFieldValue(kv[1])
+		if !ok {
+			return nil, fmt.Errorf("invalid value %s of field %s", kv[1], unescape(kv[0]))
+		}
+		row.add(unescape(kv[0]), value)
+	}
+
+	ts := now.UnixNano() / int64(opts.precision)
+	if len(sections) == 3 {
+		if ts, err = strconv.ParseInt(sections[2], 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q", sections[2])
+		}
+	}
+	row.epoch, row.nanoseconds = opts.epoch(ts)
+	return row, nil
+}
+
+// parseFieldValue returns the value of a field of the line protocol, ok is false if it is invalid.
+func parseFieldValue(s string) (v interface{}, ok bool) {
+	var err error
+	switch {
+	case strings.HasPrefix(s, `"`):
+		if len(s) < 2 || !strings.HasSuffix(s, `"`) {
+			return nil, false
+		}
+		return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(s[1 : len(s)-1]), true
+	case strings.HasSuffix(s, "i"):
+		v, err = strconv.ParseInt(strings.TrimSuffix(s, "i"), 10, 64)
+	case strings.HasSuffix(s, "u"):
+		v, err = strconv.ParseUint(strings.TrimSuffix(s, "u"), 10, 64)
+	case s == "t" || s == "T" || s == "true" || s == "True" || s == "TRUE":
+		return true, true
+	case s == "f" || s == "F" || s == "false" || s == "False" || s == "FALSE":
+		return false, true
+	default:
+		v, err = strconv.ParseFloat(s, 64)
+	}
+	return v, err == nil
+}
+
+/*
+splitUnescaped splits s around the separators that are not escaped by a backslash, nor in a double quoted
+string when quoted is set.
+*/
+func splitUnescaped(s string, sep byte, quoted bool) []string {
+	var (
+		parts    []string
+		start    int
+		inString bool
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\':
+			i++
+		case c == '"' && quoted:
+			inString = !inString
+		case c == sep && !inString:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unescape removes the backslashes escaping the commas, equal signs and spaces of the names and tags.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	return strings.NewReplacer(`\,`, ",", `\=`, "=", `\ `, " ").Replace(s)
+}
@@ -0,0 +1,173 @@
+package pgwire
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	goio "io"
+)
+
+const (
+	// protocolVersion is the version 3.0 of the protocol in the startup message.
+	protocolVersion = 196608
+	// the special startup messages, which have a request code instead of a version
+	sslRequestCode    = 80877103
+	gssEncRequestCode = 80877104
+	cancelRequestCode = 80877102
+
+	// maxMessageSize bounds the size of the messages of the authenticated clients.
+	maxMessageSize = 64 << 20
+	// maxStartupSize bounds the size of the messages before the authentication, like PostgreSQL.
+	maxStartupSize = 10000
+)
+
+// The types of the messages of the clients.
+const (
+	msgQuery     = 'Q'
+	msgTerminate = 'X'
+	msgPassword  = 'p'
+	msgParse     = 'P'
+	msgBind      = 'B'
+	msgDescribe  = 'D'
+	msgExecute   = 'E'
+	msgClose     = 'C'
+	msgFlush     = 'H'
+	msgSync      = 'S'
+)
+
+// The types of the messages of the server.
+const (
+	msgAuthentication  = 'R'
+	msgParameterStatus = 'S'
+	msgBackendKeyData  = 'K'
+	msgReadyForQuery   = 'Z'
+	msgRowDescription  = 'T'
+	msgDataRow         = 'D'
+	msgCommandComplete = 'C'
+	msgEmptyQuery      = 'I'
+	msgErrorResponse   = 'E'
+)
+
+// The authentication requests of the server.
+const (
+	authOK                = 0
+	authCleartextPassword = 3
+)
+
+// The SQLSTATE codes of the errors.
+const (
+	codeProtocolViolation     = "08P01"
+	codeFeatureNotSupported   = "0A000"
+	codeInvalidAuthorization  = "28000"
+	codeInvalidPassword       = "28P01"
+	codeInsufficientPrivilege = "42501"
+	codeInternalError         = "XX000"
+)
+
+// readStartup returns the payload of a startup message, which has no type.
+func readStartup(r goio.Reader) ([]byte, error) {
+	var header [4]byte
+	if _, err := goio.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	size := int(binary.BigEndian.Uint32(header[:]))
+	if size < 8 || size > maxStartupSize {
+		return nil, fmt.Errorf("invalid startup message length %d", size)
+	}
+	payload := make([]byte, size-4)
+	if _, err := goio.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// readMessage returns the type and the payload of a message, whose size is at most maxSize.
+func readMessage(r *bufio.Reader, maxSize int) (byte, []byte, error) {
+	typ, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var header [4]byte
+	if _, err = goio.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := int(binary.BigEndian.Uint32(header[:]))
+	if size < 4 || size > maxSize {
+		return 0, nil, fmt.Errorf("invalid message length %d", size)
+	}
+	payload := make([]byte, size-4)
+	if _, err = goio.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return typ, payload, nil
+}
+
+// parseStartupParameters returns the parameters of a startup message, e.g. "user" and "database".
+func parseStartupParameters(payload []byte) map[string]string {
+	params := map[string]string{}
+	fields := bytes.Split(bytes.TrimRight(payload, "\x00"), []byte{0})
+	for i := 0; i+1 < len(fields); i += 2 {
+		params[string(fields[i])] = string(fields[i+1])
+	}
+	return params
+}
+
+// cstring returns the null terminated string of a payload.
+func cstring(payload []byte) (string, error) {
+	i := bytes.IndexByte(payload, 0)
+	if i < 0 {
+		return "", fmt.Errorf("string without its null terminator")
+	}
+	return string(payload[:i]), nil
+}
+
+// message builds a message of the server.
+type message struct {
+	buf bytes.Buffer
+}
+
+func newMessage(typ byte) *message {
+	m := &message{}
+	m.buf.WriteByte(typ)
+	m.buf.Write([]byte{0, 0, 0, 0}) // the length
+	return m
+}
+
+func (m *message) int16(v int16) *message {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(v))
+	m.buf.Write(b[:])
+	return m
+}
+
+func (m *message) int32(v int32) *message {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	m.buf.Write(b[:])
+	return m
+}
+
+func (m *message) byte(v byte) *message {
+	m.buf.WriteByte(v)
+	return m
+}
+
+func (m *message) string(s string) *message {
+	m.buf.WriteString(s)
+	m.buf.WriteByte(0)
+	return m
+}
+
+func (m *message) bytes(b []byte) *message {
+	m.buf.Write(b)
+	return m
+}
+
+// writeTo writes the message with its length.
+func (m *message) writeTo(w goio.Writer) error {
+	b := m.buf.Bytes()
+	binary.BigEndian.PutUint32(b[1:5], uint32(len(b)-1))
+	_, err := w.Write(b)
+	return err
+}
@@ -0,0 +1,548 @@
+/*
+Package pgwire serves the SQL engine over the PostgreSQL v3 wire protocol, so that psql, the PostgreSQL
+datasources of the dashboards and the BI tools can query MarketStore directly:
+
+	psql "host=localhost port=5433 user=dashboard password=<api key> sslmode=require"
+	=> SELECT Epoch, Close FROM "AAPL/1Min/OHLCV" WHERE Epoch > '2021-01-04';
+	=> SELECT table_name FROM information_schema.tables;
+
+Only the simple query protocol is supported, with the results in the text format. The statements of a query
+run one after the other, each through sqlparser like the SQL statements of the Query RPC. SET, RESET, DISCARD,
+BEGIN, COMMIT and ROLLBACK are accepted and do nothing, SHOW returns the parameters of the session. The
+tables are the buckets, e.g. "AAPL/1Min/OHLCV" or AAPL."1Min".OHLCV, and the information_schema tables
+describe them.
+
+When the authentication is enabled, the password of the connection is an API key or a token of the user,
+which the ACLs authorize like the requests of the other APIs. The user name is not checked. The password is
+sent in clear, so it is only asked for on the connections encrypted with the TLS of the server.
+*/
+package pgwire
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	goio "io"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+// Executor returns the results of the SQL statements, see frontend.DataService.ExecuteSQL.
+type Executor interface {
+	ExecuteSQL(principal *auth.Principal, sqlStatement string) (*io.ColumnSeries, error)
+}
+
+// parameters are the parameters of the server reported to the clients after the startup.
+var parameters = [][2]string{
+	{"server_version", "13.0"},
+	{"server_encoding", "UTF8"},
+	{"client_encoding", "UTF8"},
+	{"DateStyle", "ISO, MDY"},
+	{"TimeZone", "UTC"},
+	{"integer_datetimes", "on"},
+	{"standard_conforming_strings", "on"},
+	{"application_name", ""},
+}
+
+// Server accepts the connections of the PostgreSQL clients.
+type Server struct {
+	executor  Executor
+	guard     *auth.Guard
+	tlsConfig *tls.Config
+
+	mu        sync.Mutex
+	listeners map[net.Listener]struct{}
+	conns     map[net.Conn]struct{}
+	closed    bool
+}
+
+/*
+NewServer returns a server running the statements with the executor, the guard is nil without authentication.
+The TLS config encrypts the connections of the clients asking for it, the authentication needs it.
+*/
+func NewServer(executor Executor, guard *auth.Guard, tlsConfig *tls.Config) *Server {
+	return &Server{
+		executor:  executor,
+		guard:     guard,
+		tlsConfig: tlsConfig,
+		listeners: map[net.Listener]struct{}{},
+		conns:     map[net.Conn]struct{}{},
+	}
+}
+
+// Serve serves the connections of the listener until the server is closed.
+func (s *Server) Serve(ln net.Listener) error {
+	if !s.track(ln, nil) {
+		ln.Close()
+		return nil
+	}
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+			return err
+		}
+		if !s.track(nil, conn) {
+			conn.Close()
+			return nil
+		}
+		go func() {
+			defer s.untrack(conn)
+			if err := s.serveConn(conn); err != nil && !errors.Is(err, goio.EOF) && !s.isClosed() {
+				log.Debug("postgres connection from %s: %v", conn.RemoteAddr(), err)
+			}
+		}()
+	}
+}
+
+// Close closes the listeners and the connections.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for ln := range s.listeners {
+		ln.Close()
+	}
+	for conn := range s.conns {
+		conn.Close()
+	}
+	return nil
+}
+
+func (s *Server) track(ln net.Listener, conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return false
+	}
+	if ln != nil {
+		s.listeners[ln] = struct{}{}
+	}
+	if conn != nil {
+		s.conns[conn] = struct{}{}
+	}
+	return true
+}
+
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.conns, conn)
+	conn.Close()
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// session is a connection once started.
+type session struct {
+	executor  Executor
+	conn      net.Conn
+	r         *bufio.Reader
+	w         *bufio.Writer
+	principal *auth.Principal
+	params    map[string]string
+	// encrypted is true once the connection is upgraded to TLS
+	encrypted bool
+}
+
+func (s *Server) serveConn(conn net.Conn) error {
+	ss := &session{
+		executor: s.executor,
+		conn:     conn,
+		r:        bufio.NewReader(conn),
+		w:        bufio.NewWriter(conn),
+		params:   map[string]string{},
+	}
+	for _, p := range parameters {
+		ss.params[p[0]] = p[1]
+	}
+	ok, err := ss.startup(s.guard, s.tlsConfig)
+	if err != nil || !ok {
+		return err
+	}
+	return ss.run()
+}
+
+// startup negotiates the protocol and authenticates the client, it returns false if the connection is over.
+func (ss *session) startup(guard *auth.Guard, tlsConfig *tls.Config) (bool, error) {
+	for {
+		payload, err := readStartup(ss.r)
+		if err != nil {
+			return false, err
+		}
+		switch binary.BigEndian.Uint32(payload) {
+		case sslRequestCode:
+			if tlsConfig != nil && !ss.encrypted {
+				if err = ss.startTLS(tlsConfig); err != nil {
+					return false, err
+				}
+				continue
+			}
+			// no encryption, the client goes on in clear or gives up
+			if err = ss.w.WriteByte('N'); err != nil {
+				return false, err
+			}
+			if err = ss.w.Flush(); err != nil {
+				return false, err
+			}
+			continue
+		case gssEncRequestCode:
+			if err = ss.w.WriteByte('N'); err != nil {
+				return false, err
+			}
+			if err = ss.w.Flush(); err != nil {
+				return false, err
+			}
+			continue
+		case cancelRequestCode:
+			// the queries are not cancellable
+			return false, nil
+		case protocolVersion:
+		default:
+			return false, ss.fatal(codeProtocolViolation, "unsupported frontend protocol %d.%d",
+				binary.BigEndian.Uint16(payload), binary.BigEndian.Uint16(payload[2:]))
+		}
+
+		startup := parseStartupParameters(payload[4:])
+		if name, ok := startup["application_name"]; ok {
+			ss.params["application_name"] = name
+		}
+		if guard != nil {
+			if ss.principal, err = ss.authenticate(guard, startup["user"]); err != nil {
+				return false, err
+			}
+		}
+		if err = newMessage(msgAuthentication).int32(authOK).writeTo(ss.w); err != nil {
+			return false, err
+		}
+		for _, p := range parameters {
+			m := newMessage(msgParameterStatus).string(p[0]).string(ss.params[p[0]])
+			if err = m.writeTo(ss.w); err != nil {
+				return false, err
+			}
+		}
+		var key [8]byte
+		if _, err = rand.Read(key[:]); err != nil {
+			return false, err
+		}
+		if err = newMessage(msgBackendKeyData).bytes(key[:]).writeTo(ss.w); err != nil {
+			return false, err
+		}
+		return true, ss.ready()
+	}
+}
+
+/*
+startTLS accepts the SSLRequest of the client and upgrades the connection. The bytes sent after the request
+before the handshake are refused, they could be injected by a man in the middle.
+*/
+func (ss *session) startTLS(config *tls.Config) error {
+	if err := ss.w.WriteByte('S'); err != nil {
+		return err
+	}
+	if err := ss.w.Flush(); err != nil {
+		return err
+	}
+	if ss.r.Buffered() != 0 {
+		return errors.New("unencrypted data after the SSLRequest")
+	}
+	conn := tls.Server(ss.conn, config)
+	if err := conn.Handshake(); err != nil {
+		return fmt.Errorf("TLS handshake: %w", err)
+	}
+	ss.r, ss.w = bufio.NewReader(conn), bufio.NewWriter(conn)
+	ss.encrypted = true
+	return nil
+}
+
+// authenticate returns the principal of the API key or the token sent as the password.
+func (ss *session) authenticate(guard *auth.Guard, user string) (*auth.Principal, error) {
+	if !ss.encrypted {
+		return nil, ss.fatal(codeInvalidAuthorization,
+			"the password authentication of user %q needs an encrypted connection, connect with sslmode=require",
+			user)
+	}
+	if err := newMessage(msgAuthentication).int32(authCleartextPassword).writeTo(ss.w); err != nil {
+		return nil, err
+	}
+	if err := ss.w.Flush(); err != nil {
+		return nil, err
+	}
+	typ, payload, err := readMessage(ss.r, maxStartupSize)
+	if err != nil {
+		return nil, err
+	}
+	if typ != msgPassword {
+		return nil, ss.fatal(codeProtocolViolation, "expected a password message, have %q", typ)
+	}
+	password, err := cstring(payload)
+	if err != nil {
+		return nil, ss.fatal(codeProtocolViolation, "invalid password message: %v", err)
+	}
+	principal, err := guard.Authenticate(password)
+	if err != nil {
+		return nil, ss.fatal(codeInvalidPassword, "password authentication failed for user %q", user)
+	}
+	return principal, nil
+}
+
+// run answers the messages of a started session until it is terminated.
+func (ss *session) run() error {
+	for {
+		typ, payload, err := readMessage(ss.r, maxMessageSize)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case msgQuery:
+			query, err := cstring(payload)
+			if err != nil {
+				return ss.fatal(codeProtocolViolation, "invalid query message: %v", err)
+			}
+			if err = ss.query(query); err != nil {
+				return err
+			}
+			if err = ss.ready(); err != nil {
+				return err
+			}
+		case msgParse, msgBind, msgDescribe, msgExecute, msgClose, msgFlush:
+			if err = ss.rejectExtendedQuery(); err != nil {
+				return err
+			}
+		case msgSync:
+			if err = ss.ready(); err != nil {
+				return err
+			}
+		case msgTerminate:
+			return nil
+		default:
+			return ss.fatal(codeProtocolViolation, "unexpected message %q", typ)
+		}
+	}
+}
+
+/*
+rejectExtendedQuery answers a message of the extended query protocol with an error, then skips the messages
+until the Sync which ends the failed extended query.
+*/
+func (ss *session) rejectExtendedQuery() error {
+	if err := ss.error(codeFeatureNotSupported,
+		"the extended query protocol is not supported, use the simple query protocol"); err != nil {
+		return err
+	}
+	for {
+		typ, _, err := readMessage(ss.r, maxMessageSize)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case msgSync:
+			return ss.ready()
+		case msgTerminate:
+			return goio.EOF
+		}
+	}
+}
+
+// query runs the statements of a simple query until one fails.
+func (ss *session) query(query string) error {
+	statements := splitStatements(query)
+	if len(statements) == 0 {
+		return newMessage(msgEmptyQuery).writeTo(ss.w)
+	}
+	for _, statement := range statements {
+		tag, err := ss.statement(statement)
+		if err != nil {
+			code := codeInternalError
+			if errors.Is(err, auth.ErrPermissionDenied) {
+				code = codeInsufficientPrivilege
+			}
+			return ss.error(code, "%s", err.Error())
+		}
+		if err = newMessage(msgCommandComplete).string(tag).writeTo(ss.w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// statement runs a statement and sends its rows, it returns the tag of the command.
+func (ss *session) statement(statement string) (string, error) {
+	command := strings.ToUpper(strings.Fields(statement)[0])
+	switch command {
+	case "SET", "RESET", "DISCARD", "BEGIN", "COMMIT", "ROLLBACK":
+		return command, nil
+	case "START":
+		return "START TRANSACTION", nil
+	case "END":
+		return "COMMIT", nil
+	case "SHOW":
+		return ss.show(statement)
+	}
+
+	cs, err := ss.executor.ExecuteSQL(ss.principal, statement+";")
+	if err != nil {
+		return "", err
+	}
+	if command == "INSERT" {
+		var written int
+		if cs != nil {
+			if rows, ok := cs.GetColumn("Rows Written").([]float32); ok && len(rows) == 1 {
+				written = int(rows[0])
+			}
+		}
+		return fmt.Sprintf("INSERT 0 %d", written), nil
+	}
+	if cs == nil {
+		cs = io.NewColumnSeries()
+	}
+	if err = ss.rows(cs); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("SELECT %d", cs.Len()), nil
+}
+
+// show returns a parameter of the session as a one row result.
+func (ss *session) show(statement string) (string, error) {
+	fields := strings.Fields(strings.TrimSpace(statement))
+	if len(fields) != 2 {
+		return "", fmt.Errorf("SHOW needs the name of a parameter")
+	}
+	for name, value := range ss.params {
+		if strings.EqualFold(name, fields[1]) {
+			cs := io.NewColumnSeries()
+			cs.AddColumn(name, []string{value})
+			return "SHOW", ss.rows(cs)
+		}
+	}
+	return "", fmt.Errorf("unrecognized configuration parameter %q", fields[1])
+}
+
+// rows sends the description and the rows of a result.
+func (ss *session) rows(cs *io.ColumnSeries) error {
+	columns, err := resultColumns(cs)
+	if err != nil {
+		return err
+	}
+	m := newMessage(msgRowDescription).int16(int16(len(columns)))
+	for _, c := range columns {
+		// no table, the type has no modifier and the values are in text
+		m.string(c.name).int32(0).int16(0).int32(c.typ.oid).int16(c.typ.size).int32(-1).int16(0)
+	}
+	if err = m.writeTo(ss.w); err != nil {
+		return err
+	}
+	for i := 0; i < cs.Len(); i++ {
+		m = newMessage(msgDataRow).int16(int16(len(columns)))
+		for _, c := range columns {
+			text := c.texts(i)
+			if text == nil {
+				m.int32(-1)
+				continue
+			}
+			m.int32(int32(len(text))).bytes(text)
+		}
+		if err = m.writeTo(ss.w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ready tells the client that the session waits for a query, outside of a transaction.
+func (ss *session) ready() error {
+	if err := newMessage(msgReadyForQuery).byte('I').writeTo(ss.w); err != nil {
+		return err
+	}
+	return ss.w.Flush()
+}
+
+// error sends an error of a query.
+func (ss *session) error(code, format string, args ...interface{}) error {
+	return ss.errorResponse("ERROR", code, fmt.Sprintf(format, args...))
+}
+
+// fatal sends the error ending a session, and returns it.
+func (ss *session) fatal(code, format string, args ...interface{}) error {
+	msg := fmt.Sprintf(format, args...)
+	if err := ss.errorResponse("FATAL", code, msg); err != nil {
+		return err
+	}
+	if err := ss.w.Flush(); err != nil {
+		return err
+	}
+	return errors.New(msg)
+}
+
+func (ss *session) errorResponse(severity, code, msg string) error {
+	m := newMessage(msgErrorResponse).
+		byte('S').string(severity).
+		byte('V').string(severity).
+		byte('C').string(code).
+		byte('M').string(msg).
+		byte(0)
+	return m.writeTo(ss.w)
+}
+
+/*
+splitStatements returns the statements of a query separated by semicolons, outside of the quoted strings and
+identifiers and of the comments, without the empty statements.
+*/
+func splitStatements(query string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      rune
+	)
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+			current.WriteRune(' ')
+			continue
+		case c == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			i += 2
+			for i+1 < len(runes) && (runes[i] != '*' || runes[i+1] != '/') {
+				i++
+			}
+			i++ // the closing slash
+			current.WriteRune(' ')
+			continue
+		case c == ';':
+			if s := strings.TrimSpace(current.String()); s != "" {
+				statements = append(statements, s)
+			}
+			current.Reset()
+			continue
+		}
+		current.WriteRune(c)
+	}
+	if s := strings.TrimSpace(current.String()); s != "" {
+		statements = append(statements, s)
+	}
+	return statements
+}
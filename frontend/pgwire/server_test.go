@@ -0,0 +1,378 @@
+package pgwire
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"fmt"
+	goio "io"
+	"math/big"
+	"net"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/sqlparser"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/test"
+)
+
+// client is a minimal client of the simple query protocol.
+type client struct {
+	t    *testing.T
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// result is what a client receives for a statement.
+type result struct {
+	columns []string
+	oids    []int32
+	rows    [][]*string
+	tag     string
+	code    string // the SQLSTATE of an error
+}
+
+func dial(t *testing.T, addr string) *client {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	require.Nil(t, err)
+	return &client{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+func (c *client) sendStartup(code uint32, params ...string) {
+	var payload bytes.Buffer
+	_ = binary.Write(&payload, binary.BigEndian, code)
+	for _, p := range params {
+		payload.WriteString(p)
+		payload.WriteByte(0)
+	}
+	if len(params) != 0 {
+		payload.WriteByte(0)
+	}
+	_ = binary.Write(c.conn, binary.BigEndian, uint32(payload.Len()+4))
+	_, err := c.conn.Write(payload.Bytes())
+	require.Nil(c.t, err)
+}
+
+func (c *client) send(typ byte, payload []byte) {
+	msg := append([]byte{typ, 0, 0, 0, 0}, payload...)
+	binary.BigEndian.PutUint32(msg[1:], uint32(len(payload)+4))
+	_, err := c.conn.Write(msg)
+	require.Nil(c.t, err)
+}
+
+func (c *client) recv() (byte, []byte) {
+	typ, payload, err := readMessage(c.r, maxMessageSize)
+	require.Nil(c.t, err)
+	return typ, payload
+}
+
+/*
+start negotiates the protocol, with TLS if the server accepts it, and returns the error code of the startup
+if any.
+*/
+func (c *client) start(password string) string {
+	c.sendStartup(sslRequestCode)
+	b, err := c.r.ReadByte()
+	require.Nil(c.t, err)
+	if b == 'S' {
+		// nolint:gosec // the certificate of the test server is self-signed
+		conn := tls.Client(c.conn, &tls.Config{InsecureSkipVerify: true})
+		require.Nil(c.t, conn.Handshake())
+		c.conn, c.r = conn, bufio.NewReader(conn)
+	} else {
+		require.Equal(c.t, byte('N'), b)
+	}
+
+	c.sendStartup(protocolVersion, "user", "dashboard", "database", "marketstore")
+	for {
+		typ, payload := c.recv()
+		switch typ {
+		case msgAuthentication:
+			if binary.BigEndian.Uint32(payload) == authCleartextPassword {
+				c.send(msgPassword, append([]byte(password), 0))
+			}
+		case msgErrorResponse:
+			return errorCode(payload)
+		case msgReadyForQuery:
+			return ""
+		}
+	}
+}
+
+// query returns the results of the statements of a query.
+func (c *client) query(query string) []result {
+	c.send(msgQuery, append([]byte(query), 0))
+	return c.results()
+}
+
+// results reads the results until the server is ready for a query.
+func (c *client) results() []result {
+	var (
+		results []result
+		current result
+	)
+	for {
+		typ, payload := c.recv()
+		switch typ {
+		case msgRowDescription:
+			n := int(binary.BigEndian.Uint16(payload))
+			payload = payload[2:]
+			for i := 0; i < n; i++ {
+				end := bytes.IndexByte(payload, 0)
+				current.columns = append(current.columns, string(payload[:end]))
+				payload = payload[end+1:]
+				current.oids = append(current.oids, int32(binary.BigEndian.Uint32(payload[6:])))
+				payload = payload[18:]
+			}
+		case msgDataRow:
+			n := int(binary.BigEndian.Uint16(payload))
+			payload = payload[2:]
+			row := make([]*string, n)
+			for i := range row {
+				size := int32(binary.BigEndian.Uint32(payload))
+				payload = payload[4:]
+				if size >= 0 {
+					value := string(payload[:size])
+					row[i] = &value
+					payload = payload[size:]
+				}
+			}
+			current.rows = append(current.rows, row)
+		case msgCommandComplete:
+			current.tag = string(payload[:len(payload)-1])
+			results = append(results, current)
+			current = result{}
+		case msgEmptyQuery:
+			results = append(results, result{})
+		case msgErrorResponse:
+			results = append(results, result{code: errorCode(payload)})
+		case msgReadyForQuery:
+			return results
+		}
+	}
+}
+
+func errorCode(payload []byte) string {
+	for _, field := range bytes.Split(payload, []byte{0}) {
+		if len(field) != 0 && field[0] == 'C' {
+			return string(field[1:])
+		}
+	}
+	return ""
+}
+
+// serve serves the fixture buckets, and returns the address of the server.
+func serve(t *testing.T, guard *auth.Guard, tlsConfig *tls.Config) (addr string, tearDown func()) {
+	t.Helper()
+	rootDir, err := os.MkdirTemp("", "pgwire_test")
+	require.Nil(t, err)
+	test.MakeDummyCurrencyDir(rootDir, true, false)
+	metadata, _, _, err := executor.NewInstanceSetup(rootDir, nil, nil, 5, executor.BackgroundSync(false))
+	require.Nil(t, err)
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	require.Nil(t, err)
+	service := frontend.NewDataService(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer,
+		frontend.NewQueryService(metadata.CatalogDir))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	s := NewServer(service, guard, tlsConfig)
+	go func() { _ = s.Serve(ln) }()
+	return ln.Addr().String(), func() {
+		s.Close()
+		test.CleanupDummyDataDir(rootDir)
+	}
+}
+
+func TestServer(t *testing.T) {
+	addr, tearDown := serve(t, nil, nil)
+	defer tearDown()
+
+	c := dial(t, addr)
+	defer c.conn.Close()
+	require.Equal(t, "", c.start(""))
+
+	// The statements of a query run in order
+	results := c.query(`SELECT Epoch, Open FROM "USDJPY/1Min/OHLC" LIMIT 3;
+		SELECT table_name FROM information_schema.tables -- the buckets; with a comment`)
+	require.Len(t, results, 2)
+	assert.Equal(t, []string{"Epoch", "Open"}, results[0].columns)
+	assert.Equal(t, []int32{1184, 700}, results[0].oids)
+	assert.Equal(t, "SELECT 3", results[0].tag)
+	require.Len(t, results[0].rows, 3)
+	_, err := time.Parse(timestampFormat, *results[0].rows[0][0])
+	require.Nil(t, err)
+	assert.True(t, strings.HasSuffix(*results[0].rows[0][0], "+00"))
+	var tables []string
+	for _, row := range results[1].rows {
+		tables = append(tables, *row[0])
+	}
+	assert.Contains(t, tables, "USDJPY/1Min/OHLC")
+	assert.Equal(t, fmt.Sprintf("SELECT %d", len(tables)), results[1].tag)
+
+	// A failed statement stops the query, the session goes on
+	results = c.query(`SELECT * FROM "NONE/1Min/OHLC"; SET extra_float_digits = 3`)
+	require.Len(t, results, 1)
+	assert.Equal(t, codeInternalError, results[0].code)
+	results = c.query(`SET extra_float_digits = 3; SHOW TimeZone; ;`)
+	require.Len(t, results, 2)
+	assert.Equal(t, "SET", results[0].tag)
+	assert.Equal(t, "UTC", *results[1].rows[0][0])
+	results = c.query(";")
+	assert.Equal(t, []result{{}}, results)
+
+	// The extended query protocol is refused until the Sync
+	c.send(msgParse, []byte("\x00SELECT 1\x00\x00\x00"))
+	c.send(msgSync, nil)
+	results = c.results()
+	require.Len(t, results, 1)
+	assert.Equal(t, codeFeatureNotSupported, results[0].code)
+
+	c.send(msgTerminate, nil)
+	_, err = c.r.ReadByte()
+	assert.Equal(t, goio.EOF, err)
+}
+
+func TestServerAuthentication(t *testing.T) {
+	guard, err := auth.NewFromSetting(utils.AuthSetting{
+		APIKeys: map[string]string{"reader-key": "dashboard", "feeder-key": "feeder"},
+		Users:   map[string][]string{"dashboard": {"reader"}, "feeder": {"feeder"}},
+		Roles: map[string][]utils.ACLRule{
+			"reader": {{Keys: []string{"USDJPY/*/*"}, Verbs: []string{"read"}}},
+			"feeder": {{Keys: []string{"*/*/*"}, Verbs: []string{"read", "write"}}},
+		},
+	})
+	require.Nil(t, err)
+	addr, tearDown := serve(t, guard, testTLSConfig(t))
+	defer tearDown()
+
+	c := dial(t, addr)
+	assert.Equal(t, codeInvalidPassword, c.start("wrong-key"))
+	c.conn.Close()
+
+	// the startup messages are small
+	c = dial(t, addr)
+	_ = binary.Write(c.conn, binary.BigEndian, uint32(maxStartupSize+1))
+	_, err = c.r.ReadByte()
+	assert.Equal(t, goio.EOF, err)
+	c.conn.Close()
+
+	c = dial(t, addr)
+	defer c.conn.Close()
+	require.Equal(t, "", c.start("reader-key"))
+	results := c.query(`SELECT Close FROM "USDJPY/1Min/OHLC" LIMIT 1`)
+	require.Len(t, results, 1)
+	assert.Equal(t, "SELECT 1", results[0].tag)
+	for _, query := range []string{
+		`SELECT Close FROM "EURUSD/1Min/OHLC" LIMIT 1`,
+		`SELECT table_name FROM information_schema.tables`,
+		"INSERT INTO `USDJPY/1Min/OHLC` SELECT * FROM `USDJPY/1Min/OHLC` LIMIT 1",
+	} {
+		results = c.query(query)
+		require.Len(t, results, 1)
+		assert.Equal(t, codeInsufficientPrivilege, results[0].code, query)
+	}
+
+	// an INSERT into a new bucket creates it
+	c = dial(t, addr)
+	defer c.conn.Close()
+	require.Equal(t, "", c.start("feeder-key"))
+	results = c.query("INSERT INTO `NEW/1Min/OHLC` SELECT * FROM `USDJPY/1Min/OHLC` LIMIT 1")
+	require.Len(t, results, 1)
+	assert.Equal(t, codeInsufficientPrivilege, results[0].code)
+}
+
+func TestServerAuthenticationWithoutTLS(t *testing.T) {
+	guard, err := auth.NewFromSetting(utils.AuthSetting{APIKeys: map[string]string{"reader-key": "dashboard"}})
+	require.Nil(t, err)
+	addr, tearDown := serve(t, guard, nil)
+	defer tearDown()
+
+	// the password is not asked for in clear
+	c := dial(t, addr)
+	defer c.conn.Close()
+	assert.Equal(t, codeInvalidAuthorization, c.start("reader-key"))
+}
+
+// testTLSConfig returns the TLS config of a self-signed certificate.
+func testTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	t.Parallel()
+	for query, statements := range map[string][]string{
+		"":                                      nil,
+		" ; ;":                                  nil,
+		"SELECT 1":                              {"SELECT 1"},
+		"SELECT 1; SELECT 2;":                   {"SELECT 1", "SELECT 2"},
+		`SELECT ';' FROM "a;b"; SELECT 2`:       {`SELECT ';' FROM "a;b"`, "SELECT 2"},
+		"SELECT 1 -- one; two\n; SELECT 2":      {"SELECT 1", "SELECT 2"},
+		"SELECT /* ; */ 1; /* unterminated ;":   {"SELECT   1"},
+		"SELECT * FROM `a;b` WHERE x = 'it''s'": {"SELECT * FROM `a;b` WHERE x = 'it''s'"},
+	} {
+		assert.Equal(t, statements, splitStatements(query), query)
+	}
+}
+
+func TestResultColumns(t *testing.T) {
+	t.Parallel()
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1600000000})
+	cs.AddColumn("Price", []float64{1.5})
+	cs.AddColumn("Size", []uint64{1 << 63})
+	cs.AddColumn("Halted", []bool{true})
+	cs.AddColumn("Symbol", [][16]rune{{'A', 'A', 'P', 'L'}})
+	cs.AddColumn("Raw", [][]byte{{0xCA, 0xFE}})
+	cs.AddDecimalColumn("Bid", []io.Decimal64{-12345}, 3)
+	cs.AddColumn("Nanoseconds", []int32{500000000})
+	cs.SetNulls("Price", []bool{true})
+
+	columns, err := resultColumns(cs)
+	require.Nil(t, err)
+	var names, texts []string
+	for _, c := range columns {
+		names = append(names, c.name)
+		if text := c.texts(0); text != nil {
+			texts = append(texts, string(text))
+		} else {
+			texts = append(texts, "NULL")
+		}
+	}
+	assert.Equal(t, []string{"Epoch", "Price", "Size", "Halted", "Symbol", "Raw", "Bid", "Nanoseconds"}, names)
+	assert.Equal(t, []string{
+		"2020-09-13 12:26:40.5+00", "NULL", fmt.Sprint(uint64(1 << 63)), "t", "AAPL", `\xcafe`, "-12.345",
+		"500000000",
+	}, texts)
+	assert.Equal(t, pgTypes["numeric"], columns[2].typ)
+}
@@ -0,0 +1,162 @@
+package pgwire
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/sqlparser"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// pgType is a PostgreSQL type, by its OID in pg_type.
+type pgType struct {
+	oid  int32
+	size int16 // -1 for the variable size types
+}
+
+// pgTypes are the types of the columns by their SQL type name, see sqlparser.SQLTypeName.
+var pgTypes = map[string]pgType{
+	"boolean":                  {oid: 16, size: 1},
+	"bytea":                    {oid: 17, size: -1},
+	"bigint":                   {oid: 20, size: 8},
+	"smallint":                 {oid: 21, size: 2},
+	"integer":                  {oid: 23, size: 4},
+	"text":                     {oid: 25, size: -1},
+	"real":                     {oid: 700, size: 4},
+	"double precision":         {oid: 701, size: 8},
+	"timestamp with time zone": {oid: 1184, size: 8},
+	"numeric":                  {oid: 1700, size: -1},
+}
+
+// timestampFormat is the text format of the timestamptz values with the ISO DateStyle and the UTC TimeZone.
+const timestampFormat = "2006-01-02 15:04:05.999999-07"
+
+// column is a column of a result, with the text of its values.
+type column struct {
+	name  string
+	typ   pgType
+	texts func(i int) []byte // nil for NULL
+}
+
+/*
+resultColumns returns the columns of a result in their order. The companion columns of the NULL marks are
+not columns, and the Epoch has the sub-second time of the Nanoseconds column of the variable length buckets.
+*/
+func resultColumns(cs *io.ColumnSeries) ([]column, error) {
+	types := map[string]io.EnumElementType{}
+	for _, ds := range cs.GetDataShapes() {
+		types[ds.Name] = ds.Type
+	}
+	nanoseconds, _ := cs.GetColumn("Nanoseconds").([]int32)
+	var columns []column
+	for _, name := range cs.GetColumnNames() {
+		if cs.IsNullColumn(name) {
+			continue
+		}
+		typ, ok := pgTypes[sqlparser.SQLTypeName(name, types[name])]
+		if !ok {
+			return nil, fmt.Errorf("column %s has no PostgreSQL type", name)
+		}
+		var texts func(int) []byte
+		if epochs, ok := cs.GetColumn(name).([]int64); ok && name == "Epoch" {
+			texts = func(i int) []byte {
+				t := time.Unix(epochs[i], 0).UTC()
+				if nanoseconds != nil {
+					t = t.Add(time.Duration(nanoseconds[i]))
+				}
+				return []byte(t.Format(timestampFormat))
+			}
+		} else {
+			var err error
+			texts, err = columnTexts(cs.GetColumn(name), cs.Scale(name))
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", name, err)
+			}
+		}
+		if nulls := cs.Nulls(name); nulls != nil {
+			values := texts
+			texts = func(i int) []byte {
+				if nulls[i] {
+					return nil
+				}
+				return values(i)
+			}
+		}
+		columns = append(columns, column{name: name, typ: typ, texts: texts})
+	}
+	return columns, nil
+}
+
+// columnTexts returns the text format of the values of a column.
+func columnTexts(col interface{}, scale int8) (func(i int) []byte, error) {
+	switch values := col.(type) {
+	case []float32:
+		return func(i int) []byte { return formatFloat(float64(values[i]), 32) }, nil
+	case []float64:
+		return func(i int) []byte { return formatFloat(values[i], 64) }, nil
+	case []int8:
+		return func(i int) []byte { return strconv.AppendInt(nil, int64(values[i]), 10) }, nil
+	case []int16:
+		return func(i int) []byte { return strconv.AppendInt(nil, int64(values[i]), 10) }, nil
+	case []int32:
+		return func(i int) []byte { return strconv.AppendInt(nil, int64(values[i]), 10) }, nil
+	case []int64:
+		return func(i int) []byte { return strconv.AppendInt(nil, values[i], 10) }, nil
+	case []uint8:
+		return func(i int) []byte { return strconv.AppendUint(nil, uint64(values[i]), 10) }, nil
+	case []uint16:
+		return func(i int) []byte { return strconv.AppendUint(nil, uint64(values[i]), 10) }, nil
+	case []uint32:
+		return func(i int) []byte { return strconv.AppendUint(nil, uint64(values[i]), 10) }, nil
+	case []uint64:
+		return func(i int) []byte { return strconv.AppendUint(nil, values[i], 10) }, nil
+	case []bool:
+		return func(i int) []byte {
+			if values[i] {
+				return []byte("t")
+			}
+			return []byte("f")
+		}, nil
+	case []string:
+		return func(i int) []byte { return []byte(values[i]) }, nil
+	case [][16]rune:
+		return func(i int) []byte { return []byte(string16(values[i])) }, nil
+	case [][]byte:
+		return func(i int) []byte {
+			text := make([]byte, 2+hex.EncodedLen(len(values[i])))
+			copy(text, `\x`)
+			hex.Encode(text[2:], values[i])
+			return text
+		}, nil
+	case []io.Decimal64:
+		return func(i int) []byte { return []byte(values[i].Format(scale)) }, nil
+	default:
+		return nil, fmt.Errorf("unsupported column type %T", col)
+	}
+}
+
+// formatFloat returns the text of a float in the format of PostgreSQL.
+func formatFloat(f float64, bitSize int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return []byte("NaN")
+	case math.IsInf(f, 1):
+		return []byte("Infinity")
+	case math.IsInf(f, -1):
+		return []byte("-Infinity")
+	default:
+		return strconv.AppendFloat(nil, f, 'g', -1, bitSize)
+	}
+}
+
+// string16 returns the string of a STRING16 value, without its trailing zeros.
+func string16(r [16]rune) string {
+	n := len(r)
+	for n > 0 && r[n-1] == 0 {
+		n--
+	}
+	return string(r[:n])
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/alpacahq/marketstore/v4/catalog"
 	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
 	"github.com/alpacahq/marketstore/v4/planner"
 	"github.com/alpacahq/marketstore/v4/sqlparser"
 	"github.com/alpacahq/marketstore/v4/utils"
@@ -44,6 +45,9 @@ type QueryRequest struct {
 
 	// Support for functions is experimental and subject to change
 	Functions []string `msgpack:"functions,omitempty"`
+
+	// "arrow" returns the result as Arrow IPC streams, the default is a NumpyMultiDataset
+	Format string `msgpack:"format,omitempty"`
 }
 
 type MultiQueryRequest struct {
@@ -55,6 +59,8 @@ type MultiQueryRequest struct {
 
 type QueryResponse struct {
 	Result *io.NumpyMultiDataset `msgpack:"result"`
+	// An Arrow IPC stream per time bucket key, for the "arrow" format
+	Arrow [][]byte `msgpack:"arrow,omitempty"`
 }
 
 type MultiQueryResponse struct {
@@ -74,6 +80,16 @@ func (resp *MultiQueryResponse) ToColumnSeriesMap() (*io.ColumnSeriesMap, error)
 	csm := io.NewColumnSeriesMap()
 
 	for _, ds := range resp.Responses { // Datasets are packed in a slice, each has a NumpyMultiDataset inside
+		if ds.Arrow != nil {
+			streams, err := fromArrowStreams(ds.Arrow)
+			if err != nil {
+				return nil, err
+			}
+			for tbk, cs := range streams {
+				csm[tbk] = cs
+			}
+			continue
+		}
 		nmds := ds.Result
 		for tbkStr, startIndex := range nmds.StartIndex {
 			cs, err := nmds.ToColumnSeries(startIndex, nmds.Lengths[tbkStr])
@@ -91,20 +107,24 @@ func (resp *MultiQueryResponse) ToColumnSeriesMap() (*io.ColumnSeriesMap, error)
 func (s *DataService) Query(r *http.Request, reqs *MultiQueryRequest, response *MultiQueryResponse) (err error) {
 	response.Version = utils.GitHash
 	response.Timezone = utils.InstanceConfig.Timezone.String()
+	principal := principalOf(r)
 	for _, req := range reqs.Requests {
 		var (
 			resp *QueryResponse
 			err  error
 		)
+		if err = checkFormat(req.Format); err != nil {
+			return err
+		}
 		// SQL
 		if req.IsSQLStatement {
-			resp, err = s.executeSQL(req.SQLStatement)
+			resp, err = s.executeSQL(principal, req.SQLStatement, req.Format)
 			if err != nil {
 				return err
 			}
 		} else {
 			// Query
-			resp, err = s.executeQuery(&req)
+			resp, err = s.executeQuery(principal, &req)
 			if err != nil {
 				return err
 			}
@@ -115,32 +135,55 @@ func (s *DataService) Query(r *http.Request, reqs *MultiQueryRequest, response *
 	return nil
 }
 
-func (s *DataService) executeSQL(sqlStatement string) (*QueryResponse, error) {
-	queryTree, err := sqlparser.BuildQueryTree(sqlStatement)
+func (s *DataService) executeSQL(principal *auth.Principal, sqlStatement, format string) (*QueryResponse, error) {
+	cs, err := s.ExecuteSQL(principal, sqlStatement)
 	if err != nil {
 		return nil, err
 	}
-	es, err := sqlparser.NewExecutableStatement(queryTree)
+	tbk := io.NewTimeBucketKeyFromString(sqlStatement + ":SQL")
+	return newQueryResponse(io.ColumnSeriesMap{*tbk: cs}, format)
+}
+
+// ExecuteSQL returns the result of a SQL statement, the principal is nil if the authentication is disabled.
+func (s *DataService) ExecuteSQL(principal *auth.Principal, sqlStatement string) (*io.ColumnSeries, error) {
+	return materializeSQL(principal, s.aggRunner, s.catalogDir, sqlStatement)
+}
+
+// materializeSQL returns the result of a SQL statement once the principal is authorized on its tables.
+func materializeSQL(principal *auth.Principal, aggRunner *sqlparser.AggRunner, catDir *catalog.Directory,
+	sqlStatement string,
+) (*io.ColumnSeries, error) {
+	queryTree, err := sqlparser.BuildQueryTree(sqlStatement)
 	if err != nil {
 		return nil, err
 	}
-	cs, err := es.Materialize(s.aggRunner, s.catalogDir)
+	es, err := sqlparser.NewExecutableStatement(queryTree)
 	if err != nil {
 		return nil, err
 	}
-	nds, err := io.NewNumpyDataset(cs)
-	if err != nil {
+	if err = authorizeSQL(principal, catDir, es); err != nil {
 		return nil, err
 	}
-	tbk := io.NewTimeBucketKeyFromString(sqlStatement + ":SQL")
-	nmds, err := io.NewNumpyMultiDataset(nds, *tbk)
+	return es.Materialize(aggRunner, catDir)
+}
+
+// newQueryResponse returns the response of a result in the format of the query.
+func newQueryResponse(csm io.ColumnSeriesMap, format string) (*QueryResponse, error) {
+	if format == FormatArrow {
+		streams, err := toArrowStreams(csm)
+		if err != nil {
+			return nil, err
+		}
+		return &QueryResponse{Arrow: streams}, nil
+	}
+	nmds, err := toNumpyMultiDataset(csm)
 	if err != nil {
 		return nil, err
 	}
-	return &QueryResponse{nmds}, nil
+	return &QueryResponse{Result: nmds}, nil
 }
 
-func (s *DataService) executeQuery(req *QueryRequest) (*QueryResponse, error) {
+func (s *DataService) executeQuery(principal *auth.Principal, req *QueryRequest) (*QueryResponse, error) {
 	/*
 		Assumption: Within each TimeBucketKey, we have one or more of each category, with the exception of
 		the AttributeGroup (aka Record Format) and Timeframe
@@ -161,7 +204,12 @@ func (s *DataService) executeQuery(req *QueryRequest) (*QueryResponse, error) {
 	if len(Timeframe) == 0 || len(RecordFormat) == 0 || len(Symbols) == 0 {
 		return nil, fmt.Errorf("destinations must have a Symbol, Timeframe and AttributeGroup, have: %s",
 			dest.String())
-	} else if len(Symbols) == 1 && Symbols[0] == "*" {
+	}
+	dest, err := readableDestination(principal, s.catalogDir, dest)
+	if err != nil {
+		return nil, err
+	}
+	if dest.GetItemInCategory("Symbol") == "*" {
 		// replace the * "symbol" with a list all known actual symbols
 		allSymbols := s.catalogDir.GatherCategoriesAndItems()["Symbol"]
 		symbols := make([]string, 0, len(allSymbols))
@@ -227,25 +275,9 @@ func (s *DataService) executeQuery(req *QueryRequest) (*QueryResponse, error) {
 	}
 
 	/*
-		Separate each TimeBucket from the result and compose a NumpyMultiDataset
+		Separate each TimeBucket from the result and compose a NumpyMultiDataset, or an Arrow stream per bucket
 	*/
-	var nmds *io.NumpyMultiDataset
-	for tbk, cs := range csm {
-		nds, err := io.NewNumpyDataset(cs)
-		if err != nil {
-			return nil, err
-		}
-		if nmds == nil {
-			nmds, err = io.NewNumpyMultiDataset(nds, tbk)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			nmds.Append(cs, tbk)
-		}
-	}
-
-	return &QueryResponse{nmds}, nil
+	return newQueryResponse(csm, req.Format)
 }
 
 type ListSymbolsResponse struct {
@@ -261,15 +293,16 @@ func (s *DataService) ListSymbols(r *http.Request, req *ListSymbolsRequest, resp
 	if atomic.LoadUint32(&Queryable) == 0 {
 		return errNotQueryable
 	}
+	principal := principalOf(r)
 
 	// TBK format (e.g. ["AMZN/1Min/TICK", "AAPL/1Sec/OHLCV", ...])
 	if req != nil && req.Format == "tbk" {
-		response.Results = catalog.ListTimeBucketKeyNames(s.catalogDir)
+		response.Results = readableKeys(principal, catalog.ListTimeBucketKeyNames(s.catalogDir))
 		return nil
 	}
 
 	// Symbol format (e.g. ["AMZN", "AAPL", ...])
-	symbols := s.catalogDir.GatherCategoriesAndItems()["Symbol"]
+	symbols := readableSymbols(principal, s.catalogDir)
 	response.Results = make([]string, len(symbols))
 	cnt := 0
 	for symbol := range symbols {
@@ -389,3 +389,49 @@ func TestFunctions(t *testing.T) {
 	tref := time.Date(2002, time.December, 31, 23, 55, 0, 0, time.UTC)
 	assert.Equal(t, ti, tref)
 }
+
+func TestWindowFunctions(t *testing.T) {
+	tearDown, rootDir, metadata, writer, q := setup(t, "TestWindowFunctions")
+	defer tearDown()
+
+	service := frontend.NewDataService(rootDir, metadata.CatalogDir,
+		sqlparser.NewDefaultAggRunner(metadata.CatalogDir), writer, q,
+	)
+	service.Init()
+
+	// Window functions keep the input rows, so they can be chained
+	args := &frontend.MultiQueryRequest{
+		Requests: []frontend.QueryRequest{
+			frontend.NewQueryRequestBuilder("USDJPY/1Min/OHLC").
+				LimitRecordCount(200).
+				Functions([]string{"sma('3', Close)", "lag(SMA_Close)"}).
+				End(),
+		},
+	}
+
+	var response frontend.MultiQueryResponse
+	if err := service.Query(nil, args, &response); err != nil {
+		t.Fatalf("error returned: %s", err)
+	}
+
+	cs, err := response.Responses[0].Result.ToColumnSeries()
+	assert.Nil(t, err)
+	assert.Equal(t, 200, cs.Len())
+	assert.Equal(t, []string{"Epoch", "Open", "High", "Low", "Close", "SMA_Close", "Lag_SMA_Close"},
+		cs.GetColumnNames())
+
+	closes := cs.GetColumn("Close").([]float32)
+	sma := cs.GetColumn("SMA_Close").([]float64)
+	lag := cs.GetColumn("Lag_SMA_Close").([]float64)
+	assert.InDelta(t, float64(closes[0]), sma[0], 1e-6)
+	assert.True(t, math.IsNaN(lag[0]))
+	for i := 2; i < len(closes); i++ {
+		mean := (float64(closes[i-2]) + float64(closes[i-1]) + float64(closes[i])) / 3
+		assert.InDelta(t, mean, sma[i], 1e-6)
+		assert.Equal(t, sma[i-1], lag[i])
+	}
+
+	args.Requests[0].Functions = []string{"ema(Close)"}
+	err = service.Query(nil, args, &response)
+	assert.NotNil(t, err)
+}
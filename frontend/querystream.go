@@ -0,0 +1,372 @@
+package frontend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/vmihailenco/msgpack"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/planner"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/log"
+)
+
+// DefaultQueryBatchSize is the number of rows in each batch of a streamed query when the client doesn't set one.
+const DefaultQueryBatchSize = 10000
+
+/*
+QueryCursor is the position of the last row sent by a streamed query. A query
+resumed with the cursor starts right after that row.
+*/
+type QueryCursor struct {
+	Key         string `msgpack:"key"` // The time bucket key of the row
+	Epoch       int64  `msgpack:"epoch"`
+	Nanoseconds int32  `msgpack:"nanoseconds"`
+	Ordinal     int32  `msgpack:"ordinal"` // The index of the row among the rows of the same time
+}
+
+// This is the parameter interface of the streamed queries.
+type QueryStreamRequest struct {
+	// Destination, time range and columns of the query.
+	// SQL statements, record limits and functions are not supported
+	Request QueryRequest `msgpack:"request"`
+	// Max number of rows in each response, DefaultQueryBatchSize if 0
+	BatchSize int `msgpack:"batch_size,omitempty"`
+	// Resume a query after the row of the cursor
+	Cursor *QueryCursor `msgpack:"cursor,omitempty"`
+}
+
+type QueryStreamResponse struct {
+	Result *io.NumpyMultiDataset `msgpack:"result,omitempty"` // The next rows of a single time bucket
+	Cursor QueryCursor           `msgpack:"cursor"`           // The last row of the result
+	Error  string                `msgpack:"error,omitempty"`  // Set on the last response if the query failed
+}
+
+/*
+QueryPager reads the rows of a query in batches of bounded size. The buckets
+are read one after the other in the order of their keys, each one in time
+order. A batch is only read when it is asked for, so a client that stops
+early doesn't cost the server the rest of the query.
+*/
+type QueryPager struct {
+	catalogDir *catalog.Directory
+	keys       []*io.TimeBucketKey // The buckets left to read
+	start, end time.Time
+	columns    []string
+	batchSize  int
+	cursor     *QueryCursor
+}
+
+/*
+NewQueryPager prepares a query of the buckets of a destination, which may have
+a list of symbols, or * for all the symbols. The query starts after the cursor
+when one is given.
+*/
+func NewQueryPager(catDir *catalog.Directory, dest *io.TimeBucketKey, start, end time.Time, columns []string,
+	batchSize int, cursor *QueryCursor,
+) (*QueryPager, error) {
+	timeframe := dest.GetItemInCategory("Timeframe")
+	recordFormat := dest.GetItemInCategory("AttributeGroup")
+	symbols := dest.GetMultiItemInCategory("Symbol")
+	if len(timeframe) == 0 || len(recordFormat) == 0 || len(symbols) == 0 {
+		return nil, fmt.Errorf("destinations must have a Symbol, Timeframe and AttributeGroup, have: %s",
+			dest.String())
+	}
+	if len(symbols) == 1 && symbols[0] == "*" {
+		symbols = symbols[:0]
+		for symbol := range catDir.GatherCategoriesAndItems()["Symbol"] {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if batchSize <= 0 {
+		batchSize = DefaultQueryBatchSize
+	}
+
+	p := &QueryPager{
+		catalogDir: catDir,
+		start:      start,
+		end:        end,
+		columns:    columns,
+		batchSize:  batchSize,
+		cursor:     cursor,
+	}
+	for _, symbol := range symbols {
+		key := io.NewTimeBucketKey(strings.Join([]string{symbol, timeframe, recordFormat}, "/"),
+			dest.GetCatKey())
+		// Buckets before the one of the cursor were already sent
+		if cursor != nil && key.String() < cursor.Key {
+			continue
+		}
+		if _, err := catDir.GetLatestTimeBucketInfoFromKey(key); err != nil {
+			continue
+		}
+		p.keys = append(p.keys, key)
+	}
+	sort.Slice(p.keys, func(i, j int) bool { return p.keys[i].String() < p.keys[j].String() })
+	return p, nil
+}
+
+// Cursor returns the position of the last row returned by Next, nil before the first batch.
+func (p *QueryPager) Cursor() *QueryCursor {
+	return p.cursor
+}
+
+// Next returns the next batch of rows and its bucket, the batch is nil once all the rows were read.
+func (p *QueryPager) Next() (key *io.TimeBucketKey, cs *io.ColumnSeries, err error) {
+	for len(p.keys) != 0 {
+		key = p.keys[0]
+		cs, err = p.readBatch(key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if cs.Len() == 0 {
+			p.keys = p.keys[1:]
+			continue
+		}
+
+		p.cursor = nextCursor(p.cursor, key, cs)
+		csm := io.ColumnSeriesMap{*key: cs}
+		csm.FilterColumns(p.columns)
+		return key, cs, nil
+	}
+	return nil, nil, nil
+}
+
+/*
+nextCursor returns the cursor of the last row of a batch read after the cursor
+prev. The rows of the same time are counted from the previous batches when the
+batch only has rows of that time.
+*/
+func nextCursor(prev *QueryCursor, key *io.TimeBucketKey, cs *io.ColumnSeries) *QueryCursor {
+	epochs := cs.GetEpoch()
+	nanoseconds, _ := cs.GetColumn("Nanoseconds").([]int32)
+	last := len(epochs) - 1
+	cursor := &QueryCursor{Key: key.String(), Epoch: epochs[last]}
+	if nanoseconds != nil {
+		cursor.Nanoseconds = nanoseconds[last]
+	}
+	for i := last - 1; i >= 0 && epochs[i] == cursor.Epoch &&
+		(nanoseconds == nil || nanoseconds[i] == cursor.Nanoseconds); i-- {
+		cursor.Ordinal++
+	}
+	if prev != nil && prev.Key == cursor.Key && prev.Epoch == cursor.Epoch &&
+		prev.Nanoseconds == cursor.Nanoseconds && int(cursor.Ordinal) == last {
+		cursor.Ordinal += prev.Ordinal + 1
+	}
+	return cursor
+}
+
+// readBatch reads the rows of the bucket that follow the cursor.
+func (p *QueryPager) readBatch(key *io.TimeBucketKey) (cs *io.ColumnSeries, err error) {
+	start := p.start
+	limit := p.batchSize
+	var after *QueryCursor
+	if p.cursor != nil && p.cursor.Key == key.String() {
+		after = p.cursor
+		resume := io.ToSystemTimezone(time.Unix(after.Epoch, int64(after.Nanoseconds)))
+		if resume.After(start) {
+			start = resume
+		}
+		limit += int(after.Ordinal) + 1
+	}
+
+	/*
+		The bucket is read from the time of the cursor, the rows of that time
+		up to the one of the cursor are read again. They are read in addition
+		to the batch so that a full batch is left once they are dropped.
+	*/
+	query := planner.NewQuery(p.catalogDir)
+	query.AddTargetKey(key)
+	query.SetRange(start, p.end)
+	query.SetRowLimit(io.FIRST, limit)
+	parseResult, err := query.Parse()
+	if err != nil {
+		return nil, err
+	}
+	scanner, err := executor.NewReader(parseResult)
+	if err != nil {
+		return nil, err
+	}
+	csm, err := scanner.Read()
+	if err != nil {
+		return nil, err
+	}
+	cs = csm[*key]
+	if cs == nil {
+		return io.NewColumnSeries(), nil
+	}
+
+	if after != nil {
+		epochs := cs.GetEpoch()
+		nanoseconds, _ := cs.GetColumn("Nanoseconds").([]int32)
+		sent := make([]bool, len(epochs))
+		var sameTime int32
+		for i, epoch := range epochs {
+			switch {
+			case epoch < after.Epoch:
+				sent[i] = true
+			case epoch > after.Epoch:
+			case nanoseconds == nil || nanoseconds[i] < after.Nanoseconds:
+				sent[i] = true
+			case nanoseconds[i] == after.Nanoseconds:
+				sent[i] = sameTime <= after.Ordinal
+				sameTime++
+			}
+		}
+		if err = cs.RestrictViaBitmap(sent); err != nil {
+			return nil, err
+		}
+	}
+	if err = cs.RestrictLength(p.batchSize, io.FIRST); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+var errStreamUnsupported = errors.New("SQL statements, record limits and functions can not be streamed")
+
+/*
+newRequestPager validates and authorizes a streamed query and prepares its
+pager, the time range defaults to all the rows like for Query.
+*/
+func newRequestPager(principal *auth.Principal, catDir *catalog.Directory, req *QueryStreamRequest,
+) (*QueryPager, error) {
+	q := &req.Request
+	if q.IsSQLStatement || q.LimitRecordCount != nil && *q.LimitRecordCount != 0 || len(q.Functions) != 0 {
+		return nil, errStreamUnsupported
+	}
+	dest, err := readableDestination(principal, catDir, io.NewTimeBucketKey(q.Destination, q.KeyCategory))
+	if err != nil {
+		return nil, err
+	}
+
+	epochStart := int64(0)
+	epochEnd := int64(math.MaxInt64)
+	var epochStartNanos, epochEndNanos int64
+	if q.EpochStart != nil {
+		epochStart = *q.EpochStart
+		if q.EpochStartNanos != nil {
+			epochStartNanos = *q.EpochStartNanos
+		}
+	}
+	if q.EpochEnd != nil {
+		epochEnd = *q.EpochEnd
+		if q.EpochEndNanos != nil {
+			epochEndNanos = *q.EpochEndNanos
+		}
+	}
+	start := io.ToSystemTimezone(time.Unix(epochStart, epochStartNanos))
+	end := io.ToSystemTimezone(time.Unix(epochEnd, epochEndNanos))
+	return NewQueryPager(catDir, dest, start, end, q.Columns, req.BatchSize, req.Cursor)
+}
+
+/*
+QueryStreamHandler serves streamed queries over HTTP. The body of the request
+is a QueryStreamRequest, the response is chunked with one QueryStreamResponse
+per batch, encoded with msgpack or JSON after the Content-Type of the
+request. A failure after the first batch is reported by the last response.
+*/
+type QueryStreamHandler struct {
+	catalogDir *catalog.Directory
+}
+
+func NewQueryStreamHandler(catDir *catalog.Directory) *QueryStreamHandler {
+	return &QueryStreamHandler{catalogDir: catDir}
+}
+
+type streamEncoder interface {
+	Encode(v interface{}) error
+}
+
+func (h *QueryStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST a QueryStreamRequest", http.StatusMethodNotAllowed)
+		return
+	}
+	if atomic.LoadUint32(&Queryable) == 0 {
+		http.Error(w, errNotQueryable.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	var req QueryStreamRequest
+	var enc streamEncoder
+	contentType := r.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "application/x-msgpack") {
+		err := msgpack.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("decode request: %v", err), http.StatusBadRequest)
+			return
+		}
+		enc = msgpack.NewEncoder(w)
+	} else {
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("decode request: %v", err), http.StatusBadRequest)
+			return
+		}
+		contentType = "application/x-ndjson"
+		enc = json.NewEncoder(w)
+	}
+	pager, err := newRequestPager(principalOf(r), h.catalogDir, &req)
+	if errors.Is(err, auth.ErrPermissionDenied) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("marketstore-version", utils.GitHash)
+	w.Header().Set("marketstore-timezone", utils.InstanceConfig.Timezone.String())
+	flusher, _ := w.(http.Flusher)
+	for r.Context().Err() == nil {
+		resp, done, err := nextStreamResponse(pager)
+		if err != nil {
+			resp = &QueryStreamResponse{Error: err.Error()}
+		} else if done {
+			return
+		}
+		if err2 := enc.Encode(resp); err2 != nil {
+			log.Debug("query stream closed by the client: %v", err2)
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// nextStreamResponse reads the next batch of the pager, done is true once all the rows were sent.
+func nextStreamResponse(pager *QueryPager) (resp *QueryStreamResponse, done bool, err error) {
+	key, cs, err := pager.Next()
+	if err != nil {
+		return nil, false, err
+	}
+	if cs == nil {
+		return nil, true, nil
+	}
+	nds, err := io.NewNumpyDataset(cs)
+	if err != nil {
+		return nil, false, err
+	}
+	nmds, err := io.NewNumpyMultiDataset(nds, *key)
+	if err != nil {
+		return nil, false, err
+	}
+	return &QueryStreamResponse{Result: nmds, Cursor: *pager.Cursor()}, false, nil
+}
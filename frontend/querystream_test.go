@@ -0,0 +1,212 @@
+package frontend_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/client"
+	"github.com/alpacahq/marketstore/v4/proto"
+	"github.com/alpacahq/marketstore/v4/sqlparser"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+	"github.com/alpacahq/marketstore/v4/utils/test"
+)
+
+// readAll pages through a query and returns the epochs read from each bucket.
+func readAll(t *testing.T, pager *frontend.QueryPager, batchSize int) map[string][]int64 {
+	t.Helper()
+
+	epochs := make(map[string][]int64)
+	for {
+		key, cs, err := pager.Next()
+		require.Nil(t, err)
+		if cs == nil {
+			return epochs
+		}
+		assert.LessOrEqual(t, cs.Len(), batchSize)
+		epochs[key.String()] = append(epochs[key.String()], cs.GetEpoch()...)
+	}
+}
+
+func TestQueryPager(t *testing.T) {
+	tearDown, _, metadata, _, _ := setup(t, "TestQueryPager")
+	defer tearDown()
+
+	dest := io.NewTimeBucketKey("USDJPY,EURUSD/1H/OHLC")
+	start := test.ParseT("2002-10-01 00:00:00")
+	end := test.ParseT("2002-10-03 00:00:00")
+
+	pager, err := frontend.NewQueryPager(metadata.CatalogDir, dest, start, end, nil, 1000, nil)
+	require.Nil(t, err)
+	all := readAll(t, pager, 1000)
+	assert.Len(t, all, 2)
+	assert.Len(t, all["USDJPY/1H/OHLC:Symbol/Timeframe/AttributeGroup"], 49)
+
+	// Small batches return the same rows
+	pager, err = frontend.NewQueryPager(metadata.CatalogDir, dest, start, end, nil, 7, nil)
+	require.Nil(t, err)
+	assert.Equal(t, all, readAll(t, pager, 7))
+
+	// A query resumed with the cursor returns the rows not read yet
+	pager, err = frontend.NewQueryPager(metadata.CatalogDir, dest, start, end, nil, 10, nil)
+	require.Nil(t, err)
+	read := make(map[string][]int64)
+	for i := 0; i < 6; i++ {
+		key, cs, err2 := pager.Next()
+		require.Nil(t, err2)
+		read[key.String()] = append(read[key.String()], cs.GetEpoch()...)
+	}
+	cursor := pager.Cursor()
+	assert.Equal(t, "USDJPY/1H/OHLC:Symbol/Timeframe/AttributeGroup", cursor.Key)
+
+	pager, err = frontend.NewQueryPager(metadata.CatalogDir, dest, start, end, nil, 10, cursor)
+	require.Nil(t, err)
+	for key, epochs := range readAll(t, pager, 10) {
+		read[key] = append(read[key], epochs...)
+	}
+	assert.Equal(t, all, read)
+}
+
+func TestQueryPagerDuplicateTimes(t *testing.T) {
+	tearDown, _, metadata, writer, _ := setup(t, "TestQueryPagerDuplicateTimes")
+	defer tearDown()
+
+	// Several rows have the same time, the batches split them. The times are
+	// binary fractions of the interval, they are stored without rounding
+	base := time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC).Unix()
+	tbk := io.NewTimeBucketKey("TEST-DUP/1Min/TICK")
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{base, base + 7, base + 7, base + 7, base + 15, base + 15, base + 30, base + 30,
+		base + 60, base + 75, base + 75, base + 75})
+	cs.AddColumn("Price", []float32{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})
+	cs.AddColumn("Nanoseconds", []int32{0, 5e8, 5e8, 5e8, 0, 0, 0, 0, 0, 0, 0, 0})
+	csm := io.NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	require.Nil(t, writer.WriteCSM(csm, true))
+
+	end := time.Unix(base+3600, 0)
+	for batchSize := 1; batchSize <= 5; batchSize++ {
+		// Each batch is read by a query resumed with the cursor of the previous one
+		var (
+			cursor *frontend.QueryCursor
+			prices []float32
+		)
+		for {
+			pager, err := frontend.NewQueryPager(metadata.CatalogDir, tbk, time.Unix(base, 0), end, nil,
+				batchSize, cursor)
+			require.Nil(t, err)
+			_, batch, err := pager.Next()
+			require.Nil(t, err)
+			if batch == nil {
+				break
+			}
+			prices = append(prices, batch.GetColumn("Price").([]float32)...)
+			cursor = pager.Cursor()
+		}
+		assert.Equal(t, cs.GetColumn("Price"), prices, "batch size %d", batchSize)
+	}
+}
+
+func TestQueryStreamHandler(t *testing.T) {
+	tearDown, _, metadata, _, _ := setup(t, "TestQueryStreamHandler")
+	defer tearDown()
+
+	mux := http.NewServeMux()
+	mux.Handle("/query/stream", frontend.NewQueryStreamHandler(metadata.CatalogDir))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cl, err := client.NewClient(srv.URL)
+	require.Nil(t, err)
+	req := &frontend.QueryStreamRequest{
+		Request: frontend.NewQueryRequestBuilder("USDJPY/1Min/OHLC").
+			EpochStart(test.ParseT("2002-10-01 00:00:00").Unix()).
+			EpochEnd(test.ParseT("2002-10-01 01:00:00").Unix()).
+			End(),
+		BatchSize: 25,
+	}
+
+	// The 61 rows come in 3 batches
+	var batches []int
+	err = cl.QueryStream(req, func(resp *frontend.QueryStreamResponse) bool {
+		cs, err2 := resp.Result.ToColumnSeries()
+		require.Nil(t, err2)
+		epochs := cs.GetEpoch()
+		assert.Equal(t, epochs[len(epochs)-1], resp.Cursor.Epoch)
+		batches = append(batches, cs.Len())
+		return true
+	})
+	require.Nil(t, err)
+	assert.Equal(t, []int{25, 25, 11}, batches)
+
+	// The client can stop after the first batch
+	batches = nil
+	err = cl.QueryStream(req, func(resp *frontend.QueryStreamResponse) bool {
+		batches = append(batches, resp.Result.Length)
+		return false
+	})
+	require.Nil(t, err)
+	assert.Len(t, batches, 1)
+
+	// SQL statements are rejected before streaming
+	req.Request = frontend.QueryRequest{IsSQLStatement: true, SQLStatement: "SELECT * FROM `USDJPY/1Min/OHLC`"}
+	err = cl.QueryStream(req, func(*frontend.QueryStreamResponse) bool { return true })
+	require.NotNil(t, err)
+	assert.True(t, strings.Contains(err.Error(), "can not be streamed"))
+}
+
+type queryStreamServer struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses []*proto.QueryStreamResponse
+}
+
+func (s *queryStreamServer) Context() context.Context { return s.ctx }
+
+func (s *queryStreamServer) Send(resp *proto.QueryStreamResponse) error {
+	s.responses = append(s.responses, resp)
+	return nil
+}
+
+func TestGRPCQueryStream(t *testing.T) {
+	tearDown, rootDir, metadata, writer, q := setup(t, "TestGRPCQueryStream")
+	defer tearDown()
+
+	service := frontend.NewGRPCService(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer, q)
+	req := &proto.QueryStreamRequest{
+		Request: &proto.QueryRequest{
+			Destination: "USDJPY/1Min/OHLC",
+			EpochStart:  test.ParseT("2002-10-01 00:00:00").Unix(),
+			EpochEnd:    test.ParseT("2002-10-01 01:00:00").Unix(),
+		},
+		BatchSize: 50,
+	}
+	stream := &queryStreamServer{ctx: context.Background()}
+	require.Nil(t, service.QueryStream(req, stream))
+	require.Len(t, stream.responses, 2)
+	assert.Equal(t, int32(11), stream.responses[1].Result.Data.Length)
+	assert.Equal(t, time.Date(2002, time.October, 1, 1, 0, 0, 0, time.UTC).Unix(),
+		stream.responses[1].Cursor.Epoch)
+
+	// Resuming after the first batch returns the last one
+	req.Cursor = stream.responses[0].Cursor
+	stream = &queryStreamServer{ctx: context.Background()}
+	require.Nil(t, service.QueryStream(req, stream))
+	require.Len(t, stream.responses, 1)
+	assert.Equal(t, int32(11), stream.responses[0].Result.Data.Length)
+
+	// Nothing is read once the client has gone
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stream = &queryStreamServer{ctx: ctx}
+	assert.NotNil(t, service.QueryStream(req, stream))
+	assert.Empty(t, stream.responses)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/alpacahq/rpc/rpc2/json2"
 
 	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor"
 	"github.com/alpacahq/marketstore/v4/metrics"
 	"github.com/alpacahq/marketstore/v4/sqlparser"
 	"github.com/alpacahq/marketstore/v4/utils"
@@ -22,6 +23,11 @@ var errNotQueryable = errors.New("server is not queryable")
 
 type Writer interface {
 	WriteCSM(csm io.ColumnSeriesMap, isVariableLength bool) error
+	WriteGroup(batches []executor.WriteBatch) (tgID int64, errs []error, err error)
+	Delete(tbk *io.TimeBucketKey, start, end time.Time) error
+	AlterBucket(tbk *io.TimeBucketKey, changes executor.ColumnChanges) error
+	Backup(dest string) (*executor.BackupManifest, error)
+	Compact(pattern *io.TimeBucketKey, year int16, minDeadRatio float64) ([]executor.CompactionResult, error)
 }
 
 type QueryInterface interface {
@@ -1,12 +1,20 @@
 package frontend_test
 
 import (
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/frontend/client"
 	"github.com/alpacahq/marketstore/v4/sqlparser"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
 )
 
 func TestNewServer(t *testing.T) {
@@ -16,3 +24,120 @@ func TestNewServer(t *testing.T) {
 	serv, _ := frontend.NewServer(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer, q)
 	assert.True(t, serv.HasMethod("DataService.Query"))
 }
+
+func TestServerAuthorization(t *testing.T) {
+	tearDown, rootDir, metadata, writer, q := setup(t, "TestServerAuthorization")
+	defer tearDown()
+
+	guard, err := auth.NewFromSetting(utils.AuthSetting{
+		APIKeys: map[string]string{"eurusd-key": "eurusd", "ops-key": "ops"},
+		Users:   map[string][]string{"eurusd": {"reader"}, "ops": {"ops"}},
+		Roles: map[string][]utils.ACLRule{
+			"reader": {{Keys: []string{"EURUSD/*/*"}, Verbs: []string{"read"}}},
+			"ops": {
+				{Keys: []string{"*/*/*"}, Verbs: []string{"read", "create"}},
+				{Keys: []string{"EURUSD/*/*"}, Verbs: []string{"destroy"}},
+			},
+		},
+	})
+	require.Nil(t, err)
+	serv, _ := frontend.NewServer(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer, q)
+	mux := http.NewServeMux()
+	mux.Handle("/rpc", guard.Handler(serv))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cl, err := client.NewClient(srv.URL)
+	require.Nil(t, err)
+	query := func(req frontend.QueryRequest) error {
+		_, err2 := cl.DoRPC("Query", &frontend.MultiQueryRequest{Requests: []frontend.QueryRequest{req}})
+		return err2
+	}
+
+	// no credentials
+	err = query(frontend.NewQueryRequestBuilder("EURUSD/1H/OHLC").End())
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "401")
+
+	cl.Token = "eurusd-key"
+	assert.Nil(t, query(frontend.NewQueryRequestBuilder("EURUSD/1H/OHLC").End()))
+	err = query(frontend.NewQueryRequestBuilder("EURUSD,USDJPY/1H/OHLC").End())
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+
+	// a pattern only reads the buckets that can be read
+	result, err := cl.DoRPC("Query", &frontend.MultiQueryRequest{Requests: []frontend.QueryRequest{
+		frontend.NewQueryRequestBuilder("*/1H/OHLC").End(),
+	}})
+	require.Nil(t, err)
+	csm := *result.(*io.ColumnSeriesMap)
+	require.Len(t, csm, 1)
+	for tbk := range csm {
+		assert.Equal(t, "EURUSD/1H/OHLC", tbk.GetItemKey())
+	}
+	assert.NotNil(t, query(frontend.NewQueryRequestBuilder("*/*/OHLC").End()))
+
+	assert.Nil(t, query(frontend.QueryRequest{IsSQLStatement: true, SQLStatement: "SELECT * FROM `EURUSD/1H/OHLC`;"}))
+	err = query(frontend.QueryRequest{IsSQLStatement: true, SQLStatement: "SELECT * FROM `USDJPY/1H/OHLC`;"})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+	assert.Nil(t, query(frontend.QueryRequest{IsSQLStatement: true, SQLStatement: "SELECT * FROM `EUR*/1H/OHLC`;"}))
+	err = query(frontend.QueryRequest{IsSQLStatement: true, SQLStatement: "SELECT * FROM `*/1H/OHLC`;"})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+
+	// only the readable buckets are listed
+	symbols, err := cl.DoRPC("ListSymbols", &frontend.ListSymbolsRequest{})
+	require.Nil(t, err)
+	assert.Equal(t, []string{"EURUSD"}, symbols)
+
+	resp, err := cl.DoRPC("Destroy", &frontend.MultiKeyRequest{Requests: []frontend.KeyRequest{{Key: "EURUSD/1H/OHLC"}}})
+	require.Nil(t, err)
+	assert.Contains(t, resp.(*frontend.MultiServerResponse).Responses[0].Error, "permission denied")
+	assert.Nil(t, query(frontend.NewQueryRequestBuilder("EURUSD/1H/OHLC").End()))
+
+	// a pattern is destroyed only if every bucket it matches can be destroyed
+	cl.Token = "ops-key"
+	rows := func(key string) int {
+		result, err2 := cl.DoRPC("Query", &frontend.MultiQueryRequest{Requests: []frontend.QueryRequest{
+			frontend.NewQueryRequestBuilder(key).End(),
+		}})
+		require.Nil(t, err2)
+		n := 0
+		for _, cs := range *result.(*io.ColumnSeriesMap) {
+			n += cs.Len()
+		}
+		return n
+	}
+	require.NotZero(t, rows("USDJPY/1H/OHLC"))
+	require.NotZero(t, rows("EURUSD/1H/OHLC"))
+	del := func(key string) string {
+		resp, err2 := cl.DoRPC("Delete", &frontend.MultiDeleteRequest{Requests: []frontend.DeleteRequest{
+			{Key: key, EpochEnd: math.MaxInt32},
+		}})
+		require.Nil(t, err2)
+		return resp.(*frontend.MultiServerResponse).Responses[0].Error
+	}
+	assert.Contains(t, del("*/1H/OHLC"), "permission denied")
+	assert.NotZero(t, rows("USDJPY/1H/OHLC"))
+	assert.NotZero(t, rows("EURUSD/1H/OHLC"))
+	assert.Empty(t, del("EUR*/1H/OHLC"))
+	assert.Zero(t, rows("EURUSD/1H/OHLC"))
+	assert.NotZero(t, rows("USDJPY/1H/OHLC"))
+
+	// dropping columns destroys them
+	alter := func(key string) string {
+		resp, err2 := cl.DoRPC("AlterBucket", &frontend.MultiAlterBucketRequest{
+			Requests: []frontend.AlterBucketRequest{{Key: key, DropColumnNames: []string{"Low"}}},
+		})
+		require.Nil(t, err2)
+		return resp.(*frontend.MultiServerResponse).Responses[0].Error
+	}
+	assert.Contains(t, alter("USDJPY/1H/OHLC"), "permission denied")
+	assert.Empty(t, alter("EURUSD/1H/OHLC"))
+
+	// a backup needs the administration of the database
+	_, err = cl.DoRPC("Backup", &frontend.BackupRequest{})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "permission denied")
+}
@@ -8,7 +8,8 @@
 // The only requirement in this layer is the server accepts the incoming connection
 // and receives the "subscribe" request from the client.  The subscribe request
 // must have a valid streaming channel format of TimeBucketKey with three elements
-// in it.  Currently we do not check th existence of the requested key.
+// in it.  Currently we do not check th existence of the requested key.  When the
+// authentication is enabled, the subscriber must be allowed to read the streams.
 //
 // A plugin can push a message by calling `Push`.  Each message data should be
 // enclosed by the structure with "key" (TimeBucketKey string) and "data" (opaque)
@@ -27,6 +28,7 @@ import (
 	"github.com/gorilla/websocket"
 	msgpack "github.com/vmihailenco/msgpack"
 
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
 	"github.com/alpacahq/marketstore/v4/metrics"
 	"github.com/alpacahq/marketstore/v4/utils/io"
 	"github.com/alpacahq/marketstore/v4/utils/log"
@@ -83,11 +85,17 @@ type Subscriber struct {
 	c       *websocket.Conn
 	done    chan struct{}
 	streams map[string]struct{}
+	// principal is the authenticated user of the connection, nil if the authentication is disabled
+	principal *auth.Principal
 }
 
 // Subscribed matches the subscriber's subscribed streams
-// with the supplied timebucket key string.
+// with the supplied timebucket key string, if the subscriber
+// can read its bucket.
 func (s *Subscriber) Subscribed(itemKey string) bool {
+	if !s.principal.Allowed(auth.Read, itemKey) {
+		return false
+	}
 	s.RLock()
 	defer s.RUnlock()
 	for stream := range s.streams {
@@ -131,6 +139,12 @@ func (s *Subscriber) handleInbound(msg SubscribeMessage) error {
 			if !validStream(stream) {
 				return fmt.Errorf("%s is an invalid stream", stream)
 			}
+			// the buckets of a pattern are authorized when they are streamed, see Subscribed
+			if !auth.IsPattern(stream) {
+				if err := s.principal.Authorize(auth.Read, stream); err != nil {
+					return err
+				}
+			}
 			m[stream] = struct{}{}
 		}
 		s.streams = m
@@ -254,8 +268,22 @@ func Initialize() {
 // Handler hooks into the HTTP interface and handles the incoming
 // streaming requests, and upgrades the connection.
 func Handler(w http.ResponseWriter, r *http.Request) {
+	serve(&upgrader, w, r)
+}
+
+// NewHandler returns a Handler which only upgrades the connections
+// whose origin is accepted by checkOrigin, the subscriber is the
+// principal of the request context (see auth.Guard.Handler).
+func NewHandler(checkOrigin func(r *http.Request) bool) http.HandlerFunc {
+	u := websocket.Upgrader{CheckOrigin: checkOrigin}
+	return func(w http.ResponseWriter, r *http.Request) {
+		serve(&u, w, r)
+	}
+}
+
+func serve(u *websocket.Upgrader, w http.ResponseWriter, r *http.Request) {
 	// upgrade the socket
-	ws, err := upgrader.Upgrade(w, r, nil)
+	ws, err := u.Upgrade(w, r, nil)
 	if err != nil {
 		log.Error("failed to upgrade stream socket (%s)", err)
 		return
@@ -263,8 +291,9 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 
 	// build the subscriber
 	s := &Subscriber{
-		c:    ws,
-		done: make(chan struct{}),
+		c:         ws,
+		done:      make(chan struct{}),
+		principal: auth.FromContext(r.Context()),
 	}
 
 	if s.c != nil {
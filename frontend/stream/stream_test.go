@@ -15,7 +15,9 @@ import (
 	"github.com/vmihailenco/msgpack"
 
 	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
 	"github.com/alpacahq/marketstore/v4/frontend/stream"
+	"github.com/alpacahq/marketstore/v4/utils"
 	"github.com/alpacahq/marketstore/v4/utils/io"
 	"github.com/alpacahq/marketstore/v4/utils/log"
 	"github.com/alpacahq/marketstore/v4/utils/test"
@@ -144,6 +146,63 @@ func TestStream(t *testing.T) {
 	}
 }
 
+func TestStreamAuthorization(t *testing.T) {
+	tearDown := setup(t, "TestStreamAuthorization")
+	defer tearDown()
+
+	guard, err := auth.NewFromSetting(utils.AuthSetting{
+		APIKeys: map[string]string{"aapl-key": "aapl"},
+		Users:   map[string][]string{"aapl": {"reader"}},
+		Roles: map[string][]utils.ACLRule{
+			"reader": {{Keys: []string{"AAPL/*/*"}, Verbs: []string{"read"}}},
+		},
+	})
+	assert.Nil(t, err)
+	srv := httptest.NewServer(guard.Handler(stream.NewHandler(guard.CheckOrigin)))
+	defer srv.Close()
+
+	u, _ := url.Parse(srv.URL + "/ws")
+	u.Scheme = "ws"
+
+	// no credentials
+	_, resp, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	assert.NotNil(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// a foreign origin
+	header := http.Header{"Authorization": {"Bearer aapl-key"}, "Origin": {"https://evil.example.com"}}
+	_, _, err = websocket.DefaultDialer.Dial(u.String(), header)
+	assert.NotNil(t, err)
+
+	conn, _, err := websocket.DefaultDialer.Dial(u.String(), http.Header{"Authorization": {"Bearer aapl-key"}})
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	subscribe := func(streams ...string) string {
+		buf, err2 := msgpack.Marshal(stream.SubscribeMessage{Streams: streams})
+		assert.Nil(t, err2)
+		assert.Nil(t, conn.WriteMessage(websocket.BinaryMessage, buf))
+		_, buf, err2 = conn.ReadMessage()
+		assert.Nil(t, err2)
+		msg := stream.ErrorMessage{}
+		assert.Nil(t, msgpack.Unmarshal(buf, &msg))
+		return msg.Error
+	}
+	assert.Empty(t, subscribe("AAPL/5Min/OHLCV", "AAPL/*/*"))
+	assert.Contains(t, subscribe("AAPL/5Min/OHLCV", "TSLA/1D/OHLCV"), "permission denied")
+
+	// a pattern only streams the buckets that can be read
+	assert.Empty(t, subscribe("*/1D/OHLCV"))
+	stream.Push(*io.NewTimeBucketKey("TSLA/1D/OHLCV"), genColumns())
+	stream.Push(*io.NewTimeBucketKey("AAPL/1D/OHLCV"), genColumns())
+	assert.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, buf, err := conn.ReadMessage()
+	assert.Nil(t, err)
+	var payload *stream.Payload
+	assert.Nil(t, msgpack.Unmarshal(buf, &payload))
+	assert.Equal(t, "AAPL/1D/OHLCV", payload.Key)
+}
+
 func genColumns() map[string]interface{} {
 	return map[string]interface{}{
 		"Open":   float32(1.0),
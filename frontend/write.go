@@ -6,6 +6,9 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
 	"github.com/alpacahq/marketstore/v4/utils"
 	"github.com/alpacahq/marketstore/v4/utils/io"
 )
@@ -15,6 +18,8 @@ const colonSeparatedPartsLen = 2 // expecting a key string like "TSLA/1Min/OHLCV
 type WriteRequest struct {
 	Data             *io.NumpyMultiDataset `msgpack:"dataset"`
 	IsVariableLength bool                  `msgpack:"is_variable_length"`
+	// Arrow IPC streams to write instead of the dataset, each with its time bucket key in the schema metadata
+	Arrow [][]byte `msgpack:"arrow,omitempty"`
 }
 
 type MultiWriteRequest struct {
@@ -33,13 +38,18 @@ type MultiServerResponse struct {
 	Responses []ServerResponse `msgpack:"responses"`
 }
 
-func (s *DataService) Write(_ *http.Request, reqs *MultiWriteRequest, response *MultiServerResponse) (err error) {
+func (s *DataService) Write(r *http.Request, reqs *MultiWriteRequest, response *MultiServerResponse) (err error) {
+	principal := principalOf(r)
 	for _, req := range reqs.Requests {
-		csm, err := req.Data.ToColumnSeriesMap()
+		csm, err := writeRequestSeries(req.Data, req.Arrow)
 		if err != nil {
 			response.appendResponse(err)
 			continue
 		}
+		if err = authorizeWrite(principal, csm); err != nil {
+			response.appendResponse(err)
+			continue
+		}
 		if err = s.writer.WriteCSM(csm, req.IsVariableLength); err != nil {
 			response.appendResponse(err)
 			continue
@@ -50,6 +60,17 @@ func (s *DataService) Write(_ *http.Request, reqs *MultiWriteRequest, response *
 	return nil
 }
 
+// writeRequestSeries returns the series of the dataset of a write request, or of its Arrow streams.
+func writeRequestSeries(nmds *io.NumpyMultiDataset, streams [][]byte) (io.ColumnSeriesMap, error) {
+	if streams != nil {
+		return fromArrowStreams(streams)
+	}
+	if nmds == nil {
+		return nil, fmt.Errorf("write request without data")
+	}
+	return nmds.ToColumnSeriesMap()
+}
+
 /*
 	Create: Creates a new time bucket in the DB
 */
@@ -61,13 +82,16 @@ type CreateRequest struct {
 	// a list of column names
 	ColumnNames      []string `msgpack:"column_names"`
 	IsVariableLength bool     `msgpack:"is_variable_length"`
+	// Nullable adds a validity bitmap to the bucket, so that its values can be NULL
+	Nullable bool `msgpack:"nullable"`
 }
 
 type MultiCreateRequest struct {
 	Requests []CreateRequest `msgpack:"requests"`
 }
 
-func (s *DataService) Create(_ *http.Request, reqs *MultiCreateRequest, response *MultiServerResponse) (err error) {
+func (s *DataService) Create(r *http.Request, reqs *MultiCreateRequest, response *MultiServerResponse) (err error) {
+	principal := principalOf(r)
 	for _, req := range reqs.Requests {
 		// Construct a time bucket key from the input string
 		parts := strings.Split(req.Key, ":")
@@ -87,6 +111,10 @@ func (s *DataService) Create(_ *http.Request, reqs *MultiCreateRequest, response
 			response.appendResponse(err)
 			continue
 		}
+		if err = principal.Authorize(auth.Create, tbk.GetItemKey()); err != nil {
+			response.appendResponse(err)
+			continue
+		}
 
 		// --- Timeframe
 		year := int16(time.Now().Year())
@@ -107,13 +135,19 @@ func (s *DataService) Create(_ *http.Request, reqs *MultiCreateRequest, response
 		// --- DataShapes
 		dsv := make([]io.DataShape, len(req.ColumnNames))
 		for i, name := range req.ColumnNames {
-			t, ok := io.TypeStrToElemType(req.ColumnTypes[i])
+			ds, ok := io.TypeStrToDataShape(name, req.ColumnTypes[i])
 			if !ok {
 				response.appendResponse(fmt.Errorf("unexpected data type:%v", req.ColumnTypes[i]))
 				return nil
 			}
 
-			dsv[i] = io.DataShape{Name: name, Type: t}
+			dsv[i] = ds
+		}
+		if req.Nullable {
+			if dsv, err = io.NullableDataShapes(dsv); err != nil {
+				response.appendResponse(err)
+				continue
+			}
 		}
 
 		tbinfo := io.NewTimeBucketInfo(*tf, tbk.GetPathToYearFiles(s.rootDir), "Default", year, dsv, recordType)
@@ -149,9 +183,10 @@ type MultiGetInfoResponse struct {
 	Responses []GetInfoResponse `msgpack:"responses"`
 }
 
-func (s *DataService) GetInfo(_ *http.Request, reqs *MultiKeyRequest, response *MultiGetInfoResponse) (err error) {
+func (s *DataService) GetInfo(r *http.Request, reqs *MultiKeyRequest, response *MultiGetInfoResponse) (err error) {
 	const errorString = "key \"%s\" is not in proper format, should be like: TSLA/1Min/OHLCV"
 
+	principal := principalOf(r)
 	for _, req := range reqs.Requests {
 		// Construct a time bucket key from the input string
 		parts := strings.Split(req.Key, ":")
@@ -166,6 +201,10 @@ func (s *DataService) GetInfo(_ *http.Request, reqs *MultiKeyRequest, response *
 			response.appendResponse(nil, err)
 			continue
 		}
+		if err = principal.Authorize(auth.Read, tbk.GetItemKey()); err != nil {
+			response.appendResponse(nil, err)
+			continue
+		}
 
 		tbi, err := s.catalogDir.GetLatestTimeBucketInfoFromKey(tbk)
 		if err != nil {
@@ -179,9 +218,10 @@ func (s *DataService) GetInfo(_ *http.Request, reqs *MultiKeyRequest, response *
 	return nil
 }
 
-func (s *DataService) Destroy(_ *http.Request, reqs *MultiKeyRequest, response *MultiServerResponse) (err error) {
+func (s *DataService) Destroy(r *http.Request, reqs *MultiKeyRequest, response *MultiServerResponse) (err error) {
 	errorString := "key \"%s\" is not in proper format, should be like: TSLA/1Min/OHLCV"
 
+	principal := principalOf(r)
 	for _, req := range reqs.Requests {
 		// Construct a time bucket key from the input string
 		parts := strings.Split(req.Key, ":")
@@ -196,6 +236,10 @@ func (s *DataService) Destroy(_ *http.Request, reqs *MultiKeyRequest, response *
 			response.appendResponse(err)
 			continue
 		}
+		if err = principal.Authorize(auth.Destroy, tbk.GetItemKey()); err != nil {
+			response.appendResponse(err)
+			continue
+		}
 
 		err = s.catalogDir.RemoveTimeBucket(tbk)
 		if err != nil {
@@ -209,10 +253,161 @@ func (s *DataService) Destroy(_ *http.Request, reqs *MultiKeyRequest, response *
 	return nil
 }
 
+/*
+	Delete: Deletes the rows of a time range in the DB
+*/
+type DeleteRequest struct {
+	// bucket key string, the symbol may be a list or a wildcard. e.g. "TSLA,F/1Min/OHLC" or "*/1Min/OHLC"
+	Key string `msgpack:"key"`
+	// the rows timestamped from the start to the end, both included, are deleted
+	EpochStart      int64 `msgpack:"epoch_start"`
+	EpochStartNanos int64 `msgpack:"epoch_start_nanos"`
+	EpochEnd        int64 `msgpack:"epoch_end"`
+	EpochEndNanos   int64 `msgpack:"epoch_end_nanos"`
+}
+
+type MultiDeleteRequest struct {
+	Requests []DeleteRequest `msgpack:"requests"`
+}
+
+func (s *DataService) Delete(r *http.Request, reqs *MultiDeleteRequest, response *MultiServerResponse) (err error) {
+	for _, req := range reqs.Requests {
+		response.appendResponse(deleteRange(principalOf(r), s.catalogDir, s.writer, req.Key,
+			time.Unix(req.EpochStart, req.EpochStartNanos), time.Unix(req.EpochEnd, req.EpochEndNanos)))
+	}
+	return nil
+}
+
+/*
+deleteRange deletes the rows of a time range from the buckets matching a key string, it destroys data so each
+bucket must allow it.
+*/
+func deleteRange(principal *auth.Principal, catDir *catalog.Directory, w Writer, key string, start, end time.Time,
+) error {
+	const errorString = "key \"%s\" is not in proper format, should be like: TSLA/1Min/OHLCV"
+
+	// The schema string is optional, so we append a blank if none is provided
+	parts := strings.Split(key, ":")
+	if len(parts) < colonSeparatedPartsLen {
+		parts = append(parts, "")
+	}
+	tbk := io.NewTimeBucketKey(parts[0], parts[1])
+	if tbk == nil {
+		return fmt.Errorf(errorString, key)
+	}
+	keys, err := authorizeKeys(principal, auth.Destroy, catDir, tbk)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("delete of %s failed: no bucket matches %s", key, tbk.GetItemKey())
+	}
+	// The buckets are deleted one by one, a bucket created since they were authorized does not match
+	for _, k := range keys {
+		if err = w.Delete(k, io.ToSystemTimezone(start), io.ToSystemTimezone(end)); err != nil {
+			return fmt.Errorf("delete of %s failed: %w", key, err)
+		}
+	}
+	return nil
+}
+
+/*
+	AlterBucket: Changes the columns of an existing bucket
+*/
+type AlterBucketRequest struct {
+	// bucket key string. e.g. "TSLA/1Min/OHLCV"
+	Key string `msgpack:"key"`
+	// the columns added to the bucket and their types (e.g. "f4","i8"), filled with zeros in the existing rows
+	AddColumnNames []string `msgpack:"add_column_names"`
+	AddColumnTypes []string `msgpack:"add_column_types"`
+	// the columns removed from the bucket
+	DropColumnNames []string `msgpack:"drop_column_names"`
+	// the columns whose values are converted to a new numeric type
+	RetypeColumnNames []string `msgpack:"retype_column_names"`
+	RetypeColumnTypes []string `msgpack:"retype_column_types"`
+	// Nullable adds a validity bitmap to a bucket that has none, the existing values are not NULL
+	Nullable bool `msgpack:"nullable"`
+}
+
+type MultiAlterBucketRequest struct {
+	Requests []AlterBucketRequest `msgpack:"requests"`
+}
+
+func (s *DataService) AlterBucket(r *http.Request, reqs *MultiAlterBucketRequest, response *MultiServerResponse,
+) (err error) {
+	for i := range reqs.Requests {
+		response.appendResponse(alterBucket(principalOf(r), s.writer, &reqs.Requests[i]))
+	}
+	return nil
+}
+
+/*
+alterBucket changes the columns of the bucket of an alter request, it is allowed like a creation, and the drop
+of columns also needs the permission to destroy.
+*/
+func alterBucket(principal *auth.Principal, w Writer, req *AlterBucketRequest) error {
+	const errorString = "key \"%s\" is not in proper format, should be like: TSLA/1Min/OHLCV"
+
+	parts := strings.Split(req.Key, ":")
+	if len(parts) < colonSeparatedPartsLen {
+		parts = append(parts, "")
+	}
+	tbk := io.NewTimeBucketKey(parts[0], parts[1])
+	if tbk == nil {
+		return fmt.Errorf(errorString, req.Key)
+	}
+	if err := principal.Authorize(auth.Create, tbk.GetItemKey()); err != nil {
+		return err
+	}
+	if len(req.DropColumnNames) != 0 {
+		if err := principal.Authorize(auth.Destroy, tbk.GetItemKey()); err != nil {
+			return err
+		}
+	}
+	add, err := columnDataShapes(req.AddColumnNames, req.AddColumnTypes)
+	if err != nil {
+		return err
+	}
+	retype, err := columnDataShapes(req.RetypeColumnNames, req.RetypeColumnTypes)
+	if err != nil {
+		return err
+	}
+	changes := executor.ColumnChanges{Add: add, Drop: req.DropColumnNames, Retype: retype, Nullable: req.Nullable}
+	if err = w.AlterBucket(tbk, changes); err != nil {
+		return fmt.Errorf("alter of %s failed: %w", req.Key, err)
+	}
+	return nil
+}
+
+func columnDataShapes(names, types []string) ([]io.DataShape, error) {
+	if len(names) != len(types) {
+		return nil, fmt.Errorf("%d column names for %d column types", len(names), len(types))
+	}
+	dsv := make([]io.DataShape, len(names))
+	for i, name := range names {
+		ds, ok := io.TypeStrToDataShape(name, types[i])
+		if !ok {
+			return nil, fmt.Errorf("unexpected data type:%v", types[i])
+		}
+		dsv[i] = ds
+	}
+	return dsv, nil
+}
+
 /*
 Utility functions
 */
 
+// authorizeWrite authorizes the write of the buckets of a series map.
+func authorizeWrite(principal *auth.Principal, csm io.ColumnSeriesMap) error {
+	for tbk := range csm {
+		if err := principal.Authorize(auth.Write, tbk.GetItemKey()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (mr *MultiServerResponse) appendResponse(err error) {
 	var errorText string
 	if err == nil {
@@ -1,13 +1,16 @@
 package frontend_test
 
 import (
+	"context"
 	"strconv"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/proto"
 	"github.com/alpacahq/marketstore/v4/sqlparser"
 	"github.com/alpacahq/marketstore/v4/utils/io"
 )
@@ -103,3 +106,73 @@ func TestWrite(t *testing.T) {
 		assert.Equal(t, ti, tref)
 	}
 }
+
+// queryLen returns the number of rows of each bucket of a destination in a time range.
+func queryLen(t *testing.T, service *frontend.DataService, dest string, start, end time.Time) map[string]int {
+	t.Helper()
+
+	qargs := &frontend.MultiQueryRequest{
+		Requests: []frontend.QueryRequest{
+			frontend.NewQueryRequestBuilder(dest).EpochStart(start.Unix()).EpochEnd(end.Unix()).End(),
+		},
+	}
+	var qresponse frontend.MultiQueryResponse
+	require.Nil(t, service.Query(nil, qargs, &qresponse))
+	csm, err := qresponse.Responses[0].Result.ToColumnSeriesMap()
+	require.Nil(t, err)
+	lens := make(map[string]int)
+	for tbk, cs := range csm {
+		lens[tbk.GetItemInCategory("Symbol")] = cs.Len()
+	}
+	return lens
+}
+
+func TestDelete(t *testing.T) {
+	tearDown, rootDir, metadata, writer, q := setup(t, "TestDelete")
+	defer tearDown()
+
+	service := frontend.NewDataService(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer, q)
+	service.Init()
+
+	start := time.Date(2002, time.October, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2002, time.October, 1, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, map[string]int{"EURUSD": 24, "NZDUSD": 24, "USDJPY": 24},
+		queryLen(t, service, "EURUSD,NZDUSD,USDJPY/1H/OHLC", start, end))
+
+	// The symbol can be a wildcard
+	args := &frontend.MultiDeleteRequest{
+		Requests: []frontend.DeleteRequest{
+			{
+				Key:        "*/1H/OHLC",
+				EpochStart: time.Date(2002, time.October, 1, 10, 0, 0, 0, time.UTC).Unix(),
+				EpochEnd:   time.Date(2002, time.October, 1, 13, 0, 0, 0, time.UTC).Unix(),
+			},
+			{
+				Key:        "NOPE/1H/OHLC",
+				EpochStart: start.Unix(),
+				EpochEnd:   end.Unix(),
+			},
+		},
+	}
+	var response frontend.MultiServerResponse
+	require.Nil(t, service.Delete(nil, args, &response))
+	require.Len(t, response.Responses, 2)
+	assert.Empty(t, response.Responses[0].Error)
+	assert.Contains(t, response.Responses[1].Error, "no bucket matches")
+	assert.Equal(t, map[string]int{"EURUSD": 20, "NZDUSD": 20, "USDJPY": 20},
+		queryLen(t, service, "EURUSD,NZDUSD,USDJPY/1H/OHLC", start, end))
+
+	// The same over gRPC, for a list of symbols
+	grpcService := frontend.NewGRPCService(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer, q)
+	grpcResponse, err := grpcService.Delete(context.Background(), &proto.MultiDeleteRequest{
+		Requests: []*proto.DeleteRequest{{
+			Key:        "EURUSD,USDJPY/1H/OHLC",
+			EpochStart: start.Unix(),
+			EpochEnd:   time.Date(2002, time.October, 1, 1, 0, 0, 0, time.UTC).Unix(),
+		}},
+	})
+	require.Nil(t, err)
+	assert.Empty(t, grpcResponse.Responses[0].Error)
+	assert.Equal(t, map[string]int{"EURUSD": 18, "NZDUSD": 20, "USDJPY": 18},
+		queryLen(t, service, "EURUSD,NZDUSD,USDJPY/1H/OHLC", start, end))
+}
@@ -0,0 +1,145 @@
+package frontend_test
+
+import (
+	"context"
+	goio "io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/alpacahq/marketstore/v4/frontend"
+	"github.com/alpacahq/marketstore/v4/frontend/auth"
+	"github.com/alpacahq/marketstore/v4/proto"
+	"github.com/alpacahq/marketstore/v4/sqlparser"
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// grpcClient serves the gRPC service on an in-memory connection, with authentication.
+func grpcClient(t *testing.T, service *frontend.GRPCService) (client proto.MarketstoreClient, closer func()) {
+	t.Helper()
+
+	guard, err := auth.NewFromSetting(utils.AuthSetting{
+		APIKeys: map[string]string{"reader-key": "reader", "writer-key": "writer"},
+		Users:   map[string][]string{"reader": {"reader"}, "writer": {"writer"}},
+		Roles: map[string][]utils.ACLRule{
+			"reader": {{Keys: []string{"*/*/*"}, Verbs: []string{"read"}}},
+			"writer": {{Keys: []string{"TEST/*/*"}, Verbs: []string{"write", "create"}}},
+		},
+	})
+	require.Nil(t, err)
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(guard.UnaryServerInterceptor),
+		grpc.StreamInterceptor(guard.StreamServerInterceptor),
+	)
+	proto.RegisterMarketstoreServer(server, service)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(lis) }()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }))
+	require.Nil(t, err)
+	return proto.NewMarketstoreClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+// tickBatch returns a write stream request of a bucket with the Price and the extra columns.
+func tickBatch(t *testing.T, key string, epochs []int64, extra ...string) *proto.WriteStreamRequest {
+	t.Helper()
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", epochs)
+	prices := make([]float64, len(epochs))
+	for i := range prices {
+		prices[i] = float64(i) + 0.5
+	}
+	cs.AddColumn("Price", prices)
+	for _, name := range extra {
+		cs.AddColumn(name, make([]int64, len(epochs)))
+	}
+	nds, err := io.NewNumpyDataset(cs)
+	require.Nil(t, err)
+	nmds, err := io.NewNumpyMultiDataset(nds, *io.NewTimeBucketKey(key))
+	require.Nil(t, err)
+	return &proto.WriteStreamRequest{Request: &proto.WriteRequest{Data: frontend.ToProtoNumpyMultiDataSet(nmds)}}
+}
+
+func TestGRPCWriteStream(t *testing.T) {
+	tearDown, rootDir, metadata, writer, q := setup(t, "TestGRPCWriteStream")
+	defer tearDown()
+
+	service := frontend.NewGRPCService(rootDir, metadata.CatalogDir, sqlparser.NewAggRunner(nil), writer, q)
+	client, closer := grpcClient(t, service)
+	defer closer()
+
+	stream, err := client.WriteStream(withKey("writer-key"))
+	require.Nil(t, err)
+	for _, req := range []*proto.WriteStreamRequest{
+		tickBatch(t, "TEST/1Sec/Tick", []int64{1600000000, 1600000001}),
+		{},
+		tickBatch(t, "EURUSD/1Sec/Tick", []int64{1600000000}),
+		tickBatch(t, "TEST/1Sec/Tick", []int64{1600000002}),
+		tickBatch(t, "TEST/1Sec/Tick", []int64{1600000003}, "Size"),
+	} {
+		require.Nil(t, stream.Send(req))
+	}
+	require.Nil(t, stream.CloseSend())
+
+	// The groups acknowledge all the batches in order, with the errors of the failed ones
+	var (
+		next = uint64(1)
+		tgID int64
+	)
+	errs := map[uint64]string{}
+	for {
+		resp, err := stream.Recv()
+		if err == goio.EOF {
+			break
+		}
+		require.Nil(t, err)
+		assert.Equal(t, next, resp.FirstSequence)
+		next = resp.LastSequence + 1
+		assert.GreaterOrEqual(t, resp.Tgid, tgID)
+		tgID = resp.Tgid
+		for _, e := range resp.Errors {
+			errs[e.Sequence] = e.Error
+		}
+	}
+	assert.Equal(t, uint64(6), next)
+	assert.Greater(t, tgID, int64(0))
+	require.Len(t, errs, 3)
+	assert.Contains(t, errs[2], "without data")
+	assert.Contains(t, errs[3], auth.ErrPermissionDenied.Error())
+	assert.Contains(t, errs[5], "unable to match data columns")
+
+	cs := queryAll(t, q, "TEST/1Sec/Tick")
+	require.NotNil(t, cs)
+	assert.Equal(t, []int64{1600000000, 1600000001, 1600000002}, cs.GetEpoch())
+	assert.Equal(t, []float64{0.5, 1.5, 0.5}, cs.GetColumn("Price"))
+
+	// A stream of a reader is refused
+	stream, err = client.WriteStream(withKey("reader-key"))
+	require.Nil(t, err)
+	require.Nil(t, stream.Send(tickBatch(t, "TEST/1Sec/Tick", []int64{1600000004})))
+	resp, err := stream.Recv()
+	require.Nil(t, err)
+	require.Len(t, resp.Errors, 1)
+	assert.Contains(t, resp.Errors[0].Error, auth.ErrPermissionDenied.Error())
+	assert.Equal(t, int64(0), resp.Tgid)
+	require.Nil(t, stream.CloseSend())
+	_, err = stream.Recv()
+	assert.Equal(t, goio.EOF, err)
+
+	// So is a stream without credentials
+	stream, err = client.WriteStream(context.Background())
+	require.Nil(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -17,11 +17,16 @@ type Setter interface {
 // StartDiskUsageMonitor retrieves the total disk usage of the provided directory at each provided time interval,
 // and set it as a prometheus metric.
 func StartDiskUsageMonitor(s Setter, rootDir string, interval time.Duration) {
-	s.Set(float64(diskUsage(rootDir)))
+	StartUsageMonitor(s, func() int64 { return diskUsage(rootDir) }, interval)
+}
+
+// StartUsageMonitor sets the size returned by usage as a prometheus metric at each provided time interval.
+func StartUsageMonitor(s Setter, usage func() int64, interval time.Duration) {
+	s.Set(float64(usage()))
 
 	t := time.NewTicker(interval)
 	for range t.C {
-		s.Set(float64(diskUsage(rootDir)))
+		s.Set(float64(usage()))
 	}
 }
 
@@ -58,12 +58,72 @@ var (
 		Buckets:   []float64{.0001, .001, .005, .01, .05, .1, .25, .5, 1},
 	})
 
-	// TotalDiskUsageBytes stores the total size of DB files managed by Marketstore.
-	TotalDiskUsageBytes = promauto.NewGauge(
+	// TotalDiskUsageBytes stores the total size of DB files managed by Marketstore, partitioned by storage tier:
+	// "local" for the root directory, "cache" for the cache of the offloaded years and "remote" for the years
+	// offloaded to the tiered storage.
+	TotalDiskUsageBytes = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: namespace,
 			Subsystem: subsystem,
 			Name:      "total_disk_usage_bytes",
-			Help:      "Total disk usage [bytes] of the Marketstore data files",
-		})
+			Help:      "Total disk usage [bytes] of the Marketstore data files partitioned by storage tier",
+		}, []string{"tier"})
+
+	// RetentionDroppedYears counts the year files removed by the retention policies, partitioned by policy
+	// pattern. The years a dry run would remove are counted with dry_run="true".
+	RetentionDroppedYears = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "retention_dropped_years_total",
+		Help:      "Number of year files removed by the retention policies",
+	}, []string{"pattern", "dry_run"})
+
+	// RetentionDroppedBytes counts the size of the year files removed by the retention policies.
+	RetentionDroppedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "retention_dropped_bytes_total",
+		Help:      "Size [bytes] of the year files removed by the retention policies",
+	}, []string{"pattern", "dry_run"})
+
+	// RetentionTruncatedYears counts the years whose expired rows are deleted by the retention policies.
+	RetentionTruncatedYears = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "retention_truncated_years_total",
+		Help:      "Number of years whose expired rows are deleted by the retention policies",
+	}, []string{"pattern", "dry_run"})
+
+	// RetentionLastRunTimestamp stores the time of the last application of the retention policies.
+	RetentionLastRunTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "retention_last_run_timestamp_seconds",
+		Help:      "Unix time of the last application of the retention policies",
+	})
+
+	// CompactionReclaimedBytes counts the space freed by the compaction of the variable length buckets.
+	CompactionReclaimedBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "compaction_reclaimed_bytes_total",
+		Help:      "Size [bytes] of the dead space freed by the compaction of the year files",
+	})
+
+	// CompactionLastRunTimestamp stores the time of the last background compaction.
+	CompactionLastRunTimestamp = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "compaction_last_run_timestamp_seconds",
+		Help:      "Unix time of the last background compaction of the variable length buckets",
+	})
+
+	// AuthDenied counts the requests denied by the authentication and the ACLs, partitioned by the verb
+	// they were denied, "authenticate" for the requests without valid credentials.
+	AuthDenied = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "auth_denied_total",
+		Help:      "Number of requests denied by the authentication and the ACLs",
+	}, []string{"verb"})
 )
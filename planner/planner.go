@@ -67,6 +67,16 @@ func NewRowLimit() *RowLimit {
 	return &r
 }
 
+/*
+ColumnRange bounds the values of a column that a query looks for, Min and Max included. The reader skips
+the blocks of rows that have no value in the range according to the block index of the files, but does not
+filter the rows it reads one by one.
+*/
+type ColumnRange struct {
+	Name     string
+	Min, Max float64
+}
+
 type QualifiedFile struct {
 	Key  TimeBucketKey
 	File *TimeBucketInfo
@@ -79,6 +89,8 @@ type ParseResult struct {
 	IntervalsPerDay int64
 	RootDir         string
 	TimeQuals       []TimeQualFunc
+	// ColumnRanges are ignored by a read with a row limit
+	ColumnRanges []ColumnRange
 }
 
 func NewParseResult() *ParseResult {
@@ -102,11 +114,7 @@ func (pr *ParseResult) GetDataShapes() (dsv map[TimeBucketKey][]DataShape) {
 		/*
 			Prepend the Epoch column info, as it is not present in the file info but it is in the query data
 		*/
-		names := []string{"Epoch"}
-		types := []EnumElementType{INT64}
-		names = append(names, qf.File.GetElementNames()...)
-		types = append(types, qf.File.GetElementTypes()...)
-		dsv[qf.Key] = NewDataShapeVector(names, types)
+		dsv[qf.Key] = qf.File.GetDataShapesWithEpoch()
 	}
 	return dsv
 }
@@ -152,11 +160,12 @@ func NamesMatch(src, candidates []string) (match bool) {
 }
 
 type query struct {
-	Range       *DateRange
-	Restriction RestrictionList
-	Limit       *RowLimit
-	DataDir     *Directory
-	TimeQuals   []TimeQualFunc
+	Range        *DateRange
+	Restriction  RestrictionList
+	Limit        *RowLimit
+	DataDir      *Directory
+	TimeQuals    []TimeQualFunc
+	ColumnRanges []ColumnRange
 }
 
 func NewQuery(d *Directory) *query {
@@ -215,6 +224,10 @@ func (q *query) AddTimeQual(timeQual TimeQualFunc) {
 	q.TimeQuals = append(q.TimeQuals, timeQual)
 }
 
+func (q *query) AddColumnRange(name string, min, max float64) {
+	q.ColumnRanges = append(q.ColumnRanges, ColumnRange{Name: name, Min: min, Max: max})
+}
+
 func (q *query) Parse() (pr *ParseResult, err error) {
 	const notFoundErrMsg = "no files returned from query parse"
 	// Check to see that the categories in the query are present in the DB directory
@@ -334,5 +347,6 @@ func (q *query) Parse() (pr *ParseResult, err error) {
 			utils.InstanceConfig.Timezone)
 	}
 	pr.TimeQuals = q.TimeQuals
+	pr.ColumnRanges = q.ColumnRanges
 	return pr, nil
 }
@@ -17,6 +17,8 @@
 //
 // The "on" value is matched with the file path to decide whether the trigger
 // is fired or not.  It can contain wildcard character "*".
+// Rows removed by the Delete API are reported to the triggers implementing
+// DeleteTrigger.
 // As of now, trigger fires only on the running state.  Trigger on WAL replay
 // may be added later.
 package trigger
@@ -42,6 +44,17 @@ type Trigger interface {
 	Fire(keyPath string, records []Record)
 }
 
+// DeleteTrigger is implemented by the triggers that also need to know about
+// deleted rows, such as the ones keeping data derived from the modified file.
+type DeleteTrigger interface {
+	Trigger
+	// FireDelete is called when rows have been deleted from the target file.
+	// indexes are the indexes of the deleted rows.  In a variable length
+	// bucket, an index is an interval that lost some or all of its rows, the
+	// rows left are rewritten and reported to Fire.
+	FireDelete(keyPath string, indexes []int64)
+}
+
 // TriggerMatcher checks if the trigger should be fired or not.
 type TriggerMatcher struct {
 	Trigger Trigger
@@ -112,6 +125,9 @@ func RecordsToColumnSeries(
 		cs.AddColumn(s.Name, s.Type.ConvertByteSliceInto(data))
 		index += s.Len()
 	}
+	if io.IsNullable(ds) {
+		cs.UnpackNulls(ds)
+	}
 
 	return cs
 }
@@ -464,7 +464,9 @@ type QueryRequest struct {
 	// Array of column names to be returned
 	Columns []string `protobuf:"bytes,11,rep,name=columns,proto3" json:"columns,omitempty"`
 	// Support for functions is experimental and subject to change
-	Functions            []string `protobuf:"bytes,12,rep,name=functions,proto3" json:"functions,omitempty"`
+	Functions []string `protobuf:"bytes,12,rep,name=functions,proto3" json:"functions,omitempty"`
+	// "arrow" returns the results as Arrow IPC streams, the default is a NumpyMultiDataset
+	Format               string   `protobuf:"bytes,13,opt,name=format,proto3" json:"format,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -579,6 +581,13 @@ func (m *QueryRequest) GetFunctions() []string {
 	return nil
 }
 
+func (m *QueryRequest) GetFormat() string {
+	if m != nil {
+		return m.Format
+	}
+	return ""
+}
+
 type MultiQueryResponse struct {
 	Responses            []*QueryResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
 	Version              string           `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
@@ -635,10 +644,12 @@ func (m *MultiQueryResponse) GetTimezone() string {
 }
 
 type QueryResponse struct {
-	Result               *NumpyMultiDataset `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
-	XXX_unrecognized     []byte             `json:"-"`
-	XXX_sizecache        int32              `json:"-"`
+	Result *NumpyMultiDataset `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	// An Arrow IPC stream per time bucket key, for the "arrow" format
+	Arrow                [][]byte `protobuf:"bytes,2,rep,name=arrow,proto3" json:"arrow,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *QueryResponse) Reset()         { *m = QueryResponse{} }
@@ -673,6 +684,13 @@ func (m *QueryResponse) GetResult() *NumpyMultiDataset {
 	return nil
 }
 
+func (m *QueryResponse) GetArrow() [][]byte {
+	if m != nil {
+		return m.Arrow
+	}
+	return nil
+}
+
 type MultiWriteRequest struct {
 	//
 	//A multi-request allows for different Timeframes and record formats for each request
@@ -715,11 +733,13 @@ func (m *MultiWriteRequest) GetRequests() []*WriteRequest {
 }
 
 type WriteRequest struct {
-	Data                 *NumpyMultiDataset `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
-	IsVariableLength     bool               `protobuf:"varint,2,opt,name=is_variable_length,json=isVariableLength,proto3" json:"is_variable_length,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
-	XXX_unrecognized     []byte             `json:"-"`
-	XXX_sizecache        int32              `json:"-"`
+	Data             *NumpyMultiDataset `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	IsVariableLength bool               `protobuf:"varint,2,opt,name=is_variable_length,json=isVariableLength,proto3" json:"is_variable_length,omitempty"`
+	// Arrow IPC streams to write instead of the data, each with its time bucket key in the schema metadata
+	Arrow                [][]byte `protobuf:"bytes,3,rep,name=arrow,proto3" json:"arrow,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *WriteRequest) Reset()         { *m = WriteRequest{} }
@@ -761,6 +781,13 @@ func (m *WriteRequest) GetIsVariableLength() bool {
 	return false
 }
 
+func (m *WriteRequest) GetArrow() [][]byte {
+	if m != nil {
+		return m.Arrow
+	}
+	return nil
+}
+
 type MultiServerResponse struct {
 	Responses            []*ServerResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
@@ -1073,6 +1100,634 @@ func (m *ServerVersionResponse) GetVersion() string {
 	return ""
 }
 
+type QueryCursor struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Epoch                int64    `protobuf:"varint,2,opt,name=epoch,proto3" json:"epoch,omitempty"`
+	Nanoseconds          int32    `protobuf:"varint,3,opt,name=nanoseconds,proto3" json:"nanoseconds,omitempty"`
+	Ordinal              int32    `protobuf:"varint,4,opt,name=ordinal,proto3" json:"ordinal,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *QueryCursor) Reset()         { *m = QueryCursor{} }
+func (m *QueryCursor) String() string { return proto.CompactTextString(m) }
+func (*QueryCursor) ProtoMessage()    {}
+func (*QueryCursor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{19}
+}
+
+func (m *QueryCursor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryCursor.Unmarshal(m, b)
+}
+func (m *QueryCursor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryCursor.Marshal(b, m, deterministic)
+}
+func (m *QueryCursor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryCursor.Merge(m, src)
+}
+func (m *QueryCursor) XXX_Size() int {
+	return xxx_messageInfo_QueryCursor.Size(m)
+}
+func (m *QueryCursor) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryCursor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryCursor proto.InternalMessageInfo
+
+func (m *QueryCursor) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *QueryCursor) GetEpoch() int64 {
+	if m != nil {
+		return m.Epoch
+	}
+	return 0
+}
+
+func (m *QueryCursor) GetNanoseconds() int32 {
+	if m != nil {
+		return m.Nanoseconds
+	}
+	return 0
+}
+
+func (m *QueryCursor) GetOrdinal() int32 {
+	if m != nil {
+		return m.Ordinal
+	}
+	return 0
+}
+
+type QueryStreamRequest struct {
+	Request              *QueryRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	BatchSize            int32         `protobuf:"varint,2,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	Cursor               *QueryCursor  `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *QueryStreamRequest) Reset()         { *m = QueryStreamRequest{} }
+func (m *QueryStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*QueryStreamRequest) ProtoMessage()    {}
+func (*QueryStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{20}
+}
+
+func (m *QueryStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryStreamRequest.Unmarshal(m, b)
+}
+func (m *QueryStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryStreamRequest.Marshal(b, m, deterministic)
+}
+func (m *QueryStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryStreamRequest.Merge(m, src)
+}
+func (m *QueryStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_QueryStreamRequest.Size(m)
+}
+func (m *QueryStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryStreamRequest proto.InternalMessageInfo
+
+func (m *QueryStreamRequest) GetRequest() *QueryRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *QueryStreamRequest) GetBatchSize() int32 {
+	if m != nil {
+		return m.BatchSize
+	}
+	return 0
+}
+
+func (m *QueryStreamRequest) GetCursor() *QueryCursor {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+type QueryStreamResponse struct {
+	Result               *NumpyMultiDataset `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	Cursor               *QueryCursor       `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
+	XXX_unrecognized     []byte             `json:"-"`
+	XXX_sizecache        int32              `json:"-"`
+}
+
+func (m *QueryStreamResponse) Reset()         { *m = QueryStreamResponse{} }
+func (m *QueryStreamResponse) String() string { return proto.CompactTextString(m) }
+func (*QueryStreamResponse) ProtoMessage()    {}
+func (*QueryStreamResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{21}
+}
+
+func (m *QueryStreamResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_QueryStreamResponse.Unmarshal(m, b)
+}
+func (m *QueryStreamResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_QueryStreamResponse.Marshal(b, m, deterministic)
+}
+func (m *QueryStreamResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_QueryStreamResponse.Merge(m, src)
+}
+func (m *QueryStreamResponse) XXX_Size() int {
+	return xxx_messageInfo_QueryStreamResponse.Size(m)
+}
+func (m *QueryStreamResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_QueryStreamResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_QueryStreamResponse proto.InternalMessageInfo
+
+func (m *QueryStreamResponse) GetResult() *NumpyMultiDataset {
+	if m != nil {
+		return m.Result
+	}
+	return nil
+}
+
+func (m *QueryStreamResponse) GetCursor() *QueryCursor {
+	if m != nil {
+		return m.Cursor
+	}
+	return nil
+}
+
+type MultiDeleteRequest struct {
+	Requests             []*DeleteRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *MultiDeleteRequest) Reset()         { *m = MultiDeleteRequest{} }
+func (m *MultiDeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*MultiDeleteRequest) ProtoMessage()    {}
+func (*MultiDeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{22}
+}
+
+func (m *MultiDeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultiDeleteRequest.Unmarshal(m, b)
+}
+func (m *MultiDeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultiDeleteRequest.Marshal(b, m, deterministic)
+}
+func (m *MultiDeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultiDeleteRequest.Merge(m, src)
+}
+func (m *MultiDeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_MultiDeleteRequest.Size(m)
+}
+func (m *MultiDeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultiDeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MultiDeleteRequest proto.InternalMessageInfo
+
+func (m *MultiDeleteRequest) GetRequests() []*DeleteRequest {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+type DeleteRequest struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	EpochStart           int64    `protobuf:"varint,2,opt,name=epoch_start,json=epochStart,proto3" json:"epoch_start,omitempty"`
+	EpochStartNanos      int64    `protobuf:"varint,3,opt,name=epoch_start_nanos,json=epochStartNanos,proto3" json:"epoch_start_nanos,omitempty"`
+	EpochEnd             int64    `protobuf:"varint,4,opt,name=epoch_end,json=epochEnd,proto3" json:"epoch_end,omitempty"`
+	EpochEndNanos        int64    `protobuf:"varint,5,opt,name=epoch_end_nanos,json=epochEndNanos,proto3" json:"epoch_end_nanos,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DeleteRequest) Reset()         { *m = DeleteRequest{} }
+func (m *DeleteRequest) String() string { return proto.CompactTextString(m) }
+func (*DeleteRequest) ProtoMessage()    {}
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{23}
+}
+
+func (m *DeleteRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeleteRequest.Unmarshal(m, b)
+}
+func (m *DeleteRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeleteRequest.Marshal(b, m, deterministic)
+}
+func (m *DeleteRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeleteRequest.Merge(m, src)
+}
+func (m *DeleteRequest) XXX_Size() int {
+	return xxx_messageInfo_DeleteRequest.Size(m)
+}
+func (m *DeleteRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeleteRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeleteRequest proto.InternalMessageInfo
+
+func (m *DeleteRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *DeleteRequest) GetEpochStart() int64 {
+	if m != nil {
+		return m.EpochStart
+	}
+	return 0
+}
+
+func (m *DeleteRequest) GetEpochStartNanos() int64 {
+	if m != nil {
+		return m.EpochStartNanos
+	}
+	return 0
+}
+
+func (m *DeleteRequest) GetEpochEnd() int64 {
+	if m != nil {
+		return m.EpochEnd
+	}
+	return 0
+}
+
+func (m *DeleteRequest) GetEpochEndNanos() int64 {
+	if m != nil {
+		return m.EpochEndNanos
+	}
+	return 0
+}
+
+type MultiAlterBucketRequest struct {
+	Requests             []*AlterBucketRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *MultiAlterBucketRequest) Reset()         { *m = MultiAlterBucketRequest{} }
+func (m *MultiAlterBucketRequest) String() string { return proto.CompactTextString(m) }
+func (*MultiAlterBucketRequest) ProtoMessage()    {}
+func (*MultiAlterBucketRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{24}
+}
+
+func (m *MultiAlterBucketRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MultiAlterBucketRequest.Unmarshal(m, b)
+}
+func (m *MultiAlterBucketRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MultiAlterBucketRequest.Marshal(b, m, deterministic)
+}
+func (m *MultiAlterBucketRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MultiAlterBucketRequest.Merge(m, src)
+}
+func (m *MultiAlterBucketRequest) XXX_Size() int {
+	return xxx_messageInfo_MultiAlterBucketRequest.Size(m)
+}
+func (m *MultiAlterBucketRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_MultiAlterBucketRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MultiAlterBucketRequest proto.InternalMessageInfo
+
+func (m *MultiAlterBucketRequest) GetRequests() []*AlterBucketRequest {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+type AlterBucketRequest struct {
+	Key                  string       `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	AddColumns           []*DataShape `protobuf:"bytes,2,rep,name=add_columns,json=addColumns,proto3" json:"add_columns,omitempty"`
+	DropColumns          []string     `protobuf:"bytes,3,rep,name=drop_columns,json=dropColumns,proto3" json:"drop_columns,omitempty"`
+	RetypeColumns        []*DataShape `protobuf:"bytes,4,rep,name=retype_columns,json=retypeColumns,proto3" json:"retype_columns,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *AlterBucketRequest) Reset()         { *m = AlterBucketRequest{} }
+func (m *AlterBucketRequest) String() string { return proto.CompactTextString(m) }
+func (*AlterBucketRequest) ProtoMessage()    {}
+func (*AlterBucketRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{25}
+}
+
+func (m *AlterBucketRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AlterBucketRequest.Unmarshal(m, b)
+}
+func (m *AlterBucketRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AlterBucketRequest.Marshal(b, m, deterministic)
+}
+func (m *AlterBucketRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AlterBucketRequest.Merge(m, src)
+}
+func (m *AlterBucketRequest) XXX_Size() int {
+	return xxx_messageInfo_AlterBucketRequest.Size(m)
+}
+func (m *AlterBucketRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AlterBucketRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AlterBucketRequest proto.InternalMessageInfo
+
+func (m *AlterBucketRequest) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *AlterBucketRequest) GetAddColumns() []*DataShape {
+	if m != nil {
+		return m.AddColumns
+	}
+	return nil
+}
+
+func (m *AlterBucketRequest) GetDropColumns() []string {
+	if m != nil {
+		return m.DropColumns
+	}
+	return nil
+}
+
+func (m *AlterBucketRequest) GetRetypeColumns() []*DataShape {
+	if m != nil {
+		return m.RetypeColumns
+	}
+	return nil
+}
+
+type BackupRequest struct {
+	Name                 string   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupRequest) Reset()         { *m = BackupRequest{} }
+func (m *BackupRequest) String() string { return proto.CompactTextString(m) }
+func (*BackupRequest) ProtoMessage()    {}
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{26}
+}
+
+func (m *BackupRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupRequest.Unmarshal(m, b)
+}
+func (m *BackupRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupRequest.Marshal(b, m, deterministic)
+}
+func (m *BackupRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupRequest.Merge(m, src)
+}
+func (m *BackupRequest) XXX_Size() int {
+	return xxx_messageInfo_BackupRequest.Size(m)
+}
+func (m *BackupRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupRequest proto.InternalMessageInfo
+
+func (m *BackupRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+type BackupResponse struct {
+	Path                 string   `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Tgid                 int64    `protobuf:"varint,2,opt,name=tgid,proto3" json:"tgid,omitempty"`
+	Files                int64    `protobuf:"varint,3,opt,name=files,proto3" json:"files,omitempty"`
+	Bytes                int64    `protobuf:"varint,4,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BackupResponse) Reset()         { *m = BackupResponse{} }
+func (m *BackupResponse) String() string { return proto.CompactTextString(m) }
+func (*BackupResponse) ProtoMessage()    {}
+func (*BackupResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{27}
+}
+
+func (m *BackupResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BackupResponse.Unmarshal(m, b)
+}
+func (m *BackupResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BackupResponse.Marshal(b, m, deterministic)
+}
+func (m *BackupResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BackupResponse.Merge(m, src)
+}
+func (m *BackupResponse) XXX_Size() int {
+	return xxx_messageInfo_BackupResponse.Size(m)
+}
+func (m *BackupResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_BackupResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BackupResponse proto.InternalMessageInfo
+
+func (m *BackupResponse) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *BackupResponse) GetTgid() int64 {
+	if m != nil {
+		return m.Tgid
+	}
+	return 0
+}
+
+func (m *BackupResponse) GetFiles() int64 {
+	if m != nil {
+		return m.Files
+	}
+	return 0
+}
+
+func (m *BackupResponse) GetBytes() int64 {
+	if m != nil {
+		return m.Bytes
+	}
+	return 0
+}
+
+type WriteStreamRequest struct {
+	Request              *WriteRequest `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *WriteStreamRequest) Reset()         { *m = WriteStreamRequest{} }
+func (m *WriteStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*WriteStreamRequest) ProtoMessage()    {}
+func (*WriteStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{28}
+}
+
+func (m *WriteStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteStreamRequest.Unmarshal(m, b)
+}
+func (m *WriteStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteStreamRequest.Marshal(b, m, deterministic)
+}
+func (m *WriteStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteStreamRequest.Merge(m, src)
+}
+func (m *WriteStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_WriteStreamRequest.Size(m)
+}
+func (m *WriteStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteStreamRequest proto.InternalMessageInfo
+
+func (m *WriteStreamRequest) GetRequest() *WriteRequest {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+type WriteStreamError struct {
+	Sequence             uint64   `protobuf:"varint,1,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	Error                string   `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WriteStreamError) Reset()         { *m = WriteStreamError{} }
+func (m *WriteStreamError) String() string { return proto.CompactTextString(m) }
+func (*WriteStreamError) ProtoMessage()    {}
+func (*WriteStreamError) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{29}
+}
+
+func (m *WriteStreamError) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteStreamError.Unmarshal(m, b)
+}
+func (m *WriteStreamError) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteStreamError.Marshal(b, m, deterministic)
+}
+func (m *WriteStreamError) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteStreamError.Merge(m, src)
+}
+func (m *WriteStreamError) XXX_Size() int {
+	return xxx_messageInfo_WriteStreamError.Size(m)
+}
+func (m *WriteStreamError) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteStreamError.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteStreamError proto.InternalMessageInfo
+
+func (m *WriteStreamError) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
+func (m *WriteStreamError) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type WriteStreamResponse struct {
+	FirstSequence        uint64              `protobuf:"varint,1,opt,name=first_sequence,json=firstSequence,proto3" json:"first_sequence,omitempty"`
+	LastSequence         uint64              `protobuf:"varint,2,opt,name=last_sequence,json=lastSequence,proto3" json:"last_sequence,omitempty"`
+	Tgid                 int64               `protobuf:"varint,3,opt,name=tgid,proto3" json:"tgid,omitempty"`
+	Errors               []*WriteStreamError `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *WriteStreamResponse) Reset()         { *m = WriteStreamResponse{} }
+func (m *WriteStreamResponse) String() string { return proto.CompactTextString(m) }
+func (*WriteStreamResponse) ProtoMessage()    {}
+func (*WriteStreamResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a89eb64cdc1fc4a5, []int{30}
+}
+
+func (m *WriteStreamResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WriteStreamResponse.Unmarshal(m, b)
+}
+func (m *WriteStreamResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WriteStreamResponse.Marshal(b, m, deterministic)
+}
+func (m *WriteStreamResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WriteStreamResponse.Merge(m, src)
+}
+func (m *WriteStreamResponse) XXX_Size() int {
+	return xxx_messageInfo_WriteStreamResponse.Size(m)
+}
+func (m *WriteStreamResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_WriteStreamResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WriteStreamResponse proto.InternalMessageInfo
+
+func (m *WriteStreamResponse) GetFirstSequence() uint64 {
+	if m != nil {
+		return m.FirstSequence
+	}
+	return 0
+}
+
+func (m *WriteStreamResponse) GetLastSequence() uint64 {
+	if m != nil {
+		return m.LastSequence
+	}
+	return 0
+}
+
+func (m *WriteStreamResponse) GetTgid() int64 {
+	if m != nil {
+		return m.Tgid
+	}
+	return 0
+}
+
+func (m *WriteStreamResponse) GetErrors() []*WriteStreamError {
+	if m != nil {
+		return m.Errors
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterEnum("proto.DataType", DataType_name, DataType_value)
 	proto.RegisterEnum("proto.ListSymbolsRequest_Format", ListSymbolsRequest_Format_name, ListSymbolsRequest_Format_value)
@@ -1097,6 +1752,18 @@ func init() {
 	proto.RegisterType((*ListSymbolsResponse)(nil), "proto.ListSymbolsResponse")
 	proto.RegisterType((*ServerVersionRequest)(nil), "proto.ServerVersionRequest")
 	proto.RegisterType((*ServerVersionResponse)(nil), "proto.ServerVersionResponse")
+	proto.RegisterType((*QueryCursor)(nil), "proto.QueryCursor")
+	proto.RegisterType((*QueryStreamRequest)(nil), "proto.QueryStreamRequest")
+	proto.RegisterType((*QueryStreamResponse)(nil), "proto.QueryStreamResponse")
+	proto.RegisterType((*MultiDeleteRequest)(nil), "proto.MultiDeleteRequest")
+	proto.RegisterType((*DeleteRequest)(nil), "proto.DeleteRequest")
+	proto.RegisterType((*MultiAlterBucketRequest)(nil), "proto.MultiAlterBucketRequest")
+	proto.RegisterType((*AlterBucketRequest)(nil), "proto.AlterBucketRequest")
+	proto.RegisterType((*BackupRequest)(nil), "proto.BackupRequest")
+	proto.RegisterType((*BackupResponse)(nil), "proto.BackupResponse")
+	proto.RegisterType((*WriteStreamRequest)(nil), "proto.WriteStreamRequest")
+	proto.RegisterType((*WriteStreamError)(nil), "proto.WriteStreamError")
+	proto.RegisterType((*WriteStreamResponse)(nil), "proto.WriteStreamResponse")
 }
 
 func init() {
@@ -1104,80 +1771,111 @@ func init() {
 }
 
 var fileDescriptor_a89eb64cdc1fc4a5 = []byte{
-	// 1154 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x94, 0x56, 0x6f, 0x53, 0xdb, 0x36,
-	0x1c, 0x6e, 0x12, 0xf2, 0xef, 0xe7, 0x84, 0x18, 0x41, 0x39, 0x37, 0xed, 0x6d, 0x99, 0x77, 0xdb,
-	0xb2, 0x1e, 0xa3, 0x25, 0x70, 0x1c, 0xd7, 0x5b, 0xaf, 0x2d, 0x10, 0xb6, 0x14, 0x48, 0x36, 0x27,
-	0xb4, 0xc7, 0x2b, 0x9f, 0x49, 0x44, 0xf1, 0x88, 0xed, 0x20, 0x29, 0x74, 0xde, 0x8b, 0x7d, 0x92,
-	0x7d, 0x98, 0xdd, 0xad, 0x1f, 0x6c, 0xa7, 0x3f, 0x76, 0xec, 0x04, 0xd6, 0xdb, 0xab, 0xfc, 0xf4,
-	0xe8, 0xd1, 0x23, 0xe9, 0xd1, 0x23, 0x39, 0xb0, 0xe2, 0x39, 0xe4, 0x1a, 0x33, 0xca, 0x02, 0x82,
-	0x37, 0x27, 0x24, 0x60, 0x01, 0xca, 0x8b, 0x1f, 0x73, 0x1b, 0xca, 0x87, 0x0e, 0x73, 0xfa, 0x57,
-	0xce, 0x04, 0x23, 0x04, 0x4b, 0xbe, 0xe3, 0x61, 0x23, 0xd3, 0xc8, 0x34, 0xcb, 0x96, 0xa8, 0x39,
-	0xc6, 0xc2, 0x09, 0x36, 0xb2, 0x12, 0xe3, 0xb5, 0xf9, 0x4f, 0x16, 0x56, 0xba, 0x53, 0x6f, 0x12,
-	0x9e, 0x4e, 0xc7, 0xcc, 0xe5, 0xe3, 0x29, 0x66, 0xe8, 0x3b, 0x58, 0x1a, 0x39, 0xcc, 0x11, 0xa3,
-	0xb5, 0xd6, 0xaa, 0x9c, 0x67, 0x53, 0xf0, 0x14, 0xc5, 0x12, 0x04, 0xd4, 0x01, 0x8d, 0x32, 0x87,
-	0x30, 0xdb, 0xf5, 0x47, 0xf8, 0x77, 0x23, 0xdb, 0xc8, 0x35, 0xb5, 0x56, 0x33, 0xc9, 0x4f, 0xea,
-	0x6e, 0xf6, 0x39, 0xb7, 0xc3, 0xa9, 0x6d, 0x9f, 0x91, 0xd0, 0x02, 0x1a, 0x03, 0xe8, 0x15, 0x14,
-	0xc7, 0xd8, 0xff, 0xc0, 0xae, 0xa8, 0x91, 0x13, 0x32, 0xdf, 0xdc, 0x2b, 0x73, 0x22, 0x79, 0x52,
-	0x23, 0x1a, 0x55, 0x7f, 0x09, 0xb5, 0x39, 0x7d, 0xa4, 0x43, 0xee, 0x1a, 0x87, 0xca, 0x04, 0x5e,
-	0xa2, 0x35, 0xc8, 0xdf, 0x3a, 0xe3, 0xa9, 0x34, 0x21, 0x6f, 0xc9, 0xc6, 0x8b, 0xec, 0x5e, 0xa6,
-	0xfe, 0x02, 0x2a, 0x49, 0xdd, 0xff, 0x33, 0xd6, 0xfc, 0x3b, 0x03, 0x95, 0xa4, 0x3b, 0xe8, 0x2b,
-	0xa8, 0x0c, 0x83, 0xf1, 0xd4, 0xf3, 0x6d, 0xee, 0x32, 0x35, 0x32, 0x8d, 0x5c, 0xb3, 0x6c, 0x69,
-	0x12, 0x1b, 0x70, 0x28, 0x41, 0xe1, 0x87, 0x43, 0x85, 0x77, 0x31, 0xa5, 0xcb, 0x21, 0xf4, 0x25,
-	0xa8, 0xa6, 0x2d, 0x4e, 0x83, 0xdb, 0x52, 0xb1, 0x40, 0x42, 0x7c, 0x26, 0xb4, 0x0e, 0x05, 0xb9,
-	0x7b, 0x63, 0x49, 0x2c, 0x49, 0xb5, 0xd0, 0x16, 0x68, 0x7c, 0x84, 0x4d, 0x79, 0x16, 0xa8, 0x91,
-	0x17, 0x7e, 0xea, 0xca, 0xcf, 0x38, 0x24, 0x16, 0x8c, 0xa2, 0x92, 0x9a, 0x01, 0x54, 0x0f, 0x08,
-	0x76, 0x18, 0xb6, 0xf0, 0xcd, 0x14, 0x53, 0x76, 0xc7, 0xfe, 0xe7, 0x54, 0xb3, 0x9f, 0x57, 0x45,
-	0x8f, 0xa0, 0x44, 0x82, 0x8f, 0xc2, 0x04, 0x23, 0x27, 0x94, 0x8a, 0x24, 0xf8, 0xc8, 0x0d, 0x30,
-	0x8f, 0x00, 0x89, 0x43, 0x4d, 0xcf, 0xfa, 0x1c, 0x4a, 0x44, 0x96, 0xd2, 0x34, 0xad, 0xb5, 0xa6,
-	0x26, 0x48, 0xf1, 0xac, 0x98, 0x65, 0x1e, 0xc2, 0x8a, 0xd0, 0xf9, 0x75, 0x8a, 0x49, 0x18, 0xc9,
-	0x3c, 0x5b, 0x90, 0x89, 0x42, 0x9c, 0xa4, 0x25, 0x54, 0x3e, 0xe5, 0xa0, 0x92, 0x52, 0x68, 0x82,
-	0xee, 0x52, 0x9b, 0xde, 0x8c, 0x6d, 0xca, 0x1c, 0x86, 0x3d, 0xec, 0x33, 0xe1, 0x45, 0xc9, 0x5a,
-	0x76, 0x69, 0xff, 0x66, 0xdc, 0x8f, 0x50, 0xf4, 0x35, 0x54, 0xd3, 0x34, 0x79, 0xbf, 0x2a, 0x34,
-	0x49, 0x6a, 0x80, 0x36, 0xc2, 0x94, 0xb9, 0xbe, 0xc3, 0xdc, 0xc0, 0x57, 0x5e, 0x24, 0x21, 0x9e,
-	0x87, 0x6b, 0x1c, 0xda, 0x43, 0x87, 0xe1, 0x0f, 0x01, 0x09, 0xc5, 0x89, 0x96, 0x2d, 0xed, 0x1a,
-	0x87, 0x07, 0x0a, 0xe2, 0x79, 0xc0, 0x93, 0x60, 0x78, 0x65, 0x8b, 0x6b, 0x63, 0xe4, 0x1b, 0x99,
-	0x66, 0xce, 0x02, 0x01, 0x89, 0xe4, 0xa3, 0xa7, 0xb0, 0x92, 0x20, 0xd8, 0xbe, 0xe3, 0x07, 0xd4,
-	0x28, 0x08, 0x5a, 0x6d, 0x46, 0xeb, 0x72, 0x18, 0x3d, 0x86, 0xb2, 0xe4, 0x62, 0x7f, 0x64, 0x14,
-	0x05, 0xa7, 0x24, 0x80, 0xb6, 0x3f, 0x42, 0xdf, 0x42, 0x2d, 0xee, 0x54, 0x32, 0x25, 0x41, 0xa9,
-	0x46, 0x14, 0x29, 0xb2, 0x01, 0x68, 0xec, 0x7a, 0x2e, 0xb3, 0x09, 0x1e, 0x06, 0x64, 0x64, 0x0f,
-	0x83, 0xa9, 0xcf, 0x8c, 0xb2, 0x08, 0xa3, 0x2e, 0x7a, 0x2c, 0xd1, 0x71, 0xc0, 0x71, 0xee, 0xa9,
-	0x64, 0x5f, 0x92, 0xc0, 0x53, 0x9b, 0x00, 0xe9, 0xa9, 0xc0, 0x8f, 0x48, 0xe0, 0xc9, 0x8d, 0x18,
-	0x50, 0x94, 0x31, 0xa7, 0x86, 0x26, 0xee, 0x45, 0xd4, 0x44, 0x4f, 0xa0, 0x7c, 0x39, 0xf5, 0x87,
-	0xdc, 0x32, 0x6a, 0x54, 0x44, 0xdf, 0x0c, 0x30, 0xff, 0x54, 0xa1, 0x52, 0x47, 0x49, 0x27, 0x81,
-	0x4f, 0x31, 0x6a, 0x41, 0x99, 0xa8, 0x7a, 0x3e, 0x55, 0x29, 0xa2, 0x35, 0xa3, 0xf1, 0x15, 0xdc,
-	0x62, 0x42, 0xf9, 0x61, 0xc9, 0xf3, 0x8c, 0x9a, 0xa8, 0x0e, 0x25, 0xe6, 0x7a, 0xf8, 0x8f, 0xc0,
-	0x8f, 0x32, 0x1d, 0xb7, 0xcd, 0x37, 0x50, 0x4d, 0x4f, 0xfd, 0x1c, 0x0a, 0x04, 0xd3, 0xe9, 0x98,
-	0xa9, 0xb7, 0xd4, 0xb8, 0xef, 0x51, 0xb3, 0x14, 0x2f, 0xce, 0xf3, 0x7b, 0xe2, 0xce, 0xae, 0xc5,
-	0xfd, 0x79, 0x4e, 0xd2, 0x12, 0x79, 0xfe, 0x0d, 0x2a, 0x29, 0x81, 0x8d, 0xd4, 0x8b, 0x7e, 0xff,
-	0x2a, 0xe4, 0xb3, 0xbe, 0x01, 0xc8, 0xa5, 0xf6, 0xad, 0x43, 0x5c, 0xe7, 0x62, 0x8c, 0x6d, 0xf5,
-	0xc6, 0x64, 0xc5, 0x51, 0xe9, 0x2e, 0x7d, 0xa7, 0x3a, 0xe4, 0x7b, 0x69, 0xbe, 0x85, 0x55, 0xa1,
-	0xd1, 0xc7, 0xe4, 0x16, 0x93, 0x78, 0xeb, 0xdb, 0x8b, 0xae, 0x3f, 0x54, 0xf3, 0xa6, 0x99, 0x09,
-	0xdb, 0xcd, 0xd7, 0xb0, 0x3c, 0x27, 0xb3, 0x06, 0x79, 0x4c, 0x48, 0x40, 0xd4, 0x4b, 0x24, 0x1b,
-	0xf7, 0x1f, 0x8f, 0xf9, 0x1a, 0x6a, 0x62, 0x35, 0xc7, 0x38, 0xbe, 0xcb, 0x3f, 0x2c, 0xb8, 0xb7,
-	0xa2, 0x16, 0x32, 0x23, 0x25, 0xbc, 0xfb, 0x02, 0x20, 0x31, 0x78, 0xe1, 0x1d, 0x34, 0x43, 0x40,
-	0x27, 0x2e, 0x65, 0xfd, 0xd0, 0xbb, 0x08, 0xc6, 0x34, 0xe2, 0xed, 0x41, 0xe1, 0x32, 0x20, 0x9e,
-	0x23, 0x4f, 0x7a, 0xb9, 0xd5, 0x50, 0x53, 0x2c, 0x52, 0x37, 0x8f, 0x04, 0xcf, 0x52, 0x7c, 0xf3,
-	0x7b, 0x28, 0x48, 0x04, 0x01, 0x14, 0xfa, 0xe7, 0xa7, 0xfb, 0xbd, 0x13, 0xfd, 0x01, 0x5a, 0x85,
-	0xda, 0xa0, 0x73, 0xda, 0xb6, 0xf7, 0xcf, 0x0e, 0x8e, 0xdb, 0x03, 0xfb, 0xb8, 0x7d, 0xae, 0x67,
-	0xcc, 0x67, 0xb0, 0x9a, 0xd2, 0x53, 0x1e, 0x19, 0x50, 0x94, 0xe9, 0x89, 0xbe, 0x34, 0x51, 0xd3,
-	0x5c, 0x87, 0x35, 0xe9, 0xe7, 0x3b, 0x69, 0x8f, 0x5a, 0x82, 0xb9, 0x05, 0x0f, 0xe7, 0xf0, 0x99,
-	0x54, 0x64, 0x6c, 0x26, 0x65, 0xec, 0xd3, 0x4f, 0x19, 0x28, 0xf1, 0x98, 0xf0, 0xd7, 0x1b, 0x69,
-	0x50, 0x3c, 0xeb, 0x1e, 0x77, 0x7b, 0xef, 0xbb, 0xfa, 0x03, 0xde, 0x38, 0x3a, 0xe9, 0xbd, 0x19,
-	0x6c, 0xb7, 0xf4, 0x0c, 0x2a, 0x43, 0xbe, 0xd3, 0xe5, 0x65, 0x36, 0xc6, 0x77, 0x77, 0xf4, 0x9c,
-	0xc2, 0x77, 0x77, 0xf4, 0x25, 0x5e, 0xb6, 0x7f, 0xe9, 0x1d, 0xfc, 0xac, 0xe7, 0x51, 0x09, 0x96,
-	0xf6, 0xcf, 0x07, 0x6d, 0xbd, 0x20, 0xaa, 0x5e, 0xef, 0x44, 0x2f, 0xf2, 0xaa, 0xdb, 0xeb, 0xb6,
-	0xf5, 0x92, 0xf0, 0x63, 0x60, 0x75, 0xba, 0x3f, 0xe9, 0x65, 0x35, 0x7e, 0x6b, 0x57, 0x07, 0x5e,
-	0x9e, 0x75, 0xba, 0x83, 0x3d, 0x5d, 0xe3, 0x8c, 0x33, 0x09, 0x57, 0xa2, 0x7a, 0xbb, 0xa5, 0x57,
-	0xa3, 0x7a, 0x77, 0x47, 0x5f, 0x46, 0x15, 0x28, 0x49, 0x95, 0xad, 0x5d, 0xbd, 0xd6, 0xfa, 0x2b,
-	0x07, 0xda, 0xe9, 0xec, 0x3f, 0x14, 0xfa, 0x11, 0xf2, 0xe2, 0xca, 0xa2, 0xe8, 0x52, 0x2c, 0x7c,
-	0x4d, 0xea, 0x8f, 0xee, 0xe8, 0x51, 0x76, 0xbd, 0x82, 0x82, 0xfc, 0x30, 0xa1, 0x14, 0x29, 0xf5,
-	0xb1, 0xaa, 0xd7, 0x93, 0x5d, 0x73, 0xf1, 0x7e, 0x09, 0x79, 0x71, 0x51, 0xd3, 0xd3, 0x27, 0xef,
-	0xee, 0x67, 0x86, 0x17, 0x0f, 0x31, 0x65, 0x24, 0x08, 0xd1, 0x7a, 0x92, 0x36, 0x0b, 0xf0, 0x7f,
-	0x0e, 0x3f, 0x04, 0x2d, 0x91, 0xa7, 0x78, 0x0f, 0x8b, 0x99, 0x8d, 0x55, 0xee, 0x8a, 0xdf, 0x5b,
-	0xa8, 0xa6, 0xc2, 0x84, 0x1e, 0xa7, 0xee, 0x79, 0x3a, 0x7a, 0xf5, 0x27, 0x77, 0x77, 0x4a, 0xad,
-	0x8b, 0x82, 0xe8, 0xdc, 0xfe, 0x37, 0x00, 0x00, 0xff, 0xff, 0xdc, 0x8e, 0xa4, 0x49, 0xe8, 0x0a,
-	0x00, 0x00,
+	// 1652 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x57, 0xdd, 0x52, 0xe3, 0xc8,
+	0x15, 0x5e, 0xf9, 0xdf, 0x47, 0x36, 0x88, 0x86, 0x99, 0xd5, 0x78, 0x37, 0x1b, 0xa2, 0xa9, 0x4d,
+	0xc8, 0xd4, 0x86, 0x01, 0x33, 0x4b, 0xa6, 0xb6, 0xb2, 0xb5, 0xbb, 0x18, 0x93, 0x65, 0x01, 0x33,
+	0x91, 0x61, 0xa6, 0xe6, 0x4a, 0x25, 0xac, 0x66, 0x50, 0x21, 0x4b, 0xa6, 0xbb, 0xcd, 0xc4, 0x73,
+	0x91, 0xca, 0x13, 0xe4, 0x31, 0xf2, 0x02, 0xa9, 0xdc, 0xa7, 0x2a, 0x79, 0x8c, 0xbc, 0x44, 0xde,
+	0x20, 0xd5, 0x3f, 0x92, 0x5b, 0xb6, 0x19, 0x48, 0xae, 0xdc, 0xe7, 0xf4, 0xd7, 0x5f, 0x77, 0x7f,
+	0xe7, 0xe8, 0x9c, 0x36, 0xac, 0x0c, 0x7d, 0x72, 0x8d, 0x19, 0x65, 0x09, 0xc1, 0x9b, 0x23, 0x92,
+	0xb0, 0x04, 0x95, 0xc5, 0x8f, 0xb3, 0x03, 0xf5, 0x7d, 0x9f, 0xf9, 0xfd, 0x2b, 0x7f, 0x84, 0x11,
+	0x82, 0x52, 0xec, 0x0f, 0xb1, 0x6d, 0xac, 0x1b, 0x1b, 0x75, 0x57, 0x8c, 0xb9, 0x8f, 0x4d, 0x46,
+	0xd8, 0x2e, 0x48, 0x1f, 0x1f, 0x3b, 0xff, 0x2c, 0xc0, 0x4a, 0x6f, 0x3c, 0x1c, 0x4d, 0x4e, 0xc6,
+	0x11, 0x0b, 0xf9, 0x7a, 0x8a, 0x19, 0xfa, 0x15, 0x94, 0x02, 0x9f, 0xf9, 0x62, 0xb5, 0xd9, 0x5e,
+	0x95, 0xfb, 0x6c, 0x0a, 0x9c, 0x82, 0xb8, 0x02, 0x80, 0x0e, 0xc1, 0xa4, 0xcc, 0x27, 0xcc, 0x0b,
+	0xe3, 0x00, 0xff, 0xd1, 0x2e, 0xac, 0x17, 0x37, 0xcc, 0xf6, 0x86, 0x8e, 0xd7, 0x79, 0x37, 0xfb,
+	0x1c, 0x7b, 0xc8, 0xa1, 0xdd, 0x98, 0x91, 0x89, 0x0b, 0x34, 0x73, 0xa0, 0xef, 0xa0, 0x1a, 0xe1,
+	0xf8, 0x1d, 0xbb, 0xa2, 0x76, 0x51, 0xd0, 0x7c, 0x79, 0x27, 0xcd, 0xb1, 0xc4, 0x49, 0x8e, 0x74,
+	0x55, 0xeb, 0x5b, 0x58, 0x9e, 0xe1, 0x47, 0x16, 0x14, 0xaf, 0xf1, 0x44, 0x89, 0xc0, 0x87, 0x68,
+	0x0d, 0xca, 0xb7, 0x7e, 0x34, 0x96, 0x22, 0x94, 0x5d, 0x69, 0x7c, 0x53, 0x78, 0x69, 0xb4, 0xbe,
+	0x81, 0x86, 0xce, 0xfb, 0xbf, 0xac, 0x75, 0xfe, 0x61, 0x40, 0x43, 0x57, 0x07, 0xfd, 0x02, 0x1a,
+	0x83, 0x24, 0x1a, 0x0f, 0x63, 0x8f, 0xab, 0x4c, 0x6d, 0x63, 0xbd, 0xb8, 0x51, 0x77, 0x4d, 0xe9,
+	0x3b, 0xe3, 0x2e, 0x0d, 0xc2, 0x83, 0x43, 0xed, 0x82, 0x0e, 0xe9, 0x71, 0x17, 0xfa, 0x39, 0x28,
+	0xd3, 0x13, 0xd1, 0xe0, 0xb2, 0x34, 0x5c, 0x90, 0x2e, 0xbe, 0x13, 0x7a, 0x0c, 0x15, 0x79, 0x7b,
+	0xbb, 0x24, 0x8e, 0xa4, 0x2c, 0xb4, 0x0d, 0x26, 0x5f, 0xe1, 0x51, 0x9e, 0x0b, 0xd4, 0x2e, 0x0b,
+	0x3d, 0x2d, 0xa5, 0x67, 0x96, 0x24, 0x2e, 0x04, 0xe9, 0x90, 0x3a, 0x09, 0x34, 0x3b, 0x04, 0xfb,
+	0x0c, 0xbb, 0xf8, 0x66, 0x8c, 0x29, 0x5b, 0x70, 0xff, 0x19, 0xd6, 0xc2, 0xfd, 0xac, 0xe8, 0x09,
+	0xd4, 0x48, 0xf2, 0x5e, 0x88, 0x60, 0x17, 0x05, 0x53, 0x95, 0x24, 0xef, 0xb9, 0x00, 0xce, 0x01,
+	0x20, 0x11, 0xd4, 0xfc, 0xae, 0x5b, 0x50, 0x23, 0x72, 0x28, 0x45, 0x33, 0xdb, 0x6b, 0x6a, 0x83,
+	0x1c, 0xce, 0xcd, 0x50, 0xce, 0x3e, 0xac, 0x08, 0x9e, 0x3f, 0x8c, 0x31, 0x99, 0xa4, 0x34, 0xcf,
+	0xe7, 0x68, 0xd2, 0x24, 0xd6, 0x61, 0x1a, 0xcb, 0xbf, 0x8b, 0xd0, 0xc8, 0x31, 0x6c, 0x80, 0x15,
+	0x52, 0x8f, 0xde, 0x44, 0x1e, 0x65, 0x3e, 0xc3, 0x43, 0x1c, 0x33, 0xa1, 0x45, 0xcd, 0x5d, 0x0a,
+	0x69, 0xff, 0x26, 0xea, 0xa7, 0x5e, 0xf4, 0x14, 0x9a, 0x79, 0x98, 0xfc, 0xbe, 0x1a, 0x54, 0x07,
+	0xad, 0x83, 0x19, 0x60, 0xca, 0xc2, 0xd8, 0x67, 0x61, 0x12, 0x2b, 0x2d, 0x74, 0x17, 0xcf, 0x87,
+	0x6b, 0x3c, 0xf1, 0x06, 0x3e, 0xc3, 0xef, 0x12, 0x32, 0x11, 0x11, 0xad, 0xbb, 0xe6, 0x35, 0x9e,
+	0x74, 0x94, 0x8b, 0xe7, 0x03, 0x1e, 0x25, 0x83, 0x2b, 0x4f, 0x7c, 0x36, 0x76, 0x79, 0xdd, 0xd8,
+	0x28, 0xba, 0x20, 0x5c, 0x22, 0xf3, 0xd1, 0x33, 0x58, 0xd1, 0x00, 0x5e, 0xec, 0xc7, 0x09, 0xb5,
+	0x2b, 0x02, 0xb6, 0x3c, 0x85, 0xf5, 0xb8, 0x1b, 0x7d, 0x06, 0x75, 0x89, 0xc5, 0x71, 0x60, 0x57,
+	0x05, 0xa6, 0x26, 0x1c, 0xdd, 0x38, 0x40, 0xbf, 0x84, 0xe5, 0x6c, 0x52, 0xd1, 0xd4, 0x04, 0xa4,
+	0x99, 0x42, 0x24, 0xc9, 0x57, 0x80, 0xa2, 0x70, 0x18, 0x32, 0x8f, 0xe0, 0x41, 0x42, 0x02, 0x6f,
+	0x90, 0x8c, 0x63, 0x66, 0xd7, 0x45, 0x32, 0x5a, 0x62, 0xc6, 0x15, 0x13, 0x1d, 0xee, 0xe7, 0x9a,
+	0x4a, 0xf4, 0x25, 0x49, 0x86, 0xea, 0x12, 0x20, 0x35, 0x15, 0xfe, 0x03, 0x92, 0x0c, 0xe5, 0x45,
+	0x6c, 0xa8, 0xca, 0x34, 0xa7, 0xb6, 0x29, 0xbe, 0x8b, 0xd4, 0x44, 0x9f, 0x43, 0xfd, 0x72, 0x1c,
+	0x0f, 0xb8, 0x64, 0xd4, 0x6e, 0x88, 0xb9, 0xa9, 0x83, 0x7f, 0x10, 0x97, 0x09, 0x19, 0xfa, 0xcc,
+	0x6e, 0x0a, 0xf9, 0x94, 0xe5, 0xfc, 0x49, 0x25, 0x9b, 0x0a, 0x31, 0x1d, 0x25, 0x31, 0xc5, 0xa8,
+	0x0d, 0x75, 0xa2, 0xc6, 0xb3, 0xd9, 0x96, 0x03, 0xba, 0x53, 0x18, 0x3f, 0xd9, 0x2d, 0x26, 0x94,
+	0x07, 0x51, 0xc6, 0x39, 0x35, 0x51, 0x0b, 0x6a, 0x2c, 0x1c, 0xe2, 0x0f, 0x49, 0x9c, 0xe6, 0x7a,
+	0x66, 0x3b, 0x6f, 0xa0, 0x99, 0xdf, 0x7a, 0x0b, 0x2a, 0x04, 0xd3, 0x71, 0xc4, 0x54, 0x8d, 0xb5,
+	0xef, 0x2a, 0x76, 0xae, 0xc2, 0xf1, 0xea, 0xe3, 0x13, 0x92, 0xbc, 0x17, 0xdf, 0x5d, 0xc3, 0x95,
+	0x46, 0x96, 0xfd, 0x6f, 0x48, 0xc8, 0xf0, 0xfd, 0xd9, 0xaf, 0xc3, 0xb4, 0xec, 0xff, 0xb3, 0x01,
+	0x8d, 0x1c, 0xc3, 0x57, 0xb9, 0x06, 0x70, 0xf7, 0xe1, 0x04, 0x8a, 0x67, 0x41, 0x48, 0xbd, 0x5b,
+	0x9f, 0x84, 0xfe, 0x45, 0x84, 0x3d, 0x55, 0x92, 0x0a, 0x22, 0xb2, 0x56, 0x48, 0x5f, 0xab, 0x09,
+	0x59, 0x5e, 0xa7, 0x17, 0x29, 0xea, 0x17, 0xf9, 0x09, 0x56, 0x05, 0x73, 0x1f, 0x93, 0x5b, 0x4c,
+	0x32, 0x9d, 0x76, 0xe6, 0x43, 0xf4, 0x48, 0x9d, 0x26, 0x8f, 0xd4, 0x62, 0xe4, 0x7c, 0x0f, 0x4b,
+	0x33, 0x34, 0x6b, 0x50, 0xc6, 0x84, 0x24, 0x44, 0x95, 0x33, 0x69, 0xdc, 0x1d, 0x4b, 0xe7, 0x7b,
+	0x58, 0x16, 0xa7, 0x39, 0xc2, 0x59, 0x41, 0xf8, 0xcd, 0x9c, 0xa8, 0x2b, 0xea, 0x20, 0x53, 0x90,
+	0x26, 0xe9, 0x17, 0x00, 0xda, 0xe2, 0xb9, 0x62, 0xea, 0x4c, 0x00, 0x1d, 0x87, 0x94, 0xf5, 0x27,
+	0xc3, 0x8b, 0x24, 0xa2, 0x29, 0xee, 0x65, 0x96, 0xbf, 0x1c, 0xba, 0xd4, 0x5e, 0x57, 0x5b, 0xcc,
+	0x43, 0x37, 0x0f, 0x04, 0x2e, 0xcb, 0xf0, 0x5f, 0x43, 0x45, 0x7a, 0x10, 0x40, 0xa5, 0xff, 0xf6,
+	0x64, 0xef, 0xf4, 0xd8, 0xfa, 0x04, 0xad, 0xc2, 0xf2, 0xd9, 0xe1, 0x49, 0xd7, 0xdb, 0x3b, 0xef,
+	0x1c, 0x75, 0xcf, 0xbc, 0xa3, 0xee, 0x5b, 0xcb, 0x70, 0x9e, 0xc3, 0x6a, 0x8e, 0x4f, 0x69, 0x64,
+	0x43, 0x55, 0xa6, 0x5a, 0xda, 0xae, 0x52, 0xd3, 0x79, 0x0c, 0x6b, 0x52, 0xcf, 0xd7, 0x52, 0x1e,
+	0x75, 0x04, 0x67, 0x1b, 0x1e, 0xcd, 0xf8, 0xa7, 0x54, 0xa9, 0xb0, 0x46, 0x5e, 0xd8, 0x1b, 0x30,
+	0xc5, 0x87, 0xd0, 0x19, 0x13, 0x9a, 0x90, 0xc5, 0x4d, 0x56, 0x94, 0x18, 0x11, 0x91, 0xa2, 0x2b,
+	0x0d, 0x5e, 0x3e, 0x45, 0x15, 0xc2, 0x83, 0x24, 0x0e, 0xa8, 0xf8, 0xbc, 0xca, 0xae, 0xee, 0xe2,
+	0x5b, 0x26, 0x24, 0x08, 0x63, 0x3f, 0x52, 0xbd, 0x30, 0x35, 0x9d, 0xbf, 0x18, 0x80, 0xc4, 0x9e,
+	0x7d, 0x46, 0xb0, 0x3f, 0x9c, 0xc6, 0xb3, 0xaa, 0x82, 0x35, 0xf3, 0xcc, 0xc9, 0x75, 0x88, 0x14,
+	0x83, 0x7e, 0x06, 0x70, 0xe1, 0x33, 0x5e, 0x5a, 0xc3, 0x0f, 0xe9, 0x0b, 0xa0, 0x2e, 0x3c, 0xfd,
+	0xf0, 0x03, 0x46, 0xcf, 0xa0, 0x32, 0x10, 0x57, 0x12, 0x67, 0x33, 0xdb, 0x48, 0x27, 0x93, 0x97,
+	0x75, 0x15, 0xc2, 0xa1, 0xb0, 0x9a, 0x3b, 0xcf, 0xff, 0x5d, 0x12, 0xa6, 0x9b, 0x16, 0xee, 0xdd,
+	0x34, 0x6d, 0xb7, 0xfb, 0x38, 0xc2, 0x0f, 0x69, 0xb7, 0x39, 0x9c, 0x96, 0xd7, 0x7f, 0x33, 0xa0,
+	0x99, 0xe7, 0x98, 0x8f, 0xe1, 0x4c, 0x9f, 0x2a, 0x3c, 0xac, 0x4f, 0x15, 0x1f, 0xd0, 0xa7, 0x4a,
+	0xf7, 0xf7, 0xa9, 0xf2, 0x82, 0x3e, 0xe5, 0xbc, 0x82, 0x4f, 0xc5, 0xed, 0x7f, 0x88, 0x18, 0x26,
+	0x7b, 0xe3, 0xc1, 0x35, 0x66, 0xe9, 0xf1, 0xbf, 0x9e, 0x93, 0xe0, 0x89, 0x92, 0x60, 0x1e, 0xac,
+	0xe9, 0xf0, 0x77, 0x03, 0xd0, 0x02, 0xb6, 0x85, 0xaf, 0x26, 0x3f, 0x08, 0x3c, 0xd5, 0xbf, 0xee,
+	0x7e, 0x35, 0xf9, 0x41, 0xd0, 0x91, 0x18, 0xfe, 0x14, 0x08, 0x48, 0x32, 0xca, 0xd6, 0x14, 0xe5,
+	0xd3, 0x90, 0xfb, 0x52, 0xc8, 0x6f, 0x61, 0x89, 0x60, 0xfe, 0xac, 0xca, 0x40, 0xa5, 0x3b, 0x88,
+	0x9b, 0x12, 0xa7, 0x16, 0x3a, 0x4f, 0xa1, 0xb9, 0xe7, 0x0f, 0xae, 0xc7, 0xa3, 0xf4, 0xc4, 0x0b,
+	0xfe, 0x29, 0x38, 0x01, 0x2c, 0xa5, 0x20, 0x95, 0x9c, 0x08, 0x4a, 0x23, 0x9f, 0x5d, 0xa5, 0x28,
+	0x3e, 0xe6, 0x3e, 0xf6, 0x2e, 0x0c, 0x54, 0x7c, 0xc5, 0x98, 0x7f, 0xbe, 0x97, 0x61, 0x84, 0xd3,
+	0x68, 0x4a, 0x83, 0x7b, 0x2f, 0x26, 0x0c, 0x53, 0x15, 0x3f, 0x69, 0x38, 0x1d, 0x40, 0xa2, 0xe9,
+	0x3c, 0xf0, 0xbb, 0xcc, 0xf5, 0xae, 0x14, 0xe3, 0xec, 0x83, 0xa5, 0x91, 0x74, 0x45, 0x5d, 0x6f,
+	0x41, 0x8d, 0xf2, 0xe9, 0x78, 0x20, 0xaf, 0x55, 0x72, 0x33, 0x7b, 0xda, 0x09, 0x0a, 0x5a, 0x27,
+	0x70, 0xfe, 0x6a, 0xc0, 0x6a, 0xee, 0x2c, 0xea, 0xda, 0x5f, 0xc2, 0xd2, 0x65, 0x48, 0x28, 0xf3,
+	0x66, 0xf8, 0x9a, 0xc2, 0xdb, 0x4f, 0x49, 0x9f, 0x42, 0x33, 0xf2, 0x75, 0x54, 0x41, 0xa0, 0x1a,
+	0x91, 0xaf, 0x81, 0x52, 0xb9, 0x8a, 0x9a, 0x5c, 0xcf, 0xa1, 0x22, 0x0e, 0x90, 0x86, 0xef, 0x53,
+	0xfd, 0xae, 0xda, 0x95, 0x5c, 0x05, 0x7b, 0xf6, 0x2f, 0x03, 0x6a, 0x3c, 0xb6, 0xfc, 0x09, 0x8d,
+	0x4c, 0xa8, 0x9e, 0xf7, 0x8e, 0x7a, 0xa7, 0x6f, 0x7a, 0xd6, 0x27, 0xdc, 0x38, 0x38, 0x3e, 0xfd,
+	0xe1, 0x6c, 0xa7, 0x6d, 0x19, 0xa8, 0x0e, 0xe5, 0xc3, 0x1e, 0x1f, 0x16, 0x32, 0xff, 0xee, 0x0b,
+	0xab, 0xa8, 0xfc, 0xbb, 0x2f, 0xac, 0x12, 0x1f, 0x76, 0x5f, 0x9d, 0x76, 0x7e, 0xb4, 0xca, 0xa8,
+	0x06, 0xa5, 0xbd, 0xb7, 0x67, 0x5d, 0xab, 0x22, 0x46, 0xa7, 0xa7, 0xc7, 0x56, 0x95, 0x8f, 0x7a,
+	0xa7, 0xbd, 0xae, 0x55, 0x13, 0xfd, 0xe4, 0xcc, 0x3d, 0xec, 0xfd, 0xde, 0xaa, 0xab, 0xf5, 0xdb,
+	0xbb, 0x16, 0xf0, 0xe1, 0xf9, 0x61, 0xef, 0xec, 0xa5, 0x65, 0x72, 0xc4, 0xb9, 0x74, 0x37, 0xd2,
+	0xf1, 0x4e, 0xdb, 0x6a, 0xa6, 0xe3, 0xdd, 0x17, 0xd6, 0x12, 0x6a, 0x40, 0x4d, 0xb2, 0x6c, 0xef,
+	0x5a, 0xcb, 0xed, 0xff, 0x94, 0xc1, 0x3c, 0x99, 0xfe, 0x91, 0x45, 0xbf, 0x83, 0xb2, 0x28, 0x5a,
+	0x28, 0x2d, 0x7a, 0x73, 0x4f, 0xfa, 0xd6, 0x93, 0x05, 0x33, 0x2a, 0x4a, 0xdf, 0x41, 0x45, 0xfe,
+	0x3b, 0x40, 0x39, 0x50, 0xee, 0x1f, 0x43, 0xab, 0xa5, 0x4f, 0xcd, 0x3c, 0x0f, 0xbe, 0x85, 0xb2,
+	0x50, 0x3c, 0xbf, 0xbd, 0x9e, 0x70, 0xf7, 0x2c, 0xaf, 0xee, 0x63, 0xca, 0x48, 0x32, 0x41, 0x8f,
+	0x75, 0xd8, 0xf4, 0x01, 0xf0, 0xd1, 0xe5, 0xfb, 0x60, 0x6a, 0xfd, 0x38, 0xbb, 0xc3, 0x7c, 0xcf,
+	0x6f, 0xb5, 0x16, 0x4d, 0x29, 0x96, 0x9f, 0xa0, 0x99, 0x6b, 0xc6, 0xe8, 0xb3, 0xdc, 0x3b, 0x29,
+	0xdf, 0xba, 0x5b, 0x9f, 0x2f, 0x9e, 0x54, 0x5c, 0x07, 0xaa, 0x4b, 0xcb, 0x0c, 0xcc, 0x4e, 0x34,
+	0xdf, 0x45, 0x5b, 0xad, 0x45, 0x53, 0x92, 0x65, 0xcb, 0xe0, 0x81, 0x91, 0xbd, 0x22, 0x1f, 0x98,
+	0x5c, 0xff, 0xf8, 0xa8, 0x34, 0x87, 0x60, 0x6a, 0x45, 0x16, 0x7d, 0xa1, 0x43, 0xe7, 0xab, 0xef,
+	0x47, 0xa9, 0xbe, 0x86, 0x8a, 0xac, 0x69, 0x28, 0x6d, 0x71, 0xb9, 0x3a, 0xd8, 0x7a, 0x34, 0xe3,
+	0x55, 0xcb, 0x7e, 0x04, 0x53, 0xfb, 0x18, 0xb3, 0x7b, 0xcc, 0x17, 0xae, 0x56, 0x6b, 0xd1, 0x94,
+	0x64, 0xd9, 0x30, 0xb6, 0x8c, 0x8b, 0x8a, 0x98, 0xde, 0xf9, 0xef, 0x00, 0x86, 0x22, 0x11, 0x27,
+	0xc2, 0x11, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1198,6 +1896,11 @@ type MarketstoreClient interface {
 	Destroy(ctx context.Context, in *MultiKeyRequest, opts ...grpc.CallOption) (*MultiServerResponse, error)
 	ListSymbols(ctx context.Context, in *ListSymbolsRequest, opts ...grpc.CallOption) (*ListSymbolsResponse, error)
 	ServerVersion(ctx context.Context, in *ServerVersionRequest, opts ...grpc.CallOption) (*ServerVersionResponse, error)
+	QueryStream(ctx context.Context, in *QueryStreamRequest, opts ...grpc.CallOption) (Marketstore_QueryStreamClient, error)
+	Delete(ctx context.Context, in *MultiDeleteRequest, opts ...grpc.CallOption) (*MultiServerResponse, error)
+	AlterBucket(ctx context.Context, in *MultiAlterBucketRequest, opts ...grpc.CallOption) (*MultiServerResponse, error)
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error)
+	WriteStream(ctx context.Context, opts ...grpc.CallOption) (Marketstore_WriteStreamClient, error)
 }
 
 type marketstoreClient struct {
@@ -1262,6 +1965,96 @@ func (c *marketstoreClient) ServerVersion(ctx context.Context, in *ServerVersion
 	return out, nil
 }
 
+func (c *marketstoreClient) QueryStream(ctx context.Context, in *QueryStreamRequest, opts ...grpc.CallOption) (Marketstore_QueryStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Marketstore_serviceDesc.Streams[0], "/proto.Marketstore/QueryStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &marketstoreQueryStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Marketstore_QueryStreamClient interface {
+	Recv() (*QueryStreamResponse, error)
+	grpc.ClientStream
+}
+
+type marketstoreQueryStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *marketstoreQueryStreamClient) Recv() (*QueryStreamResponse, error) {
+	m := new(QueryStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *marketstoreClient) Delete(ctx context.Context, in *MultiDeleteRequest, opts ...grpc.CallOption) (*MultiServerResponse, error) {
+	out := new(MultiServerResponse)
+	err := c.cc.Invoke(ctx, "/proto.Marketstore/Delete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketstoreClient) AlterBucket(ctx context.Context, in *MultiAlterBucketRequest, opts ...grpc.CallOption) (*MultiServerResponse, error) {
+	out := new(MultiServerResponse)
+	err := c.cc.Invoke(ctx, "/proto.Marketstore/AlterBucket", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketstoreClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error) {
+	out := new(BackupResponse)
+	err := c.cc.Invoke(ctx, "/proto.Marketstore/Backup", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketstoreClient) WriteStream(ctx context.Context, opts ...grpc.CallOption) (Marketstore_WriteStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Marketstore_serviceDesc.Streams[1], "/proto.Marketstore/WriteStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &marketstoreWriteStreamClient{stream}
+	return x, nil
+}
+
+type Marketstore_WriteStreamClient interface {
+	Send(*WriteStreamRequest) error
+	Recv() (*WriteStreamResponse, error)
+	grpc.ClientStream
+}
+
+type marketstoreWriteStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *marketstoreWriteStreamClient) Send(m *WriteStreamRequest) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *marketstoreWriteStreamClient) Recv() (*WriteStreamResponse, error) {
+	m := new(WriteStreamResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // MarketstoreServer is the server API for Marketstore service.
 type MarketstoreServer interface {
 	Query(context.Context, *MultiQueryRequest) (*MultiQueryResponse, error)
@@ -1270,6 +2063,11 @@ type MarketstoreServer interface {
 	Destroy(context.Context, *MultiKeyRequest) (*MultiServerResponse, error)
 	ListSymbols(context.Context, *ListSymbolsRequest) (*ListSymbolsResponse, error)
 	ServerVersion(context.Context, *ServerVersionRequest) (*ServerVersionResponse, error)
+	QueryStream(*QueryStreamRequest, Marketstore_QueryStreamServer) error
+	Delete(context.Context, *MultiDeleteRequest) (*MultiServerResponse, error)
+	AlterBucket(context.Context, *MultiAlterBucketRequest) (*MultiServerResponse, error)
+	Backup(context.Context, *BackupRequest) (*BackupResponse, error)
+	WriteStream(Marketstore_WriteStreamServer) error
 }
 
 // UnimplementedMarketstoreServer can be embedded to have forward compatible implementations.
@@ -1294,6 +2092,21 @@ func (*UnimplementedMarketstoreServer) ListSymbols(ctx context.Context, req *Lis
 func (*UnimplementedMarketstoreServer) ServerVersion(ctx context.Context, req *ServerVersionRequest) (*ServerVersionResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ServerVersion not implemented")
 }
+func (*UnimplementedMarketstoreServer) QueryStream(req *QueryStreamRequest, srv Marketstore_QueryStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method QueryStream not implemented")
+}
+func (*UnimplementedMarketstoreServer) Delete(ctx context.Context, req *MultiDeleteRequest) (*MultiServerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (*UnimplementedMarketstoreServer) AlterBucket(ctx context.Context, req *MultiAlterBucketRequest) (*MultiServerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AlterBucket not implemented")
+}
+func (*UnimplementedMarketstoreServer) Backup(ctx context.Context, req *BackupRequest) (*BackupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (*UnimplementedMarketstoreServer) WriteStream(srv Marketstore_WriteStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method WriteStream not implemented")
+}
 
 func RegisterMarketstoreServer(s *grpc.Server, srv MarketstoreServer) {
 	s.RegisterService(&_Marketstore_serviceDesc, srv)
@@ -1407,6 +2220,107 @@ func _Marketstore_ServerVersion_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Marketstore_QueryStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(QueryStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketstoreServer).QueryStream(m, &marketstoreQueryStreamServer{stream})
+}
+
+type Marketstore_QueryStreamServer interface {
+	Send(*QueryStreamResponse) error
+	grpc.ServerStream
+}
+
+type marketstoreQueryStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *marketstoreQueryStreamServer) Send(m *QueryStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Marketstore_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketstoreServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Marketstore/Delete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketstoreServer).Delete(ctx, req.(*MultiDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Marketstore_AlterBucket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiAlterBucketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketstoreServer).AlterBucket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Marketstore/AlterBucket",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketstoreServer).AlterBucket(ctx, req.(*MultiAlterBucketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Marketstore_Backup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BackupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketstoreServer).Backup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Marketstore/Backup",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketstoreServer).Backup(ctx, req.(*BackupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Marketstore_WriteStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MarketstoreServer).WriteStream(&marketstoreWriteStreamServer{stream})
+}
+
+type Marketstore_WriteStreamServer interface {
+	Send(*WriteStreamResponse) error
+	Recv() (*WriteStreamRequest, error)
+	grpc.ServerStream
+}
+
+type marketstoreWriteStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *marketstoreWriteStreamServer) Send(m *WriteStreamResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *marketstoreWriteStreamServer) Recv() (*WriteStreamRequest, error) {
+	m := new(WriteStreamRequest)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 var _Marketstore_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Marketstore",
 	HandlerType: (*MarketstoreServer)(nil),
@@ -1435,7 +2349,31 @@ var _Marketstore_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ServerVersion",
 			Handler:    _Marketstore_ServerVersion_Handler,
 		},
+		{
+			MethodName: "Delete",
+			Handler:    _Marketstore_Delete_Handler,
+		},
+		{
+			MethodName: "AlterBucket",
+			Handler:    _Marketstore_AlterBucket_Handler,
+		},
+		{
+			MethodName: "Backup",
+			Handler:    _Marketstore_Backup_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "QueryStream",
+			Handler:       _Marketstore_QueryStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WriteStream",
+			Handler:       _Marketstore_WriteStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "marketstore.proto",
 }
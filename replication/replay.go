@@ -2,6 +2,7 @@ package replication
 
 import (
 	"fmt"
+	goio "io"
 	"time"
 
 	"github.com/pkg/errors"
@@ -19,6 +20,12 @@ type ReplayerImpl struct {
 	parseTGFunc func(tgSerialized []byte, rootPath string) (tgID int64, wtSets []wal.WTSet)
 	// WriteFunc is a function to write CSM to marketstore.
 	writeFunc func(csm io.ColumnSeriesMap, isVariableLength bool) (err error)
+	// deleteFunc is a function to delete the records of a time range from marketstore.
+	deleteFunc func(tbk *io.TimeBucketKey, start, end time.Time) (err error)
+	// alterFunc is a function to change the data shapes (without Epoch) of a bucket of marketstore.
+	alterFunc func(tbk *io.TimeBucketKey, dsv []io.DataShape) (err error)
+	// dropFunc is a function to remove a year of a bucket of marketstore.
+	dropFunc func(tbk *io.TimeBucketKey, year int16) (err error)
 	// rootDir is the path to the directory in which Marketstore database resides(e.g. "data")
 	rootDir string
 }
@@ -26,11 +33,17 @@ type ReplayerImpl struct {
 func NewReplayer(
 	parseTGFunc func(tgSerialized []byte, rootPath string) (TGID int64, wtSets []wal.WTSet),
 	writeFunc func(csm io.ColumnSeriesMap, isVariableLength bool) (err error),
+	deleteFunc func(tbk *io.TimeBucketKey, start, end time.Time) (err error),
+	alterFunc func(tbk *io.TimeBucketKey, dsv []io.DataShape) (err error),
+	dropFunc func(tbk *io.TimeBucketKey, year int16) (err error),
 	rootDir string,
 ) *ReplayerImpl {
 	return &ReplayerImpl{
 		parseTGFunc: parseTGFunc,
 		writeFunc:   writeFunc,
+		deleteFunc:  deleteFunc,
+		alterFunc:   alterFunc,
+		dropFunc:    dropFunc,
 		rootDir:     rootDir,
 	}
 }
@@ -47,6 +60,49 @@ func (r *ReplayerImpl) Replay(transactionGroup []byte) error {
 	log.Debug(fmt.Sprintf("[replica] transactionGroupID=%v", tgID))
 
 	for _, wtSet := range wtsets {
+		if wtSet.Buffer.IsDelete() {
+			tbk, start, end, err := wtSetToDeletedRange(&wtSet)
+			if err != nil {
+				return errors.Wrap(err, "failed to convert WTSet to a deleted range")
+			}
+
+			err = r.deleteFunc(tbk, start, end)
+			if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to Delete. tbk:%v, start:%v, end:%v", tbk, start, end))
+			}
+			continue
+		}
+
+		if wtSet.Buffer.IsAlter() {
+			tbk, _, err := io.NewTimeBucketKeyFromWalKeyPath(wtSet.FilePath)
+			if err != nil {
+				return errors.Wrap(err, "failed to parse walKeyPath to bucket info. wkp:"+wtSet.FilePath)
+			}
+
+			dsv := wtSet.Buffer.AlteredDataShapes()
+			if len(dsv) < 2 {
+				return errors.New("[bug] no data shape to alter")
+			}
+			err = r.alterFunc(tbk, dsv[1:]) // without Epoch
+			if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to AlterDataShapes. tbk:%v, dsv:%v", tbk, dsv))
+			}
+			continue
+		}
+
+		if wtSet.Buffer.IsDrop() {
+			tbk, year, err := io.NewTimeBucketKeyFromWalKeyPath(wtSet.FilePath)
+			if err != nil {
+				return errors.Wrap(err, "failed to parse walKeyPath to bucket info. wkp:"+wtSet.FilePath)
+			}
+
+			err = r.dropFunc(tbk, int16(year))
+			if err != nil {
+				return errors.Wrap(err, fmt.Sprintf("failed to DropYear. tbk:%v, year:%v", tbk, year))
+			}
+			continue
+		}
+
 		csm, err := WTSetToCSM(&wtSet)
 		if err != nil {
 			return errors.Wrap(err, "failed to convert WTSet to CSM")
@@ -119,9 +175,46 @@ func wtSetToCS(wtSet *wal.WTSet) (*io.ColumnSeries, *io.TimeBucketKey, error) {
 	rs := io.NewRowSeries(*tbk, buf, wtSet.DataShapes, 0, wtSet.RecordType)
 	_, cs := rs.ToColumnSeries()
 
+	// the values of the string and blob columns are in the heap of the write
+	if io.HasHeapColumns(wtSet.DataShapes) {
+		err = cs.ReadHeapColumns(wtSet.DataShapes, func(int) (goio.ReaderAt, error) {
+			if wtSet.Heap == nil {
+				return nil, errors.New("[bug] no heap in the write transaction of " + wtSet.FilePath)
+			}
+			return wtSet.Heap, nil
+		})
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to read the heap values of "+wtSet.FilePath)
+		}
+	}
+
 	return cs, tbk, nil
 }
 
+// wtSetToDeletedRange returns the bucket and the time range of the records deleted by a WTSet.
+func wtSetToDeletedRange(wtSet *wal.WTSet) (tbk *io.TimeBucketKey, start, end time.Time, err error) {
+	tbk, year, err := io.NewTimeBucketKeyFromWalKeyPath(wtSet.FilePath)
+	if err != nil {
+		return nil, start, end, errors.Wrap(err, "failed to parse walKeyPath to bucket info. wkp:"+wtSet.FilePath)
+	}
+
+	tf, err := tbk.GetTimeFrame()
+	if err != nil {
+		return nil, start, end, errors.Wrap(err, "failed to get TimeFrame from TimeBucketKey. tbk:"+tbk.String())
+	}
+
+	recordLen, count := wtSet.Buffer.DeletedRecords()
+	if recordLen == 0 || count == 0 {
+		return nil, start, end, errors.New("[bug] no record to delete")
+	}
+
+	// the deleted records are consecutive, from the one at the offset
+	first := (wtSet.Buffer.Offset()-io.Headersize)/recordLen + 1
+	start = io.IndexToTime(first, tf.Duration, int16(year))
+	end = io.IndexToTime(first+count-1, tf.Duration, int16(year)).Add(tf.Duration - time.Nanosecond)
+	return tbk, start, end, nil
+}
+
 // serializeVariableRecord serializes variableLength record(s) data in a WTSet to []byte.
 func serializeVariableRecords(epoch time.Time, intervalsPerDay uint32, wtSet *wal.WTSet) ([]byte, error) {
 	const (
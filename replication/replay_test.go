@@ -246,7 +246,7 @@ func TestReplayerImpl_Replay(t *testing.T) {
 				return 1, tt.wtSets
 			}
 
-			r := replication.NewReplayer(parseTGFunc, writeFunc, "/file/path")
+			r := replication.NewReplayer(parseTGFunc, writeFunc, nil, nil, nil, "/file/path")
 
 			// --- when ---
 			err := r.Replay(nil)
@@ -258,3 +258,159 @@ func TestReplayerImpl_Replay(t *testing.T) {
 		})
 	}
 }
+
+func TestReplayerImpl_ReplayDelete(t *testing.T) {
+	t.Parallel()
+
+	// --- given ---
+	// delete the 2 records from 2020-01-01 00:02:00 in a 1Min bucket
+	const recordLen = 40
+	deleteIndex := int64(wal.DeleteIndex)
+	buffer := make([]byte, 32)
+	binary.LittleEndian.PutUint64(buffer, uint64(io.IndexToOffset(3, recordLen)))
+	binary.LittleEndian.PutUint64(buffer[8:], uint64(deleteIndex))
+	binary.LittleEndian.PutUint64(buffer[16:], recordLen)
+	binary.LittleEndian.PutUint64(buffer[24:], 2)
+	wtSets := []wal.WTSet{
+		{
+			RecordType: io.FIXED,
+			FilePath:   "/data/AMZN/1Min/OHLC/2020.bin",
+			DataLen:    16,
+			Buffer:     buffer,
+			DataShapes: []io.DataShape{},
+		},
+	}
+
+	var deleted []time.Time
+	deleteFunc := func(tbk *io.TimeBucketKey, start, end time.Time) error {
+		if tbk.GetItemKey() != "AMZN/1Min/OHLC" {
+			t.Errorf("Replayed delete: want AMZN/1Min/OHLC, got=%v", tbk.GetItemKey())
+		}
+		deleted = append(deleted, start, end)
+		return nil
+	}
+	writeFunc := func(csm io.ColumnSeriesMap, isVariableLength bool) error {
+		t.Error("a deletion must not be written")
+		return nil
+	}
+	parseTGFunc := func(TG_Serialized []byte, rootPath string) (TGID int64, wtSets2 []wal.WTSet) {
+		return 1, wtSets
+	}
+	r := replication.NewReplayer(parseTGFunc, writeFunc, deleteFunc, nil, nil, "/file/path")
+
+	// --- when ---
+	err := r.Replay(nil)
+
+	// --- then ---
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	want := []time.Time{
+		time.Date(2020, 1, 1, 0, 2, 0, 0, time.UTC),
+		time.Date(2020, 1, 1, 0, 3, 59, 999999999, time.UTC),
+	}
+	if len(deleted) != len(want) || !deleted[0].Equal(want[0]) || !deleted[1].Equal(want[1]) {
+		t.Errorf("Replayed delete: want=%v, got=%v", want, deleted)
+	}
+}
+
+func TestReplayerImpl_ReplayAlter(t *testing.T) {
+	t.Parallel()
+
+	// --- given ---
+	// change the columns of a bucket to {Open, Trades}
+	dsv := []io.DataShape{
+		{Name: "Epoch", Type: io.INT64},
+		{Name: "Open", Type: io.FLOAT64},
+		{Name: "Trades", Type: io.INT32},
+	}
+	dsvBytes, err := io.DSVToBytes(dsv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alterIndex := int64(wal.AlterIndex)
+	buffer := make([]byte, 16)
+	binary.LittleEndian.PutUint64(buffer[8:], uint64(alterIndex))
+	buffer = append(buffer, dsvBytes...)
+	wtSets := []wal.WTSet{
+		{
+			RecordType: io.FIXED,
+			FilePath:   "/data/AMZN/1Min/OHLC/2020.bin",
+			DataLen:    len(dsvBytes),
+			Buffer:     buffer,
+			DataShapes: dsv,
+		},
+	}
+
+	var altered []io.DataShape
+	alterFunc := func(tbk *io.TimeBucketKey, dsv []io.DataShape) error {
+		if tbk.GetItemKey() != "AMZN/1Min/OHLC" {
+			t.Errorf("Replayed alter: want AMZN/1Min/OHLC, got=%v", tbk.GetItemKey())
+		}
+		altered = dsv
+		return nil
+	}
+	writeFunc := func(csm io.ColumnSeriesMap, isVariableLength bool) error {
+		t.Error("an alter must not be written")
+		return nil
+	}
+	parseTGFunc := func(TG_Serialized []byte, rootPath string) (TGID int64, wtSets2 []wal.WTSet) {
+		return 1, wtSets
+	}
+	r := replication.NewReplayer(parseTGFunc, writeFunc, nil, alterFunc, nil, "/file/path")
+
+	// --- when ---
+	err = r.Replay(nil)
+
+	// --- then ---
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if !io.DataShapesEqual(altered, dsv[1:]) {
+		t.Errorf("Replayed alter: want=%v, got=%v", dsv[1:], altered)
+	}
+}
+
+func TestReplayerImpl_ReplayDrop(t *testing.T) {
+	t.Parallel()
+	// --- given ---
+	// remove the offloaded year 2010 of a bucket
+	dropIndex := int64(wal.DropIndex)
+	buffer := make([]byte, 16)
+	binary.LittleEndian.PutUint64(buffer[8:], uint64(dropIndex))
+	wtSets := []wal.WTSet{
+		{
+			RecordType: io.FIXED,
+			FilePath:   "/data/AMZN/1Min/OHLC/2010.remote",
+			Buffer:     buffer,
+		},
+	}
+
+	var dropped int16
+	dropFunc := func(tbk *io.TimeBucketKey, year int16) error {
+		if tbk.GetItemKey() != "AMZN/1Min/OHLC" {
+			t.Errorf("Replayed drop: want AMZN/1Min/OHLC, got=%v", tbk.GetItemKey())
+		}
+		dropped = year
+		return nil
+	}
+	writeFunc := func(csm io.ColumnSeriesMap, isVariableLength bool) error {
+		t.Error("a drop must not be written")
+		return nil
+	}
+	parseTGFunc := func(TG_Serialized []byte, rootPath string) (TGID int64, wtSets2 []wal.WTSet) {
+		return 1, wtSets
+	}
+	r := replication.NewReplayer(parseTGFunc, writeFunc, nil, nil, dropFunc, "/file/path")
+
+	// --- when ---
+	err := r.Replay(nil)
+
+	// --- then ---
+	if err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if dropped != 2010 {
+		t.Errorf("Replayed drop: want year 2010, got=%v", dropped)
+	}
+}
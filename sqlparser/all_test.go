@@ -2,7 +2,9 @@ package sqlparser_test
 
 import (
 	"fmt"
+	"math"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -195,6 +197,701 @@ func TestExecutableStatement(t *testing.T) {
 	assert.Equal(t, cs.Len(), 0)
 }
 
+func TestOrderBy(t *testing.T) {
+	tearDown, metadata := setup(t, "TestOrderBy")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	// Top N by a data column
+	cs := materialize(t, aggRunner, metadata, "SELECT Epoch, Volume from `AAPL/1Min/OHLCV` "+
+		"WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY Volume DESC LIMIT 3;", false)
+	assert.Equal(t, 3, cs.Len())
+	volume, ok := cs.GetColumn("Volume").([]int32)
+	assert.True(t, ok)
+	assert.True(t, volume[0] > volume[1] && volume[1] > volume[2])
+	epoch := cs.GetEpoch()
+	assert.True(t, epoch[0] > epoch[1] && epoch[1] > epoch[2])
+
+	// Epoch ordering is pushed down with the limit
+	cs = materialize(t, aggRunner, metadata,
+		"SELECT Epoch, Close from `AAPL/1Min/OHLCV` ORDER BY Epoch DESC LIMIT 5;", false)
+	assert.Equal(t, 5, cs.Len())
+	epoch = cs.GetEpoch()
+	for i := 1; i < len(epoch); i++ {
+		assert.True(t, epoch[i-1] > epoch[i])
+	}
+
+	// Multiple keys, by alias and by the source name of an aliased column
+	for _, stmt := range []string{
+		"SELECT Epoch, Open AS o from `AAPL/1Min/OHLCV` " +
+			"WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY o DESC, Epoch ASC;",
+		"SELECT Epoch, Open AS o from `AAPL/1Min/OHLCV` " +
+			"WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY Open DESC NULLS LAST, 1;",
+	} {
+		cs = materialize(t, aggRunner, metadata, stmt, false)
+		assert.Equal(t, 29, cs.Len())
+		open, ok := cs.GetColumn("o").([]float32)
+		assert.True(t, ok)
+		epoch = cs.GetEpoch()
+		for i := 1; i < len(open); i++ {
+			assert.True(t, open[i-1] > open[i] || (open[i-1] == open[i] && epoch[i-1] < epoch[i]))
+		}
+	}
+
+	// Function output, by the name of a column of a function with several outputs
+	cs = materialize(t, aggRunner, metadata, "SELECT TickCandler('5Min', Open) from `AAPL/1Min/OHLCV` "+
+		"WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY Close DESC;", false)
+	assert.Equal(t, 6, cs.Len())
+	closes, ok := cs.GetColumn("Close").([]float32)
+	assert.True(t, ok)
+	for i := 1; i < len(closes); i++ {
+		assert.True(t, closes[i-1] >= closes[i])
+	}
+	_ = materialize(t, aggRunner, metadata, "SELECT TickCandler('5Min', Open) from `AAPL/1Min/OHLCV` "+
+		"WHERE Epoch BETWEEN '2000-01-05-12:30' AND '2000-01-05-13:00' ORDER BY TickCandler('5Min', Open);", true)
+
+	// A function call matches the arguments of the select list
+	const hours = "WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-14:00' GROUP BY date_trunc('1H', Epoch) "
+	cs = materialize(t, aggRunner, metadata,
+		"SELECT count(*), sum(Volume) FROM `AAPL/1Min/OHLCV` "+hours+"ORDER BY sum(Volume) DESC;", false)
+	assert.Equal(t, []float64{394230, 384149}, cs.GetColumn("Sum"))
+	_ = materialize(t, aggRunner, metadata,
+		"SELECT sum(Volume) FROM `AAPL/1Min/OHLCV` "+hours+"ORDER BY sum(Close);", true)
+
+	// Sort keys must be in the output
+	_ = materialize(t, aggRunner, metadata, "SELECT Epoch, Open from `AAPL/1Min/OHLCV` ORDER BY Volume LIMIT 1;", true)
+	_ = materialize(t, aggRunner, metadata, "SELECT Epoch, Open from `AAPL/1Min/OHLCV` ORDER BY 3 LIMIT 1;", true)
+}
+
+func TestGroupBy(t *testing.T) {
+	tearDown, metadata := setup(t, "TestGroupBy")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	const window = "WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-14:00' "
+	hour12 := time.Date(2000, time.January, 5, 12, 0, 0, 0, time.UTC).Unix()
+	hour13 := time.Date(2000, time.January, 5, 13, 0, 0, 0, time.UTC).Unix()
+
+	// Hourly buckets, 12:01 to 12:59 and 13:00 to 13:59. FIRST and LAST are
+	// keywords, so those function names have to be quoted
+	cs := materialize(t, aggRunner, metadata,
+		"SELECT date_trunc('1H', Epoch) AS hr, count(*), max(High), min(Low), sum(Volume), "+
+			"`first`(Volume), `last`(Volume) FROM `AAPL/1Min/OHLCV` "+window+"GROUP BY hr;", false)
+	assert.Equal(t, 2, cs.Len())
+	assert.Equal(t, []int64{hour12, hour13}, cs.GetEpoch())
+	assert.Equal(t, []int64{hour12, hour13}, cs.GetColumn("hr"))
+	assert.Equal(t, []int64{59, 60}, cs.GetColumn("Count"))
+	high, low := float32(0.2), float32(0.3)
+	high *= 15
+	assert.Equal(t, []float32{high, high}, cs.GetColumn("Max"))
+	assert.Equal(t, []float32{low, low}, cs.GetColumn("Min"))
+	assert.Equal(t, []float64{384149, 394230}, cs.GetColumn("Sum"))
+	assert.Equal(t, []int32{6482, 6541}, cs.GetColumn("First"))
+	assert.Equal(t, []int32{6540, 6600}, cs.GetColumn("Last"))
+
+	// HAVING on an aggregate, by function call and by alias
+	for _, stmt := range []string{
+		"SELECT count(*), sum(Volume) FROM `AAPL/1Min/OHLCV` " + window +
+			"GROUP BY date_trunc('1H', Epoch) HAVING count(*) > 59;",
+		"SELECT count(*) AS n, sum(Volume) FROM `AAPL/1Min/OHLCV` " + window +
+			"GROUP BY date_trunc('1H', Epoch) HAVING n BETWEEN 59 AND 100 OR n < 0;",
+	} {
+		cs = materialize(t, aggRunner, metadata, stmt, false)
+		assert.Equal(t, 1, cs.Len())
+		assert.Equal(t, []int64{hour13}, cs.GetEpoch())
+	}
+
+	// The Symbol of the bucket, along with the time buckets
+	cs = materialize(t, aggRunner, metadata,
+		"SELECT Symbol, date_trunc('1H', Epoch), sum(Volume) FROM `AAPL/1Min/OHLCV` "+window+
+			"GROUP BY 1, 2 ORDER BY sum(Volume) DESC;", false)
+	assert.Equal(t, 2, cs.Len())
+	symbols, ok := cs.GetColumn("Symbol").([][16]rune)
+	assert.True(t, ok)
+	assert.Equal(t, [16]rune{'A', 'A', 'P', 'L'}, symbols[0])
+	assert.Equal(t, []int64{hour13, hour12}, cs.GetEpoch())
+
+	cs = materialize(t, aggRunner, metadata,
+		"SELECT Symbol, avg(Close) FROM `AAPL/1Min/OHLCV` "+window+"GROUP BY Symbol;", false)
+	assert.Equal(t, 1, cs.Len())
+
+	// Select list columns must be grouped and functions must aggregate
+	_ = materialize(t, aggRunner, metadata,
+		"SELECT Open, count(*) FROM `AAPL/1Min/OHLCV` "+window+"GROUP BY Symbol;", true)
+	_ = materialize(t, aggRunner, metadata,
+		"SELECT count(*) FROM `AAPL/1Min/OHLCV` "+window+"GROUP BY date_trunc('1H', Open);", true)
+	_ = materialize(t, aggRunner, metadata, "SELECT TickCandler('5Min', Open) FROM `AAPL/1Min/OHLCV` "+window+
+		"GROUP BY date_trunc('1H', Epoch);", true)
+	_ = materialize(t, aggRunner, metadata, "SELECT count(*) FROM `AAPL/1Min/OHLCV` "+window+
+		"GROUP BY date_trunc('1H', Epoch) HAVING max(High) > 1;", true)
+}
+
+func TestJoin(t *testing.T) {
+	tearDown, metadata := setup(t, "TestJoin")
+	defer tearDown()
+	aggRunner := sqlparser.NewAggRunner(nil)
+
+	const window = " WHERE Epoch BETWEEN '2000-01-01-00:00' AND '2000-01-01-00:12';"
+
+	// As-of join, the 5Min bar from before the window applies to its first minutes
+	stmt := "SELECT m.Volume, f.Volume, f.Epoch FROM `AAPL/1Min/OHLCV` m " +
+		"LEFT JOIN `AAPL/5Min/OHLCV` f ON m.Epoch >= f.Epoch" + window
+	cs := materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, 11, cs.Len())
+	assert.Equal(t, []int32{2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}, cs.GetColumn("m.Volume"))
+	assert.Equal(t, []int32{1, 1, 1, 1, 2, 2, 2, 2, 2, 3, 3}, cs.GetColumn("f.Volume"))
+	fiveMinEpochs, ok := cs.GetColumn("f.Epoch").([]int64)
+	assert.True(t, ok)
+	for i, epoch := range cs.GetEpoch() {
+		assert.Equal(t, epoch-epoch%300, fiveMinEpochs[i])
+	}
+
+	// The same join written from the other side, and with a strict inequality
+	stmt = "SELECT f.Volume FROM `AAPL/1Min/OHLCV` AS m " +
+		"JOIN `AAPL/5Min/OHLCV` AS f ON f.Epoch < m.Epoch" + window
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []int32{1, 1, 1, 1, 1, 2, 2, 2, 2, 2, 3}, cs.GetColumn("f.Volume"))
+
+	// Equi-join, only the minutes starting a 5Min bar have a match
+	stmt = "SELECT * FROM `AAPL/1Min/OHLCV` m JOIN `AAPL/5Min/OHLCV` f USING (Epoch)" + window
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, 2, cs.Len())
+	assert.Equal(t, []int32{6, 11}, cs.GetColumn("m.Volume"))
+	assert.Equal(t, []int32{2, 3}, cs.GetColumn("f.Volume"))
+	assert.Equal(t, cs.GetEpoch(), cs.GetColumn("f.Epoch"))
+
+	// Unmatched rows of a LEFT join are zero, or NaN for floats
+	stmt = "SELECT f.Volume, f.Close FROM `AAPL/1Min/OHLCV` m LEFT JOIN `AAPL/5Min/OHLCV` f " +
+		"ON m.Epoch > f.Epoch WHERE Epoch < '2000-01-01-00:03';"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, 3, cs.Len())
+	assert.Equal(t, []int32{0, 1, 1}, cs.GetColumn("f.Volume"))
+	closes, ok := cs.GetColumn("f.Close").([]float32)
+	assert.True(t, ok)
+	assert.True(t, math.IsNaN(float64(closes[0])))
+	stmt = strings.Replace(stmt, "LEFT JOIN", "JOIN", 1)
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, 2, cs.Len())
+
+	// Without an alias the columns are prefixed with the attribute group
+	stmt = "SELECT m.Close, OHLCV.Close FROM `AAPL/1Min/OHLCV` m JOIN `BBPL/1Min/OHLCV` " +
+		"ON m.Epoch = OHLCV.Epoch" + window
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, 11, cs.Len())
+	assert.Equal(t, cs.GetColumn("m.Close"), cs.GetColumn("OHLCV.Close"))
+
+	for _, stmt := range []string{
+		// Ambiguous prefixes
+		"SELECT * FROM `AAPL/1Min/OHLCV` JOIN `AAPL/5Min/OHLCV` USING (Epoch);",
+		// Join conditions must compare the Epochs
+		"SELECT * FROM `AAPL/1Min/OHLCV` m JOIN `AAPL/5Min/OHLCV` f ON m.Close = f.Close;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` m JOIN `AAPL/5Min/OHLCV` f ON m.Epoch <= f.Epoch;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` m JOIN `AAPL/5Min/OHLCV` f USING (Close);",
+		"SELECT * FROM `AAPL/1Min/OHLCV` m JOIN `AAPL/5Min/OHLCV` f;",
+		// Unsupported join types and relations
+		"SELECT * FROM `AAPL/1Min/OHLCV` m FULL OUTER JOIN `AAPL/5Min/OHLCV` f USING (Epoch);",
+		"SELECT * FROM `AAPL/1Min/OHLCV` m CROSS JOIN `AAPL/5Min/OHLCV` f;",
+		"SELECT * FROM `AAPL/1Min/OHLCV` m, `AAPL/5Min/OHLCV` f;",
+	} {
+		_ = materialize(t, aggRunner, metadata, stmt, true)
+	}
+}
+
+func TestWhere(t *testing.T) {
+	tearDown, metadata := setup(t, "TestWhere")
+	defer tearDown()
+	aggRunner := sqlparser.NewAggRunner(nil)
+
+	// 119 rows with a Volume from 6482 to 6600
+	const query = "SELECT Epoch, Volume FROM `AAPL/1Min/OHLCV` " +
+		"WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-14:00' AND "
+
+	cases := []struct {
+		where  string
+		length int
+	}{
+		{"(Volume > 6590 OR Volume < 6485)", 13},
+		{"Volume >= 1E6 AND (Close > Open OR Symbol IN ('AAPL', 'MSFT'))", 0},
+		{"Volume < 1E6 AND (Close > Open OR Symbol IN ('AAPL', 'MSFT'))", 119},
+		{"NOT (Close > Open)", 0},
+		{"NOT (Volume > 6590 OR Volume < 6485)", 106},
+		{"NOT (6500 <= Volume AND Volume < 6510.5)", 108},
+		{"NOT (NOT (Volume > 6590))", 10},
+		{"Volume <> 6500", 118},
+		{"Volume NOT BETWEEN 6490 AND 6590", 20},
+		{"Volume IN (6482, 6600, 1)", 2},
+		{"Symbol IN ('AAPL', 'MSFT')", 119},
+		{"Symbol NOT IN ('AAPL')", 0},
+		{"Symbol LIKE 'AA%'", 119},
+		{"Symbol LIKE '_APL'", 119},
+		{"Symbol NOT LIKE 'A'", 119},
+		{"Symbol LIKE 'A+%' ESCAPE '+'", 0},
+		// Epoch terms under an OR are evaluated per row, like other columns
+		{"(Volume > 6590 OR Epoch < '2000-01-05-12:03')", 12},
+		{"(Volume > 6590 OR Epoch <= 947073720)", 12},
+		{"Volume BETWEEN 6500 AND 6509 AND Close > 0", 8},
+		{"6500 <= Volume AND Volume < 6510.5", 11},
+		{"Volume = 6600", 1},
+		{"Volume > 1E6", 0},
+	}
+	for _, tc := range cases {
+		stmt := query + tc.where + ";"
+		cs := materialize(t, aggRunner, metadata, stmt, false)
+		assert.Equal(t, tc.length, cs.Len(), stmt)
+		assert.False(t, cs.Exists("Symbol"), stmt)
+	}
+
+	// the block index does not change the rows returned
+	yearFile := filepath.Join(metadata.CatalogDir.GetPath(), "AAPL/1Min/OHLCV/2000.bin")
+	assert.Nil(t, executor.BuildBlockIndex(&io.TimeBucketInfo{Year: 2000, Path: yearFile}))
+	for _, tc := range cases {
+		stmt := query + tc.where + ";"
+		cs := materialize(t, aggRunner, metadata, stmt, false)
+		assert.Equal(t, tc.length, cs.Len(), stmt)
+	}
+
+	cs := materialize(t, aggRunner, metadata, query+"Volume IN (6482, 6600);", false)
+	assert.Equal(t, []int32{6482, 6600}, cs.GetColumn("Volume"))
+
+	for _, where := range []string{
+		"Fooble > 1",
+		"Close LIKE 'A%'",
+		"Symbol > 1",
+		"count(*) > 1",
+		"Volume IN (SELECT Volume FROM `AAPL/1Min/OHLCV`)",
+	} {
+		stmt := query + where + ";"
+		_ = materialize(t, aggRunner, metadata, stmt, true)
+	}
+}
+
+func TestNullValues(t *testing.T) {
+	tearDown, metadata := setup(t, "TestNullValues")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	// Close is NULL in the rows 1 and 3, and zero in the row 2
+	tbk := io.NewTimeBucketKey("TEST-NULL/1Min/OHLCV")
+	base := time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC).Unix()
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{base, base + 60, base + 120, base + 180})
+	cs.AddColumn("Close", []float32{1, 0, 0, 4})
+	cs.SetNulls("Close", []bool{false, true, false, true})
+	cs.AddColumn("Volume", []int64{10, 20, 30, 40})
+	csm := io.NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.WriteCSM(csm, false))
+
+	const query = "SELECT Epoch, Volume FROM `TEST-NULL/1Min/OHLCV` WHERE "
+	for _, tc := range []struct {
+		where  string
+		volume []int64
+	}{
+		{"Close IS NULL", []int64{20, 40}},
+		{"Close IS NOT NULL", []int64{10, 30}},
+		{"NOT Close IS NULL", []int64{10, 30}},
+		{"Volume IS NULL", []int64{}},
+		{"Close = 0", []int64{30}},
+		{"Close < 2", []int64{10, 30}},
+		// a comparison with a NULL is unknown, its negation does not match either
+		{"NOT Close < 2", []int64{}},
+		{"NOT (Close < 2 AND Volume > 15)", []int64{10}},
+		{"NOT (Close > 2 OR Volume > 25)", []int64{10}},
+		{"Close NOT IN (1)", []int64{30}},
+		{"Close * 2 >= 0", []int64{10, 30}},
+		{"Volume = 10 OR Close IS NULL", []int64{10, 20, 40}},
+	} {
+		stmt := query + tc.where + ";"
+		got := materialize(t, aggRunner, metadata, stmt, false)
+		volume, _ := got.GetColumn("Volume").([]int64)
+		if volume == nil {
+			volume = []int64{}
+		}
+		assert.Equal(t, tc.volume, volume, stmt)
+	}
+
+	got := materialize(t, aggRunner, metadata, "SELECT * FROM `TEST-NULL/1Min/OHLCV`;", false)
+	assert.Equal(t, []bool{false, true, false, true}, got.Nulls("Close"))
+	assert.Equal(t, []bool{false, false, false, false}, got.Nulls("Volume"))
+
+	// the aggregates leave the NULL values out
+	got = materialize(t, aggRunner, metadata, "SELECT Avg(Close) FROM `TEST-NULL/1Min/OHLCV`;", false)
+	assert.Equal(t, []float64{0.5}, got.GetColumn("Avg"))
+	assert.Equal(t, []bool{false}, got.Nulls("Avg"))
+	got = materialize(t, aggRunner, metadata,
+		"SELECT Max(Close) FROM `TEST-NULL/1Min/OHLCV` WHERE Close IS NULL;", false)
+	assert.Equal(t, []bool{true}, got.Nulls("Max"))
+	got = materialize(t, aggRunner, metadata, "SELECT TickCandler('5Min', Close) FROM `TEST-NULL/1Min/OHLCV`;", false)
+	assert.Equal(t, []float32{1}, got.GetColumn("Open"))
+	assert.Equal(t, []float32{0}, got.GetColumn("Close"))
+	assert.Equal(t, []float32{0}, got.GetColumn("Low"))
+}
+
+func TestHeapColumnPredicates(t *testing.T) {
+	tearDown, metadata := setup(t, "TestHeapColumnPredicates")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	tbk := io.NewTimeBucketKey("TEST-HEAP/1Min/TRADE")
+	base := time.Date(2019, 1, 2, 0, 0, 0, 0, time.UTC).Unix()
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{base, base + 60, base + 120})
+	cs.AddColumn("Size", []int64{10, 20, 30})
+	cs.AddColumn("Cond", []string{"@ F", "@ T", ""})
+	cs.AddColumn("Raw", [][]byte{[]byte("b"), []byte("a"), []byte("c")})
+	csm := io.NewColumnSeriesMap()
+	csm.AddColumnSeries(*tbk, cs)
+	writer, err := executor.NewWriter(metadata.CatalogDir, metadata.WALFile)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.WriteCSM(csm, false))
+
+	for _, tc := range []struct {
+		where string
+		size  []int64
+	}{
+		{"Cond = '@ T'", []int64{20}},
+		{"Cond LIKE '@%'", []int64{10, 20}},
+		{"Cond NOT LIKE '%F'", []int64{20, 30}},
+		{"Raw IN ('a', 'c')", []int64{20, 30}},
+		{"Raw LIKE 'b'", []int64{10}},
+	} {
+		stmt := "SELECT Size FROM `TEST-HEAP/1Min/TRADE` WHERE " + tc.where + ";"
+		queryTree, err := sqlparser.BuildQueryTree(stmt)
+		evalAndPrint(t, err, false, stmt)
+		es, err := sqlparser.NewExecutableStatement(queryTree)
+		evalAndPrint(t, err, false, stmt)
+		got, err := es.Materialize(aggRunner, metadata.CatalogDir)
+		evalAndPrint(t, err, false, stmt)
+		size, _ := got.GetColumn("Size").([]int64)
+		if size == nil {
+			size = []int64{}
+		}
+		assert.Equal(t, tc.size, size, stmt)
+	}
+
+	stmt := "SELECT Cond, Raw FROM `TEST-HEAP/1Min/TRADE` ORDER BY Raw;"
+	queryTree, err := sqlparser.BuildQueryTree(stmt)
+	evalAndPrint(t, err, false, stmt)
+	es, err := sqlparser.NewExecutableStatement(queryTree)
+	evalAndPrint(t, err, false, stmt)
+	got, err := es.Materialize(aggRunner, metadata.CatalogDir)
+	evalAndPrint(t, err, false, stmt)
+	assert.Equal(t, []string{"@ T", "@ F", ""}, got.GetColumn("Cond"))
+}
+
+func TestSelectExpressions(t *testing.T) {
+	tearDown, metadata := setup(t, "TestSelectExpressions")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	// 4 rows, from 12:01 to 12:04
+	const from = " FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-12:05';"
+
+	stmt := "SELECT Epoch, Close, Open, (Close-Open)/Open AS ret, Volume * 2 AS v2, -Volume, " +
+		"Volume / 1000, Volume % 7, Close * 2, Volume + 0.5" + from
+	cs := materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []string{"Epoch", "Close", "Open", "ret", "v2", "_col5", "_col6", "_col7", "_col8", "_col9"},
+		cs.GetColumnNames())
+	closes, _ := cs.GetColumn("Close").([]float32)
+	opens, _ := cs.GetColumn("Open").([]float32)
+	volumes := []int32{6482, 6483, 6484, 6485}
+	assert.Len(t, opens, 4)
+	for i := range opens {
+		assert.InDelta(t, float64((closes[i]-opens[i])/opens[i]), cs.GetColumn("ret").([]float32)[i], 1e-6)
+	}
+	assert.Equal(t, []int32{12964, 12966, 12968, 12970}, cs.GetColumn("v2"))
+	assert.Equal(t, []int32{-6482, -6483, -6484, -6485}, cs.GetColumn("_col5"))
+	assert.Equal(t, []int32{6, 6, 6, 6}, cs.GetColumn("_col6")) // Integer division truncates
+	assert.Equal(t, []int32{0, 1, 2, 3}, cs.GetColumn("_col7"))
+	assert.IsType(t, []float32{}, cs.GetColumn("_col8"))
+	assert.Equal(t, []float64{6482.5, 6483.5, 6484.5, 6485.5}, cs.GetColumn("_col9"))
+
+	stmt = "SELECT CAST(Volume AS FLOAT64) AS Volume, CAST(Close AS INT64) AS c, " +
+		"CAST(Volume AS DOUBLE PRECISION) AS d, CAST(Volume AS uint16) AS u" + from
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []float64{6482, 6483, 6484, 6485}, cs.GetColumn("Volume"))
+	assert.IsType(t, []int64{}, cs.GetColumn("c"))
+	assert.IsType(t, []float64{}, cs.GetColumn("d"))
+	assert.Equal(t, []uint16{6482, 6483, 6484, 6485}, cs.GetColumn("u"))
+
+	stmt = "SELECT log(Volume) AS l, log(10, 100) AS l10, sqrt(abs(-Volume)) AS s, round(Close * 1.234, 2) AS r, " +
+		"pow(2, 10) AS p, hour(Epoch) AS h, minute(Epoch) AS m, day_of_week(Epoch) AS dow, year(Epoch + 60) AS y" + from
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	for i, volume := range volumes {
+		assert.InDelta(t, math.Log(float64(volume)), cs.GetColumn("l").([]float64)[i], 1e-9)
+		assert.InDelta(t, math.Sqrt(float64(volume)), cs.GetColumn("s").([]float64)[i], 1e-9)
+	}
+	assert.Equal(t, []float64{2, 2, 2, 2}, cs.GetColumn("l10"))
+	assert.Equal(t, []float64{1024, 1024, 1024, 1024}, cs.GetColumn("p"))
+	assert.Equal(t, []int64{12, 12, 12, 12}, cs.GetColumn("h"))
+	assert.Equal(t, []int64{1, 2, 3, 4}, cs.GetColumn("m"))
+	assert.Equal(t, []int64{3, 3, 3, 3}, cs.GetColumn("dow")) // A Wednesday
+	assert.Equal(t, []int64{2000, 2000, 2000, 2000}, cs.GetColumn("y"))
+	assert.IsType(t, []float32{}, cs.GetColumn("r")) // The literal takes the type of Close
+
+	// Expressions can be used in WHERE too
+	stmt = "SELECT Epoch, Volume FROM `AAPL/1Min/OHLCV` " +
+		"WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-14:00' AND Volume % 10 = 0 AND -Volume < -6550;"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []int32{6560, 6570, 6580, 6590, 6600}, cs.GetColumn("Volume"))
+
+	for _, stmt := range []string{
+		"SELECT Volume / 0" + from,
+		"SELECT Fooble * 2" + from,
+		"SELECT max(Close) - 1" + from,
+		"SELECT log(Close, 1, 2)" + from,
+		"SELECT CAST(Close AS STRING)" + from,
+		"SELECT CAST(Close AS fooble)" + from,
+		"SELECT Close * 2, count(*)" + from,
+		"SELECT Volume * 2 AS v, count(*)" + from[:len(from)-1] + " GROUP BY v;",
+	} {
+		_ = materialize(t, aggRunner, metadata, stmt, true)
+	}
+}
+
+func TestSubqueries(t *testing.T) {
+	tearDown, metadata := setup(t, "TestSubqueries")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	// 119 rows, from 12:01 to 13:59
+	const inner = "SELECT * FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-14:00'"
+
+	// The outer query filters the rows of the inner query
+	stmt := "SELECT Epoch, Volume FROM (" + inner + ") WHERE Volume % 10 = 0 AND Volume > 6550;"
+	cs := materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []int32{6560, 6570, 6580, 6590, 6600}, cs.GetColumn("Volume"))
+
+	stmt = "SELECT Volume FROM (" + inner + ") WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-12:05';"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []int32{6482, 6483, 6484, 6485}, cs.GetColumn("Volume"))
+
+	// Candle the ticks in the inner query and filter the candles in the outer one
+	candles := "SELECT tickcandler('5Min', Close) FROM `AAPL/1Min/OHLCV` " +
+		"WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-14:00'"
+	all := materialize(t, aggRunner, metadata, candles+";", false)
+	closes, _ := all.GetColumn("Close").([]float32)
+	threshold := float64(closes[len(closes)/2])
+	var want []int64
+	for i, epoch := range all.GetEpoch() {
+		if float64(closes[i]) > threshold {
+			want = append(want, epoch)
+		}
+	}
+	assert.NotEmpty(t, want)
+	assert.Less(t, len(want), all.Len())
+	filter := fmt.Sprintf(" WHERE Close > %v", threshold)
+	for _, stmt := range []string{
+		"SELECT * FROM (" + candles + ")" + filter + ";",
+		"WITH candles AS (" + candles + ") SELECT * FROM candles" + filter + ";",
+		"WITH c AS (" + candles + "), up AS (SELECT * FROM c" + filter + ") SELECT Epoch, Close FROM up;",
+	} {
+		cs = materialize(t, aggRunner, metadata, stmt, false)
+		assert.Equal(t, want, cs.GetEpoch())
+	}
+
+	// Column aliases rename the columns of the subquery, except Epoch
+	stmt = "WITH v (vol) AS (SELECT Volume FROM (" + inner + ")) SELECT vol FROM v ORDER BY vol DESC LIMIT 2;"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []int32{6600, 6599}, cs.GetColumn("vol"))
+
+	stmt = "SELECT v FROM (SELECT Volume FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:00' AND " +
+		"'2000-01-05-12:05') t (v);"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []int32{6482, 6483, 6484, 6485}, cs.GetColumn("v"))
+
+	// A parenthesized query only gets the ORDER BY and LIMIT of the enclosing query
+	stmt = "(" + inner + ") ORDER BY Volume DESC LIMIT 1;"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []int32{6600}, cs.GetColumn("Volume"))
+
+	for _, stmt := range []string{
+		"WITH RECURSIVE c AS (" + inner + ") SELECT * FROM c;",
+		"WITH c AS (" + inner + "), c AS (" + inner + ") SELECT * FROM c;",
+		"WITH c (a, b) AS (SELECT Volume FROM (" + inner + ")) SELECT * FROM c;",
+		"WITH c AS (" + inner + ") SELECT * FROM d;",
+		"WITH c AS (SELECT * FROM d) SELECT * FROM c;",
+		"SELECT * FROM (SELECT Fooble FROM (" + inner + "));",
+	} {
+		_ = materialize(t, aggRunner, metadata, stmt, true)
+	}
+}
+
+func TestWindowFunctions(t *testing.T) {
+	tearDown, metadata := setup(t, "TestWindowFunctions")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	assertFloats := func(expected, actual []float64) {
+		t.Helper()
+		assert.Len(t, actual, len(expected))
+		for i := range actual {
+			if math.IsNaN(expected[i]) {
+				assert.True(t, math.IsNaN(actual[i]), "row %d", i)
+				continue
+			}
+			assert.InDelta(t, expected[i], actual[i], 1e-6, "row %d", i)
+		}
+	}
+	nan := math.NaN()
+	// 4 rows, from 12:01 to 12:04
+	const from = " FROM `AAPL/1Min/OHLCV` WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-12:05'"
+
+	stmt := "SELECT Epoch, Volume, Close, " +
+		"avg(Volume) OVER (ORDER BY Epoch ROWS 1 PRECEDING) AS ma2, " +
+		"sma(Volume) OVER (ORDER BY Epoch ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) AS ma3, " +
+		"sum(Volume) OVER (ORDER BY Epoch) AS total, " +
+		"cumsum(Volume) OVER (ORDER BY Epoch ROWS UNBOUNDED PRECEDING) AS running, " +
+		"rolling_max(Volume) OVER (ORDER BY Epoch ROWS 1 PRECEDING) AS mx, " +
+		"min(Volume) OVER (ORDER BY Epoch ROWS CURRENT ROW) AS mn, " +
+		"stddev(Volume) OVER (ORDER BY Epoch ROWS 1 PRECEDING) AS sd, " +
+		"ema(Volume) OVER (ORDER BY Epoch ROWS 2 PRECEDING) AS e, " +
+		"lag(Volume) OVER (ORDER BY Epoch) AS prev, " +
+		"lead(Volume, 2) OVER (ORDER BY Epoch) AS next2, " +
+		"pct_change(Volume) OVER (ORDER BY Epoch) AS pc, " +
+		"vwap(Close, Volume) OVER (ORDER BY Epoch), " +
+		"rolling_sum(Volume) OVER (ORDER BY Epoch ROWS 1 PRECEDING)" + from + ";"
+	cs := materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []string{"Epoch", "Volume", "Close", "ma2", "ma3", "total", "running", "mx", "mn", "sd", "e",
+		"prev", "next2", "pc", "_col14", "_col15"}, cs.GetColumnNames())
+	assertFloats([]float64{6482, 6482.5, 6483.5, 6484.5}, cs.GetColumn("ma2").([]float64))
+	assertFloats([]float64{6482, 6482.5, 6483, 6484}, cs.GetColumn("ma3").([]float64))
+	assertFloats([]float64{6482, 12965, 19449, 25934}, cs.GetColumn("total").([]float64))
+	assertFloats([]float64{6482, 12965, 19449, 25934}, cs.GetColumn("running").([]float64))
+	assertFloats([]float64{6482, 6483, 6484, 6485}, cs.GetColumn("mx").([]float64))
+	assertFloats([]float64{6482, 6483, 6484, 6485}, cs.GetColumn("mn").([]float64))
+	assertFloats([]float64{nan, math.Sqrt(0.5), math.Sqrt(0.5), math.Sqrt(0.5)}, cs.GetColumn("sd").([]float64))
+	assertFloats([]float64{6482, 6482.5, 6483.25, 6484.125}, cs.GetColumn("e").([]float64)) // 2/(3+1) smoothing
+	assertFloats([]float64{nan, 6482, 6483, 6484}, cs.GetColumn("prev").([]float64))
+	assertFloats([]float64{6484, 6485, nan, nan}, cs.GetColumn("next2").([]float64))
+	assertFloats([]float64{nan, 6483.0/6482 - 1, 6484.0/6483 - 1, 6485.0/6484 - 1}, cs.GetColumn("pc").([]float64))
+	assertFloats([]float64{12965, 12967, 12969}, cs.GetColumn("_col15").([]float64)[1:])
+	closes, _ := cs.GetColumn("Close").([]float32)
+	var weighted, volume float64
+	vwap := make([]float64, len(closes))
+	for i, c := range closes {
+		weighted += float64(c) * float64(6482+i)
+		volume += float64(6482 + i)
+		vwap[i] = weighted / volume
+	}
+	assertFloats(vwap, cs.GetColumn("_col14").([]float64))
+
+	// The window is in time order, whatever the order of the rows
+	for _, stmt := range []string{
+		"SELECT Epoch, Volume, lag(Volume) OVER (ORDER BY Epoch) AS prev" + from + " ORDER BY Volume DESC;",
+		"SELECT Volume, lag(Volume) OVER (ORDER BY Epoch) AS prev FROM (SELECT *" + from + " ORDER BY Volume DESC);",
+	} {
+		cs = materialize(t, aggRunner, metadata, stmt, false)
+		assert.Equal(t, []int32{6485, 6484, 6483, 6482}, cs.GetColumn("Volume"))
+		assertFloats([]float64{6484, 6483, 6482, nan}, cs.GetColumn("prev").([]float64))
+	}
+
+	for _, stmt := range []string{
+		"SELECT avg(Volume) OVER (PARTITION BY Fooble ORDER BY Epoch)" + from + ";",
+		"SELECT avg(Volume) OVER (PARTITION BY Close + 1 ORDER BY Epoch)" + from + ";",
+		"SELECT avg(Volume) OVER (ORDER BY Close)" + from + ";",
+		"SELECT avg(Volume) OVER (ORDER BY Epoch DESC)" + from + ";",
+		"SELECT avg(Volume) OVER (ORDER BY Epoch RANGE 2 PRECEDING)" + from + ";",
+		"SELECT avg(Volume) OVER (ORDER BY Epoch ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING)" + from + ";",
+		"SELECT avg(Volume) OVER (ORDER BY Epoch ROWS 1 FOLLOWING)" + from + ";",
+		"SELECT ema(Volume) OVER (ORDER BY Epoch)" + from + ";",
+		"SELECT count(*) OVER (ORDER BY Epoch)" + from + ";",
+		"SELECT lag(Volume, 'x') OVER (ORDER BY Epoch)" + from + ";",
+		"SELECT sma(Volume, 2) OVER (ORDER BY Epoch)" + from + ";",
+		"SELECT cumsum(Volume) OVER (ORDER BY Epoch ROWS 2 PRECEDING)" + from + ";",
+		"SELECT sma(Fooble) OVER (ORDER BY Epoch)" + from + ";",
+		"SELECT sma(Volume) OVER (ORDER BY Epoch), count(*)" + from + ";",
+		"SELECT Volume, sma(Volume) OVER (ORDER BY Epoch)" + from + " GROUP BY Volume;",
+		"SELECT Volume" + from + " AND lag(Volume) OVER (ORDER BY Epoch) > 0;",
+	} {
+		_ = materialize(t, aggRunner, metadata, stmt, true)
+	}
+}
+
+func TestMultiSymbolQueries(t *testing.T) {
+	tearDown, metadata := setup(t, "TestMultiSymbolQueries")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	symbolsOf := func(cs *io.ColumnSeries) (symbols []string) {
+		t.Helper()
+		column, ok := cs.GetColumn("Symbol").([][16]rune)
+		assert.True(t, ok)
+		for _, symbol := range column {
+			symbols = append(symbols, strings.TrimRight(string(symbol[:]), "\x00"))
+		}
+		return symbols
+	}
+	// 4 rows per symbol, from 12:01 to 12:04
+	const window = " WHERE Epoch BETWEEN '2000-01-05-12:00' AND '2000-01-05-12:05'"
+
+	// The rows of all the symbols are in time order, with a Symbol column
+	stmt := "SELECT * FROM `*/1Min/OHLCV`" + window + ";"
+	cs := materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []string{"Epoch", "Symbol", "Open", "High", "Low", "Close", "Volume"}, cs.GetColumnNames())
+	assert.Equal(t, 12, cs.Len())
+	assert.Equal(t, []string{"AAPL", "BBPL", "CCPL", "AAPL"}, symbolsOf(cs)[:4])
+	assert.Equal(t, []int32{6482, 6482, 6482, 6483}, cs.GetColumn("Volume").([]int32)[:4])
+
+	// Lists and globs select the symbols, the WHERE clause restricts them
+	for _, tc := range []struct {
+		stmt    string
+		symbols []string
+		rows    int
+	}{
+		{"SELECT Symbol FROM `AAPL,CCPL/1Min/OHLCV`" + window, []string{"AAPL", "CCPL"}, 8},
+		{"SELECT Symbol FROM `?BPL,C*/1Min/OHLCV`" + window, []string{"BBPL", "CCPL"}, 8},
+		{"SELECT Symbol FROM `*/1Min/OHLCV`" + window + " AND Symbol IN ('AAPL', 'BBPL', 'MSFT')",
+			[]string{"AAPL", "BBPL"}, 8},
+		{"SELECT Symbol FROM `*/1Min/OHLCV`" + window + " AND Symbol = 'CCPL'", []string{"CCPL"}, 4},
+		{"SELECT Symbol FROM `*/1Min/OHLCV`" + window + " AND Symbol NOT LIKE 'A%'", []string{"BBPL", "CCPL"}, 8},
+		{"SELECT Symbol FROM `*/1Min/OHLCV`" + window + " AND (Symbol = 'AAPL' OR Volume > 6484)",
+			[]string{"AAPL", "BBPL", "CCPL"}, 6},
+	} {
+		cs = materialize(t, aggRunner, metadata, tc.stmt+" ORDER BY Symbol;", false)
+		var symbols []string
+		for _, symbol := range symbolsOf(cs) {
+			if len(symbols) == 0 || symbol != symbols[len(symbols)-1] {
+				symbols = append(symbols, symbol)
+			}
+		}
+		assert.Equal(t, tc.symbols, symbols, tc.stmt)
+		assert.Equal(t, tc.rows, cs.Len(), tc.stmt)
+	}
+	stmt = "SELECT * FROM `*/1Min/OHLCV`" + window + " AND Symbol = 'MSFT';"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, 0, cs.Len())
+
+	// Cross-sectional aggregates and windows per symbol
+	stmt = "SELECT Symbol, sum(Volume) AS total FROM `*/1Min/OHLCV`" + window + " GROUP BY Symbol ORDER BY Symbol DESC;"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	assert.Equal(t, []string{"CCPL", "BBPL", "AAPL"}, symbolsOf(cs))
+	assert.Equal(t, []float64{25934, 25934, 25934}, cs.GetColumn("total"))
+
+	stmt = "SELECT Symbol, Volume, lag(Volume) OVER (PARTITION BY Symbol ORDER BY Epoch) AS prev " +
+		"FROM `AAPL,BBPL/1Min/OHLCV`" + window + ";"
+	cs = materialize(t, aggRunner, metadata, stmt, false)
+	prev, _ := cs.GetColumn("prev").([]float64)
+	assert.Len(t, prev, 8)
+	for i := range prev {
+		if i < 2 {
+			assert.True(t, math.IsNaN(prev[i]))
+			continue
+		}
+		assert.Equal(t, float64(6482+i/2-1), prev[i])
+	}
+
+	for _, stmt := range []string{
+		"SELECT * FROM `[/1Min/OHLCV`;",
+		"SELECT * FROM `*/1Sec/OHLCV`;",
+		"SELECT tickcandler('5Min', Close) FROM `*/1Min/OHLCV`;",
+	} {
+		_ = materialize(t, aggRunner, metadata, stmt, true)
+	}
+}
+
 func TestStatementErrors(t *testing.T) {
 	tearDown, metadata := setup(t, "TestStatementErrors")
 	defer tearDown()
@@ -579,6 +1276,23 @@ func parseAndPrintError(t *testing.T, stmt string, shouldErr bool) {
 	evalAndPrint(t, err, shouldErr, stmt)
 }
 
+// materialize executes a statement that has to parse, its execution fails if and only if shouldErr is set.
+func materialize(t *testing.T, aggRunner *sqlparser.AggRunner, metadata *executor.InstanceMetadata,
+	stmt string, shouldErr bool,
+) *io.ColumnSeries {
+	t.Helper()
+
+	queryTree, err := sqlparser.BuildQueryTree(stmt)
+	evalAndPrint(t, err, false, stmt)
+	var cs *io.ColumnSeries
+	es, err := sqlparser.NewExecutableStatement(queryTree)
+	if err == nil {
+		cs, err = es.Materialize(aggRunner, metadata.CatalogDir)
+	}
+	evalAndPrint(t, err, shouldErr, stmt)
+	return cs
+}
+
 func makeTestCS() (csA *io.ColumnSeries) {
 	t1 := time.Date(2016, time.December, 1, 10, 0, 0, 0, time.UTC)
 	t2 := t1.Add(10 * time.Second)
@@ -599,3 +1313,57 @@ func makeTestCS() (csA *io.ColumnSeries) {
 	csA.AddColumn("Five", col5)
 	return csA
 }
+
+func TestStatementTables(t *testing.T) {
+	t.Parallel()
+	for _, tc := range []struct {
+		stmt          string
+		read, written []string
+	}{
+		{"SELECT * FROM `AAPL/1Min/OHLCV`;", []string{"AAPL/1Min/OHLCV"}, nil},
+		{"SELECT * FROM `AAPL,TSLA/1Min/OHLCV` WHERE Close > 1;", []string{"AAPL,TSLA/1Min/OHLCV"}, nil},
+		{
+			"SELECT * FROM `AAPL/1Min/OHLCV` m JOIN `AAPL/5Min/OHLCV` f USING (Epoch);",
+			[]string{"AAPL/1Min/OHLCV", "AAPL/5Min/OHLCV"}, nil,
+		},
+		{
+			"WITH c AS (SELECT * FROM `AAPL/1Min/OHLCV`) SELECT * FROM c;",
+			[]string{"AAPL/1Min/OHLCV"}, nil,
+		},
+		{
+			"INSERT INTO `AAPL/5Min/OHLCV` SELECT * FROM `AAPL/1Min/OHLCV`;",
+			[]string{"AAPL/1Min/OHLCV"}, []string{"AAPL/5Min/OHLCV"},
+		},
+		{`SELECT * FROM "AAPL"."1Min"."OHLCV";`, []string{"AAPL/1Min/OHLCV"}, nil},
+		{"SELECT table_name FROM information_schema.tables;", []string{"*/*/*"}, nil},
+	} {
+		queryTree, err := sqlparser.BuildQueryTree(tc.stmt)
+		if !assert.Nil(t, err, tc.stmt) {
+			continue
+		}
+		es, err := sqlparser.NewExecutableStatement(queryTree)
+		if !assert.Nil(t, err, tc.stmt) {
+			continue
+		}
+		read, written := es.Tables()
+		assert.Equal(t, tc.read, read, tc.stmt)
+		assert.Equal(t, tc.written, written, tc.stmt)
+	}
+}
+
+func TestCatalogTables(t *testing.T) {
+	tearDown, metadata := setup(t, "TestCatalogTables")
+	defer tearDown()
+	aggRunner := sqlparser.NewDefaultAggRunner(metadata.CatalogDir)
+
+	cs := materialize(t, aggRunner, metadata, "SELECT * FROM information_schema.tables;", false)
+	tables, _ := cs.GetColumn("table_name").([]string)
+	assert.Contains(t, tables, "AAPL/1Min/OHLCV")
+	assert.Equal(t, []string{"table_schema", "table_name", "table_type"}, cs.GetColumnNames())
+
+	cs = materialize(t, aggRunner, metadata, "SELECT column_name, data_type FROM INFORMATION_SCHEMA.COLUMNS "+
+		"WHERE table_name = 'AAPL/1Min/OHLCV';", false)
+	assert.Equal(t, []string{"Epoch", "Open", "High", "Low", "Close", "Volume"}, cs.GetColumn("column_name"))
+	assert.Equal(t, "timestamp with time zone", cs.GetColumn("data_type").([]string)[0])
+	assert.Equal(t, "real", cs.GetColumn("data_type").([]string)[1])
+}
@@ -0,0 +1,129 @@
+package sqlparser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+The catalog tables describe the buckets like the information_schema of the SQL databases, so that the SQL
+clients can list the tables and their columns:
+
+	SELECT table_name FROM information_schema.tables
+	SELECT column_name, data_type FROM information_schema.columns WHERE table_name = 'AAPL/1Min/OHLCV'
+
+A table is a bucket, named by its TimeBucketKey. The catalog tables have no Epoch column.
+*/
+var catalogTables = map[string]func(catDir *catalog.Directory) (*io.ColumnSeries, error){
+	"information_schema/tables":  tablesTable,
+	"information_schema/columns": columnsTable,
+}
+
+// catalogSchema is the table_schema of the buckets.
+const catalogSchema = "public"
+
+// catalogTable returns the catalog table of a table name, case insensitive.
+func catalogTable(name string) (func(catDir *catalog.Directory) (*io.ColumnSeries, error), bool) {
+	table, ok := catalogTables[strings.ToLower(name)]
+	return table, ok
+}
+
+// bucketNames returns the sorted item keys of the buckets.
+func bucketNames(catDir *catalog.Directory) []string {
+	names := catalog.ListTimeBucketKeyNames(catDir)
+	sort.Strings(names)
+	return names
+}
+
+func tablesTable(catDir *catalog.Directory) (*io.ColumnSeries, error) {
+	var schemas, names, types []string
+	for _, name := range bucketNames(catDir) {
+		schemas = append(schemas, catalogSchema)
+		names = append(names, name)
+		types = append(types, "BASE TABLE")
+	}
+	cs := io.NewColumnSeries()
+	cs.AddColumn("table_schema", schemas)
+	cs.AddColumn("table_name", names)
+	cs.AddColumn("table_type", types)
+	return cs, nil
+}
+
+// columnsTable lists the columns returned by a SELECT * on each bucket.
+func columnsTable(catDir *catalog.Directory) (*io.ColumnSeries, error) {
+	var (
+		schemas, tables, columns, types, nullables []string
+		positions                                  []int64
+	)
+	for _, name := range bucketNames(catDir) {
+		tbi, err := catDir.GetLatestTimeBucketInfoFromKey(io.NewTimeBucketKey(name))
+		if err != nil {
+			return nil, err
+		}
+		nullable := io.IsNullable(tbi.GetDataShapes())
+		dsv := tbi.GetDataShapesWithEpoch()
+		if tbi.GetRecordType() == io.VARIABLE {
+			dsv = append(dsv, io.DataShape{Name: "Nanoseconds", Type: io.INT32})
+		}
+		var position int64
+		for _, ds := range dsv {
+			if ds.Name == io.NullsColumn {
+				continue
+			}
+			position++
+			schemas = append(schemas, catalogSchema)
+			tables = append(tables, name)
+			columns = append(columns, ds.Name)
+			positions = append(positions, position)
+			types = append(types, SQLTypeName(ds.Name, ds.Type))
+			if nullable && ds.Name != "Epoch" && ds.Name != "Nanoseconds" {
+				nullables = append(nullables, "YES")
+			} else {
+				nullables = append(nullables, "NO")
+			}
+		}
+	}
+	cs := io.NewColumnSeries()
+	cs.AddColumn("table_schema", schemas)
+	cs.AddColumn("table_name", tables)
+	cs.AddColumn("column_name", columns)
+	cs.AddColumn("ordinal_position", positions)
+	cs.AddColumn("data_type", types)
+	cs.AddColumn("is_nullable", nullables)
+	return cs, nil
+}
+
+/*
+SQLTypeName returns the standard SQL type of a result column. The Epoch column is a timestamp, and the unsigned
+integers are the smallest signed type holding all their values.
+*/
+func SQLTypeName(name string, typ io.EnumElementType) string {
+	if name == "Epoch" && (typ == io.INT64 || typ == io.EPOCH) {
+		return "timestamp with time zone"
+	}
+	switch typ {
+	case io.FLOAT32:
+		return "real"
+	case io.FLOAT64:
+		return "double precision"
+	case io.BYTE, io.UINT8, io.INT16:
+		return "smallint"
+	case io.UINT16, io.INT32:
+		return "integer"
+	case io.UINT32, io.INT64, io.EPOCH:
+		return "bigint"
+	case io.UINT64, io.DECIMAL64:
+		return "numeric"
+	case io.BOOL:
+		return "boolean"
+	case io.STRING, io.STRING16:
+		return "text"
+	case io.BLOB:
+		return "bytea"
+	default:
+		return "unknown"
+	}
+}
@@ -37,9 +37,43 @@ const (
 	EXCEPT
 )
 
+/*
+SortItem is one key of an ORDER BY clause. Exactly one of ColumnName,
+FunctionCall or Ordinal identifies the output column to be sorted.
+*/
 type SortItem struct {
-	Order     SortOrderEnum
-	NullOrder NullOrderEnum
+	Order        SortOrderEnum
+	NullOrder    NullOrderEnum
+	ColumnName   string
+	FunctionCall *FunctionCallReference
+	Ordinal      int // 1-based position in the output, 0 if not used
+}
+
+func (si SortItem) IsDescending() bool {
+	return si.Order == DESCENDING
+}
+
+// NullsFirst follows the PostgreSQL default when no NULLS clause is given:
+// NULLs are larger than any other value.
+func (si SortItem) NullsFirst() bool {
+	switch si.NullOrder {
+	case FIRST:
+		return true
+	case LAST:
+		return false
+	default:
+		return si.IsDescending()
+	}
+}
+
+/*
+GroupingKey is one expression of a GROUP BY clause. Exactly one of ColumnName,
+FunctionCall or Ordinal identifies the key.
+*/
+type GroupingKey struct {
+	ColumnName   string
+	FunctionCall *FunctionCallReference // A time bucket like date_trunc('1H', Epoch)
+	Ordinal      int                    // 1-based position in the select list, 0 if not used
 }
 
 type BaseTypeEnum uint8
@@ -115,6 +149,8 @@ const (
 	LEFT_OUTER
 	RIGHT_OUTER
 	FULL_OUTER
+	CROSS
+	NATURAL
 )
 
 type StatementTypeEnum uint8
@@ -1,9 +1,9 @@
 package sqlparser
 
 import (
-	"bytes"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/alpacahq/marketstore/v4/catalog"
@@ -13,8 +13,9 @@ import (
 type ExecutableStatement struct {
 	MSTree
 	BaseSQLQueryTreeVisitor
-	nodeCursor *ExecutableStatement
-	pendingSP  *StaticPredicate
+	nodeCursor  *ExecutableStatement
+	pendingSP   *StaticPredicate
+	withQueries map[string]*SubqueryRelation // The WITH queries in scope, by name
 }
 
 func NewExecutableStatement(qtree ...IMSTree,
@@ -143,13 +144,13 @@ func (es *ExecutableStatement) VisitStatementParse(ctx *StatementParse) interfac
 }
 
 func QueryWalk(es *ExecutableStatement, i_ctx IMSTree) interface{} {
-	var ctx *QueryParse
-	var ok bool
-	if ctx, ok = i_ctx.(*QueryParse); !ok {
+	switch i_ctx.(type) {
+	case *QueryParse, *QueryNoWithParse: // A parenthesized query has no WITH clause
+	default:
 		return fmt.Errorf("unable to get *QueryParse")
 	}
 	var retval interface{}
-	retval = es.nodeCursor.Visit(ctx)
+	retval = es.nodeCursor.Visit(i_ctx)
 	if retval != nil {
 		for {
 			switch value := retval.(type) {
@@ -175,47 +176,98 @@ func QueryWalk(es *ExecutableStatement, i_ctx IMSTree) interface{} {
 }
 
 func (es *ExecutableStatement) VisitQueryParse(ctx *QueryParse) interface{} {
+	if ctx.with != nil {
+		if err, ok := es.nodeCursor.Visit(ctx.with).(error); ok {
+			return err
+		}
+	}
 	return ctx.queryNoWith
 }
 
 func (es *ExecutableStatement) VisitQueryNoWithParse(ctx *QueryNoWithParse) interface{} {
-	if ctx.sortItems != nil {
-		// TODO: Support ORDER BY
-		return fmt.Errorf("unsupported statement type: %s", "Query with ORDER BY")
-	}
-
 	sr := NewSelectRelation()
 	sr.Limit = ctx.limit
 
+	for _, item := range ctx.sortItems {
+		retval := es.nodeCursor.Visit(item)
+		switch value := retval.(type) {
+		case *SortItem:
+			sr.OrderBy = append(sr.OrderBy, *value)
+		case error:
+			return value
+		default:
+			return fmt.Errorf("unable to parse ORDER BY item")
+		}
+	}
+
 	es.nodeCursor.payload = sr // For retrieval of the dynamic type later
 	return ctx.queryTerm
 }
 
+func (es *ExecutableStatement) VisitSortItemParse(ctx *SortItemParse) interface{} {
+	/*
+		The sort key must identify an output column, either by name (or alias),
+		by repeating a function call from the select list, or by its 1-based
+		position in the output
+	*/
+	si := &SortItem{
+		Order:     ctx.sortOrdering,
+		NullOrder: ctx.nullOrdering,
+	}
+	retval := es.nodeCursor.Visit(ctx.expression)
+	switch value := retval.(type) {
+	case *ColumnReference:
+		si.ColumnName = value.GetName()
+	case *FunctionCallReference:
+		si.FunctionCall = value
+	case *Literal:
+		if value.Type != INTEGER_LITERAL {
+			return fmt.Errorf("ORDER BY position must be an integer")
+		}
+		//nolint:forcetypeassert // integer literals are always stored as int64
+		position := value.Value.(int64)
+		if position < 1 {
+			return fmt.Errorf("ORDER BY position %d is not in select list", position)
+		}
+		si.Ordinal = int(position)
+	case error:
+		return value
+	default:
+		return fmt.Errorf("unsupported ORDER BY expression")
+	}
+	return si
+}
+
 func (es *ExecutableStatement) VisitQueryTermParse(ctx *QueryTermParse) interface{} {
 	if ctx.queryPrimary == nil {
-		// TODO: Support JOIN
-		return fmt.Errorf("unsupported statement type: %s", "Join")
+		// TODO: Support set operations
+		return fmt.Errorf("unsupported statement type: %s", "UNION, INTERSECT or EXCEPT")
 	}
 	return ctx.queryPrimary
 }
 
 func (es *ExecutableStatement) VisitQueryPrimaryParse(ctx *QueryPrimaryParse) interface{} {
-	if ctx.querySpec == nil {
-		// TODO: Support TABLE, INLINE TABLE and SUBQUERY
-		return fmt.Errorf("unsupported statement type: %s", "TABLE, INLINE TABLE or SUBQUERY")
-	}
 	//nolint:forcetypeassert // hard to refactor for now
 	sr := es.nodeCursor.payload.(*SelectRelation)
-	sr.IsPrimary = true
 	if ctx.subquery != nil {
-		// fmt.Println("Visit Query Primary subquery")
-		sr.IsPrimary = false
-		node, err := NewExecutableStatement(ctx.subquery)
+		/*
+			A parenthesized query is read as a whole, the enclosing query
+			only adds its ORDER BY and LIMIT
+		*/
+		child, err := es.newSubqueryStatement(ctx.subquery)
 		if err != nil {
 			return err
 		}
-		es.AddChild(node)
+		sr.IsPrimary = false
+		sr.IsSelectAll = true
+		sr.Subquery = &SubqueryRelation{Statement: child}
+		return nil
+	}
+	if ctx.querySpec == nil {
+		// TODO: Support TABLE and INLINE TABLE
+		return fmt.Errorf("unsupported statement type: %s", "TABLE or INLINE TABLE")
 	}
+	sr.IsPrimary = true
 	return ctx.querySpec
 }
 
@@ -239,7 +291,7 @@ func (es *ExecutableStatement) VisitQuerySpecificationParse(ctx *QuerySpecificat
 	/*
 		Gather Select list
 	*/
-	for _, item := range ctx.selectItems {
+	for i, item := range ctx.selectItems {
 		//nolint:forcetypeassert // hard to refactor for now
 		cctx := item.(*SelectItemParse)
 		if cctx.IsSelectAll {
@@ -265,12 +317,36 @@ func (es *ExecutableStatement) VisitQuerySpecificationParse(ctx *QuerySpecificat
 			sr.SelectList = append(sr.SelectList, cr.Value)
 		case *FunctionCallReference:
 			ai := NewAliasedIdentifier()
+			if cr.Window != nil {
+				// Window functions are computed columns of the input rows
+				ai.PrimaryName = fmt.Sprintf("_col%d", i)
+				ai.WindowFunction = cr
+				if len(aliasName) != 0 {
+					ai.AddAlias(aliasName)
+				}
+				sr.SelectList = append(sr.SelectList, ai)
+				break
+			}
 			ai.AddFunctionCall(cr)
 			if len(aliasName) != 0 {
 				ai.Alias = aliasName
 				ai.IsAliased = true
 			}
 			sr.SelectList = append(sr.SelectList, ai)
+		case *ScalarExpression:
+			/*
+				Computed columns are named like the columns of Presto until
+				they are renamed to their alias
+			*/
+			ai := NewAliasedIdentifier()
+			ai.PrimaryName = fmt.Sprintf("_col%d", i)
+			ai.AddRuntimeExpression(cr)
+			if len(aliasName) != 0 {
+				ai.AddAlias(aliasName)
+			}
+			sr.SelectList = append(sr.SelectList, ai)
+		case error:
+			return cr
 		}
 	}
 	if sr.IsSelectAll && len(ctx.selectItems) > 1 {
@@ -280,16 +356,20 @@ func (es *ExecutableStatement) VisitQuerySpecificationParse(ctx *QuerySpecificat
 	/*
 		Gather table references
 	*/
+	if len(ctx.relations) > 1 {
+		return fmt.Errorf("unsupported option: Multiple relations in FROM, use JOIN ... ON to combine them")
+	}
 	for _, item := range ctx.relations {
 		i_tableName := es.nodeCursor.Visit(item)
 		// fmt.Println("Gathering relations: ", i_tableName, item, reflect.ValueOf(item).Type())
 		switch value := i_tableName.(type) {
 		case string:
 			sr.PrimaryTargetName = append(sr.PrimaryTargetName, value)
-		case *SelectRelation:
-			// fmt.Println("Gathered subquery")
+		case *SubqueryRelation:
 			sr.IsPrimary = false
 			sr.Subquery = value
+		case *JoinRelation:
+			sr.Join = value
 		case error:
 			return value
 		}
@@ -299,21 +379,105 @@ func (es *ExecutableStatement) VisitQuerySpecificationParse(ctx *QuerySpecificat
 		Retrieve conforming Epoch column predicates from the WHERE
 		expressions so they can be pushed down.
 
-		Conforming predicates are of the form:
+		Conforming predicates are terms of the top level AND of the form:
 		       Epoch [<,>,==,>=,<=] time_specification
 		   or:
 		       Epoch BETWEEN time_specification AND time_specification
 
+		All other terms are combined into a row predicate, evaluated on
+		the data after it is read
 	*/
 	if ctx.where != nil {
-		i_err := es.nodeCursor.Visit(ctx.where) // BooleanExpression
-		if err, ok := i_err.(error); ok {
+		for _, conjunct := range splitConjuncts(ctx.where) {
+			if es.addStaticPredicate(conjunct) {
+				continue
+			}
+			rp, err := es.newRowPredicate(conjunct)
+			if err != nil {
+				return err
+			}
+			sr.WherePredicate = andRowPredicates(sr.WherePredicate, rp)
+		}
+	}
+
+	/*
+		Gather the grouping keys and the HAVING condition, which is
+		evaluated on the grouped rows
+	*/
+	if ctx.groupBy != nil {
+		switch value := es.nodeCursor.Visit(ctx.groupBy).(type) {
+		case []*GroupingKey:
+			sr.GroupBy = value
+		case error:
+			return value
+		}
+	}
+	if ctx.having != nil {
+		having, err := es.newRowPredicate(ctx.having)
+		if err != nil {
 			return err
 		}
+		sr.Having = having
 	}
 	return nil
 }
 
+func (es *ExecutableStatement) VisitGroupByParse(ctx *GroupByParse) interface{} {
+	var keys []*GroupingKey
+	for _, element := range ctx.groupingElements {
+		switch value := es.nodeCursor.Visit(element).(type) {
+		case []*GroupingKey:
+			keys = append(keys, value...)
+		case error:
+			return value
+		default:
+			return fmt.Errorf("unable to parse GROUP BY element")
+		}
+	}
+	return keys
+}
+
+func (es *ExecutableStatement) VisitGroupingElementParse(ctx *GroupingElementParse) interface{} {
+	if ctx.groupingExp == nil {
+		// TODO: Support ROLLUP, CUBE and GROUPING SETS
+		return fmt.Errorf("unsupported grouping type: %s", "ROLLUP, CUBE or GROUPING SETS")
+	}
+	return es.nodeCursor.Visit(ctx.groupingExp)
+}
+
+func (es *ExecutableStatement) VisitGroupingExpressionsParse(ctx *GroupingExpressionsParse) interface{} {
+	/*
+		A grouping key is a column name (or select list alias), a time bucket
+		expression like date_trunc('1H', Epoch), or a 1-based position in the
+		select list
+	*/
+	keys := make([]*GroupingKey, 0, len(ctx.expressions))
+	for _, expr := range ctx.expressions {
+		gk := new(GroupingKey)
+		switch value := es.nodeCursor.Visit(expr).(type) {
+		case *ColumnReference:
+			gk.ColumnName = value.GetName()
+		case *FunctionCallReference:
+			gk.FunctionCall = value
+		case *Literal:
+			position, ok := value.Value.(int64)
+			if !ok || value.Type != INTEGER_LITERAL {
+				return fmt.Errorf("GROUP BY position must be an integer")
+			}
+			if position < 1 {
+				return fmt.Errorf("GROUP BY position %d is not in select list", position)
+			}
+			gk.Ordinal = int(position)
+		case error:
+			return value
+		default:
+			return fmt.Errorf("unsupported GROUP BY expression")
+		}
+		keys = append(keys, gk)
+	}
+	return keys
+}
+
 func (es *ExecutableStatement) VisitExpressionParse(ctx *ExpressionParse) interface{} {
 	/*
 		1 Child, one of ValueExpression or BooleanExpression
@@ -329,9 +493,15 @@ func (es *ExecutableStatement) VisitValueExpressionParse(ctx *ValueExpressionPar
 	switch cctx := child.(type) {
 	case *PrimaryExpressionParse: // Primary Expression
 		return es.nodeCursor.Visit(cctx)
+	case *ArithmeticBinaryParse, *ArithmeticUnaryParse:
+		se, err := es.newScalarExpression(cctx)
+		if err != nil {
+			return err
+		}
+		return se
 	default:
-		// TODO: Support non primary expressions
-		return fmt.Errorf("only Primary Expressions supported")
+		// TODO: Support AT TIME ZONE and concatenation
+		return fmt.Errorf("only Primary and arithmetic Expressions supported")
 	}
 }
 
@@ -351,14 +521,33 @@ func (es *ExecutableStatement) VisitPrimaryExpressionParse(ctx *PrimaryExpressio
 		default:
 			return fmt.Errorf("non string returned as column reference")
 		}
+	case CAST:
+		se, err := es.newScalarExpression(ctx)
+		if err != nil {
+			return err
+		}
+		return se
 	case FUNCTION_CALL:
+		if fcp, ok := ctx.GetChild(0).(*FunctionCallParse); ok {
+			if _, isScalar := es.scalarFunctionName(fcp); isScalar {
+				se, err := es.newScalarExpression(ctx)
+				if err != nil {
+					return err
+				}
+				return se
+			}
+		}
 		retval := es.nodeCursor.Visit(ctx.GetChild(0))
 		switch value := retval.(type) {
 		case *FunctionCallReference:
 			return value
+		case error:
+			return value
 		default:
 			return fmt.Errorf("unexpected non FunctionCall returned")
 		}
+	case DEREFERENCE:
+		return es.nodeCursor.Visit(ctx.GetChild(0))
 	case PARENTHESIZED_EXPRESSION:
 		return es.nodeCursor.Visit(ctx.GetChild(0))
 	default:
@@ -368,11 +557,34 @@ func (es *ExecutableStatement) VisitPrimaryExpressionParse(ctx *PrimaryExpressio
 	}
 }
 
+func (es *ExecutableStatement) VisitDereferenceParse(ctx *DereferenceParse) interface{} {
+	/*
+		A qualified column name like t.Price, naming a column of a joined
+		relation by its prefix
+	*/
+	base, ok := es.nodeCursor.Visit(ctx.base).(*ColumnReference)
+	if !ok {
+		return fmt.Errorf("unsupported dereference, only relation.column names are supported")
+	}
+	fieldName, ok := es.nodeCursor.Visit(ctx.fieldName).(string)
+	if !ok {
+		return fmt.Errorf("non string returned as field name")
+	}
+	return NewColumnReference(base.GetName() + "." + fieldName)
+}
+
 func (es *ExecutableStatement) VisitIDParse(ctx *IDParse) interface{} {
 	return ctx.name
 }
 
 func (es *ExecutableStatement) VisitRelationParse(ctx *RelationParse) interface{} {
+	if ctx.sampled == nil {
+		jr, err := es.newJoinRelation(ctx)
+		if err != nil {
+			return err
+		}
+		return jr
+	}
 	return es.nodeCursor.Visit(ctx.sampled)
 }
 
@@ -381,64 +593,54 @@ func (es *ExecutableStatement) VisitSampledRelationParse(ctx *SampledRelationPar
 }
 
 func (es *ExecutableStatement) VisitAliasedRelationParse(ctx *AliasedRelationParse) interface{} {
+	relation := es.nodeCursor.Visit(ctx.relationPrimary)
 	if ctx.hasAliases {
-		// TODO: Support table aliases
-		return fmt.Errorf("table Aliases not supported")
+		sq, ok := relation.(*SubqueryRelation)
+		if !ok {
+			// TODO: Support column aliases for tables
+			return fmt.Errorf("table Aliases not supported")
+		}
+		// The aliases only apply to this use of the query
+		sq = &SubqueryRelation{Name: sq.Name, Statement: sq.Statement}
+		sq.ColumnAliases = es.columnAliases(ctx.aliases)
+		return sq
 	}
-	return es.nodeCursor.Visit(ctx.relationPrimary)
+	return relation
 }
 
 func (es *ExecutableStatement) VisitRelationPrimaryParse(ctx *RelationPrimaryParse) interface{} {
 	switch {
-	case ctx.IsTableName || ctx.IsRelation:
+	case ctx.IsTableName:
+		name := es.nodeCursor.Visit(ctx.GetChild(0))
+		if value, ok := name.(string); ok && es.nodeCursor.withQueries[value] != nil {
+			return es.nodeCursor.withQueries[value] // A WITH query is used like a table
+		}
+		return name
+	case ctx.IsRelation:
 		return es.nodeCursor.Visit(ctx.GetChild(0))
 	case ctx.IsSubquery:
-		//fmt.Println("Visit IsSubquery")
-		/*
-			childNode, _ := NewExecutableStatement()
-			es.AddChild(childNode)
-			retval := QueryWalk(childNode, ctx.GetChild(0).(*QueryParse))
-			if err, ok := retval.(error); ok {
-				return err
-			}
-			fmt.Println("Retval: ", retval)
-			if sr, ok := childNode.payload.(*SelectRelation); ok {
-				return sr
-			} else {
-				return fmt.Errorf("Unable to load subquery")
-			}
-		*/
-		if sr, ok := es.payload.(*SelectRelation); ok {
-			newNode, _ := NewExecutableStatement()
-			retval := QueryWalk(newNode, ctx.GetChild(0).(*QueryParse))
-			if err, ok := retval.(error); ok {
-				return err
-			}
-			// Get the subquery relation
-			if srSub, ok := newNode.payload.(*SelectRelation); ok {
-				sr.Subquery = srSub
-				return srSub
-			}
+		child, err := es.newSubqueryStatement(ctx.GetChild(0))
+		if err != nil {
+			return err
 		}
+		return &SubqueryRelation{Statement: child}
 	default:
 		return fmt.Errorf("unsupported Primary Relation type")
 	}
-	return nil
 }
 
 func (es *ExecutableStatement) VisitQualifiedNameParse(ctx *QualifiedNameParse) interface{} {
-	var buffer bytes.Buffer
-	numberElements := ctx.GetChildCount()
-	for i, child := range ctx.GetChildren() {
+	var names []string
+	for _, child := range ctx.GetChildren() {
 		id := es.nodeCursor.Visit(child)
 		//nolint:forcetypeassert // hard to refactor for now
 		name := id.(string)
-		buffer.WriteString(name)
-		if i < numberElements-1 {
-			buffer.WriteString("/")
+		// the dots between the parts have no name
+		if name != "" {
+			names = append(names, name)
 		}
 	}
-	return buffer.String()
+	return strings.Join(names, "/")
 }
 
 func (es *ExecutableStatement) VisitBooleanExpressionParse(ctx *BooleanExpressionParse) interface{} {
@@ -450,6 +652,9 @@ func (es *ExecutableStatement) VisitBooleanExpressionParse(ctx *BooleanExpressio
 	if err != nil {
 		return err
 	}
+	if ctx.IsNot {
+		return fmt.Errorf("NOT is not supported in static predicates")
+	}
 
 	var done bool
 	doneRight := (ctx.right == nil) // No right side
@@ -502,6 +707,34 @@ func (es *ExecutableStatement) VisitBooleanExpressionParse(ctx *BooleanExpressio
 	return nil
 }
 
+/*
+addStaticPredicate merges a conforming Epoch predicate into the static
+predicates, it returns false for anything that must be evaluated per row
+*/
+func (es *ExecutableStatement) addStaticPredicate(tree IMSTree) bool {
+	ctx, ok := tree.(*BooleanExpressionParse)
+	if !ok || ctx.IsNot || ctx.IsLiteral || ctx.right != nil || ctx.predicate == nil {
+		return false
+	}
+	if cr, ok := es.nodeCursor.Visit(ctx.left).(*ColumnReference); !ok || cr.GetName() != "Epoch" {
+		return false
+	}
+	switch predicate := ctx.predicate.GetChild(0).(type) {
+	case *ComparisonParse:
+		if predicate.comparisonOperator == io.NEQ {
+			return false
+		}
+	case *BetweenParse:
+		if predicate.IsNot {
+			return false
+		}
+	default:
+		return false
+	}
+	// A bound that isn't a time literal is left for the row predicate
+	return es.nodeCursor.Visit(ctx) == nil
+}
+
 func (es *ExecutableStatement) VisitPredicateParse(ctx *PredicateParse) interface{} {
 	node := ctx.GetChild(0)
 	switch node.(type) {
@@ -510,7 +743,7 @@ func (es *ExecutableStatement) VisitPredicateParse(ctx *PredicateParse) interfac
 	case *QuantifiedComparisonParse:
 		return fmt.Errorf("quantified Comparisons (ALL/ANY/SOME) not supported")
 	case *InListParse, *InSubqueryParse, *LikeParse, *NullPredicateParse, *DistinctFromParse:
-		// TODO: Implement dynamic predicates (and inlist)
+		// These are evaluated per row, see RowPredicate
 		return fmt.Errorf("unsupported predicate type, only static types are supported")
 	}
 	return nil
@@ -580,11 +813,6 @@ func (es *ExecutableStatement) VisitFunctionCallParse(ctx *FunctionCallParse) in
 	}
 
 	var args []interface{}
-	if ctx.hasAsterisk {
-		fc := NewFunctionCallReference(name, args)
-		fc.IsAsterisk = true
-		return fc
-	}
 	for _, expr := range ctx.expressionList {
 		i_value := es.nodeCursor.Visit(expr)
 		switch value := i_value.(type) {
@@ -596,7 +824,17 @@ func (es *ExecutableStatement) VisitFunctionCallParse(ctx *FunctionCallParse) in
 			return fmt.Errorf("error parsing column ref")
 		}
 	}
-	return NewFunctionCallReference(name, args)
+	fc := NewFunctionCallReference(name, args)
+	fc.IsAsterisk = ctx.hasAsterisk
+	if ctx.over != nil {
+		//nolint:forcetypeassert // hard to refactor for now
+		window, err := es.newWindowFrame(ctx.over.(*OverParse))
+		if err != nil {
+			return err
+		}
+		fc.Window = window
+	}
+	return fc
 }
 
 /*
@@ -606,6 +844,7 @@ type FunctionCallReference struct {
 	Name       string
 	IsAsterisk bool
 	Args       []interface{}
+	Window     *WindowFrame // Set for a window function, called with OVER
 }
 
 func NewFunctionCallReference(name string, args []interface{}) *FunctionCallReference {
@@ -615,6 +854,54 @@ func NewFunctionCallReference(name string, args []interface{}) *FunctionCallRefe
 	return fc
 }
 
+// Equal returns true when both calls name the same function with the same arguments.
+func (fc *FunctionCallReference) Equal(other *FunctionCallReference) bool {
+	if other == nil || !strings.EqualFold(fc.Name, other.Name) ||
+		fc.IsAsterisk != other.IsAsterisk || len(fc.Args) != len(other.Args) {
+		return false
+	}
+	if (fc.Window == nil) != (other.Window == nil) || fc.Window != nil && fc.Window.String() != other.Window.String() {
+		return false
+	}
+	for i, i_arg := range fc.Args {
+		switch arg := i_arg.(type) {
+		case *AliasedIdentifier:
+			otherArg, ok := other.Args[i].(*AliasedIdentifier)
+			if !ok || arg.PrimaryName != otherArg.PrimaryName {
+				return false
+			}
+		case *Literal:
+			otherArg, ok := other.Args[i].(*Literal)
+			if !ok || arg.Value != otherArg.Value {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (fc *FunctionCallReference) String() string {
+	var args []string
+	if fc.IsAsterisk {
+		args = append(args, "*")
+	}
+	for _, iArg := range fc.Args {
+		switch arg := iArg.(type) {
+		case *AliasedIdentifier:
+			args = append(args, arg.PrimaryName)
+		case *Literal:
+			args = append(args, fmt.Sprint(arg.Value))
+		}
+	}
+	out := fmt.Sprintf("%s(%s)", fc.Name, strings.Join(args, ", "))
+	if fc.Window != nil {
+		out += " OVER (" + fc.Window.String() + ")"
+	}
+	return out
+}
+
 func (fc *FunctionCallReference) GetIDs() (idList []string) {
 	for _, i_arg := range fc.Args {
 		switch arg := i_arg.(type) {
@@ -0,0 +1,407 @@
+package sqlparser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/utils"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+const dateTruncFunction = "date_trunc"
+
+/*
+groupingColumn is a GROUP BY key resolved against the input of the relation.
+*/
+type groupingColumn struct {
+	name       string                 // Source column
+	duration   *utils.CandleDuration  // Set when Epoch is truncated into time buckets
+	call       *FunctionCallReference // The date_trunc call of a time bucket
+	fromKey    bool                   // The Symbol of the TimeBucketKey, not a column
+	selectItem *AliasedIdentifier     // The select list item naming this key, if any
+}
+
+/*
+newTimeBucketGrouping validates a time bucket expression of the form
+date_trunc('1H', Epoch). Buckets are aligned the same way as the candlers.
+*/
+func newTimeBucketGrouping(fc *FunctionCallReference) (gc *groupingColumn, err error) {
+	if !strings.EqualFold(fc.Name, dateTruncFunction) {
+		return nil, fmt.Errorf("function %s is not allowed in GROUP BY", fc.Name)
+	}
+	literals, ids := fc.GetLiterals(), fc.GetIDs()
+	if len(fc.Args) != 2 || len(literals) != 1 || len(ids) != 1 {
+		return nil, fmt.Errorf("%s needs a timeframe and a column, e.g. %s('1H', Epoch)",
+			dateTruncFunction, dateTruncFunction)
+	}
+	if ids[0] != "Epoch" {
+		return nil, fmt.Errorf("%s is only supported on the Epoch column", dateTruncFunction)
+	}
+	timeframe, ok := literals[0].Value.(string)
+	if !ok || literals[0].Type != STRING_LITERAL {
+		return nil, fmt.Errorf("timeframe of %s must be a string", dateTruncFunction)
+	}
+	timeframe = timeframe[1 : len(timeframe)-1] // Strip the quotes
+	cd := utils.CandleDurationFromString(timeframe)
+	if cd == nil {
+		return nil, fmt.Errorf("invalid timeframe %s for %s", timeframe, dateTruncFunction)
+	}
+	return &groupingColumn{name: "Epoch", duration: cd, call: fc}, nil
+}
+
+/*
+newColumnGrouping returns nil if the name is neither a source column nor the
+Symbol of the bucket being read.
+*/
+func newColumnGrouping(name string, dsv []io.DataShape, key *io.TimeBucketKey) *groupingColumn {
+	if name == "Epoch" {
+		return &groupingColumn{name: name}
+	}
+	for _, ds := range dsv {
+		if ds.Name == name {
+			return &groupingColumn{name: name}
+		}
+	}
+	if name == "Symbol" && key != nil {
+		return &groupingColumn{name: name, fromKey: true}
+	}
+	return nil
+}
+
+func newSelectItemGrouping(sl *AliasedIdentifier, dsv []io.DataShape, key *io.TimeBucketKey,
+) (gc *groupingColumn, err error) {
+	if sl.RuntimeExpression != nil || sl.WindowFunction != nil {
+		return nil, fmt.Errorf("GROUP BY on the computed column %s is not supported", sl.computedName())
+	}
+	if sl.IsFunctionCall {
+		gc, err = newTimeBucketGrouping(sl.FunctionCall)
+		if err != nil {
+			return nil, err
+		}
+	} else if gc = newColumnGrouping(sl.PrimaryName, dsv, key); gc == nil {
+		return nil, fmt.Errorf("GROUP BY column %s not found", sl.PrimaryName)
+	}
+	gc.selectItem = sl
+	return gc, nil
+}
+
+// resolveGrouping maps the GROUP BY clause to columns of the input.
+func (sr *SelectRelation) resolveGrouping(dsv []io.DataShape, key *io.TimeBucketKey,
+) (grouping []*groupingColumn, err error) {
+	if sr.IsSelectAll {
+		return nil, fmt.Errorf("SELECT * can not be used with GROUP BY")
+	}
+	for _, gk := range sr.GroupBy {
+		var gc *groupingColumn
+		switch {
+		case gk.Ordinal != 0:
+			if gk.Ordinal > len(sr.SelectList) {
+				return nil, fmt.Errorf("GROUP BY position %d is not in select list", gk.Ordinal)
+			}
+			gc, err = newSelectItemGrouping(sr.SelectList[gk.Ordinal-1], dsv, key)
+		case gk.FunctionCall != nil:
+			gc, err = newTimeBucketGrouping(gk.FunctionCall)
+		default:
+			// Input columns take precedence over select list aliases
+			gc = newColumnGrouping(gk.ColumnName, dsv, key)
+			for _, sl := range sr.SelectList {
+				if gc == nil && sl.IsAliased && sl.Alias == gk.ColumnName {
+					gc, err = newSelectItemGrouping(sl, dsv, key)
+				}
+			}
+			if gc == nil && err == nil {
+				err = fmt.Errorf("GROUP BY column %s not found", gk.ColumnName)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+		grouping = append(grouping, gc)
+	}
+	return grouping, nil
+}
+
+/*
+keyValues returns the grouping value of every input row. The Symbol of the
+TimeBucketKey is returned as a single value shared by all rows.
+*/
+func (gc *groupingColumn) keyValues(cs *io.ColumnSeries, key *io.TimeBucketKey,
+) (values interface{}, shared bool, err error) {
+	switch {
+	case gc.fromKey:
+		return [][16]rune{toString16(key.GetItemInCategory("Symbol"))}, true, nil
+	case gc.duration != nil:
+		epochs := cs.GetEpoch()
+		if epochs == nil {
+			return nil, false, fmt.Errorf("unable to group by time without an Epoch column")
+		}
+		buckets := make([]int64, len(epochs))
+		var start, end int64 // The bucket of the previous row, rows are mostly in time order
+		for i, epoch := range epochs {
+			if epoch < start || epoch >= end {
+				t := io.ToSystemTimezone(time.Unix(epoch, 0))
+				start, end = gc.duration.Truncate(t).Unix(), gc.duration.Ceil(t).Unix()
+			}
+			buckets[i] = start
+		}
+		return buckets, false, nil
+	default:
+		values = cs.GetColumn(gc.name)
+		if values == nil {
+			return nil, false, fmt.Errorf("GROUP BY column %s not found", gc.name)
+		}
+		return values, false, nil
+	}
+}
+
+func (gc *groupingColumn) matches(sl *AliasedIdentifier) bool {
+	switch {
+	case gc.selectItem == sl:
+		return true
+	case sl.IsFunctionCall:
+		return gc.call != nil && gc.call.Equal(sl.FunctionCall)
+	default:
+		return gc.duration == nil && gc.name == sl.PrimaryName
+	}
+}
+
+type groupID struct {
+	parent int
+	value  interface{}
+}
+
+/*
+partitionRows splits the row positions into groups sharing the same value for
+every key. Groups are numbered in the order of their first row.
+*/
+func partitionRows(length int, keyValues []interface{}, shared []bool) (groups [][]int) {
+	ids := make([]int, length)
+	for k, values := range keyValues {
+		if shared[k] {
+			continue
+		}
+		col := reflect.ValueOf(values)
+		lookup := make(map[groupID]int)
+		for i := range ids {
+			gid := groupID{parent: ids[i], value: col.Index(i).Interface()}
+			id, ok := lookup[gid]
+			if !ok {
+				id = len(lookup)
+				lookup[gid] = id
+			}
+			ids[i] = id
+		}
+	}
+	for i, id := range ids {
+		if id == len(groups) {
+			groups = append(groups, nil)
+		}
+		groups[id] = append(groups[id], i)
+	}
+	return groups
+}
+
+// gatherRows returns the elements of a column found at the given rows.
+func gatherRows(values interface{}, shared bool, rows []int) interface{} {
+	col := reflect.ValueOf(values)
+	out := reflect.MakeSlice(col.Type(), len(rows), len(rows))
+	for j, i := range rows {
+		if shared {
+			i = 0
+		}
+		out.Index(j).Set(col.Index(i))
+	}
+	return out.Interface()
+}
+
+func toString16(s string) (r [16]rune) {
+	copy(r[:], []rune(s))
+	return r
+}
+
+/*
+materializeGroups evaluates the select list once for every group of rows
+sharing the same GROUP BY keys. Plain columns of the select list must be
+grouping keys and functions must be aggregates returning a single row.
+*/
+func (sr *SelectRelation) materializeGroups(aggRunner *AggRunner, cs *io.ColumnSeries,
+	key *io.TimeBucketKey, grouping []*groupingColumn, functionOutputs map[*AliasedIdentifier][]string,
+) (out *io.ColumnSeries, err error) {
+	keyValues := make([]interface{}, len(grouping))
+	shared := make([]bool, len(grouping))
+	for i, gc := range grouping {
+		keyValues[i], shared[i], err = gc.keyValues(cs, key)
+		if err != nil {
+			return nil, err
+		}
+	}
+	groups := partitionRows(cs.Len(), keyValues, shared)
+	firstRows := make([]int, len(groups))
+	for i, rows := range groups {
+		firstRows[i] = rows[0]
+	}
+
+	/*
+		The Epoch of each group is the start of its time bucket, or the time of
+		the query like for an ungrouped aggregate
+	*/
+	out = io.NewColumnSeries()
+	var epochs []int64
+	for i, gc := range grouping {
+		if gc.duration != nil {
+			//nolint:forcetypeassert // time buckets are always int64
+			epochs = gatherRows(keyValues[i], shared[i], firstRows).([]int64)
+			break
+		}
+	}
+	if epochs == nil {
+		tNow := time.Now().UTC().Unix()
+		epochs = make([]int64, len(groups))
+		for i := range epochs {
+			epochs[i] = tNow
+		}
+	}
+	out.AddColumn("Epoch", epochs)
+
+	var tbk io.TimeBucketKey
+	if key != nil {
+		tbk = *key
+	}
+	var groupSeries []*io.ColumnSeries
+	for _, sl := range sr.SelectList {
+		if !sl.IsFunctionCall && sl.PrimaryName == "Epoch" {
+			continue // Already in the output
+		}
+		if sl.RuntimeExpression != nil || sl.WindowFunction != nil {
+			return nil, fmt.Errorf("computed column %s is not supported with GROUP BY", sl.computedName())
+		}
+		keyIndex := -1
+		for i, gc := range grouping {
+			if gc.matches(sl) {
+				keyIndex = i
+				break
+			}
+		}
+		if keyIndex >= 0 {
+			name := sl.PrimaryName
+			switch {
+			case sl.IsAliased:
+				name = sl.Alias
+			case sl.IsFunctionCall:
+				name = sl.FunctionCall.Name
+			}
+			out.AddColumn(name, gatherRows(keyValues[keyIndex], shared[keyIndex], firstRows))
+			continue
+		}
+		if !sl.IsFunctionCall {
+			return nil, fmt.Errorf(
+				"column %s must appear in the GROUP BY clause or be used in an aggregate function",
+				sl.PrimaryName)
+		}
+
+		if groupSeries == nil {
+			groupSeries, err = splitRows(cs, groups)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result, err := aggregateGroups(aggRunner, sl.FunctionCall, tbk, groupSeries)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range result.GetColumnNames() {
+			outname := name
+			if sl.IsAliased {
+				outname = sl.Alias
+			}
+			outname = out.AddColumn(outname, result.GetColumn(name))
+			out.CopyScale(outname, result, name)
+			functionOutputs[sl] = append(functionOutputs[sl], outname)
+		}
+	}
+	return out, nil
+}
+
+func splitRows(cs *io.ColumnSeries, groups [][]int) (groupSeries []*io.ColumnSeries, err error) {
+	groupSeries = make([]*io.ColumnSeries, len(groups))
+	for i, rows := range groups {
+		group := io.NewColumnSeries()
+		for _, name := range cs.GetColumnNames() {
+			group.AddColumn(name, cs.GetColumn(name))
+			group.CopyScale(name, cs, name)
+		}
+		if err = group.RestrictViaIndex(rows); err != nil {
+			return nil, err
+		}
+		groupSeries[i] = group
+	}
+	return groupSeries, nil
+}
+
+/*
+aggregateGroups runs a fresh instance of the aggregate over every group and
+concatenates the single row results, without their Epoch.
+*/
+func aggregateGroups(aggRunner *AggRunner, fc *FunctionCallReference, tbk io.TimeBucketKey,
+	groupSeries []*io.ColumnSeries) (*io.ColumnSeries, error) {
+	agg, argMap, initArgList, err := prepareFunction(aggRunner, fc)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	columns := make(map[string]reflect.Value)
+	var first *io.ColumnSeries
+	for _, group := range groupSeries {
+		aggfunc, err := agg.New(argMap, initArgList)
+		if err != nil {
+			return nil, fmt.Errorf("init aggfunc: %w", err)
+		}
+		result, err := aggfunc.Accum(tbk, argMap, group)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil || result.Len() != 1 {
+			return nil, fmt.Errorf("%s is not an aggregate, it must return one row per group", fc.Name)
+		}
+		if first == nil {
+			first = result
+		}
+		for _, name := range result.GetColumnNames() {
+			if name == "Epoch" {
+				continue
+			}
+			col := reflect.ValueOf(result.GetColumn(name))
+			current, ok := columns[name]
+			if !ok {
+				names = append(names, name)
+				current = reflect.MakeSlice(col.Type(), 0, len(groupSeries))
+			} else if current.Type() != col.Type() {
+				return nil, fmt.Errorf("%s returned %s with different types", fc.Name, name)
+			}
+			columns[name] = reflect.AppendSlice(current, col)
+		}
+	}
+	out := io.NewColumnSeries()
+	for _, name := range names {
+		out.AddColumn(name, columns[name].Interface())
+		out.CopyScale(name, first, name)
+	}
+	return out, nil
+}
+
+// applyHaving removes the rows of the results not satisfying the HAVING clause.
+func (sr *SelectRelation) applyHaving(cs *io.ColumnSeries, functionOutputs map[*AliasedIdentifier][]string,
+) error {
+	match, err := sr.Having.Evaluate(cs, func(operand *RowOperand) (string, error) {
+		return sr.resolveOutputColumn(cs, "HAVING", operand.ColumnName, operand.FunctionCall, functionOutputs)
+	})
+	if err != nil {
+		return err
+	}
+	for i := range match {
+		match[i] = !match[i] // true removes the row
+	}
+	return cs.RestrictViaBitmap(match)
+}
@@ -0,0 +1,361 @@
+package sqlparser
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/executor"
+	"github.com/alpacahq/marketstore/v4/planner"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+JoinRelation is a join between two time buckets on Epoch. An equi-join pairs
+the rows with the same time, an as-of join pairs every row of the left bucket
+with the most recent row of the right bucket at (or strictly before) its time:
+
+	SELECT t.Price, q.BidPrice FROM `AAPL/1Sec/TRADE` t
+	LEFT JOIN `AAPL/1Sec/QUOTE` q ON t.Epoch >= q.Epoch
+
+The result has the Epoch of the left bucket, followed by the columns of both
+buckets prefixed with their relation name, e.g. t.Price and q.Epoch.
+*/
+type JoinRelation struct {
+	Left, Right *JoinTarget
+	Type        JoinTypeEnum              // INNER or LEFT_OUTER
+	Condition   io.ComparisonOperatorEnum // EQ for an equi-join, GTE or GT for an as-of join
+}
+
+/*
+JoinTarget is one of the buckets of a join. Its columns are prefixed by the
+table alias, or by the attribute group of the bucket when it has no alias.
+*/
+type JoinTarget struct {
+	Key   *io.TimeBucketKey
+	Alias string
+}
+
+func (jt *JoinTarget) Prefix() string {
+	if len(jt.Alias) != 0 {
+		return jt.Alias
+	}
+	return jt.Key.GetItemInCategory("AttributeGroup")
+}
+
+func (es *ExecutableStatement) newJoinRelation(ctx *RelationParse) (jr *JoinRelation, err error) {
+	switch ctx.joinType {
+	case INNER, LEFT_OUTER:
+	default:
+		return nil, fmt.Errorf("unsupported join type, only INNER and LEFT joins are supported")
+	}
+	jr = &JoinRelation{Type: ctx.joinType}
+	if jr.Left, err = es.newJoinTarget(ctx.left); err != nil {
+		return nil, err
+	}
+	if jr.Right, err = es.newJoinTarget(ctx.right); err != nil {
+		return nil, err
+	}
+	if jr.Left.Prefix() == jr.Right.Prefix() {
+		return nil, fmt.Errorf("both sides of the join are named %s, use table aliases to tell them apart",
+			jr.Left.Prefix())
+	}
+
+	criteria, ok := ctx.criteria.(*JoinCriteriaParse)
+	if !ok {
+		return nil, fmt.Errorf("a JOIN needs an ON or USING condition on Epoch")
+	}
+	if err = es.setJoinCondition(jr, criteria); err != nil {
+		return nil, err
+	}
+	return jr, nil
+}
+
+func (es *ExecutableStatement) newJoinTarget(tree IMSTree) (jt *JoinTarget, err error) {
+	relation, ok := tree.(*RelationParse)
+	if !ok || relation.sampled == nil {
+		return nil, fmt.Errorf("unsupported join, only joins between two tables are supported")
+	}
+	//nolint:forcetypeassert // a sampled relation always wraps an aliased relation
+	aliased := relation.sampled.(*SampledRelationParse).aliasedRelation.(*AliasedRelationParse)
+	if aliased.hasAliases {
+		return nil, fmt.Errorf("column aliases not supported for joined tables")
+	}
+	name, ok := es.nodeCursor.Visit(aliased.relationPrimary).(string)
+	if !ok {
+		return nil, fmt.Errorf("unsupported join, only joins between two tables are supported")
+	}
+	jt = new(JoinTarget)
+	jt.Key = io.NewTimeBucketKey(name, "Symbol/Timeframe/AttributeGroup")
+	if jt.Key == nil {
+		return nil, fmt.Errorf("table name must match \"one/two/three\" for three directory levels")
+	}
+	if aliased.hasID {
+		//nolint:forcetypeassert // identifiers are always strings
+		jt.Alias = es.nodeCursor.Visit(aliased.identifier).(string)
+	}
+	return jt, nil
+}
+
+/*
+setJoinCondition accepts USING (Epoch) or a comparison between the Epoch of
+both relations. The condition is stored as seen from the left relation, so
+q.Epoch <= t.Epoch is the same as-of join as t.Epoch >= q.Epoch.
+*/
+func (es *ExecutableStatement) setJoinCondition(jr *JoinRelation, criteria *JoinCriteriaParse) (err error) {
+	if criteria.onExpression == nil {
+		for _, id := range criteria.identifiers {
+			if name, _ := es.nodeCursor.Visit(id).(string); name != "Epoch" {
+				return fmt.Errorf("unsupported join column %s, joins are only supported on Epoch", name)
+			}
+		}
+		jr.Condition = io.EQ
+		return nil
+	}
+
+	rp, err := es.newRowPredicate(criteria.onExpression)
+	if err != nil {
+		return err
+	}
+	if rp.kind != comparisonPredicate || rp.IsNot ||
+		len(rp.Operand.ColumnName) == 0 || len(rp.Argument.ColumnName) == 0 {
+		return fmt.Errorf("unsupported join condition, it must compare the Epoch of both relations")
+	}
+	left, err := jr.targetOf(rp.Operand.ColumnName)
+	if err != nil {
+		return err
+	}
+	right, err := jr.targetOf(rp.Argument.ColumnName)
+	if err != nil {
+		return err
+	}
+	condition := rp.Comparison
+	switch {
+	case left == jr.Left && right == jr.Right:
+	case left == jr.Right && right == jr.Left:
+		switch condition {
+		case io.LT:
+			condition = io.GT
+		case io.LTE:
+			condition = io.GTE
+		case io.GT:
+			condition = io.LT
+		case io.GTE:
+			condition = io.LTE
+		}
+	default:
+		return fmt.Errorf("unsupported join condition, it must compare the Epoch of both relations")
+	}
+	switch condition {
+	case io.EQ, io.GTE, io.GT:
+		jr.Condition = condition
+	default:
+		return fmt.Errorf("unsupported join condition %s, use = for an equi-join or >= for an as-of join",
+			rp.Comparison)
+	}
+	return nil
+}
+
+// targetOf returns the side of the join named by a qualified Epoch column.
+func (jr *JoinRelation) targetOf(columnName string) (jt *JoinTarget, err error) {
+	parts := strings.SplitN(columnName, ".", 2)
+	if len(parts) != 2 || parts[1] != "Epoch" {
+		return nil, fmt.Errorf("join column %s must be a qualified Epoch, like %s.Epoch",
+			columnName, jr.Left.Prefix())
+	}
+	for _, jt := range []*JoinTarget{jr.Left, jr.Right} {
+		if jt.Prefix() == parts[0] {
+			return jt, nil
+		}
+	}
+	return nil, fmt.Errorf("relation %s is not part of the join", parts[0])
+}
+
+/*
+Materialize reads both buckets within the time range and joins them. An as-of
+join also needs the last row of the right bucket before the range, which
+applies to the first rows of the left bucket.
+*/
+func (jr *JoinRelation) Materialize(catDir *catalog.Directory, start, end *time.Time,
+) (cs *io.ColumnSeries, err error) {
+	left, err := readTimeBucket(catDir, jr.Left.Key, start, end, 0)
+	if err != nil {
+		return nil, err
+	}
+	right, err := readTimeBucket(catDir, jr.Right.Key, start, end, 0)
+	if err != nil {
+		return nil, err
+	}
+	if jr.Condition != io.EQ && start != nil {
+		before := start.Add(-time.Nanosecond)
+		previous, err := readTimeBucket(catDir, jr.Right.Key, nil, &before, 1)
+		if err != nil {
+			return nil, err
+		}
+		right = appendColumnSeries(previous, right)
+	}
+
+	matches, err := jr.matchRows(left, right)
+	if err != nil {
+		return nil, err
+	}
+	var leftRows, rightRows []int
+	for i, j := range matches {
+		if j < 0 && jr.Type == INNER {
+			continue
+		}
+		leftRows = append(leftRows, i)
+		rightRows = append(rightRows, j)
+	}
+
+	cs = io.NewColumnSeries()
+	cs.AddColumn("Epoch", gatherJoinRows(left.GetColumn("Epoch"), leftRows))
+	if nanoseconds := left.GetColumn("Nanoseconds"); nanoseconds != nil {
+		cs.AddColumn("Nanoseconds", gatherJoinRows(nanoseconds, leftRows))
+	}
+	for _, name := range left.GetColumnNames() {
+		outname := cs.AddColumn(jr.Left.Prefix()+"."+name, gatherJoinRows(left.GetColumn(name), leftRows))
+		cs.CopyScale(outname, left, name)
+	}
+	for _, name := range right.GetColumnNames() {
+		column := gatherJoinRows(right.GetColumn(name), rightRows)
+		if nulls, ok := column.([]bool); ok && right.IsNullColumn(name) {
+			// the columns of a nullable bucket are NULL in the unmatched rows
+			for i, row := range rightRows {
+				nulls[i] = nulls[i] || row < 0
+			}
+		}
+		outname := cs.AddColumn(jr.Right.Prefix()+"."+name, column)
+		cs.CopyScale(outname, right, name)
+	}
+	return cs, nil
+}
+
+/*
+matchRows returns the row of the right series that goes with each row of the
+left series, or -1 when there is none. Both series are in time order.
+*/
+func (jr *JoinRelation) matchRows(left, right *io.ColumnSeries) (matches []int, err error) {
+	leftTimes, err := left.GetTime()
+	if err != nil {
+		return nil, err
+	}
+	rightTimes, err := right.GetTime()
+	if err != nil {
+		return nil, err
+	}
+	matches = make([]int, len(leftTimes))
+	j := -1 // The last right row at or before the current left row
+	for i, t := range leftTimes {
+		for j+1 < len(rightTimes) && !rightTimes[j+1].After(t) {
+			j++
+		}
+		match := j
+		switch jr.Condition {
+		case io.EQ:
+			if j < 0 || !rightTimes[j].Equal(t) {
+				match = -1
+			}
+		case io.GT:
+			for match >= 0 && rightTimes[match].Equal(t) {
+				match--
+			}
+		}
+		matches[i] = match
+	}
+	return matches, nil
+}
+
+/*
+gatherJoinRows picks the rows of a column, a negative row is an unmatched row
+of a LEFT join and is left as zero, or NaN for floating point columns
+*/
+func gatherJoinRows(column interface{}, rows []int) interface{} {
+	values := reflect.ValueOf(column)
+	out := reflect.MakeSlice(values.Type(), len(rows), len(rows))
+	for i, row := range rows {
+		if row >= 0 {
+			out.Index(i).Set(values.Index(row))
+			continue
+		}
+		switch out.Index(i).Kind() {
+		case reflect.Float32, reflect.Float64:
+			out.Index(i).SetFloat(math.NaN())
+		}
+	}
+	return out.Interface()
+}
+
+// appendColumnSeries returns the rows of head followed by the rows of tail.
+func appendColumnSeries(head, tail *io.ColumnSeries) *io.ColumnSeries {
+	if head.Len() == 0 {
+		return tail
+	}
+	if tail.Len() == 0 {
+		return head
+	}
+	cs := io.NewColumnSeries()
+	for _, name := range head.GetColumnNames() {
+		cs.AddColumn(name, reflect.AppendSlice(
+			reflect.ValueOf(head.GetColumn(name)),
+			reflect.ValueOf(tail.GetColumn(name)),
+		).Interface())
+		cs.CopyScale(name, head, name)
+	}
+	return cs
+}
+
+/*
+readTimeBucket reads a bucket within the optional time range, or only its last
+rows when lastRows is not zero. A range without data still has all the columns
+of the bucket.
+*/
+func readTimeBucket(catDir *catalog.Directory, key *io.TimeBucketKey, start, end *time.Time, lastRows int,
+) (cs *io.ColumnSeries, err error) {
+	q := planner.NewQuery(catDir)
+	q.AddTargetKey(key)
+	if start != nil {
+		q.SetStart(*start)
+	}
+	if end != nil {
+		q.SetEnd(*end)
+	}
+	if lastRows != 0 {
+		q.SetRowLimit(io.LAST, lastRows)
+	}
+	parsed, err := q.Parse()
+	if err != nil {
+		return nil, err
+	}
+	scanner, err := executor.NewReader(parsed)
+	if err != nil {
+		return nil, err
+	}
+	csm, err := scanner.Read()
+	if err != nil {
+		return nil, err
+	}
+	if cs, ok := csm[*key]; ok && cs.Exists("Epoch") {
+		return cs, nil
+	}
+
+	dsv, err := catDir.GetDataShapes(key)
+	if err != nil {
+		return nil, err
+	}
+	cs = io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{})
+	for _, ds := range dsv {
+		switch {
+		case ds.Name == "Epoch" || ds.Name == io.NullsColumn:
+		case io.IsNullable(dsv):
+			cs.AddNullableColumn(ds)
+		default:
+			cs.AddNullColumn(ds)
+		}
+	}
+	return cs, nil
+}
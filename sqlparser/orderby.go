@@ -0,0 +1,265 @@
+package sqlparser
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+rowComparator compares two rows of a single column. Floating point NaN values
+are treated as NULL for the purposes of NULLS FIRST / NULLS LAST.
+*/
+type rowComparator struct {
+	compare func(i, j int) int
+	isNull  func(i int) bool
+}
+
+func notNull(int) bool { return false }
+
+func newRowComparator(iCol interface{}) (rc *rowComparator, err error) {
+	rc = &rowComparator{isNull: notNull}
+	switch col := iCol.(type) {
+	case []float32:
+		rc.compare = func(i, j int) int { return compareFloat64(float64(col[i]), float64(col[j])) }
+		rc.isNull = func(i int) bool { return math.IsNaN(float64(col[i])) }
+	case []float64:
+		rc.compare = func(i, j int) int { return compareFloat64(col[i], col[j]) }
+		rc.isNull = func(i int) bool { return math.IsNaN(col[i]) }
+	case []int:
+		rc.compare = func(i, j int) int { return compareInt64(int64(col[i]), int64(col[j])) }
+	case []int8:
+		rc.compare = func(i, j int) int { return compareInt64(int64(col[i]), int64(col[j])) }
+	case []int16:
+		rc.compare = func(i, j int) int { return compareInt64(int64(col[i]), int64(col[j])) }
+	case []int32:
+		rc.compare = func(i, j int) int { return compareInt64(int64(col[i]), int64(col[j])) }
+	case []int64:
+		rc.compare = func(i, j int) int { return compareInt64(col[i], col[j]) }
+	case []io.Decimal64:
+		// the decimals of a column have the same scale
+		rc.compare = func(i, j int) int { return compareInt64(int64(col[i]), int64(col[j])) }
+	case []uint8:
+		rc.compare = func(i, j int) int { return compareUint64(uint64(col[i]), uint64(col[j])) }
+	case []uint16:
+		rc.compare = func(i, j int) int { return compareUint64(uint64(col[i]), uint64(col[j])) }
+	case []uint32:
+		rc.compare = func(i, j int) int { return compareUint64(uint64(col[i]), uint64(col[j])) }
+	case []uint64:
+		rc.compare = func(i, j int) int { return compareUint64(col[i], col[j]) }
+	case []bool:
+		rc.compare = func(i, j int) int {
+			switch {
+			case col[i] == col[j]:
+				return 0
+			case !col[i]:
+				return -1
+			default:
+				return 1
+			}
+		}
+	case []string:
+		rc.compare = func(i, j int) int { return strings.Compare(col[i], col[j]) }
+	case [][16]rune:
+		rc.compare = func(i, j int) int {
+			return strings.Compare(string(trimRunes(col[i])), string(trimRunes(col[j])))
+		}
+	case [][]byte:
+		rc.compare = func(i, j int) int { return bytes.Compare(col[i], col[j]) }
+	default:
+		return nil, fmt.Errorf("unable to sort column of type %T", iCol)
+	}
+	return rc, nil
+}
+
+func compareFloat64(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareUint64(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func trimRunes(r [16]rune) []rune {
+	for i, c := range r {
+		if c == 0 {
+			return r[:i]
+		}
+	}
+	return r[:]
+}
+
+/*
+sortKey is a resolved ORDER BY item.
+*/
+type sortKey struct {
+	item       SortItem
+	comparator *rowComparator
+}
+
+func (sk *sortKey) less(i, j int) (less, decided bool) {
+	iNull, jNull := sk.comparator.isNull(i), sk.comparator.isNull(j)
+	switch {
+	case iNull && jNull:
+		return false, false
+	case iNull:
+		return sk.item.NullsFirst(), true
+	case jNull:
+		return !sk.item.NullsFirst(), true
+	}
+	c := sk.comparator.compare(i, j)
+	if c == 0 {
+		return false, false
+	}
+	if sk.item.IsDescending() {
+		return c > 0, true
+	}
+	return c < 0, true
+}
+
+// limitDirection returns the direction that a LIMIT can be pushed down to the
+// IO layer with, which is only possible when the output is in Epoch order.
+func (sr *SelectRelation) limitDirection() (direction io.DirectionEnum, ok bool) {
+	switch {
+	case len(sr.OrderBy) == 0:
+		return io.FIRST, true
+	case len(sr.OrderBy) == 1 && sr.OrderBy[0].ColumnName == "Epoch":
+		if sr.OrderBy[0].IsDescending() {
+			return io.LAST, true
+		}
+		return io.FIRST, true
+	default:
+		return io.FIRST, false
+	}
+}
+
+// resolveSortColumn finds the name of the output column referenced by an
+// ORDER BY item.
+func (sr *SelectRelation) resolveSortColumn(cs *io.ColumnSeries, item SortItem,
+	functionOutputs map[*AliasedIdentifier][]string) (name string, err error) {
+	if item.Ordinal != 0 {
+		names := cs.GetColumnNames()
+		if item.Ordinal > len(names) {
+			return "", fmt.Errorf("ORDER BY position %d is not in select list", item.Ordinal)
+		}
+		return names[item.Ordinal-1], nil
+	}
+	return sr.resolveOutputColumn(cs, "ORDER BY", item.ColumnName, item.FunctionCall, functionOutputs)
+}
+
+// resolveOutputColumn finds the name of the output column referenced by a
+// column name or alias, or by repeating a function call of the select list
+// with the same arguments. A function with several output columns has to be
+// referenced by the name of one of them.
+func (sr *SelectRelation) resolveOutputColumn(cs *io.ColumnSeries, clause, columnName string,
+	functionCall *FunctionCallReference, functionOutputs map[*AliasedIdentifier][]string) (name string, err error) {
+	if functionCall != nil {
+		var outputs []string
+		for _, sl := range sr.SelectList {
+			if sl.IsFunctionCall && sl.FunctionCall.Equal(functionCall) && len(functionOutputs[sl]) != 0 {
+				outputs = functionOutputs[sl]
+				break
+			}
+		}
+		switch len(outputs) {
+		case 0:
+			return "", fmt.Errorf("%s function %s is not in select list", clause, functionCall)
+		case 1:
+			return outputs[0], nil
+		default:
+			return "", fmt.Errorf("%s function %s is ambiguous, use one of its columns %s", clause, functionCall,
+				strings.Join(outputs, ", "))
+		}
+	}
+	if cs.Exists(columnName) {
+		return columnName, nil
+	}
+	// The reference may use the source name of an aliased column
+	for _, sl := range sr.SelectList {
+		if sl.IsPrimary && sl.IsAliased && sl.PrimaryName == columnName {
+			return sl.Alias, nil
+		}
+	}
+	for _, n := range cs.GetColumnNames() {
+		if strings.EqualFold(n, columnName) {
+			return n, nil
+		}
+	}
+	return "", fmt.Errorf("%s column %s is not in select list", clause, columnName)
+}
+
+// sortOutput reorders the rows of the result set according to the ORDER BY
+// clause. The sort is stable, so rows with equal keys stay in time order.
+func (sr *SelectRelation) sortOutput(cs *io.ColumnSeries,
+	functionOutputs map[*AliasedIdentifier][]string) error {
+	if cs.Len() < 2 {
+		return nil
+	}
+	keys := make([]*sortKey, len(sr.OrderBy))
+	for i, item := range sr.OrderBy {
+		name, err := sr.resolveSortColumn(cs, item, functionOutputs)
+		if err != nil {
+			return err
+		}
+		comparator, err := newRowComparator(cs.GetColumn(name))
+		if err != nil {
+			return err
+		}
+		if name == "Epoch" {
+			// Variable length records share an Epoch and differ by Nanoseconds
+			if nanosecs, ok := cs.GetColumn("Nanoseconds").([]int32); ok {
+				compareEpoch := comparator.compare
+				comparator.compare = func(i, j int) int {
+					if c := compareEpoch(i, j); c != 0 {
+						return c
+					}
+					return compareInt64(int64(nanosecs[i]), int64(nanosecs[j]))
+				}
+			}
+		}
+		keys[i] = &sortKey{item: item, comparator: comparator}
+	}
+
+	index := make([]int, cs.Len())
+	for i := range index {
+		index[i] = i
+	}
+	sort.SliceStable(index, func(a, b int) bool {
+		for _, key := range keys {
+			if less, decided := key.less(index[a], index[b]); decided {
+				return less
+			}
+		}
+		return false
+	})
+	return cs.RestrictViaIndex(index)
+}
@@ -206,6 +206,7 @@ booleanExpression
     | left=booleanExpression operator=(AND | OR)
      right=expression                                              #logicalBinary
     | booleanliteral                                               #boolLiteralToo
+    | '(' booleanExpression ')'                                    #parenthesizedBooleanExpression
     ;
 
 booleanliteral: (TRUE | FALSE);
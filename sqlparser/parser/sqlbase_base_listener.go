@@ -267,6 +267,14 @@ func (s *BaseSQLBaseListener) EnterBoolLiteralToo(ctx *BoolLiteralTooContext) {}
 // ExitBoolLiteralToo is called when production boolLiteralToo is exited.
 func (s *BaseSQLBaseListener) ExitBoolLiteralToo(ctx *BoolLiteralTooContext) {}
 
+// EnterParenthesizedBooleanExpression is called when production parenthesizedBooleanExpression is entered.
+func (s *BaseSQLBaseListener) EnterParenthesizedBooleanExpression(ctx *ParenthesizedBooleanExpressionContext) {
+}
+
+// ExitParenthesizedBooleanExpression is called when production parenthesizedBooleanExpression is exited.
+func (s *BaseSQLBaseListener) ExitParenthesizedBooleanExpression(ctx *ParenthesizedBooleanExpressionContext) {
+}
+
 // EnterLogicalBinary is called when production logicalBinary is entered.
 func (s *BaseSQLBaseListener) EnterLogicalBinary(ctx *LogicalBinaryContext) {}
 
@@ -131,6 +131,9 @@ type SQLBaseListener interface {
 	// EnterBoolLiteralToo is called when entering the boolLiteralToo production.
 	EnterBoolLiteralToo(c *BoolLiteralTooContext)
 
+	// EnterParenthesizedBooleanExpression is called when entering the parenthesizedBooleanExpression production.
+	EnterParenthesizedBooleanExpression(c *ParenthesizedBooleanExpressionContext)
+
 	// EnterLogicalBinary is called when entering the logicalBinary production.
 	EnterLogicalBinary(c *LogicalBinaryContext)
 
@@ -458,6 +461,9 @@ type SQLBaseListener interface {
 	// ExitBoolLiteralToo is called when exiting the boolLiteralToo production.
 	ExitBoolLiteralToo(c *BoolLiteralTooContext)
 
+	// ExitParenthesizedBooleanExpression is called when exiting the parenthesizedBooleanExpression production.
+	ExitParenthesizedBooleanExpression(c *ParenthesizedBooleanExpressionContext)
+
 	// ExitLogicalBinary is called when exiting the logicalBinary production.
 	ExitLogicalBinary(c *LogicalBinaryContext)
 
@@ -16,7 +16,7 @@ var _ = reflect.Copy
 var _ = strconv.Itoa
 
 var parserATN = []uint16{
-	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 213, 950,
+	3, 24715, 42794, 33075, 47597, 16764, 15335, 30598, 22884, 3, 213, 953,
 	4, 2, 9, 2, 4, 3, 9, 3, 4, 4, 9, 4, 4, 5, 9, 5, 4, 6, 9, 6, 4, 7, 9, 7,
 	4, 8, 9, 8, 4, 9, 9, 9, 4, 10, 9, 10, 4, 11, 9, 11, 4, 12, 9, 12, 4, 13,
 	9, 13, 4, 14, 9, 14, 4, 15, 9, 15, 4, 16, 9, 16, 4, 17, 9, 17, 4, 18, 9,
@@ -120,238 +120,239 @@ var parserATN = []uint16{
 	3, 43, 5, 43, 925, 10, 43, 3, 44, 3, 44, 3, 44, 3, 44, 5, 44, 931, 10,
 	44, 3, 45, 3, 45, 3, 45, 7, 45, 936, 10, 45, 12, 45, 14, 45, 939, 11, 45,
 	3, 46, 3, 46, 3, 46, 3, 46, 3, 46, 5, 46, 946, 10, 46, 3, 47, 3, 47, 3,
-	47, 2, 8, 12, 34, 50, 56, 58, 70, 48, 2, 4, 6, 8, 10, 12, 14, 16, 18, 20,
-	22, 24, 26, 28, 30, 32, 34, 36, 38, 40, 42, 44, 46, 48, 50, 52, 54, 56,
-	58, 60, 62, 64, 66, 68, 70, 72, 74, 76, 78, 80, 82, 84, 86, 88, 90, 92,
-	2, 20, 3, 2, 136, 138, 3, 2, 46, 47, 3, 2, 43, 44, 4, 2, 15, 15, 18, 18,
-	3, 2, 140, 142, 3, 2, 30, 31, 3, 2, 40, 41, 3, 2, 196, 197, 3, 2, 196,
-	200, 3, 2, 180, 183, 3, 2, 190, 195, 3, 2, 15, 17, 3, 2, 58, 63, 3, 2,
-	89, 90, 3, 2, 92, 93, 3, 2, 119, 120, 3, 2, 121, 123, 18, 2, 13, 13, 15,
-	17, 29, 29, 34, 34, 48, 49, 51, 64, 86, 90, 92, 95, 100, 100, 102, 104,
-	110, 123, 126, 134, 139, 143, 148, 168, 172, 178, 180, 186, 2, 1082, 2,
-	94, 3, 2, 2, 2, 4, 124, 3, 2, 2, 2, 6, 127, 3, 2, 2, 2, 8, 131, 3, 2, 2,
-	2, 10, 143, 3, 2, 2, 2, 12, 160, 3, 2, 2, 2, 14, 190, 3, 2, 2, 2, 16, 192,
-	3, 2, 2, 2, 18, 200, 3, 2, 2, 2, 20, 237, 3, 2, 2, 2, 22, 287, 3, 2, 2,
-	2, 24, 302, 3, 2, 2, 2, 26, 317, 3, 2, 2, 2, 28, 319, 3, 2, 2, 2, 30, 328,
-	3, 2, 2, 2, 32, 342, 3, 2, 2, 2, 34, 344, 3, 2, 2, 2, 36, 371, 3, 2, 2,
-	2, 38, 387, 3, 2, 2, 2, 40, 389, 3, 2, 2, 2, 42, 398, 3, 2, 2, 2, 44, 408,
-	3, 2, 2, 2, 46, 443, 3, 2, 2, 2, 48, 447, 3, 2, 2, 2, 50, 456, 3, 2, 2,
-	2, 52, 466, 3, 2, 2, 2, 54, 529, 3, 2, 2, 2, 56, 535, 3, 2, 2, 2, 58, 764,
-	3, 2, 2, 2, 60, 785, 3, 2, 2, 2, 62, 787, 3, 2, 2, 2, 64, 789, 3, 2, 2,
-	2, 66, 791, 3, 2, 2, 2, 68, 801, 3, 2, 2, 2, 70, 845, 3, 2, 2, 2, 72, 856,
-	3, 2, 2, 2, 74, 863, 3, 2, 2, 2, 76, 865, 3, 2, 2, 2, 78, 870, 3, 2, 2,
-	2, 80, 876, 3, 2, 2, 2, 82, 915, 3, 2, 2, 2, 84, 924, 3, 2, 2, 2, 86, 930,
-	3, 2, 2, 2, 88, 932, 3, 2, 2, 2, 90, 945, 3, 2, 2, 2, 92, 947, 3, 2, 2,
-	2, 94, 95, 5, 4, 3, 2, 95, 96, 7, 10, 2, 2, 96, 3, 3, 2, 2, 2, 97, 125,
-	5, 6, 4, 2, 98, 99, 7, 105, 2, 2, 99, 100, 7, 107, 2, 2, 100, 102, 5, 88,
-	45, 2, 101, 103, 5, 44, 23, 2, 102, 101, 3, 2, 2, 2, 102, 103, 3, 2, 2,
-	2, 103, 104, 3, 2, 2, 2, 104, 105, 5, 6, 4, 2, 105, 125, 3, 2, 2, 2, 106,
-	108, 7, 115, 2, 2, 107, 109, 7, 116, 2, 2, 108, 107, 3, 2, 2, 2, 108, 109,
-	3, 2, 2, 2, 109, 121, 3, 2, 2, 2, 110, 111, 7, 3, 2, 2, 111, 116, 5, 86,
-	44, 2, 112, 113, 7, 4, 2, 2, 113, 115, 5, 86, 44, 2, 114, 112, 3, 2, 2,
-	2, 115, 118, 3, 2, 2, 2, 116, 114, 3, 2, 2, 2, 116, 117, 3, 2, 2, 2, 117,
-	119, 3, 2, 2, 2, 118, 116, 3, 2, 2, 2, 119, 120, 7, 5, 2, 2, 120, 122,
-	3, 2, 2, 2, 121, 110, 3, 2, 2, 2, 121, 122, 3, 2, 2, 2, 122, 123, 3, 2,
-	2, 2, 123, 125, 5, 4, 3, 2, 124, 97, 3, 2, 2, 2, 124, 98, 3, 2, 2, 2, 124,
-	106, 3, 2, 2, 2, 125, 5, 3, 2, 2, 2, 126, 128, 5, 8, 5, 2, 127, 126, 3,
-	2, 2, 2, 127, 128, 3, 2, 2, 2, 128, 129, 3, 2, 2, 2, 129, 130, 5, 10, 6,
-	2, 130, 7, 3, 2, 2, 2, 131, 133, 7, 96, 2, 2, 132, 134, 7, 97, 2, 2, 133,
-	132, 3, 2, 2, 2, 133, 134, 3, 2, 2, 2, 134, 135, 3, 2, 2, 2, 135, 140,
-	5, 28, 15, 2, 136, 137, 7, 4, 2, 2, 137, 139, 5, 28, 15, 2, 138, 136, 3,
-	2, 2, 2, 139, 142, 3, 2, 2, 2, 140, 138, 3, 2, 2, 2, 140, 141, 3, 2, 2,
-	2, 141, 9, 3, 2, 2, 2, 142, 140, 3, 2, 2, 2, 143, 154, 5, 12, 7, 2, 144,
-	145, 7, 26, 2, 2, 145, 146, 7, 21, 2, 2, 146, 151, 5, 16, 9, 2, 147, 148,
-	7, 4, 2, 2, 148, 150, 5, 16, 9, 2, 149, 147, 3, 2, 2, 2, 150, 153, 3, 2,
-	2, 2, 151, 149, 3, 2, 2, 2, 151, 152, 3, 2, 2, 2, 152, 155, 3, 2, 2, 2,
-	153, 151, 3, 2, 2, 2, 154, 144, 3, 2, 2, 2, 154, 155, 3, 2, 2, 2, 155,
-	158, 3, 2, 2, 2, 156, 157, 7, 28, 2, 2, 157, 159, 7, 205, 2, 2, 158, 156,
-	3, 2, 2, 2, 158, 159, 3, 2, 2, 2, 159, 11, 3, 2, 2, 2, 160, 161, 8, 7,
-	1, 2, 161, 162, 5, 14, 8, 2, 162, 171, 3, 2, 2, 2, 163, 164, 12, 3, 2,
-	2, 164, 166, 9, 2, 2, 2, 165, 167, 5, 30, 16, 2, 166, 165, 3, 2, 2, 2,
-	166, 167, 3, 2, 2, 2, 167, 168, 3, 2, 2, 2, 168, 170, 5, 12, 7, 4, 169,
-	163, 3, 2, 2, 2, 170, 173, 3, 2, 2, 2, 171, 169, 3, 2, 2, 2, 171, 172,
-	3, 2, 2, 2, 172, 13, 3, 2, 2, 2, 173, 171, 3, 2, 2, 2, 174, 191, 5, 18,
-	10, 2, 175, 176, 7, 101, 2, 2, 176, 191, 5, 88, 45, 2, 177, 178, 7, 98,
-	2, 2, 178, 183, 5, 48, 25, 2, 179, 180, 7, 4, 2, 2, 180, 182, 5, 48, 25,
-	2, 181, 179, 3, 2, 2, 2, 182, 185, 3, 2, 2, 2, 183, 181, 3, 2, 2, 2, 183,
-	184, 3, 2, 2, 2, 184, 191, 3, 2, 2, 2, 185, 183, 3, 2, 2, 2, 186, 187,
-	7, 3, 2, 2, 187, 188, 5, 10, 6, 2, 188, 189, 7, 5, 2, 2, 189, 191, 3, 2,
-	2, 2, 190, 174, 3, 2, 2, 2, 190, 175, 3, 2, 2, 2, 190, 177, 3, 2, 2, 2,
-	190, 186, 3, 2, 2, 2, 191, 15, 3, 2, 2, 2, 192, 194, 5, 48, 25, 2, 193,
-	195, 9, 3, 2, 2, 194, 193, 3, 2, 2, 2, 194, 195, 3, 2, 2, 2, 195, 198,
-	3, 2, 2, 2, 196, 197, 7, 42, 2, 2, 197, 199, 9, 4, 2, 2, 198, 196, 3, 2,
-	2, 2, 198, 199, 3, 2, 2, 2, 199, 17, 3, 2, 2, 2, 200, 202, 7, 11, 2, 2,
-	201, 203, 5, 30, 16, 2, 202, 201, 3, 2, 2, 2, 202, 203, 3, 2, 2, 2, 203,
-	204, 3, 2, 2, 2, 204, 209, 5, 32, 17, 2, 205, 206, 7, 4, 2, 2, 206, 208,
-	5, 32, 17, 2, 207, 205, 3, 2, 2, 2, 208, 211, 3, 2, 2, 2, 209, 207, 3,
-	2, 2, 2, 209, 210, 3, 2, 2, 2, 210, 221, 3, 2, 2, 2, 211, 209, 3, 2, 2,
-	2, 212, 213, 7, 12, 2, 2, 213, 218, 5, 34, 18, 2, 214, 215, 7, 4, 2, 2,
-	215, 217, 5, 34, 18, 2, 216, 214, 3, 2, 2, 2, 217, 220, 3, 2, 2, 2, 218,
-	216, 3, 2, 2, 2, 218, 219, 3, 2, 2, 2, 219, 222, 3, 2, 2, 2, 220, 218,
-	3, 2, 2, 2, 221, 212, 3, 2, 2, 2, 221, 222, 3, 2, 2, 2, 222, 225, 3, 2,
-	2, 2, 223, 224, 7, 19, 2, 2, 224, 226, 5, 50, 26, 2, 225, 223, 3, 2, 2,
-	2, 225, 226, 3, 2, 2, 2, 226, 230, 3, 2, 2, 2, 227, 228, 7, 20, 2, 2, 228,
-	229, 7, 21, 2, 2, 229, 231, 5, 20, 11, 2, 230, 227, 3, 2, 2, 2, 230, 231,
-	3, 2, 2, 2, 231, 234, 3, 2, 2, 2, 232, 233, 7, 27, 2, 2, 233, 235, 5, 50,
-	26, 2, 234, 232, 3, 2, 2, 2, 234, 235, 3, 2, 2, 2, 235, 19, 3, 2, 2, 2,
-	236, 238, 5, 30, 16, 2, 237, 236, 3, 2, 2, 2, 237, 238, 3, 2, 2, 2, 238,
-	239, 3, 2, 2, 2, 239, 244, 5, 22, 12, 2, 240, 241, 7, 4, 2, 2, 241, 243,
-	5, 22, 12, 2, 242, 240, 3, 2, 2, 2, 243, 246, 3, 2, 2, 2, 244, 242, 3,
-	2, 2, 2, 244, 245, 3, 2, 2, 2, 245, 21, 3, 2, 2, 2, 246, 244, 3, 2, 2,
-	2, 247, 288, 5, 24, 13, 2, 248, 249, 7, 25, 2, 2, 249, 258, 7, 3, 2, 2,
-	250, 255, 5, 88, 45, 2, 251, 252, 7, 4, 2, 2, 252, 254, 5, 88, 45, 2, 253,
-	251, 3, 2, 2, 2, 254, 257, 3, 2, 2, 2, 255, 253, 3, 2, 2, 2, 255, 256,
-	3, 2, 2, 2, 256, 259, 3, 2, 2, 2, 257, 255, 3, 2, 2, 2, 258, 250, 3, 2,
-	2, 2, 258, 259, 3, 2, 2, 2, 259, 260, 3, 2, 2, 2, 260, 288, 7, 5, 2, 2,
-	261, 262, 7, 24, 2, 2, 262, 271, 7, 3, 2, 2, 263, 268, 5, 88, 45, 2, 264,
-	265, 7, 4, 2, 2, 265, 267, 5, 88, 45, 2, 266, 264, 3, 2, 2, 2, 267, 270,
-	3, 2, 2, 2, 268, 266, 3, 2, 2, 2, 268, 269, 3, 2, 2, 2, 269, 272, 3, 2,
-	2, 2, 270, 268, 3, 2, 2, 2, 271, 263, 3, 2, 2, 2, 271, 272, 3, 2, 2, 2,
-	272, 273, 3, 2, 2, 2, 273, 288, 7, 5, 2, 2, 274, 275, 7, 22, 2, 2, 275,
-	276, 7, 23, 2, 2, 276, 277, 7, 3, 2, 2, 277, 282, 5, 26, 14, 2, 278, 279,
-	7, 4, 2, 2, 279, 281, 5, 26, 14, 2, 280, 278, 3, 2, 2, 2, 281, 284, 3,
-	2, 2, 2, 282, 280, 3, 2, 2, 2, 282, 283, 3, 2, 2, 2, 283, 285, 3, 2, 2,
-	2, 284, 282, 3, 2, 2, 2, 285, 286, 7, 5, 2, 2, 286, 288, 3, 2, 2, 2, 287,
-	247, 3, 2, 2, 2, 287, 248, 3, 2, 2, 2, 287, 261, 3, 2, 2, 2, 287, 274,
-	3, 2, 2, 2, 288, 23, 3, 2, 2, 2, 289, 298, 7, 3, 2, 2, 290, 295, 5, 48,
-	25, 2, 291, 292, 7, 4, 2, 2, 292, 294, 5, 48, 25, 2, 293, 291, 3, 2, 2,
-	2, 294, 297, 3, 2, 2, 2, 295, 293, 3, 2, 2, 2, 295, 296, 3, 2, 2, 2, 296,
-	299, 3, 2, 2, 2, 297, 295, 3, 2, 2, 2, 298, 290, 3, 2, 2, 2, 298, 299,
-	3, 2, 2, 2, 299, 300, 3, 2, 2, 2, 300, 303, 7, 5, 2, 2, 301, 303, 5, 48,
-	25, 2, 302, 289, 3, 2, 2, 2, 302, 301, 3, 2, 2, 2, 303, 25, 3, 2, 2, 2,
-	304, 313, 7, 3, 2, 2, 305, 310, 5, 88, 45, 2, 306, 307, 7, 4, 2, 2, 307,
-	309, 5, 88, 45, 2, 308, 306, 3, 2, 2, 2, 309, 312, 3, 2, 2, 2, 310, 308,
-	3, 2, 2, 2, 310, 311, 3, 2, 2, 2, 311, 314, 3, 2, 2, 2, 312, 310, 3, 2,
-	2, 2, 313, 305, 3, 2, 2, 2, 313, 314, 3, 2, 2, 2, 314, 315, 3, 2, 2, 2,
-	315, 318, 7, 5, 2, 2, 316, 318, 5, 88, 45, 2, 317, 304, 3, 2, 2, 2, 317,
-	316, 3, 2, 2, 2, 318, 27, 3, 2, 2, 2, 319, 321, 5, 90, 46, 2, 320, 322,
-	5, 44, 23, 2, 321, 320, 3, 2, 2, 2, 321, 322, 3, 2, 2, 2, 322, 323, 3,
-	2, 2, 2, 323, 324, 7, 14, 2, 2, 324, 325, 7, 3, 2, 2, 325, 326, 5, 6, 4,
-	2, 326, 327, 7, 5, 2, 2, 327, 29, 3, 2, 2, 2, 328, 329, 9, 5, 2, 2, 329,
-	31, 3, 2, 2, 2, 330, 335, 5, 48, 25, 2, 331, 333, 7, 14, 2, 2, 332, 331,
-	3, 2, 2, 2, 332, 333, 3, 2, 2, 2, 333, 334, 3, 2, 2, 2, 334, 336, 5, 90,
-	46, 2, 335, 332, 3, 2, 2, 2, 335, 336, 3, 2, 2, 2, 336, 343, 3, 2, 2, 2,
-	337, 338, 5, 88, 45, 2, 338, 339, 7, 202, 2, 2, 339, 340, 7, 198, 2, 2,
-	340, 343, 3, 2, 2, 2, 341, 343, 7, 198, 2, 2, 342, 330, 3, 2, 2, 2, 342,
-	337, 3, 2, 2, 2, 342, 341, 3, 2, 2, 2, 343, 33, 3, 2, 2, 2, 344, 345, 8,
-	18, 1, 2, 345, 346, 5, 40, 21, 2, 346, 358, 3, 2, 2, 2, 347, 349, 12, 4,
-	2, 2, 348, 350, 5, 36, 19, 2, 349, 348, 3, 2, 2, 2, 349, 350, 3, 2, 2,
-	2, 350, 351, 3, 2, 2, 2, 351, 352, 7, 76, 2, 2, 352, 354, 5, 34, 18, 2,
-	353, 355, 5, 38, 20, 2, 354, 353, 3, 2, 2, 2, 354, 355, 3, 2, 2, 2, 355,
-	357, 3, 2, 2, 2, 356, 347, 3, 2, 2, 2, 357, 360, 3, 2, 2, 2, 358, 356,
-	3, 2, 2, 2, 358, 359, 3, 2, 2, 2, 359, 35, 3, 2, 2, 2, 360, 358, 3, 2,
-	2, 2, 361, 372, 7, 79, 2, 2, 362, 363, 7, 80, 2, 2, 363, 372, 7, 78, 2,
-	2, 364, 365, 7, 81, 2, 2, 365, 372, 7, 78, 2, 2, 366, 367, 7, 82, 2, 2,
-	367, 372, 7, 78, 2, 2, 368, 372, 7, 77, 2, 2, 369, 372, 7, 83, 2, 2, 370,
-	372, 7, 80, 2, 2, 371, 361, 3, 2, 2, 2, 371, 362, 3, 2, 2, 2, 371, 364,
-	3, 2, 2, 2, 371, 366, 3, 2, 2, 2, 371, 368, 3, 2, 2, 2, 371, 369, 3, 2,
-	2, 2, 371, 370, 3, 2, 2, 2, 372, 37, 3, 2, 2, 2, 373, 374, 7, 85, 2, 2,
-	374, 388, 5, 50, 26, 2, 375, 376, 7, 84, 2, 2, 376, 377, 7, 3, 2, 2, 377,
-	382, 5, 90, 46, 2, 378, 379, 7, 4, 2, 2, 379, 381, 5, 90, 46, 2, 380, 378,
-	3, 2, 2, 2, 381, 384, 3, 2, 2, 2, 382, 380, 3, 2, 2, 2, 382, 383, 3, 2,
-	2, 2, 383, 385, 3, 2, 2, 2, 384, 382, 3, 2, 2, 2, 385, 386, 7, 5, 2, 2,
-	386, 388, 3, 2, 2, 2, 387, 373, 3, 2, 2, 2, 387, 375, 3, 2, 2, 2, 388,
-	39, 3, 2, 2, 2, 389, 396, 5, 42, 22, 2, 390, 391, 7, 143, 2, 2, 391, 392,
-	9, 6, 2, 2, 392, 393, 7, 3, 2, 2, 393, 394, 5, 48, 25, 2, 394, 395, 7,
-	5, 2, 2, 395, 397, 3, 2, 2, 2, 396, 390, 3, 2, 2, 2, 396, 397, 3, 2, 2,
-	2, 397, 41, 3, 2, 2, 2, 398, 406, 5, 46, 24, 2, 399, 401, 7, 14, 2, 2,
-	400, 399, 3, 2, 2, 2, 400, 401, 3, 2, 2, 2, 401, 402, 3, 2, 2, 2, 402,
-	404, 5, 90, 46, 2, 403, 405, 5, 44, 23, 2, 404, 403, 3, 2, 2, 2, 404, 405,
-	3, 2, 2, 2, 405, 407, 3, 2, 2, 2, 406, 400, 3, 2, 2, 2, 406, 407, 3, 2,
-	2, 2, 407, 43, 3, 2, 2, 2, 408, 409, 7, 3, 2, 2, 409, 414, 5, 90, 46, 2,
-	410, 411, 7, 4, 2, 2, 411, 413, 5, 90, 46, 2, 412, 410, 3, 2, 2, 2, 413,
-	416, 3, 2, 2, 2, 414, 412, 3, 2, 2, 2, 414, 415, 3, 2, 2, 2, 415, 417,
-	3, 2, 2, 2, 416, 414, 3, 2, 2, 2, 417, 418, 7, 5, 2, 2, 418, 45, 3, 2,
-	2, 2, 419, 444, 5, 88, 45, 2, 420, 421, 7, 3, 2, 2, 421, 422, 5, 6, 4,
-	2, 422, 423, 7, 5, 2, 2, 423, 444, 3, 2, 2, 2, 424, 425, 7, 146, 2, 2,
-	425, 426, 7, 3, 2, 2, 426, 431, 5, 48, 25, 2, 427, 428, 7, 4, 2, 2, 428,
-	430, 5, 48, 25, 2, 429, 427, 3, 2, 2, 2, 430, 433, 3, 2, 2, 2, 431, 429,
-	3, 2, 2, 2, 431, 432, 3, 2, 2, 2, 432, 434, 3, 2, 2, 2, 433, 431, 3, 2,
-	2, 2, 434, 437, 7, 5, 2, 2, 435, 436, 7, 96, 2, 2, 436, 438, 7, 147, 2,
-	2, 437, 435, 3, 2, 2, 2, 437, 438, 3, 2, 2, 2, 438, 444, 3, 2, 2, 2, 439,
-	440, 7, 3, 2, 2, 440, 441, 5, 34, 18, 2, 441, 442, 7, 5, 2, 2, 442, 444,
-	3, 2, 2, 2, 443, 419, 3, 2, 2, 2, 443, 420, 3, 2, 2, 2, 443, 424, 3, 2,
-	2, 2, 443, 439, 3, 2, 2, 2, 444, 47, 3, 2, 2, 2, 445, 448, 5, 50, 26, 2,
-	446, 448, 5, 56, 29, 2, 447, 445, 3, 2, 2, 2, 447, 446, 3, 2, 2, 2, 448,
-	49, 3, 2, 2, 2, 449, 450, 8, 26, 1, 2, 450, 451, 5, 56, 29, 2, 451, 452,
-	5, 54, 28, 2, 452, 457, 3, 2, 2, 2, 453, 454, 7, 33, 2, 2, 454, 457, 5,
-	50, 26, 5, 455, 457, 5, 52, 27, 2, 456, 449, 3, 2, 2, 2, 456, 453, 3, 2,
-	2, 2, 456, 455, 3, 2, 2, 2, 457, 463, 3, 2, 2, 2, 458, 459, 12, 4, 2, 2,
-	459, 460, 9, 7, 2, 2, 460, 462, 5, 48, 25, 2, 461, 458, 3, 2, 2, 2, 462,
-	465, 3, 2, 2, 2, 463, 461, 3, 2, 2, 2, 463, 464, 3, 2, 2, 2, 464, 51, 3,
-	2, 2, 2, 465, 463, 3, 2, 2, 2, 466, 467, 9, 8, 2, 2, 467, 53, 3, 2, 2,
-	2, 468, 469, 5, 62, 32, 2, 469, 470, 5, 56, 29, 2, 470, 530, 3, 2, 2, 2,
-	471, 472, 5, 62, 32, 2, 472, 473, 5, 64, 33, 2, 473, 474, 7, 3, 2, 2, 474,
-	475, 5, 6, 4, 2, 475, 476, 7, 5, 2, 2, 476, 530, 3, 2, 2, 2, 477, 479,
-	7, 33, 2, 2, 478, 477, 3, 2, 2, 2, 478, 479, 3, 2, 2, 2, 479, 480, 3, 2,
-	2, 2, 480, 481, 7, 36, 2, 2, 481, 482, 5, 56, 29, 2, 482, 483, 7, 31, 2,
-	2, 483, 484, 5, 56, 29, 2, 484, 530, 3, 2, 2, 2, 485, 487, 7, 33, 2, 2,
-	486, 485, 3, 2, 2, 2, 486, 487, 3, 2, 2, 2, 487, 488, 3, 2, 2, 2, 488,
-	489, 7, 32, 2, 2, 489, 490, 7, 3, 2, 2, 490, 495, 5, 56, 29, 2, 491, 492,
-	7, 4, 2, 2, 492, 494, 5, 56, 29, 2, 493, 491, 3, 2, 2, 2, 494, 497, 3,
-	2, 2, 2, 495, 493, 3, 2, 2, 2, 495, 496, 3, 2, 2, 2, 496, 498, 3, 2, 2,
-	2, 497, 495, 3, 2, 2, 2, 498, 499, 7, 5, 2, 2, 499, 530, 3, 2, 2, 2, 500,
-	502, 7, 33, 2, 2, 501, 500, 3, 2, 2, 2, 501, 502, 3, 2, 2, 2, 502, 503,
-	3, 2, 2, 2, 503, 504, 7, 32, 2, 2, 504, 505, 7, 3, 2, 2, 505, 506, 5, 6,
-	4, 2, 506, 507, 7, 5, 2, 2, 507, 530, 3, 2, 2, 2, 508, 510, 7, 33, 2, 2,
-	509, 508, 3, 2, 2, 2, 509, 510, 3, 2, 2, 2, 510, 511, 3, 2, 2, 2, 511,
-	512, 7, 37, 2, 2, 512, 515, 5, 56, 29, 2, 513, 514, 7, 45, 2, 2, 514, 516,
-	5, 56, 29, 2, 515, 513, 3, 2, 2, 2, 515, 516, 3, 2, 2, 2, 516, 530, 3,
-	2, 2, 2, 517, 519, 7, 38, 2, 2, 518, 520, 7, 33, 2, 2, 519, 518, 3, 2,
-	2, 2, 519, 520, 3, 2, 2, 2, 520, 521, 3, 2, 2, 2, 521, 530, 7, 39, 2, 2,
-	522, 524, 7, 38, 2, 2, 523, 525, 7, 33, 2, 2, 524, 523, 3, 2, 2, 2, 524,
-	525, 3, 2, 2, 2, 525, 526, 3, 2, 2, 2, 526, 527, 7, 18, 2, 2, 527, 528,
-	7, 12, 2, 2, 528, 530, 5, 56, 29, 2, 529, 468, 3, 2, 2, 2, 529, 471, 3,
-	2, 2, 2, 529, 478, 3, 2, 2, 2, 529, 486, 3, 2, 2, 2, 529, 501, 3, 2, 2,
-	2, 529, 509, 3, 2, 2, 2, 529, 517, 3, 2, 2, 2, 529, 522, 3, 2, 2, 2, 530,
-	55, 3, 2, 2, 2, 531, 532, 8, 29, 1, 2, 532, 536, 5, 58, 30, 2, 533, 534,
-	9, 9, 2, 2, 534, 536, 5, 56, 29, 5, 535, 531, 3, 2, 2, 2, 535, 533, 3,
-	2, 2, 2, 536, 548, 3, 2, 2, 2, 537, 538, 12, 4, 2, 2, 538, 539, 9, 10,
-	2, 2, 539, 547, 5, 56, 29, 5, 540, 541, 12, 3, 2, 2, 541, 542, 7, 201,
-	2, 2, 542, 547, 5, 56, 29, 4, 543, 544, 12, 6, 2, 2, 544, 545, 7, 29, 2,
-	2, 545, 547, 5, 60, 31, 2, 546, 537, 3, 2, 2, 2, 546, 540, 3, 2, 2, 2,
-	546, 543, 3, 2, 2, 2, 547, 550, 3, 2, 2, 2, 548, 546, 3, 2, 2, 2, 548,
-	549, 3, 2, 2, 2, 549, 57, 3, 2, 2, 2, 550, 548, 3, 2, 2, 2, 551, 552, 8,
-	30, 1, 2, 552, 765, 7, 39, 2, 2, 553, 765, 7, 203, 2, 2, 554, 765, 7, 204,
-	2, 2, 555, 765, 7, 206, 2, 2, 556, 765, 7, 205, 2, 2, 557, 765, 5, 52,
-	27, 2, 558, 559, 5, 74, 38, 2, 559, 560, 7, 203, 2, 2, 560, 765, 3, 2,
-	2, 2, 561, 765, 5, 66, 34, 2, 562, 765, 7, 6, 2, 2, 563, 566, 5, 90, 46,
-	2, 564, 566, 7, 189, 2, 2, 565, 563, 3, 2, 2, 2, 565, 564, 3, 2, 2, 2,
-	566, 567, 3, 2, 2, 2, 567, 765, 7, 203, 2, 2, 568, 569, 7, 49, 2, 2, 569,
-	570, 7, 3, 2, 2, 570, 571, 5, 56, 29, 2, 571, 572, 7, 32, 2, 2, 572, 573,
-	5, 56, 29, 2, 573, 574, 7, 5, 2, 2, 574, 765, 3, 2, 2, 2, 575, 576, 7,
-	3, 2, 2, 576, 579, 5, 48, 25, 2, 577, 578, 7, 4, 2, 2, 578, 580, 5, 48,
-	25, 2, 579, 577, 3, 2, 2, 2, 580, 581, 3, 2, 2, 2, 581, 579, 3, 2, 2, 2,
-	581, 582, 3, 2, 2, 2, 582, 583, 3, 2, 2, 2, 583, 584, 7, 5, 2, 2, 584,
-	765, 3, 2, 2, 2, 585, 586, 7, 95, 2, 2, 586, 587, 7, 3, 2, 2, 587, 592,
-	5, 48, 25, 2, 588, 589, 7, 4, 2, 2, 589, 591, 5, 48, 25, 2, 590, 588, 3,
-	2, 2, 2, 591, 594, 3, 2, 2, 2, 592, 590, 3, 2, 2, 2, 592, 593, 3, 2, 2,
-	2, 593, 595, 3, 2, 2, 2, 594, 592, 3, 2, 2, 2, 595, 596, 7, 5, 2, 2, 596,
-	765, 3, 2, 2, 2, 597, 598, 5, 88, 45, 2, 598, 599, 7, 3, 2, 2, 599, 600,
-	7, 198, 2, 2, 600, 602, 7, 5, 2, 2, 601, 603, 5, 78, 40, 2, 602, 601, 3,
-	2, 2, 2, 602, 603, 3, 2, 2, 2, 603, 605, 3, 2, 2, 2, 604, 606, 5, 80, 41,
-	2, 605, 604, 3, 2, 2, 2, 605, 606, 3, 2, 2, 2, 606, 765, 3, 2, 2, 2, 607,
-	608, 5, 88, 45, 2, 608, 620, 7, 3, 2, 2, 609, 611, 5, 30, 16, 2, 610, 609,
-	3, 2, 2, 2, 610, 611, 3, 2, 2, 2, 611, 612, 3, 2, 2, 2, 612, 617, 5, 48,
-	25, 2, 613, 614, 7, 4, 2, 2, 614, 616, 5, 48, 25, 2, 615, 613, 3, 2, 2,
-	2, 616, 619, 3, 2, 2, 2, 617, 615, 3, 2, 2, 2, 617, 618, 3, 2, 2, 2, 618,
-	621, 3, 2, 2, 2, 619, 617, 3, 2, 2, 2, 620, 610, 3, 2, 2, 2, 620, 621,
-	3, 2, 2, 2, 621, 622, 3, 2, 2, 2, 622, 624, 7, 5, 2, 2, 623, 625, 5, 78,
-	40, 2, 624, 623, 3, 2, 2, 2, 624, 625, 3, 2, 2, 2, 625, 627, 3, 2, 2, 2,
-	626, 628, 5, 80, 41, 2, 627, 626, 3, 2, 2, 2, 627, 628, 3, 2, 2, 2, 628,
-	765, 3, 2, 2, 2, 629, 630, 5, 90, 46, 2, 630, 631, 7, 7, 2, 2, 631, 632,
-	5, 48, 25, 2, 632, 765, 3, 2, 2, 2, 633, 634, 7, 3, 2, 2, 634, 639, 5,
-	90, 46, 2, 635, 636, 7, 4, 2, 2, 636, 638, 5, 90, 46, 2, 637, 635, 3, 2,
-	2, 2, 638, 641, 3, 2, 2, 2, 639, 637, 3, 2, 2, 2, 639, 640, 3, 2, 2, 2,
-	640, 642, 3, 2, 2, 2, 641, 639, 3, 2, 2, 2, 642, 643, 7, 5, 2, 2, 643,
-	644, 7, 7, 2, 2, 644, 645, 5, 48, 25, 2, 645, 765, 3, 2, 2, 2, 646, 647,
-	7, 3, 2, 2, 647, 648, 5, 6, 4, 2, 648, 649, 7, 5, 2, 2, 649, 765, 3, 2,
-	2, 2, 650, 651, 7, 35, 2, 2, 651, 652, 7, 3, 2, 2, 652, 653, 5, 6, 4, 2,
-	653, 654, 7, 5, 2, 2, 654, 765, 3, 2, 2, 2, 655, 656, 7, 71, 2, 2, 656,
-	658, 5, 56, 29, 2, 657, 659, 5, 76, 39, 2, 658, 657, 3, 2, 2, 2, 659, 660,
-	3, 2, 2, 2, 660, 658, 3, 2, 2, 2, 660, 661, 3, 2, 2, 2, 661, 664, 3, 2,
-	2, 2, 662, 663, 7, 74, 2, 2, 663, 665, 5, 48, 25, 2, 664, 662, 3, 2, 2,
-	2, 664, 665, 3, 2, 2, 2, 665, 666, 3, 2, 2, 2, 666, 667, 7, 75, 2, 2, 667,
+	47, 3, 26, 3, 26, 3, 26, 2, 8, 12, 34, 50, 56, 58, 70, 48, 2, 4, 6, 8,
+	10, 12, 14, 16, 18, 20, 22, 24, 26, 28, 30, 32, 34, 36, 38, 40, 42, 44,
+	46, 48, 50, 52, 54, 56, 58, 60, 62, 64, 66, 68, 70, 72, 74, 76, 78, 80,
+	82, 84, 86, 88, 90, 92, 2, 20, 3, 2, 136, 138, 3, 2, 46, 47, 3, 2, 43,
+	44, 4, 2, 15, 15, 18, 18, 3, 2, 140, 142, 3, 2, 30, 31, 3, 2, 40, 41, 3,
+	2, 196, 197, 3, 2, 196, 200, 3, 2, 180, 183, 3, 2, 190, 195, 3, 2, 15,
+	17, 3, 2, 58, 63, 3, 2, 89, 90, 3, 2, 92, 93, 3, 2, 119, 120, 3, 2, 121,
+	123, 18, 2, 13, 13, 15, 17, 29, 29, 34, 34, 48, 49, 51, 64, 86, 90, 92,
+	95, 100, 100, 102, 104, 110, 123, 126, 134, 139, 143, 148, 168, 172, 178,
+	180, 186, 2, 1086, 2, 94, 3, 2, 2, 2, 4, 124, 3, 2, 2, 2, 6, 127, 3, 2,
+	2, 2, 8, 131, 3, 2, 2, 2, 10, 143, 3, 2, 2, 2, 12, 160, 3, 2, 2, 2, 14,
+	190, 3, 2, 2, 2, 16, 192, 3, 2, 2, 2, 18, 200, 3, 2, 2, 2, 20, 237, 3,
+	2, 2, 2, 22, 287, 3, 2, 2, 2, 24, 302, 3, 2, 2, 2, 26, 317, 3, 2, 2, 2,
+	28, 319, 3, 2, 2, 2, 30, 328, 3, 2, 2, 2, 32, 342, 3, 2, 2, 2, 34, 344,
+	3, 2, 2, 2, 36, 371, 3, 2, 2, 2, 38, 387, 3, 2, 2, 2, 40, 389, 3, 2, 2,
+	2, 42, 398, 3, 2, 2, 2, 44, 408, 3, 2, 2, 2, 46, 443, 3, 2, 2, 2, 48, 447,
+	3, 2, 2, 2, 50, 456, 3, 2, 2, 2, 52, 466, 3, 2, 2, 2, 54, 529, 3, 2, 2,
+	2, 56, 535, 3, 2, 2, 2, 58, 764, 3, 2, 2, 2, 60, 785, 3, 2, 2, 2, 62, 787,
+	3, 2, 2, 2, 64, 789, 3, 2, 2, 2, 66, 791, 3, 2, 2, 2, 68, 801, 3, 2, 2,
+	2, 70, 845, 3, 2, 2, 2, 72, 856, 3, 2, 2, 2, 74, 863, 3, 2, 2, 2, 76, 865,
+	3, 2, 2, 2, 78, 870, 3, 2, 2, 2, 80, 876, 3, 2, 2, 2, 82, 915, 3, 2, 2,
+	2, 84, 924, 3, 2, 2, 2, 86, 930, 3, 2, 2, 2, 88, 932, 3, 2, 2, 2, 90, 945,
+	3, 2, 2, 2, 92, 947, 3, 2, 2, 2, 94, 95, 5, 4, 3, 2, 95, 96, 7, 10, 2,
+	2, 96, 3, 3, 2, 2, 2, 97, 125, 5, 6, 4, 2, 98, 99, 7, 105, 2, 2, 99, 100,
+	7, 107, 2, 2, 100, 102, 5, 88, 45, 2, 101, 103, 5, 44, 23, 2, 102, 101,
+	3, 2, 2, 2, 102, 103, 3, 2, 2, 2, 103, 104, 3, 2, 2, 2, 104, 105, 5, 6,
+	4, 2, 105, 125, 3, 2, 2, 2, 106, 108, 7, 115, 2, 2, 107, 109, 7, 116, 2,
+	2, 108, 107, 3, 2, 2, 2, 108, 109, 3, 2, 2, 2, 109, 121, 3, 2, 2, 2, 110,
+	111, 7, 3, 2, 2, 111, 116, 5, 86, 44, 2, 112, 113, 7, 4, 2, 2, 113, 115,
+	5, 86, 44, 2, 114, 112, 3, 2, 2, 2, 115, 118, 3, 2, 2, 2, 116, 114, 3,
+	2, 2, 2, 116, 117, 3, 2, 2, 2, 117, 119, 3, 2, 2, 2, 118, 116, 3, 2, 2,
+	2, 119, 120, 7, 5, 2, 2, 120, 122, 3, 2, 2, 2, 121, 110, 3, 2, 2, 2, 121,
+	122, 3, 2, 2, 2, 122, 123, 3, 2, 2, 2, 123, 125, 5, 4, 3, 2, 124, 97, 3,
+	2, 2, 2, 124, 98, 3, 2, 2, 2, 124, 106, 3, 2, 2, 2, 125, 5, 3, 2, 2, 2,
+	126, 128, 5, 8, 5, 2, 127, 126, 3, 2, 2, 2, 127, 128, 3, 2, 2, 2, 128,
+	129, 3, 2, 2, 2, 129, 130, 5, 10, 6, 2, 130, 7, 3, 2, 2, 2, 131, 133, 7,
+	96, 2, 2, 132, 134, 7, 97, 2, 2, 133, 132, 3, 2, 2, 2, 133, 134, 3, 2,
+	2, 2, 134, 135, 3, 2, 2, 2, 135, 140, 5, 28, 15, 2, 136, 137, 7, 4, 2,
+	2, 137, 139, 5, 28, 15, 2, 138, 136, 3, 2, 2, 2, 139, 142, 3, 2, 2, 2,
+	140, 138, 3, 2, 2, 2, 140, 141, 3, 2, 2, 2, 141, 9, 3, 2, 2, 2, 142, 140,
+	3, 2, 2, 2, 143, 154, 5, 12, 7, 2, 144, 145, 7, 26, 2, 2, 145, 146, 7,
+	21, 2, 2, 146, 151, 5, 16, 9, 2, 147, 148, 7, 4, 2, 2, 148, 150, 5, 16,
+	9, 2, 149, 147, 3, 2, 2, 2, 150, 153, 3, 2, 2, 2, 151, 149, 3, 2, 2, 2,
+	151, 152, 3, 2, 2, 2, 152, 155, 3, 2, 2, 2, 153, 151, 3, 2, 2, 2, 154,
+	144, 3, 2, 2, 2, 154, 155, 3, 2, 2, 2, 155, 158, 3, 2, 2, 2, 156, 157,
+	7, 28, 2, 2, 157, 159, 7, 205, 2, 2, 158, 156, 3, 2, 2, 2, 158, 159, 3,
+	2, 2, 2, 159, 11, 3, 2, 2, 2, 160, 161, 8, 7, 1, 2, 161, 162, 5, 14, 8,
+	2, 162, 171, 3, 2, 2, 2, 163, 164, 12, 3, 2, 2, 164, 166, 9, 2, 2, 2, 165,
+	167, 5, 30, 16, 2, 166, 165, 3, 2, 2, 2, 166, 167, 3, 2, 2, 2, 167, 168,
+	3, 2, 2, 2, 168, 170, 5, 12, 7, 4, 169, 163, 3, 2, 2, 2, 170, 173, 3, 2,
+	2, 2, 171, 169, 3, 2, 2, 2, 171, 172, 3, 2, 2, 2, 172, 13, 3, 2, 2, 2,
+	173, 171, 3, 2, 2, 2, 174, 191, 5, 18, 10, 2, 175, 176, 7, 101, 2, 2, 176,
+	191, 5, 88, 45, 2, 177, 178, 7, 98, 2, 2, 178, 183, 5, 48, 25, 2, 179,
+	180, 7, 4, 2, 2, 180, 182, 5, 48, 25, 2, 181, 179, 3, 2, 2, 2, 182, 185,
+	3, 2, 2, 2, 183, 181, 3, 2, 2, 2, 183, 184, 3, 2, 2, 2, 184, 191, 3, 2,
+	2, 2, 185, 183, 3, 2, 2, 2, 186, 187, 7, 3, 2, 2, 187, 188, 5, 10, 6, 2,
+	188, 189, 7, 5, 2, 2, 189, 191, 3, 2, 2, 2, 190, 174, 3, 2, 2, 2, 190,
+	175, 3, 2, 2, 2, 190, 177, 3, 2, 2, 2, 190, 186, 3, 2, 2, 2, 191, 15, 3,
+	2, 2, 2, 192, 194, 5, 48, 25, 2, 193, 195, 9, 3, 2, 2, 194, 193, 3, 2,
+	2, 2, 194, 195, 3, 2, 2, 2, 195, 198, 3, 2, 2, 2, 196, 197, 7, 42, 2, 2,
+	197, 199, 9, 4, 2, 2, 198, 196, 3, 2, 2, 2, 198, 199, 3, 2, 2, 2, 199,
+	17, 3, 2, 2, 2, 200, 202, 7, 11, 2, 2, 201, 203, 5, 30, 16, 2, 202, 201,
+	3, 2, 2, 2, 202, 203, 3, 2, 2, 2, 203, 204, 3, 2, 2, 2, 204, 209, 5, 32,
+	17, 2, 205, 206, 7, 4, 2, 2, 206, 208, 5, 32, 17, 2, 207, 205, 3, 2, 2,
+	2, 208, 211, 3, 2, 2, 2, 209, 207, 3, 2, 2, 2, 209, 210, 3, 2, 2, 2, 210,
+	221, 3, 2, 2, 2, 211, 209, 3, 2, 2, 2, 212, 213, 7, 12, 2, 2, 213, 218,
+	5, 34, 18, 2, 214, 215, 7, 4, 2, 2, 215, 217, 5, 34, 18, 2, 216, 214, 3,
+	2, 2, 2, 217, 220, 3, 2, 2, 2, 218, 216, 3, 2, 2, 2, 218, 219, 3, 2, 2,
+	2, 219, 222, 3, 2, 2, 2, 220, 218, 3, 2, 2, 2, 221, 212, 3, 2, 2, 2, 221,
+	222, 3, 2, 2, 2, 222, 225, 3, 2, 2, 2, 223, 224, 7, 19, 2, 2, 224, 226,
+	5, 50, 26, 2, 225, 223, 3, 2, 2, 2, 225, 226, 3, 2, 2, 2, 226, 230, 3,
+	2, 2, 2, 227, 228, 7, 20, 2, 2, 228, 229, 7, 21, 2, 2, 229, 231, 5, 20,
+	11, 2, 230, 227, 3, 2, 2, 2, 230, 231, 3, 2, 2, 2, 231, 234, 3, 2, 2, 2,
+	232, 233, 7, 27, 2, 2, 233, 235, 5, 50, 26, 2, 234, 232, 3, 2, 2, 2, 234,
+	235, 3, 2, 2, 2, 235, 19, 3, 2, 2, 2, 236, 238, 5, 30, 16, 2, 237, 236,
+	3, 2, 2, 2, 237, 238, 3, 2, 2, 2, 238, 239, 3, 2, 2, 2, 239, 244, 5, 22,
+	12, 2, 240, 241, 7, 4, 2, 2, 241, 243, 5, 22, 12, 2, 242, 240, 3, 2, 2,
+	2, 243, 246, 3, 2, 2, 2, 244, 242, 3, 2, 2, 2, 244, 245, 3, 2, 2, 2, 245,
+	21, 3, 2, 2, 2, 246, 244, 3, 2, 2, 2, 247, 288, 5, 24, 13, 2, 248, 249,
+	7, 25, 2, 2, 249, 258, 7, 3, 2, 2, 250, 255, 5, 88, 45, 2, 251, 252, 7,
+	4, 2, 2, 252, 254, 5, 88, 45, 2, 253, 251, 3, 2, 2, 2, 254, 257, 3, 2,
+	2, 2, 255, 253, 3, 2, 2, 2, 255, 256, 3, 2, 2, 2, 256, 259, 3, 2, 2, 2,
+	257, 255, 3, 2, 2, 2, 258, 250, 3, 2, 2, 2, 258, 259, 3, 2, 2, 2, 259,
+	260, 3, 2, 2, 2, 260, 288, 7, 5, 2, 2, 261, 262, 7, 24, 2, 2, 262, 271,
+	7, 3, 2, 2, 263, 268, 5, 88, 45, 2, 264, 265, 7, 4, 2, 2, 265, 267, 5,
+	88, 45, 2, 266, 264, 3, 2, 2, 2, 267, 270, 3, 2, 2, 2, 268, 266, 3, 2,
+	2, 2, 268, 269, 3, 2, 2, 2, 269, 272, 3, 2, 2, 2, 270, 268, 3, 2, 2, 2,
+	271, 263, 3, 2, 2, 2, 271, 272, 3, 2, 2, 2, 272, 273, 3, 2, 2, 2, 273,
+	288, 7, 5, 2, 2, 274, 275, 7, 22, 2, 2, 275, 276, 7, 23, 2, 2, 276, 277,
+	7, 3, 2, 2, 277, 282, 5, 26, 14, 2, 278, 279, 7, 4, 2, 2, 279, 281, 5,
+	26, 14, 2, 280, 278, 3, 2, 2, 2, 281, 284, 3, 2, 2, 2, 282, 280, 3, 2,
+	2, 2, 282, 283, 3, 2, 2, 2, 283, 285, 3, 2, 2, 2, 284, 282, 3, 2, 2, 2,
+	285, 286, 7, 5, 2, 2, 286, 288, 3, 2, 2, 2, 287, 247, 3, 2, 2, 2, 287,
+	248, 3, 2, 2, 2, 287, 261, 3, 2, 2, 2, 287, 274, 3, 2, 2, 2, 288, 23, 3,
+	2, 2, 2, 289, 298, 7, 3, 2, 2, 290, 295, 5, 48, 25, 2, 291, 292, 7, 4,
+	2, 2, 292, 294, 5, 48, 25, 2, 293, 291, 3, 2, 2, 2, 294, 297, 3, 2, 2,
+	2, 295, 293, 3, 2, 2, 2, 295, 296, 3, 2, 2, 2, 296, 299, 3, 2, 2, 2, 297,
+	295, 3, 2, 2, 2, 298, 290, 3, 2, 2, 2, 298, 299, 3, 2, 2, 2, 299, 300,
+	3, 2, 2, 2, 300, 303, 7, 5, 2, 2, 301, 303, 5, 48, 25, 2, 302, 289, 3,
+	2, 2, 2, 302, 301, 3, 2, 2, 2, 303, 25, 3, 2, 2, 2, 304, 313, 7, 3, 2,
+	2, 305, 310, 5, 88, 45, 2, 306, 307, 7, 4, 2, 2, 307, 309, 5, 88, 45, 2,
+	308, 306, 3, 2, 2, 2, 309, 312, 3, 2, 2, 2, 310, 308, 3, 2, 2, 2, 310,
+	311, 3, 2, 2, 2, 311, 314, 3, 2, 2, 2, 312, 310, 3, 2, 2, 2, 313, 305,
+	3, 2, 2, 2, 313, 314, 3, 2, 2, 2, 314, 315, 3, 2, 2, 2, 315, 318, 7, 5,
+	2, 2, 316, 318, 5, 88, 45, 2, 317, 304, 3, 2, 2, 2, 317, 316, 3, 2, 2,
+	2, 318, 27, 3, 2, 2, 2, 319, 321, 5, 90, 46, 2, 320, 322, 5, 44, 23, 2,
+	321, 320, 3, 2, 2, 2, 321, 322, 3, 2, 2, 2, 322, 323, 3, 2, 2, 2, 323,
+	324, 7, 14, 2, 2, 324, 325, 7, 3, 2, 2, 325, 326, 5, 6, 4, 2, 326, 327,
+	7, 5, 2, 2, 327, 29, 3, 2, 2, 2, 328, 329, 9, 5, 2, 2, 329, 31, 3, 2, 2,
+	2, 330, 335, 5, 48, 25, 2, 331, 333, 7, 14, 2, 2, 332, 331, 3, 2, 2, 2,
+	332, 333, 3, 2, 2, 2, 333, 334, 3, 2, 2, 2, 334, 336, 5, 90, 46, 2, 335,
+	332, 3, 2, 2, 2, 335, 336, 3, 2, 2, 2, 336, 343, 3, 2, 2, 2, 337, 338,
+	5, 88, 45, 2, 338, 339, 7, 202, 2, 2, 339, 340, 7, 198, 2, 2, 340, 343,
+	3, 2, 2, 2, 341, 343, 7, 198, 2, 2, 342, 330, 3, 2, 2, 2, 342, 337, 3,
+	2, 2, 2, 342, 341, 3, 2, 2, 2, 343, 33, 3, 2, 2, 2, 344, 345, 8, 18, 1,
+	2, 345, 346, 5, 40, 21, 2, 346, 358, 3, 2, 2, 2, 347, 349, 12, 4, 2, 2,
+	348, 350, 5, 36, 19, 2, 349, 348, 3, 2, 2, 2, 349, 350, 3, 2, 2, 2, 350,
+	351, 3, 2, 2, 2, 351, 352, 7, 76, 2, 2, 352, 354, 5, 34, 18, 2, 353, 355,
+	5, 38, 20, 2, 354, 353, 3, 2, 2, 2, 354, 355, 3, 2, 2, 2, 355, 357, 3,
+	2, 2, 2, 356, 347, 3, 2, 2, 2, 357, 360, 3, 2, 2, 2, 358, 356, 3, 2, 2,
+	2, 358, 359, 3, 2, 2, 2, 359, 35, 3, 2, 2, 2, 360, 358, 3, 2, 2, 2, 361,
+	372, 7, 79, 2, 2, 362, 363, 7, 80, 2, 2, 363, 372, 7, 78, 2, 2, 364, 365,
+	7, 81, 2, 2, 365, 372, 7, 78, 2, 2, 366, 367, 7, 82, 2, 2, 367, 372, 7,
+	78, 2, 2, 368, 372, 7, 77, 2, 2, 369, 372, 7, 83, 2, 2, 370, 372, 7, 80,
+	2, 2, 371, 361, 3, 2, 2, 2, 371, 362, 3, 2, 2, 2, 371, 364, 3, 2, 2, 2,
+	371, 366, 3, 2, 2, 2, 371, 368, 3, 2, 2, 2, 371, 369, 3, 2, 2, 2, 371,
+	370, 3, 2, 2, 2, 372, 37, 3, 2, 2, 2, 373, 374, 7, 85, 2, 2, 374, 388,
+	5, 50, 26, 2, 375, 376, 7, 84, 2, 2, 376, 377, 7, 3, 2, 2, 377, 382, 5,
+	90, 46, 2, 378, 379, 7, 4, 2, 2, 379, 381, 5, 90, 46, 2, 380, 378, 3, 2,
+	2, 2, 381, 384, 3, 2, 2, 2, 382, 380, 3, 2, 2, 2, 382, 383, 3, 2, 2, 2,
+	383, 385, 3, 2, 2, 2, 384, 382, 3, 2, 2, 2, 385, 386, 7, 5, 2, 2, 386,
+	388, 3, 2, 2, 2, 387, 373, 3, 2, 2, 2, 387, 375, 3, 2, 2, 2, 388, 39, 3,
+	2, 2, 2, 389, 396, 5, 42, 22, 2, 390, 391, 7, 143, 2, 2, 391, 392, 9, 6,
+	2, 2, 392, 393, 7, 3, 2, 2, 393, 394, 5, 48, 25, 2, 394, 395, 7, 5, 2,
+	2, 395, 397, 3, 2, 2, 2, 396, 390, 3, 2, 2, 2, 396, 397, 3, 2, 2, 2, 397,
+	41, 3, 2, 2, 2, 398, 406, 5, 46, 24, 2, 399, 401, 7, 14, 2, 2, 400, 399,
+	3, 2, 2, 2, 400, 401, 3, 2, 2, 2, 401, 402, 3, 2, 2, 2, 402, 404, 5, 90,
+	46, 2, 403, 405, 5, 44, 23, 2, 404, 403, 3, 2, 2, 2, 404, 405, 3, 2, 2,
+	2, 405, 407, 3, 2, 2, 2, 406, 400, 3, 2, 2, 2, 406, 407, 3, 2, 2, 2, 407,
+	43, 3, 2, 2, 2, 408, 409, 7, 3, 2, 2, 409, 414, 5, 90, 46, 2, 410, 411,
+	7, 4, 2, 2, 411, 413, 5, 90, 46, 2, 412, 410, 3, 2, 2, 2, 413, 416, 3,
+	2, 2, 2, 414, 412, 3, 2, 2, 2, 414, 415, 3, 2, 2, 2, 415, 417, 3, 2, 2,
+	2, 416, 414, 3, 2, 2, 2, 417, 418, 7, 5, 2, 2, 418, 45, 3, 2, 2, 2, 419,
+	444, 5, 88, 45, 2, 420, 421, 7, 3, 2, 2, 421, 422, 5, 6, 4, 2, 422, 423,
+	7, 5, 2, 2, 423, 444, 3, 2, 2, 2, 424, 425, 7, 146, 2, 2, 425, 426, 7,
+	3, 2, 2, 426, 431, 5, 48, 25, 2, 427, 428, 7, 4, 2, 2, 428, 430, 5, 48,
+	25, 2, 429, 427, 3, 2, 2, 2, 430, 433, 3, 2, 2, 2, 431, 429, 3, 2, 2, 2,
+	431, 432, 3, 2, 2, 2, 432, 434, 3, 2, 2, 2, 433, 431, 3, 2, 2, 2, 434,
+	437, 7, 5, 2, 2, 435, 436, 7, 96, 2, 2, 436, 438, 7, 147, 2, 2, 437, 435,
+	3, 2, 2, 2, 437, 438, 3, 2, 2, 2, 438, 444, 3, 2, 2, 2, 439, 440, 7, 3,
+	2, 2, 440, 441, 5, 34, 18, 2, 441, 442, 7, 5, 2, 2, 442, 444, 3, 2, 2,
+	2, 443, 419, 3, 2, 2, 2, 443, 420, 3, 2, 2, 2, 443, 424, 3, 2, 2, 2, 443,
+	439, 3, 2, 2, 2, 444, 47, 3, 2, 2, 2, 445, 448, 5, 50, 26, 2, 446, 448,
+	5, 56, 29, 2, 447, 445, 3, 2, 2, 2, 447, 446, 3, 2, 2, 2, 448, 49, 3, 2,
+	2, 2, 449, 450, 8, 26, 1, 2, 450, 451, 5, 56, 29, 2, 451, 452, 5, 54, 28,
+	2, 452, 457, 3, 2, 2, 2, 453, 454, 7, 33, 2, 2, 454, 457, 5, 50, 26, 5,
+	455, 457, 5, 52, 27, 2, 456, 449, 3, 2, 2, 2, 456, 453, 3, 2, 2, 2, 456,
+	455, 3, 2, 2, 2, 457, 463, 3, 2, 2, 2, 458, 459, 12, 4, 2, 2, 459, 460,
+	9, 7, 2, 2, 460, 462, 5, 48, 25, 2, 461, 458, 3, 2, 2, 2, 462, 465, 3,
+	2, 2, 2, 463, 461, 3, 2, 2, 2, 463, 464, 3, 2, 2, 2, 464, 51, 3, 2, 2,
+	2, 465, 463, 3, 2, 2, 2, 466, 467, 9, 8, 2, 2, 467, 53, 3, 2, 2, 2, 468,
+	469, 5, 62, 32, 2, 469, 470, 5, 56, 29, 2, 470, 530, 3, 2, 2, 2, 471, 472,
+	5, 62, 32, 2, 472, 473, 5, 64, 33, 2, 473, 474, 7, 3, 2, 2, 474, 475, 5,
+	6, 4, 2, 475, 476, 7, 5, 2, 2, 476, 530, 3, 2, 2, 2, 477, 479, 7, 33, 2,
+	2, 478, 477, 3, 2, 2, 2, 478, 479, 3, 2, 2, 2, 479, 480, 3, 2, 2, 2, 480,
+	481, 7, 36, 2, 2, 481, 482, 5, 56, 29, 2, 482, 483, 7, 31, 2, 2, 483, 484,
+	5, 56, 29, 2, 484, 530, 3, 2, 2, 2, 485, 487, 7, 33, 2, 2, 486, 485, 3,
+	2, 2, 2, 486, 487, 3, 2, 2, 2, 487, 488, 3, 2, 2, 2, 488, 489, 7, 32, 2,
+	2, 489, 490, 7, 3, 2, 2, 490, 495, 5, 56, 29, 2, 491, 492, 7, 4, 2, 2,
+	492, 494, 5, 56, 29, 2, 493, 491, 3, 2, 2, 2, 494, 497, 3, 2, 2, 2, 495,
+	493, 3, 2, 2, 2, 495, 496, 3, 2, 2, 2, 496, 498, 3, 2, 2, 2, 497, 495,
+	3, 2, 2, 2, 498, 499, 7, 5, 2, 2, 499, 530, 3, 2, 2, 2, 500, 502, 7, 33,
+	2, 2, 501, 500, 3, 2, 2, 2, 501, 502, 3, 2, 2, 2, 502, 503, 3, 2, 2, 2,
+	503, 504, 7, 32, 2, 2, 504, 505, 7, 3, 2, 2, 505, 506, 5, 6, 4, 2, 506,
+	507, 7, 5, 2, 2, 507, 530, 3, 2, 2, 2, 508, 510, 7, 33, 2, 2, 509, 508,
+	3, 2, 2, 2, 509, 510, 3, 2, 2, 2, 510, 511, 3, 2, 2, 2, 511, 512, 7, 37,
+	2, 2, 512, 515, 5, 56, 29, 2, 513, 514, 7, 45, 2, 2, 514, 516, 5, 56, 29,
+	2, 515, 513, 3, 2, 2, 2, 515, 516, 3, 2, 2, 2, 516, 530, 3, 2, 2, 2, 517,
+	519, 7, 38, 2, 2, 518, 520, 7, 33, 2, 2, 519, 518, 3, 2, 2, 2, 519, 520,
+	3, 2, 2, 2, 520, 521, 3, 2, 2, 2, 521, 530, 7, 39, 2, 2, 522, 524, 7, 38,
+	2, 2, 523, 525, 7, 33, 2, 2, 524, 523, 3, 2, 2, 2, 524, 525, 3, 2, 2, 2,
+	525, 526, 3, 2, 2, 2, 526, 527, 7, 18, 2, 2, 527, 528, 7, 12, 2, 2, 528,
+	530, 5, 56, 29, 2, 529, 468, 3, 2, 2, 2, 529, 471, 3, 2, 2, 2, 529, 478,
+	3, 2, 2, 2, 529, 486, 3, 2, 2, 2, 529, 501, 3, 2, 2, 2, 529, 509, 3, 2,
+	2, 2, 529, 517, 3, 2, 2, 2, 529, 522, 3, 2, 2, 2, 530, 55, 3, 2, 2, 2,
+	531, 532, 8, 29, 1, 2, 532, 536, 5, 58, 30, 2, 533, 534, 9, 9, 2, 2, 534,
+	536, 5, 56, 29, 5, 535, 531, 3, 2, 2, 2, 535, 533, 3, 2, 2, 2, 536, 548,
+	3, 2, 2, 2, 537, 538, 12, 4, 2, 2, 538, 539, 9, 10, 2, 2, 539, 547, 5,
+	56, 29, 5, 540, 541, 12, 3, 2, 2, 541, 542, 7, 201, 2, 2, 542, 547, 5,
+	56, 29, 4, 543, 544, 12, 6, 2, 2, 544, 545, 7, 29, 2, 2, 545, 547, 5, 60,
+	31, 2, 546, 537, 3, 2, 2, 2, 546, 540, 3, 2, 2, 2, 546, 543, 3, 2, 2, 2,
+	547, 550, 3, 2, 2, 2, 548, 546, 3, 2, 2, 2, 548, 549, 3, 2, 2, 2, 549,
+	57, 3, 2, 2, 2, 550, 548, 3, 2, 2, 2, 551, 552, 8, 30, 1, 2, 552, 765,
+	7, 39, 2, 2, 553, 765, 7, 203, 2, 2, 554, 765, 7, 204, 2, 2, 555, 765,
+	7, 206, 2, 2, 556, 765, 7, 205, 2, 2, 557, 765, 5, 52, 27, 2, 558, 559,
+	5, 74, 38, 2, 559, 560, 7, 203, 2, 2, 560, 765, 3, 2, 2, 2, 561, 765, 5,
+	66, 34, 2, 562, 765, 7, 6, 2, 2, 563, 566, 5, 90, 46, 2, 564, 566, 7, 189,
+	2, 2, 565, 563, 3, 2, 2, 2, 565, 564, 3, 2, 2, 2, 566, 567, 3, 2, 2, 2,
+	567, 765, 7, 203, 2, 2, 568, 569, 7, 49, 2, 2, 569, 570, 7, 3, 2, 2, 570,
+	571, 5, 56, 29, 2, 571, 572, 7, 32, 2, 2, 572, 573, 5, 56, 29, 2, 573,
+	574, 7, 5, 2, 2, 574, 765, 3, 2, 2, 2, 575, 576, 7, 3, 2, 2, 576, 579,
+	5, 48, 25, 2, 577, 578, 7, 4, 2, 2, 578, 580, 5, 48, 25, 2, 579, 577, 3,
+	2, 2, 2, 580, 581, 3, 2, 2, 2, 581, 579, 3, 2, 2, 2, 581, 582, 3, 2, 2,
+	2, 582, 583, 3, 2, 2, 2, 583, 584, 7, 5, 2, 2, 584, 765, 3, 2, 2, 2, 585,
+	586, 7, 95, 2, 2, 586, 587, 7, 3, 2, 2, 587, 592, 5, 48, 25, 2, 588, 589,
+	7, 4, 2, 2, 589, 591, 5, 48, 25, 2, 590, 588, 3, 2, 2, 2, 591, 594, 3,
+	2, 2, 2, 592, 590, 3, 2, 2, 2, 592, 593, 3, 2, 2, 2, 593, 595, 3, 2, 2,
+	2, 594, 592, 3, 2, 2, 2, 595, 596, 7, 5, 2, 2, 596, 765, 3, 2, 2, 2, 597,
+	598, 5, 88, 45, 2, 598, 599, 7, 3, 2, 2, 599, 600, 7, 198, 2, 2, 600, 602,
+	7, 5, 2, 2, 601, 603, 5, 78, 40, 2, 602, 601, 3, 2, 2, 2, 602, 603, 3,
+	2, 2, 2, 603, 605, 3, 2, 2, 2, 604, 606, 5, 80, 41, 2, 605, 604, 3, 2,
+	2, 2, 605, 606, 3, 2, 2, 2, 606, 765, 3, 2, 2, 2, 607, 608, 5, 88, 45,
+	2, 608, 620, 7, 3, 2, 2, 609, 611, 5, 30, 16, 2, 610, 609, 3, 2, 2, 2,
+	610, 611, 3, 2, 2, 2, 611, 612, 3, 2, 2, 2, 612, 617, 5, 48, 25, 2, 613,
+	614, 7, 4, 2, 2, 614, 616, 5, 48, 25, 2, 615, 613, 3, 2, 2, 2, 616, 619,
+	3, 2, 2, 2, 617, 615, 3, 2, 2, 2, 617, 618, 3, 2, 2, 2, 618, 621, 3, 2,
+	2, 2, 619, 617, 3, 2, 2, 2, 620, 610, 3, 2, 2, 2, 620, 621, 3, 2, 2, 2,
+	621, 622, 3, 2, 2, 2, 622, 624, 7, 5, 2, 2, 623, 625, 5, 78, 40, 2, 624,
+	623, 3, 2, 2, 2, 624, 625, 3, 2, 2, 2, 625, 627, 3, 2, 2, 2, 626, 628,
+	5, 80, 41, 2, 627, 626, 3, 2, 2, 2, 627, 628, 3, 2, 2, 2, 628, 765, 3,
+	2, 2, 2, 629, 630, 5, 90, 46, 2, 630, 631, 7, 7, 2, 2, 631, 632, 5, 48,
+	25, 2, 632, 765, 3, 2, 2, 2, 633, 634, 7, 3, 2, 2, 634, 639, 5, 90, 46,
+	2, 635, 636, 7, 4, 2, 2, 636, 638, 5, 90, 46, 2, 637, 635, 3, 2, 2, 2,
+	638, 641, 3, 2, 2, 2, 639, 637, 3, 2, 2, 2, 639, 640, 3, 2, 2, 2, 640,
+	642, 3, 2, 2, 2, 641, 639, 3, 2, 2, 2, 642, 643, 7, 5, 2, 2, 643, 644,
+	7, 7, 2, 2, 644, 645, 5, 48, 25, 2, 645, 765, 3, 2, 2, 2, 646, 647, 7,
+	3, 2, 2, 647, 648, 5, 6, 4, 2, 648, 649, 7, 5, 2, 2, 649, 765, 3, 2, 2,
+	2, 650, 651, 7, 35, 2, 2, 651, 652, 7, 3, 2, 2, 652, 653, 5, 6, 4, 2, 653,
+	654, 7, 5, 2, 2, 654, 765, 3, 2, 2, 2, 655, 656, 7, 71, 2, 2, 656, 658,
+	5, 56, 29, 2, 657, 659, 5, 76, 39, 2, 658, 657, 3, 2, 2, 2, 659, 660, 3,
+	2, 2, 2, 660, 658, 3, 2, 2, 2, 660, 661, 3, 2, 2, 2, 661, 664, 3, 2, 2,
+	2, 662, 663, 7, 74, 2, 2, 663, 665, 5, 48, 25, 2, 664, 662, 3, 2, 2, 2,
+	664, 665, 3, 2, 2, 2, 665, 666, 3, 2, 2, 2, 666, 667, 7, 75, 2, 2, 667,
 	765, 3, 2, 2, 2, 668, 670, 7, 71, 2, 2, 669, 671, 5, 76, 39, 2, 670, 669,
 	3, 2, 2, 2, 671, 672, 3, 2, 2, 2, 672, 670, 3, 2, 2, 2, 672, 673, 3, 2,
 	2, 2, 673, 676, 3, 2, 2, 2, 674, 675, 7, 74, 2, 2, 675, 677, 5, 48, 25,
@@ -462,15 +463,16 @@ var parserATN = []uint16{
 	946, 7, 208, 2, 2, 942, 946, 7, 209, 2, 2, 943, 946, 7, 210, 2, 2, 944,
 	946, 5, 92, 47, 2, 945, 940, 3, 2, 2, 2, 945, 941, 3, 2, 2, 2, 945, 942,
 	3, 2, 2, 2, 945, 943, 3, 2, 2, 2, 945, 944, 3, 2, 2, 2, 946, 91, 3, 2,
-	2, 2, 947, 948, 9, 19, 2, 2, 948, 93, 3, 2, 2, 2, 119, 102, 108, 116, 121,
-	124, 127, 133, 140, 151, 154, 158, 166, 171, 183, 190, 194, 198, 202, 209,
-	218, 221, 225, 230, 234, 237, 244, 255, 258, 268, 271, 282, 287, 295, 298,
-	302, 310, 313, 317, 321, 332, 335, 342, 349, 354, 358, 371, 382, 387, 396,
-	400, 404, 406, 414, 431, 437, 443, 447, 456, 463, 478, 486, 495, 501, 509,
-	515, 519, 524, 529, 535, 546, 548, 565, 581, 592, 602, 605, 610, 617, 620,
-	624, 627, 639, 660, 664, 672, 676, 701, 704, 713, 719, 725, 731, 740, 749,
-	764, 774, 776, 785, 793, 799, 826, 838, 843, 845, 851, 856, 863, 885, 888,
-	897, 900, 903, 915, 924, 930, 937, 945,
+	2, 2, 947, 948, 9, 19, 2, 2, 948, 93, 3, 2, 2, 2, 950, 951, 7, 3, 2, 2,
+	951, 952, 5, 50, 26, 2, 952, 457, 7, 5, 2, 2, 456, 950, 3, 2, 2, 2, 119,
+	102, 108, 116, 121, 124, 127, 133, 140, 151, 154, 158, 166, 171, 183, 190,
+	194, 198, 202, 209, 218, 221, 225, 230, 234, 237, 244, 255, 258, 268, 271,
+	282, 287, 295, 298, 302, 310, 313, 317, 321, 332, 335, 342, 349, 354, 358,
+	371, 382, 387, 396, 400, 404, 406, 414, 431, 437, 443, 447, 456, 463, 478,
+	486, 495, 501, 509, 515, 519, 524, 529, 535, 546, 548, 565, 581, 592, 602,
+	605, 610, 617, 620, 624, 627, 639, 660, 664, 672, 676, 701, 704, 713, 719,
+	725, 731, 740, 749, 764, 774, 776, 785, 793, 799, 826, 838, 843, 845, 851,
+	856, 863, 885, 888, 897, 900, 903, 915, 924, 930, 937, 945,
 }
 var literalNames = []string{
 	"", "'('", "','", "')'", "'?'", "'->'", "'['", "']'", "';'", "", "", "",
@@ -6096,6 +6098,46 @@ func (s *BoolLiteralTooContext) ExitRule(listener antlr.ParseTreeListener) {
 	}
 }
 
+type ParenthesizedBooleanExpressionContext struct {
+	*BooleanExpressionContext
+}
+
+func NewParenthesizedBooleanExpressionContext(parser antlr.Parser, ctx antlr.ParserRuleContext) *ParenthesizedBooleanExpressionContext {
+	var p = new(ParenthesizedBooleanExpressionContext)
+
+	p.BooleanExpressionContext = NewEmptyBooleanExpressionContext()
+	p.parser = parser
+	p.CopyFrom(ctx.(*BooleanExpressionContext))
+
+	return p
+}
+
+func (s *ParenthesizedBooleanExpressionContext) GetRuleContext() antlr.RuleContext {
+	return s
+}
+
+func (s *ParenthesizedBooleanExpressionContext) BooleanExpression() IBooleanExpressionContext {
+	var t = s.GetTypedRuleContext(reflect.TypeOf((*IBooleanExpressionContext)(nil)).Elem(), 0)
+
+	if t == nil {
+		return nil
+	}
+
+	return t.(IBooleanExpressionContext)
+}
+
+func (s *ParenthesizedBooleanExpressionContext) EnterRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(SQLBaseListener); ok {
+		listenerT.EnterParenthesizedBooleanExpression(s)
+	}
+}
+
+func (s *ParenthesizedBooleanExpressionContext) ExitRule(listener antlr.ParseTreeListener) {
+	if listenerT, ok := listener.(SQLBaseListener); ok {
+		listenerT.ExitParenthesizedBooleanExpression(s)
+	}
+}
+
 type LogicalBinaryContext struct {
 	*BooleanExpressionContext
 	left     IBooleanExpressionContext
@@ -6241,6 +6283,23 @@ func (p *SQLBaseParser) booleanExpression(_p int) (localctx IBooleanExpressionCo
 			p.Booleanliteral()
 		}
 
+	case 4:
+		localctx = NewParenthesizedBooleanExpressionContext(p, localctx)
+		p.SetParserRuleContext(localctx)
+		_prevctx = localctx
+		{
+			p.SetState(948)
+			p.Match(SQLBaseParserT__0)
+		}
+		{
+			p.SetState(949)
+			p.booleanExpression(0)
+		}
+		{
+			p.SetState(950)
+			p.Match(SQLBaseParserT__2)
+		}
+
 	}
 	p.GetParserRuleContext().SetStop(p.GetTokenStream().LT(-1))
 	p.SetState(461)
@@ -11,9 +11,13 @@ import (
 	"github.com/alpacahq/marketstore/v4/uda/adjust"
 	"github.com/alpacahq/marketstore/v4/uda/avg"
 	"github.com/alpacahq/marketstore/v4/uda/count"
+	"github.com/alpacahq/marketstore/v4/uda/first"
 	"github.com/alpacahq/marketstore/v4/uda/gap"
+	"github.com/alpacahq/marketstore/v4/uda/last"
 	"github.com/alpacahq/marketstore/v4/uda/max"
 	"github.com/alpacahq/marketstore/v4/uda/min"
+	"github.com/alpacahq/marketstore/v4/uda/sum"
+	"github.com/alpacahq/marketstore/v4/uda/window"
 	"github.com/alpacahq/marketstore/v4/utils/functions"
 	"github.com/alpacahq/marketstore/v4/utils/io"
 )
@@ -38,8 +42,23 @@ func NewDefaultAggRunner(catDir *catalog.Directory) *AggRunner {
 			"min":           &min.Min{},
 			"max":           &max.Max{},
 			"avg":           &avg.Avg{},
+			"sum":           &sum.Sum{},
+			"first":         &first.First{},
+			"last":          &last.Last{},
 			"gap":           &gap.Gap{},
 			"adjust":        &adjust.Adjust{CatalogDir: catDir},
+			// Window functions, they keep the input rows
+			"sma":            &window.Function{Kind: window.SMA},
+			"ema":            &window.Function{Kind: window.EMA},
+			"rolling_sum":    &window.Function{Kind: window.RollingSum},
+			"rolling_stddev": &window.Function{Kind: window.RollingStdDev},
+			"rolling_min":    &window.Function{Kind: window.RollingMin},
+			"rolling_max":    &window.Function{Kind: window.RollingMax},
+			"lag":            &window.Function{Kind: window.Lag},
+			"lead":           &window.Function{Kind: window.Lead},
+			"pct_change":     &window.Function{Kind: window.PctChange},
+			"cumsum":         &window.Function{Kind: window.CumSum},
+			"vwap":           &window.Function{Kind: window.VWAP},
 		},
 	)
 }
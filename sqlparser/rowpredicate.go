@@ -0,0 +1,849 @@
+package sqlparser
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/planner"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+type rowPredicateKind uint8
+
+const (
+	_ rowPredicateKind = iota
+	logicalPredicate
+	literalPredicate
+	comparisonPredicate
+	betweenPredicate
+	inListPredicate
+	likePredicate
+	nullPredicate
+)
+
+/*
+RowPredicate is a boolean expression evaluated against every row of a
+materialized result, as opposed to the StaticPredicates that are resolved
+before the data is read. Interior nodes combine their children with AND / OR,
+leaf nodes compare an operand with literals or with other columns, test it
+against an IN list, match it with a LIKE pattern or test whether it is NULL.
+
+A NULL operand makes a comparison unknown: the row matches neither the
+condition nor its negation.
+*/
+type RowPredicate struct {
+	kind         rowPredicateKind
+	IsNot        bool
+	Operator     BinaryOperatorEnum // AND_OP or OR_OP for interior nodes
+	Left, Right  *RowPredicate
+	Value        bool // Result of a TRUE / FALSE literal
+	Operand      *RowOperand
+	Comparison   io.ComparisonOperatorEnum
+	Argument     *RowOperand    // Right hand side of a comparison
+	Lower, Upper *RowOperand    // Bounds of a BETWEEN
+	List         []*RowOperand  // Values of an IN list
+	Pattern      *regexp.Regexp // Compiled LIKE pattern
+}
+
+/*
+RowOperand is one side of a row level comparison, exactly one of the fields
+is set.
+*/
+type RowOperand struct {
+	ColumnName   string
+	FunctionCall *FunctionCallReference
+	Literal      *Literal
+	Expression   *ScalarExpression
+}
+
+func (ro *RowOperand) String() string {
+	switch {
+	case ro.FunctionCall != nil:
+		return ro.FunctionCall.Name
+	case ro.Expression != nil:
+		return ro.Expression.String()
+	case ro.Literal != nil:
+		return fmt.Sprint(ro.Literal.Value)
+	default:
+		return ro.ColumnName
+	}
+}
+
+/*
+columnResolver returns the name of the column that a column reference or a
+function call designates in the series being filtered.
+*/
+type columnResolver func(operand *RowOperand) (name string, err error)
+
+// Evaluate returns true for every row of the series satisfying the predicate.
+func (rp *RowPredicate) Evaluate(cs *io.ColumnSeries, resolve columnResolver) (match []bool, err error) {
+	match, _, err = rp.evaluate(cs, resolve)
+	return match, err
+}
+
+/*
+evaluate also returns the rows where the condition is unknown because of a
+NULL operand, or nil if there is none. An unknown row never matches, and the
+logical operators combine it following the three-valued logic of SQL so that
+a negation over a whole subtree keeps it unknown.
+*/
+func (rp *RowPredicate) evaluate(cs *io.ColumnSeries, resolve columnResolver) (match, unknown []bool, err error) {
+	length := cs.Len()
+	switch rp.kind {
+	case literalPredicate:
+		match = make([]bool, length)
+		for i := range match {
+			match[i] = rp.Value
+		}
+	case logicalPredicate:
+		var leftUnknown, right, rightUnknown []bool
+		match, leftUnknown, err = rp.Left.evaluate(cs, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		right, rightUnknown, err = rp.Right.evaluate(cs, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range match {
+			l, r := isUnknown(leftUnknown, i), isUnknown(rightUnknown, i)
+			var u bool
+			if rp.Operator == OR_OP {
+				// Unknown unless one side is true
+				u = (l || r) && !match[i] && !right[i]
+				match[i] = match[i] || right[i]
+			} else {
+				// Unknown unless one side is false
+				u = (l || r) && (match[i] || l) && (right[i] || r)
+				match[i] = match[i] && right[i]
+			}
+			if u {
+				if unknown == nil {
+					unknown = make([]bool, length)
+				}
+				unknown[i] = true
+			}
+		}
+	case comparisonPredicate:
+		left, err := newRowValues(cs, rp.Operand, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		right, err := newRowValues(cs, rp.Argument, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		match, err = compareRowValues(left, right, rp.Comparison, length)
+		if err != nil {
+			return nil, nil, err
+		}
+		unknown = unknownRows(length, left, right)
+	case betweenPredicate:
+		values, err := newRowValues(cs, rp.Operand, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		lower, err := newRowValues(cs, rp.Lower, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		upper, err := newRowValues(cs, rp.Upper, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		// BETWEEN excludes its bounds, just like the static predicates
+		match, err = compareRowValues(values, lower, io.GT, length)
+		if err != nil {
+			return nil, nil, err
+		}
+		below, err := compareRowValues(values, upper, io.LT, length)
+		if err != nil {
+			return nil, nil, err
+		}
+		for i := range match {
+			match[i] = match[i] && below[i]
+		}
+		unknown = unknownRows(length, values, lower, upper)
+	case inListPredicate:
+		values, err := newRowValues(cs, rp.Operand, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		match = make([]bool, length)
+		for _, item := range rp.List {
+			value, err := newRowValues(cs, item, resolve)
+			if err != nil {
+				return nil, nil, err
+			}
+			equal, err := compareRowValues(values, value, io.EQ, length)
+			if err != nil {
+				return nil, nil, err
+			}
+			for i := range match {
+				match[i] = match[i] || equal[i]
+			}
+		}
+		unknown = unknownRows(length, values)
+	case likePredicate:
+		values, err := newRowValues(cs, rp.Operand, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !values.isString {
+			return nil, nil, fmt.Errorf("LIKE needs a string operand, %s is not a string", rp.Operand)
+		}
+		match = make([]bool, length)
+		for i := range match {
+			match[i] = !values.isNull(i) && rp.Pattern.MatchString(values.strings[values.index(i)])
+		}
+		unknown = unknownRows(length, values)
+	case nullPredicate:
+		values, err := newRowValues(cs, rp.Operand, resolve)
+		if err != nil {
+			return nil, nil, err
+		}
+		match = make([]bool, length)
+		for i := range match {
+			match[i] = values.isNull(i)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported row predicate")
+	}
+	if rp.IsNot {
+		for i := range match {
+			match[i] = !match[i] && !isUnknown(unknown, i)
+		}
+	}
+	return match, unknown, nil
+}
+
+func isUnknown(unknown []bool, i int) bool {
+	return unknown != nil && unknown[i]
+}
+
+// unknownRows returns the rows where one of the values is NULL, or nil if there is none.
+func unknownRows(length int, values ...*rowValues) (unknown []bool) {
+	for _, rv := range values {
+		if rv.nulls == nil {
+			continue
+		}
+		if unknown == nil {
+			unknown = make([]bool, length)
+		}
+		for i := range unknown {
+			unknown[i] = unknown[i] || rv.isNull(i)
+		}
+	}
+	return unknown
+}
+
+/*
+rowValues holds an operand converted for comparison, either as numbers or as
+strings. A literal is stored as a scalar that applies to every row. The NULL
+values of a column of a nullable bucket are marked by nulls.
+*/
+type rowValues struct {
+	numbers  []float64
+	strings  []string
+	nulls    []bool
+	isString bool
+	isScalar bool
+}
+
+func (rv *rowValues) index(i int) int {
+	if rv.isScalar {
+		return 0
+	}
+	return i
+}
+
+func (rv *rowValues) isNull(i int) bool {
+	return rv.nulls != nil && rv.nulls[rv.index(i)]
+}
+
+// addNulls marks the rows where the column is NULL as NULL.
+func (rv *rowValues) addNulls(cs *io.ColumnSeries, name string) {
+	nulls := cs.Nulls(name)
+	if nulls == nil {
+		return
+	}
+	if rv.nulls == nil {
+		rv.nulls = make([]bool, len(nulls))
+	}
+	for i, isNull := range nulls {
+		rv.nulls[i] = rv.nulls[i] || isNull
+	}
+}
+
+func newRowValues(cs *io.ColumnSeries, operand *RowOperand, resolve columnResolver) (rv *rowValues, err error) {
+	rv = new(rowValues)
+	if operand.Literal != nil {
+		rv.isScalar = true
+		switch value := operand.Literal.Value.(type) {
+		case int64:
+			rv.numbers = []float64{float64(value)}
+		case float64:
+			rv.numbers = []float64{value}
+		case bool:
+			rv.numbers = []float64{0}
+			if value {
+				rv.numbers[0] = 1
+			}
+		case string:
+			if operand.Literal.Type != STRING_LITERAL {
+				return nil, fmt.Errorf("unsupported literal %s", value)
+			}
+			rv.isString = true
+			rv.strings = []string{value[1 : len(value)-1]} // Strip the quotes
+		default:
+			return nil, fmt.Errorf("unsupported literal %v", operand.Literal.Value)
+		}
+		return rv, nil
+	}
+	if operand.Expression != nil {
+		column, err := operand.Expression.Evaluate(cs, resolve)
+		if err != nil {
+			return nil, err
+		}
+		// An expression is NULL where one of its columns is
+		for _, columnName := range operand.Expression.ColumnNames() {
+			if name, err := resolve(&RowOperand{ColumnName: columnName}); err == nil {
+				rv.addNulls(cs, name)
+			}
+		}
+		rv.numbers, err = columnToFloat64(column)
+		return rv, err
+	}
+
+	name, err := resolve(operand)
+	if err != nil {
+		return nil, err
+	}
+	rv.addNulls(cs, name)
+	switch col := cs.GetColumn(name).(type) {
+	case []string:
+		rv.isString = true
+		rv.strings = col
+	case [][16]rune:
+		rv.isString = true
+		rv.strings = make([]string, len(col))
+		for i := range col {
+			rv.strings[i] = string(trimRunes(col[i]))
+		}
+	case [][]byte:
+		rv.isString = true
+		rv.strings = make([]string, len(col))
+		for i := range col {
+			rv.strings[i] = string(col[i])
+		}
+	case []int64:
+		if name == "Epoch" {
+			rv.numbers = epochSeconds(cs, col)
+			break
+		}
+		rv.numbers, _ = columnToFloat64(col)
+	case []io.Decimal64:
+		rv.numbers = io.DecimalsToFloat64(col, cs.Scale(name))
+	default:
+		rv.numbers, err = columnToFloat64(col)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", name, err)
+		}
+	}
+	return rv, nil
+}
+
+/*
+epochSeconds returns the time of every row in seconds, adding the Nanoseconds
+column of variable length buckets as a fraction
+*/
+func epochSeconds(cs *io.ColumnSeries, epochs []int64) (seconds []float64) {
+	nanoseconds, _ := cs.GetColumn("Nanoseconds").([]int32)
+	seconds = make([]float64, len(epochs))
+	for i, epoch := range epochs {
+		seconds[i] = float64(epoch)
+		if nanoseconds != nil {
+			seconds[i] += float64(nanoseconds[i]) / nanosec
+		}
+	}
+	return seconds
+}
+
+func columnToFloat64(iCol interface{}) (out []float64, err error) {
+	switch col := iCol.(type) {
+	case []float64:
+		return col, nil
+	case []float32:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []int:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []int8:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []int16:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []int32:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []int64:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []uint8:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []uint16:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []uint32:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []uint64:
+		out = make([]float64, len(col))
+		for i := range col {
+			out[i] = float64(col[i])
+		}
+	case []bool:
+		out = make([]float64, len(col))
+		for i := range col {
+			if col[i] {
+				out[i] = 1
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unable to compare values of type %T", iCol)
+	}
+	return out, nil
+}
+
+func compareRowValues(left, right *rowValues, op io.ComparisonOperatorEnum, length int) (match []bool, err error) {
+	if left.isString != right.isString {
+		return nil, fmt.Errorf("unable to compare a string with a number")
+	}
+	match = make([]bool, length)
+	for i := range match {
+		if left.isNull(i) || right.isNull(i) { // NULL never matches
+			continue
+		}
+		var c int
+		if left.isString {
+			l, r := left.strings[left.index(i)], right.strings[right.index(i)]
+			switch {
+			case l < r:
+				c = -1
+			case l > r:
+				c = 1
+			}
+		} else {
+			l, r := left.numbers[left.index(i)], right.numbers[right.index(i)]
+			if math.IsNaN(l) || math.IsNaN(r) { // NaN never matches
+				continue
+			}
+			c = compareFloat64(l, r)
+		}
+		switch op {
+		case io.EQ:
+			match[i] = c == 0
+		case io.NEQ:
+			match[i] = c != 0
+		case io.LT:
+			match[i] = c < 0
+		case io.LTE:
+			match[i] = c <= 0
+		case io.GT:
+			match[i] = c > 0
+		case io.GTE:
+			match[i] = c >= 0
+		default:
+			return nil, fmt.Errorf("unsupported comparison operator %s", op)
+		}
+	}
+	return match, nil
+}
+
+/*
+newRowPredicate builds a row predicate from a boolean expression, using the
+visitor only to resolve the value expressions at the leaves.
+*/
+func (es *ExecutableStatement) newRowPredicate(tree IMSTree) (rp *RowPredicate, err error) {
+	switch ctx := tree.(type) {
+	case *ExpressionParse:
+		return es.newRowPredicate(ctx.GetChild(0))
+	case *ValueExpressionParse:
+		// A parenthesized boolean expression
+		if pe, ok := ctx.GetChild(0).(*PrimaryExpressionParse); ok && pe.primaryType == PARENTHESIZED_EXPRESSION {
+			return es.newRowPredicate(pe.GetChild(0))
+		}
+		return nil, fmt.Errorf("value expression used as a predicate")
+	case *BooleanExpressionParse:
+		rp = &RowPredicate{IsNot: ctx.IsNot}
+		switch {
+		case ctx.IsLiteral:
+			rp.kind = literalPredicate
+			rp.Value = ctx.value
+		case ctx.right != nil:
+			rp.kind = logicalPredicate
+			rp.Operator = ctx.operator
+			if rp.Left, err = es.newRowPredicate(ctx.left); err != nil {
+				return nil, err
+			}
+			if rp.Right, err = es.newRowPredicate(ctx.right); err != nil {
+				return nil, err
+			}
+		default:
+			if rp.Operand, err = es.newRowOperand(ctx.left); err != nil {
+				return nil, err
+			}
+			if err = es.addRowPredicateCondition(rp, ctx.predicate); err != nil {
+				return nil, err
+			}
+			if err = rp.coerceEpochLiterals(); err != nil {
+				return nil, err
+			}
+		}
+		return rp, nil
+	default:
+		return nil, fmt.Errorf("unsupported boolean expression")
+	}
+}
+
+func (es *ExecutableStatement) addRowPredicateCondition(rp *RowPredicate, predicate IMSTree) (err error) {
+	if predicate == nil {
+		return fmt.Errorf("missing predicate for %s", rp.Operand)
+	}
+	switch ctx := predicate.GetChild(0).(type) {
+	case *ComparisonParse:
+		rp.kind = comparisonPredicate
+		rp.Comparison = ctx.comparisonOperator
+		rp.Argument, err = es.newRowOperand(ctx.right)
+	case *BetweenParse:
+		rp.kind = betweenPredicate
+		if ctx.IsNot {
+			rp.IsNot = !rp.IsNot
+		}
+		if rp.Lower, err = es.newRowOperand(ctx.lower); err != nil {
+			return err
+		}
+		rp.Upper, err = es.newRowOperand(ctx.upper)
+	case *InListParse:
+		rp.kind = inListPredicate
+		if ctx.IsNot {
+			rp.IsNot = !rp.IsNot
+		}
+		for _, item := range ctx.inlist {
+			operand, err := es.newRowOperand(item)
+			if err != nil {
+				return err
+			}
+			rp.List = append(rp.List, operand)
+		}
+	case *LikeParse:
+		rp.kind = likePredicate
+		if ctx.IsNot {
+			rp.IsNot = !rp.IsNot
+		}
+		pattern, err := es.newStringLiteral(ctx.pattern, "LIKE pattern")
+		if err != nil {
+			return err
+		}
+		var escape string
+		if ctx.escape != nil {
+			if escape, err = es.newStringLiteral(ctx.escape, "LIKE escape"); err != nil {
+				return err
+			}
+			if len([]rune(escape)) != 1 {
+				return fmt.Errorf("LIKE escape must be a single character")
+			}
+		}
+		rp.Pattern, err = likeToRegexp(pattern, escape)
+		return err
+	case *NullPredicateParse:
+		rp.kind = nullPredicate
+		if ctx.IsNot {
+			rp.IsNot = !rp.IsNot
+		}
+	case *InSubqueryParse, *QuantifiedComparisonParse:
+		return fmt.Errorf("subqueries are not supported in predicates")
+	default:
+		return fmt.Errorf("unsupported predicate type for %s", rp.Operand)
+	}
+	return err
+}
+
+// newStringLiteral returns the unquoted value of a string literal.
+func (es *ExecutableStatement) newStringLiteral(tree IMSTree, what string) (value string, err error) {
+	literal, ok := es.nodeCursor.Visit(tree).(*Literal)
+	if !ok || literal.Type != STRING_LITERAL {
+		return "", fmt.Errorf("%s must be a string literal", what)
+	}
+	//nolint:forcetypeassert // string literals are always stored as strings
+	value = literal.Value.(string)
+	return value[1 : len(value)-1], nil // Strip the quotes
+}
+
+/*
+likeToRegexp translates a LIKE pattern, where % matches any run of characters
+and _ matches a single one, unless preceded by the escape character
+*/
+func likeToRegexp(pattern, escape string) (*regexp.Regexp, error) {
+	var buffer strings.Builder
+	buffer.WriteString("(?s)^")
+	var escaped bool
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			buffer.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case string(r) == escape:
+			escaped = true
+		case r == '%':
+			buffer.WriteString(".*")
+		case r == '_':
+			buffer.WriteString(".")
+		default:
+			buffer.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if escaped {
+		return nil, fmt.Errorf("LIKE pattern %s ends with the escape character", pattern)
+	}
+	buffer.WriteString("$")
+	return regexp.Compile(buffer.String())
+}
+
+/*
+coerceEpochLiterals converts the literals compared with Epoch to seconds, so
+that date strings like '2000-01-05-12:00' and epochs in nanoseconds can be
+used just like in the static predicates
+*/
+func (rp *RowPredicate) coerceEpochLiterals() error {
+	operands := append([]*RowOperand{rp.Operand, rp.Argument, rp.Lower, rp.Upper}, rp.List...)
+	var hasEpoch bool
+	for _, operand := range operands {
+		if operand != nil && operand.ColumnName == "Epoch" {
+			hasEpoch = true
+		}
+	}
+	if !hasEpoch {
+		return nil
+	}
+	for _, operand := range operands {
+		if operand == nil || operand.Literal == nil {
+			continue
+		}
+		literal := NewLiteral(operand.Literal.Value, operand.Literal.Type)
+		if err := CoerceToNumeric(literal); err != nil {
+			return err
+		}
+		if epoch, ok := literal.Value.(int64); ok {
+			seconds := float64(epoch)
+			if isNanosec(epoch) {
+				seconds /= nanosec
+			}
+			operand.Literal = NewLiteral(seconds, DECIMAL_LITERAL)
+		}
+	}
+	return nil
+}
+
+// references returns true if the predicate uses the named column.
+func (rp *RowPredicate) references(columnName string) bool {
+	if rp == nil {
+		return false
+	}
+	if rp.Left.references(columnName) || rp.Right.references(columnName) {
+		return true
+	}
+	for _, operand := range append([]*RowOperand{rp.Operand, rp.Argument, rp.Lower, rp.Upper}, rp.List...) {
+		if operand != nil && operand.ColumnName == columnName {
+			return true
+		}
+		if operand != nil && operand.Expression != nil {
+			for _, name := range operand.Expression.ColumnNames() {
+				if name == columnName {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+/*
+columnRanges returns the bounds of the columns compared with numeric literals by
+the terms of the top level AND of the predicate, which are pushed down to the
+read so that it can skip the blocks of rows that can not match
+*/
+func (rp *RowPredicate) columnRanges() (ranges []planner.ColumnRange) {
+	if rp == nil || rp.IsNot {
+		return nil
+	}
+	switch rp.kind {
+	case logicalPredicate:
+		if rp.Operator == AND_OP {
+			return append(rp.Left.columnRanges(), rp.Right.columnRanges()...)
+		}
+	case comparisonPredicate:
+		column, argument, op := rp.Operand, rp.Argument, rp.Comparison
+		if column.ColumnName == "" {
+			// the literal is on the left: 5 < Col is Col > 5
+			column, argument = argument, column
+			switch op {
+			case io.LT:
+				op = io.GT
+			case io.LTE:
+				op = io.GTE
+			case io.GT:
+				op = io.LT
+			case io.GTE:
+				op = io.LTE
+			}
+		}
+		value, ok := numericLiteral(argument)
+		if column.ColumnName == "" || !ok {
+			return nil
+		}
+		cr := planner.ColumnRange{Name: column.ColumnName, Min: math.Inf(-1), Max: math.Inf(1)}
+		switch op {
+		case io.EQ:
+			cr.Min, cr.Max = value, value
+		case io.LT, io.LTE:
+			cr.Max = value
+		case io.GT, io.GTE:
+			cr.Min = value
+		default:
+			return nil
+		}
+		return []planner.ColumnRange{cr}
+	case betweenPredicate:
+		lower, okLower := numericLiteral(rp.Lower)
+		upper, okUpper := numericLiteral(rp.Upper)
+		if rp.Operand.ColumnName != "" && okLower && okUpper {
+			return []planner.ColumnRange{{Name: rp.Operand.ColumnName, Min: lower, Max: upper}}
+		}
+	}
+	return nil
+}
+
+func numericLiteral(operand *RowOperand) (value float64, ok bool) {
+	if operand.Literal == nil {
+		return 0, false
+	}
+	switch v := operand.Literal.Value.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// andRowPredicates combines two predicates with AND, either may be nil.
+func andRowPredicates(left, right *RowPredicate) *RowPredicate {
+	switch {
+	case left == nil:
+		return right
+	case right == nil:
+		return left
+	}
+	return &RowPredicate{kind: logicalPredicate, Operator: AND_OP, Left: left, Right: right}
+}
+
+/*
+splitConjuncts breaks a boolean expression into the terms of its top level
+AND, looking through parentheses
+*/
+func splitConjuncts(tree IMSTree) (conjuncts []IMSTree) {
+	switch ctx := tree.(type) {
+	case *ExpressionParse:
+		return splitConjuncts(ctx.GetChild(0))
+	case *ValueExpressionParse:
+		if pe, ok := ctx.GetChild(0).(*PrimaryExpressionParse); ok && pe.primaryType == PARENTHESIZED_EXPRESSION {
+			return splitConjuncts(pe.GetChild(0))
+		}
+	case *BooleanExpressionParse:
+		if ctx.right != nil && ctx.operator == AND_OP && !ctx.IsNot {
+			return append(splitConjuncts(ctx.left), splitConjuncts(ctx.right)...)
+		}
+	}
+	return []IMSTree{tree}
+}
+
+/*
+applyWhere removes the rows of the series failing the row level WHERE
+predicate. The Symbol of the bucket key can be used like a column.
+*/
+func (sr *SelectRelation) applyWhere(cs *io.ColumnSeries, key *io.TimeBucketKey) (err error) {
+	if cs.Len() == 0 {
+		return nil
+	}
+	if key != nil && !cs.Exists("Symbol") && sr.WherePredicate.references("Symbol") {
+		symbol := toString16(key.GetItemInCategory("Symbol"))
+		symbols := make([][16]rune, cs.Len())
+		for i := range symbols {
+			symbols[i] = symbol
+		}
+		cs.AddColumn("Symbol", symbols)
+		defer func() {
+			if err2 := cs.Remove("Symbol"); err == nil {
+				err = err2
+			}
+		}()
+	}
+	match, err := sr.WherePredicate.Evaluate(cs, func(operand *RowOperand) (string, error) {
+		if operand.FunctionCall != nil {
+			return "", fmt.Errorf("function %s is not allowed in WHERE", operand.FunctionCall.Name)
+		}
+		if !cs.Exists(operand.ColumnName) {
+			return "", fmt.Errorf("WHERE column %s not found in source table", operand.ColumnName)
+		}
+		return operand.ColumnName, nil
+	})
+	if err != nil {
+		return err
+	}
+	for i := range match {
+		match[i] = !match[i] // The bitmap marks the rows to remove
+	}
+	return cs.RestrictViaBitmap(match)
+}
+
+func (es *ExecutableStatement) newRowOperand(tree IMSTree) (operand *RowOperand, err error) {
+	switch value := es.nodeCursor.Visit(tree).(type) {
+	case *ColumnReference:
+		return &RowOperand{ColumnName: value.GetName()}, nil
+	case *FunctionCallReference:
+		return &RowOperand{FunctionCall: value}, nil
+	case *Literal:
+		return &RowOperand{Literal: value}, nil
+	case *ScalarExpression:
+		return &RowOperand{Expression: value}, nil
+	case error:
+		return nil, value
+	default:
+		return nil, fmt.Errorf("unsupported operand in predicate")
+	}
+}
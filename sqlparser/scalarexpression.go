@@ -0,0 +1,634 @@
+package sqlparser
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+type scalarExpressionKind uint8
+
+const (
+	_ scalarExpressionKind = iota
+	operandExpression
+	arithmeticExpression
+	negateExpression
+	castExpression
+	functionExpression
+)
+
+/*
+ScalarExpression is a value computed for every row of a result, like the
+computed columns of a select list:
+
+	SELECT Epoch, (High-Low)/Close AS range_pct, log(Close) FROM `AAPL/1Min/OHLCV`
+
+Interior nodes apply an arithmetic operator, a CAST or a scalar function to
+their arguments, the leaves are columns or literals. Only numeric values are
+supported.
+*/
+type ScalarExpression struct {
+	kind         scalarExpressionKind
+	Operand      *RowOperand // Column or literal of a leaf
+	Operator     ArithmeticOperatorEnum
+	Args         []*ScalarExpression // Arguments of an operator, a CAST or a function
+	CastType     io.EnumElementType
+	FunctionName string
+}
+
+/*
+scalarValues is the result of an expression, one value per row. A literal
+takes the type of the column it is combined with, so that Close * 2 is still a
+FLOAT32 when Close is.
+*/
+type scalarValues struct {
+	column      interface{}
+	elementType io.EnumElementType
+	isLiteral   bool
+}
+
+func (se *ScalarExpression) String() string {
+	var args []string
+	for _, arg := range se.Args {
+		args = append(args, arg.String())
+	}
+	switch se.kind {
+	case arithmeticExpression:
+		return "(" + args[0] + " " + arithmeticOperators[se.Operator] + " " + args[1] + ")"
+	case negateExpression:
+		return "-" + args[0]
+	case castExpression:
+		return "CAST(" + args[0] + " AS " + se.CastType.String() + ")"
+	case functionExpression:
+		return se.FunctionName + "(" + strings.Join(args, ", ") + ")"
+	default:
+		return se.Operand.String()
+	}
+}
+
+var arithmeticOperators = map[ArithmeticOperatorEnum]string{
+	MINUS:    "-",
+	PLUS:     "+",
+	MULTIPLY: "*",
+	DIVIDE:   "/",
+	PERCENT:  "%",
+}
+
+// ColumnNames returns the columns used by the expression.
+func (se *ScalarExpression) ColumnNames() (names []string) {
+	if se.kind == operandExpression {
+		if len(se.Operand.ColumnName) != 0 {
+			names = append(names, se.Operand.ColumnName)
+		}
+		return names
+	}
+	for _, arg := range se.Args {
+		names = append(names, arg.ColumnNames()...)
+	}
+	return names
+}
+
+// Evaluate returns a column holding the value of the expression for every row of the series.
+func (se *ScalarExpression) Evaluate(cs *io.ColumnSeries, resolve columnResolver) (column interface{}, err error) {
+	values, err := se.evaluate(cs, resolve)
+	if err != nil {
+		return nil, err
+	}
+	return values.column, nil
+}
+
+func (se *ScalarExpression) evaluate(cs *io.ColumnSeries, resolve columnResolver) (values *scalarValues, err error) {
+	if se.kind == operandExpression {
+		return newScalarValues(cs, se.Operand, resolve)
+	}
+	args := make([]*scalarValues, len(se.Args))
+	for i, arg := range se.Args {
+		if args[i], err = arg.evaluate(cs, resolve); err != nil {
+			return nil, err
+		}
+	}
+	switch se.kind {
+	case arithmeticExpression:
+		return applyArithmetic(se.Operator, args[0], args[1])
+	case negateExpression:
+		return applyArithmetic(MINUS, literalValues(int64(0), cs.Len()), args[0])
+	case castExpression:
+		column, err := coerceColumn(args[0].column, se.CastType)
+		if err != nil {
+			return nil, err
+		}
+		return &scalarValues{column: column, elementType: io.GetElementType(column)}, nil
+	default:
+		return scalarFunctions[se.FunctionName].evaluate(args)
+	}
+}
+
+func newScalarValues(cs *io.ColumnSeries, operand *RowOperand, resolve columnResolver,
+) (values *scalarValues, err error) {
+	if operand.Literal != nil {
+		switch value := operand.Literal.Value.(type) {
+		case int64, float64:
+			return literalValues(value, cs.Len()), nil
+		default:
+			return nil, fmt.Errorf("unsupported literal %v in expression, only numbers are supported",
+				operand.Literal.Value)
+		}
+	}
+	name, err := resolve(operand)
+	if err != nil {
+		return nil, err
+	}
+	column := cs.GetColumn(name)
+	if decimals, ok := column.([]io.Decimal64); ok {
+		// the decimals are computed as floats
+		return &scalarValues{column: io.DecimalsToFloat64(decimals, cs.Scale(name)), elementType: io.FLOAT64}, nil
+	}
+	elementType := io.GetElementType(column)
+	if !isNumericType(elementType) {
+		return nil, fmt.Errorf("column %s of type %s can not be used in an expression", name, elementType)
+	}
+	return &scalarValues{column: column, elementType: elementType}, nil
+}
+
+// literalValues repeats a numeric literal for every row.
+func literalValues(value interface{}, length int) *scalarValues {
+	if number, ok := value.(float64); ok {
+		column := make([]float64, length)
+		for i := range column {
+			column[i] = number
+		}
+		return &scalarValues{column: column, elementType: io.FLOAT64, isLiteral: true}
+	}
+	//nolint:forcetypeassert // only int64 and float64 literals are numbers
+	number := value.(int64)
+	column := make([]int64, length)
+	for i := range column {
+		column[i] = number
+	}
+	return &scalarValues{column: column, elementType: io.INT64, isLiteral: true}
+}
+
+func isNumericType(elementType io.EnumElementType) bool {
+	switch elementType {
+	case io.FLOAT32, io.FLOAT64, io.INT64, io.EPOCH, io.INT32, io.INT16, io.BYTE,
+		io.UINT8, io.UINT16, io.UINT32, io.UINT64:
+		return true
+	}
+	return false
+}
+
+func isFloatType(elementType io.EnumElementType) bool {
+	return elementType == io.FLOAT32 || elementType == io.FLOAT64
+}
+
+func isUnsignedType(elementType io.EnumElementType) bool {
+	switch elementType {
+	case io.UINT8, io.UINT16, io.UINT32, io.UINT64:
+		return true
+	}
+	return false
+}
+
+/*
+promoteTypes returns the type of an operation between two values: the
+smallest type holding both of them, as numpy does for the columns read by the
+clients. A literal takes the type of the column, unless a decimal number is
+combined with an integer column.
+*/
+func promoteTypes(left, right *scalarValues) io.EnumElementType {
+	a, b := left.elementType, right.elementType
+	switch {
+	case left.isLiteral && !right.isLiteral:
+		if isFloatType(a) && !isFloatType(b) {
+			return io.FLOAT64
+		}
+		return b
+	case right.isLiteral && !left.isLiteral:
+		if isFloatType(b) && !isFloatType(a) {
+			return io.FLOAT64
+		}
+		return a
+	}
+	switch {
+	case a == b:
+		return a
+	case isFloatType(a) || isFloatType(b):
+		if a == io.FLOAT64 || b == io.FLOAT64 {
+			return io.FLOAT64
+		}
+		// A FLOAT32 only holds integers of up to 16 bits exactly
+		other := a
+		if other == io.FLOAT32 {
+			other = b
+		}
+		if other.Size() <= 2 {
+			return io.FLOAT32
+		}
+		return io.FLOAT64
+	case isUnsignedType(a) == isUnsignedType(b):
+		if a.Size() >= b.Size() {
+			return a
+		}
+		return b
+	default:
+		// A signed type larger than the unsigned one
+		signed, unsigned := a, b
+		if isUnsignedType(signed) {
+			signed, unsigned = b, a
+		}
+		size := signed.Size()
+		if 2*unsigned.Size() > size {
+			size = 2 * unsigned.Size()
+		}
+		switch size {
+		case 2:
+			return io.INT16
+		case 4:
+			return io.INT32
+		default:
+			return io.INT64
+		}
+	}
+}
+
+/*
+coerceColumn converts the values of a column to another type, with the same
+conversions as io.ColumnSeries.CoerceColumnType.
+*/
+func coerceColumn(column interface{}, elementType io.EnumElementType) (interface{}, error) {
+	if elementType == io.EPOCH {
+		elementType = io.INT64
+	}
+	current := io.GetElementType(column)
+	if !isNumericType(current) {
+		return nil, fmt.Errorf("unable to cast a value of type %s", current)
+	}
+	if current == elementType {
+		return column, nil
+	}
+	cs := io.NewColumnSeries()
+	cs.AddColumn("value", column)
+	if err := cs.CoerceColumnType("value", elementType); err != nil {
+		return nil, err
+	}
+	return cs.GetColumn("value"), nil
+}
+
+func float64Values(values *scalarValues) []float64 {
+	column, _ := coerceColumn(values.column, io.FLOAT64)
+	//nolint:forcetypeassert // the values are always numeric
+	return column.([]float64)
+}
+
+func int64Values(values *scalarValues) []int64 {
+	column, _ := coerceColumn(values.column, io.INT64)
+	//nolint:forcetypeassert // the values are always numeric
+	return column.([]int64)
+}
+
+// typedValues converts the result of an operation computed in 64 bits to its type.
+func typedValues(column interface{}, elementType io.EnumElementType, isLiteral bool) (*scalarValues, error) {
+	column, err := coerceColumn(column, elementType)
+	if err != nil {
+		return nil, err
+	}
+	return &scalarValues{column: column, elementType: elementType, isLiteral: isLiteral}, nil
+}
+
+/*
+applyArithmetic computes an operation in float64 for floating point types and
+in int64 for integers, where the division truncates like in SQL.
+*/
+func applyArithmetic(op ArithmeticOperatorEnum, left, right *scalarValues) (*scalarValues, error) {
+	elementType := promoteTypes(left, right)
+	isLiteral := left.isLiteral && right.isLiteral
+	if isFloatType(elementType) {
+		l, r := float64Values(left), float64Values(right)
+		out := make([]float64, len(l))
+		for i := range out {
+			switch op {
+			case PLUS:
+				out[i] = l[i] + r[i]
+			case MINUS:
+				out[i] = l[i] - r[i]
+			case MULTIPLY:
+				out[i] = l[i] * r[i]
+			case DIVIDE:
+				out[i] = l[i] / r[i]
+			case PERCENT:
+				out[i] = math.Mod(l[i], r[i])
+			}
+		}
+		return typedValues(out, elementType, isLiteral)
+	}
+	l, r := int64Values(left), int64Values(right)
+	out := make([]int64, len(l))
+	for i := range out {
+		switch op {
+		case PLUS:
+			out[i] = l[i] + r[i]
+		case MINUS:
+			out[i] = l[i] - r[i]
+		case MULTIPLY:
+			out[i] = l[i] * r[i]
+		case DIVIDE, PERCENT:
+			if r[i] == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			if op == DIVIDE {
+				out[i] = l[i] / r[i]
+			} else {
+				out[i] = l[i] % r[i]
+			}
+		}
+	}
+	return typedValues(out, elementType, isLiteral)
+}
+
+/*
+scalarFunction is a function of the select list computing one value per row,
+as opposed to the aggregates of the UDA registry.
+*/
+type scalarFunction struct {
+	minArgs, maxArgs int
+	evaluate         func(args []*scalarValues) (*scalarValues, error)
+}
+
+var scalarFunctions = map[string]scalarFunction{
+	"abs":         {1, 1, keepTypeFunction(math.Abs)},
+	"sign":        {1, 1, keepTypeFunction(sign)},
+	"round":       {1, 2, roundFunction},
+	"floor":       {1, 1, keepTypeFunction(math.Floor)},
+	"ceil":        {1, 1, keepTypeFunction(math.Ceil)},
+	"ceiling":     {1, 1, keepTypeFunction(math.Ceil)},
+	"sqrt":        {1, 1, mathFunction(math.Sqrt)},
+	"cbrt":        {1, 1, mathFunction(math.Cbrt)},
+	"exp":         {1, 1, mathFunction(math.Exp)},
+	"ln":          {1, 1, mathFunction(math.Log)},
+	"log":         {1, 2, logFunction},
+	"log10":       {1, 1, mathFunction(math.Log10)},
+	"log2":        {1, 1, mathFunction(math.Log2)},
+	"pow":         {2, 2, math2Function(math.Pow)},
+	"power":       {2, 2, math2Function(math.Pow)},
+	"year":        {1, 1, timeFunction(func(t time.Time) int { return t.Year() })},
+	"month":       {1, 1, timeFunction(func(t time.Time) int { return int(t.Month()) })},
+	"day":         {1, 1, timeFunction(func(t time.Time) int { return t.Day() })},
+	"hour":        {1, 1, timeFunction(func(t time.Time) int { return t.Hour() })},
+	"minute":      {1, 1, timeFunction(func(t time.Time) int { return t.Minute() })},
+	"second":      {1, 1, timeFunction(func(t time.Time) int { return t.Second() })},
+	"day_of_week": {1, 1, timeFunction(dayOfWeek)},
+	"day_of_year": {1, 1, timeFunction(func(t time.Time) int { return t.YearDay() })},
+}
+
+func isScalarFunction(name string) bool {
+	_, ok := scalarFunctions[strings.ToLower(name)]
+	return ok
+}
+
+func sign(x float64) float64 {
+	switch {
+	case x > 0:
+		return 1
+	case x < 0:
+		return -1
+	}
+	return x
+}
+
+// dayOfWeek returns the ISO day of the week, from 1 for Monday to 7 for Sunday.
+func dayOfWeek(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+// mathFunction applies a function to every value, the result is a FLOAT64.
+func mathFunction(f func(float64) float64) func(args []*scalarValues) (*scalarValues, error) {
+	return func(args []*scalarValues) (*scalarValues, error) {
+		x := float64Values(args[0])
+		out := make([]float64, len(x))
+		for i := range out {
+			out[i] = f(x[i])
+		}
+		return &scalarValues{column: out, elementType: io.FLOAT64, isLiteral: args[0].isLiteral}, nil
+	}
+}
+
+func math2Function(f func(x, y float64) float64) func(args []*scalarValues) (*scalarValues, error) {
+	return func(args []*scalarValues) (*scalarValues, error) {
+		x, y := float64Values(args[0]), float64Values(args[1])
+		out := make([]float64, len(x))
+		for i := range out {
+			out[i] = f(x[i], y[i])
+		}
+		isLiteral := args[0].isLiteral && args[1].isLiteral
+		return &scalarValues{column: out, elementType: io.FLOAT64, isLiteral: isLiteral}, nil
+	}
+}
+
+// keepTypeFunction applies a function to every value, keeping the type of the argument.
+func keepTypeFunction(f func(float64) float64) func(args []*scalarValues) (*scalarValues, error) {
+	return func(args []*scalarValues) (*scalarValues, error) {
+		x := float64Values(args[0])
+		out := make([]float64, len(x))
+		for i := range out {
+			out[i] = f(x[i])
+		}
+		return typedValues(out, args[0].elementType, args[0].isLiteral)
+	}
+}
+
+// logFunction is the natural logarithm of x for log(x), and the logarithm in base b for log(b, x).
+func logFunction(args []*scalarValues) (*scalarValues, error) {
+	if len(args) == 1 {
+		return mathFunction(math.Log)(args)
+	}
+	return math2Function(func(b, x float64) float64 {
+		return math.Log(x) / math.Log(b)
+	})(args)
+}
+
+// roundFunction rounds half away from zero to the given number of decimals, 0 by default.
+func roundFunction(args []*scalarValues) (*scalarValues, error) {
+	x := args[0]
+	if !isFloatType(x.elementType) {
+		return x, nil
+	}
+	values := float64Values(x)
+	decimals := make([]int64, len(values))
+	if len(args) == 2 {
+		if isFloatType(args[1].elementType) {
+			return nil, fmt.Errorf("the decimals of round must be an integer")
+		}
+		decimals = int64Values(args[1])
+	}
+	out := make([]float64, len(values))
+	for i := range out {
+		scale := math.Pow(10, float64(decimals[i]))
+		out[i] = math.Round(values[i]*scale) / scale
+	}
+	return typedValues(out, x.elementType, x.isLiteral)
+}
+
+/*
+timeFunction extracts a field of the time of Epoch values in seconds, in the
+timezone of the server like the time buckets of date_trunc.
+*/
+func timeFunction(field func(t time.Time) int) func(args []*scalarValues) (*scalarValues, error) {
+	return func(args []*scalarValues) (*scalarValues, error) {
+		epochs := int64Values(args[0])
+		out := make([]int64, len(epochs))
+		for i, epoch := range epochs {
+			out[i] = int64(field(io.ToSystemTimezone(time.Unix(epoch, 0))))
+		}
+		return &scalarValues{column: out, elementType: io.INT64, isLiteral: args[0].isLiteral}, nil
+	}
+}
+
+/*
+newScalarExpression builds an expression from a value expression of the
+parse tree, using the visitor only to resolve the columns and literals at the
+leaves.
+*/
+func (es *ExecutableStatement) newScalarExpression(tree IMSTree) (se *ScalarExpression, err error) {
+	switch ctx := tree.(type) {
+	case *ExpressionParse, *ValueExpressionParse:
+		return es.newScalarExpression(ctx.GetChild(0))
+	case *BooleanExpressionParse:
+		return nil, fmt.Errorf("boolean expressions are not supported in computed values")
+	case *ArithmeticBinaryParse:
+		se = &ScalarExpression{kind: arithmeticExpression, Operator: ctx.operator}
+		for _, arg := range []IMSTree{ctx.left, ctx.right} {
+			value, err := es.newScalarExpression(arg)
+			if err != nil {
+				return nil, err
+			}
+			se.Args = append(se.Args, value)
+		}
+		return se, nil
+	case *ArithmeticUnaryParse:
+		value, err := es.newScalarExpression(ctx.value)
+		if err != nil || ctx.operator == PLUS {
+			return value, err
+		}
+		return &ScalarExpression{kind: negateExpression, Args: []*ScalarExpression{value}}, nil
+	case *PrimaryExpressionParse:
+		switch ctx.primaryType {
+		case PARENTHESIZED_EXPRESSION:
+			return es.newScalarExpression(ctx.GetChild(0))
+		case CAST:
+			//nolint:forcetypeassert // the child of a CAST is always a CastParse
+			return es.newCastExpression(ctx.GetChild(0).(*CastParse))
+		case FUNCTION_CALL:
+			//nolint:forcetypeassert // the child of a function call is always a FunctionCallParse
+			fcp := ctx.GetChild(0).(*FunctionCallParse)
+			if name, ok := es.scalarFunctionName(fcp); ok {
+				return es.newFunctionExpression(name, fcp)
+			}
+		}
+	}
+
+	// A leaf, either a column or a literal
+	operand, err := es.newRowOperand(tree)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case operand.FunctionCall != nil:
+		return nil, fmt.Errorf("aggregate function %s can not be used in an expression",
+			operand.FunctionCall.Name)
+	case operand.Expression != nil:
+		return operand.Expression, nil
+	}
+	return &ScalarExpression{kind: operandExpression, Operand: operand}, nil
+}
+
+// scalarFunctionName returns the lower case name of a call to a scalar function.
+func (es *ExecutableStatement) scalarFunctionName(fcp *FunctionCallParse) (name string, ok bool) {
+	name, ok = es.nodeCursor.Visit(fcp.qualifiedName).(string)
+	if !ok || !isScalarFunction(name) {
+		return "", false
+	}
+	return strings.ToLower(name), true
+}
+
+func (es *ExecutableStatement) newFunctionExpression(name string, fcp *FunctionCallParse,
+) (se *ScalarExpression, err error) {
+	function := scalarFunctions[name]
+	switch {
+	case fcp.hasAsterisk || fcp.hasSetQuantifier || fcp.hasFilter || fcp.over != nil:
+		return nil, fmt.Errorf("%s is a scalar function, only a list of arguments is supported", name)
+	case len(fcp.expressionList) < function.minArgs || len(fcp.expressionList) > function.maxArgs:
+		if function.minArgs == function.maxArgs {
+			return nil, fmt.Errorf("%s needs %d arguments, have %d",
+				name, function.minArgs, len(fcp.expressionList))
+		}
+		return nil, fmt.Errorf("%s needs %d to %d arguments, have %d",
+			name, function.minArgs, function.maxArgs, len(fcp.expressionList))
+	}
+	se = &ScalarExpression{kind: functionExpression, FunctionName: name}
+	for _, expr := range fcp.expressionList {
+		arg, err := es.newScalarExpression(expr)
+		if err != nil {
+			return nil, err
+		}
+		se.Args = append(se.Args, arg)
+	}
+	return se, nil
+}
+
+func (es *ExecutableStatement) newCastExpression(ctx *CastParse) (se *ScalarExpression, err error) {
+	value, err := es.newScalarExpression(ctx.expression)
+	if err != nil {
+		return nil, err
+	}
+	castType, err := es.castElementType(ctx.typeT)
+	if err != nil {
+		return nil, err
+	}
+	return &ScalarExpression{kind: castExpression, Args: []*ScalarExpression{value}, CastType: castType}, nil
+}
+
+// sqlTypeNames are the SQL names of the element types, next to their own names like FLOAT32.
+var sqlTypeNames = map[string]io.EnumElementType{
+	"real":     io.FLOAT32,
+	"float":    io.FLOAT64,
+	"double":   io.FLOAT64,
+	"tinyint":  io.BYTE,
+	"smallint": io.INT16,
+	"int":      io.INT32,
+	"integer":  io.INT32,
+	"bigint":   io.INT64,
+}
+
+// castElementType returns the numeric element type named by the type of a CAST.
+func (es *ExecutableStatement) castElementType(tree IMSTree) (elementType io.EnumElementType, err error) {
+	typeT, ok := tree.(*TypeTParse)
+	if !ok || typeT.baseType == nil || len(typeT.typeElem) != 0 {
+		return io.NONE, fmt.Errorf("unsupported CAST type, only numeric types like FLOAT64 are supported")
+	}
+	//nolint:forcetypeassert // the base type of a type is always a BaseTypeParse
+	baseType := typeT.baseType.(*BaseTypeParse)
+	if baseType.typeID == DOUBLE_PRECISION {
+		return io.FLOAT64, nil
+	}
+	if baseType.GetChildCount() == 0 {
+		return io.NONE, fmt.Errorf("unsupported CAST type, only numeric types like FLOAT64 are supported")
+	}
+	name, _ := es.nodeCursor.Visit(baseType.GetChild(0)).(string)
+	elementType, ok = sqlTypeNames[strings.ToLower(name)]
+	if !ok {
+		elementType = io.EnumElementTypeFromName(name)
+	}
+	if !isNumericType(elementType) {
+		return io.NONE, fmt.Errorf("unsupported CAST type %s, only numeric types like FLOAT64 are supported", name)
+	}
+	if elementType == io.EPOCH {
+		return io.INT64, nil
+	}
+	return elementType, nil
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/alpacahq/marketstore/v4/catalog"
 	"github.com/alpacahq/marketstore/v4/executor"
 	"github.com/alpacahq/marketstore/v4/planner"
+	"github.com/alpacahq/marketstore/v4/uda"
 	"github.com/alpacahq/marketstore/v4/utils/functions"
 	"github.com/alpacahq/marketstore/v4/utils/io"
 )
@@ -18,11 +19,14 @@ type SelectRelation struct {
 	ExecutableStatement
 	Limit                  int
 	OrderBy                []SortItem
+	GroupBy                []*GroupingKey
+	Having                 *RowPredicate // Evaluated on the grouped results
 	SelectList             []*AliasedIdentifier
 	IsPrimary, IsSelectAll bool
 	PrimaryTargetName      []string
-	Subquery               *SelectRelation
-	WherePredicate         IMSTree // Runtime predicates
+	Subquery               *SubqueryRelation
+	Join                   *JoinRelation
+	WherePredicate         *RowPredicate // Runtime predicates, evaluated after the read
 	SetQuantifier          SetQuantifierEnum
 	StaticPredicates       StaticPredicateGroup
 }
@@ -71,13 +75,21 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 		}
 	}
 	//	fmt.Printf("Materialize... %+v\n", sr)
-	if !sr.IsPrimary {
-		//		fmt.Println("Materializing subquery")
+	if sr.Subquery != nil {
 		inputColumnSeries, err = sr.Subquery.Materialize(aggRunner, catDir)
 		if err != nil {
 			return nil, err
 		}
 	}
+	// The catalog tables are read like the results of a subquery
+	if inputColumnSeries == nil && sr.Join == nil && len(sr.PrimaryTargetName) != 0 {
+		if table, ok := catalogTable(sr.PrimaryTargetName[0]); ok {
+			inputColumnSeries, err = table(catDir)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	// Check for the early "always false predicate" case
 	for _, sp := range sr.StaticPredicates {
@@ -90,10 +102,30 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 		Get column metadata, either from primary table or from input results
 	*/
 	var dsv []io.DataShape
-	var key *io.TimeBucketKey
-	if inputColumnSeries != nil {
+	var key, readKey *io.TimeBucketKey
+	var symbols []string // Set when the table name selects several symbols
+	var joined *io.ColumnSeries
+	switch {
+	case inputColumnSeries != nil:
+		if inputColumnSeries.Len() == 0 {
+			return inputColumnSeries, nil
+		}
 		dsv = inputColumnSeries.GetDataShapes()
-	} else {
+	case sr.Join != nil:
+		/*
+			A join reads both of its buckets up front, its columns are only
+			known once they are prefixed
+		*/
+		start, end, err2 := sr.epochBounds()
+		if err2 != nil {
+			return nil, err2
+		}
+		joined, err = sr.Join.Materialize(catDir, start, end)
+		if err != nil {
+			return nil, err
+		}
+		dsv = joined.GetDataShapes()
+	default:
 		if len(sr.PrimaryTargetName) == 0 {
 			return nil, fmt.Errorf("unable to retrieve table name")
 		}
@@ -101,12 +133,47 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 		if key == nil {
 			return nil, fmt.Errorf("table name must match \"one/two/three\" for three directory levels")
 		}
+		readKey = key
+		if pattern, ok := symbolPattern(key); ok {
+			symbols, err = sr.resolveSymbols(catDir, pattern)
+			if err != nil {
+				return nil, err
+			}
+			if len(symbols) == 0 {
+				return io.NewColumnSeries(), nil
+			}
+			dsv, err = symbolDataShapes(catDir, key, symbols)
+			if err != nil {
+				return nil, err
+			}
+			// The rows carry their Symbol in a column instead of the key
+			readKey, key = symbolKey(key, strings.Join(symbols, ",")), nil
+			break
+		}
 		dsv, err = catDir.GetDataShapes(key)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	/*
+		Resolve the GROUP BY keys, the Symbol of the bucket key can be used
+		like a column when grouping
+	*/
+	var grouping []*groupingColumn
+	if len(sr.GroupBy) != 0 {
+		grouping, err = sr.resolveGrouping(dsv, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, gc := range grouping {
+			if gc.fromKey {
+				dsv = append(dsv, io.DataShape{Name: "Symbol", Type: io.STRING16})
+				break
+			}
+		}
+	}
+
 	/*
 		Validate the SELECT list
 	*/
@@ -142,48 +209,53 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 	/*
 		Get input results, either by query or using input results
 	*/
-	if inputColumnSeries != nil {
+	switch {
+	case inputColumnSeries != nil:
 		outputColumnSeries = inputColumnSeries
-	} else {
+		if err = sr.filterStaticPredicates(outputColumnSeries); err != nil {
+			return nil, err
+		}
+	case joined != nil:
+		outputColumnSeries = joined
+		if err = sr.filterStaticPredicates(outputColumnSeries); err != nil {
+			return nil, err
+		}
+	default:
 		q := planner.NewQuery(catDir)
-		q.AddTargetKey(key)
+		q.AddTargetKey(readKey)
 
 		/*
 			Search for time/Epoch predicates and push them down to the IO query
 		*/
-		if sp, ok := sr.StaticPredicates["Epoch"]; ok {
-			if sp.ContentsEnum.IsSet(MINBOUND) {
-				val, err2 := io.GetValueAsInt64(sp.min)
-				if err2 != nil {
-					return nil, fmt.Errorf("non date predicate found for Epoch")
-				}
-				if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-					val += 1
-				}
-				q.SetStart(time.Unix(val/nanosec, val%nanosec))
-			}
-			if sp.ContentsEnum.IsSet(MAXBOUND) {
-				val, err2 := io.GetValueAsInt64(sp.max)
-				if err2 != nil {
-					return nil, fmt.Errorf("non date predicate found for Epoch")
-				}
-				if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-					val -= 1
-				}
-				q.SetEnd(time.Unix(val/nanosec, val%nanosec))
-			}
+		start, end, err2 := sr.epochBounds()
+		if err2 != nil {
+			return nil, err2
+		}
+		if start != nil {
+			q.SetStart(*start)
+		}
+		if end != nil {
+			q.SetEnd(*end)
+		}
+		// the block index of the files skips the rows out of the bounds of the WHERE columns
+		for _, cr := range sr.WherePredicate.columnRanges() {
+			q.AddColumnRange(cr.Name, cr.Min, cr.Max)
 		}
 
 		// TODO: push down range predicates on Epoch column
 		checkForPredicatesAndFunctions := func() bool {
 			// First check for predicates - we don't push these down (even though we can for Epoch predicates)
-			if len(sr.StaticPredicates) != 0 {
+			if len(sr.StaticPredicates) != 0 || sr.WherePredicate != nil {
+				return true
+			}
+			// Grouping and HAVING change the number of rows
+			if len(sr.GroupBy) != 0 || sr.Having != nil {
 				return true
 			}
 			// Check for functions on the relation
 			if !sr.IsSelectAll {
 				for _, sl := range sr.SelectList {
-					if sl.IsFunctionCall {
+					if sl.IsFunctionCall || sl.WindowFunction != nil {
 						return true
 					}
 				}
@@ -192,7 +264,10 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 		}
 		if !checkForPredicatesAndFunctions() {
 			if sr.Limit != 0 {
-				q.SetRowLimit(io.FIRST, sr.Limit)
+				// the limit can only be pushed down when the result is in time order
+				if direction, ok := sr.limitDirection(); ok {
+					q.SetRowLimit(direction, sr.Limit)
+				}
 			}
 		}
 
@@ -212,7 +287,14 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 			return nil, fmt.Errorf("no results returned from query")
 		}
 
-		outputColumnSeries = csm[*key]
+		if symbols != nil {
+			outputColumnSeries, err = mergeSymbols(csm, readKey, symbols)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			outputColumnSeries = csm[*key]
+		}
 		if outputColumnSeries.Len() == 0 {
 			return outputColumnSeries, nil
 		}
@@ -220,243 +302,18 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 		/*
 			Evaluate all predicates on final results set
 		*/
-		totalLength := outputColumnSeries.Len()
-		removalBitmap := make([]bool, totalLength) // true means we ditch the value, default is keep
-		for _, name := range outputColumnSeries.GetColumnNames() {
-			if sp, ok := sr.StaticPredicates[name]; ok {
-				iCol := outputColumnSeries.GetColumn(name)
-				switch col := iCol.(type) {
-				case []float32:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsFloat64(sp.equal)
-						for i, val := range col {
-							if val != float32(eqval) {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsFloat64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < float32(minval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= float32(minval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsFloat64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > float32(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= float32(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				case []float64:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsFloat64(sp.equal)
-						for i, val := range col {
-							if val != eqval {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsFloat64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < minval {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= minval {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsFloat64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > maxval {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= maxval {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				case []int:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsInt64(sp.equal)
-						for i, val := range col {
-							if val != int(eqval) {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsInt64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < int(minval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= int(minval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsInt64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > int(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= int(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				case []int32:
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsInt64(sp.equal)
-						for i, val := range col {
-							if val != int32(eqval) {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsInt64(sp.min)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < int32(minval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= int32(minval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsInt64(sp.max)
-						for i, val := range col {
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > int32(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= int32(maxval) {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				case []int64:
-					// Epoch is second (e.g. 1620027224),
-					// but "Nanoseconds" column values should be considered in case of variable-length record.
-					//
-					// Note that max/min values for Epoch column in SQL is managed in nanoseconds precision
-					// when specified by a datetime string (e.g. "2021-01-02-03:04:05.123456")
-					var nanosecs []int32
-					if name == "Epoch" {
-						nanosecCol := outputColumnSeries.GetColumn("Nanoseconds")
-						if nanosecCol != nil {
-							nanosecs, ok = nanosecCol.([]int32)
-							if !ok {
-								return nil, fmt.Errorf("invalid nanosec dtype %v", nanosecCol)
-							}
-						}
-					}
-
-					if sp.ContentsEnum.IsSet(EQUALITY) {
-						eqval, _ := io.GetValueAsInt64(sp.equal)
-						for i, val := range col {
-							// need to consider "Nanoseconds" column value
-							if name == "Epoch" {
-								eqval = convertUnitToNanosec(eqval)
-								val = convertUnitToNanosec(val)
-							}
-							if nanosecs != nil {
-								val = val + int64(nanosecs[i])
-							}
-							if val != eqval {
-								removalBitmap[i] = true // remove
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MINBOUND) {
-						minval, _ := io.GetValueAsInt64(sp.min)
-						for i, val := range col {
-							// need to consider "Nanoseconds" column value
-							if name == "Epoch" {
-								minval = convertUnitToNanosec(minval)
-								val = convertUnitToNanosec(val)
-							}
-							if nanosecs != nil {
-								val = val + int64(nanosecs[i])
-							}
-
-							if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
-								if val < minval {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val <= minval {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-					if sp.ContentsEnum.IsSet(MAXBOUND) {
-						maxval, _ := io.GetValueAsInt64(sp.max)
-						for i, val := range col {
-							// need to consider "Nanoseconds" column value
-							if name == "Epoch" {
-								maxval = convertUnitToNanosec(maxval)
-								val = convertUnitToNanosec(val)
-							}
-							if nanosecs != nil {
-								val = val + int64(nanosecs[i])
-							}
+		if err = sr.filterStaticPredicates(outputColumnSeries); err != nil {
+			return nil, err
+		}
+	}
 
-							if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
-								if val > maxval {
-									removalBitmap[i] = true // remove
-								}
-							} else {
-								if val >= maxval {
-									removalBitmap[i] = true // remove
-								}
-							}
-						}
-					}
-				}
-			}
+	/*
+		Filter the rows with the WHERE terms that could not be pushed down
+	*/
+	if sr.WherePredicate != nil {
+		if err = sr.applyWhere(outputColumnSeries, key); err != nil {
+			return nil, err
 		}
-		_ = outputColumnSeries.RestrictViaBitmap(removalBitmap)
 	}
 
 	/*
@@ -464,7 +321,15 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 	*/
 	var selectListOutput *io.ColumnSeries
 	var skipProjection bool // TODO: Only skip for SRF
-	if !sr.IsSelectAll {
+	functionOutputs := make(map[*AliasedIdentifier][]string)
+	switch {
+	case len(grouping) != 0:
+		outputColumnSeries, err = sr.materializeGroups(aggRunner, outputColumnSeries, key, grouping, functionOutputs)
+		if err != nil {
+			return nil, err
+		}
+		skipProjection = true
+	case !sr.IsSelectAll:
 		for _, sl := range sr.SelectList {
 			if sl.IsFunctionCall {
 				if selectListOutput == nil {
@@ -473,58 +338,12 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 				// TODO: This only handles SRF
 				skipProjection = true
 				aggName := sl.FunctionCall.Name
-				agg := aggRunner.GetFunc(strings.ToLower(aggName))
-				if agg == nil {
-					return nil, fmt.Errorf("no function in the UDA Registry named \"%s\"", aggName)
-				}
-
-				argMap := functions.NewArgumentMap(agg.GetRequiredArgs(), agg.GetOptionalArgs()...)
-				if unmapped := argMap.Validate(); unmapped != nil {
-					return nil, fmt.Errorf("unmapped columns: %s", unmapped)
-				}
-
-				if sl.FunctionCall.IsAsterisk {
-					/*
-						If an asterisk is provided, use Epoch as the mapped input column
-					*/
-					argMap.MapRequiredColumn("*", io.DataShape{
-						Name: "Epoch", Type: io.INT64,
-					})
-				} else {
-					idList := sl.FunctionCall.GetIDs()
-					err = argMap.PrepareArguments(idList)
-					if err != nil {
-						return nil, fmt.Errorf("Argument mapping error for %s: %s", aggName, err.Error())
-					}
-				}
-
-				/*
-					Initialize the Aggregate
-						An agg may have init parameters, which are used only to initialize it
-						These are single value literals (like '1Min')
-				*/
-				requiredInitDSV := agg.GetInitArgs()
-				requiredInitNames := io.GetNamesFromDSV(requiredInitDSV)
-
-				initList := sl.FunctionCall.GetLiterals()
-				if len(requiredInitNames) > len(initList) {
-					return nil, fmt.Errorf(
-						"not enough init arguments for %s, need %d have %d",
-						aggName,
-						len(requiredInitNames),
-						len(initList),
-					)
+				if symbols != nil {
+					return nil, fmt.Errorf("function %s can not read several symbols, use GROUP BY Symbol", aggName)
 				}
-				// TODO: Handle different argument types from string
-				var initArgList []string
-				for _, lit := range initList {
-					//nolint:forcetypeassert // hard to refactor for now
-					value := lit.Value.(string)
-					value = value[1 : len(value)-1] // Strip the quotes
-					initArgList = append(
-						initArgList,
-						value,
-					)
+				agg, argMap, initArgList, err2 := prepareFunction(aggRunner, sl.FunctionCall)
+				if err2 != nil {
+					return nil, err2
 				}
 				aggfunc, err2 := agg.New(argMap, initArgList)
 				if err2 != nil {
@@ -557,12 +376,39 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 							outname = sl.Alias
 						}
 					}
-					selectListOutput.AddColumn(
+					outname = selectListOutput.AddColumn(
 						outname,
 						functionResult.GetColumn(name))
+					selectListOutput.CopyScale(outname, functionResult, name)
+					if name != "Epoch" {
+						functionOutputs[sl] = append(functionOutputs[sl], outname)
+					}
 				}
 			}
 		}
+		/*
+			Add the computed columns to the input rows, before the projection
+		*/
+		for _, sl := range sr.SelectList {
+			var column interface{}
+			var err2 error
+			switch {
+			case sl.RuntimeExpression != nil:
+				column, err2 = sl.RuntimeExpression.Evaluate(outputColumnSeries, resolveSourceColumn(outputColumnSeries))
+			case sl.WindowFunction != nil:
+				column, err2 = sl.WindowFunction.materializeWindow(aggRunner, outputColumnSeries)
+			default:
+				continue
+			}
+			if err2 != nil {
+				return nil, err2
+			}
+			if selectListOutput != nil {
+				return nil, fmt.Errorf("computed column %s can not be combined with aggregate functions",
+					sl.computedName())
+			}
+			outputColumnSeries.AddColumn(sl.PrimaryName, column)
+		}
 		if selectListOutput != nil { // We had function calls in the select list, replace the output
 			outputColumnSeries = io.NewColumnSeries()
 
@@ -579,6 +425,7 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 			for _, name := range selectListOutput.GetColumnNames() {
 				outputColumnSeries.AddColumn(name,
 					selectListOutput.GetColumn(name))
+				outputColumnSeries.CopyScale(name, selectListOutput, name)
 			}
 		}
 	}
@@ -603,6 +450,26 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 		}
 	}
 
+	/*
+		Filter the aggregated results with the HAVING clause
+	*/
+	if sr.Having != nil {
+		err = sr.applyHaving(outputColumnSeries, functionOutputs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	/*
+		Apply ORDER BY on the projected results, before the LIMIT
+	*/
+	if len(sr.OrderBy) != 0 {
+		err = sr.sortOutput(outputColumnSeries, functionOutputs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	/*
 		Enforce LIMIT on the final results
 	*/
@@ -613,6 +480,356 @@ func (sr *SelectRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Dire
 	return outputColumnSeries, nil
 }
 
+// resolveSourceColumn resolves the columns used by the computed columns of the select list.
+func resolveSourceColumn(cs *io.ColumnSeries) columnResolver {
+	return func(operand *RowOperand) (string, error) {
+		if !cs.Exists(operand.ColumnName) {
+			return "", fmt.Errorf("column %s not found in source table", operand.ColumnName)
+		}
+		return operand.ColumnName, nil
+	}
+}
+
+/*
+epochBounds returns the time range set by the static predicates on Epoch, a
+nil bound is left open
+*/
+func (sr *SelectRelation) epochBounds() (start, end *time.Time, err error) {
+	sp, ok := sr.StaticPredicates["Epoch"]
+	if !ok {
+		return nil, nil, nil
+	}
+	if sp.ContentsEnum.IsSet(MINBOUND) {
+		val, err := io.GetValueAsInt64(sp.min)
+		if err != nil {
+			return nil, nil, fmt.Errorf("non date predicate found for Epoch")
+		}
+		if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+			val += 1
+		}
+		t := time.Unix(val/nanosec, val%nanosec)
+		start = &t
+	}
+	if sp.ContentsEnum.IsSet(MAXBOUND) {
+		val, err := io.GetValueAsInt64(sp.max)
+		if err != nil {
+			return nil, nil, fmt.Errorf("non date predicate found for Epoch")
+		}
+		if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+			val -= 1
+		}
+		t := time.Unix(val/nanosec, val%nanosec)
+		end = &t
+	}
+	return start, end, nil
+}
+
+/*
+filterStaticPredicates removes the rows of the series that fail any of the
+static predicates, including the Epoch range already pushed down to the read
+*/
+func (sr *SelectRelation) filterStaticPredicates(cs *io.ColumnSeries) (err error) {
+	totalLength := cs.Len()
+	removalBitmap := make([]bool, totalLength) // true means we ditch the value, default is keep
+	for _, name := range cs.GetColumnNames() {
+		if sp, ok := sr.StaticPredicates[name]; ok {
+			iCol := cs.GetColumn(name)
+			switch col := iCol.(type) {
+			case []float32:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsFloat64(sp.equal)
+					for i, val := range col {
+						if val != float32(eqval) {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsFloat64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < float32(minval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= float32(minval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsFloat64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > float32(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= float32(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			case []float64:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsFloat64(sp.equal)
+					for i, val := range col {
+						if val != eqval {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsFloat64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < minval {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= minval {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsFloat64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > maxval {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= maxval {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			case []int:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsInt64(sp.equal)
+					for i, val := range col {
+						if val != int(eqval) {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsInt64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < int(minval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= int(minval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsInt64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > int(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= int(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			case []int32:
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsInt64(sp.equal)
+					for i, val := range col {
+						if val != int32(eqval) {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsInt64(sp.min)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < int32(minval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= int32(minval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsInt64(sp.max)
+					for i, val := range col {
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > int32(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= int32(maxval) {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			case []int64:
+				// Epoch is second (e.g. 1620027224),
+				// but "Nanoseconds" column values should be considered in case of variable-length record.
+				//
+				// Note that max/min values for Epoch column in SQL is managed in nanoseconds precision
+				// when specified by a datetime string (e.g. "2021-01-02-03:04:05.123456")
+				var nanosecs []int32
+				if name == "Epoch" {
+					nanosecCol := cs.GetColumn("Nanoseconds")
+					if nanosecCol != nil {
+						nanosecs, ok = nanosecCol.([]int32)
+						if !ok {
+							return fmt.Errorf("invalid nanosec dtype %v", nanosecCol)
+						}
+					}
+				}
+
+				if sp.ContentsEnum.IsSet(EQUALITY) {
+					eqval, _ := io.GetValueAsInt64(sp.equal)
+					for i, val := range col {
+						// need to consider "Nanoseconds" column value
+						if name == "Epoch" {
+							eqval = convertUnitToNanosec(eqval)
+							val = convertUnitToNanosec(val)
+						}
+						if nanosecs != nil {
+							val = val + int64(nanosecs[i])
+						}
+						if val != eqval {
+							removalBitmap[i] = true // remove
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MINBOUND) {
+					minval, _ := io.GetValueAsInt64(sp.min)
+					for i, val := range col {
+						// need to consider "Nanoseconds" column value
+						if name == "Epoch" {
+							minval = convertUnitToNanosec(minval)
+							val = convertUnitToNanosec(val)
+						}
+						if nanosecs != nil {
+							val = val + int64(nanosecs[i])
+						}
+
+						if sp.ContentsEnum.IsSet(INCLUSIVEMIN) {
+							if val < minval {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val <= minval {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+				if sp.ContentsEnum.IsSet(MAXBOUND) {
+					maxval, _ := io.GetValueAsInt64(sp.max)
+					for i, val := range col {
+						// need to consider "Nanoseconds" column value
+						if name == "Epoch" {
+							maxval = convertUnitToNanosec(maxval)
+							val = convertUnitToNanosec(val)
+						}
+						if nanosecs != nil {
+							val = val + int64(nanosecs[i])
+						}
+
+						if sp.ContentsEnum.IsSet(INCLUSIVEMAX) {
+							if val > maxval {
+								removalBitmap[i] = true // remove
+							}
+						} else {
+							if val >= maxval {
+								removalBitmap[i] = true // remove
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return cs.RestrictViaBitmap(removalBitmap)
+}
+
+/*
+prepareFunction finds a select list function in the UDA registry, maps its
+column arguments and extracts its init arguments
+*/
+func prepareFunction(aggRunner *AggRunner, fc *FunctionCallReference,
+) (agg uda.AggInterface, argMap *functions.ArgumentMap, initArgList []string, err error) {
+	aggName := fc.Name
+	agg = aggRunner.GetFunc(strings.ToLower(aggName))
+	if agg == nil {
+		return nil, nil, nil, fmt.Errorf("no function in the UDA Registry named \"%s\"", aggName)
+	}
+
+	argMap = functions.NewArgumentMap(agg.GetRequiredArgs(), agg.GetOptionalArgs()...)
+	if unmapped := argMap.Validate(); unmapped != nil {
+		return nil, nil, nil, fmt.Errorf("unmapped columns: %s", unmapped)
+	}
+
+	if fc.IsAsterisk {
+		/*
+			If an asterisk is provided, use Epoch as the mapped input column
+		*/
+		argMap.MapRequiredColumn("*", io.DataShape{
+			Name: "Epoch", Type: io.INT64,
+		})
+	} else {
+		idList := fc.GetIDs()
+		err = argMap.PrepareArguments(idList)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("Argument mapping error for %s: %s", aggName, err.Error())
+		}
+	}
+
+	/*
+		Initialize the Aggregate
+			An agg may have init parameters, which are used only to initialize it
+			These are single value literals (like '1Min')
+	*/
+	requiredInitDSV := agg.GetInitArgs()
+	requiredInitNames := io.GetNamesFromDSV(requiredInitDSV)
+
+	initList := fc.GetLiterals()
+	if len(requiredInitNames) > len(initList) {
+		return nil, nil, nil, fmt.Errorf(
+			"not enough init arguments for %s, need %d have %d",
+			aggName,
+			len(requiredInitNames),
+			len(initList),
+		)
+	}
+	// TODO: Handle different argument types from string
+	for _, lit := range initList {
+		//nolint:forcetypeassert // hard to refactor for now
+		value := lit.Value.(string)
+		value = value[1 : len(value)-1] // Strip the quotes
+		initArgList = append(
+			initArgList,
+			value,
+		)
+	}
+	return agg, argMap, initArgList, nil
+}
+
 func (sr *SelectRelation) Explain() string {
 	if sr != nil {
 		jsonStruct, _ := json.Marshal(*sr)
@@ -666,7 +883,8 @@ func (spg StaticPredicateGroup) AddComparison(column *ColumnReference,
 func (spg StaticPredicateGroup) Merge(sp *StaticPredicate, IsOr bool) error {
 	/*
 		If IsOr is set, merge this predicate as an OR with the existing
-		TODO: Implement OR predicate logic in the Static Predicate
+		TODO: Implement OR predicate logic in the Static Predicate, until
+		then OR expressions are evaluated as a RowPredicate
 	*/
 	if sp == nil {
 		return fmt.Errorf("nil static predicate argumen")
@@ -848,8 +1066,9 @@ func (sp *StaticPredicate) AddComparison(op io.ComparisonOperatorEnum,
 type AliasedIdentifier struct {
 	IsPrimary, IsAliased, IsFunctionCall bool
 	PrimaryName, Alias                   string
-	RuntimeExpression                    *ExpressionParse
+	RuntimeExpression                    *ScalarExpression
 	FunctionCall                         *FunctionCallReference
+	WindowFunction                       *FunctionCallReference
 }
 
 func NewAliasedIdentifier(name ...string) (ai *AliasedIdentifier) {
@@ -861,8 +1080,16 @@ func NewAliasedIdentifier(name ...string) (ai *AliasedIdentifier) {
 	return ai
 }
 
-func (ai *AliasedIdentifier) AddRuntimeExpression(ep *ExpressionParse) {
-	ai.RuntimeExpression = ep
+// computedName describes a computed column of the select list in error messages.
+func (ai *AliasedIdentifier) computedName() string {
+	if ai.WindowFunction != nil {
+		return ai.WindowFunction.String()
+	}
+	return ai.RuntimeExpression.String()
+}
+
+func (ai *AliasedIdentifier) AddRuntimeExpression(se *ScalarExpression) {
+	ai.RuntimeExpression = se
 }
 
 func (ai *AliasedIdentifier) AddFunctionCall(fc *FunctionCallReference) {
@@ -878,10 +1105,13 @@ func (ai *AliasedIdentifier) AddAlias(alias string) {
 func (ai *AliasedIdentifier) String() (out string) {
 	var buffer bytes.Buffer
 	buffer.WriteString("Identifier: ")
-	if ai.IsPrimary {
+	switch {
+	case ai.IsPrimary:
 		buffer.WriteString(fmt.Sprintf("Primary Name: %s ", ai.PrimaryName))
-	} else {
-		buffer.WriteString("Runtime Expression: ")
+	case ai.WindowFunction != nil:
+		buffer.WriteString(fmt.Sprintf("Window Function: %s ", ai.WindowFunction))
+	default:
+		buffer.WriteString(fmt.Sprintf("Runtime Expression: %s ", ai.RuntimeExpression))
 	}
 	if ai.IsAliased {
 		buffer.WriteString(fmt.Sprintf("Alias: %s ", ai.Alias))
@@ -898,7 +1128,9 @@ func SourceValidator(sourceDSV []io.DataShape, selectList []*AliasedIdentifier)
 		Given a source's DataShapes, verify that the target ID list is found within it
 	*/
 	// Get target names from identifiers
+	var sourceIDs []string // Columns that must be found in the source
 	for _, id := range selectList {
+		ids := len(keepList)
 		switch {
 		case id.IsFunctionCall:
 			if id.FunctionCall.IsAsterisk {
@@ -914,18 +1146,33 @@ func SourceValidator(sourceDSV []io.DataShape, selectList []*AliasedIdentifier)
 			}
 		case id.IsPrimary:
 			keepList = append(keepList, id.PrimaryName)
+		case id.RuntimeExpression != nil:
+			// The computed column is added to the source before the projection
+			sourceIDs = append(sourceIDs, id.RuntimeExpression.ColumnNames()...)
+			keepList = append(keepList, id.PrimaryName)
+			continue
+		case id.WindowFunction != nil:
+			for _, token := range id.WindowFunction.GetIDs() {
+				args := strings.Split(token, "::")
+				sourceIDs = append(sourceIDs, args[len(args)-1])
+			}
+			keepList = append(keepList, id.PrimaryName)
+			continue
 		}
+		sourceIDs = append(sourceIDs, keepList[ids:]...)
 	}
 	sourceNames := io.GetNamesFromDSV(sourceDSV)
-	targetNamesSet, err := io.NewAnySet(keepList)
-	if err != nil {
-		return false, nil, nil, nil, fmt.Errorf("unable to build set for target")
-	}
-	i_missingIDs := targetNamesSet.Subtract(sourceNames)
 	var missingIDs []string
-	if i_missingIDs != nil {
-		//nolint:forcetypeassert // hard to refactor for now
-		missingIDs = i_missingIDs.([]string)
+	if len(sourceIDs) != 0 { // Computed columns may only use literals
+		targetNamesSet, err := io.NewAnySet(sourceIDs)
+		if err != nil {
+			return false, nil, nil, nil, fmt.Errorf("unable to build set for target")
+		}
+		i_missingIDs := targetNamesSet.Subtract(sourceNames)
+		if i_missingIDs != nil {
+			//nolint:forcetypeassert // hard to refactor for now
+			missingIDs = i_missingIDs.([]string)
+		}
 	}
 	if len(missingIDs) != 0 {
 		return false, missingIDs, nil, nil, nil
@@ -99,22 +99,70 @@ func (v *StatementParse) String(level int) (out []string) {
 
 type QueryParse struct {
 	MSTree
-	queryNoWith IMSTree
+	with, queryNoWith IMSTree
 }
 
 func NewQueryParse(node antlr.Tree) (term *QueryParse) {
 	//nolint:forcetypeassert // hard to refactor for now
 	ctx := node.(*parser.QueryContext)
 	term = new(QueryParse)
+	if ctx.With() != nil {
+		term.with = NewWithParse(ctx.With())
+	}
 	term.queryNoWith = NewQueryNoWithParse(ctx.QueryNoWith())
 	return term
 }
 
 func (v *QueryParse) String(level int) (out []string) {
+	out = append(out, Explain(v.with, level+1)...)
 	out = append(out, Explain(v.queryNoWith, level+1)...)
 	return append(out, PrependLevel(GetStructString(v), level))
 }
 
+type WithParse struct {
+	MSTree
+	IsRecursive bool
+}
+
+func NewWithParse(node antlr.Tree) (term *WithParse) {
+	//nolint:forcetypeassert // hard to refactor for now
+	ctx := node.(*parser.WithContext)
+	term = new(WithParse)
+	term.IsRecursive = ctx.RECURSIVE() != nil
+	for _, cctx := range ctx.AllNamedQuery() {
+		term.AddChild(NewNamedQueryParse(cctx))
+	}
+	return term
+}
+
+func (v *WithParse) String(level int) (out []string) {
+	return append(out, PrependLevel(GetStructString(v), level))
+}
+
+type NamedQueryParse struct {
+	MSTree
+	name, query, columnAliases IMSTree
+}
+
+func NewNamedQueryParse(node antlr.Tree) (term *NamedQueryParse) {
+	//nolint:forcetypeassert // hard to refactor for now
+	ctx := node.(*parser.NamedQueryContext)
+	term = new(NamedQueryParse)
+	term.name = NewIDParse(ctx.GetName())
+	term.query = NewQueryParse(ctx.Query())
+	if ctx.ColumnAliases() != nil {
+		term.columnAliases = NewColumnAliasesParse(ctx.ColumnAliases())
+	}
+	return term
+}
+
+func (v *NamedQueryParse) String(level int) (out []string) {
+	out = append(out, Explain(v.name, level+1)...)
+	out = append(out, Explain(v.query, level+1)...)
+	out = append(out, Explain(v.columnAliases, level+1)...)
+	return append(out, PrependLevel(GetStructString(v), level))
+}
+
 type QueryNoWithParse struct {
 	MSTree
 	queryTerm IMSTree
@@ -346,9 +394,8 @@ type GroupingElementParse struct {
 }
 
 func NewGroupingElementParse(node antlr.Tree) (term *GroupingElementParse) {
-	ictx := node.GetChild(0)
 	term = new(GroupingElementParse)
-	switch ctx := ictx.(type) {
+	switch ctx := node.(type) {
 	case *parser.SingleGroupingSetContext:
 		term.groupingExp = NewGroupingExpressionsParse(ctx.GroupingExpressions())
 	case *parser.RollupContext:
@@ -478,7 +525,13 @@ func NewRelationParse(node antlr.Tree) (term *RelationParse) {
 				term.joinType = RIGHT_OUTER
 			case cctx.FULL() != nil:
 				term.joinType = FULL_OUTER
+			case cctx.CROSS() != nil:
+				term.joinType = CROSS
+			case cctx.NATURAL() != nil:
+				term.joinType = NATURAL
 			}
+		} else {
+			term.joinType = INNER // A plain JOIN is an inner join
 		}
 	case *parser.RelationDefaultContext:
 		term.sampled = NewSampledRelationParse(ctx.SampledRelation())
@@ -504,7 +557,9 @@ func NewJoinCriteriaParse(node antlr.Tree) (term *JoinCriteriaParse) {
 	//nolint:forcetypeassert // hard to refactor for now
 	ctx := node.(*parser.JoinCriteriaContext)
 	term = new(JoinCriteriaParse)
-	term.onExpression = NewBooleanExpressionParse(ctx.BooleanExpression())
+	if ctx.BooleanExpression() != nil { // USING has a list of identifiers instead
+		term.onExpression = NewBooleanExpressionParse(ctx.BooleanExpression())
+	}
 	for _, cctx := range ctx.AllIdentifier() {
 		term.identifiers = append(term.identifiers, NewIDParse(cctx))
 	}
@@ -696,8 +751,10 @@ func NewBooleanExpressionParse(node antlr.Tree) (term *BooleanExpressionParse) {
 	for {
 		switch ctx := node.(type) {
 		case *parser.LogicalNotContext:
-			term.IsNot = true
-			node = node.GetChild(0) // Iterate over child node
+			term.IsNot = !term.IsNot
+			node = ctx.BooleanExpression() // Iterate over the negated expression
+		case *parser.ParenthesizedBooleanExpressionContext:
+			node = ctx.BooleanExpression()
 		case *parser.LogicalBinaryContext:
 			term.right = NewExpressionParse(ctx.GetRight())
 			switch ctx.GetOperator().GetText() {
@@ -905,6 +962,9 @@ func NewLikeParse(node antlr.Tree) (term *LikeParse) {
 	if ctx.GetEscape() != nil {
 		term.escape = NewValueExpressionParse(ctx.GetEscape())
 	}
+	if ctx.NOT() != nil {
+		term.IsNot = true
+	}
 	return term
 }
 
@@ -1778,26 +1838,17 @@ type FrameBoundParse struct {
 
 func NewFrameBoundParse(node antlr.Tree) (term *FrameBoundParse) {
 	term = new(FrameBoundParse)
-	ictx := node.GetChild(0)
-	switch childCtx := ictx.(type) {
+	switch ctx := node.(type) {
 	case *parser.CurrentRowBoundContext:
 		term.IsCurrentRow = true
 	case *parser.UnboundedFrameContext:
 		term.IsUnbounded = true
-		switch {
-		case strings.EqualFold(childCtx.GetBoundType().GetText(), "PRECEDING"):
-			term.IsPreceding = true
-		case strings.EqualFold(childCtx.GetBoundType().GetText(), "FOLLOWING"):
-			term.IsPreceding = true
-		}
+		term.IsPreceding = ctx.PRECEDING() != nil
+		term.IsFollowing = ctx.FOLLOWING() != nil
 	case *parser.BoundedFrameContext:
-		switch {
-		case strings.EqualFold(childCtx.GetBoundType().GetText(), "PRECEDING"):
-			term.IsPreceding = true
-		case strings.EqualFold(childCtx.GetBoundType().GetText(), "FOLLOWING"):
-			term.IsPreceding = true
-		}
-		term.AddChild(NewExpressionParse(childCtx))
+		term.IsPreceding = ctx.PRECEDING() != nil
+		term.IsFollowing = ctx.FOLLOWING() != nil
+		term.AddChild(NewExpressionParse(ctx.Expression()))
 	}
 	return term
 }
@@ -1857,6 +1908,12 @@ func NewIDParse(node antlr.Tree) (term *IDParse) {
 	case *parser.BackQuotedIdentifierContext:
 		term.name = ctx.BACKQUOTED_IDENTIFIER().GetText()
 		term.name = term.name[1 : len(term.name)-1]
+	case *parser.QuotedIdentifierAlternativeContext:
+		term.name = ctx.QUOTED_IDENTIFIER().GetText()
+		term.name = term.name[1 : len(term.name)-1]
+	case *parser.NonReservedIdentifierContext:
+		// Keywords like HOUR can be used as names, e.g. for functions
+		term.name = ctx.GetText()
 	}
 	return term
 }
@@ -0,0 +1,129 @@
+package sqlparser
+
+import (
+	"fmt"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+SubqueryRelation is a query used as the source of another query, either
+inline in the FROM clause or named by a WITH clause:
+
+	WITH candles AS (
+		SELECT tickcandler('5Min', Close) FROM `AAPL/1Min/OHLCV`
+	)
+	SELECT * FROM candles WHERE Close > Open
+
+The query is a child statement, its results are the input rows of the outer
+query.
+*/
+type SubqueryRelation struct {
+	Name          string // The name given by the WITH clause, empty for an inline subquery
+	Statement     *ExecutableStatement
+	ColumnAliases []string // Renames the output columns in order, Epoch excluded
+}
+
+func (sq *SubqueryRelation) Materialize(aggRunner *AggRunner, catDir *catalog.Directory,
+) (cs *io.ColumnSeries, err error) {
+	cs, err = sq.Statement.Materialize(aggRunner, catDir)
+	if err != nil {
+		return nil, err
+	}
+	if cs == nil {
+		return nil, fmt.Errorf("no results returned from subquery %s", sq.Name)
+	}
+	if len(sq.ColumnAliases) == 0 {
+		return cs, nil
+	}
+
+	var names []string
+	for _, name := range cs.GetColumnNames() {
+		if name != "Epoch" {
+			names = append(names, name)
+		}
+	}
+	if len(names) != len(sq.ColumnAliases) {
+		return nil, fmt.Errorf("subquery %s has %d columns, but %d column aliases",
+			sq.Name, len(names), len(sq.ColumnAliases))
+	}
+	for i, name := range names {
+		if err = cs.Rename(sq.ColumnAliases[i], name); err != nil {
+			return nil, err
+		}
+	}
+	return cs, nil
+}
+
+/*
+newSubqueryStatement builds the child statement of a subquery. The WITH
+queries of the enclosing statements remain visible within it.
+*/
+func (es *ExecutableStatement) newSubqueryStatement(query IMSTree) (child *ExecutableStatement, err error) {
+	child, err = NewExecutableStatement()
+	if err != nil {
+		return nil, err
+	}
+	child.withQueries = es.nodeCursor.withQueries
+	if err, ok := QueryWalk(child, query).(error); ok {
+		return nil, err
+	}
+	if _, ok := child.payload.(*SelectRelation); !ok {
+		return nil, fmt.Errorf("unable to load subquery")
+	}
+	return child, nil
+}
+
+func (es *ExecutableStatement) VisitWithParse(ctx *WithParse) interface{} {
+	if ctx.IsRecursive {
+		return fmt.Errorf("unsupported option: WITH RECURSIVE")
+	}
+	/*
+		The scope is copied, so the names are only visible to this query
+		and to the named queries that follow them
+	*/
+	withQueries := make(map[string]*SubqueryRelation, len(es.nodeCursor.withQueries)+ctx.GetChildCount())
+	for name, sq := range es.nodeCursor.withQueries {
+		withQueries[name] = sq
+	}
+	es.nodeCursor.withQueries = withQueries
+
+	named := make(map[string]bool)
+	for _, child := range ctx.GetChildren() {
+		switch value := es.nodeCursor.Visit(child).(type) {
+		case *SubqueryRelation:
+			if named[value.Name] {
+				return fmt.Errorf("WITH query name %s specified more than once", value.Name)
+			}
+			named[value.Name] = true
+			withQueries[value.Name] = value
+		case error:
+			return value
+		}
+	}
+	return nil
+}
+
+func (es *ExecutableStatement) VisitNamedQueryParse(ctx *NamedQueryParse) interface{} {
+	//nolint:forcetypeassert // identifiers are always strings
+	name := es.nodeCursor.Visit(ctx.name).(string)
+	child, err := es.newSubqueryStatement(ctx.query)
+	if err != nil {
+		return fmt.Errorf("WITH query %s: %w", name, err)
+	}
+	sq := &SubqueryRelation{Name: name, Statement: child}
+	if ctx.columnAliases != nil {
+		sq.ColumnAliases = es.columnAliases(ctx.columnAliases)
+	}
+	return sq
+}
+
+// columnAliases returns the names of a column alias list.
+func (es *ExecutableStatement) columnAliases(tree IMSTree) (aliases []string) {
+	for _, child := range tree.GetChildren() {
+		//nolint:forcetypeassert // identifiers are always strings
+		aliases = append(aliases, es.nodeCursor.Visit(child).(string))
+	}
+	return aliases
+}
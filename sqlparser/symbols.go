@@ -0,0 +1,247 @@
+package sqlparser
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/catalog"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+A table name can select several symbols sharing a timeframe and an attribute
+group, with a comma separated list and with glob patterns in place of the
+symbol, like `AAPL,MSFT/1Min/OHLCV` or `A?PL/1Min/OHLCV`. A lone * selects
+every symbol of the catalog.
+
+The buckets are merged into one series in time order, rows of the same time
+are in the order of their symbols. The Symbol column names the bucket of
+every row, so it can be filtered, grouped and sorted like any other column.
+*/
+const SymbolPatternChars = "*?[,"
+
+// symbolPattern returns the Symbol of a table name when it selects several symbols.
+func symbolPattern(key *io.TimeBucketKey) (pattern string, ok bool) {
+	pattern = key.GetItemInCategory("Symbol")
+	return pattern, strings.ContainsAny(pattern, SymbolPatternChars)
+}
+
+// symbolKey returns the bucket key of another symbol.
+func symbolKey(key *io.TimeBucketKey, symbol string) *io.TimeBucketKey {
+	symbolKey := *key
+	symbolKey.SetItemInCategory("Symbol", symbol)
+	return &symbolKey
+}
+
+/*
+resolveSymbols expands the pattern with the symbols of the catalog, then keeps
+the symbols that the WHERE clause allows. The symbols are sorted.
+*/
+func (sr *SelectRelation) resolveSymbols(catDir *catalog.Directory, pattern string) (symbols []string, err error) {
+	selected, _, err := matchSymbols(catDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	// Symbols excluded by the WHERE clause are not read at all
+	if allowed, ok := sr.WherePredicate.symbolRestriction(); ok {
+		for symbol := range selected {
+			if !allowed[symbol] {
+				delete(selected, symbol)
+			}
+		}
+	}
+	for symbol := range selected {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols, nil
+}
+
+/*
+matchSymbols returns the symbols of a pattern, the ones listed and the ones of
+the catalog matching a glob. The matched symbols are also returned apart.
+*/
+func matchSymbols(catDir *catalog.Directory, pattern string) (selected, matched map[string]bool, err error) {
+	catalogSymbols := catDir.GatherCategoriesAndItems()["Symbol"]
+	selected, matched = make(map[string]bool), make(map[string]bool)
+	for _, item := range strings.Split(pattern, ",") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+			continue
+		case !strings.ContainsAny(item, SymbolPatternChars):
+			selected[item] = true
+			continue
+		}
+		if _, err = path.Match(item, ""); err != nil {
+			return nil, nil, fmt.Errorf("invalid symbol pattern %s: %w", item, err)
+		}
+		for symbol := range catalogSymbols {
+			if ok, _ := path.Match(item, symbol); ok {
+				selected[symbol], matched[symbol] = true, true
+			}
+		}
+	}
+	return selected, matched, nil
+}
+
+/*
+TableBuckets returns the keys of the buckets that a table name can read, e.g.
+to authorize them: the symbols of a pattern are expanded like for a query,
+the symbols matching a glob are kept if they have a bucket of the timeframe
+and the attribute group. A name without a pattern is its own key.
+*/
+func TableBuckets(catDir *catalog.Directory, name string) ([]string, error) {
+	key := io.NewTimeBucketKey(name, "Symbol/Timeframe/AttributeGroup")
+	if key == nil {
+		return []string{name}, nil
+	}
+	pattern, ok := symbolPattern(key)
+	if !ok {
+		return []string{name}, nil
+	}
+	selected, matched, err := matchSymbols(catDir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(selected))
+	for symbol := range selected {
+		bucket := symbolKey(key, symbol)
+		if matched[symbol] {
+			if _, err = catDir.GetLatestTimeBucketInfoFromKey(bucket); err != nil {
+				continue
+			}
+		}
+		keys = append(keys, bucket.GetItemKey())
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+/*
+symbolDataShapes returns the columns of the first bucket found among the
+symbols, followed by the Symbol column.
+*/
+func symbolDataShapes(catDir *catalog.Directory, key *io.TimeBucketKey, symbols []string,
+) (dsv []io.DataShape, err error) {
+	for _, symbol := range symbols {
+		dsv, err = catDir.GetDataShapes(symbolKey(key, symbol))
+		if err == nil {
+			return append(dsv, io.DataShape{Name: "Symbol", Type: io.STRING16}), nil
+		}
+	}
+	return nil, fmt.Errorf("no bucket found for %s", key.GetItemKey())
+}
+
+/*
+symbolRestriction returns the only symbols that satisfy the predicate, when it
+requires the Symbol column to equal a string or to be in a list of strings.
+*/
+func (rp *RowPredicate) symbolRestriction() (symbols map[string]bool, ok bool) {
+	if rp == nil || rp.IsNot {
+		return nil, false
+	}
+	switch rp.kind {
+	case logicalPredicate:
+		if rp.Operator != AND_OP {
+			return nil, false
+		}
+		left, leftOK := rp.Left.symbolRestriction()
+		right, rightOK := rp.Right.symbolRestriction()
+		switch {
+		case !leftOK:
+			return right, rightOK
+		case !rightOK:
+			return left, true
+		}
+		for symbol := range left {
+			if !right[symbol] {
+				delete(left, symbol)
+			}
+		}
+		return left, true
+	case comparisonPredicate:
+		if rp.Comparison != io.EQ {
+			return nil, false
+		}
+		return symbolLiterals(rp.Operand, rp.Argument)
+	case inListPredicate:
+		return symbolLiterals(rp.Operand, rp.List...)
+	default:
+		return nil, false
+	}
+}
+
+func symbolLiterals(operand *RowOperand, values ...*RowOperand) (symbols map[string]bool, ok bool) {
+	if operand.ColumnName != "Symbol" {
+		return nil, false
+	}
+	symbols = make(map[string]bool, len(values))
+	for _, value := range values {
+		if value.Literal == nil || value.Literal.Type != STRING_LITERAL {
+			return nil, false
+		}
+		//nolint:forcetypeassert // string literals are always stored as strings
+		symbol := value.Literal.Value.(string)
+		symbols[symbol[1:len(symbol)-1]] = true // Strip the quotes
+	}
+	return symbols, true
+}
+
+/*
+mergeSymbols concatenates the buckets of the symbols read by a query and sorts
+the rows by time, the Symbol column follows the Epoch.
+*/
+func mergeSymbols(csm io.ColumnSeriesMap, key *io.TimeBucketKey, symbols []string,
+) (cs *io.ColumnSeries, err error) {
+	var names []string
+	columns := make(map[string]reflect.Value)
+	var symbolColumn [][16]rune
+	var first *io.ColumnSeries
+	for _, symbol := range symbols {
+		part := csm[*symbolKey(key, symbol)]
+		if part == nil || part.Len() == 0 {
+			continue
+		}
+		if names == nil {
+			names = part.GetColumnNames()
+			first = part
+		}
+		if part.GetNumColumns() != len(names) {
+			return nil, fmt.Errorf("bucket of %s does not have the columns of the other symbols", symbol)
+		}
+		for _, name := range names {
+			values := reflect.ValueOf(part.GetColumn(name))
+			column, ok := columns[name]
+			if !ok && values.IsValid() {
+				column = reflect.MakeSlice(values.Type(), 0, values.Len())
+			}
+			if !values.IsValid() || values.Type() != column.Type() || part.Scale(name) != first.Scale(name) {
+				return nil, fmt.Errorf("column %s of %s does not match the other symbols", name, symbol)
+			}
+			columns[name] = reflect.AppendSlice(column, values)
+		}
+		symbol16 := toString16(symbol)
+		for i := 0; i < part.Len(); i++ {
+			symbolColumn = append(symbolColumn, symbol16)
+		}
+	}
+
+	cs = io.NewColumnSeries()
+	if names == nil {
+		return cs, nil
+	}
+	for _, name := range names {
+		cs.AddColumn(name, columns[name].Interface())
+		cs.CopyScale(name, first, name)
+		if name == "Epoch" {
+			cs.AddColumn("Symbol", symbolColumn)
+		}
+	}
+	cs, _ = timeOrder(cs)
+	return cs, nil
+}
@@ -0,0 +1,61 @@
+package sqlparser
+
+/*
+Tables returns the names of the tables (TimeBucketKeys, e.g. "AAPL/1Min/OHLCV") that the statement reads and
+writes when it is materialized, e.g. to authorize it. The names are as written in the statement, so they can
+be lists or patterns of symbols.
+*/
+func (es *ExecutableStatement) Tables() (read, written []string) {
+	t := &statementTables{}
+	t.statement(es)
+	return t.read, t.written
+}
+
+type statementTables struct {
+	read, written []string
+}
+
+// statement follows the materialization of ExecutableStatement, which only materializes its first child.
+func (t *statementTables) statement(es *ExecutableStatement) {
+	if es.GetChildCount() == 0 {
+		if sr, ok := es.nodeCursor.payload.(*SelectRelation); ok {
+			t.selectRelation(sr)
+		}
+		return
+	}
+	switch node := es.GetChild(0).(type) {
+	case *ExecutableStatement:
+		t.statement(node)
+	case *SelectRelation:
+		t.selectRelation(node)
+	case *InsertIntoStatement:
+		t.written = append(t.written, node.TableName)
+		t.selectRelation(node.SelectRelation)
+	}
+}
+
+func (t *statementTables) selectRelation(sr *SelectRelation) {
+	if sr == nil {
+		return
+	}
+	hasInput := false
+	for _, node := range sr.GetChildren() {
+		if child, ok := node.(*SelectRelation); ok {
+			t.selectRelation(child)
+			hasInput = true
+		}
+	}
+	switch {
+	case sr.Subquery != nil:
+		t.statement(sr.Subquery.Statement)
+	case sr.Join != nil:
+		t.read = append(t.read, sr.Join.Left.Key.GetItemKey(), sr.Join.Right.Key.GetItemKey())
+	case !hasInput && len(sr.PrimaryTargetName) != 0:
+		if _, ok := catalogTable(sr.PrimaryTargetName[0]); ok {
+			// the catalog tables describe all the buckets
+			t.read = append(t.read, "*/*/*")
+			return
+		}
+		t.read = append(t.read, sr.PrimaryTargetName[0])
+	}
+}
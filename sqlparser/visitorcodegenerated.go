@@ -24,6 +24,22 @@ func (v *QueryParse) Accept(visitor IMSTreeVisitor) interface{} {
 		return t.VisitChildren(v)
 	}
 }
+func (v *WithParse) Accept(visitor IMSTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case ISQLQueryTreeVisitor:
+		return t.VisitWithParse(v)
+	default:
+		return t.VisitChildren(v)
+	}
+}
+func (v *NamedQueryParse) Accept(visitor IMSTreeVisitor) interface{} {
+	switch t := visitor.(type) {
+	case ISQLQueryTreeVisitor:
+		return t.VisitNamedQueryParse(v)
+	default:
+		return t.VisitChildren(v)
+	}
+}
 func (v *QueryNoWithParse) Accept(visitor IMSTreeVisitor) interface{} {
 	switch t := visitor.(type) {
 	case ISQLQueryTreeVisitor:
@@ -494,6 +510,8 @@ type ISQLQueryTreeVisitor interface {
 	VisitStatementsParse(ctx *StatementsParse) interface{}
 	VisitStatementParse(ctx *StatementParse) interface{}
 	VisitQueryParse(ctx *QueryParse) interface{}
+	VisitWithParse(ctx *WithParse) interface{}
+	VisitNamedQueryParse(ctx *NamedQueryParse) interface{}
 	VisitQueryNoWithParse(ctx *QueryNoWithParse) interface{}
 	VisitQueryTermParse(ctx *QueryTermParse) interface{}
 	VisitQueryPrimaryParse(ctx *QueryPrimaryParse) interface{}
@@ -583,6 +601,12 @@ func (v *BaseSQLQueryTreeVisitor) VisitStatementParse(ctx *StatementParse) inter
 func (v *BaseSQLQueryTreeVisitor) VisitQueryParse(ctx *QueryParse) interface{} {
 	return v.VisitChildren(ctx)
 }
+func (v *BaseSQLQueryTreeVisitor) VisitWithParse(ctx *WithParse) interface{} {
+	return v.VisitChildren(ctx)
+}
+func (v *BaseSQLQueryTreeVisitor) VisitNamedQueryParse(ctx *NamedQueryParse) interface{} {
+	return v.VisitChildren(ctx)
+}
 func (v *BaseSQLQueryTreeVisitor) VisitQueryNoWithParse(ctx *QueryNoWithParse) interface{} {
 	return v.VisitChildren(ctx)
 }
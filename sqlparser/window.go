@@ -0,0 +1,229 @@
+package sqlparser
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/uda/window"
+	"github.com/alpacahq/marketstore/v4/utils/functions"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+/*
+WindowFrame is the OVER clause of a window function. Windows are in time order
+and end at the current row:
+
+	SELECT Epoch, Close, avg(Close) OVER (ORDER BY Epoch ROWS 19 PRECEDING) AS sma20
+	FROM `AAPL/1Min/OHLCV`
+
+Without a frame, the window has all the rows up to the current one. PARTITION
+BY evaluates the function separately on the rows sharing the same values, like
+the symbols of a multi symbol table:
+
+	SELECT Epoch, Symbol, lag(Close) OVER (PARTITION BY Symbol ORDER BY Epoch)
+	FROM `AAPL,MSFT/1Min/OHLCV`
+*/
+type WindowFrame struct {
+	Length      int      // The number of rows of the frame including the current row, 0 when unbounded
+	PartitionBy []string // Columns splitting the rows into separate windows
+}
+
+func (wf *WindowFrame) String() string {
+	var out string
+	if len(wf.PartitionBy) != 0 {
+		out = "PARTITION BY " + strings.Join(wf.PartitionBy, ", ") + " "
+	}
+	if wf.Length == 0 {
+		return out + "ORDER BY Epoch"
+	}
+	return out + fmt.Sprintf("ORDER BY Epoch ROWS %d PRECEDING", wf.Length-1)
+}
+
+// windowAliases are the aggregate functions that become window functions with an OVER clause.
+var windowAliases = map[string]string{
+	"avg":    "sma",
+	"sum":    "rolling_sum",
+	"min":    "rolling_min",
+	"max":    "rolling_max",
+	"stddev": "rolling_stddev",
+}
+
+func (es *ExecutableStatement) newWindowFrame(ctx *OverParse) (wf *WindowFrame, err error) {
+	wf = new(WindowFrame)
+	for _, partition := range ctx.partitions {
+		cr, ok := es.nodeCursor.Visit(partition).(*ColumnReference)
+		if !ok {
+			return nil, fmt.Errorf("only columns are supported in the PARTITION BY of a window")
+		}
+		wf.PartitionBy = append(wf.PartitionBy, cr.GetName())
+	}
+	for _, item := range ctx.sortItems {
+		switch si := es.nodeCursor.Visit(item).(type) {
+		case *SortItem:
+			if si.ColumnName != "Epoch" || si.IsDescending() {
+				return nil, fmt.Errorf("windows are only supported in time order, use ORDER BY Epoch")
+			}
+		case error:
+			return nil, si
+		}
+	}
+
+	if ctx.GetChildCount() == 0 {
+		return wf, nil
+	}
+	//nolint:forcetypeassert // the only child of an OVER clause is its frame
+	frame := ctx.GetChild(0).(*WindowFrameParse)
+	if frame.IsRange {
+		return nil, fmt.Errorf("unsupported window frame RANGE, use ROWS")
+	}
+	if frame.IsBetween {
+		//nolint:forcetypeassert // the children of a frame are its bounds
+		if end := frame.GetChild(1).(*FrameBoundParse); !end.IsCurrentRow {
+			return nil, fmt.Errorf("unsupported window frame, it must end at the CURRENT ROW")
+		}
+	}
+	//nolint:forcetypeassert // the children of a frame are its bounds
+	start := frame.GetChild(0).(*FrameBoundParse)
+	switch {
+	case start.IsCurrentRow:
+		wf.Length = 1
+	case start.IsUnbounded && start.IsPreceding:
+	case start.IsPreceding:
+		literal, ok := es.nodeCursor.Visit(start.GetChild(0)).(*Literal)
+		if !ok || literal.Type != INTEGER_LITERAL {
+			return nil, fmt.Errorf("the number of PRECEDING rows of a window frame must be an integer")
+		}
+		//nolint:forcetypeassert // integer literals are always stored as int64
+		wf.Length = int(literal.Value.(int64)) + 1
+	default:
+		return nil, fmt.Errorf("unsupported window frame, it must start at or before the CURRENT ROW")
+	}
+	return wf, nil
+}
+
+/*
+materializeWindow evaluates a window function on the rows of the series, or on
+the rows of each partition. The function sees the rows in time order, the
+results are in the order of the series.
+*/
+func (fc *FunctionCallReference) materializeWindow(aggRunner *AggRunner, cs *io.ColumnSeries,
+) (column []float64, err error) {
+	name := strings.ToLower(fc.Name)
+	if alias, ok := windowAliases[name]; ok {
+		name = alias
+	}
+	wf, ok := aggRunner.GetFunc(name).(*window.Function)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a window function", fc.Name)
+	}
+	if fc.IsAsterisk {
+		return nil, fmt.Errorf("window function %s needs a column", fc.Name)
+	}
+	argMap := functions.NewArgumentMap(wf.GetRequiredArgs(), wf.GetOptionalArgs()...)
+	if err = argMap.PrepareArguments(fc.GetIDs()); err != nil {
+		return nil, fmt.Errorf("argument mapping error for %s: %w", fc.Name, err)
+	}
+
+	/*
+		The offset of lag, lead and pct_change is their second argument, the
+		other functions take the length of the frame
+	*/
+	var initArgs []string
+	literals := fc.GetLiterals()
+	switch {
+	case wf.Kind.IsOffset():
+		if len(literals) > 1 {
+			return nil, fmt.Errorf("%s takes a column and an optional offset", fc.Name)
+		}
+		if len(literals) == 1 {
+			if literals[0].Type != INTEGER_LITERAL {
+				return nil, fmt.Errorf("the offset of %s must be an integer", fc.Name)
+			}
+			//nolint:forcetypeassert // integer literals are always stored as int64
+			initArgs = append(initArgs, strconv.FormatInt(literals[0].Value.(int64), 10))
+		}
+	case len(literals) != 0:
+		return nil, fmt.Errorf("%s only takes columns, its window is set by OVER", fc.Name)
+	case wf.Kind == window.CumSum:
+		if fc.Window.Length != 0 {
+			return nil, fmt.Errorf("%s always uses all the rows up to the current one", fc.Name)
+		}
+	case fc.Window.Length != 0:
+		initArgs = append(initArgs, strconv.Itoa(fc.Window.Length))
+	}
+	agg, err := wf.New(argMap, initArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:forcetypeassert // New returns the same kind of function
+	function := agg.(*window.Function)
+	if len(fc.Window.PartitionBy) == 0 {
+		return evaluateInTimeOrder(function, argMap, cs)
+	}
+
+	keyValues := make([]interface{}, len(fc.Window.PartitionBy))
+	for i, name := range fc.Window.PartitionBy {
+		if keyValues[i] = cs.GetColumn(name); keyValues[i] == nil {
+			return nil, fmt.Errorf("PARTITION BY column %s not found", name)
+		}
+	}
+	column = make([]float64, cs.Len())
+	for _, rows := range partitionRows(cs.Len(), keyValues, make([]bool, len(keyValues))) {
+		partition := io.NewColumnSeries()
+		for _, name := range cs.GetColumnNames() {
+			partition.AddColumn(name, gatherJoinRows(cs.GetColumn(name), rows))
+			partition.CopyScale(name, cs, name)
+		}
+		values, err := evaluateInTimeOrder(function, argMap, partition)
+		if err != nil {
+			return nil, err
+		}
+		for i, row := range rows {
+			column[row] = values[i]
+		}
+	}
+	return column, nil
+}
+
+// evaluateInTimeOrder evaluates the function on the rows sorted by time, the results are in the order of the series.
+func evaluateInTimeOrder(function *window.Function, argMap *functions.ArgumentMap, cs *io.ColumnSeries,
+) (column []float64, err error) {
+	ordered, order := timeOrder(cs)
+	values, err := function.Evaluate(argMap, ordered)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return values, nil
+	}
+	column = make([]float64, len(values))
+	for i, row := range order {
+		column[row] = values[i]
+	}
+	return column, nil
+}
+
+/*
+timeOrder returns the series sorted by Epoch, and the rows of the input in
+that order. The order is nil when the series is already in time order.
+*/
+func timeOrder(cs *io.ColumnSeries) (ordered *io.ColumnSeries, order []int) {
+	epochs := cs.GetEpoch()
+	if sort.SliceIsSorted(epochs, func(i, j int) bool { return epochs[i] < epochs[j] }) {
+		return cs, nil
+	}
+	order = make([]int, len(epochs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool { return epochs[order[i]] < epochs[order[j]] })
+	ordered = io.NewColumnSeries()
+	for _, name := range cs.GetColumnNames() {
+		ordered.AddColumn(name, gatherJoinRows(cs.GetColumn(name), order))
+		ordered.CopyScale(name, cs, name)
+	}
+	return ordered, order
+}
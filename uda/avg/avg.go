@@ -21,6 +21,7 @@ var (
 
 type Avg struct {
 	uda.AggInterface
+	uda.NullableOutput
 
 	Avg   float64
 	Count int64
@@ -52,7 +53,11 @@ func (a *Avg) Accum(_ io.TimeBucketKey, argMap *functions.ArgumentMap, cols io.C
 		return nil, err
 	}
 
-	for _, value := range inputCol {
+	nulls := a.InputNulls(cols, inputColName)
+	for i, value := range inputCol {
+		if nulls != nil && nulls[i] {
+			continue
+		}
 		a.Avg += float64(value)
 		a.Count++
 	}
@@ -77,5 +82,6 @@ func (a *Avg) Output() *io.ColumnSeries {
 	cs := io.NewColumnSeries()
 	cs.AddColumn("Epoch", []int64{time.Now().UTC().Unix()})
 	cs.AddColumn("Avg", []float64{a.Avg / float64(a.Count)})
+	a.SetOutputNulls(cs, "Avg", a.Count == 0)
 	return cs
 }
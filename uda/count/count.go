@@ -43,9 +43,22 @@ func (c *Count) GetInitArgs() []io.DataShape {
 }
 
 // Accum sends new data to the aggregate.
-func (c *Count) Accum(_ io.TimeBucketKey, _ *functions.ArgumentMap, cols io.ColumnInterface,
+func (c *Count) Accum(_ io.TimeBucketKey, argMap *functions.ArgumentMap, cols io.ColumnInterface,
 ) (*io.ColumnSeries, error) {
 	c.Sum += int64(cols.Len())
+	if argMap == nil {
+		return c.Output(), nil
+	}
+	// the rows where a counted column is NULL are not counted
+	var names []string
+	for _, ds := range argMap.GetMappedColumns(requiredColumns[0].Name) {
+		names = append(names, ds.Name)
+	}
+	for _, isNull := range uda.ColumnNulls(cols, names...) {
+		if isNull {
+			c.Sum--
+		}
+	}
 	return c.Output(), nil
 }
 
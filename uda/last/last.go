@@ -0,0 +1,105 @@
+package last
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/uda"
+	"github.com/alpacahq/marketstore/v4/utils/functions"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+var (
+	requiredColumns = []io.DataShape{
+		{Name: "*", Type: io.FLOAT32},
+	}
+
+	optionalColumns = []io.DataShape{}
+
+	initArgs = []io.DataShape{}
+)
+
+/*
+The last value of the input column is kept with the type of the input column,
+stored as a single element slice. A series without data outputs a NaN.
+*/
+type Last struct {
+	uda.AggInterface
+	uda.NullableOutput
+
+	IsInitialized bool
+	Last          interface{}
+	// scale is the scale of a DECIMAL64 input column
+	scale int8
+}
+
+func (f *Last) GetRequiredArgs() []io.DataShape {
+	return requiredColumns
+}
+
+func (f *Last) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+
+func (f *Last) GetInitArgs() []io.DataShape {
+	return initArgs
+}
+
+// Accum sends new data to the aggregate.
+func (f *Last) Accum(_ io.TimeBucketKey, argMap *functions.ArgumentMap, cols io.ColumnInterface,
+) (*io.ColumnSeries, error) {
+	if cols.Len() == 0 {
+		return f.Output(), nil
+	}
+	inputColDSV := argMap.GetMappedColumns(requiredColumns[0].Name)
+	inputColName := inputColDSV[0].Name
+	nulls := f.InputNulls(cols, inputColName)
+	row := cols.Len() - 1
+	for nulls != nil && row >= 0 && nulls[row] {
+		row--
+	}
+	if row < 0 {
+		return f.Output(), nil
+	}
+	value, err := uda.ColumnElement(cols, inputColName, row)
+	if err != nil {
+		return nil, err
+	}
+	f.Last = value
+	f.scale = io.ColumnScale(cols, inputColName)
+	f.IsInitialized = true
+	return f.Output(), nil
+}
+
+/*
+Creates a new last using the arguments of the specific implementation
+for inputColumns and optionalInputColumns
+*/
+func (f Last) New(argMap *functions.ArgumentMap, _ ...interface{}) (out uda.AggInterface, err error) {
+	if unmapped := argMap.Validate(); unmapped != nil {
+		return nil, fmt.Errorf("unmapped columns: %s", unmapped)
+	}
+
+	return &Last{
+		IsInitialized: false,
+	}, nil
+}
+
+/*
+Output() returns the currently valid output of this aggregate
+*/
+func (f *Last) Output() *io.ColumnSeries {
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{time.Now().UTC().Unix()})
+	if f.IsInitialized {
+		cs.AddColumn("Last", f.Last)
+		if f.scale != 0 {
+			cs.SetScale("Last", f.scale)
+		}
+	} else {
+		cs.AddColumn("Last", []float32{float32(math.NaN())})
+	}
+	f.SetOutputNulls(cs, "Last", !f.IsInitialized)
+	return cs
+}
@@ -21,6 +21,7 @@ var (
 
 type Max struct {
 	uda.AggInterface
+	uda.NullableOutput
 
 	IsInitialized bool
 	Max           float32
@@ -51,13 +52,14 @@ func (m *Max) Accum(_ io.TimeBucketKey, argMap *functions.ArgumentMap, cols io.C
 		return nil, err
 	}
 
-	if !m.IsInitialized {
-		m.Max = inputCol[0]
-		m.IsInitialized = true
-	}
-	for _, value := range inputCol {
-		if value > m.Max {
+	nulls := m.InputNulls(cols, inputColName)
+	for i, value := range inputCol {
+		if nulls != nil && nulls[i] {
+			continue
+		}
+		if !m.IsInitialized || value > m.Max {
 			m.Max = value
+			m.IsInitialized = true
 		}
 	}
 	return m.Output(), nil
@@ -85,5 +87,6 @@ func (m *Max) Output() *io.ColumnSeries {
 	cs := io.NewColumnSeries()
 	cs.AddColumn("Epoch", []int64{time.Now().UTC().Unix()})
 	cs.AddColumn("Max", []float32{m.Max})
+	m.SetOutputNulls(cs, "Max", !m.IsInitialized)
 	return cs
 }
@@ -21,6 +21,7 @@ var (
 
 type Min struct {
 	uda.AggInterface
+	uda.NullableOutput
 
 	IsInitialized bool
 	Min           float32
@@ -51,13 +52,14 @@ func (m *Min) Accum(_ io.TimeBucketKey, argMap *functions.ArgumentMap, cols io.C
 		return nil, err
 	}
 
-	if !m.IsInitialized {
-		m.Min = inputCol[0]
-		m.IsInitialized = true
-	}
-	for _, value := range inputCol {
-		if value < m.Min {
+	nulls := m.InputNulls(cols, inputColName)
+	for i, value := range inputCol {
+		if nulls != nil && nulls[i] {
+			continue
+		}
+		if !m.IsInitialized || value < m.Min {
 			m.Min = value
+			m.IsInitialized = true
 		}
 	}
 	return m.Output(), nil
@@ -87,5 +89,6 @@ func (m *Min) Output() *io.ColumnSeries {
 	cs := io.NewColumnSeries()
 	cs.AddColumn("Epoch", []int64{time.Now().UTC().Unix()})
 	cs.AddColumn("Min", []float32{m.Min})
+	m.SetOutputNulls(cs, "Min", !m.IsInitialized)
 	return cs
 }
@@ -0,0 +1,89 @@
+package sum
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/alpacahq/marketstore/v4/uda"
+	"github.com/alpacahq/marketstore/v4/utils/functions"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+var (
+	requiredColumns = []io.DataShape{
+		{Name: "*", Type: io.FLOAT32},
+	}
+
+	optionalColumns = []io.DataShape{}
+
+	initArgs = []io.DataShape{}
+)
+
+type Sum struct {
+	uda.AggInterface
+	uda.NullableOutput
+
+	Sum      float64
+	hasValue bool
+}
+
+func (s *Sum) GetRequiredArgs() []io.DataShape {
+	return requiredColumns
+}
+
+func (s *Sum) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+
+func (s *Sum) GetInitArgs() []io.DataShape {
+	return initArgs
+}
+
+// Accum sends new data to the aggregate.
+func (s *Sum) Accum(_ io.TimeBucketKey, argMap *functions.ArgumentMap, cols io.ColumnInterface,
+) (*io.ColumnSeries, error) {
+	if cols.Len() == 0 {
+		return s.Output(), nil
+	}
+	inputColDSV := argMap.GetMappedColumns(requiredColumns[0].Name)
+	inputColName := inputColDSV[0].Name
+	inputCol, err := uda.ColumnToFloat64(cols, inputColName)
+	if err != nil {
+		return nil, err
+	}
+
+	nulls := s.InputNulls(cols, inputColName)
+	for i, value := range inputCol {
+		if nulls != nil && nulls[i] {
+			continue
+		}
+		s.Sum += value
+		s.hasValue = true
+	}
+	return s.Output(), nil
+}
+
+/*
+Creates a new sum using the arguments of the specific implementation
+for inputColumns and optionalInputColumns
+*/
+func (s Sum) New(argMap *functions.ArgumentMap, _ ...interface{}) (out uda.AggInterface, err error) {
+	if unmapped := argMap.Validate(); unmapped != nil {
+		return nil, fmt.Errorf("unmapped columns: %s", unmapped)
+	}
+
+	return &Sum{
+		Sum: 0,
+	}, nil
+}
+
+/*
+Output() returns the currently valid output of this aggregate
+*/
+func (s *Sum) Output() *io.ColumnSeries {
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{time.Now().UTC().Unix()})
+	cs.AddColumn("Sum", []float64{s.Sum})
+	s.SetOutputNulls(cs, "Sum", !s.hasValue)
+	return cs
+}
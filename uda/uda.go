@@ -2,6 +2,7 @@ package uda
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/alpacahq/marketstore/v4/utils/io"
 )
@@ -35,6 +36,12 @@ func ColumnToFloat32(cols io.ColumnInterface, name string) (outCol []float32, er
 		for i := range cc {
 			outCol[i] = float32(cc[i])
 		}
+	case []io.Decimal64:
+		scale := io.ColumnScale(cols, name)
+		outCol = make([]float32, len(cc))
+		for i := range cc {
+			outCol[i] = float32(cc[i].Float64(scale))
+		}
 	}
 	return outCol, nil
 }
@@ -68,6 +75,75 @@ func ColumnToFloat64(cols io.ColumnInterface, name string) (outCol []float64, er
 		for i := range cc {
 			outCol[i] = float64(cc[i])
 		}
+	case []io.Decimal64:
+		scale := io.ColumnScale(cols, name)
+		outCol = make([]float64, len(cc))
+		for i := range cc {
+			outCol[i] = float64(cc[i].Float64(scale))
+		}
 	}
 	return outCol, nil
 }
+
+// ColumnElement returns the element of the named column at the given row as a
+// single element slice, keeping the type of the column.
+func ColumnElement(cols io.ColumnInterface, name string, row int) (element interface{}, err error) {
+	ccol := cols.GetColumn(name)
+	if ccol == nil {
+		return nil, fmt.Errorf("unable to retrieve column named %s", name)
+	}
+	col := reflect.ValueOf(ccol)
+	if col.Kind() != reflect.Slice || row < 0 || row >= col.Len() {
+		return nil, fmt.Errorf("row %d not found in column named %s", row, name)
+	}
+	out := reflect.MakeSlice(col.Type(), 1, 1)
+	out.Index(0).Set(col.Index(row))
+	return out.Interface(), nil
+}
+
+/*
+ColumnNulls returns the NULL marks of the rows of the named columns, a row is NULL if one of the columns is
+NULL in it. It returns nil if the columns have no NULL, see io.NullSuffix.
+*/
+func ColumnNulls(cols io.ColumnInterface, names ...string) (nulls []bool) {
+	for _, name := range names {
+		marks, ok := cols.GetColumn(io.NullColumnName(name)).([]bool)
+		if !ok {
+			continue
+		}
+		if nulls == nil {
+			nulls = make([]bool, len(marks))
+		}
+		for i, isNull := range marks {
+			nulls[i] = nulls[i] || isNull
+		}
+	}
+	return nulls
+}
+
+/*
+NullableOutput is embedded by the aggregates that leave the NULL values of their input columns out. Once an
+input column has NULL values, the output marks as NULL the rows without any aggregated value.
+*/
+type NullableOutput struct {
+	nullable bool
+}
+
+// InputNulls returns the NULL marks of the input columns like ColumnNulls, and records whether there is any.
+func (n *NullableOutput) InputNulls(cols io.ColumnInterface, names ...string) []bool {
+	nulls := ColumnNulls(cols, names...)
+	n.nullable = n.nullable || nulls != nil
+	return nulls
+}
+
+// IsNullable returns true once an input column had NULL values.
+func (n *NullableOutput) IsNullable() bool {
+	return n.nullable
+}
+
+// SetOutputNulls sets the NULL marks of an output column if an input column had NULL values.
+func (n *NullableOutput) SetOutputNulls(cs *io.ColumnSeries, name string, nulls ...bool) {
+	if n.nullable {
+		cs.SetNulls(name, nulls)
+	}
+}
@@ -0,0 +1,363 @@
+package window
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/alpacahq/marketstore/v4/uda"
+	"github.com/alpacahq/marketstore/v4/utils/functions"
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+var (
+	requiredColumns = []io.DataShape{
+		{Name: "*", Type: io.FLOAT32},
+	}
+
+	// VWAP weights the price of each row by its volume
+	vwapColumns = []io.DataShape{
+		{Name: "Price", Type: io.FLOAT32},
+		{Name: "Volume", Type: io.FLOAT32},
+	}
+
+	optionalColumns []io.DataShape
+
+	// The length of the window, or the offset of lag, lead and pct_change, is optional
+	initArgs []io.DataShape
+)
+
+// Kind selects one of the window functions.
+type Kind int
+
+const (
+	SMA Kind = iota
+	EMA
+	RollingSum
+	RollingStdDev
+	RollingMin
+	RollingMax
+	Lag
+	Lead
+	PctChange
+	CumSum
+	VWAP
+)
+
+var kindNames = [...]string{
+	SMA:           "SMA",
+	EMA:           "EMA",
+	RollingSum:    "RollingSum",
+	RollingStdDev: "RollingStdDev",
+	RollingMin:    "RollingMin",
+	RollingMax:    "RollingMax",
+	Lag:           "Lag",
+	Lead:          "Lead",
+	PctChange:     "PctChange",
+	CumSum:        "CumSum",
+	VWAP:          "VWAP",
+}
+
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+	return kindNames[k]
+}
+
+// IsOffset is true for the functions that compare each row with the row at a fixed offset.
+func (k Kind) IsOffset() bool {
+	return k == Lag || k == Lead || k == PctChange
+}
+
+/*
+Function is a window function, evaluated for every row over the rows that
+precede it in time order. It is the equivalent of
+
+	OVER (ORDER BY Epoch ROWS Length-1 PRECEDING)
+
+The input rows must be in time order. Rows without a value, like the first
+rows of lag, are NaN.
+*/
+type Function struct {
+	uda.AggInterface
+
+	Kind   Kind
+	Length int // The number of rows of the window including the current row, 0 for all the rows so far
+	Offset int // The distance to the other row of lag, lead and pct_change
+}
+
+func (f *Function) GetRequiredArgs() []io.DataShape {
+	if f.Kind == VWAP {
+		return vwapColumns
+	}
+	return requiredColumns
+}
+
+func (f *Function) GetOptionalArgs() []io.DataShape {
+	return optionalColumns
+}
+
+func (f *Function) GetInitArgs() []io.DataShape {
+	return initArgs
+}
+
+/*
+New creates a function of the same kind, the optional init argument is the
+length of the window, or the offset of lag, lead and pct_change (1 by default)
+*/
+func (f Function) New(_ *functions.ArgumentMap, args ...interface{}) (out uda.AggInterface, err error) {
+	wf := &Function{Kind: f.Kind, Offset: 1}
+	params := initParameters(args)
+	if len(params) > 1 {
+		return nil, fmt.Errorf("%s takes at most one init argument, have %d", f.Kind, len(params))
+	}
+	if len(params) == 1 {
+		n, err := strconv.Atoi(strings.TrimSpace(params[0]))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("init argument of %s must be a number of rows, have '%s'", f.Kind, params[0])
+		}
+		switch {
+		case f.Kind == CumSum:
+			return nil, fmt.Errorf("%s takes no init argument", f.Kind)
+		case f.Kind.IsOffset():
+			wf.Offset = n
+		default:
+			wf.Length = n
+		}
+	}
+	if wf.Kind == EMA && wf.Length == 0 {
+		return nil, fmt.Errorf("%s needs the number of rows of its span", f.Kind)
+	}
+	return wf, nil
+}
+
+// initParameters flattens the init arguments, which come as strings or lists of strings.
+func initParameters(args []interface{}) (params []string) {
+	for _, arg := range args {
+		switch val := arg.(type) {
+		case string:
+			params = append(params, val)
+		case *string:
+			params = append(params, *val)
+		case []string:
+			params = append(params, val...)
+		case *[]string:
+			params = append(params, *val...)
+		}
+	}
+	return params
+}
+
+/*
+Accum returns the input columns, followed by the result of the function named
+after the function and its input, like SMA_Close
+*/
+func (f *Function) Accum(_ io.TimeBucketKey, argMap *functions.ArgumentMap, cols io.ColumnInterface,
+) (*io.ColumnSeries, error) {
+	values, err := f.Evaluate(argMap, cols)
+	if err != nil {
+		return nil, err
+	}
+	cs := io.NewColumnSeries()
+	for _, ds := range cols.GetDataShapes() {
+		cs.AddColumn(ds.Name, cols.GetColumn(ds.Name))
+		cs.CopyScale(ds.Name, cols, ds.Name)
+	}
+	name := f.Kind.String()
+	if f.Kind != VWAP {
+		name += "_" + argMap.GetMappedColumns(requiredColumns[0].Name)[0].Name
+	}
+	cs.AddColumn(name, values)
+	return cs, nil
+}
+
+// Evaluate returns the value of the function for each row.
+func (f *Function) Evaluate(argMap *functions.ArgumentMap, cols io.ColumnInterface) (out []float64, err error) {
+	if f.Kind == VWAP {
+		price, err := inputColumn(argMap, cols, vwapColumns[0].Name)
+		if err != nil {
+			return nil, err
+		}
+		volume, err := inputColumn(argMap, cols, vwapColumns[1].Name)
+		if err != nil {
+			return nil, err
+		}
+		return vwap(price, volume, f.Length), nil
+	}
+
+	values, err := inputColumn(argMap, cols, requiredColumns[0].Name)
+	if err != nil {
+		return nil, err
+	}
+	switch f.Kind {
+	case SMA:
+		return rollingMean(values, f.Length), nil
+	case EMA:
+		return ema(values, f.Length), nil
+	case RollingSum:
+		return rollingSum(values, f.Length), nil
+	case CumSum:
+		return rollingSum(values, 0), nil
+	case RollingStdDev:
+		return rollingStdDev(values, f.Length), nil
+	case RollingMin:
+		return rollingExtreme(values, f.Length, func(a, b float64) bool { return a <= b }), nil
+	case RollingMax:
+		return rollingExtreme(values, f.Length, func(a, b float64) bool { return a >= b }), nil
+	case Lag:
+		return shift(values, f.Offset), nil
+	case Lead:
+		return shift(values, -f.Offset), nil
+	case PctChange:
+		out = shift(values, f.Offset)
+		for i, previous := range out {
+			out[i] = values[i]/previous - 1
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unknown window function %s", f.Kind)
+	}
+}
+
+func inputColumn(argMap *functions.ArgumentMap, cols io.ColumnInterface, requiredName string,
+) (values []float64, err error) {
+	mapped := argMap.GetMappedColumns(requiredName)
+	if len(mapped) == 0 {
+		return nil, fmt.Errorf("no column mapped to %s", requiredName)
+	}
+	values, err = uda.ColumnToFloat64(cols, mapped[0].Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != cols.Len() {
+		return nil, fmt.Errorf("column %s is not numeric", mapped[0].Name)
+	}
+	return values, nil
+}
+
+// windowStart returns the first row of the window that ends at row i.
+func windowStart(i, length int) int {
+	if length == 0 || i < length {
+		return 0
+	}
+	return i - length + 1
+}
+
+func rollingSum(values []float64, length int) []float64 {
+	out := make([]float64, len(values))
+	var sum float64
+	for i, value := range values {
+		sum += value
+		if length != 0 && i >= length {
+			sum -= values[i-length]
+		}
+		out[i] = sum
+	}
+	return out
+}
+
+func rollingMean(values []float64, length int) []float64 {
+	out := rollingSum(values, length)
+	for i := range out {
+		out[i] /= float64(i - windowStart(i, length) + 1)
+	}
+	return out
+}
+
+/*
+rollingStdDev is the sample standard deviation of the window, the sums are
+taken around the first value to limit the loss of precision
+*/
+func rollingStdDev(values []float64, length int) []float64 {
+	out := make([]float64, len(values))
+	if len(values) == 0 {
+		return out
+	}
+	origin := values[0]
+	var sum, sumSquares float64
+	for i, value := range values {
+		d := value - origin
+		sum += d
+		sumSquares += d * d
+		if length != 0 && i >= length {
+			d = values[i-length] - origin
+			sum -= d
+			sumSquares -= d * d
+		}
+		n := float64(i - windowStart(i, length) + 1)
+		if n < 2 {
+			out[i] = math.NaN()
+			continue
+		}
+		variance := (sumSquares - sum*sum/n) / (n - 1)
+		out[i] = math.Sqrt(math.Max(variance, 0))
+	}
+	return out
+}
+
+/*
+rollingExtreme keeps the candidates of the window in a monotonic queue, keep
+tells if the first value wins over the second
+*/
+func rollingExtreme(values []float64, length int, keep func(a, b float64) bool) []float64 {
+	out := make([]float64, len(values))
+	var queue []int // Rows of the window, their values are in order of preference
+	for i, value := range values {
+		for len(queue) != 0 && keep(value, values[queue[len(queue)-1]]) {
+			queue = queue[:len(queue)-1]
+		}
+		queue = append(queue, i)
+		if queue[0] < windowStart(i, length) {
+			queue = queue[1:]
+		}
+		out[i] = values[queue[0]]
+	}
+	return out
+}
+
+// ema is the exponential moving average with the smoothing 2/(span+1), it starts at the first value.
+func ema(values []float64, span int) []float64 {
+	out := make([]float64, len(values))
+	alpha := 2 / (float64(span) + 1)
+	for i, value := range values {
+		if i == 0 {
+			out[i] = value
+			continue
+		}
+		out[i] = alpha*value + (1-alpha)*out[i-1]
+	}
+	return out
+}
+
+// shift returns the value of the row offset rows before each row, or after it for a negative offset.
+func shift(values []float64, offset int) []float64 {
+	out := make([]float64, len(values))
+	for i := range out {
+		j := i - offset
+		if j < 0 || j >= len(values) {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = values[j]
+	}
+	return out
+}
+
+func vwap(price, volume []float64, length int) []float64 {
+	weighted := make([]float64, len(price))
+	for i := range price {
+		weighted[i] = price[i] * volume[i]
+	}
+	out := rollingSum(weighted, length)
+	volumes := rollingSum(volume, length)
+	for i := range out {
+		if volumes[i] == 0 {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] /= volumes[i]
+	}
+	return out
+}
@@ -0,0 +1,437 @@
+/*
+Package arrow encodes the query results in the Apache Arrow IPC format, and decodes the Arrow data to write,
+so that the clients which are not written in Python can use any Arrow library instead of the NumpyDataset.
+The schemas, the record batches and their messages are those of the Go library of the Apache Arrow project.
+
+A ColumnSeries of a TimeBucketKey is one Arrow schema followed by one record batch. The key is in the
+"marketstore.key" metadata of the schema, and the element type of each column in the "marketstore.type"
+metadata of its field, so that a column keeps its type through a round trip:
+
+	EPOCH ("Epoch")        Timestamp(second, UTC)
+	INT16/32/64, BYTE      Int(16/32/64/8, signed)
+	UINT8/16/32/64         Int(8/16/32/64, unsigned)
+	FLOAT32/64             FloatingPoint(single/double)
+	BOOL                   Bool
+	STRING, STRING16       Utf8
+	BLOB                   Binary
+	DECIMAL64              Decimal(18, scale, 128 bits)
+
+The NULL marks of a column (see io.ColumnSeries.Nulls) are the validity bitmap of its field. The decoding also
+accepts the Timestamps of the other units, which are floored to seconds, and the Decimals whose values fit in 64
+bits. The Arrow data without a marketstore type, such as dictionaries or lists, are rejected.
+*/
+package arrow
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+
+	goarrow "github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/decimal128"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+const (
+	// KeyMetadata is the metadata of a schema holding its TimeBucketKey.
+	KeyMetadata = "marketstore.key"
+	// TypeMetadata is the metadata of a field holding the element type of its column.
+	TypeMetadata = "marketstore.type"
+
+	// decimalPrecision is the precision of the DECIMAL64 columns, the digits of an int64
+	decimalPrecision = 18
+)
+
+var errMalformed = errors.New("malformed arrow message")
+
+// recoverMalformed turns the panics of the decoding of a corrupted message into its error.
+func recoverMalformed(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%w: %v", errMalformed, r)
+	}
+}
+
+// column is the marketstore column of a field.
+type column struct {
+	name  string
+	typ   io.EnumElementType
+	scale int8
+}
+
+// newField returns the field of a column, with the scale of the DECIMAL64 data shapes.
+func newField(ds io.DataShape, nullable bool) (goarrow.Field, error) {
+	typ := ds.Type
+	if typ == io.INT64 && ds.Name == "Epoch" {
+		typ = io.EPOCH
+	}
+	f := goarrow.Field{
+		Name:     ds.Name,
+		Nullable: nullable,
+		Metadata: goarrow.NewMetadata([]string{TypeMetadata}, []string{typ.String()}),
+	}
+	switch typ {
+	case io.EPOCH:
+		f.Type = &goarrow.TimestampType{Unit: goarrow.Second, TimeZone: "UTC"}
+	case io.BYTE:
+		f.Type = goarrow.PrimitiveTypes.Int8
+	case io.INT16:
+		f.Type = goarrow.PrimitiveTypes.Int16
+	case io.INT32:
+		f.Type = goarrow.PrimitiveTypes.Int32
+	case io.INT64:
+		f.Type = goarrow.PrimitiveTypes.Int64
+	case io.UINT8:
+		f.Type = goarrow.PrimitiveTypes.Uint8
+	case io.UINT16:
+		f.Type = goarrow.PrimitiveTypes.Uint16
+	case io.UINT32:
+		f.Type = goarrow.PrimitiveTypes.Uint32
+	case io.UINT64:
+		f.Type = goarrow.PrimitiveTypes.Uint64
+	case io.FLOAT32:
+		f.Type = goarrow.PrimitiveTypes.Float32
+	case io.FLOAT64:
+		f.Type = goarrow.PrimitiveTypes.Float64
+	case io.BOOL:
+		f.Type = goarrow.FixedWidthTypes.Boolean
+	case io.STRING, io.STRING16:
+		f.Type = goarrow.BinaryTypes.String
+	case io.BLOB:
+		f.Type = goarrow.BinaryTypes.Binary
+	case io.DECIMAL64:
+		f.Type = &goarrow.Decimal128Type{Precision: decimalPrecision, Scale: int32(ds.Scale)}
+	default:
+		return f, fmt.Errorf("column %s of type %s can not be encoded in arrow", ds.Name, ds.Type)
+	}
+	return f, nil
+}
+
+// newColumn returns the column of a field, with the element type in its metadata when the arrow type can hold it.
+func newColumn(f *goarrow.Field) (column, error) {
+	c := column{name: f.Name}
+	switch t := f.Type.(type) {
+	case *goarrow.TimestampType:
+		c.typ = io.EPOCH
+	case *goarrow.Int8Type:
+		c.typ = io.BYTE
+	case *goarrow.Int16Type:
+		c.typ = io.INT16
+	case *goarrow.Int32Type:
+		c.typ = io.INT32
+	case *goarrow.Int64Type:
+		c.typ = io.INT64
+	case *goarrow.Uint8Type:
+		c.typ = io.UINT8
+	case *goarrow.Uint16Type:
+		c.typ = io.UINT16
+	case *goarrow.Uint32Type:
+		c.typ = io.UINT32
+	case *goarrow.Uint64Type:
+		c.typ = io.UINT64
+	case *goarrow.Float32Type:
+		c.typ = io.FLOAT32
+	case *goarrow.Float64Type:
+		c.typ = io.FLOAT64
+	case *goarrow.BooleanType:
+		c.typ = io.BOOL
+	case *goarrow.StringType:
+		c.typ = io.STRING
+		if i := f.Metadata.FindKey(TypeMetadata); i >= 0 && f.Metadata.Values()[i] == io.STRING16.String() {
+			c.typ = io.STRING16
+		}
+	case *goarrow.BinaryType:
+		c.typ = io.BLOB
+	case *goarrow.Decimal128Type:
+		if t.Scale < math.MinInt8 || t.Scale > math.MaxInt8 {
+			return c, fmt.Errorf("field %s has an unsupported decimal scale %d", f.Name, t.Scale)
+		}
+		c.typ, c.scale = io.DECIMAL64, int8(t.Scale)
+		if err := io.ValidateScale(c.scale); err != nil {
+			return c, fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	default:
+		return c, fmt.Errorf("field %s has the arrow type %s which has no marketstore type", f.Name, f.Type)
+	}
+	return c, nil
+}
+
+// newSchema returns the schema of the fields of a bucket.
+func newSchema(tbk io.TimeBucketKey, fields []goarrow.Field) *goarrow.Schema {
+	metadata := goarrow.NewMetadata([]string{KeyMetadata}, []string{tbk.String()})
+	return goarrow.NewSchema(fields, &metadata)
+}
+
+// schemaColumns returns the columns of the fields of a schema.
+func schemaColumns(schema *goarrow.Schema) ([]column, error) {
+	columns := make([]column, 0, len(schema.Fields()))
+	for i := range schema.Fields() {
+		c, err := newColumn(&schema.Fields()[i])
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	return columns, nil
+}
+
+// seriesFields returns the fields of the columns of the series, without their NULL marks.
+func seriesFields(cs *io.ColumnSeries) ([]goarrow.Field, error) {
+	var fields []goarrow.Field
+	for _, ds := range cs.GetDataShapes() {
+		if cs.IsNullColumn(ds.Name) {
+			continue
+		}
+		f, err := newField(ds, cs.Nulls(ds.Name) != nil)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// shapeFields returns the fields of the columns of a bucket, which are all nullable in a nullable bucket.
+func shapeFields(dsv []io.DataShape) ([]goarrow.Field, error) {
+	nullable := io.IsNullable(dsv)
+	var fields []goarrow.Field
+	for _, ds := range dsv {
+		if ds.Name == io.NullsColumn {
+			continue
+		}
+		f, err := newField(ds, nullable && ds.Name != "Epoch")
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// newRecord returns the record of the series with the fields of the schema.
+func newRecord(schema *goarrow.Schema, cs *io.ColumnSeries) (goarrow.Record, error) {
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	fields := schema.Fields()
+	for i := range fields {
+		f := &fields[i]
+		if err := checkColumn(cs, f); err != nil {
+			return nil, err
+		}
+		var valid []bool
+		if nulls := cs.Nulls(f.Name); nulls != nil {
+			valid = make([]bool, len(nulls))
+			for row, null := range nulls {
+				valid[row] = !null
+			}
+		}
+		if err := appendValues(b.Field(i), cs.GetColumn(f.Name), valid); err != nil {
+			return nil, fmt.Errorf("column %s: %w", f.Name, err)
+		}
+	}
+	return b.NewRecord(), nil
+}
+
+// checkColumn returns an error if the series has no column of the type of the field.
+func checkColumn(cs *io.ColumnSeries, f *goarrow.Field) error {
+	if !cs.Exists(f.Name) {
+		return fmt.Errorf("series without the column %s", f.Name)
+	}
+	c, err := newColumn(f)
+	if err != nil {
+		return err
+	}
+	typ := io.GetElementType(cs.GetColumn(f.Name))
+	if typ != c.typ && !(c.typ == io.EPOCH && typ == io.INT64) {
+		return fmt.Errorf("column %s of type %s instead of %s", f.Name, typ, c.typ)
+	}
+	if n := reflect.ValueOf(cs.GetColumn(f.Name)).Len(); n != cs.Len() {
+		return fmt.Errorf("column %s has %d rows instead of %d", f.Name, n, cs.Len())
+	}
+	if nulls := cs.Nulls(f.Name); nulls != nil && len(nulls) != cs.Len() {
+		return fmt.Errorf("column %s has %d NULL marks for %d rows", f.Name, len(nulls), cs.Len())
+	}
+	return nil
+}
+
+// appendValues appends the values of a column to the builder of its field, valid is nil without NULL marks.
+func appendValues(b array.Builder, col interface{}, valid []bool) error {
+	switch b := b.(type) {
+	case *array.TimestampBuilder:
+		epochs := col.([]int64)
+		values := make([]goarrow.Timestamp, len(epochs))
+		for i, v := range epochs {
+			values[i] = goarrow.Timestamp(v)
+		}
+		b.AppendValues(values, valid)
+	case *array.Int8Builder:
+		b.AppendValues(col.([]int8), valid)
+	case *array.Int16Builder:
+		b.AppendValues(col.([]int16), valid)
+	case *array.Int32Builder:
+		b.AppendValues(col.([]int32), valid)
+	case *array.Int64Builder:
+		b.AppendValues(col.([]int64), valid)
+	case *array.Uint8Builder:
+		b.AppendValues(col.([]uint8), valid)
+	case *array.Uint16Builder:
+		b.AppendValues(col.([]uint16), valid)
+	case *array.Uint32Builder:
+		b.AppendValues(col.([]uint32), valid)
+	case *array.Uint64Builder:
+		b.AppendValues(col.([]uint64), valid)
+	case *array.Float32Builder:
+		b.AppendValues(col.([]float32), valid)
+	case *array.Float64Builder:
+		b.AppendValues(col.([]float64), valid)
+	case *array.BooleanBuilder:
+		b.AppendValues(col.([]bool), valid)
+	case *array.StringBuilder:
+		values, ok := col.([]string)
+		if !ok {
+			runes := col.([][16]rune)
+			values = make([]string, len(runes))
+			for i, r := range runes {
+				values[i] = string16(r)
+			}
+		}
+		b.AppendValues(values, valid)
+	case *array.BinaryBuilder:
+		b.AppendValues(col.([][]byte), valid)
+	case *array.Decimal128Builder:
+		decimals := col.([]io.Decimal64)
+		values := make([]decimal128.Num, len(decimals))
+		for i, v := range decimals {
+			values[i] = decimal128.FromI64(int64(v))
+		}
+		b.AppendValues(values, valid)
+	default:
+		return fmt.Errorf("no arrow encoding of %s", b.Type())
+	}
+	return nil
+}
+
+// recordSeries returns the series of a record of the columns.
+func recordSeries(rec goarrow.Record, columns []column) (*io.ColumnSeries, error) {
+	cs := io.NewColumnSeries()
+	for i := range columns {
+		c := &columns[i]
+		arr := rec.Column(i)
+		if int64(arr.Len()) != rec.NumRows() {
+			return nil, fmt.Errorf("field %s has %d rows instead of %d", c.name, arr.Len(), rec.NumRows())
+		}
+		col, err := columnValues(c, arr)
+		if err != nil {
+			return nil, err
+		}
+		cs.AddColumn(c.name, col)
+		if c.typ == io.DECIMAL64 {
+			cs.SetScale(c.name, c.scale)
+		}
+		if arr.NullN() > 0 {
+			nulls := make([]bool, arr.Len())
+			for row := range nulls {
+				nulls[row] = arr.IsNull(row)
+			}
+			cs.SetNulls(c.name, nulls)
+		}
+	}
+	return cs, nil
+}
+
+// columnValues returns a copy of the values of an array of a column.
+func columnValues(c *column, arr goarrow.Array) (interface{}, error) {
+	switch a := arr.(type) {
+	case *array.Timestamp:
+		return timestampValues(a), nil
+	case *array.Int8:
+		return append([]int8{}, a.Int8Values()...), nil
+	case *array.Int16:
+		return append([]int16{}, a.Int16Values()...), nil
+	case *array.Int32:
+		return append([]int32{}, a.Int32Values()...), nil
+	case *array.Int64:
+		return append([]int64{}, a.Int64Values()...), nil
+	case *array.Uint8:
+		return append([]uint8{}, a.Uint8Values()...), nil
+	case *array.Uint16:
+		return append([]uint16{}, a.Uint16Values()...), nil
+	case *array.Uint32:
+		return append([]uint32{}, a.Uint32Values()...), nil
+	case *array.Uint64:
+		return append([]uint64{}, a.Uint64Values()...), nil
+	case *array.Float32:
+		return append([]float32{}, a.Float32Values()...), nil
+	case *array.Float64:
+		return append([]float64{}, a.Float64Values()...), nil
+	case *array.Boolean:
+		values := make([]bool, a.Len())
+		for i := range values {
+			values[i] = a.Value(i)
+		}
+		return values, nil
+	case *array.String:
+		if c.typ == io.STRING16 {
+			values := make([][16]rune, a.Len())
+			for i := range values {
+				values[i] = toString16(a.Value(i))
+			}
+			return values, nil
+		}
+		values := make([]string, a.Len())
+		for i := range values {
+			// the values of the array share the body of the message
+			values[i] = string([]byte(a.Value(i)))
+		}
+		return values, nil
+	case *array.Binary:
+		values := make([][]byte, a.Len())
+		for i := range values {
+			values[i] = append([]byte{}, a.Value(i)...)
+		}
+		return values, nil
+	case *array.Decimal128:
+		values := make([]io.Decimal64, a.Len())
+		for i := range values {
+			v := a.Value(i)
+			hi, lo := v.HighBits(), v.LowBits()
+			if !(hi == 0 && lo <= math.MaxInt64) && !(hi == -1 && lo > math.MaxInt64) {
+				return nil, fmt.Errorf("a value of the decimal field %s does not fit in 64 bits", c.name)
+			}
+			values[i] = io.Decimal64(int64(lo))
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("field %s has the arrow type %s which has no marketstore type", c.name, arr.DataType())
+}
+
+// timestampValues returns the epochs of the timestamps, rounded toward the past.
+func timestampValues(a *array.Timestamp) []int64 {
+	div := int64(goarrow.Second.Multiplier() / a.DataType().(*goarrow.TimestampType).Unit.Multiplier())
+	epochs := make([]int64, a.Len())
+	for i, v := range a.TimestampValues() {
+		epochs[i] = int64(v) / div
+		if int64(v)%div < 0 {
+			epochs[i]--
+		}
+	}
+	return epochs
+}
+
+// string16 returns the string of a STRING16 value, without its trailing zeros.
+func string16(r [16]rune) string {
+	n := len(r)
+	for n > 0 && r[n-1] == 0 {
+		n--
+	}
+	return string(r[:n])
+}
+
+// toString16 returns the STRING16 value of a string, truncated to 16 runes.
+func toString16(s string) (r [16]rune) {
+	copy(r[:], []rune(s))
+	return r
+}
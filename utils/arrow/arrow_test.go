@@ -0,0 +1,214 @@
+package arrow
+
+import (
+	"bytes"
+	"testing"
+
+	goarrow "github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/array"
+	"github.com/apache/arrow/go/v10/arrow/decimal128"
+	"github.com/apache/arrow/go/v10/arrow/ipc"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+func allTypesSeries() *io.ColumnSeries {
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1600000000, 1600000060, 1600000120})
+	cs.AddColumn("Open", []float32{1.5, 2.5, 3.5})
+	cs.AddColumn("Close", []float64{1.25, 2.25, 3.25})
+	cs.AddColumn("Count", []int32{-1, 0, 1})
+	cs.AddColumn("Volume", []int64{10, 20, 30})
+	cs.AddColumn("Short", []int16{-2, 0, 2})
+	cs.AddColumn("Byte", []int8{-3, 0, 3})
+	cs.AddColumn("U8", []uint8{1, 2, 3})
+	cs.AddColumn("U16", []uint16{1, 2, 3})
+	cs.AddColumn("U32", []uint32{1, 2, 3})
+	cs.AddColumn("U64", []uint64{1, 2, 1 << 63})
+	cs.AddColumn("Halted", []bool{true, false, true})
+	cs.AddColumn("Exchange", []string{"NYSE", "", "NASDAQ"})
+	cs.AddColumn("Condition", [][16]rune{{'a'}, {}, {'x', 'y'}})
+	cs.AddColumn("Payload", [][]byte{{0, 1}, {}, {2}})
+	cs.AddDecimalColumn("Price", []io.Decimal64{12345, -1, 0}, 4)
+	return cs
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	t.Parallel()
+	tbk := io.NewTimeBucketKey("AAPL/1Min/OHLCV")
+	cs := allTypesSeries()
+	cs.SetNulls("Open", []bool{false, true, false})
+	cs.SetNulls("Exchange", []bool{false, true, false})
+
+	data, err := MarshalStream(*tbk, cs)
+	require.Nil(t, err)
+	key, out, err := UnmarshalStream(data)
+	require.Nil(t, err)
+	assert.Equal(t, tbk, key)
+	assert.ElementsMatch(t, cs.GetDataShapes(), out.GetDataShapes())
+	for _, name := range cs.GetColumnNames() {
+		assert.Equal(t, cs.GetColumn(name), out.GetColumn(name), name)
+	}
+	assert.Equal(t, int8(4), out.Scale("Price"))
+	assert.Nil(t, out.Nulls("Close"))
+
+	// a series without rows keeps its columns
+	empty := io.NewColumnSeries()
+	empty.AddColumn("Epoch", []int64{})
+	empty.AddColumn("Byte", []int8{})
+	empty.AddDecimalColumn("Price", []io.Decimal64{}, 2)
+	data, err = MarshalStream(*tbk, empty)
+	require.Nil(t, err)
+	_, out, err = UnmarshalStream(data)
+	require.Nil(t, err)
+	assert.Equal(t, empty.GetDataShapes(), out.GetDataShapes())
+	assert.Equal(t, int8(2), out.Scale("Price"))
+}
+
+// writeStream writes the records of a schema with the writer of the arrow library, without its end.
+func writeStream(t *testing.T, schema *goarrow.Schema, build func(b *array.RecordBuilder)) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	build(b)
+	rec := b.NewRecord()
+	defer rec.Release()
+	require.Nil(t, w.Write(rec))
+	return buf.Bytes()
+}
+
+func TestStreamBatches(t *testing.T) {
+	t.Parallel()
+	// a stream without the key metadata, as written by the other arrow libraries
+	schema := goarrow.NewSchema([]goarrow.Field{
+		{Name: "Epoch", Type: &goarrow.TimestampType{Unit: goarrow.Second}},
+		{Name: "Price", Type: goarrow.PrimitiveTypes.Float64, Nullable: true},
+	}, nil)
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	b.Field(0).(*array.TimestampBuilder).AppendValues([]goarrow.Timestamp{1, 2}, nil)
+	b.Field(1).(*array.Float64Builder).AppendValues([]float64{1, 2}, nil)
+	first := b.NewRecord()
+	defer first.Release()
+	require.Nil(t, w.Write(first))
+	b.Field(0).(*array.TimestampBuilder).Append(3)
+	b.Field(1).(*array.Float64Builder).AppendNull()
+	second := b.NewRecord()
+	defer second.Release()
+	require.Nil(t, w.Write(second))
+
+	// the end of the stream is optional
+	key, cs, err := UnmarshalStream(buf.Bytes())
+	require.Nil(t, err)
+	assert.Nil(t, key)
+	assert.Equal(t, []int64{1, 2, 3}, cs.GetEpoch())
+	assert.Equal(t, []float64{1, 2, 0}, cs.GetColumn("Price"))
+	assert.Equal(t, []bool{false, false, true}, cs.Nulls("Price"))
+}
+
+func TestDecodeTimestampUnits(t *testing.T) {
+	t.Parallel()
+	schema := goarrow.NewSchema([]goarrow.Field{
+		{Name: "Epoch", Type: &goarrow.TimestampType{Unit: goarrow.Millisecond}},
+	}, nil)
+	data := writeStream(t, schema, func(b *array.RecordBuilder) {
+		b.Field(0).(*array.TimestampBuilder).AppendValues([]goarrow.Timestamp{1600000000123, -1500}, nil)
+	})
+	_, cs, err := UnmarshalStream(data)
+	require.Nil(t, err)
+	assert.Equal(t, []int64{1600000000, -2}, cs.GetEpoch())
+
+	// the arrow types without a marketstore type are rejected
+	schema = goarrow.NewSchema([]goarrow.Field{
+		{Name: "Values", Type: goarrow.ListOf(goarrow.PrimitiveTypes.Int64)},
+	}, nil)
+	data = writeStream(t, schema, func(b *array.RecordBuilder) {
+		b.Field(0).(*array.ListBuilder).Append(true)
+	})
+	_, _, err = UnmarshalStream(data)
+	assert.NotNil(t, err)
+}
+
+func TestArrowLibraryReader(t *testing.T) {
+	t.Parallel()
+	tbk := io.NewTimeBucketKey("AAPL/1Min/OHLCV")
+	cs := allTypesSeries()
+	cs.SetNulls("Close", []bool{true, false, false})
+	data, err := MarshalStream(*tbk, cs)
+	require.Nil(t, err)
+
+	// the streams are read by the reader of the arrow library
+	r, err := ipc.NewReader(bytes.NewReader(data))
+	require.Nil(t, err)
+	defer r.Release()
+	schema := r.Schema()
+	i := schema.Metadata().FindKey(KeyMetadata)
+	require.True(t, i >= 0)
+	assert.Equal(t, tbk.String(), schema.Metadata().Values()[i])
+	assert.Equal(t, "timestamp[s, tz=UTC]", schema.Field(0).Type.String())
+	assert.Equal(t, "decimal(18, 4)", schema.Field(schema.FieldIndices("Price")[0]).Type.String())
+	require.True(t, r.Next())
+	rec := r.Record()
+	assert.Equal(t, int64(3), rec.NumRows())
+	epochs := rec.Column(0).(*array.Timestamp).TimestampValues()
+	assert.Equal(t, []goarrow.Timestamp{1600000000, 1600000060, 1600000120}, epochs)
+	closes := rec.Column(schema.FieldIndices("Close")[0]).(*array.Float64)
+	assert.True(t, closes.IsNull(0))
+	assert.Equal(t, 2.25, closes.Value(1))
+	assert.Equal(t, "NASDAQ", rec.Column(schema.FieldIndices("Exchange")[0]).(*array.String).Value(2))
+	assert.Equal(t, "xy", rec.Column(schema.FieldIndices("Condition")[0]).(*array.String).Value(2))
+	assert.Equal(t, decimal128.FromI64(-1), rec.Column(schema.FieldIndices("Price")[0]).(*array.Decimal128).Value(1))
+	assert.False(t, r.Next())
+	assert.Nil(t, r.Err())
+
+	// so are the messages of an encoder, e.g. in Arrow Flight
+	enc, err := NewSeriesEncoder(*tbk, cs)
+	require.Nil(t, err)
+	batch, err := enc.Encode(cs)
+	require.Nil(t, err)
+	var buf bytes.Buffer
+	require.Nil(t, WriteMessage(&buf, enc.Schema()))
+	require.Nil(t, WriteMessage(&buf, batch))
+	r, err = ipc.NewReader(&buf)
+	require.Nil(t, err)
+	defer r.Release()
+	require.True(t, r.Next())
+	assert.Equal(t, int64(3), r.Record().NumRows())
+}
+
+func TestMalformed(t *testing.T) {
+	t.Parallel()
+	data, err := MarshalStream(*io.NewTimeBucketKey("AAPL/1Min/OHLCV"), allTypesSeries())
+	require.Nil(t, err)
+
+	// the truncated and corrupted streams return an error without panicking, a stream cut after its schema has no rows
+	for n := 0; n < len(data)-8; n += 7 {
+		_, cs, err := UnmarshalStream(data[:n])
+		assert.True(t, err != nil || cs.Len() == 0, n)
+	}
+	for i := 8; i < len(data); i += 3 {
+		corrupted := append([]byte{}, data...)
+		corrupted[i] ^= 0xA5
+		assert.NotPanics(t, func() { _, _, _ = UnmarshalStream(corrupted) }, i)
+	}
+
+	// a record batch needs a schema
+	_, batch, err := EncodeSeries(io.TimeBucketKey{}, allTypesSeries())
+	require.Nil(t, err)
+	_, err = (&Decoder{}).Decode(batch)
+	assert.NotNil(t, err)
+
+	cs := io.NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1})
+	cs.AddColumn("Other", []int{1})
+	_, err = MarshalStream(io.TimeBucketKey{}, cs)
+	assert.NotNil(t, err)
+}
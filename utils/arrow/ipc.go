@@ -0,0 +1,506 @@
+package arrow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	goio "io"
+	"reflect"
+
+	goarrow "github.com/apache/arrow/go/v10/arrow"
+	"github.com/apache/arrow/go/v10/arrow/ipc"
+	"github.com/apache/arrow/go/v10/arrow/memory"
+	flatbuffers "github.com/google/flatbuffers/go"
+
+	"github.com/alpacahq/marketstore/v4/utils/io"
+)
+
+// ContentType is the media type of the Arrow IPC streams.
+const ContentType = "application/vnd.apache.arrow.stream"
+
+// continuation marks the start of an encapsulated message, a zero length after it marks the end of a stream
+const continuation = 0xFFFFFFFF
+
+// maxMessageSize bounds the metadata and the body of the messages read from a stream
+const maxMessageSize = 1 << 31
+
+// maxInflation bounds the buffers of a record batch by the size of its body, once decompressed
+const maxInflation = 256
+
+// Message is an Arrow IPC message: its flatbuffer metadata and its body, which is empty for a Schema.
+type Message struct {
+	Header []byte
+	Body   []byte
+}
+
+// EncodeSeries returns the Schema and the RecordBatch messages of the series of a bucket.
+func EncodeSeries(tbk io.TimeBucketKey, cs *io.ColumnSeries) (schema, batch Message, err error) {
+	e, err := NewSeriesEncoder(tbk, cs)
+	if err != nil {
+		return schema, batch, err
+	}
+	batch, err = e.Encode(cs)
+	return e.Schema(), batch, err
+}
+
+// Encoder encodes the series of a bucket as the record batches of a single schema.
+type Encoder struct {
+	schema        *goarrow.Schema
+	schemaMessage Message
+}
+
+// NewSeriesEncoder returns the Encoder of the columns of a series.
+func NewSeriesEncoder(tbk io.TimeBucketKey, cs *io.ColumnSeries) (*Encoder, error) {
+	fields, err := seriesFields(cs)
+	if err != nil {
+		return nil, err
+	}
+	return newEncoder(newSchema(tbk, fields))
+}
+
+// NewShapeEncoder returns the Encoder of the columns of a bucket.
+func NewShapeEncoder(tbk io.TimeBucketKey, dsv []io.DataShape) (*Encoder, error) {
+	fields, err := shapeFields(dsv)
+	if err != nil {
+		return nil, err
+	}
+	return newEncoder(newSchema(tbk, fields))
+}
+
+func newEncoder(schema *goarrow.Schema) (*Encoder, error) {
+	var p payloads
+	// a writer closed without records writes the schema alone
+	if err := ipc.NewWriterWithPayloadWriter(&p, ipc.WithSchema(schema)).Close(); err != nil {
+		return nil, err
+	}
+	if len(p) != 1 {
+		return nil, fmt.Errorf("arrow schema encoded in %d messages", len(p))
+	}
+	return &Encoder{schema: schema, schemaMessage: p[0]}, nil
+}
+
+// Schema returns the Schema message, which comes before the record batches.
+func (e *Encoder) Schema() Message {
+	return e.schemaMessage
+}
+
+// Encode returns the RecordBatch message of a series, which must have the columns of the schema.
+func (e *Encoder) Encode(cs *io.ColumnSeries) (batch Message, err error) {
+	rec, err := newRecord(e.schema, cs)
+	if err != nil {
+		return batch, err
+	}
+	defer rec.Release()
+	var p payloads
+	if err = ipc.NewWriterWithPayloadWriter(&p, ipc.WithSchema(e.schema)).Write(rec); err != nil {
+		return batch, err
+	}
+	// the writer writes the schema before its first record batch
+	return p[len(p)-1], nil
+}
+
+// payloads is the ipc.PayloadWriter of the messages of an Encoder.
+type payloads []Message
+
+func (p *payloads) Start() error { return nil }
+
+func (p *payloads) Close() error { return nil }
+
+func (p *payloads) WritePayload(payload ipc.Payload) error {
+	meta := payload.Meta()
+	defer meta.Release()
+	var body bytes.Buffer
+	if err := payload.SerializeBody(&body); err != nil {
+		return err
+	}
+	*p = append(*p, Message{Header: append([]byte{}, meta.Bytes()...), Body: body.Bytes()})
+	return nil
+}
+
+// Decoder decodes the messages of a stream: a Schema followed by RecordBatches.
+type Decoder struct {
+	key     *io.TimeBucketKey
+	schema  []byte
+	columns []column
+}
+
+/*
+Decode returns the series of a RecordBatch message, and nil for the Schema message which must come first.
+A new Schema replaces the previous one.
+*/
+func (d *Decoder) Decode(m Message) (cs *io.ColumnSeries, err error) {
+	defer recoverMalformed(&err)
+	msg := newMessage(m)
+	defer msg.Release()
+	switch msg.Type() {
+	case ipc.MessageSchema:
+		return nil, d.decodeSchema(m, msg)
+	case ipc.MessageRecordBatch:
+		if d.columns == nil {
+			return nil, fmt.Errorf("arrow record batch before the schema")
+		}
+		if msg.BodyLen() > int64(len(m.Body)) {
+			return nil, errMalformed
+		}
+		schema := newMessage(Message{Header: d.schema})
+		defer schema.Release()
+		mem := &boundedAllocator{left: maxInflation * len(m.Body)}
+		r, err := ipc.NewReaderFromMessageReader(&messages{schema, msg}, ipc.WithAllocator(mem))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Release()
+		if !r.Next() {
+			if r.Err() != nil {
+				return nil, fmt.Errorf("%w: %v", errMalformed, r.Err())
+			}
+			return nil, errMalformed
+		}
+		rec := r.Record()
+		// each column takes at least a bit per row, a larger length would only allocate
+		if len(d.columns) > 0 && rec.NumRows() > int64(len(m.Body))*8 {
+			return nil, errMalformed
+		}
+		return recordSeries(rec, d.columns)
+	default:
+		return nil, fmt.Errorf("unsupported arrow message type %s", msg.Type())
+	}
+}
+
+func (d *Decoder) decodeSchema(m Message, msg *ipc.Message) error {
+	if err := checkSchema(m.Header); err != nil {
+		return err
+	}
+	r, err := ipc.NewReaderFromMessageReader(&messages{msg})
+	if err != nil {
+		return err
+	}
+	defer r.Release()
+	columns, err := schemaColumns(r.Schema())
+	if err != nil {
+		return err
+	}
+	d.key, d.schema, d.columns = nil, append([]byte{}, m.Header...), columns
+	metadata := r.Schema().Metadata()
+	if i := metadata.FindKey(KeyMetadata); i >= 0 && metadata.Values()[i] != "" {
+		d.key = io.NewTimeBucketKeyFromString(metadata.Values()[i])
+	}
+	return nil
+}
+
+// the slots of the tables of the Arrow format (Message.fbs and Schema.fbs) that checkSchema reads
+const (
+	slotMessageHeader  = 2
+	slotSchemaFields   = 1
+	slotSchemaMetadata = 2
+	slotFieldChildren  = 5
+	slotFieldMetadata  = 6
+
+	// maxNesting bounds the depth of the children of the fields, as the arrow library does
+	maxNesting = 64
+)
+
+/*
+checkSchema returns errMalformed if a vector of a Schema message has more elements than the message has bytes,
+or if its fields are nested too deep: the reader of the arrow library allocates the elements of the vectors before
+reading them, and it follows the children of the fields without a bound.
+*/
+func checkSchema(header []byte) error {
+	if len(header) < flatbuffers.SizeUOffsetT {
+		return errMalformed
+	}
+	msg := flatbuffers.Table{Bytes: header, Pos: flatbuffers.GetUOffsetT(header)}
+	o := slot(msg, slotMessageHeader)
+	if o == 0 {
+		return errMalformed
+	}
+	var schema flatbuffers.Table
+	msg.Union(&schema, o)
+	return checkTables(&schema, slotSchemaFields, slotSchemaMetadata, 0)
+}
+
+// slot returns the offset of a slot of a table, 0 if the table does not have it.
+func slot(t flatbuffers.Table, i int) flatbuffers.UOffsetT {
+	return flatbuffers.UOffsetT(t.Offset(flatbuffers.VOffsetT(flatbuffers.VtableMetadataFields+i) * 2))
+}
+
+// checkTables checks the metadata and the fields of a Schema or of a Field, and the fields of those fields.
+func checkTables(t *flatbuffers.Table, fieldsSlot, metadataSlot, depth int) error {
+	if depth > maxNesting {
+		return errMalformed
+	}
+	if o := slot(*t, metadataSlot); o != 0 && t.VectorLen(o) > len(t.Bytes) {
+		return errMalformed
+	}
+	o := slot(*t, fieldsSlot)
+	if o == 0 {
+		return nil
+	}
+	n := t.VectorLen(o)
+	if n > len(t.Bytes) {
+		return errMalformed
+	}
+	start := t.Vector(o)
+	for i := 0; i < n; i++ {
+		field := flatbuffers.Table{Bytes: t.Bytes, Pos: t.Indirect(start + flatbuffers.UOffsetT(i*flatbuffers.SizeUOffsetT))}
+		if err := checkTables(&field, slotFieldChildren, slotFieldMetadata, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Key returns the TimeBucketKey in the metadata of the schema, nil if it has none.
+func (d *Decoder) Key() *io.TimeBucketKey {
+	return d.key
+}
+
+func newMessage(m Message) *ipc.Message {
+	return ipc.NewMessage(memory.NewBufferBytes(m.Header), memory.NewBufferBytes(m.Body))
+}
+
+// messages is the ipc.MessageReader of the messages of a Decode.
+type messages []*ipc.Message
+
+func (ms *messages) Message() (*ipc.Message, error) {
+	if len(*ms) == 0 {
+		return nil, goio.EOF
+	}
+	m := (*ms)[0]
+	*ms = (*ms)[1:]
+	return m, nil
+}
+
+func (ms *messages) Retain() {}
+
+func (ms *messages) Release() {}
+
+/*
+boundedAllocator fails the allocations of a decoding beyond a total size, which the lengths of the buffers of
+a corrupted record batch would exceed. The decoding recovers its panic.
+*/
+type boundedAllocator struct {
+	left int
+}
+
+func (a *boundedAllocator) reserve(size int) {
+	if size > a.left {
+		panic(errMalformed)
+	}
+	a.left -= size
+}
+
+func (a *boundedAllocator) Allocate(size int) []byte {
+	a.reserve(size)
+	return memory.DefaultAllocator.Allocate(size)
+}
+
+func (a *boundedAllocator) Reallocate(size int, b []byte) []byte {
+	a.reserve(size - len(b))
+	return memory.DefaultAllocator.Reallocate(size, b)
+}
+
+func (a *boundedAllocator) Free(b []byte) {
+	a.left += len(b)
+	memory.DefaultAllocator.Free(b)
+}
+
+// WriteMessage writes an encapsulated message to a stream.
+func WriteMessage(w goio.Writer, m Message) error {
+	var prefix [8]byte
+	binary.LittleEndian.PutUint32(prefix[:], continuation)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(len(m.Header)))
+	for _, b := range [][]byte{prefix[:], m.Header, m.Body} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+ReadMessage reads an encapsulated message from a stream, it returns io.EOF at the end of the stream. Unlike the
+reader of the arrow library, it bounds the sizes of the message by the data left in the stream.
+*/
+func ReadMessage(r goio.Reader) (m Message, err error) {
+	var prefix [4]byte
+	if _, err = goio.ReadFull(r, prefix[:]); err != nil {
+		return m, err
+	}
+	size := binary.LittleEndian.Uint32(prefix[:])
+	// the streams of the old format have no continuation
+	if size == continuation {
+		if _, err = goio.ReadFull(r, prefix[:]); err != nil {
+			return m, unexpectedEOF(err)
+		}
+		size = binary.LittleEndian.Uint32(prefix[:])
+	}
+	if size == 0 {
+		return m, goio.EOF
+	}
+	if size >= maxMessageSize {
+		return m, errMalformed
+	}
+	if exceeds(r, int64(size)) {
+		return m, goio.ErrUnexpectedEOF
+	}
+	m.Header = make([]byte, size)
+	if _, err = goio.ReadFull(r, m.Header); err != nil {
+		return m, unexpectedEOF(err)
+	}
+	bodyLength, err := messageBodyLength(m.Header)
+	if err != nil {
+		return m, err
+	}
+	if exceeds(r, bodyLength) {
+		return m, goio.ErrUnexpectedEOF
+	}
+	m.Body = make([]byte, bodyLength)
+	if _, err = goio.ReadFull(r, m.Body); err != nil {
+		return m, unexpectedEOF(err)
+	}
+	return m, nil
+}
+
+// exceeds returns whether n is more than the data left in a reader in memory, which a corrupted length would allocate.
+func exceeds(r goio.Reader, n int64) bool {
+	l, ok := r.(interface{ Len() int })
+	return ok && n > int64(l.Len())
+}
+
+func messageBodyLength(header []byte) (n int64, err error) {
+	defer recoverMalformed(&err)
+	msg := newMessage(Message{Header: header})
+	defer msg.Release()
+	n = msg.BodyLen()
+	if n < 0 || n >= maxMessageSize {
+		return 0, errMalformed
+	}
+	return n, nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, goio.EOF) {
+		return goio.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// MarshalStream returns the Arrow IPC stream of the series of a bucket.
+func MarshalStream(tbk io.TimeBucketKey, cs *io.ColumnSeries) ([]byte, error) {
+	fields, err := seriesFields(cs)
+	if err != nil {
+		return nil, err
+	}
+	schema := newSchema(tbk, fields)
+	rec, err := newRecord(schema, cs)
+	if err != nil {
+		return nil, err
+	}
+	defer rec.Release()
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema))
+	if err = w.Write(rec); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalSchema returns the encapsulated Schema message of the columns of a bucket, e.g. for Arrow Flight.
+func MarshalSchema(tbk io.TimeBucketKey, dsv []io.DataShape) ([]byte, error) {
+	e, err := NewShapeEncoder(tbk, dsv)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	_ = WriteMessage(&buf, e.Schema())
+	return buf.Bytes(), nil
+}
+
+/*
+UnmarshalStream returns the TimeBucketKey in the schema metadata of an Arrow IPC stream, nil if it has none,
+and the series of its record batches, which are concatenated.
+*/
+func UnmarshalStream(data []byte) (*io.TimeBucketKey, *io.ColumnSeries, error) {
+	r := bytes.NewReader(data)
+	d := &Decoder{}
+	var cs *io.ColumnSeries
+	for {
+		m, err := ReadMessage(r)
+		if errors.Is(err, goio.EOF) {
+			break
+		} else if err != nil {
+			return nil, nil, err
+		}
+		columns := d.columns
+		batch, err := d.Decode(m)
+		if err != nil {
+			return nil, nil, err
+		}
+		if batch != nil {
+			cs = appendSeries(cs, batch)
+		} else if columns != nil {
+			return nil, nil, fmt.Errorf("arrow stream with more than one schema")
+		}
+	}
+	if d.columns == nil {
+		return nil, nil, fmt.Errorf("arrow stream without a schema")
+	}
+	if cs == nil {
+		// a stream without rows still has the columns of its schema
+		cs = io.NewColumnSeries()
+		for _, c := range d.columns {
+			cs.AddColumn(c.name, emptyColumn(c.typ))
+			if c.typ == io.DECIMAL64 {
+				cs.SetScale(c.name, c.scale)
+			}
+		}
+	}
+	return d.key, cs, nil
+}
+
+// appendSeries returns the rows of b appended to the rows of a, nil for the first batch, with the same schema.
+func appendSeries(a, b *io.ColumnSeries) *io.ColumnSeries {
+	if a == nil {
+		return b
+	}
+	out := io.NewColumnSeries()
+	for _, name := range a.GetColumnNames() {
+		if a.IsNullColumn(name) {
+			continue
+		}
+		col := reflect.AppendSlice(reflect.ValueOf(a.GetColumn(name)), reflect.ValueOf(b.GetColumn(name)))
+		out.AddColumn(name, col.Interface())
+		out.CopyScale(name, a, name)
+		aNulls, bNulls := a.Nulls(name), b.Nulls(name)
+		if aNulls == nil && bNulls == nil {
+			continue
+		}
+		nulls := make([]bool, 0, col.Len())
+		nulls = append(nulls, fill(aNulls, a.Len())...)
+		nulls = append(nulls, fill(bNulls, b.Len())...)
+		out.SetNulls(name, nulls)
+	}
+	return out
+}
+
+// fill returns the NULL marks of a column, none if it has no marks.
+func fill(nulls []bool, n int) []bool {
+	if nulls == nil {
+		return make([]bool, n)
+	}
+	return nulls
+}
+
+// emptyColumn returns a column of no rows of the type.
+func emptyColumn(typ io.EnumElementType) interface{} {
+	if typ == io.BYTE {
+		// the BYTE columns are int8, see ConvertByteSliceInto
+		return []int8{}
+	}
+	return typ.SliceOf(0)
+}
@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AuthVerbs are the verbs of the ACL rules.
+var AuthVerbs = []string{"read", "write", "create", "destroy", "admin"}
+
+type AuthSetting struct {
+	// APIKeys are the users of the static API keys, by key
+	APIKeys map[string]string
+	// TokenSecret is the HMAC-SHA256 key of the signed tokens, they are not accepted if empty
+	TokenSecret string
+	// GRPCTLS is the TLS of the gRPC API, whose client certificates authenticate their Common Name
+	GRPCTLS AuthTLSSetting
+	// PGTLS is the TLS of the PostgreSQL server, the passwords are only accepted over it
+	PGTLS AuthTLSSetting
+	// AllowedOrigins are the origins of the websocket connections from browsers, only the same origin if empty
+	AllowedOrigins []string
+	// Users are the roles of the users, by user
+	Users map[string][]string
+	// Roles are the rules of the roles, by role
+	Roles map[string][]ACLRule
+}
+
+type AuthTLSSetting struct {
+	CertFile string
+	KeyFile  string
+	// ClientCAFile is the certificate of the CAs of the client certificates, they are not asked for if empty
+	ClientCAFile string
+}
+
+// ACLRule allows its verbs on the buckets matching one of its keys.
+type ACLRule struct {
+	// Keys are TimeBucketKeys whose items can be glob patterns, e.g. "*/1Min/OHLCV"
+	Keys  []string
+	Verbs []string
+}
+
+// Enabled returns true if the requests must be authenticated.
+func (s *AuthSetting) Enabled() bool {
+	return len(s.APIKeys) != 0 || s.TokenSecret != "" || s.GRPCTLS.ClientCAFile != ""
+}
+
+// validate checks that the roles of the users exist and that the rules are well-formed.
+func (s *AuthSetting) validate() error {
+	if s.GRPCTLS.ClientCAFile != "" && (s.GRPCTLS.CertFile == "" || s.GRPCTLS.KeyFile == "") {
+		return fmt.Errorf("the client certificates of the gRPC API need its cert_file and key_file")
+	}
+	for key, user := range s.APIKeys {
+		if key == "" || user == "" {
+			return fmt.Errorf("an API key and its user can not be empty")
+		}
+	}
+	for user, roles := range s.Users {
+		for _, role := range roles {
+			if _, ok := s.Roles[role]; !ok {
+				return fmt.Errorf("unknown role %q of the user %s", role, user)
+			}
+		}
+	}
+	for role, rules := range s.Roles {
+		for _, rule := range rules {
+			for _, key := range rule.Keys {
+				if len(strings.Split(key, "/")) != 3 {
+					return fmt.Errorf("invalid key %q of the role %s, it must be like */1Min/OHLCV", key, role)
+				}
+			}
+			for _, verb := range rule.Verbs {
+				if !isAuthVerb(verb) {
+					return fmt.Errorf("invalid verb %q of the role %s, it must be one of %s", verb, role,
+						strings.Join(AuthVerbs, ", "))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func isAuthVerb(verb string) bool {
+	for _, v := range AuthVerbs {
+		if v == verb {
+			return true
+		}
+	}
+	return false
+}
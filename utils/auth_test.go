@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAuth(t *testing.T) {
+	t.Parallel()
+	config := []byte(`
+root_directory: data
+listen_port: 5993
+auth:
+  api_keys:
+    key1: dashboard
+  token_secret: secret
+  allowed_origins: ["https://dashboard.example.com"]
+  users:
+    dashboard: [reader]
+  roles:
+    reader:
+      - keys: ["*/*/*"]
+        verbs: [read]
+    feeder:
+      - keys: ["*/1Min/OHLCV", "*/1Sec/TICK"]
+        verbs: [write, create]
+`)
+	m := &MktsConfig{}
+	_, err := m.Parse(config)
+	assert.Nil(t, err)
+	assert.True(t, m.Auth.Enabled())
+	assert.Equal(t, map[string]string{"key1": "dashboard"}, m.Auth.APIKeys)
+	assert.Equal(t, "secret", m.Auth.TokenSecret)
+	assert.Equal(t, []string{"https://dashboard.example.com"}, m.Auth.AllowedOrigins)
+	assert.Equal(t, []string{"reader"}, m.Auth.Users["dashboard"])
+	assert.Equal(t, []ACLRule{{Keys: []string{"*/*/*"}, Verbs: []string{"read"}}}, m.Auth.Roles["reader"])
+	assert.Len(t, m.Auth.Roles["feeder"], 1)
+
+	m = &MktsConfig{}
+	_, err = m.Parse([]byte("root_directory: data\nlisten_port: 5993\n"))
+	assert.Nil(t, err)
+	assert.False(t, m.Auth.Enabled())
+
+	for _, auth := range []string{
+		"  users:\n    dashboard: [unknown]\n",
+		"  roles:\n    reader:\n      - keys: [AAPL]\n        verbs: [read]\n",
+		"  roles:\n    reader:\n      - keys: [\"*/*/*\"]\n        verbs: [delete]\n",
+		"  grpc_tls:\n    client_ca_file: ca.crt\n",
+	} {
+		_, err = m.Parse([]byte("root_directory: data\nlisten_port: 5993\nauth:\n" + auth))
+		assert.NotNil(t, err, auth)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Package blob implements the object stores where the years of the tiered storage are offloaded: a directory of
+the local filesystem (e.g. a network mount, or for tests) and an S3-compatible API.
+
+The keys are slash separated paths, e.g. "AAPL/1Min/OHLCV/2010.bin".
+*/
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotFound is returned when an object does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Store is an object store.
+type Store interface {
+	// Put stores the size bytes read from r under key, replacing the object if it exists.
+	Put(key string, r io.Reader, size int64) error
+	// Get returns a reader of the object stored under key, or ErrNotFound.
+	Get(key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key, it does not fail if the object does not exist.
+	Delete(key string) error
+}
+
+const (
+	localDirPerm  = 0o700
+	localFilePerm = 0o600
+)
+
+// Local stores the objects as files under a directory of the local filesystem.
+type Local struct {
+	dir string
+}
+
+// NewLocal returns a store of the objects in dir, which is created if needed.
+func NewLocal(dir string) (*Local, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(dir, localDirPerm); err != nil {
+		return nil, fmt.Errorf("create blob directory %s: %w", dir, err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	path := filepath.Join(l.dir, filepath.FromSlash(key))
+	if rel, err := filepath.Rel(l.dir, path); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return path, nil
+}
+
+func (l *Local) Put(key string, r io.Reader, size int64) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), localDirPerm); err != nil {
+		return err
+	}
+	// the object replaces the previous one once it is complete
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, localFilePerm)
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(f, r)
+	if err == nil && n != size {
+		err = fmt.Errorf("put %s: %d bytes read instead of %d", key, n, size)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+	}
+	return err
+}
+
+func (l *Local) Get(key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s: %w", key, ErrNotFound)
+	}
+	return f, err
+}
+
+func (l *Local) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,115 @@
+package blob_test
+
+import (
+	"bytes"
+	goio "io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/alpacahq/marketstore/v4/utils/blob"
+)
+
+func testStore(t *testing.T, store blob.Store) {
+	t.Helper()
+
+	data := []byte("year file")
+	require.Nil(t, store.Put("AAPL/1Min/OHLCV/2010.bin", bytes.NewReader(data), int64(len(data))))
+	r, err := store.Get("AAPL/1Min/OHLCV/2010.bin")
+	require.Nil(t, err)
+	got, err := goio.ReadAll(r)
+	require.Nil(t, err)
+	require.Nil(t, r.Close())
+	assert.Equal(t, data, got)
+
+	// an object is replaced
+	require.Nil(t, store.Put("AAPL/1Min/OHLCV/2010.bin", bytes.NewReader(data[:4]), 4))
+	r, err = store.Get("AAPL/1Min/OHLCV/2010.bin")
+	require.Nil(t, err)
+	got, _ = goio.ReadAll(r)
+	_ = r.Close()
+	assert.Equal(t, data[:4], got)
+
+	require.Nil(t, store.Delete("AAPL/1Min/OHLCV/2010.bin"))
+	require.Nil(t, store.Delete("AAPL/1Min/OHLCV/2010.bin"))
+	_, err = store.Get("AAPL/1Min/OHLCV/2010.bin")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+func TestLocal(t *testing.T) {
+	t.Parallel()
+
+	store, err := blob.NewLocal(t.TempDir())
+	require.Nil(t, err)
+	testStore(t, store)
+
+	// the keys can not address a file out of the directory
+	_, err = store.Get("../2010.bin")
+	assert.NotNil(t, err)
+	// the size of the data is checked
+	assert.NotNil(t, store.Put("2010.bin", strings.NewReader("abc"), 4))
+}
+
+// fakeS3 serves the objects of a bucket, like the API of S3.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	auth    []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := goio.ReadAll(r.Body)
+		f.objects[r.URL.Path] = data
+	case http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := blob.NewS3(blob.S3Config{
+		Endpoint:        server.URL,
+		Region:          "us-east-1",
+		Bucket:          "bucket",
+		Prefix:          "mkts/",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+	})
+	require.Nil(t, err)
+	testStore(t, store)
+
+	require.Nil(t, store.Put("AAPL/1D/OHLCV/2010.bin", strings.NewReader("abc"), 3))
+	assert.Equal(t, []byte("abc"), fake.objects["/bucket/mkts/AAPL/1D/OHLCV/2010.bin"])
+	for _, auth := range fake.auth {
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/"), auth)
+		assert.Contains(t, auth, "/us-east-1/s3/aws4_request, SignedHeaders=host;x-amz-content-sha256;x-amz-date,")
+	}
+
+	_, err = blob.NewS3(blob.S3Config{Endpoint: "not a url", Region: "us-east-1", Bucket: "bucket"})
+	assert.NotNil(t, err)
+	_, err = blob.NewS3(blob.S3Config{Endpoint: server.URL, Region: "us-east-1"})
+	assert.NotNil(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -30,6 +31,34 @@ type ReplicationSetting struct {
 	RetryBackoffCoeff int
 }
 
+type TieredStorageSetting struct {
+	// Backend is the blob store of the offloaded years, "local" or "s3", the tiered storage is disabled if empty
+	Backend string
+	// OffloadAfterYears is the age of the years to offload, e.g. 5 to offload the years before the last 5 ones
+	OffloadAfterYears int
+	CacheDirectory    string
+	CacheMaxBytes     int64 // 0 if unlimited
+	LocalDirectory    string
+	S3                S3Setting
+}
+
+type S3Setting struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+type CompactionSetting struct {
+	// Interval between two background compactions of the variable length buckets, disabled if 0
+	Interval time.Duration
+	// MinDeadRatio is the part of the rows of a year file that must be dead space for it to be compacted
+	MinDeadRatio float64
+}
+
 type TriggerSetting struct {
 	Module string
 	On     string
@@ -45,8 +74,10 @@ type BgWorkerSetting struct {
 type MktsConfig struct {
 	// RootDirectory is the absolute path to the data directory
 	RootDirectory              string
+	BackupDirectory            string // absolute path to the directory of the server backups, empty if disabled
 	ListenURL                  string
 	GRPCListenURL              string
+	PGListenURL                string
 	GRPCMaxSendMsgSize         int // in bytes
 	GRPCMaxRecvMsgSize         int // in bytes
 	UtilitiesURL               string
@@ -55,6 +86,7 @@ type MktsConfig struct {
 	StopGracePeriod            time.Duration
 	WALRotateInterval          int
 	DisableVariableCompression bool
+	WriteAutoCreate            bool // the /write endpoint creates the buckets that don't exist
 	InitCatalog                bool
 	InitWALCache               bool
 	BackgroundSync             bool
@@ -62,6 +94,10 @@ type MktsConfig struct {
 	ClusterMode                bool
 	StartTime                  time.Time
 	Replication                ReplicationSetting
+	TieredStorage              TieredStorageSetting
+	Retention                  RetentionSetting
+	Compaction                 CompactionSetting
+	Auth                       AuthSetting
 	Triggers                   []*TriggerSetting
 	BgWorkers                  []*BgWorkerSetting
 }
@@ -70,9 +106,11 @@ func (m *MktsConfig) Parse(data []byte) (*MktsConfig, error) {
 	var aux struct {
 		// RootDirectory can be either a relative or absolute path
 		RootDirectory              string `yaml:"root_directory"`
+		BackupDirectory            string `yaml:"backup_directory"`
 		ListenHost                 string `yaml:"listen_host"`
 		ListenPort                 string `yaml:"listen_port"`
 		GRPCListenPort             string `yaml:"grpc_listen_port"`
+		PGListenPort               string `yaml:"pg_listen_port"`
 		GRPCMaxSendMsgSize         int    `yaml:"grpc_max_send_msg_size"` // in MB
 		GRPCMaxRecvMsgSize         int    `yaml:"grpc_max_recv_msg_size"` // in MB
 		UtilitiesURL               string `yaml:"utilities_url"`
@@ -82,6 +120,7 @@ func (m *MktsConfig) Parse(data []byte) (*MktsConfig, error) {
 		StopGracePeriod            int    `yaml:"stop_grace_period"`
 		WALRotateInterval          int    `yaml:"wal_rotate_interval"`
 		DisableVariableCompression string `yaml:"disable_variable_compression"`
+		WriteAutoCreate            string `yaml:"write_auto_create"`
 		InitCatalog                string `yaml:"init_catalog"`
 		InitWALCache               string `yaml:"init_wal_cache"`
 		BackgroundSync             string `yaml:"background_sync"`
@@ -98,6 +137,50 @@ func (m *MktsConfig) Parse(data []byte) (*MktsConfig, error) {
 			RetryInterval     time.Duration `yaml:"retry_interval"`
 			RetryBackoffCoeff int           `yaml:"retry_backoff_coeff"`
 		} `yaml:"replication"`
+		TieredStorage struct {
+			Backend           string `yaml:"backend"`
+			OffloadAfterYears int    `yaml:"offload_after_years"`
+			CacheDirectory    string `yaml:"cache_directory"`
+			CacheMaxMB        int64  `yaml:"cache_max_mb"`
+			Local             struct {
+				Directory string `yaml:"directory"`
+			} `yaml:"local"`
+			S3 struct {
+				Endpoint        string `yaml:"endpoint"`
+				Region          string `yaml:"region"`
+				Bucket          string `yaml:"bucket"`
+				Prefix          string `yaml:"prefix"`
+				AccessKeyID     string `yaml:"access_key_id"`
+				SecretAccessKey string `yaml:"secret_access_key"`
+				SessionToken    string `yaml:"session_token"`
+			} `yaml:"s3"`
+		} `yaml:"tiered_storage"`
+		// Retention is the retention period of the buckets matching each TimeBucketKey pattern
+		Retention         map[string]string `yaml:"retention"`
+		RetentionInterval time.Duration     `yaml:"retention_interval"`
+		RetentionDryRun   bool              `yaml:"retention_dry_run"`
+		// CompactionInterval is the interval between two compactions of the variable length buckets
+		CompactionInterval     time.Duration `yaml:"compaction_interval"`
+		CompactionMinDeadRatio float64       `yaml:"compaction_min_dead_ratio"`
+		Auth                   struct {
+			APIKeys     map[string]string `yaml:"api_keys"`
+			TokenSecret string            `yaml:"token_secret"`
+			GRPCTLS     struct {
+				CertFile     string `yaml:"cert_file"`
+				KeyFile      string `yaml:"key_file"`
+				ClientCAFile string `yaml:"client_ca_file"`
+			} `yaml:"grpc_tls"`
+			PGTLS struct {
+				CertFile string `yaml:"cert_file"`
+				KeyFile  string `yaml:"key_file"`
+			} `yaml:"pg_tls"`
+			AllowedOrigins []string            `yaml:"allowed_origins"`
+			Users          map[string][]string `yaml:"users"`
+			Roles          map[string][]struct {
+				Keys  []string `yaml:"keys"`
+				Verbs []string `yaml:"verbs"`
+			} `yaml:"roles"`
+		} `yaml:"auth"`
 		Triggers []struct {
 			Module string                 `yaml:"module"`
 			On     string                 `yaml:"on"`
@@ -121,6 +204,12 @@ func (m *MktsConfig) Parse(data []byte) (*MktsConfig, error) {
 	}
 	m.RootDirectory = absoluteRootDir
 
+	if aux.BackupDirectory != "" {
+		if m.BackupDirectory, err = filepath.Abs(filepath.Clean(aux.BackupDirectory)); err != nil {
+			return nil, fmt.Errorf("invalid backup directory. backupDir=%s, err=%w", aux.BackupDirectory, err)
+		}
+	}
+
 	if aux.ListenPort == "" {
 		log.Error("listen port can't be empty.")
 		return nil, errors.New("invalid listen port. Listen port can't be empty")
@@ -203,6 +292,13 @@ func (m *MktsConfig) Parse(data []byte) (*MktsConfig, error) {
 		}
 	}
 
+	if aux.WriteAutoCreate != "" {
+		m.WriteAutoCreate, err = strconv.ParseBool(aux.WriteAutoCreate)
+		if err != nil {
+			log.Error("Invalid value for WriteAutoCreate")
+		}
+	}
+
 	m.InitCatalog = true
 	if aux.InitCatalog != "" {
 		m.InitCatalog, err = strconv.ParseBool(aux.InitCatalog)
@@ -278,10 +374,96 @@ func (m *MktsConfig) Parse(data []byte) (*MktsConfig, error) {
 		m.Replication.RetryBackoffCoeff = aux.Replication.RetryBackoffCoeff
 	}
 
+	ts := aux.TieredStorage
+	m.TieredStorage = TieredStorageSetting{}
+	switch ts.Backend {
+	case "":
+	case "local", "s3":
+		if ts.Backend == "local" && ts.Local.Directory == "" {
+			return nil, errors.New("the directory of the local tiered storage backend is not set")
+		}
+		if ts.Backend == "s3" && (ts.S3.Endpoint == "" || ts.S3.Region == "" || ts.S3.Bucket == "") {
+			return nil, errors.New("the endpoint, region and bucket of the s3 tiered storage backend must be set")
+		}
+		if ts.OffloadAfterYears <= 0 {
+			return nil, fmt.Errorf("invalid offload_after_years of the tiered storage: %d", ts.OffloadAfterYears)
+		}
+		// the cache is next to the root directory by default, out of the catalog
+		if ts.CacheDirectory == "" {
+			ts.CacheDirectory = m.RootDirectory + "-cache"
+		}
+		m.TieredStorage = TieredStorageSetting{
+			Backend:           ts.Backend,
+			OffloadAfterYears: ts.OffloadAfterYears,
+			CacheMaxBytes:     ts.CacheMaxMB * megabyteToByte,
+			S3:                S3Setting(ts.S3),
+		}
+		if m.TieredStorage.CacheDirectory, err = filepath.Abs(filepath.Clean(ts.CacheDirectory)); err != nil {
+			return nil, fmt.Errorf("invalid tiered storage cache directory. dir=%s, err=%w", ts.CacheDirectory, err)
+		}
+		if ts.Local.Directory != "" {
+			if m.TieredStorage.LocalDirectory, err = filepath.Abs(filepath.Clean(ts.Local.Directory)); err != nil {
+				return nil, fmt.Errorf("invalid tiered storage directory. dir=%s, err=%w", ts.Local.Directory, err)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("invalid tiered storage backend %q, it must be \"local\" or \"s3\"", ts.Backend)
+	}
+
+	const defaultCompactionMinDeadRatio = 0.5
+	m.Compaction = CompactionSetting{Interval: aux.CompactionInterval, MinDeadRatio: defaultCompactionMinDeadRatio}
+	if aux.CompactionMinDeadRatio != 0 {
+		if aux.CompactionMinDeadRatio < 0 || aux.CompactionMinDeadRatio > 1 {
+			return nil, fmt.Errorf("invalid compaction_min_dead_ratio %v, it must be between 0 and 1",
+				aux.CompactionMinDeadRatio)
+		}
+		m.Compaction.MinDeadRatio = aux.CompactionMinDeadRatio
+	}
+
+	m.Auth = AuthSetting{
+		APIKeys:        aux.Auth.APIKeys,
+		TokenSecret:    aux.Auth.TokenSecret,
+		GRPCTLS:        AuthTLSSetting(aux.Auth.GRPCTLS),
+		PGTLS:          AuthTLSSetting{CertFile: aux.Auth.PGTLS.CertFile, KeyFile: aux.Auth.PGTLS.KeyFile},
+		AllowedOrigins: aux.Auth.AllowedOrigins,
+		Users:          aux.Auth.Users,
+		Roles:          map[string][]ACLRule{},
+	}
+	for role, rules := range aux.Auth.Roles {
+		for _, rule := range rules {
+			m.Auth.Roles[role] = append(m.Auth.Roles[role], ACLRule(rule))
+		}
+	}
+	if err = m.Auth.validate(); err != nil {
+		return nil, fmt.Errorf("auth: %w", err)
+	}
+
+	const defaultRetentionInterval = time.Hour
+	m.Retention = RetentionSetting{Interval: defaultRetentionInterval, DryRun: aux.RetentionDryRun}
+	if aux.RetentionInterval > 0 {
+		m.Retention.Interval = aux.RetentionInterval
+	}
+	for pattern, period := range aux.Retention {
+		if len(strings.Split(pattern, "/")) != 3 {
+			return nil, fmt.Errorf("invalid retention pattern %q, it must be like */1Min/OHLCV", pattern)
+		}
+		policy, err2 := NewRetentionPolicy(pattern, period)
+		if err2 != nil {
+			return nil, fmt.Errorf("retention of %s: %w", pattern, err2)
+		}
+		m.Retention.Policies = append(m.Retention.Policies, policy)
+	}
+	sort.Slice(m.Retention.Policies, func(i, j int) bool {
+		return m.Retention.Policies[i].Pattern < m.Retention.Policies[j].Pattern
+	})
+
 	m.ListenURL = fmt.Sprintf("%v:%v", aux.ListenHost, aux.ListenPort)
 	if aux.GRPCListenPort != "" {
 		m.GRPCListenURL = fmt.Sprintf("%v:%v", aux.ListenHost, aux.GRPCListenPort)
 	}
+	if aux.PGListenPort != "" {
+		m.PGListenURL = fmt.Sprintf("%v:%v", aux.ListenHost, aux.PGListenPort)
+	}
 	m.UtilitiesURL = fmt.Sprintf("%v", aux.UtilitiesURL)
 
 	for _, trig := range aux.Triggers {
@@ -12,17 +12,43 @@ func isIterable(i interface{}) bool {
 	return kind == reflect.Array || kind == reflect.Slice
 }
 
+// CoerceColumn replaces the data type of values in the column of the data shape
+// to the type of the data shape, with its scale for a DECIMAL64 type.
+func (cs *ColumnSeries) CoerceColumn(ds DataShape) error {
+	if ds.Type == DECIMAL64 {
+		return cs.coerceToDecimal(ds.Name, ds.Scale)
+	}
+	return cs.CoerceColumnType(ds.Name, ds.Type)
+}
+
 // CoerceColumnType replaces the data type of values
 // in a column that has the specified name to the specified elementType.
+// A DECIMAL64 column is converted with its scale, and the values coerced
+// to DECIMAL64 keep the scale of the column, 0 if it is not a DECIMAL64 one.
+// A STRING column can only be converted to a BLOB one and vice versa.
 func (cs *ColumnSeries) CoerceColumnType(columnName string, elementType EnumElementType) (err error) {
-	if elementType == BOOL || elementType == STRING || elementType == STRING16 {
+	if IsHeapType(elementType) {
+		return cs.coerceHeapColumn(columnName, elementType)
+	}
+	if elementType == BOOL || elementType == STRING16 {
 		return fmt.Errorf("can not cast to boolean or string")
 	}
+	if _, ok := HeapValues(cs.GetByName(columnName)); ok {
+		return fmt.Errorf("can not cast a string or a blob to %s", elementType)
+	}
+	if elementType == DECIMAL64 {
+		return cs.coerceToDecimal(columnName, cs.Scale(columnName))
+	}
 
 	iCol := cs.GetByName(columnName)
 	if !isIterable(iCol) {
 		return errors.New("bug! column values should be a slice or array")
 	}
+	if dec, ok := iCol.([]Decimal64); ok {
+		// the decimals are converted to their values first, not to coerce the unscaled integers
+		iCol = DecimalsToFloat64(dec, cs.Scale(columnName))
+		delete(cs.scales, columnName)
+	}
 
 	columnValues := reflect.ValueOf(iCol)
 
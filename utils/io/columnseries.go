@@ -34,6 +34,7 @@ type ColumnSeries struct {
 	columns       map[string]interface{} // key: column name, value: a slice of values of the column
 	orderedNames  []string
 	nameIncrement map[string]int
+	scales        map[string]int8 // key: column name, value: the scale of a DECIMAL64 column
 }
 
 func NewColumnSeries() *ColumnSeries {
@@ -53,7 +54,13 @@ func (cs *ColumnSeries) GetDataShapes() (ds []DataShape) {
 		// fmt.Printf("name %v, type %v\n", name, GetElementType(cs.columns[name]))
 		et[i] = GetElementType(cs.columns[cs.orderedNames[i]])
 	}
-	return NewDataShapeVector(cs.orderedNames, et)
+	ds = NewDataShapeVector(cs.orderedNames, et)
+	for i := range ds {
+		if ds[i].Type == DECIMAL64 {
+			ds[i].Scale = cs.scales[ds[i].Name]
+		}
+	}
+	return ds
 }
 
 func (cs *ColumnSeries) Len() int {
@@ -150,17 +157,26 @@ func (cs *ColumnSeries) Rename(newName, oldName string) error {
 		}
 	}
 
+	scale := cs.Scale(oldName)
 	cs.AddColumn(newName, oldColumn)
 	cs.Remove(oldName)
 	cs.orderedNames = newNames
+	if scale != 0 {
+		cs.SetScale(newName, scale)
+	}
 	return nil
 }
 
 func (cs *ColumnSeries) Replace(targetName string, col interface{}) error {
+	scale := cs.Scale(targetName)
 	if err := cs.Remove(targetName); err != nil {
 		return err
 	}
 	cs.AddColumn(targetName, col)
+	// the decimals replacing decimals keep their scale
+	if _, ok := col.([]Decimal64); ok && scale != 0 {
+		cs.SetScale(targetName, scale)
+	}
 	return nil
 }
 
@@ -176,6 +192,7 @@ func (cs *ColumnSeries) Remove(targetName string) error {
 	}
 	cs.orderedNames = newNames
 	delete(cs.columns, targetName)
+	delete(cs.scales, targetName)
 	return nil
 }
 
@@ -243,8 +260,22 @@ func (cs *ColumnSeries) ToRowSeries(itemKey TimeBucketKey, alignData bool) (rs *
 	return rs, nil
 }
 
+// AddNullColumn adds a column of zeros.
 func (cs *ColumnSeries) AddNullColumn(ds DataShape) {
 	cs.AddColumn(ds.Name, ds.Type.SliceOf(cs.Len()))
+	if ds.Type == DECIMAL64 {
+		cs.SetScale(ds.Name, ds.Scale)
+	}
+}
+
+// AddNullableColumn adds a column whose values are all NULL, they are zeros marked by a companion column.
+func (cs *ColumnSeries) AddNullableColumn(ds DataShape) {
+	cs.AddNullColumn(ds)
+	nulls := make([]bool, cs.Len())
+	for i := range nulls {
+		nulls[i] = true
+	}
+	cs.SetNulls(ds.Name, nulls)
 }
 
 // ApplyTimeQual takes a function that determines whether or
@@ -257,6 +288,7 @@ func (cs *ColumnSeries) ApplyTimeQual(tq func(epoch int64) bool) *ColumnSeries {
 		orderedNames:  cs.orderedNames,
 		nameIncrement: cs.nameIncrement,
 		columns:       map[string]interface{}{},
+		scales:        cs.scales,
 	}
 
 	for i, epoch := range cs.GetEpoch() {
@@ -289,6 +321,7 @@ func SliceColumnSeriesByEpoch(cs ColumnSeries, start, end *int64) (slc ColumnSer
 		orderedNames:  cs.orderedNames,
 		nameIncrement: cs.nameIncrement,
 		columns:       map[string]interface{}{},
+		scales:        cs.scales,
 	}
 
 	for name, col := range cs.columns {
@@ -344,6 +377,9 @@ func ColumnSeriesUnion(left, right *ColumnSeries) *ColumnSeries {
 	for k, v := range left.nameIncrement {
 		out.nameIncrement[k] = v
 	}
+	for k, v := range left.scales {
+		out.SetScale(k, v)
+	}
 
 	type entry struct {
 		epoch     int64
@@ -412,6 +448,7 @@ func (csm ColumnSeriesMap) GetMetadataKeys() (keys []TimeBucketKey) {
 func (csm ColumnSeriesMap) AddColumnSeries(key TimeBucketKey, cs *ColumnSeries) {
 	for _, name := range cs.orderedNames {
 		csm.AddColumn(key, name, cs.columns[name])
+		csm[key].CopyScale(name, cs, name)
 	}
 }
 
@@ -553,13 +590,13 @@ func SerializeColumnsToRows(cs *ColumnSeries, dataShapes []DataShape, align64 bo
 			fmt.Errorf("find missing and type coercion columns from datashape=%v: %w", dataShapes, err)
 	}
 
-	// Add in the null columns needed to complete the set
+	// Add in the zero columns needed to complete the set
 	for _, shape := range missing {
-		cs.AddNullColumn(shape)
+		cs.AddColumn(shape.Name, shape.Type.SliceOf(cs.Len()))
 	}
 	// Coerce column types as needed
 	for _, shape := range needcoercion {
-		err = cs.CoerceColumnType(shape.Name, shape.Type)
+		err = cs.CoerceColumn(shape)
 		if err != nil {
 			log.Error(fmt.Sprintf("failed to coerce column (name=%s, type=%s)", shape.Name, shape.Type))
 		}
@@ -2,6 +2,7 @@ package io
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -10,13 +11,15 @@ import (
 type DataShape struct {
 	Name string
 	Type EnumElementType
+	// Scale is the number of digits after the decimal point of a DECIMAL64 column, 0 for the other types
+	Scale int8
 }
 
 // NewDataShapeVector returns a new array of DataShapes for the given array of
 // names and element types.
 func NewDataShapeVector(names []string, etypes []EnumElementType) (dsv []DataShape) {
 	for i, name := range names {
-		dsv = append(dsv, DataShape{name, etypes[i]})
+		dsv = append(dsv, DataShape{Name: name, Type: etypes[i]})
 	}
 	return dsv
 }
@@ -27,14 +30,30 @@ func (ds *DataShape) Len() (out int) {
 }
 
 // String returns the colon-separated string of the DataShapes name and type.
-func (ds *DataShape) String() (st string) {
+func (ds DataShape) String() (st string) {
+	if ds.Type == DECIMAL64 {
+		return ds.Name + ":" + ds.Type.String() + "(" + strconv.Itoa(int(ds.Scale)) + ")"
+	}
 	return ds.Name + ":" + ds.Type.String()
 }
 
-// Equal compares both the name and type of two DataShapes, only returning true
-// if both are equal.
+// Equal compares the name, type and scale of two DataShapes, only returning true
+// if all are equal.
 func (ds *DataShape) Equal(shape DataShape) bool {
-	return ds.Name == shape.Name && ds.Type == shape.Type
+	return ds.Name == shape.Name && ds.Type == shape.Type && ds.Scale == shape.Scale
+}
+
+// DataShapesEqual returns true if both DataShape vectors have the same columns in the same order.
+func DataShapesEqual(a, b []DataShape) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
 }
 
 func DataShapesFromInputString(inputStr string) (dsa []DataShape, err error) {
@@ -49,12 +68,17 @@ func DataShapesFromInputString(inputStr string) (dsa []DataShape, err error) {
 		elementNames := strings.Split(twoParts[0], ",")
 		elementType := twoParts[1]
 		eType := EnumElementTypeFromName(elementType)
-		if eType == NONE {
+		// a DECIMAL64 type has its scale, e.g. decimal64(8)
+		scale, isDecimal := parseDecimalTypeName(elementType)
+		if isDecimal {
+			eType = DECIMAL64
+		}
+		if eType == NONE || (eType == DECIMAL64 && !isDecimal) {
 			err = fmt.Errorf("error: %s: Data type is not a supported type", group)
 			return nil, err
 		}
 		for _, name := range elementNames {
-			dsa = append(dsa, DataShape{Name: name, Type: eType})
+			dsa = append(dsa, DataShape{Name: name, Type: eType, Scale: scale})
 		}
 	}
 	return dsa, nil
@@ -82,6 +106,14 @@ func (ds *DataShape) toBytes() ([]byte, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to serialize data type:"+string(ds.Type))
 	}
+
+	// scale, only for the DECIMAL64 type so that the other data shapes keep their serialization
+	if ds.Type == DECIMAL64 {
+		buffer, err = Serialize(buffer, ds.Scale)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to serialize scale:"+ds.Name)
+		}
+	}
 	return buffer, nil
 }
 
@@ -94,8 +126,13 @@ func dsFromBytes(buf []byte) (DataShape, int) {
 	cursor += dsNameLen
 	dsType := EnumElementType(buf[cursor])
 	cursor++
+	var scale int8
+	if dsType == DECIMAL64 {
+		scale = int8(buf[cursor])
+		cursor++
+	}
 
-	return DataShape{Name: dsName, Type: dsType}, cursor
+	return DataShape{Name: dsName, Type: dsType, Scale: scale}, cursor
 }
 
 // DSVFromBytes deserializes bytes into an array of datashape (=Data Shape Vector)
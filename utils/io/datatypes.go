@@ -53,6 +53,8 @@ const (
 	UINT32
 	UINT64
 	STRING16
+	DECIMAL64
+	BLOB
 )
 
 var attributeMap = map[EnumElementType]struct {
@@ -61,21 +63,23 @@ var attributeMap = map[EnumElementType]struct {
 	size   int
 	typeOf reflect.Type
 }{
-	FLOAT32:  {reflect.Float32, "float32", 4, reflect.TypeOf(float32(0))},
-	INT32:    {reflect.Int32, "int32", 4, reflect.TypeOf(int32(0))},
-	FLOAT64:  {reflect.Float64, "float64", 8, reflect.TypeOf(float64(0))},
-	INT64:    {reflect.Int64, "int64", 8, reflect.TypeOf(int64(0))},
-	EPOCH:    {reflect.Int64, "epoch", 8, reflect.TypeOf(int64(0))},
-	BYTE:     {reflect.Int8, "byte", 1, reflect.TypeOf(byte(0))},
-	BOOL:     {reflect.Bool, "bool", 1, reflect.TypeOf(false)},
-	NONE:     {reflect.Invalid, "none", 0, reflect.TypeOf(byte(0))},
-	STRING:   {reflect.String, "string", 0, reflect.TypeOf("")},
-	INT16:    {reflect.Int16, "int16", 2, reflect.TypeOf(int16(0))},
-	UINT8:    {reflect.Uint8, "uint8", 1, reflect.TypeOf(uint8(0))},
-	UINT16:   {reflect.Uint16, "uint16", 2, reflect.TypeOf(uint16(0))},
-	UINT32:   {reflect.Uint32, "uint32", 4, reflect.TypeOf(uint32(0))},
-	UINT64:   {reflect.Uint64, "uint64", 8, reflect.TypeOf(uint64(0))},
-	STRING16: {reflect.Array, "string16", 64, reflect.TypeOf([16]rune{})},
+	FLOAT32:   {reflect.Float32, "float32", 4, reflect.TypeOf(float32(0))},
+	INT32:     {reflect.Int32, "int32", 4, reflect.TypeOf(int32(0))},
+	FLOAT64:   {reflect.Float64, "float64", 8, reflect.TypeOf(float64(0))},
+	INT64:     {reflect.Int64, "int64", 8, reflect.TypeOf(int64(0))},
+	EPOCH:     {reflect.Int64, "epoch", 8, reflect.TypeOf(int64(0))},
+	BYTE:      {reflect.Int8, "byte", 1, reflect.TypeOf(byte(0))},
+	BOOL:      {reflect.Bool, "bool", 1, reflect.TypeOf(false)},
+	NONE:      {reflect.Invalid, "none", 0, reflect.TypeOf(byte(0))},
+	STRING:    {reflect.String, "string", 8, reflect.TypeOf("")},
+	INT16:     {reflect.Int16, "int16", 2, reflect.TypeOf(int16(0))},
+	UINT8:     {reflect.Uint8, "uint8", 1, reflect.TypeOf(uint8(0))},
+	UINT16:    {reflect.Uint16, "uint16", 2, reflect.TypeOf(uint16(0))},
+	UINT32:    {reflect.Uint32, "uint32", 4, reflect.TypeOf(uint32(0))},
+	UINT64:    {reflect.Uint64, "uint64", 8, reflect.TypeOf(uint64(0))},
+	STRING16:  {reflect.Array, "string16", 64, reflect.TypeOf([16]rune{})},
+	DECIMAL64: {reflect.Int64, "decimal64", 8, decimal64Type},
+	BLOB:      {reflect.Slice, "blob", 8, blobType},
 }
 
 func EnumElementTypeFromName(name string) EnumElementType {
@@ -118,7 +122,7 @@ func (e EnumElementType) ByteSliceAt(sliceOf interface{}, index int) (bs []byte)
 
 func (e EnumElementType) SliceOf(length int) (sliceOf interface{}) {
 	typeOf := attributeMap[e].typeOf
-	return reflect.MakeSlice(typeOf, length, length)
+	return reflect.MakeSlice(reflect.SliceOf(typeOf), length, length).Interface()
 }
 
 func (e EnumElementType) ConvertByteSliceInto(data []byte) interface{} {
@@ -135,8 +139,9 @@ func (e EnumElementType) ConvertByteSliceInto(data []byte) interface{} {
 		return SwapSliceByte(data, int8(0)).([]int8)
 	case INT16:
 		return SwapSliceByte(data, int16(0)).([]int16)
-	case STRING:
-		return SwapSliceByte(data, fmt.Sprint(0)).([]string)
+	case STRING, BLOB:
+		// the references of the values in the heap, see ReadHeapColumns
+		return SwapSliceByte(data, uint64(0)).([]uint64)
 	case UINT8:
 		return SwapSliceByte(data, uint8(0)).([]uint8)
 	case UINT16:
@@ -147,6 +152,8 @@ func (e EnumElementType) ConvertByteSliceInto(data []byte) interface{} {
 		return SwapSliceByte(data, uint64(0)).([]uint64)
 	case STRING16:
 		return SwapSliceByte(data, [16]rune{}).([][16]rune)
+	case DECIMAL64:
+		return SwapSliceByte(data, Decimal64(0)).([]Decimal64)
 	}
 	return nil
 }
@@ -157,7 +164,17 @@ func GetElementType(datum interface{}) EnumElementType {
 	kind := value.Kind()
 	switch kind {
 	case reflect.Array, reflect.Chan, reflect.Map, reflect.Ptr, reflect.Slice:
+		switch reflect.TypeOf(datum).Elem() {
+		case decimal64Type:
+			return DECIMAL64
+		case blobType:
+			return BLOB
+		}
 		kind = reflect.TypeOf(datum).Elem().Kind()
+	case reflect.Int64:
+		if value.Type() == decimal64Type {
+			return DECIMAL64
+		}
 	}
 	switch kind {
 	case reflect.Struct, reflect.Func, reflect.Interface, reflect.UnsafePointer:
@@ -269,6 +286,20 @@ func getInt64Column(offset, reclen, nrecs int, data []byte) (col []int64) {
 	return col
 }
 
+func getDecimal64Column(offset, reclen, nrecs int, data []byte) (col []Decimal64) {
+	col = make([]Decimal64, nrecs)
+	if nrecs == 0 {
+		return col
+	}
+
+	cursor := offset
+	for i := 0; i < nrecs; i++ {
+		col[i] = Decimal64(ToInt64(data[cursor : cursor+8]))
+		cursor += reclen
+	}
+	return col
+}
+
 func getUInt8Column(offset, reclen, nrecs int, data []byte) (col []uint8) {
 	col = make([]uint8, nrecs)
 	if nrecs == 0 {
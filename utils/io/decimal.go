@@ -0,0 +1,244 @@
+package io
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+/*
+DECIMAL64 columns
+
+A DECIMAL64 value is a fixed-point number stored as an int64 count of units of 10^-scale, e.g. 123.45678 is
+12345678 with the scale 5, so that prices keep all their digits where a float would round them. The scale is
+a property of the column: it is stored in the header of the year files next to the element types, and it is
+carried by the DataShape of the column. In a ColumnSeries, a DECIMAL64 column is a []Decimal64 whose scale is
+set with SetScale, a column without a scale has the scale 0.
+*/
+type Decimal64 int64
+
+// MaxDecimalScale is the maximum scale of a DECIMAL64 column.
+const MaxDecimalScale = 18
+
+var decimal64Type = reflect.TypeOf(Decimal64(0))
+
+var pow10 = func() (p [MaxDecimalScale + 1]int64) {
+	p[0] = 1
+	for i := 1; i <= MaxDecimalScale; i++ {
+		p[i] = p[i-1] * 10
+	}
+	return p
+}()
+
+// ValidateScale returns an error if scale is not the scale of a DECIMAL64 column.
+func ValidateScale(scale int8) error {
+	if scale < 0 || scale > MaxDecimalScale {
+		return fmt.Errorf("decimal scale %d is out of the range [0, %d]", scale, MaxDecimalScale)
+	}
+	return nil
+}
+
+// NewDecimal64 returns the decimal with the scale that is the closest to f.
+func NewDecimal64(f float64, scale int8) Decimal64 {
+	return Decimal64(math.Round(f * float64(pow10[scale])))
+}
+
+// Float64 returns the value of the decimal with the scale.
+func (d Decimal64) Float64(scale int8) float64 {
+	// the integer part and the fraction are converted apart, not to lose the digits of large values
+	p := pow10[scale]
+	return float64(int64(d)/p) + float64(int64(d)%p)/float64(p)
+}
+
+// Rescale returns the decimal with the scale to, the closest to d with the scale from.
+func (d Decimal64) Rescale(from, to int8) Decimal64 {
+	switch {
+	case to > from:
+		return d * Decimal64(pow10[to-from])
+	case to < from:
+		p := Decimal64(pow10[from-to])
+		q, r := d/p, d%p
+		// rounds half away from zero
+		if 2*r >= p {
+			q++
+		} else if 2*r <= -p {
+			q--
+		}
+		return q
+	}
+	return d
+}
+
+// Format returns the exact decimal representation of d with the scale, e.g. "-0.050".
+func (d Decimal64) Format(scale int8) string {
+	s := strconv.FormatInt(int64(d), 10)
+	if scale == 0 {
+		return s
+	}
+	sign := ""
+	if d < 0 {
+		sign, s = "-", s[1:]
+	}
+	if len(s) <= int(scale) {
+		s = strings.Repeat("0", int(scale)-len(s)+1) + s
+	}
+	return sign + s[:len(s)-int(scale)] + "." + s[len(s)-int(scale):]
+}
+
+/*
+ParseDecimal64 returns the decimal with the scale of a number written in decimal, e.g. "-123.45", exactly
+unless it has more digits after the decimal point than the scale, in which case it is rounded. The other
+forms of numbers, e.g. "1e-3", are parsed as floats.
+*/
+func ParseDecimal64(s string, scale int8) (Decimal64, error) {
+	if err := ValidateScale(scale); err != nil {
+		return 0, err
+	}
+	sign, digits := int64(1), s
+	switch {
+	case strings.HasPrefix(digits, "-"):
+		sign, digits = -1, digits[1:]
+	case strings.HasPrefix(digits, "+"):
+		digits = digits[1:]
+	}
+	whole, frac := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		whole, frac = digits[:i], digits[i+1:]
+	}
+	if whole+frac == "" || !isDigits(whole) || !isDigits(frac) {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, err
+		}
+		return NewDecimal64(f, scale), nil
+	}
+	roundUp := false
+	if len(frac) > int(scale) {
+		roundUp = frac[scale] >= '5'
+		frac = frac[:scale]
+	}
+	n, err := strconv.ParseInt(whole+frac+strings.Repeat("0", int(scale)-len(frac)), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if roundUp {
+		n++
+	}
+	return Decimal64(sign * n), nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// DecimalsToFloat64 returns the values of the decimals with the scale.
+func DecimalsToFloat64(col []Decimal64, scale int8) []float64 {
+	out := make([]float64, len(col))
+	for i, d := range col {
+		out[i] = d.Float64(scale)
+	}
+	return out
+}
+
+// decimalTypeName returns the name of the DECIMAL64 type with the scale, e.g. "decimal64(8)". It is the
+// type string of the column in a NumpyDataset and the type of the column in DataShapesFromInputString.
+func decimalTypeName(scale int8) string {
+	return attributeMap[DECIMAL64].name + "(" + strconv.Itoa(int(scale)) + ")"
+}
+
+// parseDecimalTypeName returns the scale of the name of a DECIMAL64 type, ok=false if it is not one.
+func parseDecimalTypeName(name string) (scale int8, ok bool) {
+	prefix := attributeMap[DECIMAL64].name + "("
+	if len(name) <= len(prefix) || !strings.EqualFold(name[:len(prefix)], prefix) || !strings.HasSuffix(name, ")") {
+		return 0, false
+	}
+	s, err := strconv.ParseInt(name[len(prefix):len(name)-1], 10, 8)
+	if err != nil || ValidateScale(int8(s)) != nil {
+		return 0, false
+	}
+	return int8(s), true
+}
+
+// Scale returns the scale of a DECIMAL64 column of the series, 0 for the other columns.
+func (cs *ColumnSeries) Scale(name string) int8 {
+	return cs.scales[name]
+}
+
+// SetScale sets the scale of a DECIMAL64 column of the series.
+func (cs *ColumnSeries) SetScale(name string, scale int8) {
+	if cs.scales == nil {
+		cs.scales = map[string]int8{}
+	}
+	cs.scales[name] = scale
+}
+
+// AddDecimalColumn adds a DECIMAL64 column with the scale, see AddColumn.
+func (cs *ColumnSeries) AddDecimalColumn(name string, col []Decimal64, scale int8) (outname string) {
+	outname = cs.AddColumn(name, col)
+	cs.SetScale(outname, scale)
+	return outname
+}
+
+// CopyScale sets the scale of a column copied from the column srcName of src, if it has one.
+func (cs *ColumnSeries) CopyScale(name string, src ColumnInterface, srcName string) {
+	if scale := ColumnScale(src, srcName); scale != 0 {
+		cs.SetScale(name, scale)
+	}
+}
+
+// ColumnScale returns the scale of a DECIMAL64 column of a ColumnSeries or Rows, 0 for the other columns.
+func ColumnScale(cols ColumnInterface, name string) int8 {
+	if cs, ok := cols.(*ColumnSeries); ok {
+		return cs.Scale(name)
+	}
+	for _, ds := range cols.GetDataShapes() {
+		if ds.Name == name {
+			return ds.Scale
+		}
+	}
+	return 0
+}
+
+// coerceToDecimal replaces a column of the series by its DECIMAL64 values with the scale.
+func (cs *ColumnSeries) coerceToDecimal(columnName string, scale int8) error {
+	if err := ValidateScale(scale); err != nil {
+		return err
+	}
+	iCol := cs.GetByName(columnName)
+	if !isIterable(iCol) {
+		return fmt.Errorf("bug! column values should be a slice or array")
+	}
+	if _, ok := HeapValues(iCol); ok {
+		return fmt.Errorf("can not cast a string or a blob to %s", DECIMAL64)
+	}
+	var newCol []Decimal64
+	switch col := iCol.(type) {
+	case []Decimal64:
+		newCol = make([]Decimal64, len(col))
+		from := cs.Scale(columnName)
+		for i, d := range col {
+			newCol[i] = d.Rescale(from, scale)
+		}
+	default:
+		columnValues := reflect.ValueOf(iCol)
+		newCol = make([]Decimal64, columnValues.Len())
+		for i := 0; i < columnValues.Len(); i++ {
+			v := columnValues.Index(i)
+			if _, found := group["float"][v.Kind()]; found {
+				newCol[i] = NewDecimal64(v.Float(), scale)
+			} else {
+				newCol[i] = Decimal64(toInt(v)) * Decimal64(pow10[scale])
+			}
+		}
+	}
+	cs.columns[columnName] = newCol
+	cs.SetScale(columnName, scale)
+	return nil
+}
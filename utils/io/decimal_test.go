@@ -0,0 +1,105 @@
+package io
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecimal64(t *testing.T) {
+	t.Parallel()
+	d, err := ParseDecimal64("123.45678", 5)
+	assert.Nil(t, err)
+	assert.Equal(t, Decimal64(12345678), d)
+	assert.Equal(t, "123.45678", d.Format(5))
+	assert.Equal(t, 123.45678, d.Float64(5))
+
+	// rounded half away from zero
+	assert.Equal(t, Decimal64(1234568), d.Rescale(5, 4))
+	assert.Equal(t, Decimal64(-1234568), (-d).Rescale(5, 4))
+	assert.Equal(t, Decimal64(1234567800), d.Rescale(5, 7))
+
+	for s, want := range map[string]Decimal64{
+		"-0.05":  -50,
+		"+1":     1000,
+		"0.0005": 1,
+		".25":    250,
+		"1e-3":   1,
+		"-2.5E1": -25000,
+	} {
+		d, err = ParseDecimal64(s, 3)
+		assert.Nil(t, err, s)
+		assert.Equal(t, want, d, s)
+	}
+	assert.Equal(t, "-0.050", Decimal64(-50).Format(3))
+	assert.Equal(t, "0.001", Decimal64(1).Format(3))
+
+	for _, s := range []string{"", "-", "1.2.3", "abc"} {
+		_, err = ParseDecimal64(s, 3)
+		assert.NotNil(t, err, s)
+	}
+	_, err = ParseDecimal64("1", MaxDecimalScale+1)
+	assert.NotNil(t, err)
+}
+
+func TestDecimalDataShapes(t *testing.T) {
+	t.Parallel()
+	dsv, err := DataShapesFromInputString("Bid/decimal64(8):Size/int32")
+	assert.Nil(t, err)
+	assert.Equal(t, []DataShape{
+		{Name: "Bid", Type: DECIMAL64, Scale: 8},
+		{Name: "Size", Type: INT32},
+	}, dsv)
+	assert.Equal(t, "Bid:DECIMAL64(8)", dsv[0].String())
+
+	_, err = DataShapesFromInputString("Bid/decimal64(19)")
+	assert.NotNil(t, err)
+
+	// the scale is stored with the data shapes of a decimal column only
+	b, err := DSVToBytes(dsv)
+	assert.Nil(t, err)
+	back, _ := DSVFromBytes(b)
+	assert.Equal(t, dsv, back)
+	b2, err := DSVToBytes([]DataShape{{Name: "Bid", Type: INT64}, {Name: "Size", Type: INT32}})
+	assert.Nil(t, err)
+	assert.Len(t, b, len(b2)+1)
+}
+
+func TestDecimalNumpyDataset(t *testing.T) {
+	t.Parallel()
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1, 2})
+	cs.AddDecimalColumn("Bid", []Decimal64{12345, -5}, 4)
+
+	nds, err := NewNumpyDataset(cs)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"i8", "decimal64(4)"}, nds.ColumnTypes)
+
+	out, err := nds.ToColumnSeries()
+	assert.Nil(t, err)
+	assert.Equal(t, []Decimal64{12345, -5}, out.GetColumn("Bid"))
+	assert.Equal(t, int8(4), out.Scale("Bid"))
+	assert.Equal(t, cs.GetDataShapes(), out.GetDataShapes())
+}
+
+func TestCoerceDecimalColumn(t *testing.T) {
+	t.Parallel()
+	cs := NewColumnSeries()
+	cs.AddColumn("Price", []float64{1.23456, -0.5})
+	cs.AddColumn("Size", []int32{3, 4})
+
+	assert.Nil(t, cs.CoerceColumn(DataShape{Name: "Price", Type: DECIMAL64, Scale: 4}))
+	assert.Equal(t, []Decimal64{12346, -5000}, cs.GetColumn("Price"))
+	assert.Nil(t, cs.CoerceColumn(DataShape{Name: "Size", Type: DECIMAL64, Scale: 2}))
+	assert.Equal(t, []Decimal64{300, 400}, cs.GetColumn("Size"))
+
+	// a decimal column is rescaled
+	assert.Nil(t, cs.CoerceColumn(DataShape{Name: "Price", Type: DECIMAL64, Scale: 2}))
+	assert.Equal(t, []Decimal64{123, -50}, cs.GetColumn("Price"))
+	assert.Equal(t, int8(2), cs.Scale("Price"))
+
+	// and converted by its value to the other types
+	assert.Nil(t, cs.CoerceColumnType("Price", FLOAT64))
+	assert.Equal(t, []float64{1.23, -0.5}, cs.GetColumn("Price"))
+	assert.Equal(t, int8(0), cs.Scale("Price"))
+}
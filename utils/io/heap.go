@@ -0,0 +1,227 @@
+package io
+
+import (
+	"encoding/binary"
+	"fmt"
+	stdio "io"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+/*
+Heap columns
+
+The values of the STRING and BLOB columns have no fixed size, so they are not stored in the records: each year
+file has a heap file next to it (e.g. "2021.heap" for "2021.bin"), where the values are appended, and the
+record holds the 8-byte offset of its value in the heap, its reference. A value is stored in the heap as its
+length (uint32) followed by its bytes. The heap starts with HeapHeaderSize bytes, so that the reference 0, the
+one of the empty records, is the empty value, which is never stored.
+
+In a ColumnSeries, a STRING column is a []string and a BLOB column a [][]byte. The columns read from the
+records hold the references as a []uint64 until they are resolved with ReadHeapColumns.
+*/
+const (
+	HeapFileExt    = ".heap"
+	HeapHeaderSize = 8
+	heapLenBytes   = 4
+	heapMagic      = "MKTSHEAP"
+)
+
+var blobType = reflect.TypeOf([]byte(nil))
+
+// IsHeapType returns true if the values of the type are stored in the heap files.
+func IsHeapType(typ EnumElementType) bool {
+	return typ == STRING || typ == BLOB
+}
+
+// HasHeapColumns returns true if one of the data shapes is stored in the heap files.
+func HasHeapColumns(dsv []DataShape) bool {
+	for _, ds := range dsv {
+		if IsHeapType(ds.Type) {
+			return true
+		}
+	}
+	return false
+}
+
+// HeapPathOf returns the path of the heap file of a year file, an archive file or a stub.
+func HeapPathOf(yearFilePath string) string {
+	return strings.TrimSuffix(yearFilePath, filepath.Ext(yearFilePath)) + HeapFileExt
+}
+
+// HeapHeader returns the bytes at the beginning of a heap file.
+func HeapHeader() []byte {
+	return []byte(heapMagic)
+}
+
+// AppendHeapValue appends a value to the bytes of a heap, it returns them with the position of the value.
+func AppendHeapValue(heap, value []byte) (out []byte, pos int) {
+	pos = len(heap)
+	var l [heapLenBytes]byte
+	binary.LittleEndian.PutUint32(l[:], uint32(len(value)))
+	return append(append(heap, l[:]...), value...), pos
+}
+
+// ReadHeapValue reads the value of a reference from a heap.
+func ReadHeapValue(r stdio.ReaderAt, ref uint64) ([]byte, error) {
+	if ref == 0 {
+		return nil, nil
+	}
+	var l [heapLenBytes]byte
+	if _, err := r.ReadAt(l[:], int64(ref)); err != nil {
+		return nil, fmt.Errorf("read the length of the heap value at %d: %w", ref, err)
+	}
+	value := make([]byte, binary.LittleEndian.Uint32(l[:]))
+	if _, err := r.ReadAt(value, int64(ref)+heapLenBytes); err != nil {
+		return nil, fmt.Errorf("read the heap value at %d: %w", ref, err)
+	}
+	return value, nil
+}
+
+// HeapChunk is a part of a heap from the offset Base, e.g. the values of a write held in memory. A *HeapChunk
+// is a heap for ReadHeapColumns.
+type HeapChunk struct {
+	Base int64
+	Data []byte
+}
+
+// ReadAt reads from the heap at the offset off, which is in the chunk.
+func (c *HeapChunk) ReadAt(p []byte, off int64) (int, error) {
+	if off < c.Base || off-c.Base > int64(len(c.Data)) {
+		return 0, fmt.Errorf("heap offset %d is out of the chunk at %d", off, c.Base)
+	}
+	n := copy(p, c.Data[off-c.Base:])
+	if n < len(p) {
+		return n, stdio.EOF
+	}
+	return n, nil
+}
+
+/*
+ReadHeapColumns replaces the references of the STRING and BLOB columns of the data shapes, read from the
+records, by their values. heapOf returns the heap of the i-th row, each value is read once from its heap.
+*/
+func (cs *ColumnSeries) ReadHeapColumns(dsv []DataShape, heapOf func(i int) (stdio.ReaderAt, error)) error {
+	read := map[stdio.ReaderAt]map[uint64][]byte{}
+	for _, ds := range dsv {
+		refs, ok := cs.GetByName(ds.Name).([]uint64)
+		if !IsHeapType(ds.Type) || !ok {
+			continue
+		}
+		values := make([][]byte, len(refs))
+		for i, ref := range refs {
+			if ref == 0 {
+				continue
+			}
+			heap, err := heapOf(i)
+			if err != nil {
+				return err
+			}
+			if read[heap] == nil {
+				read[heap] = map[uint64][]byte{}
+			}
+			value, ok := read[heap][ref]
+			if !ok {
+				if value, err = ReadHeapValue(heap, ref); err != nil {
+					return fmt.Errorf("column %s: %w", ds.Name, err)
+				}
+				read[heap][ref] = value
+			}
+			values[i] = value
+		}
+		cs.columns[ds.Name] = HeapColumn(ds.Type, values)
+	}
+	return nil
+}
+
+// HeapColumnValues are the values of a STRING or BLOB column with the offset of their references in the rows.
+type HeapColumnValues struct {
+	Offset int
+	Values [][]byte
+}
+
+/*
+TakeHeapColumns replaces the STRING and BLOB columns of the series by zero references, which take their
+place in the rows serialized from the series, and returns their values with the offsets of the references.
+*/
+func (cs *ColumnSeries) TakeHeapColumns() (cols []HeapColumnValues) {
+	var offset int
+	for _, ds := range cs.GetDataShapes() {
+		if IsHeapType(ds.Type) {
+			values, _ := HeapValues(cs.columns[ds.Name])
+			cols = append(cols, HeapColumnValues{Offset: offset, Values: values})
+			cs.columns[ds.Name] = make([]uint64, len(values))
+		}
+		offset += ds.Type.Size()
+	}
+	return cols
+}
+
+// HeapColumn returns the STRING or BLOB column of the values.
+func HeapColumn(typ EnumElementType, values [][]byte) interface{} {
+	if typ == BLOB {
+		return values
+	}
+	col := make([]string, len(values))
+	for i, v := range values {
+		col[i] = string(v)
+	}
+	return col
+}
+
+// HeapValues returns the bytes of the values of a STRING or BLOB column, ok=false if it is neither.
+func HeapValues(col interface{}) (values [][]byte, ok bool) {
+	switch c := col.(type) {
+	case [][]byte:
+		return c, true
+	case []string:
+		values = make([][]byte, len(c))
+		for i, s := range c {
+			values[i] = []byte(s)
+		}
+		return values, true
+	}
+	return nil, false
+}
+
+// encodeHeapColumn returns the bytes of the values of a STRING or BLOB column in a NumpyDataset, each value
+// is its length (uint32) followed by its bytes.
+func encodeHeapColumn(col interface{}) []byte {
+	values, _ := HeapValues(col)
+	var buf []byte
+	for _, v := range values {
+		buf, _ = AppendHeapValue(buf, v)
+	}
+	return buf
+}
+
+// decodeHeapColumn returns the values of the rows [start, start+length) of the bytes of a column in a
+// NumpyDataset, see encodeHeapColumn.
+func decodeHeapColumn(typ EnumElementType, data []byte, start, length int) (interface{}, error) {
+	values := make([][]byte, 0, length)
+	for i := 0; i < start+length; i++ {
+		if len(data) < heapLenBytes {
+			return nil, fmt.Errorf("the %s column has less than %d values", typ, start+length)
+		}
+		l := int(binary.LittleEndian.Uint32(data))
+		if len(data) < heapLenBytes+l {
+			return nil, fmt.Errorf("the value %d of the %s column is truncated", i, typ)
+		}
+		if i >= start {
+			values = append(values, data[heapLenBytes:heapLenBytes+l])
+		}
+		data = data[heapLenBytes+l:]
+	}
+	return HeapColumn(typ, values), nil
+}
+
+// coerceHeapColumn replaces a STRING or BLOB column of the series by a column of the other type.
+func (cs *ColumnSeries) coerceHeapColumn(columnName string, typ EnumElementType) error {
+	values, ok := HeapValues(cs.GetByName(columnName))
+	if !ok {
+		return fmt.Errorf("only a string or a blob column can be converted to %s", typ)
+	}
+	cs.columns[columnName] = HeapColumn(typ, values)
+	return nil
+}
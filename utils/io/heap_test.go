@@ -0,0 +1,94 @@
+package io
+
+import (
+	stdio "io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeapNumpyDataset(t *testing.T) {
+	t.Parallel()
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1, 2, 3})
+	cs.AddColumn("Cond", []string{"@", "", "F T"})
+	cs.AddColumn("Raw", [][]byte{{0}, nil, {1, 2}})
+
+	nds, err := NewNumpyDataset(cs)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"i8", "O", "S"}, nds.ColumnTypes)
+	assert.Equal(t, []DataShape{
+		{Name: "Epoch", Type: INT64},
+		{Name: "Cond", Type: STRING},
+		{Name: "Raw", Type: BLOB},
+	}, cs.GetDataShapes())
+
+	out, err := nds.ToColumnSeries()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"@", "", "F T"}, out.GetColumn("Cond"))
+	assert.Equal(t, [][]byte{{0}, {}, {1, 2}}, out.GetColumn("Raw"))
+
+	// a part of the rows
+	out, err = nds.ToColumnSeries(1, 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"", "F T"}, out.GetColumn("Cond"))
+
+	nds.ColumnData[1] = nds.ColumnData[1][:6]
+	_, err = nds.ToColumnSeries()
+	assert.NotNil(t, err)
+}
+
+func TestHeapColumns(t *testing.T) {
+	t.Parallel()
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1, 2})
+	cs.AddColumn("Price", []float32{1, 2})
+	cs.AddColumn("Cond", []string{"@", ""})
+	cs.AddColumn("Raw", [][]byte{{0}, {1, 2}})
+	dsv := cs.GetDataShapes()
+
+	// the values are taken out of the series, the rows hold their references
+	cols := cs.TakeHeapColumns()
+	assert.Equal(t, []HeapColumnValues{
+		{Offset: 12, Values: [][]byte{[]byte("@"), {}}},
+		{Offset: 20, Values: [][]byte{{0}, {1, 2}}},
+	}, cols)
+	assert.Equal(t, []uint64{0, 0}, cs.GetColumn("Cond"))
+	assert.Equal(t, UINT64, cs.GetDataShapes()[3].Type)
+
+	// and read back from a heap
+	heap := HeapHeader()
+	var refs []uint64
+	for _, v := range [][]byte{[]byte("@"), {0}, {1, 2}} {
+		var pos int
+		heap, pos = AppendHeapValue(heap, v)
+		refs = append(refs, uint64(pos))
+	}
+	cs.columns["Cond"] = []uint64{refs[0], 0}
+	cs.columns["Raw"] = []uint64{refs[1], refs[2]}
+	chunk := &HeapChunk{Base: HeapHeaderSize, Data: heap[HeapHeaderSize:]}
+	assert.Nil(t, cs.ReadHeapColumns(dsv, func(int) (stdio.ReaderAt, error) { return chunk, nil }))
+	assert.Equal(t, []string{"@", ""}, cs.GetColumn("Cond"))
+	assert.Equal(t, [][]byte{{0}, {1, 2}}, cs.GetColumn("Raw"))
+	assert.Equal(t, []string{"Epoch", "Price", "Cond", "Raw"}, cs.GetColumnNames())
+
+	// a reference out of the heap
+	cs.columns["Raw"] = []uint64{uint64(len(heap)) + 1, 0}
+	assert.NotNil(t, cs.ReadHeapColumns(dsv, func(int) (stdio.ReaderAt, error) { return chunk, nil }))
+}
+
+func TestCoerceHeapColumn(t *testing.T) {
+	t.Parallel()
+	cs := NewColumnSeries()
+	cs.AddColumn("Cond", []string{"@", "F"})
+	cs.AddColumn("Size", []int32{1, 2})
+
+	assert.Nil(t, cs.CoerceColumnType("Cond", BLOB))
+	assert.Equal(t, [][]byte{[]byte("@"), []byte("F")}, cs.GetColumn("Cond"))
+	assert.Nil(t, cs.CoerceColumnType("Cond", STRING))
+	assert.Equal(t, []string{"@", "F"}, cs.GetColumn("Cond"))
+
+	assert.NotNil(t, cs.CoerceColumnType("Cond", FLOAT64))
+	assert.NotNil(t, cs.CoerceColumnType("Size", STRING))
+	assert.NotNil(t, cs.CoerceColumn(DataShape{Name: "Cond", Type: DECIMAL64, Scale: 2}))
+}
@@ -46,7 +46,8 @@ func NewTimeBucketKeyFromString(itemCategoryString string) (mk *TimeBucketKey) {
 
 // e.g. "/project/marketstore/data/AMZN/1Min/TICK/2017.bin"
 //   -> (AMZN/1Min/TICK/2017.bin), (AMZN), (1Min), (TICK), (2017).
-var wkpRegex = regexp.MustCompile(`([^/]+)/([^/]+)/([^/]+)/([^/]+)\.bin$`)
+// The archived and the offloaded years match too.
+var wkpRegex = regexp.MustCompile(`([^/]+)/([^/]+)/([^/]+)/([^/]+)\.(?:bin|arc|remote)$`)
 
 // NewTimeBucketKeyFromWalKeyPath converts a string in walKeyPath format
 // (e.g. "/project/marketstore/data/AMZN/1Min/TICK/2017.bin") to a TimeBucketKey and year.
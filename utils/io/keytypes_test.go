@@ -23,6 +23,13 @@ func TestNewTimeBucketKeyFromWalKeyPath(t *testing.T) {
 			wantYear:   2017,
 			wantErr:    false,
 		},
+		{
+			name:       "Success/offloaded year",
+			walKeyPath: "/project/marketstore/data/AMZN/1Min/TICK/2010.remote",
+			wantTbk:    io.NewTimeBucketKey("AMZN/1Min/TICK"),
+			wantYear:   2010,
+			wantErr:    false,
+		},
 		{
 			name:       "Invalid format of WalKeyPath",
 			walKeyPath: "/foor/bar/2017.bin",
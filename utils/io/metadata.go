@@ -27,10 +27,15 @@ const (
 	reservedHeader1Bytes   = 8
 	elementNameHeaderBytes = 32   // 32bytes per element
 	maxNumElements         = 1024 // max number of elements in a bucket
-	reservedHeader2Bytes   = 365
+	reservedHeader2Bytes   = 365 - maxNumElements/8
 	Headersize             = 37024
 	FileinfoVersion        = int64(2.0)
 	epochLenBytes          = 8
+	// YearFileExt is the extension of the year files, ArchiveFileExt the one of the archived (read-only) years,
+	// and RemoteFileExt the one of the stubs of the years offloaded to the remote storage (read-only).
+	YearFileExt    = ".bin"
+	ArchiveFileExt = ".arc"
+	RemoteFileExt  = ".remote"
 )
 
 func nanosecondsInYear(year int) int64 {
@@ -49,6 +54,8 @@ type TimeBucketInfo struct {
 	Year int16
 	// Path is the absolute path to the data binary file.
 	// (e.g. "/project/marketstore/data/TEST/1Sec/Tick/2021.bin")
+	// The path of an archived year ends with ArchiveFileExt (e.g. "2019.arc"),
+	// and the one of an offloaded year with RemoteFileExt (e.g. "2010.remote").
 	Path   string
 	IsRead bool
 
@@ -74,6 +81,8 @@ type TimeBucketInfo struct {
 	elementNames []string
 	// e.g. []io.EnumElementType{FLOAT32, FLOAT32}. elementTypes doesn't include "Epoch" column or "Nanoseconds" column.
 	elementTypes []EnumElementType
+	// e.g. []int8{8, 0} for "Price(DECIMAL64 with the scale 8), Size(INT64)". the scale of the other types is 0.
+	elementScales []int8
 
 	once sync.Once
 }
@@ -92,16 +101,22 @@ func NewTimeBucketInfo(tf utils.Timeframe, path, description string, year int16,
 ) (f *TimeBucketInfo) {
 	elementTypes, elementNames := CreateShapesForTimeBucketInfo(dsv)
 	f = &TimeBucketInfo{
-		version:      FileinfoVersion,
-		Path:         filepath.Join(path, strconv.Itoa(int(year))+".bin"),
-		IsRead:       true,
-		timeframe:    tf.Duration,
-		description:  description,
-		Year:         year,
-		nElements:    int32(len(elementTypes)),
-		elementTypes: elementTypes,
-		elementNames: elementNames,
-		recordType:   recordType,
+		version:       FileinfoVersion,
+		Path:          filepath.Join(path, strconv.Itoa(int(year))+".bin"),
+		IsRead:        true,
+		timeframe:     tf.Duration,
+		description:   description,
+		Year:          year,
+		nElements:     int32(len(elementTypes)),
+		elementTypes:  elementTypes,
+		elementNames:  elementNames,
+		elementScales: make([]int8, 0, len(elementTypes)),
+		recordType:    recordType,
+	}
+	for _, shape := range dsv {
+		if shape.Name != "Epoch" {
+			f.elementScales = append(f.elementScales, shape.Scale)
+		}
 	}
 	if f.recordType == FIXED {
 		f.recordLength = int32(AlignedSize(f.getFieldRecordLength())) + epochLenBytes // add an 8-byte epoch field
@@ -128,9 +143,15 @@ func CreateShapesForTimeBucketInfo(dsv []DataShape) (elementTypes []EnumElementT
 }
 
 func (f *TimeBucketInfo) GetDataShapes() []DataShape {
-	return NewDataShapeVector(
+	dsv := NewDataShapeVector(
 		f.GetElementNames(),
 		f.GetElementTypes())
+	for i, scale := range f.GetElementScales() {
+		if i < len(dsv) && dsv[i].Type == DECIMAL64 {
+			dsv[i].Scale = scale
+		}
+	}
+	return dsv
 }
 
 func (f *TimeBucketInfo) GetDataShapesWithEpoch() (out []DataShape) {
@@ -148,6 +169,16 @@ func (f *TimeBucketInfo) getFieldRecordLength() (fieldRecordLength int) {
 	return fieldRecordLength
 }
 
+// IsArchived returns true if the year is stored in a read-only archive file.
+func (f *TimeBucketInfo) IsArchived() bool {
+	return filepath.Ext(f.Path) == ArchiveFileExt
+}
+
+// IsRemote returns true if the year is offloaded to the remote storage, the path being the one of its stub.
+func (f *TimeBucketInfo) IsRemote() bool {
+	return filepath.Ext(f.Path) == RemoteFileExt
+}
+
 // GetDeepCopy returns a copy of this TimeBucketInfo.
 func (f *TimeBucketInfo) GetDeepCopy() *TimeBucketInfo {
 	f.once.Do(f.initFromFile)
@@ -165,8 +196,10 @@ func (f *TimeBucketInfo) GetDeepCopy() *TimeBucketInfo {
 	}
 	fcopy.elementNames = make([]string, len(f.elementNames))
 	fcopy.elementTypes = make([]EnumElementType, len(f.elementTypes))
+	fcopy.elementScales = make([]int8, len(f.elementScales))
 	copy(fcopy.elementNames, f.elementNames)
 	copy(fcopy.elementTypes, f.elementTypes)
+	copy(fcopy.elementScales, f.elementScales)
 	return &fcopy
 }
 
@@ -255,6 +288,13 @@ func (f *TimeBucketInfo) GetElementTypes() []EnumElementType {
 	return f.elementTypes
 }
 
+// GetElementScales returns the scales of the DECIMAL64 fields contained by the
+// file described by the given TimeBucketInfo, 0 for the fields of the other types.
+func (f *TimeBucketInfo) GetElementScales() []int8 {
+	f.once.Do(f.initFromFile)
+	return f.elementScales
+}
+
 // SetElementTypes sets the field types contained by the file described by
 // the given TimeBucketInfo.
 func (f *TimeBucketInfo) SetElementTypes(newTypes []EnumElementType) error {
@@ -312,14 +352,12 @@ func (f *TimeBucketInfo) readHeader(path string) (err error) {
 		log.Error("Failed to read header part3 from file: %v - Error: %v", path, err)
 		return err
 	}
-	if EnumRecordType(header.RecordType) == VARIABLE {
-		// Read to end of header
-		start += int(header.NElements)
-		n, err = file.Read(buffer[start:Headersize])
-		if err != nil || n != (Headersize-start) {
-			log.Error("Failed to read header part4 from file: %v - Error: %v", path, err)
-			return err
-		}
+	// Read to end of header, the element scales are after the element types
+	start += int(header.NElements)
+	n, err = file.Read(buffer[start:Headersize])
+	if err != nil || n != (Headersize-start) {
+		log.Error("Failed to read header part4 from file: %v - Error: %v", path, err)
+		return err
 	}
 	f.load(header, path)
 	return nil
@@ -337,10 +375,12 @@ func (f *TimeBucketInfo) load(hp *Header, path string) {
 	f.recordType = EnumRecordType(hp.RecordType)
 	f.elementNames = nil
 	f.elementTypes = nil
+	f.elementScales = nil
 	for i := 0; i < int(f.nElements); i++ {
 		baseName := string(bytes.Trim(hp.ElementNames[i][:], "\x00"))
 		f.elementNames = append(f.elementNames, baseName)
 		f.elementTypes = append(f.elementTypes, EnumElementType(hp.ElementTypes[i]))
+		f.elementScales = append(f.elementScales, hp.ElementScales[i])
 	}
 }
 
@@ -351,6 +391,16 @@ func NewTimeBucketInfoFromHeader(hp *Header, path string) *TimeBucketInfo {
 	return tbi
 }
 
+// ReadTimeBucketInfo reads the header of the file at path, returning an error instead
+// of exiting like the getters of a TimeBucketInfo whose header is not read yet.
+func ReadTimeBucketInfo(path string) (*TimeBucketInfo, error) {
+	tbi := &TimeBucketInfo{Path: path}
+	if err := tbi.readHeader(path); err != nil {
+		return nil, err
+	}
+	return tbi, nil
+}
+
 // Header is the on-disk byte representation of the file header.
 type Header struct {
 	Version      int64
@@ -364,7 +414,10 @@ type Header struct {
 	// Above is the fixed header portion - size is 312 Bytes = (7*8 + 256)
 	ElementNames [maxNumElements][elementNameHeaderBytes]byte
 	ElementTypes [maxNumElements]byte
-	reserved2    [reservedHeader2Bytes]int64
+	// ElementScales are the scales of the DECIMAL64 elements, 1byte per element, taken from the 365 words
+	// that were reserved. They are 0 for the other types, as in the files written before DECIMAL64.
+	ElementScales [maxNumElements]int8
+	reserved2     [reservedHeader2Bytes]int64
 }
 
 // WriteHeader writes the header described by a given TimeBucketInfo to the
@@ -394,6 +447,9 @@ func (hp *Header) Load(f *TimeBucketInfo) {
 	for i := 0; i < int(hp.NElements); i++ {
 		copy(hp.ElementNames[i][:], f.GetElementNames()[i])
 		hp.ElementTypes[i] = byte(f.GetElementTypes()[i])
+		if i < len(f.GetElementScales()) {
+			hp.ElementScales[i] = f.GetElementScales()[i]
+		}
 	}
 	hp.RecordType = int64(f.GetRecordType())
 }
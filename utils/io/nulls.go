@@ -0,0 +1,220 @@
+package io
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+Nullable buckets
+
+A nullable bucket keeps the NULL values of its rows in a validity bitmap, the NullsColumn, which is the last
+column of its data shapes: the bit i of the bitmap of a row is set if the i-th column after Epoch is NULL in
+the row. A NULL value is stored as the zero value of its type, so the bitmap is the only way to tell a NULL
+from a real zero, and a nullable bucket has at most MaxNullableColumns columns besides the bitmap.
+
+In a ColumnSeries, the bitmap is replaced by a BOOL companion column for each column of the bucket, named
+after it with the NullSuffix (e.g. "Close_isnull"), which is true for the rows where the column is NULL.
+A column without a companion has no NULL.
+*/
+const (
+	NullsColumn        = "_nulls"
+	NullSuffix         = "_isnull"
+	MaxNullableColumns = 64
+)
+
+// IsNullable returns true if the data shapes, with or without Epoch, are the ones of a nullable bucket.
+func IsNullable(dsv []DataShape) bool {
+	return len(dsv) > 0 && dsv[len(dsv)-1].Name == NullsColumn
+}
+
+// NullColumnName returns the name of the companion column marking the NULL values of a column.
+func NullColumnName(name string) string {
+	return name + NullSuffix
+}
+
+// NullableDataShapes returns the data shapes of a nullable bucket with the columns dsv, with or without Epoch.
+func NullableDataShapes(dsv []DataShape) ([]DataShape, error) {
+	if IsNullable(dsv) {
+		return dsv, nil
+	}
+	if err := ValidateNullable(dsv); err != nil {
+		return nil, err
+	}
+	return append(append([]DataShape{}, dsv...), DataShape{Name: NullsColumn, Type: UINT64}), nil
+}
+
+// ValidateNullable checks that the columns dsv, with or without Epoch, fit in a nullable bucket.
+func ValidateNullable(dsv []DataShape) error {
+	var n int
+	for _, ds := range dsv {
+		switch {
+		case ds.Name == "Epoch":
+			continue
+		case ds.Name == NullsColumn, strings.HasSuffix(ds.Name, NullSuffix):
+			return fmt.Errorf("column name %s is reserved in a nullable bucket", ds.Name)
+		}
+		n++
+	}
+	if n > MaxNullableColumns {
+		return fmt.Errorf("a nullable bucket can have at most %d columns", MaxNullableColumns)
+	}
+	return nil
+}
+
+// nullableColumns returns the names of the columns of a nullable bucket in the order of the bits of its bitmap.
+func nullableColumns(dsv []DataShape) (names []string) {
+	for _, ds := range dsv {
+		if ds.Name != "Epoch" && ds.Name != NullsColumn {
+			names = append(names, ds.Name)
+		}
+	}
+	return names
+}
+
+// Nulls returns the NULL marks of the rows of a column, or nil if the column has no NULL.
+func (cs *ColumnSeries) Nulls(name string) []bool {
+	nulls, _ := cs.GetColumn(NullColumnName(name)).([]bool)
+	return nulls
+}
+
+// SetNulls marks the NULL values of a column, replacing its previous marks.
+func (cs *ColumnSeries) SetNulls(name string, nulls []bool) {
+	if cs.Exists(NullColumnName(name)) {
+		_ = cs.Replace(NullColumnName(name), nulls)
+		return
+	}
+	cs.AddColumn(NullColumnName(name), nulls)
+}
+
+// HasNullColumns returns true if the series marks NULL values, with companion columns or a bitmap.
+func (cs *ColumnSeries) HasNullColumns() bool {
+	if cs.Exists(NullsColumn) {
+		return true
+	}
+	for _, name := range cs.orderedNames {
+		if cs.IsNullColumn(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsNullColumn returns true if the named column is the companion of another column of the series.
+func (cs *ColumnSeries) IsNullColumn(name string) bool {
+	if !strings.HasSuffix(name, NullSuffix) {
+		return false
+	}
+	_, ok := cs.columns[name].([]bool)
+	return ok && cs.Exists(strings.TrimSuffix(name, NullSuffix))
+}
+
+/*
+BucketDataShapes returns the data shapes of a bucket created to store the series: a series marking NULL values
+makes a nullable bucket, whose data shapes have the bitmap instead of the companion columns.
+*/
+func (cs *ColumnSeries) BucketDataShapes() ([]DataShape, error) {
+	if !cs.HasNullColumns() {
+		return cs.GetDataShapes(), nil
+	}
+	var dsv []DataShape
+	for _, ds := range cs.GetDataShapes() {
+		if ds.Name != NullsColumn && !cs.IsNullColumn(ds.Name) {
+			dsv = append(dsv, ds)
+		}
+	}
+	return NullableDataShapes(dsv)
+}
+
+/*
+PackNulls replaces the companion columns of the series by the bitmap of the nullable bucket with the data
+shapes dsvWithEpoch. The columns of the bucket that the series does not have are added as NULL. A series
+that already has a bitmap, e.g. read from the WAL, is left as it is.
+*/
+func (cs *ColumnSeries) PackNulls(dsvWithEpoch []DataShape) error {
+	if cs.Exists(NullsColumn) {
+		return nil
+	}
+	length := cs.Len()
+	bitmap := make([]uint64, length)
+	names := nullableColumns(dsvWithEpoch)
+	known := map[string]bool{}
+	for bit, name := range names {
+		known[name] = true
+		if !cs.Exists(name) {
+			for _, ds := range dsvWithEpoch {
+				if ds.Name == name {
+					cs.AddColumn(name, ds.Type.SliceOf(length))
+				}
+			}
+			for i := range bitmap {
+				bitmap[i] |= 1 << uint(bit)
+			}
+			continue
+		}
+		nulls := cs.Nulls(name)
+		if nulls == nil {
+			continue
+		}
+		if len(nulls) != length {
+			return fmt.Errorf("column %s has %d NULL marks for %d rows", name, len(nulls), length)
+		}
+		for i, isNull := range nulls {
+			if isNull {
+				bitmap[i] |= 1 << uint(bit)
+			}
+		}
+		if err := cs.Remove(NullColumnName(name)); err != nil {
+			return err
+		}
+	}
+	for _, name := range cs.GetColumnNames() {
+		if cs.IsNullColumn(name) && !known[strings.TrimSuffix(name, NullSuffix)] {
+			return fmt.Errorf("column %s marks the NULL values of a column that the bucket does not have", name)
+		}
+	}
+	cs.AddColumn(NullsColumn, bitmap)
+	return nil
+}
+
+// RemoveNullColumns removes the companion columns of a series written to a bucket that is not nullable.
+func (cs *ColumnSeries) RemoveNullColumns() error {
+	for _, name := range cs.GetColumnNames() {
+		if !cs.IsNullColumn(name) {
+			continue
+		}
+		for _, isNull := range cs.columns[name].([]bool) {
+			if isNull {
+				return fmt.Errorf("column %s has NULL values but the bucket is not nullable",
+					strings.TrimSuffix(name, NullSuffix))
+			}
+		}
+		if err := cs.Remove(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+UnpackNulls replaces the bitmap of a series read from the nullable bucket with the data shapes dsvWithEpoch
+by a companion column for each of the columns of the bucket, so that all the series of a bucket have the
+same columns.
+*/
+func (cs *ColumnSeries) UnpackNulls(dsvWithEpoch []DataShape) {
+	bitmap, ok := cs.GetColumn(NullsColumn).([]uint64)
+	if !ok {
+		return
+	}
+	_ = cs.Remove(NullsColumn)
+	for bit, name := range nullableColumns(dsvWithEpoch) {
+		if !cs.Exists(name) {
+			continue
+		}
+		nulls := make([]bool, len(bitmap))
+		for i, b := range bitmap {
+			nulls[i] = b&(1<<uint(bit)) != 0
+		}
+		cs.SetNulls(name, nulls)
+	}
+}
@@ -0,0 +1,113 @@
+package io
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackUnpackNulls(t *testing.T) {
+	t.Parallel()
+	dsv, err := NullableDataShapes([]DataShape{
+		{Name: "Epoch", Type: EPOCH},
+		{Name: "Open", Type: FLOAT32},
+		{Name: "Close", Type: FLOAT32},
+		{Name: "Volume", Type: INT64},
+	})
+	assert.Nil(t, err)
+	assert.True(t, IsNullable(dsv))
+	assert.Equal(t, DataShape{Name: NullsColumn, Type: UINT64}, dsv[len(dsv)-1])
+
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1, 2, 3})
+	cs.AddColumn("Open", []float32{1, 0, 3})
+	cs.SetNulls("Open", []bool{false, true, false})
+	cs.AddColumn("Close", []float32{0, 2, 3})
+	assert.True(t, cs.HasNullColumns())
+
+	// Volume is missing, so it is NULL
+	assert.Nil(t, cs.PackNulls(dsv))
+	assert.Equal(t, []uint64{0b100, 0b101, 0b100}, cs.GetColumn(NullsColumn))
+	assert.False(t, cs.Exists("Open_isnull"))
+	assert.Equal(t, []int64{0, 0, 0}, cs.GetColumn("Volume"))
+
+	cs.UnpackNulls(dsv)
+	assert.False(t, cs.Exists(NullsColumn))
+	assert.Equal(t, []bool{false, true, false}, cs.Nulls("Open"))
+	assert.Equal(t, []bool{false, false, false}, cs.Nulls("Close"))
+	assert.Equal(t, []bool{true, true, true}, cs.Nulls("Volume"))
+
+	// the marks of a column that the bucket does not have
+	cs = NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1})
+	cs.AddColumn("High", []float32{1})
+	cs.SetNulls("High", []bool{true})
+	assert.NotNil(t, cs.PackNulls(dsv))
+
+	_, err = NullableDataShapes([]DataShape{{Name: "Price_isnull", Type: BOOL}})
+	assert.NotNil(t, err)
+}
+
+func TestBucketDataShapes(t *testing.T) {
+	t.Parallel()
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1, 2})
+	cs.AddColumn("Price", []float64{1, 0})
+	dsv, err := cs.BucketDataShapes()
+	assert.Nil(t, err)
+	assert.False(t, IsNullable(dsv))
+
+	cs.SetNulls("Price", []bool{false, true})
+	dsv, err = cs.BucketDataShapes()
+	assert.Nil(t, err)
+	assert.Equal(t, []DataShape{
+		{Name: "Epoch", Type: INT64},
+		{Name: "Price", Type: FLOAT64},
+		{Name: NullsColumn, Type: UINT64},
+	}, dsv)
+
+	// a bucket that is not nullable only takes series without NULL
+	assert.NotNil(t, cs.RemoveNullColumns())
+	cs.SetNulls("Price", []bool{false, false})
+	assert.Nil(t, cs.RemoveNullColumns())
+	assert.False(t, cs.HasNullColumns())
+}
+
+func TestAddNullableColumn(t *testing.T) {
+	t.Parallel()
+	cs := NewColumnSeries()
+	cs.AddColumn("Epoch", []int64{1, 2})
+	cs.AddNullColumn(DataShape{Name: "Open", Type: FLOAT32})
+	cs.AddNullableColumn(DataShape{Name: "Close", Type: FLOAT32})
+	assert.Equal(t, []string{"Epoch", "Open", "Close", "Close_isnull"}, cs.GetColumnNames())
+	assert.Equal(t, []float32{0, 0}, cs.GetColumn("Open"))
+	assert.Nil(t, cs.Nulls("Open"))
+	assert.Equal(t, []float32{0, 0}, cs.GetColumn("Close"))
+	assert.Equal(t, []bool{true, true}, cs.Nulls("Close"))
+}
+
+func TestNumpyDatasetNulls(t *testing.T) {
+	t.Parallel()
+	nds := &NumpyDataset{
+		ColumnTypes: []string{"i8", "f4", "b1", "f8"},
+		ColumnNames: []string{"Epoch", "Price", "Price_isnull", "Size"},
+		ColumnData: [][]byte{
+			CastToByteSlice([]int64{1, 2}),
+			CastToByteSlice([]float32{1.5, 0}),
+			CastToByteSlice([]bool{false, true}),
+			nil, // a msgpack nil
+		},
+		Length: 2,
+	}
+	cs, err := nds.ToColumnSeries()
+	assert.Nil(t, err)
+	assert.Equal(t, []bool{false, true}, cs.Nulls("Price"))
+	assert.Equal(t, []float64{0, 0}, cs.GetColumn("Size"))
+	assert.Equal(t, []bool{true, true}, cs.Nulls("Size"))
+	// the other BOOL columns keep their int8 values
+	assert.Equal(t, []int8{0, 1}, BOOL.ConvertByteSliceInto(CastToByteSlice([]bool{false, true})))
+
+	out, err := NewNumpyDataset(cs)
+	assert.Nil(t, err)
+	assert.Contains(t, out.ColumnTypes, "b1")
+}
@@ -4,6 +4,7 @@ package io
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/alpacahq/marketstore/v4/utils/log"
 )
@@ -20,6 +21,9 @@ var typeMap = map[EnumElementType]string{
 	FLOAT32:  "f4",
 	FLOAT64:  "f8",
 	STRING16: "U16",
+	BOOL:     "b1",
+	STRING:   "O",
+	BLOB:     "S",
 }
 
 var typeStrMap = func() map[string]EnumElementType {
@@ -33,17 +37,39 @@ var typeStrMap = func() map[string]EnumElementType {
 // TypeStrToElemType converts a numpy type string (e.g. "i8", "f4") to an element type
 // ok=false is returned when unknown string is specified.
 func TypeStrToElemType(typeStr string) (elemType EnumElementType, ok bool) {
+	if _, isDecimal := parseDecimalTypeName(typeStr); isDecimal {
+		return DECIMAL64, true
+	}
 	elemType, ok = typeStrMap[typeStr]
 	return elemType, ok
 }
 
+// TypeStrToDataShape returns the data shape of a column from its name and its type string,
+// with the scale of a DECIMAL64 type string (e.g. "decimal64(8)").
+// ok=false is returned when unknown string is specified.
+func TypeStrToDataShape(name, typeStr string) (ds DataShape, ok bool) {
+	if scale, isDecimal := parseDecimalTypeName(typeStr); isDecimal {
+		return DataShape{Name: name, Type: DECIMAL64, Scale: scale}, true
+	}
+	elemType, ok := typeStrMap[typeStr]
+	return DataShape{Name: name, Type: elemType}, ok
+}
+
 func ToTypeStr(elemType EnumElementType) (typeStr string, ok bool) {
 	typeStr, ok = typeMap[elemType]
 	return typeStr, ok
 }
 
+// DataShapeToTypeStr returns the type string of a column, with the scale of a DECIMAL64 column.
+func DataShapeToTypeStr(ds DataShape) (typeStr string, ok bool) {
+	if ds.Type == DECIMAL64 {
+		return decimalTypeName(ds.Scale), true
+	}
+	return ToTypeStr(ds.Type)
+}
+
 type NumpyDataset struct {
-	// a list of type strings such as i4 and f8
+	// a list of type strings such as i4 and f8, or decimal64(8) for a DECIMAL64 column with the scale 8
 	ColumnTypes []string `msgpack:"types"`
 	// a list of column names
 	ColumnNames []string `msgpack:"names"`
@@ -52,6 +78,8 @@ type NumpyDataset struct {
 	// ColumnData[columnIndex] is concatenation of the byte representation of each row.
 	// (e.g. row1 of columnA = "\x01\x02\x03\x04", row2 of columnA = "\x05\x06\x07\x08"
 	// => ColumnData[index of columnA] = "\x01\x02\x03\x04\x05\x06\x07\x08"
+	// The values of a STRING (O) or a BLOB (S) column have no fixed size, each of them is its length as a
+	// little endian uint32 followed by its bytes.
 	ColumnData [][]byte `msgpack:"data"`
 	Length     int      `msgpack:"length"`
 	// hidden
@@ -64,9 +92,8 @@ func NewNumpyDataset(cs *ColumnSeries) (nds *NumpyDataset, err error) {
 	nds.dataShapes = cs.GetDataShapes()
 	for i, name := range cs.GetColumnNames() {
 		nds.ColumnNames = append(nds.ColumnNames, name)
-		colBytes := CastToByteSlice(cs.GetColumn(name))
-		nds.ColumnData = append(nds.ColumnData, colBytes)
-		if typeStr, ok := typeMap[nds.dataShapes[i].Type]; !ok {
+		nds.ColumnData = append(nds.ColumnData, columnBytes(nds.dataShapes[i].Type, cs.GetColumn(name)))
+		if typeStr, ok := DataShapeToTypeStr(nds.dataShapes[i]); !ok {
 			log.Error("unsupported type %v", nds.dataShapes[i].String())
 			return nil, fmt.Errorf("unsupported type")
 		} else {
@@ -81,15 +108,18 @@ func (nds *NumpyDataset) Len() int {
 }
 
 func (nds *NumpyDataset) buildDataShapes() ([]DataShape, error) {
-	etypes := []EnumElementType{}
-	for _, typeStr := range nds.ColumnTypes {
-		if typ, ok := typeStrMap[typeStr]; !ok {
+	if len(nds.ColumnTypes) != len(nds.ColumnNames) {
+		return nil, fmt.Errorf("%d column types for %d column names", len(nds.ColumnTypes), len(nds.ColumnNames))
+	}
+	dsv := make([]DataShape, len(nds.ColumnTypes))
+	for i, typeStr := range nds.ColumnTypes {
+		ds, ok := TypeStrToDataShape(nds.ColumnNames[i], typeStr)
+		if !ok {
 			return nil, fmt.Errorf("unsupported type string %s", typeStr)
-		} else {
-			etypes = append(etypes, typ)
 		}
+		dsv[i] = ds
 	}
-	return NewDataShapeVector(nds.ColumnNames, etypes), nil
+	return dsv, nil
 }
 
 func (nds *NumpyDataset) ToColumnSeries(options ...int) (cs *ColumnSeries, err error) {
@@ -117,11 +147,31 @@ func (nds *NumpyDataset) ToColumnSeries(options ...int) (cs *ColumnSeries, err e
 		}
 	}
 	for i, shape := range nds.dataShapes {
+		if nds.ColumnData[i] == nil {
+			// a nil column, e.g. a msgpack nil, is all NULL
+			cs.AddNullableColumn(shape)
+			continue
+		}
+		if IsHeapType(shape.Type) {
+			col, err := decodeHeapColumn(shape.Type, nds.ColumnData[i], startIndex, length)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", shape.Name, err)
+			}
+			cs.AddColumn(shape.Name, col)
+			continue
+		}
 		size := shape.Type.Size()
 		start := startIndex * size
 		end := start + length*size
 		newColData := shape.Type.ConvertByteSliceInto(nds.ColumnData[i][start:end])
+		if shape.Type == BOOL && strings.HasSuffix(shape.Name, NullSuffix) {
+			// the NULL marks of a column are bools, see IsNullColumn
+			newColData = SwapSliceByte(nds.ColumnData[i][start:end], false).([]bool)
+		}
 		cs.AddColumn(shape.Name, newColData)
+		if shape.Type == DECIMAL64 {
+			cs.SetScale(shape.Name, shape.Scale)
+		}
 	}
 	return cs, nil
 }
@@ -184,9 +234,18 @@ func (nmds *NumpyMultiDataset) Append(cs *ColumnSeries, tbk TimeBucketKey) (err
 	nmds.StartIndex[tbk.String()] = nmds.Length
 	nmds.Lengths[tbk.String()] = cs.Len()
 	nmds.Length += cs.Len()
+	dsv := cs.GetDataShapes()
 	for idx, col := range colSeriesNames {
-		newBuffer := CastToByteSlice(cs.GetColumn(col))
+		newBuffer := columnBytes(dsv[idx].Type, cs.GetColumn(col))
 		nmds.ColumnData[idx] = append(nmds.ColumnData[idx], newBuffer...)
 	}
 	return nil
 }
+
+// columnBytes returns the bytes of a column of the type in a NumpyDataset.
+func columnBytes(typ EnumElementType, col interface{}) []byte {
+	if IsHeapType(typ) {
+		return encodeHeapColumn(col)
+	}
+	return CastToByteSlice(col)
+}
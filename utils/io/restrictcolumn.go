@@ -1,5 +1,10 @@
 package io
 
+import (
+	"fmt"
+	"reflect"
+)
+
 func (cs *ColumnSeries) RestrictViaBitmap(bitmap []bool) (err error) {
 	var bitmapValidLength int
 	for _, val := range bitmap {
@@ -142,7 +147,40 @@ func (cs *ColumnSeries) RestrictViaBitmap(bitmap []bool) (err error) {
 			if err := cs.Replace(key, newCol); err != nil {
 				return err
 			}
+		default:
+			// Other column types, like strings, are restricted via reflection
+			values := reflect.ValueOf(col)
+			newCol := reflect.MakeSlice(values.Type(), 0, bitmapValidLength)
+			for i, val := range bitmap {
+				if !val { // If the bitmap is true, remove the value
+					newCol = reflect.Append(newCol, values.Index(i))
+				}
+			}
+			if err := cs.Replace(key, newCol.Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RestrictViaIndex replaces every column with the rows found at the supplied
+// positions, in the order given. Positions may repeat, so this can be used
+// to reorder, filter or duplicate the rows of the series.
+func (cs *ColumnSeries) RestrictViaIndex(index []int) (err error) {
+	length := cs.Len()
+	for _, i := range index {
+		if i < 0 || i >= length {
+			return fmt.Errorf("row index %d out of range [0:%d]", i, length)
+		}
+	}
+	for _, key := range cs.orderedNames {
+		col := reflect.ValueOf(cs.columns[key])
+		newCol := reflect.MakeSlice(col.Type(), len(index), len(index))
+		for j, i := range index {
+			newCol.Index(j).Set(col.Index(i))
 		}
+		cs.columns[key] = newCol.Interface()
 	}
 	return nil
 }
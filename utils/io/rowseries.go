@@ -44,13 +44,16 @@ func (rows *Rows) GetColumn(colname string) (col interface{}) {
 				return getInt32Column(offset, int(rows.GetRowLen()), rows.GetNumRows(), rows.GetData())
 			case EPOCH, INT64:
 				return getInt64Column(offset, int(rows.GetRowLen()), rows.GetNumRows(), rows.GetData())
+			case DECIMAL64:
+				return getDecimal64Column(offset, int(rows.GetRowLen()), rows.GetNumRows(), rows.GetData())
 			case UINT8:
 				return getUInt8Column(offset, int(rows.GetRowLen()), rows.GetNumRows(), rows.GetData())
 			case UINT16:
 				return getUInt16Column(offset, int(rows.GetRowLen()), rows.GetNumRows(), rows.GetData())
 			case UINT32:
 				return getUInt32Column(offset, int(rows.GetRowLen()), rows.GetNumRows(), rows.GetData())
-			case UINT64:
+			case UINT64, STRING, BLOB:
+				// the values of the STRING and BLOB columns are their references in the heap
 				return getUInt64Column(offset, int(rows.GetRowLen()), rows.GetNumRows(), rows.GetData())
 			case STRING16:
 				return getString16Column(offset, int(rows.GetRowLen()), rows.GetNumRows(), rows.GetData())
@@ -141,6 +144,9 @@ func (rows *Rows) ToColumnSeries() *ColumnSeries {
 			continue
 		}
 		cs.AddColumn(ds.Name, rows.GetColumn(ds.Name))
+		if ds.Type == DECIMAL64 {
+			cs.SetScale(ds.Name, ds.Scale)
+		}
 	}
 	return cs
 }
@@ -165,7 +171,7 @@ func NewRowSeries(
 		This is true because the read() function for variable types inserts a 32-bit nanoseconds column
 	*/
 	if rowType == VARIABLE {
-		dataShape = append(dataShape, DataShape{"Nanoseconds", INT32})
+		dataShape = append(dataShape, DataShape{Name: "Nanoseconds", Type: INT32})
 	}
 	rows := NewRows(dataShape, data)
 	rows.SetRowLen(rowLen)
@@ -228,6 +234,9 @@ func (rs *RowSeries) ToColumnSeries() (key TimeBucketKey, cs *ColumnSeries) {
 			continue
 		}
 		cs.AddColumn(ds.Name, rs.GetColumn(ds.Name))
+		if ds.Type == DECIMAL64 {
+			cs.SetScale(ds.Name, ds.Scale)
+		}
 	}
 	return key, cs
 }
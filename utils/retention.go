@@ -0,0 +1,62 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type RetentionSetting struct {
+	// Policies are sorted by pattern
+	Policies []RetentionPolicy
+	// Interval between two applications of the policies
+	Interval time.Duration
+	// DryRun only reports what the policies would remove
+	DryRun bool
+}
+
+// RetentionPolicy removes the rows of the buckets matching Pattern once they are older than its period.
+type RetentionPolicy struct {
+	// Pattern is a TimeBucketKey whose items can be glob patterns, e.g. "*/1Sec/TICK"
+	Pattern string
+	// Period is the string of the period, e.g. "90d"
+	Period              string
+	Years, Months, Days int
+	Hours               int
+}
+
+/*
+NewRetentionPolicy returns the policy of the buckets matching pattern. The period is a number followed by
+a unit: "h" (hours), "d" (days), "w" (weeks), "m" (months) or "y" (years), e.g. "90d" or "5y".
+*/
+func NewRetentionPolicy(pattern, period string) (RetentionPolicy, error) {
+	p := RetentionPolicy{Pattern: pattern, Period: period}
+	if len(period) < 2 {
+		return p, fmt.Errorf("invalid retention period %q", period)
+	}
+	n, err := strconv.Atoi(period[:len(period)-1])
+	if err != nil || n <= 0 {
+		return p, fmt.Errorf("invalid retention period %q", period)
+	}
+	switch period[len(period)-1] {
+	case 'h':
+		p.Hours = n
+	case 'd':
+		p.Days = n
+	case 'w':
+		const daysPerWeek = 7
+		p.Days = n * daysPerWeek
+	case 'm':
+		p.Months = n
+	case 'y':
+		p.Years = n
+	default:
+		return p, fmt.Errorf("invalid unit of the retention period %q, it must be h, d, w, m or y", period)
+	}
+	return p, nil
+}
+
+// Cutoff returns the time before which the rows are removed at the time now.
+func (p *RetentionPolicy) Cutoff(now time.Time) time.Time {
+	return now.AddDate(-p.Years, -p.Months, -p.Days).Add(-time.Duration(p.Hours) * time.Hour)
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRetentionPolicy(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2021, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	p, err := NewRetentionPolicy("*/1Sec/TICK", "90d")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2020, 12, 31, 12, 0, 0, 0, time.UTC), p.Cutoff(now))
+
+	p, err = NewRetentionPolicy("*/1Min/OHLCV", "5y")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2016, 3, 31, 12, 0, 0, 0, time.UTC), p.Cutoff(now))
+
+	p, err = NewRetentionPolicy("*/1Min/OHLCV", "2w")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2021, 3, 17, 12, 0, 0, 0, time.UTC), p.Cutoff(now))
+
+	p, err = NewRetentionPolicy("*/1Min/OHLCV", "36h")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2021, 3, 30, 0, 0, 0, 0, time.UTC), p.Cutoff(now))
+
+	for _, period := range []string{"", "d", "0d", "-1y", "5", "5s", "1.5y"} {
+		_, err = NewRetentionPolicy("*/1Min/OHLCV", period)
+		assert.NotNil(t, err, period)
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	t.Parallel()
+	config := []byte(`
+root_directory: data
+listen_port: 5993
+retention_dry_run: true
+retention:
+  "*/1Sec/TICK": 90d
+  "*/1Min/OHLCV": 5y
+`)
+	m := &MktsConfig{}
+	_, err := m.Parse(config)
+	assert.Nil(t, err)
+	assert.True(t, m.Retention.DryRun)
+	assert.Equal(t, time.Hour, m.Retention.Interval)
+	assert.Len(t, m.Retention.Policies, 2)
+	assert.Equal(t, "*/1Min/OHLCV", m.Retention.Policies[0].Pattern)
+	assert.Equal(t, 5, m.Retention.Policies[0].Years)
+	assert.Equal(t, "*/1Sec/TICK", m.Retention.Policies[1].Pattern)
+	assert.Equal(t, 90, m.Retention.Policies[1].Days)
+
+	_, err = m.Parse([]byte("root_directory: data\nlisten_port: 5993\nretention:\n  AAPL: 1y\n"))
+	assert.NotNil(t, err)
+}